@@ -0,0 +1,21 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/app"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+)
+
+// main runs the indexer (stream provider + processing pipeline) and the HTTP
+// API in one process, sharing a single Redis connection pool and flags store,
+// for small deployments that don't want a binary per service
+func main() {
+	configPath := flag.String("config", "", "path to config.yaml (defaults to $CONFIG_FILE)")
+	services := flag.String("services", "indexer,api", "comma-separated services to run: indexer, api")
+	overrides := config.BindFlags(flag.CommandLine)
+	flag.Parse()
+	app.ApplyOverrides(overrides)
+
+	app.RunAll(*configPath, *services)
+}
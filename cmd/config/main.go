@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/app"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+)
+
+const usage = `usage: config [--config path] [overrides] <command> [flags]
+
+commands:
+  validate   load the full configuration, check key formats and the pool
+             config, and ping Redis, ClickHouse and the RPC node
+  dump       print the effective configuration (secrets redacted) and
+             where each value came from
+
+overrides:
+  --rpc-url, --redis-addr, --clickhouse-addr, --api-addr, --log-level, --dev,
+  --set KEY=VALUE and others take precedence over env and the config file
+`
+
+func main() {
+	configPath := flag.String("config", "", "path to config.yaml (defaults to $CONFIG_FILE)")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	overrides := config.BindFlags(flag.CommandLine)
+	flag.Parse()
+	app.ApplyOverrides(overrides)
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "validate":
+		fs := flag.NewFlagSet("validate", flag.ExitOnError)
+		offline := fs.Bool("offline", false, "skip Redis, ClickHouse and RPC reachability checks")
+		timeout := fs.Duration("timeout", 5*time.Second, "timeout per reachability check")
+		_ = fs.Parse(args)
+		os.Exit(app.RunValidate(app.ValidateOptions{ConfigPath: *configPath, Offline: *offline, Timeout: *timeout}))
+	case "dump":
+		fs := flag.NewFlagSet("dump", flag.ExitOnError)
+		asJSON := fs.Bool("json", false, "print JSON instead of a table")
+		showUnset := fs.Bool("all", false, "include settings that are not set anywhere")
+		_ = fs.Parse(args)
+		os.Exit(app.RunDump(app.DumpOptions{ConfigPath: *configPath, JSON: *asJSON, All: *showUnset}))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", cmd)
+		flag.Usage()
+		os.Exit(2)
+	}
+}
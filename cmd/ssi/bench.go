@@ -0,0 +1,87 @@
+package main
+
+import (
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/app"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/bench"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func newBenchCommand(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark the ingestion pipeline and the API",
+		Long: `Benchmark the ingestion pipeline and the API and report latency percentiles.
+
+Use --max-p99 and --max-error-rate to fail (exit 1) on regressions, e.g. in
+a release pipeline against a staging environment.`,
+		GroupID: groupTools,
+	}
+
+	ingest := app.BenchIngestOptions{}
+	ingestCmd := &cobra.Command{
+		Use:   "ingest",
+		Short: "Replay recorded swaps through the Redis and ClickHouse sinks",
+		Long: `Replay recorded swaps through the indexer's sinks and report the latency
+of the whole pipeline and of each sink.
+
+Swaps come from --file (JSON lines, as written by the consumer file sink) or
+from ClickHouse (--from/--to, default the last 24h). Each is written with the
+current time and a signature prefixed with --tag, so benchmark rows can be
+removed afterwards:
+
+  ALTER TABLE swaps DELETE WHERE startsWith(signature, 'bench-')
+
+Benchmark swaps are also published on swaps:live; run against staging.`,
+		Example: `  ssi bench ingest --file swaps.ndjson --rate 500 --concurrency 4
+  ssi bench ingest --from 2026-01-01 --count 5000 --max-p99 50ms`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ingest.ConfigPath = g.configPath
+			exitCode(app.RunBenchIngest(ingest))
+		},
+	}
+	f := ingestCmd.Flags()
+	f.StringVar(&ingest.File, "file", "", "recorded swaps as JSON lines (default: read ClickHouse)")
+	f.StringVar(&ingest.From, "from", "", "ClickHouse: start of the range (default: 24h ago)")
+	f.StringVar(&ingest.To, "to", "", "ClickHouse: end of the range (default: now)")
+	f.StringVar(&ingest.Pair, "pair", "", "ClickHouse: only this pair")
+	f.IntVar(&ingest.Count, "count", 1000, "swaps to load")
+	f.Float64Var(&ingest.Rate, "rate", 0, "swaps per second; 0 for unthrottled")
+	f.IntVar(&ingest.Concurrency, "concurrency", 1, "swaps in flight")
+	f.IntVar(&ingest.Loops, "loops", 1, "passes over the loaded swaps")
+	f.StringVar(&ingest.Tag, "tag", bench.DefaultTag, "signature prefix of benchmark swaps")
+	addThresholdFlags(f, &ingest.BenchThresholds)
+
+	api := app.BenchAPIOptions{}
+	apiCmd := &cobra.Command{
+		Use:   "api",
+		Short: "Fire GET requests at API endpoints at a fixed rate",
+		Example: `  ssi bench api --rps 200 --duration 30s
+  ssi bench api --url https://staging.example.com --endpoint /v1/swaps/recent --endpoint /v1/prices/SOL --max-p99 100ms`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			exitCode(app.RunBenchAPI(api))
+		},
+	}
+	f = apiCmd.Flags()
+	f.StringVar(&api.URL, "url", "http://localhost:8090", "API base URL")
+	f.StringVar(&api.APIKey, "api-key", "", "X-API-Key (default API_KEY)")
+	f.StringArrayVar(&api.Endpoints, "endpoint", nil, "GET path to request, repeatable (default: health, recent swaps, SOL price and history)")
+	f.Float64Var(&api.RPS, "rps", 50, "requests per second across all endpoints")
+	f.DurationVar(&api.Duration, "duration", 10*time.Second, "how long to fire")
+	f.IntVar(&api.Concurrency, "concurrency", 16, "requests in flight at most")
+	f.DurationVar(&api.Timeout, "timeout", 5*time.Second, "per request")
+	addThresholdFlags(f, &api.BenchThresholds)
+
+	cmd.AddCommand(ingestCmd, apiCmd)
+	return cmd
+}
+
+func addThresholdFlags(f *pflag.FlagSet, th *app.BenchThresholds) {
+	f.DurationVar(&th.MaxP99, "max-p99", 0, "fail when any stage's p99 latency exceeds this")
+	f.Float64Var(&th.MaxErrorRate, "max-error-rate", 0, "fail when any stage's error rate exceeds this (0-1)")
+	f.BoolVar(&th.JSON, "json", false, "print the report as JSON")
+}
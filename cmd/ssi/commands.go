@@ -0,0 +1,302 @@
+package main
+
+import (
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/app"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/consumer"
+	"github.com/spf13/cobra"
+)
+
+// Command groups in `ssi --help`
+const (
+	groupServices = "services"
+	groupTools    = "tools"
+)
+
+func newIndexerCommand(g *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "indexer",
+		Short:   "Poll the chain for swaps and write them to Redis and ClickHouse",
+		GroupID: groupServices,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			app.RunIndexer(g.configPath)
+		},
+	}
+}
+
+func newAPICommand(g *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "api",
+		Short:   "Serve the HTTP API",
+		GroupID: groupServices,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			app.RunAPI(g.configPath)
+		},
+	}
+}
+
+func newAllCommand(g *globalOptions) *cobra.Command {
+	var (
+		services   string
+		standalone bool
+	)
+	cmd := &cobra.Command{
+		Use:     "all",
+		Short:   "Run the indexer and the API in one process",
+		GroupID: groupServices,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if standalone {
+				app.RunStandalone(g.configPath)
+				return
+			}
+			app.RunAll(g.configPath, services)
+		},
+	}
+	cmd.Flags().StringVar(&services, "services", "indexer,api", "comma-separated services to run: indexer, api, arb, anomalies, mev, ticker, webhooks, digests")
+	cmd.Flags().BoolVar(&standalone, "standalone", false, "keep swaps in memory instead of Redis and ClickHouse (indexer and api only; nothing is persisted)")
+	cmd.MarkFlagsMutuallyExclusive("services", "standalone")
+	return cmd
+}
+
+func newArbCommand(g *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "arb",
+		Short:   "Watch live swaps for cross-DEX price gaps and publish arbitrage opportunities",
+		GroupID: groupServices,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			app.RunArb(g.configPath)
+		},
+	}
+}
+
+func newAnomaliesCommand(g *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "anomalies",
+		Short:   "Watch live swaps for hourly volume and trade-count spikes and publish alerts",
+		GroupID: groupServices,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			app.RunAnomalies(g.configPath)
+		},
+	}
+}
+
+func newTickerCommand(g *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "ticker",
+		Short:   "Publish each pair's last price, 1m volume and trade count on swaps:ticker:<pair>",
+		GroupID: groupServices,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			app.RunTicker(g.configPath)
+		},
+	}
+}
+
+func newWebhooksCommand(g *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "webhooks",
+		Short:   "Post live swaps to the webhooks of the pairs and tokens API keys subscribed to",
+		GroupID: groupServices,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			app.RunWebhooks(g.configPath)
+		},
+	}
+}
+
+func newDigestsCommand(g *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "digests",
+		Short:   "Send hourly and daily summaries of the pairs and tokens API keys subscribed to",
+		GroupID: groupServices,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			exitCode(app.RunDigests(g.configPath))
+		},
+	}
+}
+
+func newSubscriberCommand(g *globalOptions) *cobra.Command {
+	opts := app.SubscriberOptions{}
+	cmd := &cobra.Command{
+		Use:     "subscriber",
+		Aliases: []string{"subscribe"},
+		Short:   "Consume published swaps: live viewer, files, CSV or webhooks",
+		GroupID: groupServices,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.ConfigPath = g.configPath
+			app.RunSubscriber(opts)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&opts.Consumers, "consumers", "", "consumer config (routes and sinks); default prints swaps:live as a table")
+	f.StringVar(&opts.Format, "format", consumer.FormatTable, "output of the default consumer: table or json")
+	f.StringVar(&opts.Group, "group", "", "read the durable swap stream as this consumer group instead of live pub/sub")
+	f.StringVar(&opts.Consumer, "consumer", "", "consumer name within --group (default: hostname-pid)")
+	f.BoolVar(&opts.FromStart, "from-start", false, "with --group: a new group starts at the oldest retained swap instead of new ones")
+	f.StringVar(&opts.Wallet, "wallet", "", "default consumer: follow the swaps of this wallet (swaps:wallet:<address>) instead of swaps:live")
+	return cmd
+}
+
+func newReplayCommand(g *globalOptions) *cobra.Command {
+	opts := app.ReplayOptions{}
+	cmd := &cobra.Command{
+		Use:   "replay --from TIME [--to TIME]",
+		Short: "Republish stored swaps from ClickHouse onto swaps:live",
+		Example: `  ssi replay --from 2024-03-01 --to 2024-03-02 --pair SOL/USDC
+  ssi replay --from 2024-03-01T12:00 --rate 0`,
+		GroupID: groupTools,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.ConfigPath = g.configPath
+			exitCode(app.RunReplay(opts))
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&opts.From, "from", "", "start of the range, inclusive (RFC3339 or YYYY-MM-DD, required)")
+	f.StringVar(&opts.To, "to", "", "end of the range, exclusive (default: now)")
+	f.StringVar(&opts.Pair, "pair", "", "only replay this pair, e.g. SOL/USDC (default: all pairs)")
+	f.Float64Var(&opts.Rate, "rate", constants.ReplayDefaultRate, "swaps published per second; 0 for unthrottled")
+	_ = cmd.MarkFlagRequired("from")
+	return cmd
+}
+
+func newMEVCommand(g *globalOptions) *cobra.Command {
+	opts := app.MEVOptions{}
+	cmd := &cobra.Command{
+		Use:   "mev [--from TIME [--to TIME]]",
+		Short: "Find sandwich attacks among stored swaps and record them in ClickHouse",
+		Example: `  ssi mev
+  ssi mev --from 2024-03-01 --to 2024-03-02`,
+		GroupID: groupServices,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.ConfigPath = g.configPath
+			exitCode(app.RunMEV(opts))
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&opts.From, "from", "", "scan this range once and exit (RFC3339 or YYYY-MM-DD); default follows new swaps")
+	f.StringVar(&opts.To, "to", "", "with --from: end of the range, exclusive (default: now)")
+	return cmd
+}
+
+func newMigrateCommand(g *globalOptions) *cobra.Command {
+	opts := app.MigrateOptions{}
+	cmd := &cobra.Command{
+		Use:     "migrate",
+		Short:   "Apply the ClickHouse schema (init.sql)",
+		GroupID: groupTools,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.ConfigPath = g.configPath
+			exitCode(app.RunMigrate(opts))
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&opts.File, "file", "init.sql", "schema file to apply")
+	f.BoolVar(&opts.DryRun, "dry-run", false, "print the statements instead of running them")
+	f.DurationVar(&opts.Timeout, "timeout", 30*time.Second, "timeout per statement")
+	return cmd
+}
+
+func newConfigCommand(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "config",
+		Short:   "Validate or print the effective configuration",
+		GroupID: groupTools,
+	}
+
+	validate := app.ValidateOptions{}
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check settings, key formats and the pool config, and ping Redis, ClickHouse and the RPC node",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			validate.ConfigPath = g.configPath
+			exitCode(app.RunValidate(validate))
+		},
+	}
+	validateCmd.Flags().BoolVar(&validate.Offline, "offline", false, "skip Redis, ClickHouse and RPC reachability checks")
+	validateCmd.Flags().DurationVar(&validate.Timeout, "timeout", 5*time.Second, "timeout per reachability check")
+
+	dump := app.DumpOptions{}
+	dumpCmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Print the effective configuration (secrets redacted) and where each value came from",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			dump.ConfigPath = g.configPath
+			exitCode(app.RunDump(dump))
+		},
+	}
+	dumpCmd.Flags().BoolVar(&dump.JSON, "json", false, "print JSON instead of a table")
+	dumpCmd.Flags().BoolVar(&dump.All, "all", false, "include settings that are not set anywhere")
+
+	cmd.AddCommand(validateCmd, dumpCmd)
+	return cmd
+}
+
+func newAskCommand(g *globalOptions) *cobra.Command {
+	opts := app.AgentOptions{}
+	cmd := &cobra.Command{
+		Use:     "ask [question]",
+		Aliases: []string{"ai-agent"},
+		Short:   "Ask questions about indexed swaps in plain English (REPL without a question)",
+		GroupID: groupTools,
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.ConfigPath = g.configPath
+			if len(args) == 1 {
+				opts.Query = args[0]
+			}
+			app.RunAIAgent(opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Model, "model", "", "OpenRouter model name (defaults to AI_MODEL)")
+	cmd.Flags().BoolVar(&opts.AllTime, "all-time", false, "Run queries without a time filter over all swaps instead of the last AI_MAX_LOOKBACK")
+	cmd.Flags().BoolVar(&opts.SQLOnly, "sql-only", false, "Print the generated SQL without running it")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the generated SQL with ClickHouse's EXPLAIN plan and row estimate instead of running it")
+	return cmd
+}
+
+func newSwapCommand(g *globalOptions) *cobra.Command {
+	opts := app.SwapOptions{}
+	cmd := &cobra.Command{
+		Use:     "swap",
+		Aliases: []string{"swapengine"},
+		Short:   "Quote or execute a swap through the swap engine",
+		GroupID: groupTools,
+	}
+	f := cmd.PersistentFlags()
+	f.StringVar(&opts.In, "in", "SOL", "input token symbol (e.g. SOL)")
+	f.StringVar(&opts.Out, "out", "USDC", "output token symbol (e.g. USDC)")
+	f.Float64Var(&opts.Amount, "amt", 0, "amount in human units (e.g. 0.1)")
+	f.IntVar(&opts.SlippageBps, "slippage-bps", 100, "slippage in bps (e.g. 100 = 1%)")
+	_ = cmd.MarkPersistentFlagRequired("amt")
+
+	for _, mode := range []struct{ name, short string }{
+		{app.SwapModeQuote, "Print the best pool quote without sending anything"},
+		{app.SwapModeExecute, "Sign and send the swap with WALLET_PRIVATE_KEY"},
+	} {
+		cmd.AddCommand(&cobra.Command{
+			Use:   mode.name,
+			Short: mode.short,
+			Args:  cobra.NoArgs,
+			Run: func(cmd *cobra.Command, args []string) {
+				opts.ConfigPath = g.configPath
+				opts.Mode = mode.name
+				exitCode(app.RunSwap(opts))
+			},
+		})
+	}
+	return cmd
+}
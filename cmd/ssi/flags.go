@@ -0,0 +1,111 @@
+package main
+
+import (
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func newFlagsCommand(g *globalOptions) *cobra.Command {
+	opts := app.FlagsOptions{}
+	cmd := &cobra.Command{
+		Use:   "flags",
+		Short: "List, get, set, delete and watch feature flags",
+		Long: `Manage feature flags and kill switches.
+
+Flags are read and written in Redis (REDIS_* settings) unless --api points
+at a running API server, in which case the /v1/flags endpoints are used with
+--api-key (default API_KEY). Writes are recorded in the flag history under
+--actor (default $USER).`,
+		Example: `  ssi flags list --prefix engine.
+  ssi flags set engine.kill_switch true --ttl 30m
+  ssi flags set ai.model openai/gpt-4.1-mini
+  ssi flags get indexer.paused --api http://localhost:8090
+  ssi flags delete maintenance.read_only
+  ssi flags watch`,
+		GroupID: groupTools,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.ConfigPath = g.configPath
+			return cmd.Root().PersistentPreRunE(cmd, args)
+		},
+	}
+	f := cmd.PersistentFlags()
+	f.StringVar(&opts.APIURL, "api", "", "API base URL, e.g. http://localhost:8090 (default: talk to Redis)")
+	f.StringVar(&opts.APIKey, "api-key", "", "X-API-Key for --api (default API_KEY)")
+	f.StringVar(&opts.Actor, "actor", "", "name recorded in the flag history (default $USER)")
+	f.BoolVar(&opts.JSON, "json", false, "print JSON instead of tables")
+	f.DurationVar(&opts.Timeout, "timeout", 5*time.Second, "timeout per operation")
+
+	var prefix string
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List flags in key order",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			exitCode(app.RunFlagsList(opts, prefix))
+		},
+	}
+	listCmd.Flags().StringVar(&prefix, "prefix", "", "only keys starting with this, e.g. engine.")
+
+	getCmd := &cobra.Command{
+		Use:   "get KEY",
+		Short: "Print a flag as JSON (exits 1 if it does not exist)",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			exitCode(app.RunFlagsGet(opts, args[0]))
+		},
+	}
+
+	set := app.FlagSetOptions{}
+	setCmd := &cobra.Command{
+		Use:   "set KEY VALUE",
+		Short: "Create or update a flag",
+		Long: `Create or update a flag. VALUE is JSON (true, 42, 0.5, "text", {"a":1});
+anything that is not valid JSON is stored as a string. Without --type or a
+schedule option an existing flag keeps its type and schedule.`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			exitCode(app.RunFlagsSet(opts, args[0], args[1], set))
+		},
+	}
+	setCmd.Flags().StringVar(&set.Type, "type", "", "bool, int, float, string or json (default: keep or infer)")
+	setCmd.Flags().DurationVar(&set.TTL, "ttl", 0, "delete the flag after this long, e.g. 2h")
+	setCmd.Flags().StringVar(&set.ActiveFrom, "active-from", "", "take effect at this time (RFC3339)")
+	setCmd.Flags().StringVar(&set.ExpiresAt, "expires-at", "", "delete the flag at this time (RFC3339)")
+
+	deleteCmd := &cobra.Command{
+		Use:     "delete KEY",
+		Aliases: []string{"rm"},
+		Short:   "Delete a flag",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			exitCode(app.RunFlagsDelete(opts, args[0]))
+		},
+	}
+
+	var limit int
+	historyCmd := &cobra.Command{
+		Use:   "history KEY",
+		Short: "Show the recorded changes to a flag, newest first",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			exitCode(app.RunFlagsHistory(opts, args[0], limit))
+		},
+	}
+	historyCmd.Flags().IntVar(&limit, "limit", 20, "number of changes to show")
+
+	var watchPrefix string
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Print flag changes as they happen (Redis only)",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			exitCode(app.RunFlagsWatch(opts, watchPrefix))
+		},
+	}
+	watchCmd.Flags().StringVar(&watchPrefix, "prefix", "", "only keys starting with this")
+
+	cmd.AddCommand(listCmd, getCmd, setCmd, deleteCmd, historyCmd, watchCmd)
+	return cmd
+}
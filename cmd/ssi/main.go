@@ -0,0 +1,81 @@
+// Command ssi runs every service and tool of the Solana swap indexer from one
+// binary: `ssi indexer`, `ssi api`, `ssi replay`, `ssi migrate` and so on.
+// The standalone binaries under cmd/ run the same code.
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// exitCode ends the process with code from a tool that reports one
+func exitCode(code int) {
+	if code != 0 {
+		os.Exit(code)
+	}
+}
+
+// globalOptions are the persistent flags shared by every command
+type globalOptions struct {
+	configPath string
+	overrides  *config.Overrides // --redis-addr, --rpc-url, --dev, --set, ...
+}
+
+func newRootCommand() *cobra.Command {
+	g := &globalOptions{}
+
+	root := &cobra.Command{
+		Use:   "ssi",
+		Short: "Solana swap indexer: services and operator tools",
+		Long: `ssi runs the Solana swap indexer services and operator tools.
+
+Every command loads configuration the same way: the .env file at the
+project root, then the config file (--config or $CONFIG_FILE, with the
+APP_ENV profile), then the secret store (SECRETS_PROVIDER). Environment
+variables always win over the config file, and the global flags
+(--redis-addr, --rpc-url, --dev, --set KEY=VALUE, ...) win over both.`,
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// the override flags are exported as their env vars for this run
+			return g.overrides.Apply()
+		},
+	}
+	root.PersistentFlags().StringVar(&g.configPath, "config", "", "path to config.yaml (defaults to $CONFIG_FILE)")
+	overrides := flag.NewFlagSet("overrides", flag.ContinueOnError)
+	g.overrides = config.BindFlags(overrides)
+	root.PersistentFlags().AddGoFlagSet(overrides)
+
+	root.AddGroup(
+		&cobra.Group{ID: groupServices, Title: "Services:"},
+		&cobra.Group{ID: groupTools, Title: "Tools:"},
+	)
+	root.AddCommand(
+		newIndexerCommand(g),
+		newAPICommand(g),
+		newAllCommand(g),
+		newArbCommand(g),
+		newAnomaliesCommand(g),
+		newMEVCommand(g),
+		newTickerCommand(g),
+		newWebhooksCommand(g),
+		newDigestsCommand(g),
+		newSubscriberCommand(g),
+		newReplayCommand(g),
+		newMigrateCommand(g),
+		newConfigCommand(g),
+		newFlagsCommand(g),
+		newBenchCommand(g),
+		newAskCommand(g),
+		newSwapCommand(g),
+	)
+	return root
+}
@@ -14,13 +14,153 @@ CREATE TABLE IF NOT EXISTS swaps (
     price Float64,
     fee Float64,
     pool String,
-    dex String
+    dex String,
+    -- on-chain position and exact amounts (0 / '' on rows indexed before they were recorded)
+    slot UInt64 DEFAULT 0,
+    block_time Int64 DEFAULT 0,
+    amount_in_raw UInt64 DEFAULT 0,
+    amount_out_raw UInt64 DEFAULT 0,
+    decimals_in UInt8 DEFAULT 0,
+    decimals_out UInt8 DEFAULT 0,
+    program_id LowCardinality(String) DEFAULT '',
+    pool_address String DEFAULT '',
+    wallet String DEFAULT '',
+    -- landing cost in lamports (priority_fee: above the base fee) and the
+    -- compute unit price bid in micro-lamports; 0 on rows indexed before
+    fee_lamports UInt64 DEFAULT 0,
+    priority_fee UInt64 DEFAULT 0,
+    compute_unit_price UInt64 DEFAULT 0,
+    -- when the indexer processed the swap; indexed_at - block_time is its
+    -- end-to-end latency (epoch 0 on rows indexed before it was recorded)
+    indexed_at DateTime64(3) DEFAULT 0,
+    -- true for swaps indexed at finalized commitment; those indexed at
+    -- confirmed are finalized or retracted later through swap_finality
+    finalized Bool DEFAULT false,
+    -- value in USD from a stablecoin leg or a recent USD price of either
+    -- token; 0 when it could not be valued or on rows indexed before
+    value_usd Float64 DEFAULT 0
 ) ENGINE = MergeTree()
 PARTITION BY toYYYYMM(timestamp)
 ORDER BY (pair, timestamp)
 SETTINGS index_granularity = 8192;
 
--- Materialized view for hourly aggregations
+-- Upgrade tables created before the on-chain columns existed
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS slot UInt64 DEFAULT 0;
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS block_time Int64 DEFAULT 0;
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS amount_in_raw UInt64 DEFAULT 0;
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS amount_out_raw UInt64 DEFAULT 0;
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS decimals_in UInt8 DEFAULT 0;
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS decimals_out UInt8 DEFAULT 0;
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS program_id LowCardinality(String) DEFAULT '';
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS pool_address String DEFAULT '';
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS wallet String DEFAULT '';
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS fee_lamports UInt64 DEFAULT 0;
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS priority_fee UInt64 DEFAULT 0;
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS compute_unit_price UInt64 DEFAULT 0;
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS indexed_at DateTime64(3) DEFAULT 0;
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS finalized Bool DEFAULT false;
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS value_usd Float64 DEFAULT 0;
+
+-- Finality of swaps indexed at confirmed commitment, one row per swap once its
+-- block is finalized ('finalized') or its fork dropped ('retracted'). Kept
+-- apart so swaps rows are never mutated: reads take a swap as finalized when
+-- either swaps.finalized or a 'finalized' row says so, and leave out swaps
+-- with a 'retracted' row.
+CREATE TABLE IF NOT EXISTS swap_finality (
+    signature String,
+    status LowCardinality(String),
+    updated_at DateTime64(3)
+) ENGINE = ReplacingMergeTree(updated_at)
+ORDER BY signature;
+
+-- Sandwich attacks found by `ssi mev`, one row per victim swap (join swaps on
+-- signature = victim_signature to tag victims). Rescanning a range replaces rows.
+CREATE TABLE IF NOT EXISTS sandwiches (
+    victim_signature String,
+    front_signature String,
+    back_signature String,
+    slot UInt64,
+    timestamp DateTime64(3),
+    pool_address String,
+    dex LowCardinality(String),
+    pair String,
+    attacker String,
+    victim String,
+    token String,
+    profit_token String,
+    profit Float64,
+    victim_amount Float64,
+    detected_at DateTime DEFAULT now()
+) ENGINE = ReplacingMergeTree(detected_at)
+PARTITION BY toYYYYMM(timestamp)
+ORDER BY (timestamp, victim_signature);
+
+-- Swap attempts that failed on chain (INDEXER_RECORD_FAILED_SWAPS), one row per
+-- transaction. error_code is the program's custom error (-1 if none) and
+-- instruction_index the failing instruction (-1 if not an instruction error).
+CREATE TABLE IF NOT EXISTS failed_swaps (
+    signature String,
+    timestamp DateTime64(3),
+    slot UInt64,
+    block_time Int64,
+    program_id LowCardinality(String),
+    dex LowCardinality(String),
+    wallet String,
+    error_class LowCardinality(String),
+    error_code Int64,
+    instruction_index Int16,
+    error String,
+    recorded_at DateTime DEFAULT now()
+) ENGINE = ReplacingMergeTree(recorded_at)
+PARTITION BY toYYYYMM(timestamp)
+ORDER BY (program_id, timestamp, signature);
+
+-- Swaps the swap engine attempted past simulation, one row per attempt, with
+-- the compute units simulated, requested (compute_unit_limit, 0 = runtime
+-- default) and consumed on chain, for tuning SWAPENGINE_CU_MARGIN.
+CREATE TABLE IF NOT EXISTS swap_executions (
+    execution_id String,
+    signature String,
+    timestamp DateTime64(3),
+    wallet String,
+    pool LowCardinality(String),
+    input_mint String,
+    output_mint String,
+    amount_in UInt64,
+    expected_out UInt64,
+    success Bool,
+    error String,
+    error_kind LowCardinality(String),
+    error_code Int64,
+    simulated_units UInt64,
+    compute_unit_limit UInt32,
+    compute_unit_price UInt64,
+    actual_units UInt64,
+    fee_lamports UInt64,
+    duration_ms Int64
+) ENGINE = MergeTree()
+PARTITION BY toYYYYMM(timestamp)
+ORDER BY (timestamp, execution_id);
+
+-- Token metadata keyed by the label swaps carry (token_in, token_out), written
+-- by the indexer: the static token list and mints registered from Metaplex.
+-- Join it to filter swaps by token name or category; read it with FINAL.
+CREATE TABLE IF NOT EXISTS tokens (
+    symbol String,
+    mint String,
+    name String,
+    category LowCardinality(String),
+    uri String,
+    updated_at DateTime64(3)
+) ENGINE = ReplacingMergeTree(updated_at)
+ORDER BY symbol;
+
+-- Materialized view for hourly aggregations. Rows are summed as swaps are
+-- inserted and cannot be backed out, so swaps retracted later through
+-- swap_finality stay counted here, in min_price and max_price too. Candles,
+-- pair stats and every other read of swaps leave retracted swaps out, so
+-- their totals can fall below this view's; aggregate swaps with the
+-- swap_finality filter when they must match.
 CREATE MATERIALIZED VIEW IF NOT EXISTS swaps_hourly
 ENGINE = SummingMergeTree()
 PARTITION BY toYYYYMM(hour)
@@ -42,4 +182,5 @@ GROUP BY pair, dex, hour;
 -- Index for faster queries
 CREATE INDEX IF NOT EXISTS idx_dex ON swaps (dex) TYPE minmax GRANULARITY 4;
 CREATE INDEX IF NOT EXISTS idx_timestamp ON swaps (timestamp) TYPE minmax GRANULARITY 4;
-
+CREATE INDEX IF NOT EXISTS idx_wallet ON swaps (wallet) TYPE bloom_filter GRANULARITY 4;
+CREATE INDEX IF NOT EXISTS idx_signature ON swaps (signature) TYPE bloom_filter GRANULARITY 4;
@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/sirupsen/logrus"
@@ -25,15 +27,29 @@ type AgentConfig struct {
 	OpenRouterAPIKey string
 	// Model name as understood by OpenRouter, e.g. "openai/gpt-4.1-mini".
 	Model string
+	// RouterModel is the cheaper model that classifies questions before any
+	// SQL is generated (default DefaultRouterModel).
+	RouterModel string
+
+	// MaxLookback bounds generated queries that have no time filter of their
+	// own to this far back (0: unbounded).
+	MaxLookback time.Duration
 
 	Logger *logrus.Logger
 }
 
+// DefaultRouterModel is the OpenRouter model that classifies questions when
+// AgentConfig.RouterModel is empty.
+const DefaultRouterModel = "openai/gpt-4.1-nano"
+
 // Agent provides NL→SQL over the swaps table using an LLM and ClickHouse.
 type Agent struct {
 	llm    llms.Model
+	router llms.Model // classifies questions (see Classify)
 	db     *sql.DB
 	logger *logrus.Logger
+
+	maxLookback time.Duration
 }
 
 // NewAgent creates a new Agent with its own ClickHouse and LLM clients.
@@ -50,6 +66,9 @@ func NewAgent(ctx context.Context, cfg AgentConfig) (*Agent, error) {
 		// Sensible default OpenRouter model (can be overridden by caller).
 		cfg.Model = "openai/gpt-4.1-mini"
 	}
+	if cfg.RouterModel == "" {
+		cfg.RouterModel = DefaultRouterModel
+	}
 
 	// Mask API key for logging (show first 8 chars)
 	maskedKey := cfg.OpenRouterAPIKey
@@ -63,14 +82,14 @@ func NewAgent(ctx context.Context, cfg AgentConfig) (*Agent, error) {
 	}).Debug("initializing OpenRouter LLM client")
 
 	// Initialise LLM backed by OpenRouter (OpenAI-compatible API).
-	llm, err := openai.New(
-		openai.WithToken(cfg.OpenRouterAPIKey),
-		openai.WithBaseURL("https://openrouter.ai/api/v1"),
-		openai.WithModel(cfg.Model),
-	)
+	llm, err := newOpenRouterLLM(cfg.OpenRouterAPIKey, cfg.Model)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OpenRouter LLM: %w", err)
 	}
+	router, err := newOpenRouterLLM(cfg.OpenRouterAPIKey, cfg.RouterModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenRouter router LLM: %w", err)
+	}
 
 	// Create ClickHouse *sql.DB using the stdlib wrapper.
 	db := clickhouse.OpenDB(&clickhouse.Options{
@@ -90,15 +109,28 @@ func NewAgent(ctx context.Context, cfg AgentConfig) (*Agent, error) {
 		"addr":     cfg.ClickHouseAddr,
 		"database": cfg.ClickHouseDatabase,
 		"model":    cfg.Model,
+		"router":   cfg.RouterModel,
 	}).Info("initialized AI agent")
 
 	return &Agent{
 		llm:    llm,
+		router: router,
 		db:     db,
 		logger: cfg.Logger,
+
+		maxLookback: cfg.MaxLookback,
 	}, nil
 }
 
+// newOpenRouterLLM creates a client for model on OpenRouter's OpenAI-compatible API.
+func newOpenRouterLLM(apiKey, model string) (llms.Model, error) {
+	return openai.New(
+		openai.WithToken(apiKey),
+		openai.WithBaseURL("https://openrouter.ai/api/v1"),
+		openai.WithModel(model),
+	)
+}
+
 // Close closes underlying resources.
 func (a *Agent) Close() error {
 	if a.db != nil {
@@ -112,43 +144,123 @@ func (a *Agent) Close() error {
 type AskResult struct {
 	SQL    string
 	Answer string
+	Usage  Usage // tokens billed by the LLM across the call
+	// Lookback is how far back the time-range guard bounded SQL that had no
+	// time filter; 0 when the query was run as generated.
+	Lookback time.Duration
+	// Estimate is what ClickHouse expects the SQL to read, for dry runs.
+	Estimate *Estimate
+}
+
+// AskOptions adjust a single Ask call.
+type AskOptions struct {
+	AllTime bool // run the generated SQL without the MaxLookback guard
+	SQLOnly bool // return the SQL without running or summarising it
+	DryRun  bool // like SQLOnly, with the Estimate of running it
+}
+
+// Estimate is ClickHouse's plan for a query and how much of the swaps table
+// it would read (EXPLAIN and EXPLAIN ESTIMATE), summed over the tables read.
+type Estimate struct {
+	Rows  uint64   // rows to read
+	Marks uint64   // index granules to read
+	Parts uint64   // data parts to read
+	Plan  []string // query plan, one step per line
+}
+
+// Usage counts the tokens an LLM billed, as reported by OpenRouter.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Add returns the sum of u and o.
+func (u Usage) Add(o Usage) Usage {
+	return Usage{PromptTokens: u.PromptTokens + o.PromptTokens, CompletionTokens: u.CompletionTokens + o.CompletionTokens}
 }
 
 // Ask takes a natural language question, generates SQL, executes it, and summarises the result.
-func (a *Agent) Ask(ctx context.Context, question string) (*AskResult, error) {
-	sqlQuery, err := a.generateSQL(ctx, question)
+// With SQLOnly or DryRun it stops after generating the SQL, so only one LLM
+// call is made and nothing is read from the swaps table.
+// The result is returned with the error as well, when the LLM was already
+// called, so callers can account for the tokens spent.
+func (a *Agent) Ask(ctx context.Context, question string, opts AskOptions) (*AskResult, error) {
+	res := &AskResult{}
+	sqlQuery, usage, err := a.generateSQL(ctx, question)
+	res.Usage = res.Usage.Add(usage)
 	if err != nil {
-		return nil, err
+		return res, err
+	}
+	if !opts.AllTime {
+		if guarded, ok := guardTimeRange(sqlQuery, a.maxLookback); ok {
+			a.logger.WithField("lookback", a.maxLookback).Debug("bounded generated SQL without a time filter")
+			sqlQuery, res.Lookback = guarded, a.maxLookback
+		}
+	}
+	res.SQL = sqlQuery
+
+	if opts.DryRun {
+		res.Estimate, err = a.explain(ctx, sqlQuery)
+		return res, err
+	}
+	if opts.SQLOnly {
+		return res, nil
 	}
 
 	rowsJSON, err := a.runQuery(ctx, sqlQuery)
 	if err != nil {
-		return nil, err
+		return res, err
 	}
 
-	answer, err := a.summariseResult(ctx, question, sqlQuery, rowsJSON)
+	answer, usage, err := a.summariseResult(ctx, question, sqlQuery, rowsJSON, res.Lookback)
+	res.Usage = res.Usage.Add(usage)
 	if err != nil {
-		return nil, err
+		return res, err
 	}
+	res.Answer = answer
+	return res, nil
+}
 
-	return &AskResult{
-		SQL:    sqlQuery,
-		Answer: answer,
-	}, nil
+// generate sends a single prompt to llm and returns the reply with its token usage.
+func generate(ctx context.Context, llm llms.Model, prompt string, options ...llms.CallOption) (string, Usage, error) {
+	msg := llms.MessageContent{
+		Role:  llms.ChatMessageTypeHuman,
+		Parts: []llms.ContentPart{llms.TextContent{Text: prompt}},
+	}
+	resp, err := llm.GenerateContent(ctx, []llms.MessageContent{msg}, options...)
+	if err != nil {
+		return "", Usage{}, err
+	}
+	if len(resp.Choices) == 0 {
+		return "", Usage{}, fmt.Errorf("empty response from model")
+	}
+	choice := resp.Choices[0]
+	return choice.Content, usageOf(choice.GenerationInfo), nil
+}
+
+// usageOf reads the token counts langchaingo's OpenAI client reports in
+// GenerationInfo.
+func usageOf(info map[string]any) Usage {
+	count := func(k string) int {
+		n, _ := info[k].(int)
+		return n
+	}
+	return Usage{PromptTokens: count("PromptTokens"), CompletionTokens: count("CompletionTokens")}
 }
 
-// generateSQL asks the LLM to produce a safe SELECT query over solana.swaps.
-func (a *Agent) generateSQL(ctx context.Context, question string) (string, error) {
+// generateSQL asks the LLM to produce a safe SELECT query over solana.swaps,
+// optionally joined with solana.tokens.
+func (a *Agent) generateSQL(ctx context.Context, question string) (string, Usage, error) {
 	prompt := fmt.Sprintf(`
 You are an expert ClickHouse SQL generator.
 
-Use ONLY the following table:
+Use ONLY the following tables:
 %s
 
 Rules:
 - Return a single SELECT query in ClickHouse SQL.
 - Do NOT include any explanation or comments, only the SQL.
-- The table is solana.swaps.
+- The main table is solana.swaps; join solana.tokens only to filter or label by token name or category.
 - Use timestamp for time filtering.
 - Use aggregate functions like sum, avg, count when appropriate.
 - If user asks for \"top\" or \"biggest\" something, use ORDER BY ... DESC and LIMIT.
@@ -158,23 +270,18 @@ User question:
 %s
 `, swapsSchemaDescription, question)
 
-	resp, err := llms.GenerateFromSinglePrompt(
-		ctx,
-		a.llm,
-		prompt,
-		llms.WithMaxTokens(512),
-	)
+	resp, usage, err := generate(ctx, a.llm, prompt, llms.WithMaxTokens(512))
 	if err != nil {
-		return "", fmt.Errorf("LLM SQL generation failed: %w", err)
+		return "", usage, fmt.Errorf("LLM SQL generation failed: %w", err)
 	}
 
 	sqlQuery := sanitizeSQL(resp)
 	if err := validateSQL(sqlQuery); err != nil {
-		return "", err
+		return "", usage, err
 	}
 
 	a.logger.WithField("sql", sqlQuery).Debug("generated SQL from question")
-	return sqlQuery, nil
+	return sqlQuery, usage, nil
 }
 
 // runQuery executes the generated SQL and encodes results as JSON.
@@ -220,8 +327,50 @@ func (a *Agent) runQuery(ctx context.Context, sqlQuery string) (string, error) {
 	return string(data), nil
 }
 
+// explain asks ClickHouse how it would run sqlQuery without running it.
+func (a *Agent) explain(ctx context.Context, sqlQuery string) (*Estimate, error) {
+	est := &Estimate{}
+	rows, err := a.db.QueryContext(ctx, "EXPLAIN ESTIMATE "+sqlQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate query: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var database, table string
+		var parts, nrows, marks uint64
+		if err := rows.Scan(&database, &table, &parts, &nrows, &marks); err != nil {
+			return nil, fmt.Errorf("failed to scan estimate: %w", err)
+		}
+		est.Parts += parts
+		est.Rows += nrows
+		est.Marks += marks
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("estimate iteration error: %w", err)
+	}
+
+	plan, err := a.db.QueryContext(ctx, "EXPLAIN "+sqlQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	defer plan.Close()
+	for plan.Next() {
+		var line string
+		if err := plan.Scan(&line); err != nil {
+			return nil, fmt.Errorf("failed to scan plan: %w", err)
+		}
+		est.Plan = append(est.Plan, line)
+	}
+	if err := plan.Err(); err != nil {
+		return nil, fmt.Errorf("plan iteration error: %w", err)
+	}
+	return est, nil
+}
+
 // summariseResult asks the LLM to answer the question given SQL + JSON results.
-func (a *Agent) summariseResult(ctx context.Context, question, sqlQuery, rowsJSON string) (string, error) {
+// A lookback other than 0 is mentioned so the answer does not claim to cover
+// all time.
+func (a *Agent) summariseResult(ctx context.Context, question, sqlQuery, rowsJSON string, lookback time.Duration) (string, Usage, error) {
 	prompt := fmt.Sprintf(`
 You are a helpful assistant analysing Solana DEX swap analytics.
 
@@ -240,18 +389,28 @@ Instructions:
 - Include key numbers (volumes, counts, prices) rounded reasonably.
 - Do not restate the raw JSON.
 `, question, sqlQuery, rowsJSON)
+	if lookback > 0 {
+		prompt += fmt.Sprintf("- The query only covered the last %s; say so instead of describing the numbers as all-time totals.\n", formatLookback(lookback))
+	}
 
-	resp, err := llms.GenerateFromSinglePrompt(
-		ctx,
-		a.llm,
-		prompt,
-		llms.WithMaxTokens(512),
-	)
+	resp, usage, err := generate(ctx, a.llm, prompt, llms.WithMaxTokens(512))
 	if err != nil {
-		return "", fmt.Errorf("LLM summarisation failed: %w", err)
+		return "", usage, fmt.Errorf("LLM summarisation failed: %w", err)
 	}
 
-	return strings.TrimSpace(resp), nil
+	return strings.TrimSpace(resp), usage, nil
+}
+
+// formatLookback writes whole days as days (90 days), anything else as a Go duration.
+func formatLookback(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d%day == 0 {
+		if d == day {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", d/day)
+	}
+	return d.String()
 }
 
 // sanitizeSQL strips code fences and trailing semicolons from the LLM output.
@@ -307,8 +466,9 @@ func validateSQL(s string) error {
 		return fmt.Errorf("multiple statements or semicolons are not allowed")
 	}
 
-	if !strings.Contains(upper, "FROM SWAPS") && !strings.Contains(upper, "FROM SOLANA.SWAPS") {
-		return fmt.Errorf("query must target solana.swaps table")
+	targets := []string{"FROM SWAPS", "FROM SOLANA.SWAPS", "FROM TOKENS", "FROM SOLANA.TOKENS"}
+	if !slices.ContainsFunc(targets, func(t string) bool { return strings.Contains(upper, t) }) {
+		return fmt.Errorf("query must target the solana.swaps or solana.tokens table")
 	}
 
 	return nil
@@ -0,0 +1,50 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// fakeLLM replies with reply and reports fixed token usage
+type fakeLLM struct {
+	reply string
+	calls int
+}
+
+func (f *fakeLLM) GenerateContent(_ context.Context, _ []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	f.calls++
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{
+		Content:        f.reply,
+		GenerationInfo: map[string]any{"PromptTokens": 120, "CompletionTokens": 30},
+	}}}, nil
+}
+
+func (f *fakeLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+func TestAskSQLOnly(t *testing.T) {
+	llm := &fakeLLM{reply: "```sql\nSELECT sum(amount_out) FROM solana.swaps;\n```"}
+	// No ClickHouse: SQLOnly must not touch the database
+	a := &Agent{llm: llm, logger: logrus.New(), maxLookback: 24 * time.Hour}
+
+	res, err := a.Ask(context.Background(), "total volume ever", AskOptions{SQLOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT sum(amount_out) FROM (SELECT * FROM solana.swaps WHERE timestamp >= now() - INTERVAL 86400 SECOND) AS swaps", res.SQL)
+	assert.Equal(t, 24*time.Hour, res.Lookback)
+	assert.Empty(t, res.Answer)
+	assert.Nil(t, res.Estimate)
+	assert.Equal(t, Usage{PromptTokens: 120, CompletionTokens: 30}, res.Usage)
+	assert.Equal(t, 1, llm.calls, "no summary")
+
+	res, err = a.Ask(context.Background(), "total volume ever", AskOptions{SQLOnly: true, AllTime: true})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT sum(amount_out) FROM solana.swaps", res.SQL)
+	assert.Zero(t, res.Lookback)
+}
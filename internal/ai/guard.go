@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// timeLowerBound matches a filter that bounds how far back a query reads:
+// timestamp >= ..., toDate(timestamp) > ..., block_time BETWEEN ..., or the
+// same written the other way round (... <= timestamp).
+var timeLowerBound = regexp.MustCompile(`(?i)\b(?:timestamp|block_time)\b\)*\s*(?:>=|>|=|\bBETWEEN\b|\bIN\b)|(?:<=|<)\s*(?:\w+\()*\b(?:timestamp|block_time)\b`)
+
+// swapsSource matches each read of the swaps table with its optional alias
+var swapsSource = regexp.MustCompile(`(?i)\b(FROM|JOIN)\s+((?:solana\.)?swaps)\b(\s+(?:AS\s+)?([A-Za-z_]\w*))?`)
+
+// notAlias are words that may follow a table name without being its alias
+var notAlias = map[string]bool{
+	"WHERE": true, "PREWHERE": true, "GROUP": true, "ORDER": true, "LIMIT": true, "HAVING": true,
+	"JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "CROSS": true, "ANY": true,
+	"ALL": true, "GLOBAL": true, "ASOF": true, "SEMI": true, "ANTI": true, "ARRAY": true, "ON": true,
+	"USING": true, "UNION": true, "SETTINGS": true, "FORMAT": true, "FINAL": true, "SAMPLE": true,
+	"WITH": true, "WINDOW": true, "QUALIFY": true, "OFFSET": true, "EXCEPT": true, "INTERSECT": true,
+}
+
+// guardTimeRange bounds queries over swaps that have no lower time filter to
+// the last lookback, so questions like "total volume ever" do not scan the
+// whole table. Every read of swaps is swapped for a subquery over the bounded
+// range, keeping its alias. It reports whether the query was changed.
+func guardTimeRange(sqlQuery string, lookback time.Duration) (string, bool) {
+	if lookback <= 0 || timeLowerBound.MatchString(sqlQuery) {
+		return sqlQuery, false
+	}
+	matches := swapsSource.FindAllStringSubmatchIndex(sqlQuery, -1)
+	if len(matches) == 0 {
+		return sqlQuery, false // tokens only
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		keyword, table := sqlQuery[m[2]:m[3]], sqlQuery[m[4]:m[5]]
+		alias, end := "", m[5]
+		if m[8] >= 0 && !notAlias[strings.ToUpper(sqlQuery[m[8]:m[9]])] {
+			alias, end = sqlQuery[m[8]:m[9]], m[1]
+		}
+		if alias == "" {
+			alias = "swaps"
+		}
+		b.WriteString(sqlQuery[last:m[0]])
+		fmt.Fprintf(&b, "%s (SELECT * FROM %s WHERE timestamp >= now() - INTERVAL %d SECOND) AS %s",
+			keyword, table, int64(lookback.Seconds()), alias)
+		last = end
+	}
+	b.WriteString(sqlQuery[last:])
+	return b.String(), true
+}
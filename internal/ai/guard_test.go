@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardTimeRange(t *testing.T) {
+	const day = 24 * time.Hour
+	bounded := "(SELECT * FROM solana.swaps WHERE timestamp >= now() - INTERVAL 86400 SECOND)"
+
+	tests := []struct {
+		name, in, out string
+	}{
+		{
+			name: "no filter",
+			in:   "SELECT sum(amount_out) FROM solana.swaps",
+			out:  "SELECT sum(amount_out) FROM " + bounded + " AS swaps",
+		},
+		{
+			name: "filter on other columns",
+			in:   "SELECT avg(price) FROM solana.swaps WHERE pair = 'SOL/USDC' GROUP BY pair",
+			out:  "SELECT avg(price) FROM " + bounded + " AS swaps WHERE pair = 'SOL/USDC' GROUP BY pair",
+		},
+		{
+			name: "alias kept",
+			in:   "SELECT t.category, count() FROM solana.swaps AS s JOIN solana.tokens FINAL AS t ON t.symbol = s.token_out GROUP BY t.category",
+			out:  "SELECT t.category, count() FROM " + bounded + " AS s JOIN solana.tokens FINAL AS t ON t.symbol = s.token_out GROUP BY t.category",
+		},
+		{
+			name: "bare alias",
+			in:   "SELECT max(s.timestamp) FROM solana.swaps s",
+			out:  "SELECT max(s.timestamp) FROM " + bounded + " AS s",
+		},
+		{
+			name: "unqualified table",
+			in:   "SELECT count() FROM swaps ORDER BY 1",
+			out:  "SELECT count() FROM (SELECT * FROM swaps WHERE timestamp >= now() - INTERVAL 86400 SECOND) AS swaps ORDER BY 1",
+		},
+	}
+	for _, tt := range tests {
+		out, changed := guardTimeRange(tt.in, day)
+		assert.True(t, changed, tt.name)
+		assert.Equal(t, tt.out, out, tt.name)
+	}
+
+	for _, in := range []string{
+		"SELECT count() FROM solana.swaps WHERE timestamp >= now() - INTERVAL 24 HOUR",
+		"SELECT count() FROM solana.swaps WHERE toDate(timestamp) > today() - 7",
+		"SELECT count() FROM solana.swaps s WHERE s.timestamp BETWEEN '2025-01-01' AND '2025-02-01'",
+		"SELECT count() FROM solana.swaps WHERE now() - INTERVAL 1 DAY <= timestamp",
+		"SELECT name FROM solana.tokens FINAL WHERE category = 'meme'",
+	} {
+		out, changed := guardTimeRange(in, day)
+		assert.False(t, changed, in)
+		assert.Equal(t, in, out)
+	}
+
+	out, changed := guardTimeRange("SELECT count() FROM solana.swaps", 0)
+	assert.False(t, changed, "guard off")
+	assert.Equal(t, "SELECT count() FROM solana.swaps", out)
+}
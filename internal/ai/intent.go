@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Intent is the kind of question asked, which decides how it is answered.
+type Intent string
+
+const (
+	IntentAnalytics  Intent = "analytics"    // needs SQL over the stored swaps
+	IntentPrice      Intent = "price"        // the current price of one token
+	IntentQuote      Intent = "quote"        // what a swap would return right now
+	IntentExecution  Intent = "execution"    // asks for a swap to be made
+	IntentOutOfScope Intent = "out_of_scope" // not about Solana DEX trading
+)
+
+// Classification is the router model's reading of a question.
+type Classification struct {
+	Intent Intent
+	Token  string // token symbol of a price question, e.g. "SOL"; "" otherwise
+	Usage  Usage
+}
+
+// Classify asks the router model what kind of question this is, so simple
+// questions skip SQL generation and the larger model. Replies the router gets
+// wrong or that do not parse are classified as analytics, which every
+// question can fall back to.
+func (a *Agent) Classify(ctx context.Context, question string) (*Classification, error) {
+	prompt := fmt.Sprintf(`
+You route questions sent to a Solana DEX swap analytics service.
+
+Classify the question into exactly one intent:
+- "price": the current or latest price of a single token, nothing else (e.g. "what is SOL trading at?")
+- "analytics": anything computed from historical swaps: volumes, averages, rankings, counts, trends, wallets, fees, DEXes
+- "quote": how much a swap of a given amount would return right now
+- "execution": a request to buy, sell or swap tokens
+- "out_of_scope": anything not about Solana tokens, swaps or DEX trading
+
+Reply with one JSON object and nothing else:
+{"intent": "<intent>", "token": "<token symbol for price questions, else empty>"}
+
+Question:
+%s
+`, question)
+
+	resp, usage, err := generate(ctx, a.router, prompt, llms.WithMaxTokens(64), llms.WithTemperature(0))
+	if err != nil {
+		return &Classification{Intent: IntentAnalytics, Usage: usage}, fmt.Errorf("LLM intent classification failed: %w", err)
+	}
+	cls := parseClassification(resp)
+	cls.Usage = usage
+	a.logger.WithField("intent", cls.Intent).WithField("token", cls.Token).Debug("classified question")
+	return cls, nil
+}
+
+// parseClassification reads the router's JSON reply, tolerating code fences
+// and surrounding text.
+func parseClassification(s string) *Classification {
+	cls := &Classification{Intent: IntentAnalytics}
+	start, end := strings.Index(s, "{"), strings.LastIndex(s, "}")
+	if start < 0 || end < start {
+		return cls
+	}
+	var reply struct {
+		Intent string `json:"intent"`
+		Token  string `json:"token"`
+	}
+	if err := json.Unmarshal([]byte(s[start:end+1]), &reply); err != nil {
+		return cls
+	}
+	switch intent := Intent(strings.ToLower(strings.TrimSpace(reply.Intent))); intent {
+	case IntentPrice:
+		token := strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(reply.Token), "$"))
+		if token == "" || strings.ContainsAny(token, " /") {
+			return cls // not a single token; let SQL answer it
+		}
+		cls.Intent, cls.Token = intent, token
+	case IntentAnalytics, IntentQuote, IntentExecution, IntentOutOfScope:
+		cls.Intent = intent
+	}
+	return cls
+}
@@ -0,0 +1,31 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClassification(t *testing.T) {
+	tests := []struct {
+		reply  string
+		intent Intent
+		token  string
+	}{
+		{`{"intent": "price", "token": "sol"}`, IntentPrice, "SOL"},
+		{"```json\n{\"intent\": \"price\", \"token\": \"$bonk\"}\n```", IntentPrice, "BONK"},
+		{`{"intent": "price", "token": ""}`, IntentAnalytics, ""},
+		{`{"intent": "price", "token": "SOL/USDC"}`, IntentAnalytics, ""},
+		{`{"intent": "Quote", "token": ""}`, IntentQuote, ""},
+		{`{"intent": "execution"}`, IntentExecution, ""},
+		{`Sure! {"intent": "out_of_scope", "token": ""}`, IntentOutOfScope, ""},
+		{`{"intent": "weather"}`, IntentAnalytics, ""},
+		{`analytics`, IntentAnalytics, ""},
+		{`{"intent": `, IntentAnalytics, ""},
+	}
+	for _, tt := range tests {
+		cls := parseClassification(tt.reply)
+		assert.Equal(t, tt.intent, cls.Intent, tt.reply)
+		assert.Equal(t, tt.token, cls.Token, tt.reply)
+	}
+}
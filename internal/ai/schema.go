@@ -1,6 +1,7 @@
 package ai
 
-// swapsSchemaDescription describes the ClickHouse schema used for NL→SQL prompting.
+// swapsSchemaDescription describes the ClickHouse schema used for NL→SQL
+// prompting: swaps, and the tokens table queries join for names and categories.
 //
 // Keeping it in sync with the actual ClickHouse table definition in init.sql.
 const swapsSchemaDescription = `
@@ -19,9 +20,38 @@ Columns:
   - fee        Float64       -- Protocol fee rate (e.g. 0.0025)
   - pool       String        -- Pool identifier (e.g. "RaydiumAMM")
   - dex        String        -- DEX name (e.g. "Raydium")
+  - wallet     String        -- Trader wallet (transaction fee payer); '' on swaps indexed before it was recorded
+  - fee_lamports       UInt64 -- Transaction fee paid in lamports (1 SOL = 1e9), base plus priority fee; 0 on swaps indexed before it was recorded
+  - priority_fee       UInt64 -- Part of fee_lamports above the base fee, i.e. what the trader paid to land faster
+  - compute_unit_price UInt64 -- Priority bid in micro-lamports per compute unit; 0 if none was set
+  - indexed_at DateTime64(3) -- When the indexer processed the swap; indexed_at - timestamp is the indexing delay; epoch 0 on swaps indexed before it was recorded
+  - finalized  Bool          -- true for swaps indexed at finalized commitment; later finality is in swap_finality (see notes)
+  - value_usd  Float64       -- Swap value in USD from a stablecoin leg or a recent USD price of either token; 0 if it could not be valued or on swaps indexed before it was recorded
 
 Notes:
   - Larger amount_out generally means larger volume in token_out.
   - For volume calculations you can SUM(amount_out) or SUM(amount_in) depending on the unit you care about.
+  - Landing cost is fee_lamports; average it over rows with fee_lamports > 0 to skip swaps indexed before fees were recorded.
   - Time filters should use timestamp, e.g. timestamp >= now() - INTERVAL 24 HOUR.
+  - Swaps whose fork was dropped stay in swaps but must be left out with
+    signature NOT IN (SELECT signature FROM swap_finality WHERE status = 'retracted').
+  - A swap is finalized when finalized is true or
+    signature IN (SELECT signature FROM swap_finality WHERE status = 'finalized').
+
+Table: tokens (one row per token label; always read it as tokens FINAL)
+
+Columns:
+  - symbol     String        -- Token label as stored in swaps.token_in / swaps.token_out
+  - mint       String        -- SPL mint address
+  - name       String        -- Token name, e.g. "USD Coin"; '' if unknown
+  - category   String        -- One of native, stablecoin, liquid-staking, wrapped, defi, meme, lp; '' if unknown
+  - uri        String        -- Metaplex metadata URI; '' for built-in tokens
+
+Notes:
+  - Tokens not listed in tokens show up in swaps as a shortened mint, e.g. "3HdP...prWQ".
+  - To filter swaps by token name or category, join on the symbol, e.g.
+    SELECT count() FROM swaps AS s
+    INNER JOIN (SELECT symbol FROM tokens FINAL WHERE category = 'meme') AS t ON t.symbol = s.token_out
+    WHERE s.timestamp >= now() - INTERVAL 1 DAY
+  - Match names case-insensitively, e.g. name ILIKE '%bonk%'.
 `
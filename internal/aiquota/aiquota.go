@@ -0,0 +1,129 @@
+// Package aiquota bounds what each API key may spend on /v1/ai: a request
+// rate and a monthly LLM cost budget. Both live in Redis, so every API
+// replica enforces the same limits.
+package aiquota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/ai"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/redis/go-redis/v9"
+)
+
+// gcra admits a request when the key's theoretical arrival time, pushed one
+// interval further, stays within burst intervals of now. It returns 0 when
+// the request is admitted, else the milliseconds until it would be.
+var gcra = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then tat = now end
+local next = tat + interval
+local wait = next - now - interval * burst
+if wait > 0 then return wait end
+redis.call('SET', KEYS[1], next, 'PX', next - now)
+return 0
+`)
+
+// Limiter allows each key rate requests per second, with bursts of burst
+type Limiter struct {
+	client   redis.Scripter
+	interval time.Duration
+	burst    int
+}
+
+// NewLimiter creates a limiter; rate must be > 0 and burst >= 1
+func NewLimiter(client redis.Scripter, rate float64, burst int) *Limiter {
+	return &Limiter{client: client, interval: time.Duration(float64(time.Second) / rate), burst: burst}
+}
+
+// Allow takes one request from key's allowance. When none is left it
+// returns false and how long to wait.
+func (l *Limiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	now := time.Now().UnixMilli()
+	wait, err := gcra.Run(ctx, l.client, []string{constants.RedisKeyAIRatePrefix + key},
+		now, l.interval.Milliseconds(), l.burst).Int64()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to check ai rate limit: %w", err)
+	}
+	if wait > 0 {
+		return false, time.Duration(wait) * time.Millisecond, nil
+	}
+	return true, 0, nil
+}
+
+// Pricing converts token usage to USD
+type Pricing struct {
+	PromptPerMTok     float64 // USD per million prompt tokens
+	CompletionPerMTok float64 // USD per million completion tokens
+}
+
+// Cost is the estimated USD price of u
+func (p Pricing) Cost(u ai.Usage) float64 {
+	return (float64(u.PromptTokens)*p.PromptPerMTok + float64(u.CompletionTokens)*p.CompletionPerMTok) / 1e6
+}
+
+// Budget caps the estimated LLM spend of each key per calendar month (UTC).
+// Spend is kept in micro-dollars, one counter per key and month.
+type Budget struct {
+	client  redis.Cmdable
+	limit   float64
+	pricing Pricing
+}
+
+// NewBudget creates a budget of limit USD per key and month
+func NewBudget(client redis.Cmdable, limit float64, pricing Pricing) *Budget {
+	return &Budget{client: client, limit: limit, pricing: pricing}
+}
+
+// Limit is the monthly budget of each key in USD
+func (b *Budget) Limit() float64 {
+	return b.limit
+}
+
+// Remaining returns what key may still spend this month in USD; it goes
+// negative when the last request overshot the budget
+func (b *Budget) Remaining(ctx context.Context, key string, now time.Time) (float64, error) {
+	spent, err := b.client.Get(ctx, budgetKey(key, now)).Result()
+	if errors.Is(err, redis.Nil) {
+		return b.limit, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read ai budget: %w", err)
+	}
+	micros, err := strconv.ParseInt(spent, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ai budget counter %q: %w", spent, err)
+	}
+	return b.limit - float64(micros)/1e6, nil
+}
+
+// Charge adds the estimated cost of u to key's spend this month and returns
+// what is left
+func (b *Budget) Charge(ctx context.Context, key string, u ai.Usage, now time.Time) (float64, error) {
+	k := budgetKey(key, now)
+	pipe := b.client.TxPipeline()
+	spent := pipe.IncrBy(ctx, k, int64(math.Ceil(b.pricing.Cost(u)*1e6)))
+	pipe.Expire(ctx, k, constants.AIBudgetRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to charge ai budget: %w", err)
+	}
+	return b.limit - float64(spent.Val())/1e6, nil
+}
+
+// Reset is when the budget of the month holding now starts over
+func Reset(now time.Time) time.Time {
+	y, m, _ := now.UTC().Date()
+	return time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func budgetKey(key string, now time.Time) string {
+	return constants.RedisKeyAIBudgetPrefix + now.UTC().Format("2006-01") + ":" + key
+}
@@ -0,0 +1,73 @@
+package aiquota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/ai"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/redistest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestRedis(t *testing.T) *redis.Client {
+	return redistest.Client(t, redistest.DBAIQuota)
+}
+
+func TestPricingCost(t *testing.T) {
+	p := Pricing{PromptPerMTok: 0.40, CompletionPerMTok: 1.60}
+	assert.InDelta(t, 0.0004+0.0008, p.Cost(ai.Usage{PromptTokens: 1000, CompletionTokens: 500}), 1e-12)
+	assert.Zero(t, p.Cost(ai.Usage{}))
+}
+
+func TestReset(t *testing.T) {
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), Reset(time.Date(2025, 1, 31, 23, 59, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Reset(time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+	l := NewLimiter(setupTestRedis(t), 0.5, 2)
+
+	for i := range 2 {
+		ok, _, err := l.Allow(ctx, "key:a")
+		require.NoError(t, err)
+		assert.True(t, ok, "burst request %d", i)
+	}
+	ok, wait, err := l.Allow(ctx, "key:a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.InDelta(t, 2*time.Second, wait, float64(100*time.Millisecond))
+
+	ok, _, err = l.Allow(ctx, "key:b")
+	require.NoError(t, err)
+	assert.True(t, ok, "keys are limited separately")
+}
+
+func TestBudget(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	b := NewBudget(setupTestRedis(t), 1, Pricing{PromptPerMTok: 1, CompletionPerMTok: 2})
+
+	left, err := b.Remaining(ctx, "key:a", now)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, left)
+
+	left, err = b.Charge(ctx, "key:a", ai.Usage{PromptTokens: 200_000, CompletionTokens: 100_000}, now)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.6, left, 1e-9)
+
+	left, err = b.Charge(ctx, "key:a", ai.Usage{CompletionTokens: 250_000}, now)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.1, left, 1e-9)
+
+	left, err = b.Remaining(ctx, "key:b", now)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, left, "keys have separate budgets")
+
+	left, err = b.Remaining(ctx, "key:a", now.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, left, "budget starts over each month")
+}
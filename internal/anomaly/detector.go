@@ -0,0 +1,191 @@
+// Package anomaly flags pairs whose hourly volume or trade count spikes far
+// above their own recent history. Each pair keeps a rolling baseline of its
+// last completed hours; the current hour is compared against it as swaps
+// arrive, so a spike is reported while it happens rather than after the hour.
+package anomaly
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// minStdDevFraction floors the standard deviation at this fraction of the
+// mean, so a nearly flat baseline does not flag ordinary wiggles
+const minStdDevFraction = 0.1
+
+// Publisher receives detected anomalies; *cache.RedisCache implements it
+type Publisher interface {
+	PublishAnomaly(ctx context.Context, a *models.Anomaly) error
+}
+
+// Config tunes a Detector
+type Config struct {
+	StdDevs       float64 // how far above the mean a spike must be (default constants.AnomalyStdDevs)
+	BaselineHours int     // completed hours kept per pair (default constants.AnomalyBaselineHours)
+	MinHours      int     // pairs with fewer baseline hours are not judged (default constants.AnomalyMinHours)
+	Publisher     Publisher
+	Logger        *logrus.Logger
+}
+
+// hourTotals is what a pair traded in one hour
+type hourTotals struct {
+	volume, trades float64
+}
+
+func (h hourTotals) metric(name string) float64 {
+	if name == models.AnomalyTrades {
+		return h.trades
+	}
+	return h.volume
+}
+
+// series is one pair's current hour and its baseline, oldest hour first
+type series struct {
+	hour     time.Time
+	current  hourTotals
+	history  []hourTotals
+	reported map[string]bool // metrics already flagged this hour
+}
+
+// Detector keeps per-pair hourly baselines in memory; they are rebuilt from
+// the live feed after a restart
+type Detector struct {
+	cfg Config
+	now func() time.Time
+
+	mu    sync.Mutex
+	pairs map[string]*series
+}
+
+// NewDetector creates a detector, filling in defaults
+func NewDetector(cfg Config) *Detector {
+	if cfg.StdDevs <= 0 {
+		cfg.StdDevs = constants.AnomalyStdDevs
+	}
+	if cfg.BaselineHours <= 0 {
+		cfg.BaselineHours = constants.AnomalyBaselineHours
+	}
+	if cfg.MinHours <= 0 {
+		cfg.MinHours = constants.AnomalyMinHours
+	}
+	cfg.MinHours = min(cfg.MinHours, cfg.BaselineHours)
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	return &Detector{cfg: cfg, now: time.Now, pairs: make(map[string]*series)}
+}
+
+// Observe adds the swap to its pair's current hour and publishes an anomaly
+// the first time in that hour a metric crosses its threshold. Swaps older
+// than the pair's current hour are ignored.
+func (d *Detector) Observe(ctx context.Context, swap *models.SwapEvent) error {
+	if swap.Pair == "" {
+		return nil
+	}
+	at := swap.Timestamp
+	if at.IsZero() {
+		at = d.now()
+	}
+	found := d.add(swap.Pair, at.UTC().Truncate(time.Hour), swap.AmountIn)
+
+	var first error
+	for _, a := range found {
+		d.cfg.Logger.WithFields(logrus.Fields{
+			"pair":    a.Pair,
+			"metric":  a.Metric,
+			"value":   a.Value,
+			"z_score": strconv.FormatFloat(a.ZScore, 'f', 1, 64),
+		}).Warn("volume anomaly")
+		if d.cfg.Publisher == nil {
+			continue
+		}
+		if err := d.cfg.Publisher.PublishAnomaly(ctx, a); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// add counts one swap and returns the anomalies it reveals
+func (d *Detector) add(pair string, hour time.Time, amount float64) []*models.Anomaly {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.pairs[pair]
+	if s == nil {
+		s = &series{hour: hour, reported: make(map[string]bool)}
+		d.pairs[pair] = s
+	}
+	if hour.Before(s.hour) {
+		return nil
+	}
+	if hour.After(s.hour) {
+		d.roll(s, hour)
+	}
+	s.current.volume += amount
+	s.current.trades++
+
+	if len(s.history) < d.cfg.MinHours {
+		return nil
+	}
+	var found []*models.Anomaly
+	for _, metric := range []string{models.AnomalyVolume, models.AnomalyTrades} {
+		if s.reported[metric] {
+			continue
+		}
+		mean, sd := meanStdDev(s.history, metric)
+		floor := math.Max(sd, mean*minStdDevFraction)
+		value := s.current.metric(metric)
+		if floor == 0 || value <= mean+d.cfg.StdDevs*floor {
+			continue
+		}
+		s.reported[metric] = true
+		found = append(found, &models.Anomaly{
+			Pair:       pair,
+			Metric:     metric,
+			Hour:       s.hour,
+			Value:      value,
+			Mean:       mean,
+			StdDev:     sd,
+			ZScore:     (value - mean) / floor,
+			Baseline:   len(s.history),
+			DetectedAt: d.now(),
+		})
+	}
+	return found
+}
+
+// roll closes the pair's current hour, recording hours without swaps as
+// zeros, and starts hour
+func (d *Detector) roll(s *series, hour time.Time) {
+	s.history = append(s.history, s.current)
+	gap := int(hour.Sub(s.hour)/time.Hour) - 1
+	for range min(gap, d.cfg.BaselineHours) {
+		s.history = append(s.history, hourTotals{})
+	}
+	if n := len(s.history) - d.cfg.BaselineHours; n > 0 {
+		s.history = append(s.history[:0], s.history[n:]...)
+	}
+	s.hour, s.current = hour, hourTotals{}
+	clear(s.reported)
+}
+
+// meanStdDev returns the population mean and standard deviation of a metric
+func meanStdDev(hours []hourTotals, metric string) (mean, sd float64) {
+	for _, h := range hours {
+		mean += h.metric(metric)
+	}
+	mean /= float64(len(hours))
+	for _, h := range hours {
+		d := h.metric(metric) - mean
+		sd += d * d
+	}
+	return mean, math.Sqrt(sd / float64(len(hours)))
+}
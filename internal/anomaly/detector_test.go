@@ -0,0 +1,72 @@
+package anomaly
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	found []*models.Anomaly
+}
+
+func (r *recorder) PublishAnomaly(_ context.Context, a *models.Anomaly) error {
+	r.found = append(r.found, a)
+	return nil
+}
+
+// trade sends n swaps of amount each for SOL/USDC at hour
+func trade(t *testing.T, d *Detector, hour time.Time, n int, amount float64) {
+	t.Helper()
+	for range n {
+		require.NoError(t, d.Observe(context.Background(), &models.SwapEvent{Pair: "SOL/USDC", AmountIn: amount, Timestamp: hour.Add(time.Minute)}))
+	}
+}
+
+func TestDetectorFlagsSpikes(t *testing.T) {
+	pub := &recorder{}
+	d := NewDetector(Config{StdDevs: 3, BaselineHours: 24, MinHours: 4, Publisher: pub})
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	// four quiet hours of 10 trades of 1 SOL (give or take) form the baseline
+	for h := range 4 {
+		trade(t, d, start.Add(time.Duration(h)*time.Hour), 10+h%2, 1)
+	}
+	hour := start.Add(4 * time.Hour)
+	trade(t, d, hour, 5, 1)
+	assert.Empty(t, pub.found)
+
+	// one whale trade spikes volume but not the trade count
+	trade(t, d, hour, 1, 100)
+	require.Len(t, pub.found, 1)
+	a := pub.found[0]
+	assert.Equal(t, models.AnomalyVolume, a.Metric)
+	assert.Equal(t, hour, a.Hour)
+	assert.InDelta(t, 105, a.Value, 1e-9)
+	assert.InDelta(t, 10.5, a.Mean, 1e-9)
+	assert.Equal(t, 4, a.Baseline)
+
+	// reported once per hour; a burst of trades then flags the count
+	trade(t, d, hour, 20, 0.01)
+	require.Len(t, pub.found, 2)
+	assert.Equal(t, models.AnomalyTrades, pub.found[1].Metric)
+}
+
+func TestDetectorNeedsBaseline(t *testing.T) {
+	pub := &recorder{}
+	d := NewDetector(Config{StdDevs: 3, BaselineHours: 24, MinHours: 4, Publisher: pub})
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	trade(t, d, start, 10, 1)
+	trade(t, d, start.Add(time.Hour), 1000, 1)
+	assert.Empty(t, pub.found, "one hour of history is not a baseline")
+
+	// skipped hours count as zero, and late swaps are ignored
+	trade(t, d, start.Add(6*time.Hour), 1, 1)
+	trade(t, d, start, 1000, 1)
+	assert.Len(t, d.pairs["SOL/USDC"].history, 6)
+}
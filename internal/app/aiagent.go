@@ -0,0 +1,144 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/ai"
+	"github.com/sirupsen/logrus"
+)
+
+// AgentOptions are the settings of the AI agent CLI
+type AgentOptions struct {
+	ConfigPath string
+	Query      string // run a single natural language query and exit; empty starts a REPL
+	Model      string // OpenRouter model name (default AI_MODEL)
+	AllTime    bool   // skip the AI_MAX_LOOKBACK guard on queries without a time filter
+	SQLOnly    bool   // print the generated SQL without running it
+	DryRun     bool   // print the SQL with ClickHouse's plan and estimate instead of running it
+}
+
+// RunAIAgent answers natural language questions about the indexed swaps,
+// once for Query or interactively
+func RunAIAgent(opts AgentOptions) {
+	logger := NewLogger("2006-01-02 15:04:05")
+	cfg, _ := Bootstrap(opts.ConfigPath, logger, logrus.InfoLevel)
+
+	if cfg.OpenRouterAPIKey == "" {
+		logger.Fatal("OPENROUTER_API_KEY is required for the AI agent. Please set it in your environment or config.")
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = cfg.AIModel
+	}
+
+	// Context + signals
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		fmt.Println("\nShutting down AI agent...")
+		cancel()
+	}()
+
+	// Agent
+	agent, err := ai.NewAgent(ctx, ai.AgentConfig{
+		ClickHouseAddr:     cfg.ClickHouseAddr,
+		ClickHouseDatabase: cfg.ClickHouseDatabase,
+		ClickHouseUsername: cfg.ClickHouseUsername,
+		ClickHousePassword: cfg.ClickHousePassword,
+		OpenRouterAPIKey:   cfg.OpenRouterAPIKey,
+		Model:              model,
+		MaxLookback:        cfg.AIMaxLookback,
+		Logger:             logger,
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create AI agent")
+	}
+	defer agent.Close()
+
+	// Single-shot mode
+	askOpts := ai.AskOptions{AllTime: opts.AllTime, SQLOnly: opts.SQLOnly, DryRun: opts.DryRun}
+	if opts.Query != "" {
+		if err := runSingle(ctx, agent, opts.Query, askOpts); err != nil {
+			logger.WithError(err).Fatal("query failed")
+		}
+		return
+	}
+
+	// REPL mode
+	runREPL(ctx, agent, askOpts)
+}
+
+func runSingle(ctx context.Context, agent *ai.Agent, q string, opts ai.AskOptions) error {
+	res, err := agent.Ask(ctx, q, opts)
+	if err != nil {
+		return err
+	}
+
+	// Bare SQL, so it can be piped into clickhouse-client
+	if opts.SQLOnly && !opts.DryRun {
+		fmt.Println(res.SQL)
+		return nil
+	}
+	printResult(res)
+	return nil
+}
+
+// printResult prints the SQL with the answer, or with the estimate of a dry run
+func printResult(res *ai.AskResult) {
+	fmt.Printf("SQL:\n%s\n\n", res.SQL)
+	if e := res.Estimate; e != nil {
+		fmt.Printf("Estimate: %d rows, %d marks, %d parts\n\n", e.Rows, e.Marks, e.Parts)
+		fmt.Printf("Plan:\n%s\n", strings.Join(e.Plan, "\n"))
+		return
+	}
+	if res.Answer != "" {
+		fmt.Printf("Answer:\n%s\n", res.Answer)
+	}
+}
+
+func runREPL(ctx context.Context, agent *ai.Agent, opts ai.AskOptions) {
+	fmt.Println("Solana Swap AI Agent (NL → ClickHouse SQL)")
+	fmt.Println("Type your question and press Enter. Empty line to exit.")
+	fmt.Println()
+
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		fmt.Print("> ")
+		q, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Println("error reading input:", err)
+			return
+		}
+		q = strings.TrimSpace(q)
+		if q == "" {
+			fmt.Println("bye")
+			return
+		}
+
+		// Short cooldown to avoid hammering the LLM if user spams enter.
+		time.Sleep(200 * time.Millisecond)
+
+		res, err := agent.Ask(ctx, q, opts)
+		if err != nil {
+			fmt.Println("error:", err)
+			continue
+		}
+
+		fmt.Println()
+		printResult(res)
+		fmt.Println()
+	}
+}
@@ -0,0 +1,286 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/indexer"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/server"
+	"github.com/sirupsen/logrus"
+)
+
+// Services that can be run in this process
+const (
+	serviceIndexer   = "indexer"
+	serviceAPI       = "api"
+	serviceArb       = "arb"
+	serviceAnomalies = "anomalies"
+	serviceMEV       = "mev"
+	serviceTicker    = "ticker"
+	serviceWebhooks  = "webhooks"
+	serviceDigests   = "digests"
+)
+
+// ParseServices turns "indexer,api" into a set, rejecting unknown names
+func ParseServices(s string) (map[string]bool, error) {
+	out := make(map[string]bool)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "":
+			continue
+		case serviceIndexer, serviceAPI, serviceArb, serviceAnomalies, serviceMEV, serviceTicker, serviceWebhooks, serviceDigests:
+			out[name] = true
+		default:
+			return nil, fmt.Errorf("unknown service %q (want %s, %s, %s, %s, %s, %s, %s or %s)",
+				name, serviceIndexer, serviceAPI, serviceArb, serviceAnomalies, serviceMEV, serviceTicker, serviceWebhooks, serviceDigests)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no services selected")
+	}
+	return out, nil
+}
+
+// RunAll runs the indexer (stream provider + processing pipeline) and the HTTP
+// API in one process, sharing a single Redis connection pool and flags store,
+// for small deployments that don't want a binary per service. servicesList is a
+// comma-separated subset of "indexer,api,arb,anomalies,mev,ticker,webhooks,digests".
+func RunAll(configPath, servicesList string) {
+	logger := NewLogger("2006-01-02 15:04:05")
+
+	services, err := ParseServices(servicesList)
+	if err != nil {
+		logger.WithError(err).Fatal("invalid services")
+	}
+
+	cfg, secretStore := Bootstrap(configPath, logger, logrus.InfoLevel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	// One Redis client for the cache, pub/sub, stream, flags and config reloads
+	redisCfg := redisConfig(cfg)
+	redisCfg.Logger = logging.Module(logger, logging.ModuleCache)
+	rclient := cache.NewRedisClient(redisCfg)
+	if err := rclient.Ping(ctx).Err(); err != nil {
+		logger.WithError(err).Fatal("failed to connect to Redis")
+	}
+	redisCache, err := cache.NewSharedRedisCache(rclient, redisCfg)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create Redis cache")
+	}
+
+	flagStore, err := flags.NewStore(rclient)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create flags store")
+	}
+	flagStore.SetHistoryLimit(cfg.FlagsHistoryLimit)
+	watchLogLevels(ctx, flagStore, logger) // log.level.<module>
+
+	// A single reloader serves every service (SIGHUP or POST /v1/admin/config/reload)
+	reloader := config.NewReloader(configPath, cfg, logger)
+
+	var (
+		wg     sync.WaitGroup
+		errCh  = make(chan error, len(services))
+		idx    *indexer.Indexer
+		srv    *server.Server
+		stopAI = func() {}
+	)
+
+	if services[serviceIndexer] {
+		clickhouseStore, err := newClickHouseStore(ctx, cfg, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("failed to connect to ClickHouse")
+		}
+		warmStartCache(ctx, cfg, redisCache, clickhouseStore, logger)
+		sinks, err := indexer.SinksFromConfig(cfg, indexer.SinkDeps{Cache: redisCache, Store: clickhouseStore})
+		if err != nil {
+			logger.WithError(err).Fatal("failed to create indexer sinks")
+		}
+		finality, err := indexer.FinalityTrackerFromConfig(cfg, clickhouseStore, redisCache, logging.Module(logger, logging.ModuleIndexer))
+		if err != nil {
+			logger.WithError(err).Fatal("failed to create finality tracker")
+		}
+		// Mints outside the built-in token list get their Metaplex symbol (INDEXER_REGISTER_TOKENS)
+		registry, err := indexer.NewTokenRegistry(ctx, cfg, redisCache, clickhouseStore, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("failed to create token registry")
+		}
+		if registry != nil {
+			go registry.Run(ctx)
+		}
+		idx = indexer.New(indexer.Config{
+			Cache:       redisCache,
+			Store:       clickhouseStore,
+			DeadLetters: redisCache,
+			Logger:      logging.Module(logger, logging.ModuleIndexer),
+			Provider:    cfg.StreamProvider,      // STREAM_PROVIDER
+			Instance:    indexer.InstanceID(cfg), // INDEXER_INSTANCE_ID
+
+			DrainTimeout:   cfg.DrainTimeout,              // INDEXER_DRAIN_TIMEOUT
+			Filter:         indexer.FilterFromConfig(cfg), // INDEXER_FILTER_*
+			DedupWindow:    cfg.DedupWindow,               // INDEXER_DEDUP_WINDOW
+			Processors:     indexer.Enrichers(registry),   // token labels, then USD value
+			DisabledStages: cfg.DisabledStages,            // INDEXER_DISABLED_STAGES
+			Sinks:          sinks,                         // INDEXER_SINKS
+			Finality:       finality,                      // INDEXER_FINALITY_INTERVAL
+		})
+
+		// With INDEXER_LEADER_ELECTION each program is polled by one replica at a time,
+		// resuming from the checkpoint the previous leader saved in Redis
+		elector := indexer.NewElector(cfg, rclient, logger)
+		if elector != nil {
+			go elector.Run(ctx)
+			logger.WithField("instance", elector.ID()).Info("leader election enabled")
+		}
+		poller, err := indexer.NewPoller(cfg, indexer.PollerOptions{
+			Checkpoints: redisCache,
+			Elector:     elector,
+			FailedSwaps: clickhouseStore, // INDEXER_RECORD_FAILED_SWAPS
+			Decimals:    redisCache,
+			Logger:      logging.Module(logger, logging.ModuleStream),
+		})
+		if err != nil {
+			logger.WithError(err).Fatal("failed to create poller")
+		}
+		programs := indexer.NewPrograms(cfg.ProgramAddresses, redisCache, poller, elector, logger)
+		programs.Refresh(ctx, cfg.ProgramAddresses)
+		indexer.WatchFlags(ctx, flagStore, poller, idx, logger)
+		supervisor := indexer.NewSupervisor(cfg, poller, logging.Module(logger, logging.ModuleStream))
+		go indexer.NewStatusReporter(indexer.InstanceID(cfg), poller).WithStream(supervisor).Run(ctx, redisCache, constants.IndexerStatusInterval, logger)
+		reloader.OnReload(indexer.ReloadHook(poller, programs))
+		reloader.OnReload(indexer.FilterReloadHook(idx))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := idx.Run(ctx, supervisor); err != nil {
+				errCh <- fmt.Errorf("indexer: %w", err)
+			}
+		}()
+		logger.WithFields(logrus.Fields{
+			"provider": cfg.StreamProvider,
+			"interval": cfg.PollInterval,
+		}).Info("indexer started")
+	}
+
+	if services[serviceAPI] {
+		srv, stopAI = NewAPIServer(ctx, cfg, redisCache, flagStore, rclient, secretStore, reloader, logger)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errCh <- fmt.Errorf("api: %w", err)
+			}
+		}()
+		logger.WithField("addr", cfg.APIAddr).Info("api server started")
+	}
+
+	// Live-feed detectors each subscribe to swaps:live
+	for name, build := range liveDetectors {
+		if !services[name] {
+			continue
+		}
+		c := build(ctx, cfg, redisCache, logger)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Run(ctx, rclient); err != nil && ctx.Err() == nil {
+				errCh <- fmt.Errorf("%s: %w", name, err)
+			}
+		}()
+	}
+
+	if services[serviceMEV] {
+		mevStore, err := newClickHouseStore(ctx, cfg, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("failed to connect to ClickHouse")
+		}
+		defer mevStore.Close()
+		scanner := newMEVScanner(cfg, mevStore, redisCache, logger)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := scanner.Run(ctx); err != nil {
+				errCh <- fmt.Errorf("mev: %w", err)
+			}
+		}()
+	}
+
+	if services[serviceDigests] {
+		digestStore, err := newClickHouseStore(ctx, cfg, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("failed to connect to ClickHouse")
+		}
+		defer digestStore.Close()
+		digester := newDigester(cfg, digestStore, redisCache, logger)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			digester.Run(ctx)
+		}()
+	}
+
+	go reloader.Run(ctx, rclient)
+
+	logger.WithFields(logrus.Fields{"app_env": cfg.AppEnv, "services": servicesList}).Info("all services running, press Ctrl+C to stop")
+
+	// Stop everything when signalled or when any service fails
+	select {
+	case <-sigCh:
+		logger.Info("shutting down gracefully")
+	case err := <-errCh:
+		logger.WithError(err).Error("service failed, shutting down")
+	}
+	cancel() // the indexer stops pulling and drains its in-flight swap (INDEXER_DRAIN_TIMEOUT)
+	if srv != nil {
+		if err := srv.Shutdown(context.Background()); err != nil {
+			logger.WithError(err).Warn("api shutdown")
+		}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		logger.Info("all services stopped")
+	case <-sigCh:
+		logger.Warn("second signal, exiting without draining")
+		return
+	}
+	stopAI()
+
+	// Shared connections are closed once, after every service has stopped
+	logger.Info("closing connections")
+	if idx != nil {
+		if err := idx.Close(); err != nil { // closes the shared Redis client too
+			logger.WithError(err).Error("error closing connections")
+		}
+	} else if err := rclient.Close(); err != nil {
+		logger.WithError(err).Error("error closing connections")
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/anomaly"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/consumer"
+	"github.com/sirupsen/logrus"
+)
+
+// newAnomalyConsumer builds the volume anomaly detector from the ANOMALY_*
+// settings and a consumer that feeds it every swap on swaps:live. Anomalies
+// go to alerts:anomalies, where `ssi subscriber --consumers` routes can
+// forward them to webhooks, and to the list read by GET /v1/anomalies.
+func newAnomalyConsumer(cfg *config.Config, publisher anomaly.Publisher, logger *logrus.Logger) *consumer.Consumer {
+	detector := anomaly.NewDetector(anomaly.Config{
+		StdDevs:       cfg.AnomalyStdDevs,
+		BaselineHours: cfg.AnomalyBaselineHours,
+		MinHours:      cfg.AnomalyMinHours,
+		Publisher:     publisher,
+		Logger:        logger,
+	})
+
+	c := consumer.New(consumer.Config{Workers: 1, ReorderWindow: cfg.ConsumerReorderWindow, Logger: logger})
+	c.Handle(constants.PubSubChannelSwaps, func(ctx context.Context, msg *consumer.Message) error {
+		if msg.Swap == nil {
+			return nil
+		}
+		return detector.Observe(ctx, msg.Swap)
+	})
+	logger.WithFields(logrus.Fields{
+		"stddevs":        cfg.AnomalyStdDevs,
+		"baseline_hours": cfg.AnomalyBaselineHours,
+	}).Info("anomaly detector started")
+	return c
+}
+
+// RunAnomalies runs the volume anomaly detector until SIGINT/SIGTERM
+func RunAnomalies(configPath string) {
+	runLiveConsumer(configPath, "anomaly detector", liveDetectors[serviceAnomalies])
+}
@@ -0,0 +1,394 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/ai"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/aiquota"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/grpcapi"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/idempotency"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/jupiter"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/orca"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/secrets"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/server"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/wallet"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// NewAPIServer wires the HTTP API onto the shared cache, flags store and Redis
+// client. The swap engine, if enabled, picks up risk limit changes through
+// reloader. The returned func closes the AI agent and swap engine once the
+// server has stopped.
+func NewAPIServer(ctx context.Context, cfg *config.Config, primary *cache.RedisCache, flagStore *flags.Store, rclient *redis.Client, secretStore *secrets.Manager, reloader *config.Reloader, logger *logrus.Logger) (*server.Server, func()) {
+	// Reads fall back to an in-memory copy of the last results during Redis outages
+	swapCache := cache.NewFallbackCache(primary, cache.NewMemoryCache(cfg.MaxRecentSwaps, cfg.PriceTTL), logging.Module(logger, logging.ModuleCache))
+
+	aiBase := ai.AgentConfig{
+		ClickHouseAddr:     cfg.ClickHouseAddr,
+		ClickHouseDatabase: cfg.ClickHouseDatabase,
+		ClickHouseUsername: cfg.ClickHouseUsername,
+		ClickHousePassword: cfg.ClickHousePassword,
+		OpenRouterAPIKey:   cfg.OpenRouterAPIKey,
+		Model:              cfg.AIModel,
+		RouterModel:        cfg.AIRouterModel,
+		MaxLookback:        cfg.AIMaxLookback,
+		Logger:             logging.Module(logger, logging.ModuleAI),
+	}
+
+	var agent *ai.Agent
+	if cfg.OpenRouterAPIKey != "" {
+		a, err := ai.NewAgent(ctx, aiBase)
+		if err != nil {
+			logger.WithError(err).Warn("failed to initialize ai agent")
+		} else {
+			agent = a
+		}
+	}
+
+	jup := newJupiterClient(cfg)
+
+	// Simulates and relays transactions API clients build and sign themselves
+	txWallet := newTxWallet(cfg, logger)
+	h := &server.Handlers{
+		Cache:        swapCache,
+		Flags:        flagStore,
+		AI:           agent,
+		AIBaseConfig: aiBase,
+		DevMode:      cfg.DevMode,
+		Logger:       logging.Module(logger, logging.ModuleAPI),
+		Jupiter:      jup,
+		Reloads:      config.NewReloadPublisher(rclient),
+		Indexers:     primary,
+		Programs:     primary,
+		Arb:          primary,
+		Markets:      primary,
+		Anomalies:    primary,
+		Idempotency:  idempotency.NewStore(rclient, cfg.IdempotencyTTL),
+
+		PriceStaleAfter: cfg.PriceStaleAfter,
+		MaxSlotLag:      cfg.ReadyMaxSlotLag,
+		MaxRecentSwaps:  cfg.MaxRecentSwaps,
+		ExportMaxRows:   cfg.ExportMaxRows,
+
+		Subscriptions:    primary,
+		SubscriptionsMax: cfg.SubscriptionsMax, // SUBSCRIPTIONS_MAX_PER_KEY
+		Webhooks:         webhookPolicy(cfg),   // WEBHOOK_ALLOWED_HOSTS, WEBHOOK_ALLOW_PRIVATE
+
+		// Explains lookups of signatures that were never indexed
+		Chain: rpc.NewClient(rpc.ClientConfig{
+			BaseURL:      cfg.RPCUrl,
+			Timeout:      cfg.HTTPTimeout,
+			MaxRetries:   cfg.MaxRetries,
+			RetryBackoff: cfg.RetryBackoff,
+			Logger:       logger,
+		}),
+		Simulator: txWallet,
+		Relay:     txWallet,
+	}
+
+	if cfg.ResponseCacheTTL > 0 || cfg.WalletStatsCacheTTL > 0 || cfg.MarketsCacheTTL > 0 {
+		h.Responses = primary
+	}
+	if cfg.JupiterQuoteCacheTTL > 0 {
+		h.Quotes = jupiter.NewQuoteCache(rclient, cfg.JupiterQuoteCacheTTL)
+	}
+
+	// /v1/ai limits are kept in Redis so every replica charges the same key
+	h.AIRate = aiquota.NewLimiter(rclient, cfg.AIRateLimit, cfg.AIRateBurst)
+	if cfg.AIMonthlyBudgetUSD > 0 {
+		h.AIBudget = aiquota.NewBudget(rclient, cfg.AIMonthlyBudgetUSD, aiquota.Pricing{
+			PromptPerMTok:     cfg.AIPromptPricePerMTok,
+			CompletionPerMTok: cfg.AICompletionPricePerMTok,
+		})
+	}
+
+	// Wallet profiles, MEV and fee stats and GraphQL read ClickHouse; the rest of the API works without it
+	cctx, ccancel := context.WithTimeout(ctx, 5*time.Second)
+	analytics, err := newClickHouseStore(cctx, cfg, logger)
+	ccancel()
+	if err != nil {
+		logger.WithError(err).Warn("ClickHouse unavailable, /v1/wallets, /v1/mev, /v1/fees and /graphql disabled")
+	} else {
+		h.Wallets = analytics
+		h.MEV = analytics
+		h.Fees = analytics
+		h.Listings = analytics
+		h.SwapStore = analytics
+		h.Exports = analytics
+		h.Explorer = analytics
+		warmStartCache(ctx, cfg, primary, analytics, logger)
+	}
+
+	// On-chain execution over HTTP is opt-in (SWAP_API_ENABLED)
+	var engine *swapengine.Engine
+	if cfg.SwapAPIEnabled {
+		e, err := swapengine.NewEngineFromEnv(logging.Module(logger, logging.ModuleSwapEngine))
+		if err != nil {
+			logger.WithError(err).Warn("failed to initialize swap engine, /v1/swap/execute disabled")
+		} else {
+			engine = e
+			h.Swaps = e
+			h.Pools = e
+			h.Risk = e
+			h.Routes = e
+			logPoolSummary(logger, e.PoolLoadSummary())
+			go reloadPoolsOnSIGHUP(ctx, e, logger)
+			reloader.OnReload(swapengine.ReloadHook(e, logging.Module(logger, logging.ModuleSwapEngine)))
+		}
+	}
+
+	// Rebuild the AI agent when the secret store rotates its credentials
+	if secretStore != nil {
+		go secretStore.Run(ctx, func(changed []string) {
+			next, err := config.TryLoad()
+			if err != nil {
+				logger.WithError(err).Error("configuration invalid after secret rotation")
+				return
+			}
+
+			base := aiBase
+			base.ClickHouseUsername = next.ClickHouseUsername
+			base.ClickHousePassword = next.ClickHousePassword
+			base.OpenRouterAPIKey = next.OpenRouterAPIKey
+
+			var rotated *ai.Agent
+			if base.OpenRouterAPIKey != "" {
+				rotated, err = ai.NewAgent(ctx, base)
+				if err != nil {
+					logger.WithError(err).Error("failed to rebuild ai agent with rotated secrets")
+					return
+				}
+			}
+			if prev := h.SetAI(rotated, base); prev != nil {
+				_ = prev.Close()
+			}
+			logger.WithField("keys", changed).Info("ai agent rebuilt with rotated secrets")
+		})
+	}
+
+	stopGRPC := serveGRPC(ctx, cfg, swapCache, logger)
+
+	srv, err := server.NewServer(server.ServerDeps{Handlers: h, Config: apiServerConfig(cfg)})
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create http server")
+	}
+
+	return srv, func() {
+		stopGRPC()
+		if a := h.SetAI(nil, aiBase); a != nil {
+			_ = a.Close()
+		}
+		if engine != nil {
+			_ = engine.Close()
+		}
+		if analytics != nil {
+			_ = analytics.Close()
+		}
+	}
+}
+
+// apiServerConfig is the HTTP server configuration described by cfg
+func apiServerConfig(cfg *config.Config) server.ServerConfig {
+	return server.ServerConfig{
+		Addr:    cfg.APIAddr,
+		DevMode: cfg.DevMode,
+		APIKey:  cfg.APIKey,
+		APIKeys: cfg.APIKeys,
+
+		AIRateLimit: cfg.AIRateLimit,
+		AIRateBurst: cfg.AIRateBurst,
+
+		ResponseCacheTTL:    cfg.ResponseCacheTTL,
+		WalletStatsCacheTTL: cfg.WalletStatsCacheTTL,
+		MarketsCacheTTL:     cfg.MarketsCacheTTL,
+
+		MaxBodyBytes:   cfg.MaxBodyBytes,
+		RequestTimeout: cfg.RequestTimeout,
+		AITimeout:      cfg.AIRequestTimeout,
+		QuoteTimeout:   cfg.QuoteRequestTimeout,
+		ExportTimeout:  cfg.ExportTimeout,
+
+		TLSCertFile:      cfg.TLSCertFile,
+		TLSKeyFile:       cfg.TLSKeyFile,
+		AutocertHosts:    cfg.AutocertHosts,
+		AutocertCacheDir: cfg.AutocertCacheDir,
+		AutocertEmail:    cfg.AutocertEmail,
+		RedirectAddr:     cfg.TLSRedirectAddr,
+	}
+}
+
+// serveGRPC serves the gRPC API on GRPC_ADDR until the returned func is
+// called or ctx is done; an empty address serves nothing
+func serveGRPC(ctx context.Context, cfg *config.Config, src grpcapi.Source, logger *logrus.Logger) func() {
+	if cfg.GRPCAddr == "" {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	srv := grpcapi.New(src, grpcapi.Config{
+		Addr:            cfg.GRPCAddr,
+		APIKey:          cfg.APIKey,
+		MaxStreams:      cfg.GRPCMaxStreams,
+		PriceStaleAfter: cfg.PriceStaleAfter,
+		TLSCertFile:     cfg.TLSCertFile,
+		TLSKeyFile:      cfg.TLSKeyFile,
+		Logger:          logger,
+	})
+	go func() {
+		if err := srv.ListenAndServe(ctx); err != nil {
+			logger.WithError(err).Error("grpc server failed")
+		}
+	}()
+	logger.WithField("addr", cfg.GRPCAddr).Info("serving grpc")
+	return cancel
+}
+
+// newJupiterClient builds a Jupiter client from the JUPITER_* settings
+func newJupiterClient(cfg *config.Config) *jupiter.Client {
+	return jupiter.NewClientWithConfig(jupiter.ClientConfig{
+		BaseURL:        os.Getenv("JUPITER_BASE_URL"),
+		APIKey:         os.Getenv("JUPITER_API_KEY"),
+		Timeout:        cfg.JupiterTimeout,
+		MaxRetries:     cfg.JupiterMaxRetries,
+		RetryBackoff:   cfg.JupiterRetryBackoff,
+		MaxBackoff:     cfg.JupiterMaxBackoff,
+		MaxConcurrency: cfg.JupiterMaxConcurrency,
+	})
+}
+
+// newTxWallet creates the keyless wallet that simulates and sends
+// client-built transactions on SOLANA_RPC_URL
+func newTxWallet(cfg *config.Config, logger *logrus.Logger) *wallet.Wallet {
+	w, err := wallet.NewReadOnlyWallet(wallet.WalletConfig{
+		RPCURL:       cfg.RPCUrl,
+		Timeout:      cfg.HTTPTimeout,
+		MaxRetries:   cfg.MaxRetries,
+		RetryBackoff: cfg.RetryBackoff,
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create transaction wallet")
+	}
+	return w
+}
+
+// reloadPoolsOnSIGHUP re-reads the swap engine pool config on every SIGHUP
+// (POST /v1/admin/pools/reload does the same over HTTP)
+func reloadPoolsOnSIGHUP(ctx context.Context, engine *swapengine.Engine, logger *logrus.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			res, err := engine.ReloadPools(ctx)
+			if res == nil {
+				logger.WithError(err).Error("pool reload failed")
+				continue
+			}
+			entry := logger.WithFields(logrus.Fields{"source": "sighup", "pools": res.Total})
+			if err != nil {
+				entry = entry.WithError(err)
+			}
+			entry.Info("pool registry reloaded")
+			logPoolSummary(logger, engine.PoolLoadSummary())
+		}
+	}
+}
+
+// logPoolSummary reports the pool config load and warns about each skipped field
+func logPoolSummary(logger *logrus.Logger, summary *orca.PoolLoadSummary) {
+	if summary == nil {
+		return
+	}
+	for _, fe := range summary.Errors {
+		logger.WithFields(logrus.Fields{
+			"index": fe.Index,
+			"pool":  fe.Pool,
+			"field": fe.Field,
+		}).Warn("skipping invalid pool config entry: " + fe.Message)
+	}
+	logger.WithFields(logrus.Fields{
+		"path":    summary.Path,
+		"source":  summary.Source,
+		"loaded":  summary.Loaded,
+		"skipped": summary.Skipped,
+	}).Info("pool config loaded")
+}
+
+// RunAPI serves the HTTP API until SIGINT/SIGTERM
+func RunAPI(configPath string) {
+	logger := NewLogger("2006-01-02 15:04:05")
+	cfg, secretStore := Bootstrap(configPath, logger, logrus.InfoLevel)
+
+	// Create context for graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Setup signal handling for graceful shutdown (Ctrl+C, SIGTERM)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	// One Redis client shared by the cache, pub/sub, flags and config reloads
+	redisCfg := redisConfig(cfg)
+	redisCfg.Logger = logging.Module(logger, logging.ModuleCache)
+	rclient := cache.NewRedisClient(redisCfg)
+	if err := rclient.Ping(ctx).Err(); err != nil {
+		logger.WithError(err).Fatal("failed to connect to Redis")
+	}
+	redisCache, err := cache.NewSharedRedisCache(rclient, redisCfg)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create Redis cache")
+	}
+
+	// Feature flags store for runtime configuration
+	flagStore, err := flags.NewStore(rclient)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create flags store")
+	}
+	flagStore.SetHistoryLimit(cfg.FlagsHistoryLimit)
+	watchLogLevels(ctx, flagStore, logger) // log.level.<module>
+
+	// SIGHUP or POST /v1/admin/config/reload re-reads the swap engine risk limits
+	reloader := config.NewReloader(configPath, cfg, logger)
+	srv, stopAI := NewAPIServer(ctx, cfg, redisCache, flagStore, rclient, secretStore, reloader, logger)
+	defer stopAI() // Clean up AI resources on shutdown
+	go reloader.Run(ctx, rclient)
+
+	// Setup graceful shutdown in a separate goroutine
+	go func() {
+		<-sigCh // Wait for shutdown signal
+		logger.Info("shutting down")
+		cancel()                               // Cancel context to stop ongoing operations
+		_ = srv.Shutdown(context.Background()) // Gracefully shutdown HTTP server
+	}()
+
+	// Start the HTTP server
+	logger.WithFields(logrus.Fields{"addr": cfg.APIAddr, "app_env": cfg.AppEnv, "tls": cfg.TLSCertFile != "" || len(cfg.AutocertHosts) > 0}).Info("api server starting")
+	if err := srv.Start(); err != nil {
+		// http.ErrServerClosed is expected during graceful shutdown
+		if errors.Is(err, http.ErrServerClosed) {
+			return
+		}
+		logger.WithError(err).Fatal("api server failed")
+	}
+
+	// Wait for server to be fully shut down
+	if err := srv.WaitClosed(context.Background()); err != nil {
+		logger.WithError(err).Warn("api shutdown")
+	}
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServices(t *testing.T) {
+	got, err := ParseServices(" Indexer, api ,ARB,anomalies,mev,ticker,webhooks,digests")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"indexer": true, "api": true, "arb": true, "anomalies": true, "mev": true, "ticker": true, "webhooks": true, "digests": true}, got)
+
+	_, err = ParseServices("indexer,worker")
+	assert.Error(t, err)
+	_, err = ParseServices(" , ")
+	assert.Error(t, err)
+}
+
+func TestParseReplayTime(t *testing.T) {
+	for in, want := range map[string]time.Time{
+		"2024-03-01":           time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		"2024-03-01T12:30":     time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+		"2024-03-01T12:30:05Z": time.Date(2024, 3, 1, 12, 30, 5, 0, time.UTC),
+	} {
+		got, err := parseReplayTime(in)
+		require.NoError(t, err, in)
+		assert.True(t, want.Equal(got), in)
+	}
+	_, err := parseReplayTime("yesterday")
+	assert.Error(t, err)
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `CREATE DATABASE IF NOT EXISTS solana;
+
+USE solana;
+
+-- Main swaps table; with a semicolon in the comment
+CREATE TABLE IF NOT EXISTS swaps (
+    signature String
+) ENGINE = MergeTree()
+ORDER BY signature;
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS slot UInt64 DEFAULT 0;
+`
+	got := splitStatements(script)
+	require.Len(t, got, 3)
+	assert.Equal(t, "CREATE DATABASE IF NOT EXISTS solana", got[0])
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS swaps (", firstLine(got[1]))
+	assert.Equal(t, "ALTER TABLE swaps ADD COLUMN IF NOT EXISTS slot UInt64 DEFAULT 0", got[2])
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/arb"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/consumer"
+	"github.com/sirupsen/logrus"
+)
+
+// newArbConsumer builds the arbitrage detector from the ARB_* settings and a
+// consumer that feeds it every swap on swaps:live. Opportunities go to
+// arb:opportunities and the arb:recent list read by GET /v1/arb/opportunities.
+func newArbConsumer(cfg *config.Config, publisher arb.Publisher, logger *logrus.Logger) *consumer.Consumer {
+	acfg := arb.Config{
+		ThresholdBps:    cfg.ArbThresholdBps,
+		MaxPriceAge:     cfg.ArbMaxPriceAge,
+		Cooldown:        cfg.ArbCooldown,
+		JupiterInterval: cfg.ArbJupiterInterval,
+		Publisher:       publisher,
+		Logger:          logger,
+	}
+	if cfg.ArbJupiterInterval > 0 {
+		acfg.Quoter = newJupiterClient(cfg)
+	}
+	detector := arb.NewDetector(acfg)
+
+	// One worker keeps each venue's latest price in stream order
+	c := consumer.New(consumer.Config{Workers: 1, ReorderWindow: cfg.ConsumerReorderWindow, Logger: logger})
+	c.Handle(constants.PubSubChannelSwaps, func(ctx context.Context, msg *consumer.Message) error {
+		if msg.Swap == nil {
+			return nil
+		}
+		return detector.Observe(ctx, msg.Swap)
+	})
+	logger.WithFields(logrus.Fields{
+		"threshold_bps":    cfg.ArbThresholdBps,
+		"jupiter_interval": cfg.ArbJupiterInterval,
+	}).Info("arbitrage detector started")
+	return c
+}
+
+// RunArb runs the arbitrage detector until SIGINT/SIGTERM
+func RunArb(configPath string) {
+	runLiveConsumer(configPath, "arbitrage detector", liveDetectors[serviceArb])
+}
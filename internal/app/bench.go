@@ -0,0 +1,211 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/bench"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/indexer"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// BenchThresholds fail a benchmark run (exit code 1) when exceeded
+type BenchThresholds struct {
+	MaxP99       time.Duration // per stage; 0 disables
+	MaxErrorRate float64       // per stage, 0-1; 0 disables
+	JSON         bool          // print the report as JSON
+}
+
+// BenchIngestOptions are the settings of `bench ingest`
+type BenchIngestOptions struct {
+	ConfigPath string
+	File       string // recorded swaps as JSON lines; empty reads ClickHouse
+	From, To   string // ClickHouse range (same formats as replay)
+	Pair       string // ClickHouse: only this pair
+	Count      int    // swaps to load (default 1000)
+
+	Rate        float64 // swaps per second; 0 is unthrottled
+	Concurrency int
+	Loops       int
+	Tag         string // signature prefix of benchmark swaps
+
+	BenchThresholds
+}
+
+// RunBenchIngest replays recorded swaps through the indexer's sinks (Redis
+// and ClickHouse) and reports pipeline and per-sink latency. It returns the
+// process exit code.
+func RunBenchIngest(opts BenchIngestOptions) int {
+	logger := NewLogger("2006-01-02 15:04:05")
+	cfg, _ := Bootstrap(opts.ConfigPath, logger, logrus.WarnLevel)
+	if opts.Count <= 0 {
+		opts.Count = 1000
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	redisCfg := redisConfig(cfg)
+	redisCfg.Logger = logger
+	redisCache, err := cache.NewRedisCache(ctx, redisCfg)
+	if err != nil {
+		logger.WithError(err).Error("failed to connect to Redis")
+		return 1
+	}
+	defer redisCache.Close()
+
+	clickhouseStore, err := newClickHouseStore(ctx, cfg, logger)
+	if err != nil {
+		logger.WithError(err).Error("failed to connect to ClickHouse")
+		return 1
+	}
+	defer clickhouseStore.Close()
+
+	swaps, err := loadBenchSwaps(ctx, opts, clickhouseStore)
+	if err != nil {
+		logger.WithError(err).Error("failed to load recorded swaps")
+		return 1
+	}
+
+	// No dead-letter queue: a failed sink write is reported as an error
+	// instead of being parked, and the per-swap logs stay quiet
+	quiet := logrus.New()
+	quiet.SetLevel(logrus.ErrorLevel)
+	timings := &bench.SinkTimings{}
+	idx := indexer.New(indexer.Config{
+		Cache:  timings.WrapCache(redisCache),
+		Store:  timings.WrapStore(clickhouseStore),
+		Logger: quiet,
+	})
+
+	logger.WithFields(logrus.Fields{
+		"swaps":       len(swaps),
+		"loops":       opts.Loops,
+		"rate":        opts.Rate,
+		"concurrency": opts.Concurrency,
+	}).Warn("benchmarking ingestion; swaps are written to Redis and ClickHouse with tagged signatures")
+
+	report, err := bench.Ingest(ctx, bench.IngestConfig{
+		Processor:   idx,
+		Sinks:       timings,
+		Rate:        opts.Rate,
+		Concurrency: opts.Concurrency,
+		Loops:       opts.Loops,
+		Tag:         opts.Tag,
+	}, swaps)
+	if err != nil {
+		logger.WithError(err).Error("benchmark failed")
+		return 1
+	}
+	return finishBench(report, opts.BenchThresholds)
+}
+
+// errBenchEnough stops the ClickHouse scan once enough swaps are loaded
+var errBenchEnough = errors.New("enough swaps")
+
+func loadBenchSwaps(ctx context.Context, opts BenchIngestOptions, history storage.SwapHistory) ([]*models.SwapEvent, error) {
+	if opts.File != "" {
+		f, err := os.Open(opts.File)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		swaps, err := bench.LoadSwaps(f)
+		if len(swaps) > opts.Count {
+			swaps = swaps[:opts.Count]
+		}
+		return swaps, err
+	}
+
+	q := storage.SwapQuery{To: time.Now().UTC(), Pair: opts.Pair}
+	if opts.From == "" {
+		q.From = q.To.Add(-24 * time.Hour)
+	} else {
+		var err error
+		if q.From, err = parseReplayTime(opts.From); err != nil {
+			return nil, err
+		}
+	}
+	if opts.To != "" {
+		var err error
+		if q.To, err = parseReplayTime(opts.To); err != nil {
+			return nil, err
+		}
+	}
+
+	var swaps []*models.SwapEvent
+	err := history.ScanSwaps(ctx, q, func(s *models.SwapEvent) error {
+		swaps = append(swaps, s)
+		if len(swaps) >= opts.Count {
+			return errBenchEnough
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBenchEnough) {
+		return nil, err
+	}
+	return swaps, nil
+}
+
+// BenchAPIOptions are the settings of `bench api`
+type BenchAPIOptions struct {
+	URL         string
+	APIKey      string // default API_KEY
+	Endpoints   []string
+	RPS         float64
+	Duration    time.Duration
+	Concurrency int
+	Timeout     time.Duration
+
+	BenchThresholds
+}
+
+// RunBenchAPI fires requests at the API and reports latency per endpoint.
+// It returns the process exit code.
+func RunBenchAPI(opts BenchAPIOptions) int {
+	loadEnvQuiet()
+	if opts.APIKey == "" {
+		opts.APIKey = os.Getenv("API_KEY")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "firing %.0f req/s at %s for %s\n", opts.RPS, opts.URL, opts.Duration)
+	report, err := bench.LoadAPI(ctx, bench.APIConfig{
+		BaseURL:     opts.URL,
+		APIKey:      opts.APIKey,
+		Endpoints:   opts.Endpoints,
+		RPS:         opts.RPS,
+		Duration:    opts.Duration,
+		Concurrency: opts.Concurrency,
+		Timeout:     opts.Timeout,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+	return finishBench(report, opts.BenchThresholds)
+}
+
+// finishBench prints the report and applies the thresholds
+func finishBench(report *bench.Report, th BenchThresholds) int {
+	if th.JSON {
+		_ = printJSON(os.Stdout, report)
+	} else {
+		_ = report.Print(os.Stdout)
+	}
+	if err := report.Check(th.MaxP99, th.MaxErrorRate); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,127 @@
+// Package app holds the entry points of every service and tool. The
+// standalone binaries under cmd/ and the unified `ssi` command both call
+// into it, so each service starts, loads configuration and shuts down the
+// same way whichever binary runs it.
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/netguard"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/secrets"
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+)
+
+// envPath is the .env file at the project root (where go.mod is)
+func envPath() string {
+	_, filename, _, _ := runtime.Caller(0)
+	projectRoot := filepath.Join(filepath.Dir(filename), "../..")
+	return filepath.Join(projectRoot, ".env")
+}
+
+// LoadEnv loads the .env file at the project root, if any
+func LoadEnv(logger *logrus.Logger) {
+	envPath := envPath()
+	if err := godotenv.Load(envPath); err != nil {
+		logger.Warnf("no .env file found at %s, using system environment variables", envPath)
+	} else {
+		logger.Infof("loaded .env from %s", envPath)
+	}
+}
+
+// NewLogger creates a text logger with full timestamps in the given layout
+func NewLogger(timestampFormat string) *logrus.Logger {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.TextFormatter{
+		FullTimestamp:   true,
+		TimestampFormat: timestampFormat,
+	})
+	return logger
+}
+
+// ApplyOverrides exports the command-line overrides bound with
+// config.BindFlags, before Bootstrap reads any configuration. It exits the
+// process on failure.
+func ApplyOverrides(o *config.Overrides) {
+	if err := o.Apply(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+// Bootstrap loads configuration the way every service does: .env, then the
+// config file (configPath, or $CONFIG_FILE), then the secret store
+// (SECRETS_PROVIDER), and finally validates the result. It exits the process
+// on any failure. The secret manager is nil when secrets come from the
+// environment.
+func Bootstrap(configPath string, logger *logrus.Logger, defLevel logrus.Level) (*config.Config, *secrets.Manager) {
+	// load .env BEFORE anything reads os.Getenv
+	LoadEnv(logger)
+
+	// config file fills in anything the environment left unset
+	if err := config.LoadFile(configPath); err != nil {
+		logger.WithError(err).Fatal("failed to load config file")
+	}
+
+	// credentials from Vault / AWS Secrets Manager (SECRETS_PROVIDER) override .env
+	secretStore, err := secrets.LoadFromEnv(context.Background(), logger)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to load secrets")
+	}
+
+	// LOG_LEVEL (falling back to defLevel), LOG_FORMAT and LOG_SAMPLE_*
+	if err := logging.Configure(logger, defLevel); err != nil {
+		logger.WithError(err).Fatal("invalid logging configuration")
+	}
+
+	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		logger.WithError(err).Fatal("invalid configuration")
+	}
+	return cfg, secretStore
+}
+
+// loadEnvQuiet loads .env like LoadEnv, without reporting whether it exists
+func loadEnvQuiet() {
+	_ = godotenv.Load(envPath())
+}
+
+// quietLogger only reports warnings, for tools whose output is their report
+func quietLogger() *logrus.Logger {
+	l := logrus.New()
+	l.SetLevel(logrus.WarnLevel)
+	return l
+}
+
+// redisConfig returns the Redis settings shared by every client (caches,
+// pub/sub, flags, config reload), so all of them honour the same address,
+// credentials, database and TLS setting, and every cache the same limits
+func redisConfig(cfg *config.Config) cache.RedisConfig {
+	return cache.RedisConfig{
+		Addr:     cfg.RedisAddr,
+		Username: cfg.RedisUsername,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+		TLS:      cfg.RedisTLS,
+
+		PriceTTL:              cfg.PriceTTL,
+		PriceHistoryWindow:    cfg.PriceHistoryWindow,
+		PriceHistoryMaxPoints: int64(cfg.PriceHistoryMaxPoints),
+		MaxRecentSwaps:        int64(cfg.MaxRecentSwaps),
+		Encoding:              cfg.SwapEncoding,
+	}
+}
+
+// webhookPolicy says where the webhooks of /v1/tx/send and /v1/subscriptions
+// may point
+func webhookPolicy(cfg *config.Config) netguard.Policy {
+	return netguard.Policy{AllowedHosts: cfg.WebhookAllowedHosts, AllowPrivate: cfg.WebhookAllowPrivate}
+}
@@ -0,0 +1,311 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/orca"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/secrets"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/wallet"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// report collects check results and prints them as they come in
+type report struct {
+	failed int
+}
+
+func (r *report) ok(name, detail string) {
+	fmt.Printf("[ok]   %-14s %s\n", name, detail)
+}
+
+func (r *report) warn(name, detail string) {
+	fmt.Printf("[warn] %-14s %s\n", name, detail)
+}
+
+func (r *report) fail(name string, err error) {
+	r.failed++
+	fmt.Printf("[FAIL] %-14s %v\n", name, err)
+}
+
+// ValidateOptions are the settings of `config validate`
+type ValidateOptions struct {
+	ConfigPath string
+	Offline    bool          // skip Redis, ClickHouse and RPC reachability checks
+	Timeout    time.Duration // timeout per reachability check (default 5s)
+}
+
+// RunValidate loads the full configuration, checks key formats and the pool
+// config, and pings Redis, ClickHouse and the RPC node. It returns the
+// process exit code: 0 if every check passed.
+func RunValidate(opts ValidateOptions) int {
+	loadEnvQuiet()
+	configPath := opts.ConfigPath
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+
+	r := &report{}
+
+	if err := config.LoadFile(configPath); err != nil {
+		r.fail("config file", err)
+		return 1
+	}
+	r.ok("config file", describeConfigFile(configPath))
+
+	if _, err := secrets.LoadFromEnv(context.Background(), quietLogger()); err != nil {
+		r.fail("secrets", err)
+		return 1
+	}
+
+	cfg, err := config.TryLoad()
+	if err != nil {
+		r.fail("config", err)
+		return 1
+	}
+	profile := cfg.AppEnv
+	if profile == "" {
+		profile = "none"
+	}
+	r.ok("config", "all required settings present (profile: "+profile+")")
+
+	checkKeys(r, cfg)
+	checkTLS(r, cfg)
+	checkPools(r, cfg, opts)
+
+	if !opts.Offline {
+		checkRedis(r, cfg, opts.Timeout)
+		checkClickHouse(r, cfg, opts.Timeout)
+		checkRPC(r, cfg, opts.Timeout)
+	}
+
+	if r.failed > 0 {
+		fmt.Printf("\n%d check(s) failed\n", r.failed)
+		return 1
+	}
+	fmt.Println("\nconfiguration is valid")
+	return 0
+}
+
+func describeConfigFile(path string) string {
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path == "" {
+		return "none (environment only)"
+	}
+	return path
+}
+
+// checkKeys validates formats that only fail at first use otherwise
+func checkKeys(r *report, cfg *config.Config) {
+	for _, addr := range cfg.ProgramAddresses {
+		if _, err := solana.PublicKeyFromBase58(addr); err != nil {
+			r.fail("program", fmt.Errorf("PROGRAM_ADDRESSES: %s: %w", addr, err))
+			return
+		}
+	}
+	r.ok("program", strings.Join(cfg.ProgramAddresses, ","))
+
+	if raw := os.Getenv("WALLET_PRIVATE_KEY"); raw != "" {
+		priv, err := wallet.ParsePrivateKey(raw)
+		if err != nil {
+			r.fail("wallet key", err)
+		} else {
+			r.ok("wallet key", priv.PublicKey().String())
+		}
+	} else {
+		r.warn("wallet key", "WALLET_PRIVATE_KEY not set (swap execution disabled)")
+	}
+
+	switch {
+	case cfg.OpenRouterAPIKey == "":
+		r.warn("openrouter", "OPENROUTER_API_KEY not set (AI endpoints disabled)")
+	case !strings.HasPrefix(cfg.OpenRouterAPIKey, "sk-or-"):
+		r.warn("openrouter", "OPENROUTER_API_KEY does not look like an OpenRouter key (sk-or-...)")
+	default:
+		r.ok("openrouter", config.Redact(cfg.OpenRouterAPIKey))
+	}
+
+	if cfg.StreamProvider == "triton" && cfg.TritonAPIKey == "" {
+		r.fail("stream", fmt.Errorf("TRITON_API_KEY required when STREAM_PROVIDER=triton"))
+	}
+}
+
+// checkTLS loads the certificate pair so a bad file fails here, not at startup
+func checkTLS(r *report, cfg *config.Config) {
+	switch {
+	case cfg.TLSCertFile != "":
+		if _, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile); err != nil {
+			r.fail("tls", fmt.Errorf("TLS_CERT_FILE/TLS_KEY_FILE: %w", err))
+			return
+		}
+		r.ok("tls", cfg.TLSCertFile)
+	case len(cfg.AutocertHosts) > 0:
+		r.ok("tls", "autocert for "+strings.Join(cfg.AutocertHosts, ","))
+	case cfg.APIKey != "":
+		r.warn("tls", "API_KEY set but TLS is off (use a proxy or TLS_CERT_FILE / TLS_AUTOCERT_HOSTS)")
+	}
+}
+
+// checkPools parses the swap engine pool config the same way the engine does.
+// Entries the engine would skip are warnings, or failures with
+// SWAPENGINE_POOL_STRICT. With SWAPENGINE_POOL_SOURCE=chain the entries are
+// resolved against the RPC node, which needs the network.
+func checkPools(r *report, cfg *config.Config, opts ValidateOptions) {
+	path := os.Getenv("SWAPENGINE_POOL_CONFIG_PATH")
+	if path == "" {
+		path = swapengine.DefaultEngineConfig().PoolConfigPath
+	}
+	strict, _ := strconv.ParseBool(os.Getenv("SWAPENGINE_POOL_STRICT"))
+	loadOpts := orca.PoolLoadOptions{
+		Source: strings.ToLower(strings.TrimSpace(os.Getenv("SWAPENGINE_POOL_SOURCE"))),
+		Strict: strict,
+	}
+
+	ctx := context.Background()
+	if loadOpts.Source == orca.PoolSourceChain {
+		if opts.Offline {
+			r.warn("pools", path+" not checked (pool source is chain, offline)")
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+		loadOpts.Fetcher, _ = orca.NewClient(rpc.ClientConfig{
+			BaseURL: cfg.RPCUrl,
+			Timeout: opts.Timeout,
+			Logger:  quietLogger(),
+		})
+	}
+
+	_, summary, err := orca.LoadPoolRegistry(ctx, path, loadOpts)
+	if summary == nil {
+		r.fail("pools", fmt.Errorf("%s: %w", path, err))
+		return
+	}
+	for _, fe := range summary.Errors {
+		if strict {
+			r.fail("pools", fe)
+		} else {
+			r.warn("pools", "skipped: "+fe.Error())
+		}
+	}
+	if err == nil {
+		r.ok("pools", summary.String())
+	}
+}
+
+func checkRedis(r *report, cfg *config.Config, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client := cache.NewRedisClient(redisConfig(cfg))
+	defer client.Close()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		r.fail("redis", fmt.Errorf("%s: %w", cfg.RedisAddr, err))
+		return
+	}
+	r.ok("redis", cfg.RedisAddr)
+}
+
+func checkClickHouse(r *report, cfg *config.Config, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	store, err := cache.NewClickHouseStore(ctx, cache.ClickHouseConfig{
+		Addr:     cfg.ClickHouseAddr,
+		Database: cfg.ClickHouseDatabase,
+		Username: cfg.ClickHouseUsername,
+		Password: cfg.ClickHousePassword,
+		Logger:   quietLogger(),
+	})
+	if err != nil {
+		r.fail("clickhouse", fmt.Errorf("%s: %w", cfg.ClickHouseAddr, err))
+		return
+	}
+	_ = store.Close()
+	r.ok("clickhouse", cfg.ClickHouseAddr+"/"+cfg.ClickHouseDatabase)
+}
+
+func checkRPC(r *report, cfg *config.Config, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rpcURL := cfg.RPCUrl
+	if cfg.StreamProvider == "triton" {
+		rpcURL = fmt.Sprintf("https://api.mainnet.solana.triton.one/%s", cfg.TritonAPIKey)
+	}
+
+	client := rpc.NewClient(rpc.ClientConfig{
+		BaseURL: rpcURL,
+		Timeout: timeout,
+		Logger:  quietLogger(),
+	})
+	if err := client.GetHealth(ctx); err != nil {
+		r.fail("rpc", fmt.Errorf("%s: %w", cfg.RPCUrl, err))
+		return
+	}
+	r.ok("rpc", cfg.RPCUrl)
+}
+
+// DumpOptions are the settings of `config dump`
+type DumpOptions struct {
+	ConfigPath string
+	JSON       bool // print JSON instead of a table
+	All        bool // include settings that are not set anywhere
+}
+
+// RunDump prints the effective configuration (secrets redacted) and where
+// each value came from. It returns the process exit code.
+func RunDump(opts DumpOptions) int {
+	loadEnvQuiet()
+
+	if err := config.LoadFile(opts.ConfigPath); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config file:", err)
+		return 1
+	}
+	if _, err := secrets.LoadFromEnv(context.Background(), quietLogger()); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load secrets:", err)
+		return 1
+	}
+
+	var settings []config.Setting
+	for _, s := range config.Effective() {
+		if s.Source == config.SourceUnset && !opts.All {
+			continue
+		}
+		settings = append(settings, s)
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(settings); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, s := range settings {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Key, s.Value, s.Source)
+	}
+	_ = tw.Flush()
+	return 0
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/anomaly"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/arb"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/consumer"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/ticker"
+	"github.com/sirupsen/logrus"
+)
+
+// livePublisher is where the live-feed detectors report, and where the
+// webhook router reads subscriptions (*cache.RedisCache)
+type livePublisher interface {
+	arb.Publisher
+	anomaly.Publisher
+	ticker.Publisher
+	storage.SubscriptionStore
+}
+
+// liveConsumerBuilder builds a detector service's consumer of swaps:live;
+// background work it starts stops with ctx
+type liveConsumerBuilder func(context.Context, *config.Config, livePublisher, *logrus.Logger) *consumer.Consumer
+
+// liveDetectors are the services that only follow swaps:live, by name
+var liveDetectors = map[string]liveConsumerBuilder{
+	serviceArb: func(_ context.Context, cfg *config.Config, p livePublisher, logger *logrus.Logger) *consumer.Consumer {
+		return newArbConsumer(cfg, p, logger)
+	},
+	serviceAnomalies: func(_ context.Context, cfg *config.Config, p livePublisher, logger *logrus.Logger) *consumer.Consumer {
+		return newAnomalyConsumer(cfg, p, logger)
+	},
+	serviceTicker: func(ctx context.Context, cfg *config.Config, p livePublisher, logger *logrus.Logger) *consumer.Consumer {
+		return newTickerConsumer(ctx, cfg, p, logger)
+	},
+	serviceWebhooks: func(ctx context.Context, cfg *config.Config, p livePublisher, logger *logrus.Logger) *consumer.Consumer {
+		return newWebhookConsumer(ctx, cfg, p, logger)
+	},
+}
+
+// runLiveConsumer runs one consumer of swaps:live, built by build on the
+// shared Redis cache, until SIGINT/SIGTERM
+func runLiveConsumer(configPath, name string, build liveConsumerBuilder) {
+	logger := NewLogger("2006-01-02 15:04:05")
+	cfg, _ := Bootstrap(configPath, logger, logrus.InfoLevel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	redisCfg := redisConfig(cfg)
+	redisCfg.Logger = logger
+	redisCache, err := cache.NewRedisCache(ctx, redisCfg)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to connect to Redis")
+	}
+	defer redisCache.Close()
+
+	defer serveMetrics(cfg.MetricsAddr, logger)()
+
+	c := build(ctx, cfg, redisCache, logger)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := c.Run(ctx, redisCache.Client()); err != nil && ctx.Err() == nil {
+			logger.WithError(err).Error(name + " stopped")
+		}
+	}()
+
+	<-sigCh
+	logger.Info("shutting down")
+	cancel()
+	<-done
+}
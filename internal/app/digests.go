@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/notify"
+	"github.com/sirupsen/logrus"
+)
+
+// newDigester builds the digest job: subscriptions and claims in Redis,
+// figures from the ClickHouse stats queries
+func newDigester(cfg *config.Config, store *cache.ClickHouseStore, redisCache *cache.RedisCache, logger *logrus.Logger) *notify.Digester {
+	return notify.NewDigester(notify.DigesterConfig{
+		Store:     redisCache,
+		Stats:     store,
+		Publisher: redisCache,
+		Webhooks:  webhookPolicy(cfg),
+		Logger:    logger,
+	})
+}
+
+// RunDigests sends the hourly and daily digests of /v1/subscriptions as
+// each period ends, until SIGINT/SIGTERM. It returns the process exit code.
+func RunDigests(configPath string) int {
+	logger := NewLogger("2006-01-02 15:04:05")
+	cfg, _ := Bootstrap(configPath, logger, logrus.InfoLevel)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, err := newClickHouseStore(ctx, cfg, logger)
+	if err != nil {
+		logger.WithError(err).Error("failed to connect to ClickHouse")
+		return 1
+	}
+	defer store.Close()
+
+	redisCfg := redisConfig(cfg)
+	redisCfg.Logger = logger
+	redisCache, err := cache.NewRedisCache(ctx, redisCfg)
+	if err != nil {
+		logger.WithError(err).Error("failed to connect to Redis")
+		return 1
+	}
+	defer redisCache.Close()
+
+	defer serveMetrics(cfg.MetricsAddr, logger)()
+
+	logger.Info("subscription digests started")
+	newDigester(cfg, store, redisCache, logger).Run(ctx)
+	return 0
+}
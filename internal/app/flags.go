@@ -0,0 +1,411 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/secrets"
+	"github.com/redis/go-redis/v9"
+)
+
+// FlagsOptions select where the flags commands read and write: Redis
+// (REDIS_* from the environment or config file) by default, or the HTTP
+// API when APIURL is set
+type FlagsOptions struct {
+	ConfigPath string
+	APIURL     string        // e.g. http://localhost:8090; empty talks to Redis directly
+	APIKey     string        // X-API-Key for APIURL (default API_KEY)
+	Actor      string        // recorded in the flag history (default $USER)
+	JSON       bool          // print JSON instead of tables
+	Timeout    time.Duration // per operation (default 5s)
+}
+
+// FlagSetOptions are the settings of `flags set`
+type FlagSetOptions struct {
+	Type       string // bool, int, float, string or json; empty keeps the current type or infers it
+	TTL        time.Duration
+	ActiveFrom string // RFC3339
+	ExpiresAt  string // RFC3339
+}
+
+// flagAdmin is what the flags commands need from a backend
+type flagAdmin interface {
+	List(ctx context.Context, prefix string) ([]*flags.Flag, error)
+	Get(ctx context.Context, key string) (*flags.Flag, error)
+	Set(ctx context.Context, key string, typ flags.Type, value json.RawMessage, sched *flags.Schedule) (*flags.Flag, error)
+	Delete(ctx context.Context, key string) error
+	History(ctx context.Context, key string, limit int) ([]flags.HistoryEntry, error)
+}
+
+// flagsSession is an opened backend; close releases its connections
+type flagsSession struct {
+	opts   FlagsOptions
+	admin  flagAdmin
+	client *redis.Client // nil when talking to the API
+	close  func()
+}
+
+// openFlags connects to the backend selected by opts
+func openFlags(opts FlagsOptions) (*flagsSession, error) {
+	loadEnvQuiet()
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Actor == "" {
+		opts.Actor = os.Getenv("USER")
+	}
+
+	if opts.APIURL != "" {
+		if opts.APIKey == "" {
+			opts.APIKey = os.Getenv("API_KEY")
+		}
+		return &flagsSession{
+			opts:  opts,
+			admin: newFlagsHTTPClient(opts.APIURL, opts.APIKey, opts.Actor, opts.Timeout),
+			close: func() {},
+		}, nil
+	}
+
+	if err := config.LoadFile(opts.ConfigPath); err != nil {
+		return nil, fmt.Errorf("load config file: %w", err)
+	}
+	if _, err := secrets.LoadFromEnv(context.Background(), quietLogger()); err != nil {
+		return nil, fmt.Errorf("load secrets: %w", err)
+	}
+	cfg, err := config.TryLoad()
+	if err != nil {
+		return nil, err
+	}
+	client := cache.NewRedisClient(redisConfig(cfg))
+	store, err := flags.NewStore(client)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	store.SetHistoryLimit(cfg.FlagsHistoryLimit)
+	return &flagsSession{
+		opts:   opts,
+		admin:  &flagsRedis{store: store, actor: flags.Actor{Name: opts.Actor}},
+		client: client,
+		close:  func() { _ = client.Close() },
+	}, nil
+}
+
+// runFlags opens the backend and runs fn with a per-operation timeout,
+// reporting errors on stderr. It returns the process exit code.
+func runFlags(opts FlagsOptions, fn func(ctx context.Context, s *flagsSession) error) int {
+	s, err := openFlags(opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	defer s.close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.Timeout)
+	defer cancel()
+	if err := fn(ctx, s); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
+
+// RunFlagsList prints every flag, or those whose key starts with prefix
+func RunFlagsList(opts FlagsOptions, prefix string) int {
+	return runFlags(opts, func(ctx context.Context, s *flagsSession) error {
+		items, err := s.admin.List(ctx, prefix)
+		if err != nil {
+			return err
+		}
+		if opts.JSON {
+			return printJSON(os.Stdout, items)
+		}
+		printFlagTable(os.Stdout, items)
+		return nil
+	})
+}
+
+// RunFlagsGet prints one flag as JSON; a missing flag exits with 1
+func RunFlagsGet(opts FlagsOptions, key string) int {
+	return runFlags(opts, func(ctx context.Context, s *flagsSession) error {
+		f, err := s.admin.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		return printJSON(os.Stdout, f)
+	})
+}
+
+// RunFlagsSet creates or updates a flag. value is JSON (true, 42, "x",
+// {"a":1}); anything that does not parse as JSON is taken as a string.
+// Without a type or schedule the flag keeps its current ones.
+func RunFlagsSet(opts FlagsOptions, key, value string, set FlagSetOptions) int {
+	return runFlags(opts, func(ctx context.Context, s *flagsSession) error {
+		typ, err := flags.ParseType(set.Type)
+		if err != nil {
+			return err
+		}
+		sched, err := set.schedule(time.Now())
+		if err != nil {
+			return err
+		}
+		f, err := s.admin.Set(ctx, key, typ, ParseFlagValue(value), sched)
+		if err != nil {
+			return err
+		}
+		return printJSON(os.Stdout, f)
+	})
+}
+
+// RunFlagsDelete removes a flag; deleting a missing flag is not an error
+func RunFlagsDelete(opts FlagsOptions, key string) int {
+	return runFlags(opts, func(ctx context.Context, s *flagsSession) error {
+		if err := flags.ValidateKey(key); err != nil {
+			return err
+		}
+		if err := s.admin.Delete(ctx, key); err != nil {
+			return err
+		}
+		if !opts.JSON {
+			fmt.Printf("deleted %s\n", key)
+		}
+		return nil
+	})
+}
+
+// RunFlagsHistory prints the recorded changes to a flag, newest first
+func RunFlagsHistory(opts FlagsOptions, key string, limit int) int {
+	return runFlags(opts, func(ctx context.Context, s *flagsSession) error {
+		items, err := s.admin.History(ctx, key, limit)
+		if err != nil {
+			return err
+		}
+		if opts.JSON {
+			return printJSON(os.Stdout, items)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "AT\tOP\tOLD\tNEW\tACTOR")
+		for _, e := range items {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.At.Format(time.RFC3339), e.Op, flagValue(e.Old), flagValue(e.New), describeActor(e.Actor))
+		}
+		return tw.Flush()
+	})
+}
+
+// RunFlagsWatch prints every flag change announced on flags:changes until
+// SIGINT/SIGTERM. Changes are only published in Redis, so it cannot run
+// against the API.
+func RunFlagsWatch(opts FlagsOptions, prefix string) int {
+	if opts.APIURL != "" {
+		fmt.Fprintln(os.Stderr, "error: watch subscribes to Redis and cannot use --api")
+		return 2
+	}
+	s, err := openFlags(opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	defer s.close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pubsub := s.client.Subscribe(ctx, flags.ChangesChannel)
+	defer pubsub.Close()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "error: subscribe:", err)
+		return 1
+	}
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return 0
+		case m, ok := <-ch:
+			if !ok {
+				return 1
+			}
+			var c flags.Change
+			if err := json.Unmarshal([]byte(m.Payload), &c); err != nil || !strings.HasPrefix(c.Key, prefix) {
+				continue
+			}
+			if opts.JSON {
+				fmt.Println(m.Payload)
+				continue
+			}
+			fmt.Printf("%s %-6s %s %s\n", time.Now().Format("15:04:05"), c.Op, c.Key, flagValue(c.Flag))
+		}
+	}
+}
+
+// ParseFlagValue turns a command-line value into JSON: valid JSON is kept,
+// anything else becomes a JSON string
+func ParseFlagValue(s string) json.RawMessage {
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	b, _ := json.Marshal(s)
+	return b
+}
+
+// schedule returns nil when no schedule option was given, so the flag keeps
+// its current one
+func (o FlagSetOptions) schedule(now time.Time) (*flags.Schedule, error) {
+	if o.TTL == 0 && o.ActiveFrom == "" && o.ExpiresAt == "" {
+		return nil, nil
+	}
+	if o.TTL != 0 && o.ExpiresAt != "" {
+		return nil, fmt.Errorf("set --ttl or --expires-at, not both")
+	}
+
+	sched := &flags.Schedule{}
+	if o.ActiveFrom != "" {
+		t, err := time.Parse(time.RFC3339, o.ActiveFrom)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --active-from: %w", err)
+		}
+		sched.ActiveFrom = &t
+	}
+	if o.ExpiresAt != "" {
+		t, err := time.Parse(time.RFC3339, o.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --expires-at: %w", err)
+		}
+		sched.ExpiresAt = &t
+	}
+	if o.TTL != 0 {
+		if o.TTL < 0 {
+			return nil, fmt.Errorf("--ttl must be positive")
+		}
+		// a TTL counts from activation when the flag is scheduled for later
+		start := now
+		if sched.ActiveFrom != nil && sched.ActiveFrom.After(now) {
+			start = *sched.ActiveFrom
+		}
+		exp := start.Add(o.TTL)
+		sched.ExpiresAt = &exp
+	}
+	return sched, nil
+}
+
+// flagsRedis manages flags directly in Redis
+type flagsRedis struct {
+	store *flags.Store
+	actor flags.Actor
+}
+
+func (r *flagsRedis) List(ctx context.Context, prefix string) ([]*flags.Flag, error) {
+	if err := flags.ValidatePrefix(prefix); err != nil {
+		return nil, err
+	}
+	var out []*flags.Flag
+	opts := flags.ListOptions{Prefix: prefix, Limit: flags.MaxPageSize}
+	for {
+		page, err := r.store.ListPage(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page.Items...)
+		if page.Next == "" {
+			return out, nil
+		}
+		opts.After = page.Next
+	}
+}
+
+func (r *flagsRedis) Get(ctx context.Context, key string) (*flags.Flag, error) {
+	return r.store.Get(ctx, key)
+}
+
+// Set keeps the current type and schedule unless they are given, like
+// PUT /v1/flags/{key}
+func (r *flagsRedis) Set(ctx context.Context, key string, typ flags.Type, value json.RawMessage, sched *flags.Schedule) (*flags.Flag, error) {
+	var s flags.Schedule
+	if sched != nil {
+		s = *sched
+	}
+	if cur, err := r.store.Get(ctx, key); err == nil {
+		if typ == "" {
+			typ = cur.Type
+		}
+		if sched == nil {
+			s = cur.Schedule()
+		}
+	} else if !errors.Is(err, flags.ErrNotFound) {
+		return nil, err
+	}
+	return r.store.SetScheduled(flags.WithActor(ctx, r.actor), key, typ, value, s)
+}
+
+func (r *flagsRedis) Delete(ctx context.Context, key string) error {
+	return r.store.Delete(flags.WithActor(ctx, r.actor), key)
+}
+
+func (r *flagsRedis) History(ctx context.Context, key string, limit int) ([]flags.HistoryEntry, error) {
+	if err := flags.ValidateKey(key); err != nil {
+		return nil, err
+	}
+	return r.store.History(ctx, key, limit)
+}
+
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func printFlagTable(w io.Writer, items []*flags.Flag) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tTYPE\tVALUE\tACTIVE\tEXPIRES\tUPDATED")
+	now := time.Now()
+	for _, f := range items {
+		expires := "-"
+		if f.ExpiresAt != nil {
+			expires = f.ExpiresAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\n", f.Key, f.Type, flagValue(f), f.ActiveAt(now), expires, f.UpdatedAt.Format(time.RFC3339))
+	}
+	_ = tw.Flush()
+}
+
+// flagValue is the JSON value of f, or "-" for none
+func flagValue(f *flags.Flag) string {
+	if f == nil {
+		return "-"
+	}
+	if len(f.Data) == 0 {
+		return strconv.FormatBool(f.Value)
+	}
+	return string(f.Data)
+}
+
+func describeActor(a flags.Actor) string {
+	var parts []string
+	if a.Name != "" {
+		parts = append(parts, a.Name)
+	}
+	if a.APIKey != "" {
+		parts = append(parts, "key:"+a.APIKey)
+	}
+	if a.IP != "" {
+		parts = append(parts, a.IP)
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
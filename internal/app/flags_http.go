@@ -0,0 +1,161 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+)
+
+// flagsHTTP manages flags through the /v1/flags API, for machines that can
+// reach the API but not Redis
+type flagsHTTP struct {
+	base   string
+	apiKey string
+	actor  string
+	client *http.Client
+}
+
+func newFlagsHTTPClient(base, apiKey, actor string, timeout time.Duration) *flagsHTTP {
+	return &flagsHTTP{
+		base:   strings.TrimRight(base, "/"),
+		apiKey: apiKey,
+		actor:  actor,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// do sends a request and decodes a 2xx JSON response into out (if non-nil).
+// A 404 is reported as flags.ErrNotFound.
+func (c *flagsHTTP) do(ctx context.Context, method, path string, body, out any) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.actor != "" {
+		req.Header.Set("X-Actor", c.actor)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return flags.ErrNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Error   string          `json:"error"`
+			Details json.RawMessage `json:"details"`
+		}
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			if len(e.Details) > 0 {
+				return fmt.Errorf("%s %s: %d %s: %s", method, path, resp.StatusCode, e.Error, e.Details)
+			}
+			return fmt.Errorf("%s %s: %d %s", method, path, resp.StatusCode, e.Error)
+		}
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (c *flagsHTTP) List(ctx context.Context, prefix string) ([]*flags.Flag, error) {
+	var out []*flags.Flag
+	cursor := ""
+	for {
+		q := url.Values{"limit": {strconv.Itoa(flags.MaxPageSize)}}
+		if prefix != "" {
+			q.Set("prefix", prefix)
+		}
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		var page flags.Page
+		if err := c.do(ctx, http.MethodGet, "/v1/flags?"+q.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		out = append(out, page.Items...)
+		if page.Next == "" {
+			return out, nil
+		}
+		cursor = page.Next
+	}
+}
+
+func (c *flagsHTTP) Get(ctx context.Context, key string) (*flags.Flag, error) {
+	var f flags.Flag
+	if err := c.do(ctx, http.MethodGet, "/v1/flags/"+url.PathEscape(key), nil, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Set uses PUT, which keeps the current type and schedule unless given
+func (c *flagsHTTP) Set(ctx context.Context, key string, typ flags.Type, value json.RawMessage, sched *flags.Schedule) (*flags.Flag, error) {
+	body := map[string]any{"value": value}
+	if typ != "" {
+		body["type"] = typ
+	}
+	if sched != nil {
+		if sched.ActiveFrom != nil {
+			body["active_from"] = sched.ActiveFrom
+		}
+		if sched.ExpiresAt != nil {
+			body["expires_at"] = sched.ExpiresAt
+		}
+	}
+	var f flags.Flag
+	if err := c.do(ctx, http.MethodPut, "/v1/flags/"+url.PathEscape(key), body, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (c *flagsHTTP) Delete(ctx context.Context, key string) error {
+	return c.do(ctx, http.MethodDelete, "/v1/flags/"+url.PathEscape(key), nil, nil)
+}
+
+func (c *flagsHTTP) History(ctx context.Context, key string, limit int) ([]flags.HistoryEntry, error) {
+	path := "/v1/flags/" + url.PathEscape(key) + "/history"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var out struct {
+		Items []flags.HistoryEntry `json:"items"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlagValue(t *testing.T) {
+	assert.JSONEq(t, `true`, string(ParseFlagValue("true")))
+	assert.JSONEq(t, `42`, string(ParseFlagValue("42")))
+	assert.JSONEq(t, `{"a":1}`, string(ParseFlagValue(`{"a":1}`)))
+	assert.JSONEq(t, `"openai/gpt-4.1-mini"`, string(ParseFlagValue("openai/gpt-4.1-mini")))
+}
+
+func TestFlagSetOptions_Schedule(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	sched, err := FlagSetOptions{}.schedule(now)
+	require.NoError(t, err)
+	assert.Nil(t, sched, "no options keeps the current schedule")
+
+	sched, err = FlagSetOptions{TTL: 2 * time.Hour, ActiveFrom: "2026-01-06T00:00:00Z"}.schedule(now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 6, 2, 0, 0, 0, time.UTC), *sched.ExpiresAt, "ttl counts from activation")
+
+	_, err = FlagSetOptions{TTL: time.Hour, ExpiresAt: "2026-01-06T00:00:00Z"}.schedule(now)
+	assert.Error(t, err)
+	_, err = FlagSetOptions{ActiveFrom: "tomorrow"}.schedule(now)
+	assert.Error(t, err)
+}
+
+func TestFlagsHTTP(t *testing.T) {
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+		assert.Equal(t, "ops", r.Header.Get("X-Actor"))
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/flags":
+			assert.Equal(t, "engine.", r.URL.Query().Get("prefix"))
+			if r.URL.Query().Get("cursor") == "" {
+				_, _ = io.WriteString(w, `{"items":[{"key":"engine.a","type":"bool","value":true}],"next_cursor":"engine.a"}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"items":[{"key":"engine.b","type":"int","value":3}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/flags/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":"flag not found","code":404}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/flags/engine.kill_switch":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+			_, _ = io.WriteString(w, `{"key":"engine.kill_switch","type":"bool","value":true}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/flags/bad":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error":"invalid value","code":400}`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/v1/flags/engine.kill_switch":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+	defer srv.Close()
+
+	c := newFlagsHTTPClient(srv.URL+"/", "secret", "ops", time.Second)
+	ctx := context.Background()
+
+	items, err := c.List(ctx, "engine.")
+	require.NoError(t, err)
+	require.Len(t, items, 2, "pages are followed")
+	assert.True(t, items[0].Value)
+	assert.Equal(t, flags.TypeInt, items[1].Type)
+
+	_, err = c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, flags.ErrNotFound)
+
+	exp := time.Date(2026, 1, 5, 14, 0, 0, 0, time.UTC)
+	f, err := c.Set(ctx, "engine.kill_switch", "", json.RawMessage("true"), &flags.Schedule{ExpiresAt: &exp})
+	require.NoError(t, err)
+	assert.True(t, f.Value)
+	assert.Equal(t, true, gotBody["value"])
+	assert.Equal(t, "2026-01-05T14:00:00Z", gotBody["expires_at"])
+	assert.NotContains(t, gotBody, "type", "an empty type keeps the current one")
+
+	_, err = c.Set(ctx, "bad", flags.TypeInt, json.RawMessage(`"x"`), nil)
+	assert.ErrorContains(t, err, "invalid value")
+
+	assert.NoError(t, c.Delete(ctx, "engine.kill_switch"))
+}
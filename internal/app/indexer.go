@@ -0,0 +1,212 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/indexer"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// RunIndexer runs the stream provider and processing pipeline until
+// SIGINT/SIGTERM, then drains the in-flight swap
+func RunIndexer(configPath string) {
+	logger := NewLogger("2006-01-02 15:04:05")
+	cfg, _ := Bootstrap(configPath, logger, logrus.InfoLevel)
+
+	// Create context with cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	// Initialize Redis cache
+	redisCfg := redisConfig(cfg) // REDIS_*, PRICE_*, RECENT_SWAPS_MAX, SWAP_ENCODING
+	redisCfg.Logger = logging.Module(logger, logging.ModuleCache)
+	redisCache, err := cache.NewRedisCache(ctx, redisCfg)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to connect to Redis")
+	}
+
+	// Initialize ClickHouse store
+	clickhouseStore, err := newClickHouseStore(ctx, cfg, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to connect to ClickHouse")
+	}
+	warmStartCache(ctx, cfg, redisCache, clickhouseStore, logger)
+
+	// Sinks swaps are written to (INDEXER_SINKS)
+	sinks, err := indexer.SinksFromConfig(cfg, indexer.SinkDeps{Cache: redisCache, Store: clickhouseStore})
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create indexer sinks")
+	}
+
+	// Swaps indexed at confirmed commitment are followed until finalized;
+	// those on dropped forks are deleted and retracted (INDEXER_FINALITY_INTERVAL)
+	finality, err := indexer.FinalityTrackerFromConfig(cfg, clickhouseStore, redisCache, logging.Module(logger, logging.ModuleIndexer))
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create finality tracker")
+	}
+
+	// Mints outside the built-in token list get their Metaplex symbol (INDEXER_REGISTER_TOKENS)
+	registry, err := indexer.NewTokenRegistry(ctx, cfg, redisCache, clickhouseStore, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create token registry")
+	}
+	if registry != nil {
+		go registry.Run(ctx)
+	}
+
+	// Create indexer
+	// Swaps a sink rejects are parked in the Redis dead-letter queue and redriven
+	idx := indexer.New(indexer.Config{
+		Cache:       redisCache,
+		Store:       clickhouseStore,
+		DeadLetters: redisCache,
+		Logger:      logging.Module(logger, logging.ModuleIndexer),
+		Provider:    cfg.StreamProvider,      // STREAM_PROVIDER
+		Instance:    indexer.InstanceID(cfg), // INDEXER_INSTANCE_ID
+
+		DrainTimeout:   cfg.DrainTimeout,              // INDEXER_DRAIN_TIMEOUT
+		Filter:         indexer.FilterFromConfig(cfg), // INDEXER_FILTER_*
+		DedupWindow:    cfg.DedupWindow,               // INDEXER_DEDUP_WINDOW
+		Processors:     indexer.Enrichers(registry),   // token labels, then USD value
+		DisabledStages: cfg.DisabledStages,            // INDEXER_DISABLED_STAGES
+		Sinks:          sinks,                         // INDEXER_SINKS
+		Finality:       finality,                      // INDEXER_FINALITY_INTERVAL
+	})
+	defer func() {
+		logger.Info("closing connections")
+		if err := idx.Close(); err != nil {
+			logger.WithError(err).Error("error closing connections")
+		}
+	}()
+
+	// Create poller for the configured provider (STREAM_PROVIDER)
+	rpcURL, err := indexer.RPCURL(cfg)
+	if err != nil {
+		logger.WithError(err).Fatal("invalid stream provider configuration")
+	}
+	// With INDEXER_LEADER_ELECTION each program is polled by one replica at a time,
+	// resuming from the checkpoint the previous leader saved in Redis
+	elector := indexer.NewElector(cfg, redisCache.Client(), logger)
+	if elector != nil {
+		go elector.Run(ctx)
+		logger.WithField("instance", elector.ID()).Info("leader election enabled")
+	}
+	poller, err := indexer.NewPoller(cfg, indexer.PollerOptions{
+		Checkpoints: redisCache,
+		Elector:     elector,
+		FailedSwaps: clickhouseStore, // INDEXER_RECORD_FAILED_SWAPS
+		Decimals:    redisCache,
+		Logger:      logging.Module(logger, logging.ModuleStream),
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create poller")
+	}
+	// Programs added or removed through /v1/admin/indexer/programs apply on top of PROGRAM_ADDRESSES
+	programs := indexer.NewPrograms(cfg.ProgramAddresses, redisCache, poller, elector, logger)
+	programs.Refresh(ctx, cfg.ProgramAddresses)
+
+	logger.WithFields(logrus.Fields{
+		"app_env":    cfg.AppEnv,
+		"provider":   cfg.StreamProvider,
+		"rpc_url":    rpcURL,
+		"interval":   cfg.PollInterval,
+		"commitment": cfg.StreamCommitment,
+	}).Info("starting Solana swap indexer")
+
+	// The supervisor restarts a poller that exits or stalls (STREAM_STALL_TIMEOUT)
+	supervisor := indexer.NewSupervisor(cfg, poller, logging.Module(logger, logging.ModuleStream))
+
+	// Publish a status summary for GET /v1/admin/indexer/status
+	reporter := indexer.NewStatusReporter(indexer.InstanceID(cfg), poller).WithStream(supervisor)
+	go reporter.Run(ctx, redisCache, constants.IndexerStatusInterval, logger)
+
+	// Prometheus metrics (METRICS_ADDR); the API serves its own /metrics
+	defer serveMetrics(cfg.MetricsAddr, logger)()
+
+	// React to flag flips (indexer.paused, indexer.filters, log.level.*) within seconds instead of polling Redis
+	if flagStore, err := flags.NewStore(redisCache.Client()); err == nil {
+		indexer.WatchFlags(ctx, flagStore, poller, idx, logger)
+		watchLogLevels(ctx, flagStore, logger)
+	}
+
+	// Start polling in background
+	runDone := make(chan struct{})
+	go func() {
+		defer close(runDone)
+		if err := idx.Run(ctx, supervisor); err != nil {
+			logger.WithError(err).Error("poller stopped with error")
+		}
+	}()
+
+	// Apply reloadable settings on SIGHUP or POST /v1/admin/config/reload
+	reloader := config.NewReloader(configPath, cfg, logger)
+	reloader.OnReload(indexer.ReloadHook(poller, programs))
+	reloader.OnReload(indexer.FilterReloadHook(idx))
+	go reloader.Run(ctx, redisCache.Client())
+
+	logger.Info("indexer running, press Ctrl+C to stop")
+
+	// Wait for shutdown signal
+	<-sigChan
+	logger.Info("shutting down gracefully")
+	cancel() // stop pulling new events
+
+	// Let the in-flight swap reach every sink and its checkpoint be saved
+	// before the deferred close; a second signal exits immediately
+	select {
+	case <-runDone:
+		logger.Info("drained")
+	case <-sigChan:
+		logger.Warn("second signal, exiting without draining")
+	}
+}
+
+// newClickHouseStore connects to the configured ClickHouse database
+func newClickHouseStore(ctx context.Context, cfg *config.Config, logger *logrus.Logger) (*cache.ClickHouseStore, error) {
+	return cache.NewClickHouseStore(ctx, cache.ClickHouseConfig{
+		Addr:     cfg.ClickHouseAddr,
+		Database: cfg.ClickHouseDatabase,
+		Username: cfg.ClickHouseUsername,
+		Password: cfg.ClickHousePassword,
+		Logger:   logging.Module(logger, logging.ModuleCache),
+
+		SlowInsert: cfg.ClickHouseSlowInsert, // CLICKHOUSE_SLOW_INSERT
+	})
+}
+
+// warmStartCache fills an empty Redis cache with recent swaps and prices from
+// ClickHouse so the API has data to serve right after a flush
+// (CACHE_WARM_START). Failures are logged: the indexer refills the cache anyway.
+func warmStartCache(ctx context.Context, cfg *config.Config, rc *cache.RedisCache, src storage.RecentSwapSource, logger *logrus.Logger) {
+	if !cfg.CacheWarmStart {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, constants.WarmStartTimeout)
+	defer cancel()
+	res, err := rc.WarmStart(ctx, src, constants.WarmStartWindow)
+	switch {
+	case err != nil:
+		logger.WithError(err).Warn("failed to warm the cache from ClickHouse")
+	case res.Skipped != "":
+		logger.WithField("reason", res.Skipped).Debug("cache warm start skipped")
+	default:
+		logger.WithFields(logrus.Fields{
+			"swaps":  res.Swaps,
+			"pairs":  res.Pairs,
+			"prices": res.Prices,
+		}).Info("warmed the cache from ClickHouse")
+	}
+}
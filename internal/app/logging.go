@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// watchLogLevels applies the log.level.<module> flags to the module loggers
+// now and on every change. Deleting a flag, or letting its TTL expire, puts
+// the module back on LOG_LEVEL.
+func watchLogLevels(ctx context.Context, store *flags.Store, logger *logrus.Logger) {
+	watcher, err := store.Watch(ctx, 0)
+	if err != nil {
+		logger.WithError(err).Warn("failed to watch log level flags")
+		return
+	}
+	for _, name := range logging.Modules {
+		applyLogLevel(watcher, name, logger)
+	}
+	watcher.OnChange(func(ch flags.Change) {
+		if name, ok := strings.CutPrefix(ch.Key, flags.KeyLogLevelPrefix); ok {
+			applyLogLevel(watcher, name, logger)
+		}
+	})
+}
+
+// applyLogLevel sets one module's level from its flag
+func applyLogLevel(watcher *flags.Watcher, name string, logger *logrus.Logger) {
+	entry := logger.WithField(logging.FieldModule, name)
+	v := watcher.String(flags.KeyLogLevelPrefix+name, "")
+	if v == "" {
+		logging.ResetModuleLevel(name)
+		return
+	}
+	level, err := logrus.ParseLevel(v)
+	if err != nil {
+		entry.WithField("level", v).Warn("ignoring invalid log level flag")
+		return
+	}
+	if !logging.SetModuleLevel(name, level) {
+		entry.Warn("ignoring log level flag of unknown module")
+		return
+	}
+	entry.WithField("level", level.String()).Info("module log level changed")
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// serveMetrics exposes /metrics on addr (METRICS_ADDR) until the returned
+// func is called; an empty addr serves nothing
+func serveMetrics(addr string, logger *logrus.Logger) func() {
+	if addr == "" {
+		return func() {}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Default.Handler())
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.WithError(err).Error("metrics server failed")
+		}
+	}()
+	logger.WithField("addr", addr).Info("serving metrics")
+	return func() { _ = srv.Close() }
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/mev"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// MEVOptions are the settings of `ssi mev`
+type MEVOptions struct {
+	ConfigPath string
+	From       string // scan this range once and exit (RFC3339 or YYYY-MM-DD)
+	To         string // end of the range, exclusive (default: now)
+}
+
+// newMEVScanner builds the sandwich scanner from the MEV_* settings
+func newMEVScanner(cfg *config.Config, store *cache.ClickHouseStore, checkpoints storage.CheckpointStore, logger *logrus.Logger) *mev.Scanner {
+	return mev.NewScanner(mev.ScannerConfig{
+		History:     store,
+		Store:       store,
+		Checkpoints: checkpoints,
+		Interval:    cfg.MEVScanInterval,
+		Lookback:    cfg.MEVLookback,
+		Settle:      cfg.MEVSettleDelay,
+		Logger:      logger,
+	})
+}
+
+// RunMEV scans stored swaps for sandwich attacks and records them in
+// ClickHouse. With From it scans that range once; otherwise it follows new
+// swaps every MEV_SCAN_INTERVAL, resuming from its Redis checkpoint, until
+// SIGINT/SIGTERM. It returns the process exit code.
+func RunMEV(opts MEVOptions) int {
+	logger := NewLogger("2006-01-02 15:04:05")
+	cfg, _ := Bootstrap(opts.ConfigPath, logger, logrus.InfoLevel)
+
+	var q storage.SwapQuery
+	if opts.From != "" {
+		var err error
+		q.To = time.Now().UTC()
+		if q.From, err = parseReplayTime(opts.From); err != nil {
+			logger.WithError(err).Error("invalid --from")
+			return 2
+		}
+		if opts.To != "" {
+			if q.To, err = parseReplayTime(opts.To); err != nil {
+				logger.WithError(err).Error("invalid --to")
+				return 2
+			}
+		}
+		if !q.From.Before(q.To) {
+			logger.Error("--from must be before --to")
+			return 2
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, err := newClickHouseStore(ctx, cfg, logger)
+	if err != nil {
+		logger.WithError(err).Error("failed to connect to ClickHouse")
+		return 1
+	}
+	defer store.Close()
+
+	// A one-off range needs no checkpoint
+	if opts.From != "" {
+		if _, err := newMEVScanner(cfg, store, nil, logger).Scan(ctx, q); err != nil && ctx.Err() == nil {
+			logger.WithError(err).Error("mev scan failed")
+			return 1
+		}
+		return 0
+	}
+
+	redisCfg := redisConfig(cfg)
+	redisCfg.Logger = logger
+	redisCache, err := cache.NewRedisCache(ctx, redisCfg)
+	if err != nil {
+		logger.WithError(err).Error("failed to connect to Redis")
+		return 1
+	}
+	defer redisCache.Close()
+
+	defer serveMetrics(cfg.MetricsAddr, logger)()
+
+	logger.WithField("interval", cfg.MEVScanInterval).Info("sandwich scanner started")
+	if err := newMEVScanner(cfg, store, redisCache, logger).Run(ctx); err != nil {
+		logger.WithError(err).Error("sandwich scanner stopped")
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MigrateOptions are the settings of `migrate`
+type MigrateOptions struct {
+	ConfigPath string
+	File       string        // schema file (default init.sql)
+	DryRun     bool          // print the statements instead of running them
+	Timeout    time.Duration // timeout per statement (default 30s)
+}
+
+// RunMigrate applies the ClickHouse schema in File to CLICKHOUSE_DATABASE.
+// Every statement in init.sql is idempotent (IF NOT EXISTS), so it is safe
+// to run on every deploy. It returns the process exit code.
+func RunMigrate(opts MigrateOptions) int {
+	if opts.File == "" {
+		opts.File = "init.sql"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	data, err := os.ReadFile(opts.File)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read schema:", err)
+		return 1
+	}
+	stmts := splitStatements(string(data))
+
+	if opts.DryRun {
+		for _, stmt := range stmts {
+			fmt.Printf("%s;\n\n", stmt)
+		}
+		return 0
+	}
+
+	logger := NewLogger("2006-01-02 15:04:05")
+	cfg, _ := Bootstrap(opts.ConfigPath, logger, logrus.InfoLevel)
+
+	ctx := context.Background()
+	store, err := newClickHouseStore(ctx, cfg, logger)
+	if err != nil {
+		logger.WithError(err).Error("failed to connect to ClickHouse")
+		return 1
+	}
+	defer store.Close()
+
+	for i, stmt := range stmts {
+		sctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+		err := store.Exec(sctx, stmt)
+		cancel()
+		if err != nil {
+			logger.WithError(err).WithField("statement", i+1).Errorf("migration failed: %s", firstLine(stmt))
+			return 1
+		}
+		logger.Debugf("applied: %s", firstLine(stmt))
+	}
+	logger.WithFields(logrus.Fields{
+		"file":       opts.File,
+		"database":   cfg.ClickHouseDatabase,
+		"statements": len(stmts),
+	}).Info("schema applied")
+	return 0
+}
+
+// splitStatements splits a SQL script on ';', dropping "--" comment lines
+// and USE statements (the database is CLICKHOUSE_DATABASE)
+func splitStatements(script string) []string {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var out []string
+	for _, stmt := range strings.Split(strings.Join(lines, "\n"), ";") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" || strings.HasPrefix(strings.ToUpper(stmt), "USE ") {
+			continue
+		}
+		out = append(out, stmt)
+	}
+	return out
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/indexer"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// ReplayOptions are the settings of `indexer replay`
+type ReplayOptions struct {
+	ConfigPath string
+	From       string  // start of the range, inclusive (required)
+	To         string  // end of the range, exclusive (default: now)
+	Pair       string  // only replay this pair (default: all pairs)
+	Rate       float64 // swaps published per second; 0 for unthrottled
+}
+
+// replayTimeLayouts are the accepted From/To formats
+var replayTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"}
+
+// parseReplayTime parses a From/To value; times without a zone are UTC
+func parseReplayTime(s string) (time.Time, error) {
+	for _, layout := range replayTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want RFC3339 or YYYY-MM-DD)", s)
+}
+
+// RunReplay republishes stored swaps from ClickHouse on the swaps:live
+// Pub/Sub channel at a fixed rate. It returns the process exit code.
+func RunReplay(opts ReplayOptions) int {
+	logger := NewLogger("2006-01-02 15:04:05")
+	cfg, _ := Bootstrap(opts.ConfigPath, logger, logrus.InfoLevel)
+
+	if opts.From == "" {
+		logger.Error("--from is required")
+		return 2
+	}
+	q := storage.SwapQuery{To: time.Now().UTC(), Pair: opts.Pair}
+	var err error
+	if q.From, err = parseReplayTime(opts.From); err != nil {
+		logger.WithError(err).Error("invalid --from")
+		return 2
+	}
+	if opts.To != "" {
+		if q.To, err = parseReplayTime(opts.To); err != nil {
+			logger.WithError(err).Error("invalid --to")
+			return 2
+		}
+	}
+	if !q.From.Before(q.To) {
+		logger.Error("--from must be before --to")
+		return 2
+	}
+
+	// Stop cleanly on Ctrl+C; whatever was published stays published
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	redisCfg := redisConfig(cfg)
+	redisCfg.Logger = logger
+	redisCache, err := cache.NewRedisCache(ctx, redisCfg)
+	if err != nil {
+		logger.WithError(err).Error("failed to connect to Redis")
+		return 1
+	}
+	defer redisCache.Close()
+
+	clickhouseStore, err := newClickHouseStore(ctx, cfg, logger)
+	if err != nil {
+		logger.WithError(err).Error("failed to connect to ClickHouse")
+		return 1
+	}
+	defer clickhouseStore.Close()
+
+	logger.WithFields(logrus.Fields{
+		"from":    q.From,
+		"to":      q.To,
+		"pair":    q.Pair,
+		"rate":    opts.Rate,
+		"channel": constants.PubSubChannelSwaps,
+	}).Info("replaying swaps")
+
+	if _, err := indexer.Replay(ctx, indexer.ReplayConfig{
+		History:   clickhouseStore,
+		Publisher: redisCache,
+		Rate:      opts.Rate,
+		Logger:    logger,
+	}, q); err != nil && ctx.Err() == nil {
+		logger.WithError(err).Error("replay failed")
+		return 1
+	}
+	return 0
+}
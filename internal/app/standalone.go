@@ -0,0 +1,146 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/indexer"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/server"
+	"github.com/sirupsen/logrus"
+)
+
+// RunStandalone runs the indexer and the HTTP API in one process on the
+// in-memory cache and store instead of Redis and ClickHouse, for local
+// development and demos. Nothing survives a restart, and the features that
+// need Redis or ClickHouse (feature flags, dead letters, leader election,
+// config reloads, analytics, AI, swap execution) are off. The Redis and
+// ClickHouse settings are still read but never connected to.
+func RunStandalone(configPath string) {
+	logger := NewLogger("2006-01-02 15:04:05")
+	cfg, _ := Bootstrap(configPath, logger, logrus.InfoLevel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	swapCache := cache.NewMemoryCache(cfg.MaxRecentSwaps, cfg.PriceTTL)
+	store := cache.NewMemoryStore(0)
+
+	finality, err := indexer.FinalityTrackerFromConfig(cfg, store, swapCache, logging.Module(logger, logging.ModuleIndexer))
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create finality tracker")
+	}
+	idx := indexer.New(indexer.Config{
+		Cache:    swapCache,
+		Store:    store,
+		Logger:   logging.Module(logger, logging.ModuleIndexer),
+		Provider: cfg.StreamProvider, // STREAM_PROVIDER
+
+		DrainTimeout:   cfg.DrainTimeout,              // INDEXER_DRAIN_TIMEOUT
+		Filter:         indexer.FilterFromConfig(cfg), // INDEXER_FILTER_*
+		DedupWindow:    cfg.DedupWindow,               // INDEXER_DEDUP_WINDOW
+		Processors:     indexer.Enrichers(nil),        // token labels, then USD value
+		DisabledStages: cfg.DisabledStages,            // INDEXER_DISABLED_STAGES
+		Finality:       finality,                      // INDEXER_FINALITY_INTERVAL
+	})
+	poller, err := indexer.NewPoller(cfg, indexer.PollerOptions{
+		Logger: logging.Module(logger, logging.ModuleStream),
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create poller")
+	}
+	supervisor := indexer.NewSupervisor(cfg, poller, logging.Module(logger, logging.ModuleStream))
+	reporter := indexer.NewStatusReporter(indexer.InstanceID(cfg), poller).WithStream(supervisor)
+
+	// Simulates and relays transactions API clients build and sign themselves
+	txWallet := newTxWallet(cfg, logger)
+	h := &server.Handlers{
+		Cache:     swapCache,
+		DevMode:   cfg.DevMode,
+		Logger:    logging.Module(logger, logging.ModuleAPI),
+		Jupiter:   newJupiterClient(cfg),
+		Indexers:  localStatus{reporter},
+		SwapStore: store,
+		Exports:   store,
+
+		PriceStaleAfter: cfg.PriceStaleAfter,
+		MaxSlotLag:      cfg.ReadyMaxSlotLag,
+		MaxRecentSwaps:  cfg.MaxRecentSwaps,
+		ExportMaxRows:   cfg.ExportMaxRows,
+
+		Chain: rpc.NewClient(rpc.ClientConfig{
+			BaseURL:      cfg.RPCUrl,
+			Timeout:      cfg.HTTPTimeout,
+			MaxRetries:   cfg.MaxRetries,
+			RetryBackoff: cfg.RetryBackoff,
+			Logger:       logger,
+		}),
+		Simulator: txWallet,
+		Relay:     txWallet,
+	}
+	srv, err := server.NewServer(server.ServerDeps{Handlers: h, Config: apiServerConfig(cfg)})
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create http server")
+	}
+	stopGRPC := serveGRPC(ctx, cfg, swapCache, logger)
+
+	var (
+		wg    sync.WaitGroup
+		errCh = make(chan error, 2)
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := idx.Run(ctx, supervisor); err != nil {
+			errCh <- fmt.Errorf("indexer: %w", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := srv.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- fmt.Errorf("api: %w", err)
+		}
+	}()
+
+	logger.WithFields(logrus.Fields{
+		"app_env":  cfg.AppEnv,
+		"provider": cfg.StreamProvider,
+		"addr":     cfg.APIAddr,
+	}).Warn("running standalone: swaps are kept in memory only and lost on exit")
+
+	select {
+	case <-sigCh:
+		logger.Info("shutting down gracefully")
+	case err := <-errCh:
+		logger.WithError(err).Error("service failed, shutting down")
+	}
+	cancel()
+	if err := srv.Shutdown(context.Background()); err != nil {
+		logger.WithError(err).Warn("api shutdown")
+	}
+	wg.Wait()
+	stopGRPC()
+	_ = idx.Close()
+	logger.Info("all services stopped")
+}
+
+// localStatus reports this process's indexer as the only live replica
+type localStatus struct {
+	reporter *indexer.StatusReporter
+}
+
+func (l localStatus) ListIndexerStatus(context.Context) ([]models.IndexerStatus, error) {
+	return []models.IndexerStatus{*l.reporter.Status()}, nil
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/consumer"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// SubscriberOptions are the settings of the subscriber
+type SubscriberOptions struct {
+	ConfigPath string
+	Consumers  string // consumer config (routes and sinks); empty prints swaps:live
+	Format     string // output of the default consumer: table or json
+	Group      string // read the durable swap stream as this consumer group
+	Consumer   string // consumer name within Group (default: hostname-pid)
+	FromStart  bool   // a new Group starts at the oldest retained swap
+	Wallet     string // the default consumer follows this wallet's channel instead of swaps:live
+}
+
+// RunSubscriber runs a consumer over live Pub/Sub or, with Group, the
+// durable swap stream until SIGINT/SIGTERM
+func RunSubscriber(opts SubscriberOptions) {
+	logger := NewLogger("15:04:05")
+	// default: warn to keep output clean
+	cfg, _ := Bootstrap(opts.ConfigPath, logger, logrus.WarnLevel)
+
+	if opts.Wallet != "" && opts.Group != "" {
+		logger.Fatal("-wallet follows live Pub/Sub and cannot be combined with -group")
+	}
+	if opts.Format == "" {
+		opts.Format = consumer.FormatTable
+	}
+
+	// Routes and sinks: from -consumers, or the live viewer on swaps:live
+	// (or one wallet's channel)
+	channel := constants.PubSubChannelSwaps
+	if opts.Wallet != "" {
+		channel = cache.WalletChannel(opts.Wallet)
+	}
+	fc := &consumer.FileConfig{Routes: []consumer.RouteConfig{{
+		Channel: channel,
+		Sinks:   []consumer.SinkConfig{{Type: consumer.SinkStdout, Format: opts.Format}},
+	}}}
+	if opts.Consumers != "" {
+		var err error
+		if fc, err = consumer.LoadConfig(opts.Consumers); err != nil {
+			logger.WithError(err).Fatal("failed to load consumer config")
+		}
+	}
+	if fc.ReorderWindow == 0 {
+		fc.ReorderWindow = cfg.ConsumerReorderWindow // CONSUMER_REORDER_WINDOW
+	}
+	if opts.Group != "" {
+		// the durable stream replaces every route's channel
+		for i := range fc.Routes {
+			fc.Routes[i].Channel = constants.RedisStreamSwaps
+		}
+	}
+	c, closeSinks, err := fc.Build(logger)
+	if err != nil {
+		logger.WithError(err).Fatal("invalid consumer config")
+	}
+	defer closeSinks()
+
+	// Create context with cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	// Connect to Redis
+	redisCfg := redisConfig(cfg)
+	redisCfg.Logger = logger
+	redisCache, err := cache.NewRedisCache(ctx, redisCfg)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to connect to Redis")
+	}
+	defer redisCache.Close()
+
+	// Prometheus metrics (METRICS_ADDR): consumer_messages_total and friends
+	defer serveMetrics(cfg.MetricsAddr, logger)()
+
+	// Print header for the default live viewer
+	if opts.Consumers == "" && opts.Format == consumer.FormatTable {
+		printSwapHeader()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var err error
+		if opts.Group != "" {
+			// Consume the durable stream: missed swaps are replayed and each is acked once its sinks accept it
+			cc := storage.ConsumerConfig{Group: opts.Group, Consumer: opts.Consumer}
+			if cc.Consumer == "" {
+				host, _ := os.Hostname()
+				cc.Consumer = fmt.Sprintf("%s-%d", host, os.Getpid())
+			}
+			if opts.FromStart {
+				cc.StartID = "0"
+			}
+			err = c.RunStream(ctx, redisCache, cc)
+		} else {
+			err = c.Run(ctx, redisCache.Client())
+		}
+		if err != nil && ctx.Err() == nil {
+			logger.WithError(err).Error("consumer stopped")
+		}
+	}()
+
+	// Wait for shutdown signal
+	<-sigChan
+	fmt.Println("\n\nShutting down...")
+	cancel()
+	<-done
+}
+
+func printSwapHeader() {
+	fmt.Println()
+	fmt.Println("╔════════════════════════════════════════════════════════════════════════════════════════════════════════════╗")
+	fmt.Println("║                              Live Swap Viewer - Solana Swap Indexer (Pub/Sub)                              ║")
+	fmt.Println("╠════════════════════════════════════════════════════════════════════════════════════════════════════════════╣")
+	fmt.Println("║  Time     │ Pair                 │ Amount In              │ Amount Out             │ Price        │ Sig    ║")
+	fmt.Println("╚════════════════════════════════════════════════════════════════════════════════════════════════════════════╝")
+}
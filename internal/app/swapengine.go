@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/secrets"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
+)
+
+// Swap engine modes
+const (
+	SwapModeQuote   = "quote"
+	SwapModeExecute = "execute"
+)
+
+// SwapOptions are the settings of the swap engine CLI
+type SwapOptions struct {
+	ConfigPath  string
+	Mode        string  // quote or execute
+	In          string  // input token symbol (e.g. SOL)
+	Out         string  // output token symbol (e.g. USDC)
+	Amount      float64 // amount in human units (e.g. 0.1)
+	SlippageBps int     // slippage in bps (e.g. 100 = 1%)
+}
+
+// RunSwap quotes or executes one swap through the swap engine and returns
+// the process exit code
+func RunSwap(opts SwapOptions) int {
+	// the engine reads its own settings; only .env and the config file are needed
+	loadEnvQuiet()
+	if err := config.LoadFile(opts.ConfigPath); err != nil {
+		fmt.Println("failed to load config file:", err)
+		return 1
+	}
+	if _, err := secrets.LoadFromEnv(context.Background(), nil); err != nil {
+		fmt.Println("failed to load secrets:", err)
+		return 1
+	}
+
+	if opts.Amount <= 0 {
+		fmt.Println("missing amount (must be > 0)")
+		return 2
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	engine, err := swapengine.NewEngineFromEnv(quietLogger())
+	if err != nil {
+		fmt.Println("failed to init swapengine:", err)
+		return 1
+	}
+	defer engine.Close()
+
+	slip := uint16(opts.SlippageBps)
+	intent := &swapengine.SwapIntent{
+		InputToken:  opts.In,
+		OutputToken: opts.Out,
+		Amount:      opts.Amount,
+		SlippageBps: &slip,
+		RequestedAt: time.Now(),
+	}
+
+	switch opts.Mode {
+	case SwapModeQuote:
+		q, err := engine.GetQuote(ctx, intent)
+		if err != nil {
+			fmt.Println("quote failed:", err)
+			return 1
+		}
+		fmt.Printf("pool=%s amount_in=%d amount_out=%d min_out=%d price_impact=%.4f fee_bps=%d\n",
+			q.PoolName, q.AmountIn, q.AmountOut, q.MinAmountOut, q.PriceImpact, q.FeeBps)
+	case SwapModeExecute:
+		res, err := engine.ExecuteAISwap(ctx, intent)
+		if err != nil {
+			fmt.Println("execute failed:", err)
+			return 1
+		}
+		fmt.Printf("success=%v sig=%s duration=%s\n", res.Success, res.Signature, res.Duration)
+	default:
+		fmt.Println("invalid mode (use quote|execute)")
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"context"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/consumer"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/ticker"
+	"github.com/sirupsen/logrus"
+)
+
+// newTickerConsumer builds the per-pair ticker from the TICKER_* settings and
+// a consumer that feeds it every swap on swaps:live. Tickers are published on
+// swaps:ticker:<pair> every TICKER_INTERVAL until ctx is cancelled.
+func newTickerConsumer(ctx context.Context, cfg *config.Config, publisher ticker.Publisher, logger *logrus.Logger) *consumer.Consumer {
+	agg := ticker.NewAggregator(ticker.Config{
+		Interval:  cfg.TickerInterval,
+		Publisher: publisher,
+		Logger:    logger,
+	})
+	go agg.Run(ctx)
+
+	c := consumer.New(consumer.Config{Workers: 1, ReorderWindow: cfg.ConsumerReorderWindow, Logger: logger})
+	c.Handle(constants.PubSubChannelSwaps, func(_ context.Context, msg *consumer.Message) error {
+		if msg.Swap != nil {
+			agg.Observe(msg.Swap)
+		}
+		return nil
+	})
+	logger.WithField("interval", cfg.TickerInterval).Info("ticker started")
+	return c
+}
+
+// RunTicker publishes per-pair tickers until SIGINT/SIGTERM
+func RunTicker(configPath string) {
+	runLiveConsumer(configPath, "ticker", liveDetectors[serviceTicker])
+}
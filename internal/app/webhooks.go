@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/consumer"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/notify"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// newWebhookConsumer builds the subscription router and a consumer that
+// hands it every swap on swaps:live. Swaps are posted to the webhooks of the
+// /v1/subscriptions they match; subscriptions are reread until ctx is
+// cancelled.
+func newWebhookConsumer(ctx context.Context, cfg *config.Config, store storage.SubscriptionStore, logger *logrus.Logger) *consumer.Consumer {
+	router := notify.NewRouter(notify.RouterConfig{Store: store, Webhooks: webhookPolicy(cfg), Logger: logger})
+	go router.Run(ctx)
+
+	// Several workers so one slow webhook does not hold up the others
+	c := consumer.New(consumer.Config{Workers: constants.SubscriptionWebhookWorkers, Logger: logger})
+	c.Handle(constants.PubSubChannelSwaps, func(ctx context.Context, msg *consumer.Message) error {
+		if msg.Swap == nil {
+			return nil
+		}
+		return router.Route(ctx, msg.Swap)
+	})
+	logger.Info("subscription webhooks started")
+	return c
+}
+
+// RunWebhooks posts live swaps to subscription webhooks until SIGINT/SIGTERM
+func RunWebhooks(configPath string) {
+	runLiveConsumer(configPath, "subscription webhooks", liveDetectors[serviceWebhooks])
+}
@@ -0,0 +1,122 @@
+// Package apperr defines the error codes shared by the cache, RPC, Jupiter
+// and swap engine packages, so the API answers every failure of one kind
+// with the same HTTP status and category.
+//
+// A Code is itself an error: errors.Is(err, apperr.NotFound) reports whether
+// err, or anything it wraps, has that code. Packages either return an *Error
+// or give their own error types an Is method matching a Code.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Code is the category of a failure, as reported in the API error "category"
+type Code string
+
+const (
+	NotFound            Code = "not_found"            // the thing asked for does not exist
+	RateLimited         Code = "rate_limited"         // too many requests, here or upstream; retry later
+	UpstreamUnavailable Code = "upstream_unavailable" // Redis, ClickHouse, the RPC node or Jupiter failed
+	ValidationFailed    Code = "validation_failed"    // the request itself is invalid
+	RiskRejected        Code = "risk_rejected"        // a swap the risk limits refuse
+)
+
+// Codes lists every code, in the order CodeOf checks them
+var Codes = []Code{ValidationFailed, NotFound, RiskRejected, RateLimited, UpstreamUnavailable}
+
+func (c Code) Error() string { return string(c) }
+
+// HTTPStatus is the status the API answers a code with; 500 for no code
+func (c Code) HTTPStatus() int {
+	switch c {
+	case NotFound:
+		return http.StatusNotFound
+	case RateLimited:
+		return http.StatusTooManyRequests
+	case UpstreamUnavailable:
+		return http.StatusServiceUnavailable
+	case ValidationFailed:
+		return http.StatusBadRequest
+	case RiskRejected:
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// ForStatus is the code of an HTTP status, from the API or an upstream; ""
+// for statuses without one
+func ForStatus(status int) Code {
+	switch {
+	case status == http.StatusNotFound:
+		return NotFound
+	case status == http.StatusTooManyRequests:
+		return RateLimited
+	case status == http.StatusBadRequest:
+		return ValidationFailed
+	case status >= 500 && status != http.StatusNotImplemented:
+		return UpstreamUnavailable
+	}
+	return ""
+}
+
+// Error is an error with a code
+type Error struct {
+	Code Code
+	Msg  string
+	Err  error // cause, may be nil
+}
+
+// New returns an error with a code and message
+func New(code Code, msg string) *Error {
+	return &Error{Code: code, Msg: msg}
+}
+
+// Errorf formats a message (%w wraps a cause) and gives it a code
+func Errorf(code Code, format string, args ...any) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{Code: code, Msg: err.Error(), Err: errors.Unwrap(err)}
+}
+
+// Wrap gives err a code, keeping its message; nil stays nil
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Msg: err.Error(), Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Msg == "" {
+		return string(e.Code)
+	}
+	return e.Msg
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is reports whether target is e's code
+func (e *Error) Is(target error) bool {
+	c, ok := target.(Code)
+	return ok && c == e.Code
+}
+
+// CodeOf returns the code of err; "" for nil or an error without one
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	for _, c := range Codes {
+		if errors.Is(err, c) {
+			return c
+		}
+	}
+	return ""
+}
+
+// HTTPStatus is the status the API answers err with: its code's, or 500
+func HTTPStatus(err error) int {
+	return CodeOf(err).HTTPStatus()
+}
@@ -0,0 +1,44 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOf(t *testing.T) {
+	cause := errors.New("dial tcp: connection refused")
+	err := fmt.Errorf("get price: %w", Errorf(UpstreamUnavailable, "redis: %w", cause))
+
+	assert.Equal(t, UpstreamUnavailable, CodeOf(err))
+	assert.ErrorIs(t, err, UpstreamUnavailable)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, NotFound)
+	assert.Equal(t, "get price: redis: dial tcp: connection refused", err.Error())
+	assert.Equal(t, http.StatusServiceUnavailable, HTTPStatus(err))
+
+	assert.Equal(t, Code(""), CodeOf(cause))
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatus(cause))
+	assert.Nil(t, Wrap(NotFound, nil))
+}
+
+func TestSentinelsKeepTheirIdentity(t *testing.T) {
+	errMissing := New(NotFound, "account not found")
+	err := fmt.Errorf("abc: %w", errMissing)
+	assert.ErrorIs(t, err, errMissing)
+	assert.ErrorIs(t, err, NotFound)
+	assert.NotErrorIs(t, fmt.Errorf("x: %w", New(NotFound, "other")), errMissing)
+}
+
+func TestStatuses(t *testing.T) {
+	for _, c := range Codes {
+		assert.NotEqual(t, http.StatusInternalServerError, c.HTTPStatus(), c)
+	}
+	assert.Equal(t, RateLimited, ForStatus(http.StatusTooManyRequests))
+	assert.Equal(t, UpstreamUnavailable, ForStatus(http.StatusBadGateway))
+	assert.Equal(t, ValidationFailed, ForStatus(http.StatusBadRequest))
+	assert.Equal(t, Code(""), ForStatus(http.StatusConflict))
+}
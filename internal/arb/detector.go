@@ -0,0 +1,268 @@
+// Package arb watches the live swap feed for the same pair trading at
+// different prices on different venues. Each DEX's latest swap price, and
+// optionally a Jupiter quote, is kept per pair; a gap above the threshold
+// between two fresh prices is published as an opportunity.
+package arb
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/jupiter"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// VenueJupiter names Jupiter quotes among the DEX venues
+const VenueJupiter = "Jupiter"
+
+// Publisher receives detected opportunities; *cache.RedisCache implements it
+type Publisher interface {
+	PublishArbOpportunity(ctx context.Context, op *models.ArbOpportunity) error
+}
+
+// Quoter returns Jupiter quotes; *jupiter.Client implements it
+type Quoter interface {
+	Quote(ctx context.Context, req jupiter.QuoteRequest) (*jupiter.QuoteResponse, error)
+}
+
+// Config tunes a Detector
+type Config struct {
+	ThresholdBps    float64       // smallest spread reported (default constants.ArbThresholdBps)
+	MaxPriceAge     time.Duration // older venue prices are ignored (default constants.ArbMaxPriceAge)
+	Cooldown        time.Duration // the same pair and venues are reported at most this often (default constants.ArbCooldown)
+	JupiterInterval time.Duration // per-pair Jupiter refresh; 0 leaves Jupiter out
+	Quoter          Quoter        // required with JupiterInterval
+	Publisher       Publisher
+	Logger          *logrus.Logger
+}
+
+// venuePrice is the latest price of a pair on one venue
+type venuePrice struct {
+	price float64 // QUOTE per BASE
+	at    time.Time
+}
+
+// pairState is what the detector knows about one pair
+type pairState struct {
+	venues      map[string]venuePrice
+	jupiterAt   time.Time // last Jupiter refresh started
+	jupiterBusy bool
+}
+
+// Detector compares the latest price of each pair across venues
+type Detector struct {
+	cfg   Config
+	now   func() time.Time
+	mints map[string]string // symbol -> mint, for Jupiter quotes
+
+	mu       sync.Mutex
+	pairs    map[string]*pairState
+	reported map[string]time.Time // pair|buy|sell -> last report
+}
+
+// NewDetector creates a detector, filling in defaults
+func NewDetector(cfg Config) *Detector {
+	if cfg.ThresholdBps <= 0 {
+		cfg.ThresholdBps = constants.ArbThresholdBps
+	}
+	if cfg.MaxPriceAge <= 0 {
+		cfg.MaxPriceAge = constants.ArbMaxPriceAge
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = constants.ArbCooldown
+	}
+	if cfg.Quoter == nil {
+		cfg.JupiterInterval = 0
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	mints := make(map[string]string, len(constants.TokenSymbols))
+	for mint, symbol := range constants.TokenSymbols {
+		mints[symbol] = mint
+	}
+	return &Detector{
+		cfg:      cfg,
+		now:      time.Now,
+		mints:    mints,
+		pairs:    make(map[string]*pairState),
+		reported: make(map[string]time.Time),
+	}
+}
+
+// Observe records the swap's price for its DEX and publishes any
+// opportunity it opens. With Jupiter enabled it also refreshes the pair's
+// Jupiter quote in the background, at most once per JupiterInterval.
+func (d *Detector) Observe(ctx context.Context, swap *models.SwapEvent) error {
+	pair, price, ok := canonical(swap.TokenIn, swap.TokenOut, swap.AmountIn, swap.AmountOut)
+	if !ok || swap.Dex == "" {
+		return nil
+	}
+	at := swap.Timestamp
+	if at.IsZero() {
+		at = d.now()
+	}
+
+	ops := d.update(pair, swap.Dex, venuePrice{price: price, at: at})
+	if d.jupiterDue(pair) {
+		go d.refreshJupiter(context.WithoutCancel(ctx), pair, swap)
+	}
+	return d.publish(ctx, ops)
+}
+
+// update stores a venue price and returns the opportunities it opens
+func (d *Detector) update(pair, venue string, vp venuePrice) []*models.ArbOpportunity {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st := d.pairs[pair]
+	if st == nil {
+		st = &pairState{venues: make(map[string]venuePrice)}
+		d.pairs[pair] = st
+	}
+	st.venues[venue] = vp
+
+	// Compare the updated venue with every other fresh one
+	now := d.now()
+	var ops []*models.ArbOpportunity
+	for other, op := range st.venues {
+		if other == venue {
+			continue
+		}
+		if now.Sub(op.at) > d.cfg.MaxPriceAge {
+			delete(st.venues, other)
+			continue
+		}
+		buy, sell, buyP, sellP := venue, other, vp, op
+		if op.price < vp.price {
+			buy, sell, buyP, sellP = other, venue, op, vp
+		}
+		spread := (sellP.price/buyP.price - 1) * 10000
+		if spread < d.cfg.ThresholdBps {
+			continue
+		}
+		key := pair + "|" + buy + "|" + sell
+		if last, ok := d.reported[key]; ok && now.Sub(last) < d.cfg.Cooldown {
+			continue
+		}
+		d.reported[key] = now
+		ops = append(ops, &models.ArbOpportunity{
+			Pair: pair, BuyVenue: buy, SellVenue: sell,
+			BuyPrice: buyP.price, SellPrice: sellP.price, SpreadBps: spread,
+			BuyAt: buyP.at, SellAt: sellP.at, DetectedAt: now,
+		})
+	}
+	return ops
+}
+
+// jupiterDue reports whether pair's Jupiter quote should be refreshed now,
+// and marks the refresh as started
+func (d *Detector) jupiterDue(pair string) bool {
+	if d.cfg.JupiterInterval <= 0 {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	st := d.pairs[pair]
+	now := d.now()
+	if st == nil || st.jupiterBusy || now.Sub(st.jupiterAt) < d.cfg.JupiterInterval {
+		return false
+	}
+	st.jupiterBusy, st.jupiterAt = true, now
+	return true
+}
+
+// refreshJupiter quotes the swap's own size through Jupiter and records the
+// result as the pair's Jupiter price
+func (d *Detector) refreshJupiter(ctx context.Context, pair string, swap *models.SwapEvent) {
+	defer func() {
+		d.mu.Lock()
+		if st := d.pairs[pair]; st != nil {
+			st.jupiterBusy = false
+		}
+		d.mu.Unlock()
+	}()
+
+	inMint, outMint := d.mints[swap.TokenIn], d.mints[swap.TokenOut]
+	// Raw amounts and decimals are needed to size the quote like the swap
+	if inMint == "" || outMint == "" || swap.AmountInRaw == 0 || swap.DecimalsIn == 0 && swap.DecimalsOut == 0 {
+		return
+	}
+	q, err := d.cfg.Quoter.Quote(ctx, jupiter.QuoteRequest{
+		InputMint:  inMint,
+		OutputMint: outMint,
+		Amount:     strconv.FormatUint(swap.AmountInRaw, 10),
+	})
+	if err != nil {
+		d.cfg.Logger.WithError(err).WithField("pair", pair).Debug("arb: jupiter quote failed")
+		return
+	}
+	amountIn, okIn := scaled(q.InAmount, swap.DecimalsIn)
+	amountOut, okOut := scaled(q.OutAmount, swap.DecimalsOut)
+	if !okIn || !okOut {
+		return
+	}
+	pair, price, ok := canonical(swap.TokenIn, swap.TokenOut, amountIn, amountOut)
+	if !ok {
+		return
+	}
+	ops := d.update(pair, VenueJupiter, venuePrice{price: price, at: d.now()})
+	if err := d.publish(ctx, ops); err != nil {
+		d.cfg.Logger.WithError(err).Warn("arb: failed to publish opportunity")
+	}
+}
+
+// publish hands opportunities to the publisher, returning the first error
+func (d *Detector) publish(ctx context.Context, ops []*models.ArbOpportunity) error {
+	var first error
+	for _, op := range ops {
+		d.cfg.Logger.WithFields(logrus.Fields{
+			"pair":       op.Pair,
+			"buy":        op.BuyVenue,
+			"sell":       op.SellVenue,
+			"spread_bps": strconv.FormatFloat(op.SpreadBps, 'f', 1, 64),
+		}).Info("arbitrage opportunity")
+		if d.cfg.Publisher == nil {
+			continue
+		}
+		if err := d.cfg.Publisher.PublishArbOpportunity(ctx, op); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// canonical orders a pair alphabetically (BASE/QUOTE) and returns the price
+// of the trade as QUOTE per BASE
+func canonical(tokenIn, tokenOut string, amountIn, amountOut float64) (pair string, price float64, ok bool) {
+	if tokenIn == "" || tokenOut == "" || tokenIn == tokenOut || amountIn <= 0 || amountOut <= 0 {
+		return "", 0, false
+	}
+	if tokenIn < tokenOut {
+		return tokenIn + "/" + tokenOut, amountOut / amountIn, true
+	}
+	return tokenOut + "/" + tokenIn, amountIn / amountOut, true
+}
+
+// scaled converts a raw integer amount to UI units
+func scaled(raw string, decimals uint8) (float64, bool) {
+	n, ok := new(big.Float).SetString(raw)
+	if !ok {
+		return 0, false
+	}
+	f, _ := n.Quo(n, new(big.Float).SetFloat64(pow10(decimals))).Float64()
+	return f, f > 0
+}
+
+func pow10(n uint8) float64 {
+	f := 1.0
+	for range n {
+		f *= 10
+	}
+	return f
+}
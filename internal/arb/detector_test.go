@@ -0,0 +1,110 @@
+package arb
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/jupiter"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu  sync.Mutex
+	ops []*models.ArbOpportunity
+}
+
+func (r *recorder) PublishArbOpportunity(_ context.Context, op *models.ArbOpportunity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops = append(r.ops, op)
+	return nil
+}
+
+func (r *recorder) published() []*models.ArbOpportunity {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*models.ArbOpportunity(nil), r.ops...)
+}
+
+func swap(dex, in, out string, amountIn, amountOut float64, at time.Time) *models.SwapEvent {
+	return &models.SwapEvent{Dex: dex, TokenIn: in, TokenOut: out, AmountIn: amountIn, AmountOut: amountOut, Timestamp: at}
+}
+
+func newTestDetector(pub Publisher, now *time.Time) *Detector {
+	d := NewDetector(Config{ThresholdBps: 50, MaxPriceAge: 30 * time.Second, Cooldown: time.Minute, Publisher: pub})
+	d.now = func() time.Time { return *now }
+	return d
+}
+
+func TestDetectorFlagsSpread(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	pub := &recorder{}
+	d := newTestDetector(pub, &now)
+
+	// 1 SOL -> 100 USDC on Orca; 101 USDC -> 1 SOL on Raydium is the reverse direction
+	require.NoError(t, d.Observe(ctx, swap("Orca", "SOL", "USDC", 1, 100, now)))
+	require.NoError(t, d.Observe(ctx, swap("Raydium", "USDC", "SOL", 101, 1, now)))
+
+	ops := pub.published()
+	require.Len(t, ops, 1)
+	op := ops[0]
+	assert.Equal(t, "SOL/USDC", op.Pair)
+	assert.Equal(t, "Orca", op.BuyVenue)
+	assert.Equal(t, "Raydium", op.SellVenue)
+	assert.InDelta(t, 100, op.SpreadBps, 0.001)
+
+	// the same gap is not reported again within the cooldown
+	require.NoError(t, d.Observe(ctx, swap("Raydium", "USDC", "SOL", 101, 1, now)))
+	assert.Len(t, pub.published(), 1)
+}
+
+func TestDetectorIgnoresSmallAndStaleSpreads(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	pub := &recorder{}
+	d := newTestDetector(pub, &now)
+
+	require.NoError(t, d.Observe(ctx, swap("Orca", "SOL", "USDC", 1, 100, now)))
+	require.NoError(t, d.Observe(ctx, swap("Raydium", "SOL", "USDC", 1, 100.3, now))) // 30 bps
+	assert.Empty(t, pub.published())
+
+	now = now.Add(time.Minute)
+	require.NoError(t, d.Observe(ctx, swap("Meteora", "SOL", "USDC", 1, 110, now)))
+	assert.Empty(t, pub.published(), "older venue prices are not compared")
+}
+
+type fakeQuoter struct {
+	req jupiter.QuoteRequest
+}
+
+func (f *fakeQuoter) Quote(_ context.Context, req jupiter.QuoteRequest) (*jupiter.QuoteResponse, error) {
+	f.req = req
+	// 1 SOL (9 decimals) -> 102 USDC (6 decimals)
+	return &jupiter.QuoteResponse{InAmount: req.Amount, OutAmount: "102000000"}, nil
+}
+
+func TestDetectorComparesJupiter(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	pub := &recorder{}
+	q := &fakeQuoter{}
+	d := NewDetector(Config{ThresholdBps: 50, MaxPriceAge: time.Minute, JupiterInterval: time.Second, Quoter: q, Publisher: pub})
+	d.now = func() time.Time { return now }
+
+	s := swap("Orca", "SOL", "USDC", 1, 100, now)
+	s.AmountInRaw, s.DecimalsIn, s.DecimalsOut = 1_000_000_000, 9, 6
+	d.refreshJupiter(context.Background(), "SOL/USDC", s)
+
+	assert.Equal(t, "1000000000", q.req.Amount)
+	assert.Equal(t, "So11111111111111111111111111111111111111112", q.req.InputMint)
+	require.NoError(t, d.Observe(context.Background(), s))
+
+	ops := pub.published()
+	require.Len(t, ops, 1)
+	assert.Equal(t, VenueJupiter, ops[0].SellVenue)
+	assert.InDelta(t, 200, ops[0].SpreadBps, 0.001)
+}
@@ -0,0 +1,122 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultEndpoints are the read paths the dashboard hits most
+var DefaultEndpoints = []string{
+	"/v1/health",
+	"/v1/swaps/recent?limit=50",
+	"/v1/prices/SOL",
+	"/v1/prices/SOL/history?window=1h",
+}
+
+// APIConfig describes an HTTP load test
+type APIConfig struct {
+	BaseURL     string        // e.g. http://localhost:8090
+	APIKey      string        // sent as X-API-Key when set
+	Endpoints   []string      // GET paths, requested round-robin (default DefaultEndpoints)
+	RPS         float64       // requests per second across all endpoints (required)
+	Duration    time.Duration // how long to fire (default 10s)
+	Concurrency int           // requests in flight at most (default 16)
+	Timeout     time.Duration // per request (default 5s)
+	Client      *http.Client  // optional; overrides Timeout
+}
+
+// LoadAPI fires GET requests at a fixed rate and reports latency per
+// endpoint. Non-2xx responses and transport errors count as errors. When
+// every worker is busy the rate drops rather than queueing, so a slow API
+// shows up as lower throughput as well as higher latency.
+func LoadAPI(ctx context.Context, cfg APIConfig) (*Report, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("bench: base URL is required")
+	}
+	if cfg.RPS <= 0 {
+		return nil, fmt.Errorf("bench: rps must be > 0")
+	}
+	if len(cfg.Endpoints) == 0 {
+		cfg.Endpoints = DefaultEndpoints
+	}
+	if cfg.Duration <= 0 {
+		cfg.Duration = 10 * time.Second
+	}
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 16
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+	base := strings.TrimRight(cfg.BaseURL, "/")
+
+	lats := make([]Latencies, len(cfg.Endpoints))
+	limiter := rate.NewLimiter(rate.Limit(cfg.RPS), 1)
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	var (
+		next atomic.Uint64
+		wg   sync.WaitGroup
+	)
+	start := time.Now()
+	for range cfg.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for limiter.Wait(ctx) == nil {
+				i := int(next.Add(1)-1) % len(cfg.Endpoints)
+				_ = lats[i].Time(func() error { return get(client, base+cfg.Endpoints[i], cfg.APIKey) })
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Elapsed: time.Since(start)}
+	all := Stage{Name: "total"}
+	var total Latencies
+	for i, ep := range cfg.Endpoints {
+		lats[i].mu.Lock()
+		total.samples = append(total.samples, lats[i].samples...)
+		total.errors += lats[i].errors
+		lats[i].mu.Unlock()
+		report.Stages = append(report.Stages, Stage{Name: ep, Summary: lats[i].Summary()})
+	}
+	all.Summary = total.Summary()
+	report.Stages = append([]Stage{all}, report.Stages...)
+	return report, nil
+}
+
+// get requests url without the load test's context, so requests in flight
+// when the duration ends complete and are measured
+func get(client *http.Client, url, apiKey string) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Package bench measures the ingestion pipeline and the HTTP API under load:
+// recorded swaps are pushed through the sinks at a fixed rate and requests
+// are fired at API endpoints, and both report latency percentiles.
+package bench
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Latencies collects the outcome of timed operations; safe for concurrent use
+type Latencies struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	errors  int
+}
+
+// Record adds one operation; failed operations count as errors and their
+// latency is kept too, since slow failures are part of what callers see
+func (l *Latencies) Record(d time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.samples = append(l.samples, d)
+	if err != nil {
+		l.errors++
+	}
+}
+
+// Time runs fn and records how long it took
+func (l *Latencies) Time(fn func() error) error {
+	start := time.Now()
+	err := fn()
+	l.Record(time.Since(start), err)
+	return err
+}
+
+// Summary is the distribution of recorded latencies
+type Summary struct {
+	Count  int           `json:"count"`
+	Errors int           `json:"errors"`
+	Min    time.Duration `json:"min"`
+	Mean   time.Duration `json:"mean"`
+	P50    time.Duration `json:"p50"`
+	P90    time.Duration `json:"p90"`
+	P99    time.Duration `json:"p99"`
+	Max    time.Duration `json:"max"`
+}
+
+// Summary computes the distribution of everything recorded so far
+func (l *Latencies) Summary() Summary {
+	l.mu.Lock()
+	sorted := append([]time.Duration(nil), l.samples...)
+	errs := l.errors
+	l.mu.Unlock()
+
+	s := Summary{Count: len(sorted), Errors: errs}
+	if len(sorted) == 0 {
+		return s
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	s.Min = sorted[0]
+	s.Max = sorted[len(sorted)-1]
+	s.Mean = total / time.Duration(len(sorted))
+	s.P50 = percentile(sorted, 50)
+	s.P90 = percentile(sorted, 90)
+	s.P99 = percentile(sorted, 99)
+	return s
+}
+
+// percentile uses the nearest-rank method on sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(float64(len(sorted))*p/100+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// Stage is the summary of one measured operation, e.g. a sink or an endpoint
+type Stage struct {
+	Name string `json:"name"`
+	Summary
+}
+
+// Report is the result of a benchmark run
+type Report struct {
+	Elapsed time.Duration `json:"elapsed"`
+	Stages  []Stage       `json:"stages"`
+}
+
+// Throughput is the rate of the first stage's operations per second
+func (r *Report) Throughput() float64 {
+	if len(r.Stages) == 0 || r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Stages[0].Count) / r.Elapsed.Seconds()
+}
+
+// Check returns an error naming every stage whose p99 exceeds maxP99 or whose
+// error rate exceeds maxErrorRate (0-1); zero limits are not checked
+func (r *Report) Check(maxP99 time.Duration, maxErrorRate float64) error {
+	var failed []string
+	for _, s := range r.Stages {
+		if maxP99 > 0 && s.P99 > maxP99 {
+			failed = append(failed, fmt.Sprintf("%s p99 %s > %s", s.Name, s.P99, maxP99))
+		}
+		if maxErrorRate > 0 && s.Count > 0 && float64(s.Errors)/float64(s.Count) > maxErrorRate {
+			failed = append(failed, fmt.Sprintf("%s error rate %.2f%% > %.2f%%", s.Name, 100*float64(s.Errors)/float64(s.Count), 100*maxErrorRate))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("thresholds exceeded: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Print writes the report as a table
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tCOUNT\tERRORS\tMIN\tMEAN\tP50\tP90\tP99\tMAX")
+	for _, s := range r.Stages {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.Name, s.Count, s.Errors, round(s.Min), round(s.Mean), round(s.P50), round(s.P90), round(s.P99), round(s.Max))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nelapsed %s, %.1f ops/s\n", r.Elapsed.Round(time.Millisecond), r.Throughput())
+	return err
+}
+
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
@@ -0,0 +1,125 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencies_Summary(t *testing.T) {
+	var l Latencies
+	for i := 1; i <= 100; i++ {
+		var err error
+		if i%10 == 0 {
+			err = errors.New("boom")
+		}
+		l.Record(time.Duration(i)*time.Millisecond, err)
+	}
+
+	s := l.Summary()
+	assert.Equal(t, 100, s.Count)
+	assert.Equal(t, 10, s.Errors)
+	assert.Equal(t, time.Millisecond, s.Min)
+	assert.Equal(t, 100*time.Millisecond, s.Max)
+	assert.Equal(t, 50*time.Millisecond, s.P50)
+	assert.Equal(t, 90*time.Millisecond, s.P90)
+	assert.Equal(t, 99*time.Millisecond, s.P99)
+	assert.Equal(t, 50500*time.Microsecond, s.Mean)
+
+	assert.Zero(t, (&Latencies{}).Summary().P99)
+}
+
+func TestReport_Check(t *testing.T) {
+	r := &Report{Stages: []Stage{
+		{Name: "fast", Summary: Summary{Count: 100, P99: 10 * time.Millisecond}},
+		{Name: "slow", Summary: Summary{Count: 100, Errors: 5, P99: 300 * time.Millisecond}},
+	}}
+	assert.NoError(t, r.Check(0, 0))
+	assert.NoError(t, r.Check(time.Second, 0.1))
+
+	err := r.Check(100*time.Millisecond, 0.01)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "slow p99")
+	assert.Contains(t, err.Error(), "slow error rate")
+	assert.NotContains(t, err.Error(), "fast")
+}
+
+type recordingProcessor struct {
+	mu   sync.Mutex
+	sigs []string
+}
+
+func (p *recordingProcessor) ProcessSwap(_ context.Context, swap *models.SwapEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sigs = append(p.sigs, swap.Signature)
+	return nil
+}
+
+func TestIngest(t *testing.T) {
+	swaps, err := LoadSwaps(strings.NewReader(`{"signature":"sigA","pair":"SOL/USDC","amount_in":1}
+
+{"signature":"sigB","pair":"SOL/USDC","amount_in":2}
+`))
+	require.NoError(t, err)
+	require.Len(t, swaps, 2)
+
+	p := &recordingProcessor{}
+	report, err := Ingest(context.Background(), IngestConfig{Processor: p, Concurrency: 2, Loops: 3}, swaps)
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, report.Stages[0].Count)
+	assert.Len(t, p.sigs, 6)
+	seen := map[string]bool{}
+	for _, s := range p.sigs {
+		assert.True(t, strings.HasPrefix(s, DefaultTag), s)
+		seen[s] = true
+	}
+	assert.Len(t, seen, 6, "every replayed swap gets a unique signature")
+	assert.Equal(t, "sigA", swaps[0].Signature, "recorded swaps are not modified")
+
+	_, err = LoadSwaps(strings.NewReader(`{"pair":"SOL/USDC"}`))
+	assert.Error(t, err)
+}
+
+func TestLoadAPI(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "k", r.Header.Get("X-API-Key"))
+		if r.URL.Path == "/bad" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	report, err := LoadAPI(context.Background(), APIConfig{
+		BaseURL:   srv.URL,
+		APIKey:    "k",
+		Endpoints: []string{"/ok", "/bad"},
+		RPS:       200,
+		Duration:  200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Stages, 3)
+
+	total, ok, bad := report.Stages[0], report.Stages[1], report.Stages[2]
+	assert.Equal(t, "total", total.Name)
+	assert.Greater(t, total.Count, 10)
+	assert.Equal(t, ok.Count+bad.Count, total.Count)
+	assert.Zero(t, ok.Errors)
+	assert.Equal(t, bad.Count, bad.Errors)
+
+	_, err = LoadAPI(context.Background(), APIConfig{BaseURL: srv.URL})
+	assert.Error(t, err, "rps is required")
+}
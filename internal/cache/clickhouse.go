@@ -3,8 +3,12 @@ package cache
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage/chquery"
 	"github.com/sirupsen/logrus"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
@@ -13,8 +17,9 @@ import (
 
 // ClickHouseStore implements the SwapStore interface using ClickHouse
 type ClickHouseStore struct {
-	conn   driver.Conn
-	logger *logrus.Logger
+	conn       driver.Conn
+	logger     *logrus.Logger
+	slowInsert time.Duration
 }
 
 // ClickHouseConfig holds configuration for ClickHouse connection
@@ -24,6 +29,9 @@ type ClickHouseConfig struct {
 	Username string
 	Password string
 	Logger   *logrus.Logger
+
+	// SlowInsert logs inserts taking at least this long (0 never does)
+	SlowInsert time.Duration
 }
 
 // NewClickHouseStore creates a new ClickHouse store with connection verification
@@ -55,8 +63,9 @@ func NewClickHouseStore(ctx context.Context, cfg ClickHouseConfig) (*ClickHouseS
 	}).Info("connected to ClickHouse")
 
 	return &ClickHouseStore{
-		conn:   conn,
-		logger: cfg.Logger,
+		conn:       conn,
+		logger:     cfg.Logger,
+		slowInsert: cfg.SlowInsert,
 	}, nil
 }
 
@@ -65,41 +74,146 @@ func (c *ClickHouseStore) InsertSwap(ctx context.Context, swap *models.SwapEvent
 	query := `
 		INSERT INTO swaps (
 			signature, timestamp, pair, token_in, token_out,
-			amount_in, amount_out, price, fee, pool, dex
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			amount_in, amount_out, price, fee, pool, dex,
+			slot, block_time, amount_in_raw, amount_out_raw,
+			decimals_in, decimals_out, program_id, pool_address,
+			wallet, fee_lamports, priority_fee, compute_unit_price,
+			indexed_at, finalized, value_usd
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	err := c.conn.Exec(ctx, query,
-		swap.Signature,
-		swap.Timestamp,
-		swap.Pair,
-		swap.TokenIn,
-		swap.TokenOut,
-		swap.AmountIn,
-		swap.AmountOut,
-		swap.Price,
-		swap.Fee,
-		swap.Pool,
-		swap.Dex,
-	)
-
+	err := c.insert(ctx, "swaps", 1, func() error {
+		return c.conn.Exec(ctx, query,
+			swap.Signature,
+			swap.Timestamp,
+			swap.Pair,
+			swap.TokenIn,
+			swap.TokenOut,
+			swap.AmountIn,
+			swap.AmountOut,
+			swap.Price,
+			swap.Fee,
+			swap.Pool,
+			swap.Dex,
+			swap.Slot,
+			swap.BlockTime,
+			swap.AmountInRaw,
+			swap.AmountOutRaw,
+			swap.DecimalsIn,
+			swap.DecimalsOut,
+			swap.ProgramID,
+			swap.PoolAddress,
+			swap.Wallet,
+			swap.FeeLamports,
+			swap.PriorityFee,
+			swap.ComputeUnitPrice,
+			swap.IndexedAt,
+			swap.Finalized,
+			swap.ValueUSD,
+		)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to insert swap: %w", err)
 	}
 
-	c.logger.WithFields(logrus.Fields{
+	logging.Swaps.Debug(logging.Entry(ctx, c.logger).WithFields(logrus.Fields{
 		"signature": swap.Signature[:8],
 		"pair":      swap.Pair,
-	}).Debug("inserted swap into ClickHouse")
+	}), "inserted swap into ClickHouse")
 
 	return nil
 }
 
+// swapColumns are the columns scanSwap reads, in order; finalized is joined
+// from swap_finality
+const swapColumns = `signature, timestamp, pair, token_in, token_out,
+			amount_in, amount_out, price, fee, pool, dex,
+			slot, block_time, amount_in_raw, amount_out_raw,
+			decimals_in, decimals_out, program_id, pool_address,
+			wallet, fee_lamports, priority_fee, compute_unit_price,
+			indexed_at, value_usd, ` + chquery.Finalized
+
+// ScanSwaps streams the swaps matching q, oldest first, into fn
+func (c *ClickHouseStore) ScanSwaps(ctx context.Context, q storage.SwapQuery, fn func(*models.SwapEvent) error) error {
+	query := `
+		SELECT ` + swapColumns + `
+		FROM swaps
+		WHERE timestamp >= ? AND timestamp < ? AND (? = '' OR pair = ?) AND ` + chquery.NotRetracted + `
+		ORDER BY timestamp, signature
+	`
+	args := []any{q.From, q.To, q.Pair, q.Pair}
+	if q.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, uint64(q.Limit))
+	}
+
+	rows, err := c.conn.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query swaps: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		swap, err := scanSwap(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(swap); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// scanSwap reads a row selected with swapColumns
+func scanSwap(rows driver.Rows) (*models.SwapEvent, error) {
+	var swap models.SwapEvent
+	if err := rows.Scan(
+		&swap.Signature,
+		&swap.Timestamp,
+		&swap.Pair,
+		&swap.TokenIn,
+		&swap.TokenOut,
+		&swap.AmountIn,
+		&swap.AmountOut,
+		&swap.Price,
+		&swap.Fee,
+		&swap.Pool,
+		&swap.Dex,
+		&swap.Slot,
+		&swap.BlockTime,
+		&swap.AmountInRaw,
+		&swap.AmountOutRaw,
+		&swap.DecimalsIn,
+		&swap.DecimalsOut,
+		&swap.ProgramID,
+		&swap.PoolAddress,
+		&swap.Wallet,
+		&swap.FeeLamports,
+		&swap.PriorityFee,
+		&swap.ComputeUnitPrice,
+		&swap.IndexedAt,
+		&swap.ValueUSD,
+		&swap.Finalized,
+	); err != nil {
+		return nil, fmt.Errorf("failed to scan swap: %w", err)
+	}
+	if swap.IndexedAt.Unix() <= 0 {
+		swap.IndexedAt = time.Time{} // indexed before it was recorded
+	}
+	return &swap, nil
+}
+
 // Ping checks if ClickHouse is reachable
 func (c *ClickHouseStore) Ping(ctx context.Context) error {
 	return c.conn.Ping(ctx)
 }
 
+// Exec runs a statement that returns no rows, e.g. schema DDL
+func (c *ClickHouseStore) Exec(ctx context.Context, query string) error {
+	return c.conn.Exec(ctx, query)
+}
+
 // Close closes the ClickHouse connection
 func (c *ClickHouseStore) Close() error {
 	c.logger.Debug("closing ClickHouse connection")
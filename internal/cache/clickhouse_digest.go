@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage/chquery"
+)
+
+// Digest summarises the swaps of q's pair or token over its window
+func (c *ClickHouseStore) Digest(ctx context.Context, q storage.DigestQuery) (*models.Digest, error) {
+	dq := chquery.Digest(q)
+	var r chquery.DigestRow
+	if err := c.conn.QueryRow(ctx, dq.SQL, dq.Args...).Scan(r.Dest()...); err != nil {
+		return nil, fmt.Errorf("failed to query digest: %w", err)
+	}
+
+	d := &models.Digest{
+		Pair:       q.Pair,
+		Token:      q.Token,
+		From:       q.From,
+		To:         q.To,
+		Trades:     r.Trades,
+		Volume:     r.Volume,
+		VolumeUSD:  r.VolumeUSD,
+		OpenPrice:  r.OpenPrice,
+		ClosePrice: r.ClosePrice,
+	}
+	if r.OpenPrice > 0 {
+		d.PriceChangePct = (r.ClosePrice - r.OpenPrice) / r.OpenPrice * 100
+	}
+	if r.Trades > 0 {
+		d.Biggest = &models.DigestSwap{Signature: r.Biggest, Amount: r.BiggestAmt, AmountUSD: r.BiggestUSD}
+	}
+	return d, nil
+}
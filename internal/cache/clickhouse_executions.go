@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// InsertExecution records a swap the swap engine attempted
+func (c *ClickHouseStore) InsertExecution(ctx context.Context, ex *models.SwapExecution) error {
+	err := c.insert(ctx, "swap_executions", 1, func() error {
+		return c.conn.Exec(ctx, `
+		INSERT INTO swap_executions (
+			execution_id, signature, timestamp, wallet, pool, input_mint, output_mint,
+			amount_in, expected_out, success, error, error_kind, error_code, simulated_units,
+			compute_unit_limit, compute_unit_price, actual_units, fee_lamports, duration_ms
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+			ex.ExecutionID, ex.Signature, ex.Timestamp, ex.Wallet, ex.Pool, ex.InputMint, ex.OutputMint,
+			ex.AmountIn, ex.ExpectedOut, ex.Success, ex.Error, ex.ErrorKind, ex.ErrorCode, ex.SimulatedUnits,
+			ex.ComputeUnitLimit, ex.ComputeUnitPrice, ex.ActualUnits, ex.FeeLamports, ex.DurationMS,
+		)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert swap execution: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage/chquery"
+)
+
+// ListSwaps returns the swaps matching f, newest first
+func (c *ClickHouseStore) ListSwaps(ctx context.Context, f storage.SwapFilter) ([]*models.SwapEvent, error) {
+	q := chquery.RecentSwaps(swapColumns, f)
+	rows, err := c.conn.Query(ctx, q.SQL, q.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query swaps: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*models.SwapEvent{}
+	for rows.Next() {
+		swap, err := scanSwap(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, swap)
+	}
+	return out, rows.Err()
+}
+
+// GetSwap returns the stored swap of a transaction; nil when none is stored
+func (c *ClickHouseStore) GetSwap(ctx context.Context, signature string) (*models.SwapEvent, error) {
+	rows, err := c.conn.Query(ctx, `SELECT `+swapColumns+` FROM swaps WHERE signature = ? AND `+chquery.NotRetracted+` LIMIT 1`, signature)
+	if err != nil {
+		return nil, apperr.Errorf(apperr.UpstreamUnavailable, "failed to query swap: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	return scanSwap(rows)
+}
+
+// Candles aggregates one pair's swaps into OHLCV candles, oldest first
+func (c *ClickHouseStore) Candles(ctx context.Context, q storage.CandleQuery) ([]models.Candle, error) {
+	cq := chquery.Candles(q)
+	rows, err := c.conn.Query(ctx, cq.SQL, cq.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query candles: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.Candle{}
+	for rows.Next() {
+		k := models.Candle{Pair: q.Pair}
+		if err := rows.Scan(&k.Start, &k.Open, &k.High, &k.Low, &k.Close, &k.Volume, &k.VolumeUSD, &k.Trades); err != nil {
+			return nil, fmt.Errorf("failed to scan candle: %w", err)
+		}
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
+
+// Pairs ranks the pairs traded since q.Since by stablecoin volume, then trades
+func (c *ClickHouseStore) Pairs(ctx context.Context, q storage.MarketQuery) ([]models.PairSummary, error) {
+	agg := chquery.Aggregate{Key: "pair", Filter: storage.SwapFilter{From: q.Since, Limit: q.Limit, Offset: q.Offset}}.Query()
+	rows, err := c.conn.Query(ctx, agg.SQL, agg.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pairs: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.PairSummary{}
+	for rows.Next() {
+		var g chquery.Group
+		if err := rows.Scan(g.Dest()...); err != nil {
+			return nil, fmt.Errorf("failed to scan pair: %w", err)
+		}
+		out = append(out, models.PairSummary{
+			Pair:      g.Key,
+			Trades:    g.Trades,
+			Volume:    g.Volume,
+			VolumeUSD: g.VolumeUSD,
+			LastPrice: g.LastPrice,
+			LastTrade: g.LastTrade,
+		})
+	}
+	return out, rows.Err()
+}
+
+// Tokens ranks the tokens traded since q.Since by stablecoin volume, then trades
+func (c *ClickHouseStore) Tokens(ctx context.Context, q storage.MarketQuery) ([]models.TokenSummary, error) {
+	rows, err := c.conn.Query(ctx, `
+		SELECT s.token, t.name, t.category, s.trades, s.pairs, s.volume_usd, s.last_trade
+		FROM (
+			SELECT token, count() AS trades, uniqExact(pair) AS pairs, sum(usd) AS volume_usd, max(timestamp) AS last_trade
+			FROM (
+				SELECT arrayJoin([token_in, token_out]) AS token, pair, timestamp, `+chquery.USDVolume+` AS usd
+				FROM swaps
+				WHERE timestamp >= ? AND `+chquery.NotRetracted+`
+			)
+			GROUP BY token
+		) AS s
+		LEFT JOIN (SELECT symbol, name, category FROM tokens FINAL) AS t ON t.symbol = s.token
+		WHERE ? = '' OR t.category = ?
+		ORDER BY s.volume_usd DESC, s.trades DESC, s.token
+		LIMIT ? OFFSET ?
+	`, q.Since, q.Category, q.Category, uint64(q.Limit), uint64(q.Offset))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tokens: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.TokenSummary{}
+	for rows.Next() {
+		var t models.TokenSummary
+		if err := rows.Scan(&t.Token, &t.Name, &t.Category, &t.Trades, &t.Pairs, &t.VolumeUSD, &t.LastTrade); err != nil {
+			return nil, fmt.Errorf("failed to scan token: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// ListPairs lists every stored pair with its first and last swap, its DEXes
+// and its last 24 hours of trading. It reads the whole swaps table, so serve
+// it through a cache.
+func (c *ClickHouseStore) ListPairs(ctx context.Context, q storage.PairListQuery) ([]models.PairListing, error) {
+	act := chquery.Activity{
+		Key:    "pair",
+		Since:  time.Now().Add(-24 * time.Hour),
+		Token:  q.Token,
+		Dex:    q.Dex,
+		Limit:  q.Limit,
+		Offset: q.Offset,
+	}.Query()
+	rows, err := c.conn.Query(ctx, act.SQL, act.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pairs: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.PairListing{}
+	for rows.Next() {
+		var r chquery.ActivityRow
+		if err := rows.Scan(r.Dest()...); err != nil {
+			return nil, fmt.Errorf("failed to scan pair: %w", err)
+		}
+		out = append(out, models.PairListing{
+			Pair:         r.Key,
+			TokenIn:      r.TokenIn,
+			TokenOut:     r.TokenOut,
+			FirstSeen:    r.FirstSeen,
+			LastSeen:     r.LastSeen,
+			Trades24h:    r.Trades,
+			Volume24h:    r.Volume,
+			VolumeUSD24h: r.VolumeUSD,
+			Dexes:        r.Dexes,
+		})
+	}
+	return out, rows.Err()
+}
+
+// DexStats compares the DEXes traded since the given time: trades, USD
+// volume and average size, and each one's share of the total
+func (c *ClickHouseStore) DexStats(ctx context.Context, since time.Time) (*models.DexStats, error) {
+	agg := chquery.Aggregate{Key: "dex", Filter: storage.SwapFilter{From: since}}
+
+	var total chquery.Group
+	tq := agg.Total()
+	if err := c.conn.QueryRow(ctx, tq.SQL, tq.Args...).Scan(total.Dest()...); err != nil {
+		return nil, fmt.Errorf("failed to query dex totals: %w", err)
+	}
+	stats := &models.DexStats{Trades: total.Trades, VolumeUSD: total.VolumeUSD, Dexes: []models.DexSummary{}}
+	if total.Trades == 0 {
+		return stats, nil
+	}
+
+	q := agg.Query()
+	rows, err := c.conn.Query(ctx, q.SQL, q.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dexes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var g chquery.Group
+		if err := rows.Scan(g.Dest()...); err != nil {
+			return nil, fmt.Errorf("failed to scan dex: %w", err)
+		}
+		d := models.DexSummary{
+			Dex:         g.Key,
+			Trades:      g.Trades,
+			VolumeUSD:   g.VolumeUSD,
+			AvgTradeUSD: g.AvgUSD(),
+			TradeShare:  float64(g.Trades) / float64(total.Trades),
+			Wallets:     g.Wallets,
+			LastTrade:   g.LastTrade,
+		}
+		if total.VolumeUSD > 0 {
+			d.VolumeShare = g.VolumeUSD / total.VolumeUSD
+		}
+		stats.Dexes = append(stats.Dexes, d)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// InsertFailedSwap records a failed swap attempt. The table is a
+// ReplacingMergeTree keyed by signature, so a transaction seen twice (e.g. a
+// block read again) is not double counted once merged.
+func (c *ClickHouseStore) InsertFailedSwap(ctx context.Context, fs *models.FailedSwap) error {
+	err := c.insert(ctx, "failed_swaps", 1, func() error {
+		return c.conn.Exec(ctx, `
+		INSERT INTO failed_swaps (
+			signature, timestamp, slot, block_time, program_id, dex,
+			wallet, error_class, error_code, instruction_index, error
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+			fs.Signature, fs.Timestamp, fs.Slot, fs.BlockTime, fs.ProgramID, fs.Dex,
+			fs.Wallet, fs.ErrorClass, fs.ErrorCode, int16(fs.InstructionIndex), fs.Error,
+		)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert failed swap: %w", err)
+	}
+	return nil
+}
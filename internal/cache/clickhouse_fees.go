@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage/chquery"
+)
+
+// landingCostColumns are the aggregates scanLandingCost reads, in order
+const landingCostColumns = `count(), avg(fee_lamports), avg(priority_fee), avg(compute_unit_price),
+			countIf(priority_fee > 0) / count()`
+
+// FeeStats averages the landing cost of the swaps since the given time,
+// overall, per DEX and for the top pairs by swap count. Swaps indexed before
+// fees were recorded are left out.
+func (c *ClickHouseStore) FeeStats(ctx context.Context, since time.Time, top int) (*models.FeeStats, error) {
+	stats := &models.FeeStats{ByDex: []models.LandingCost{}, ByPair: []models.LandingCost{}}
+	row := c.conn.QueryRow(ctx, `
+		SELECT `+landingCostColumns+`
+		FROM swaps
+		WHERE timestamp >= ? AND fee_lamports > 0 AND `+chquery.NotRetracted+`
+	`, since)
+	if err := scanLandingCost(row.Scan, &stats.LandingCost); err != nil {
+		return nil, fmt.Errorf("failed to query fee totals: %w", err)
+	}
+	if stats.Swaps == 0 {
+		stats.LandingCost = models.LandingCost{} // avg() of no rows is nan
+		return stats, nil
+	}
+
+	var err error
+	if stats.ByDex, err = c.landingCosts(ctx, "dex", since, 0); err != nil {
+		return nil, err
+	}
+	if stats.ByPair, err = c.landingCosts(ctx, "pair", since, top); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// landingCosts groups the landing cost by dex or pair, busiest first; limit 0
+// returns every group
+func (c *ClickHouseStore) landingCosts(ctx context.Context, by string, since time.Time, limit int) ([]models.LandingCost, error) {
+	query := `
+		SELECT ` + by + `, ` + landingCostColumns + `
+		FROM swaps
+		WHERE timestamp >= ? AND fee_lamports > 0 AND ` + chquery.NotRetracted + `
+		GROUP BY ` + by + `
+		ORDER BY count() DESC, ` + by
+	args := []any{since}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := c.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fees by %s: %w", by, err)
+	}
+	defer rows.Close()
+
+	out := []models.LandingCost{}
+	for rows.Next() {
+		var lc models.LandingCost
+		key := &lc.Dex
+		if by == "pair" {
+			key = &lc.Pair
+		}
+		scan := func(dest ...any) error { return rows.Scan(append([]any{key}, dest...)...) }
+		if err := scanLandingCost(scan, &lc); err != nil {
+			return nil, fmt.Errorf("failed to scan fees by %s: %w", by, err)
+		}
+		out = append(out, lc)
+	}
+	return out, rows.Err()
+}
+
+// scanLandingCost reads the landingCostColumns of a row
+func scanLandingCost(scan func(dest ...any) error, lc *models.LandingCost) error {
+	return scan(&lc.Swaps, &lc.AvgFeeLamports, &lc.AvgPriorityFee, &lc.AvgComputeUnitPrice, &lc.PrioritizedShare)
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage/chquery"
+)
+
+// MarkFinalized records that the swaps with these signatures are finalized,
+// as swap_finality rows that reads join back (chquery.Finalized) instead of
+// a mutation rewriting every part that holds one of the swaps. The finality
+// tracker batches a round of signatures into one insert.
+func (c *ClickHouseStore) MarkFinalized(ctx context.Context, signatures []string) error {
+	if err := c.insertFinality(ctx, signatures, chquery.FinalityFinalized); err != nil {
+		return fmt.Errorf("failed to mark swaps finalized: %w", err)
+	}
+	return nil
+}
+
+// RetractSwaps hides the swaps with these signatures, e.g. those whose fork
+// was dropped, from every read of swaps (chquery.NotRetracted). Like
+// MarkFinalized it inserts swap_finality rows; the swaps rows stay.
+func (c *ClickHouseStore) RetractSwaps(ctx context.Context, signatures []string) error {
+	if err := c.insertFinality(ctx, signatures, chquery.FinalityRetracted); err != nil {
+		return fmt.Errorf("failed to retract swaps: %w", err)
+	}
+	return nil
+}
+
+// insertFinality appends one swap_finality row per signature. Rows are
+// keyed by signature in a ReplacingMergeTree, so a round retried after a
+// failure only adds duplicates that merges drop.
+func (c *ClickHouseStore) insertFinality(ctx context.Context, signatures []string, status string) error {
+	if len(signatures) == 0 {
+		return nil
+	}
+	batch, err := c.conn.PrepareBatch(ctx, `INSERT INTO swap_finality (signature, status, updated_at)`)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, sig := range signatures {
+		if err := batch.Append(sig, status, now); err != nil {
+			_ = batch.Abort()
+			return err
+		}
+	}
+	return c.insert(ctx, "swap_finality", len(signatures), batch.Send)
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// Insert results recorded per statement
+const (
+	insertOK    = "ok"
+	insertError = "error"
+)
+
+var (
+	chInserts = metrics.Default.Counter("clickhouse_inserts_total",
+		"Insert statements sent to ClickHouse, by table and result (ok, error).", "table", "result")
+	chRowsInserted = metrics.Default.Counter("clickhouse_rows_inserted_total",
+		"Rows written to ClickHouse, by table; rate() gives rows per second.", "table")
+	chInsertDuration = metrics.Default.Histogram("clickhouse_insert_duration_seconds",
+		"Time of one insert statement, by table.", nil, "table")
+	chBatchRows = metrics.Default.Histogram("clickhouse_insert_batch_rows",
+		"Rows per insert statement, by table.", []float64{1, 10, 50, 100, 500, 1000, 5000}, "table")
+	chInsertsPending = metrics.Default.Gauge("clickhouse_inserts_pending",
+		"Inserts waiting on ClickHouse right now, by table; a climbing value means writes are queueing up behind the database.", "table")
+)
+
+// insert runs one insert statement of rows rows into table, recording it in
+// the clickhouse_* metrics and logging it when it takes SlowInsert or longer
+func (c *ClickHouseStore) insert(ctx context.Context, table string, rows int, fn func() error) error {
+	pending := chInsertsPending.With(table)
+	pending.Add(1)
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+	pending.Add(-1)
+
+	chInsertDuration.With(table).Observe(elapsed.Seconds())
+	chBatchRows.With(table).Observe(float64(rows))
+	if err != nil {
+		chInserts.With(table, insertError).Inc()
+	} else {
+		chInserts.With(table, insertOK).Inc()
+		chRowsInserted.With(table).Add(float64(rows))
+	}
+
+	if c.slowInsert > 0 && elapsed >= c.slowInsert {
+		entry := logging.Entry(ctx, c.logger).WithFields(logrus.Fields{
+			"table":    table,
+			"rows":     rows,
+			"duration": elapsed.Round(time.Millisecond),
+			"pending":  pending.Value(),
+		})
+		if err != nil {
+			entry = entry.WithError(err)
+		}
+		entry.Warn("slow ClickHouse insert")
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickHouseInsertMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	c := &ClickHouseStore{logger: logger, slowInsert: time.Hour}
+
+	ok, failed := chInserts.With("test", insertOK), chInserts.With("test", insertError)
+	okBefore, failedBefore := ok.Value(), failed.Value()
+	rowsBefore := chRowsInserted.With("test").Value()
+	batchesBefore := chBatchRows.With("test").Count()
+
+	require.NoError(t, c.insert(context.Background(), "test", 3, func() error {
+		assert.Equal(t, 1.0, chInsertsPending.With("test").Value(), "pending while the statement runs")
+		return nil
+	}))
+	boom := errors.New("boom")
+	assert.ErrorIs(t, c.insert(context.Background(), "test", 2, func() error { return boom }), boom)
+
+	assert.Equal(t, okBefore+1, ok.Value())
+	assert.Equal(t, failedBefore+1, failed.Value())
+	assert.Equal(t, rowsBefore+3, chRowsInserted.With("test").Value(), "failed rows are not counted")
+	assert.Equal(t, batchesBefore+2, chBatchRows.With("test").Count())
+	assert.Zero(t, chInsertsPending.With("test").Value())
+	assert.Zero(t, buf.Len(), "fast inserts are not logged")
+}
+
+func TestClickHouseSlowInsertLogged(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	c := &ClickHouseStore{logger: logger, slowInsert: time.Millisecond}
+
+	require.NoError(t, c.insert(context.Background(), "test", 1, func() error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	}))
+	assert.Contains(t, buf.String(), "slow ClickHouse insert")
+	assert.Contains(t, buf.String(), "table=test")
+
+	buf.Reset()
+	c.slowInsert = 0
+	require.NoError(t, c.insert(context.Background(), "test", 1, func() error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	}))
+	assert.Zero(t, buf.Len(), "0 turns slow insert logging off")
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// stableProfit is a sandwich's profit in USD when it was taken in a stablecoin, else 0
+const stableProfit = `if(profit_token IN ('USDC', 'USDT'), profit, 0)`
+
+// InsertSandwiches records sandwiches in one batch. The table is a
+// ReplacingMergeTree keyed by victim, so rescans do not double count once
+// merged; reads use FINAL.
+func (c *ClickHouseStore) InsertSandwiches(ctx context.Context, sandwiches []models.Sandwich) error {
+	batch, err := c.conn.PrepareBatch(ctx, `
+		INSERT INTO sandwiches (
+			victim_signature, front_signature, back_signature, slot, timestamp,
+			pool_address, dex, pair, attacker, victim,
+			token, profit_token, profit, victim_amount
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare sandwich batch: %w", err)
+	}
+	for _, s := range sandwiches {
+		if err := batch.Append(
+			s.VictimSignature, s.FrontSignature, s.BackSignature, s.Slot, s.Timestamp,
+			s.PoolAddress, s.Dex, s.Pair, s.Attacker, s.Victim,
+			s.Token, s.ProfitToken, s.Profit, s.VictimAmount,
+		); err != nil {
+			_ = batch.Abort()
+			return fmt.Errorf("failed to append sandwich: %w", err)
+		}
+	}
+	if err := c.insert(ctx, "sandwiches", len(sandwiches), batch.Send); err != nil {
+		return fmt.Errorf("failed to insert sandwiches: %w", err)
+	}
+	return nil
+}
+
+// MEVStats aggregates the sandwiches since the given time
+func (c *ClickHouseStore) MEVStats(ctx context.Context, since time.Time, top int) (*models.MEVStats, error) {
+	stats := &models.MEVStats{
+		ProfitByToken: map[string]float64{},
+		TopAttackers:  []models.AttackerStats{},
+		TopPools:      []models.PoolMEV{},
+	}
+	err := c.conn.QueryRow(ctx, `
+		SELECT count(), uniqExact(attacker), uniqExact(victim), sum(`+stableProfit+`),
+			sum(if(profit_token IN ('USDC', 'USDT'), victim_amount, 0))
+		FROM sandwiches FINAL
+		WHERE timestamp >= ?
+	`, since).Scan(&stats.Sandwiches, &stats.Attackers, &stats.Victims, &stats.ProfitUSD, &stats.VictimVolumeUSD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mev totals: %w", err)
+	}
+	if stats.Sandwiches == 0 {
+		return stats, nil
+	}
+
+	rows, err := c.conn.Query(ctx, `
+		SELECT profit_token, sum(profit)
+		FROM sandwiches FINAL
+		WHERE timestamp >= ?
+		GROUP BY profit_token
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mev profit: %w", err)
+	}
+	for rows.Next() {
+		var (
+			token  string
+			profit float64
+		)
+		if err := rows.Scan(&token, &profit); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan mev profit: %w", err)
+		}
+		stats.ProfitByToken[token] = profit
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = c.conn.Query(ctx, `
+		SELECT attacker, count() AS n, sum(`+stableProfit+`)
+		FROM sandwiches FINAL
+		WHERE timestamp >= ?
+		GROUP BY attacker
+		ORDER BY n DESC, attacker
+		LIMIT ?
+	`, since, uint64(top))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mev attackers: %w", err)
+	}
+	for rows.Next() {
+		var a models.AttackerStats
+		if err := rows.Scan(&a.Attacker, &a.Sandwiches, &a.ProfitUSD); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan mev attacker: %w", err)
+		}
+		stats.TopAttackers = append(stats.TopAttackers, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = c.conn.Query(ctx, `
+		SELECT pool_address, any(dex), any(pair), count() AS n
+		FROM sandwiches FINAL
+		WHERE timestamp >= ?
+		GROUP BY pool_address
+		ORDER BY n DESC, pool_address
+		LIMIT ?
+	`, since, uint64(top))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mev pools: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.PoolMEV
+		if err := rows.Scan(&p.PoolAddress, &p.Dex, &p.Pair, &p.Sandwiches); err != nil {
+			return nil, fmt.Errorf("failed to scan mev pool: %w", err)
+		}
+		stats.TopPools = append(stats.TopPools, p)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// UpsertTokens writes token metadata to the tokens table; writing a symbol
+// again replaces it. Mints without a symbol are skipped, as no swap carries
+// them.
+func (c *ClickHouseStore) UpsertTokens(ctx context.Context, metas []*models.TokenMetadata) error {
+	batch, err := c.conn.PrepareBatch(ctx, `
+		INSERT INTO tokens (symbol, mint, name, category, uri, updated_at)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare token batch: %w", err)
+	}
+	now := time.Now().UTC()
+	rows := 0
+	for _, m := range metas {
+		if m.Symbol == "" {
+			continue
+		}
+		if err := batch.Append(m.Symbol, m.Mint, m.Name, m.Category, m.URI, now); err != nil {
+			_ = batch.Abort()
+			return fmt.Errorf("failed to append token: %w", err)
+		}
+		rows++
+	}
+	if err := c.insert(ctx, "tokens", rows, batch.Send); err != nil {
+		return fmt.Errorf("failed to insert tokens: %w", err)
+	}
+	return nil
+}
+
+// RelabelToken renames token label from to on the swaps stored before the
+// given time, pairs included. pair is part of the sort key and cannot be
+// updated in place, so the swaps are copied with the new label and the
+// originals deleted. swaps_hourly counts the copies under the new pair
+// without dropping them from the old one.
+//
+// The delete is a mutation, so the token registry runs a relabel once per
+// mint, in the process that claims it. Swaps already copied under the new
+// label are not copied again, so a relabel whose delete failed can be run
+// again, and the delete waits for every replica before returning.
+func (c *ClickHouseStore) RelabelToken(ctx context.Context, from, to string, before time.Time) error {
+	err := c.conn.Exec(ctx, `
+		INSERT INTO swaps
+		SELECT * REPLACE (
+			if(token_in = ?, ?, token_in) AS token_in,
+			if(token_out = ?, ?, token_out) AS token_out,
+			concat(if(token_in = ?, ?, token_in), '/', if(token_out = ?, ?, token_out)) AS pair
+		)
+		FROM swaps
+		WHERE (token_in = ? OR token_out = ?) AND timestamp < ?
+			AND signature NOT IN (
+				SELECT signature FROM swaps
+				WHERE (token_in = ? OR token_out = ?) AND timestamp < ?
+			)
+	`, from, to, from, to, from, to, from, to, from, from, before, to, to, before)
+	if err != nil {
+		return fmt.Errorf("failed to copy relabeled swaps: %w", err)
+	}
+
+	err = c.conn.Exec(ctx, `
+		ALTER TABLE swaps DELETE
+		WHERE (token_in = ? OR token_out = ?) AND timestamp < ?
+		SETTINGS mutations_sync = 2
+	`, from, from, before)
+	if err != nil {
+		return fmt.Errorf("failed to delete relabeled swaps: %w", err)
+	}
+	return nil
+}
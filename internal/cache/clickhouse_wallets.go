@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage/chquery"
+)
+
+// TopWallets ranks wallets by stablecoin volume or trade count since q.Since
+func (c *ClickHouseStore) TopWallets(ctx context.Context, q storage.WalletQuery) ([]models.WalletSummary, error) {
+	order := "volume_usd DESC, trades DESC"
+	if q.OrderBy == storage.WalletOrderTrades {
+		order = "trades DESC, volume_usd DESC"
+	}
+	query := `
+		SELECT wallet, count() AS trades, sum(` + chquery.USDVolume + `) AS volume_usd,
+			uniqExact(pair), min(timestamp), max(timestamp)
+		FROM swaps
+		WHERE wallet != '' AND timestamp >= ? AND ` + chquery.NotRetracted + `
+		GROUP BY wallet
+		ORDER BY ` + order + `, wallet
+		LIMIT ?
+	`
+
+	rows, err := c.conn.Query(ctx, query, q.Since, uint64(q.Limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top wallets: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.WalletSummary{}
+	for rows.Next() {
+		var w models.WalletSummary
+		if err := rows.Scan(&w.Wallet, &w.Trades, &w.VolumeUSD, &w.Pairs, &w.FirstTrade, &w.LastTrade); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet: %w", err)
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// WalletStats profiles one wallet since the given time: totals, average
+// trade size, its topPairs most traded pairs and an hourly activity heatmap
+func (c *ClickHouseStore) WalletStats(ctx context.Context, wallet string, since time.Time, topPairs int) (*models.WalletStats, error) {
+	stats := &models.WalletStats{FavoritePairs: []models.PairActivity{}}
+	stats.Wallet = wallet
+
+	var stableTrades uint64
+	err := c.conn.QueryRow(ctx, `
+		SELECT count(), sum(`+chquery.USDVolume+`), countIf(`+chquery.USDVolume+` > 0),
+			uniqExact(pair), min(timestamp), max(timestamp)
+		FROM swaps
+		WHERE wallet = ? AND timestamp >= ? AND `+chquery.NotRetracted+`
+	`, wallet, since).Scan(&stats.Trades, &stats.VolumeUSD, &stableTrades, &stats.Pairs, &stats.FirstTrade, &stats.LastTrade)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallet totals: %w", err)
+	}
+	if stats.Trades == 0 {
+		return nil, nil
+	}
+	if stableTrades > 0 {
+		stats.AvgTradeUSD = stats.VolumeUSD / float64(stableTrades)
+	}
+
+	rows, err := c.conn.Query(ctx, `
+		SELECT pair, count() AS trades, sum(`+chquery.USDVolume+`)
+		FROM swaps
+		WHERE wallet = ? AND timestamp >= ? AND `+chquery.NotRetracted+`
+		GROUP BY pair
+		ORDER BY trades DESC, pair
+		LIMIT ?
+	`, wallet, since, uint64(topPairs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallet pairs: %w", err)
+	}
+	for rows.Next() {
+		var p models.PairActivity
+		if err := rows.Scan(&p.Pair, &p.Trades, &p.VolumeUSD); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan wallet pair: %w", err)
+		}
+		stats.FavoritePairs = append(stats.FavoritePairs, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// toDayOfWeek counts Monday as 1 and Sunday as 7; % 7 makes Sunday 0
+	rows, err = c.conn.Query(ctx, `
+		SELECT toDayOfWeek(toTimeZone(timestamp, 'UTC')) % 7, toHour(toTimeZone(timestamp, 'UTC')), count()
+		FROM swaps
+		WHERE wallet = ? AND timestamp >= ? AND `+chquery.NotRetracted+`
+		GROUP BY 1, 2
+	`, wallet, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallet heatmap: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			day, hour uint8
+			n         uint64
+		)
+		if err := rows.Scan(&day, &hour, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet heatmap: %w", err)
+		}
+		if day < 7 && hour < 24 {
+			stats.Heatmap[day][hour] = n
+		}
+	}
+	return stats, rows.Err()
+}
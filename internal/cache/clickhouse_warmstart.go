@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage/chquery"
+)
+
+// RecentSwapsPerPair returns up to perPair of the newest swaps of each pair
+// traded since the given time, newest first
+func (c *ClickHouseStore) RecentSwapsPerPair(ctx context.Context, since time.Time, perPair int) ([]*models.SwapEvent, error) {
+	rows, err := c.conn.Query(ctx, `
+		SELECT `+swapColumns+`
+		FROM swaps
+		WHERE timestamp >= ? AND `+chquery.NotRetracted+`
+		ORDER BY timestamp DESC, signature DESC
+		LIMIT ? BY pair
+	`, since, uint64(perPair))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent swaps per pair: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*models.SwapEvent
+	for rows.Next() {
+		swap, err := scanSwap(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, swap)
+	}
+	return out, rows.Err()
+}
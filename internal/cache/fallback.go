@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FallbackCache wraps a primary SwapCache (Redis) and mirrors what it reads
+// and writes into a MemoryCache. When the primary errors, reads are served
+// from memory instead, so a short Redis outage returns slightly old data
+// rather than failing. Pub/sub and the swap stream are not mirrored: they go
+// to the primary only.
+type FallbackCache struct {
+	primary  storage.SwapCache
+	memory   *MemoryCache
+	logger   *logrus.Logger
+	degraded atomic.Bool
+}
+
+// NewFallbackCache wraps primary with an in-memory fallback
+func NewFallbackCache(primary storage.SwapCache, memory *MemoryCache, logger *logrus.Logger) *FallbackCache {
+	if memory == nil {
+		memory = NewMemoryCache(0, 0)
+	}
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &FallbackCache{primary: primary, memory: memory, logger: logger}
+}
+
+// Degraded reports whether the last primary call failed
+func (f *FallbackCache) Degraded() bool {
+	return f.degraded.Load()
+}
+
+// observe records the outcome of a primary call and logs state transitions
+func (f *FallbackCache) observe(op string, err error) {
+	if err != nil {
+		if !f.degraded.Swap(true) {
+			f.logger.WithError(err).WithField("op", op).Warn("cache primary failed, serving from memory")
+		}
+		return
+	}
+	if f.degraded.Swap(false) {
+		f.logger.WithField("op", op).Info("cache primary recovered")
+	}
+}
+
+// ProcessSwap writes to both caches; it only fails if the primary does
+func (f *FallbackCache) ProcessSwap(ctx context.Context, swap *models.SwapEvent) error {
+	_ = f.memory.AddRecentSwap(ctx, swap)
+	_ = f.memory.UpdatePrice(ctx, swap.TokenOut, swap.Price)
+	err := f.primary.ProcessSwap(ctx, swap)
+	f.observe("process_swap", err)
+	return err
+}
+
+// AddRecentSwap writes to both caches; it only fails if the primary does
+func (f *FallbackCache) AddRecentSwap(ctx context.Context, swap *models.SwapEvent) error {
+	_ = f.memory.AddRecentSwap(ctx, swap)
+	err := f.primary.AddRecentSwap(ctx, swap)
+	f.observe("add_recent_swap", err)
+	return err
+}
+
+// UpdatePrice writes to both caches; it only fails if the primary does
+func (f *FallbackCache) UpdatePrice(ctx context.Context, token string, price float64) error {
+	_ = f.memory.UpdatePrice(ctx, token, price)
+	err := f.primary.UpdatePrice(ctx, token, price)
+	f.observe("update_price", err)
+	return err
+}
+
+// GetRecentSwaps reads the primary, falling back to the last swaps it returned
+func (f *FallbackCache) GetRecentSwaps(ctx context.Context, limit int64) ([]*models.SwapEvent, error) {
+	swaps, err := f.primary.GetRecentSwaps(ctx, limit)
+	f.observe("get_recent_swaps", err)
+	if err != nil {
+		return f.memory.GetRecentSwaps(ctx, limit)
+	}
+	// only a full read is a faithful copy of the primary's list
+	if limit >= int64(f.memory.capacity()) || int64(len(swaps)) < limit {
+		f.memory.SetRecentSwaps(swaps)
+	}
+	return swaps, nil
+}
+
+// GetRecentSwapsByPair reads the primary, falling back to the last swaps of the pair it returned
+func (f *FallbackCache) GetRecentSwapsByPair(ctx context.Context, pair string, limit int64) ([]*models.SwapEvent, error) {
+	swaps, err := f.primary.GetRecentSwapsByPair(ctx, pair, limit)
+	f.observe("get_recent_swaps_by_pair", err)
+	if err != nil {
+		return f.memory.GetRecentSwapsByPair(ctx, pair, limit)
+	}
+	if limit >= int64(f.memory.capacity()) || int64(len(swaps)) < limit {
+		f.memory.SetRecentSwapsByPair(pair, swaps)
+	}
+	return swaps, nil
+}
+
+// GetPrice reads the primary, falling back to the last price it returned
+func (f *FallbackCache) GetPrice(ctx context.Context, token string) (*models.TokenPrice, error) {
+	p, err := f.primary.GetPrice(ctx, token)
+	f.observe("get_price", err)
+	if err != nil {
+		return f.memory.GetPrice(ctx, token)
+	}
+	if p != nil {
+		f.memory.SetPrice(*p)
+	}
+	return p, nil
+}
+
+// GetPriceHistory reads the primary, falling back to the points it returned before
+func (f *FallbackCache) GetPriceHistory(ctx context.Context, token string, since time.Time) ([]models.PricePoint, error) {
+	points, err := f.primary.GetPriceHistory(ctx, token, since)
+	f.observe("get_price_history", err)
+	if err != nil {
+		return f.memory.GetPriceHistory(ctx, token, since)
+	}
+	f.memory.MergePriceHistory(token, points)
+	return points, nil
+}
+
+// Ping checks the primary
+func (f *FallbackCache) Ping(ctx context.Context) error {
+	err := f.primary.Ping(ctx)
+	f.observe("ping", err)
+	return err
+}
+
+// Close closes both caches
+func (f *FallbackCache) Close() error {
+	_ = f.memory.Close()
+	return f.primary.Close()
+}
+
+// PublishSwap publishes on the primary
+func (f *FallbackCache) PublishSwap(ctx context.Context, swap *models.SwapEvent) error {
+	return f.primary.PublishSwap(ctx, swap)
+}
+
+// SubscribeSwaps subscribes on the primary
+func (f *FallbackCache) SubscribeSwaps(ctx context.Context) (<-chan *models.SwapEvent, error) {
+	return f.primary.SubscribeSwaps(ctx)
+}
+
+// AppendSwap appends to the primary's stream
+func (f *FallbackCache) AppendSwap(ctx context.Context, swap *models.SwapEvent) error {
+	return f.primary.AppendSwap(ctx, swap)
+}
+
+// ConsumeSwaps consumes the primary's stream
+func (f *FallbackCache) ConsumeSwaps(ctx context.Context, cfg storage.ConsumerConfig, handler storage.StreamHandler) error {
+	return f.primary.ConsumeSwaps(ctx, cfg, handler)
+}
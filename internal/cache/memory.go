@@ -0,0 +1,288 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// MemoryCache implements SwapCache in process memory with the semantics of
+// RedisCache: recent swaps overall and per pair, prices with a TTL and a
+// rolling history, pub/sub through channels and a swap stream with consumer
+// groups (see memStream). It is not shared between processes and nothing
+// survives a restart.
+type MemoryCache struct {
+	mu        sync.RWMutex
+	maxRecent int
+	recent    *swapRing
+	pairs     map[string]*swapRing // by upper-cased pair, like the Redis keys
+	prices    map[string]memPrice
+	priceTTL  time.Duration
+	history   map[string][]models.PricePoint // oldest first, trimmed like Redis
+	subs      map[chan *models.SwapEvent]struct{}
+	stream    *memStream
+}
+
+type memPrice struct {
+	price     models.TokenPrice
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates an in-memory cache keeping up to maxRecent swaps;
+// maxRecent <= 0 uses constants.MaxRecentSwaps and priceTTL <= 0 uses
+// constants.PriceTTL
+func NewMemoryCache(maxRecent int, priceTTL time.Duration) *MemoryCache {
+	if maxRecent <= 0 {
+		maxRecent = constants.MaxRecentSwaps
+	}
+	if priceTTL <= 0 {
+		priceTTL = constants.PriceTTL
+	}
+	return &MemoryCache{
+		maxRecent: maxRecent,
+		recent:    newSwapRing(maxRecent),
+		pairs:     make(map[string]*swapRing),
+		prices:    make(map[string]memPrice),
+		history:   make(map[string][]models.PricePoint),
+		priceTTL:  priceTTL,
+		subs:      make(map[chan *models.SwapEvent]struct{}),
+		stream:    newMemStream(constants.StreamMaxLen),
+	}
+}
+
+// ProcessSwap records a swap in the recent lists, updates the output token's
+// price, publishes it and appends it to the stream
+func (m *MemoryCache) ProcessSwap(ctx context.Context, swap *models.SwapEvent) error {
+	_ = m.AddRecentSwap(ctx, swap)
+	_ = m.UpdatePrice(ctx, swap.TokenOut, swap.Price)
+	_ = m.PublishSwap(ctx, swap)
+	return m.AppendSwap(ctx, swap)
+}
+
+// AddRecentSwap adds a swap to the front of the recent swaps and of its pair's
+func (m *MemoryCache) AddRecentSwap(_ context.Context, swap *models.SwapEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recent.push(swap)
+	m.pair(swap.Pair).push(swap)
+	return nil
+}
+
+// pair returns the recent swaps of pair, creating the list; m.mu must be held
+func (m *MemoryCache) pair(pair string) *swapRing {
+	key := strings.ToUpper(pair)
+	r, ok := m.pairs[key]
+	if !ok {
+		r = newSwapRing(m.maxRecent)
+		m.pairs[key] = r
+	}
+	return r
+}
+
+// capacity is the number of recent swaps kept
+func (m *MemoryCache) capacity() int {
+	return m.maxRecent
+}
+
+// SetRecentSwaps replaces the recent swaps with swaps (newest first), e.g. to
+// mirror what Redis last returned
+func (m *MemoryCache) SetRecentSwaps(swaps []*models.SwapEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recent.reset(swaps)
+}
+
+// SetRecentSwapsByPair replaces the recent swaps of one pair (newest first)
+func (m *MemoryCache) SetRecentSwapsByPair(pair string, swaps []*models.SwapEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pair(pair).reset(swaps)
+}
+
+// UpdatePrice records the current price for a token
+func (m *MemoryCache) UpdatePrice(_ context.Context, token string, price float64) error {
+	m.SetPrice(models.TokenPrice{Token: token, Price: price, UpdatedAt: time.Now().UTC()})
+	return nil
+}
+
+// SetPrice stores a price as-is, keeping its original timestamp, and adds it
+// to the token's history
+func (m *MemoryCache) SetPrice(p models.TokenPrice) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prices[p.Token] = memPrice{price: p, expiresAt: time.Now().Add(m.priceTTL)}
+	if !p.UpdatedAt.IsZero() {
+		m.mergeHistory(p.Token, []models.PricePoint{{Price: p.Price, At: p.UpdatedAt}})
+	}
+}
+
+// MergePriceHistory adds points (e.g. read from Redis) to a token's history
+func (m *MemoryCache) MergePriceHistory(token string, points []models.PricePoint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mergeHistory(token, points)
+}
+
+func (m *MemoryCache) mergeHistory(token string, points []models.PricePoint) {
+	merged := append(slices.Clone(m.history[token]), points...)
+	slices.SortStableFunc(merged, func(a, b models.PricePoint) int { return a.At.Compare(b.At) })
+	merged = slices.CompactFunc(merged, func(a, b models.PricePoint) bool { return a.At.Equal(b.At) })
+
+	cutoff := time.Now().Add(-constants.PriceHistoryWindow)
+	start, _ := slices.BinarySearchFunc(merged, cutoff, func(p models.PricePoint, t time.Time) int { return p.At.Compare(t) })
+	start = max(start, len(merged)-constants.PriceHistoryMaxPoints)
+	m.history[token] = merged[start:]
+}
+
+// GetRecentSwaps returns up to limit swaps, newest first
+func (m *MemoryCache) GetRecentSwaps(_ context.Context, limit int64) ([]*models.SwapEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.recent.newest(limit), nil
+}
+
+// GetRecentSwapsByPair returns up to limit swaps of one pair, newest first
+func (m *MemoryCache) GetRecentSwapsByPair(_ context.Context, pair string, limit int64) ([]*models.SwapEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	r, ok := m.pairs[strings.ToUpper(pair)]
+	if !ok {
+		return []*models.SwapEvent{}, nil
+	}
+	return r.newest(limit), nil
+}
+
+// GetPrice returns the last price for a token, or nil if none or expired
+func (m *MemoryCache) GetPrice(_ context.Context, token string) (*models.TokenPrice, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	p, ok := m.prices[token]
+	if !ok || time.Now().After(p.expiresAt) {
+		return nil, nil
+	}
+	price := p.price
+	return &price, nil
+}
+
+// GetPriceHistory returns the token's price points since the given time, oldest first
+func (m *MemoryCache) GetPriceHistory(_ context.Context, token string, since time.Time) ([]models.PricePoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	points := m.history[token]
+	i, _ := slices.BinarySearchFunc(points, since, func(p models.PricePoint, t time.Time) int { return p.At.Compare(t) })
+	return slices.Clone(points[i:]), nil
+}
+
+// Ping always succeeds
+func (m *MemoryCache) Ping(context.Context) error {
+	return nil
+}
+
+// Close closes every subscriber channel and stops stream consumers
+func (m *MemoryCache) Close() error {
+	m.stream.close()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for ch := range m.subs {
+		delete(m.subs, ch)
+		close(ch)
+	}
+	return nil
+}
+
+// PublishSwap delivers a swap to in-process subscribers, dropping it for any
+// whose buffer is full
+func (m *MemoryCache) PublishSwap(_ context.Context, swap *models.SwapEvent) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for ch := range m.subs {
+		select {
+		case ch <- swap:
+		default:
+		}
+	}
+	return nil
+}
+
+// PublishRetraction drops a retracted swap from the recent lists; there are no
+// in-process subscribers to retractions
+func (m *MemoryCache) PublishRetraction(_ context.Context, rt *models.SwapRetraction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recent.remove(rt.Swap.Signature)
+	m.pair(rt.Swap.Pair).remove(rt.Swap.Signature)
+	return nil
+}
+
+// SubscribeSwaps returns a channel of swaps published after the call; it is
+// closed when ctx is cancelled
+func (m *MemoryCache) SubscribeSwaps(ctx context.Context) (<-chan *models.SwapEvent, error) {
+	ch := make(chan *models.SwapEvent, 100)
+	m.mu.Lock()
+	m.subs[ch] = struct{}{}
+	m.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if _, ok := m.subs[ch]; ok {
+			delete(m.subs, ch)
+			close(ch)
+		}
+	}()
+	return ch, nil
+}
+
+// swapRing keeps the newest swaps up to its capacity
+type swapRing struct {
+	buf  []*models.SwapEvent // newest at buf[(head-1) mod cap]
+	head int
+	size int
+}
+
+func newSwapRing(capacity int) *swapRing {
+	return &swapRing{buf: make([]*models.SwapEvent, capacity)}
+}
+
+func (r *swapRing) push(swap *models.SwapEvent) {
+	r.buf[r.head] = swap
+	r.head = (r.head + 1) % len(r.buf)
+	if r.size < len(r.buf) {
+		r.size++
+	}
+}
+
+// newest returns up to limit swaps, newest first
+func (r *swapRing) newest(limit int64) []*models.SwapEvent {
+	n := min(int(limit), r.size)
+	out := make([]*models.SwapEvent, 0, max(n, 0))
+	for i := 1; i <= n; i++ {
+		out = append(out, r.buf[(r.head-i+len(r.buf))%len(r.buf)])
+	}
+	return out
+}
+
+// remove drops the swaps with signature, keeping the others' order
+func (r *swapRing) remove(signature string) {
+	swaps := r.newest(int64(r.size))
+	if kept := slices.DeleteFunc(swaps, func(s *models.SwapEvent) bool { return s.Signature == signature }); len(kept) < r.size {
+		r.reset(kept)
+	}
+}
+
+// reset replaces the contents with swaps, newest first
+func (r *swapRing) reset(swaps []*models.SwapEvent) {
+	clear(r.buf)
+	r.head, r.size = 0, 0
+	for i := min(len(swaps), len(r.buf)) - 1; i >= 0; i-- {
+		r.push(swaps[i])
+	}
+}
@@ -0,0 +1,208 @@
+package cache
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+)
+
+// MemoryStore implements SwapStore in process memory, for tests and
+// standalone runs without ClickHouse. Like the swaps table it keeps every
+// insert, redeliveries included, ordered by timestamp and signature; past
+// its capacity the oldest swaps are dropped. Swaps are copied in and out, so
+// callers may modify what they pass or get back.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	max   int
+	swaps []models.SwapEvent        // oldest first
+	bySig map[string]*memStoredSwap // by signature
+}
+
+// memStoredSwap is the first stored copy of a signature and how many are held
+type memStoredSwap struct {
+	swap   models.SwapEvent
+	copies int
+}
+
+// NewMemoryStore creates a store keeping up to maxSwaps swaps; maxSwaps <= 0
+// uses constants.MemoryStoreMaxSwaps
+func NewMemoryStore(maxSwaps int) *MemoryStore {
+	if maxSwaps <= 0 {
+		maxSwaps = constants.MemoryStoreMaxSwaps
+	}
+	return &MemoryStore{max: maxSwaps, bySig: make(map[string]*memStoredSwap)}
+}
+
+// InsertSwap stores a copy of swap
+func (m *MemoryStore) InsertSwap(_ context.Context, swap *models.SwapEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i, _ := slices.BinarySearchFunc(m.swaps, swap, compareSwaps)
+	m.swaps = slices.Insert(m.swaps, i, *swap)
+	stored, ok := m.bySig[swap.Signature]
+	if !ok {
+		stored = &memStoredSwap{swap: *swap}
+		m.bySig[swap.Signature] = stored
+	}
+	stored.copies++
+
+	if n := len(m.swaps) - m.max; n > 0 {
+		for _, old := range m.swaps[:n] {
+			stored := m.bySig[old.Signature]
+			if stored.copies--; stored.copies == 0 {
+				delete(m.bySig, old.Signature)
+			}
+		}
+		m.swaps = slices.Delete(m.swaps, 0, n)
+	}
+	return nil
+}
+
+// MarkFinalized sets finalized on every stored copy of these signatures
+func (m *MemoryStore) MarkFinalized(_ context.Context, signatures []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set := m.storedSet(signatures)
+	for i := range m.swaps {
+		if set[m.swaps[i].Signature] {
+			m.swaps[i].Finalized = true
+		}
+	}
+	for sig := range set {
+		m.bySig[sig].swap.Finalized = true
+	}
+	return nil
+}
+
+// RetractSwaps removes every stored copy of these signatures
+func (m *MemoryStore) RetractSwaps(_ context.Context, signatures []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set := m.storedSet(signatures)
+	m.swaps = slices.DeleteFunc(m.swaps, func(s models.SwapEvent) bool { return set[s.Signature] })
+	for sig := range set {
+		delete(m.bySig, sig)
+	}
+	return nil
+}
+
+// storedSet returns the signatures that are stored
+func (m *MemoryStore) storedSet(signatures []string) map[string]bool {
+	set := make(map[string]bool, len(signatures))
+	for _, sig := range signatures {
+		if _, ok := m.bySig[sig]; ok {
+			set[sig] = true
+		}
+	}
+	return set
+}
+
+// compareSwaps orders swaps like the ClickHouse exports: timestamp, then signature
+func compareSwaps(a models.SwapEvent, b *models.SwapEvent) int {
+	if c := a.Timestamp.Compare(b.Timestamp); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Signature, b.Signature)
+}
+
+// GetSwap returns the stored swap of a transaction; nil when none is stored
+func (m *MemoryStore) GetSwap(_ context.Context, signature string) (*models.SwapEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.bySig[signature]
+	if !ok {
+		return nil, nil
+	}
+	swap := stored.swap
+	return &swap, nil
+}
+
+// ScanSwaps calls fn for every swap matching q, oldest first, and stops at the
+// first error fn returns
+func (m *MemoryStore) ScanSwaps(ctx context.Context, q storage.SwapQuery, fn func(*models.SwapEvent) error) error {
+	m.mu.RLock()
+	var matched []models.SwapEvent
+	for _, s := range m.swaps {
+		if s.Timestamp.Before(q.From) || !s.Timestamp.Before(q.To) || (q.Pair != "" && s.Pair != q.Pair) {
+			continue
+		}
+		matched = append(matched, s)
+		if q.Limit > 0 && len(matched) == q.Limit {
+			break
+		}
+	}
+	m.mu.RUnlock()
+
+	for i := range matched {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(&matched[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListSwaps returns the swaps matching f, newest first
+func (m *MemoryStore) ListSwaps(_ context.Context, f storage.SwapFilter) ([]*models.SwapEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := []*models.SwapEvent{}
+	skip := f.Offset
+	for i := len(m.swaps) - 1; i >= 0; i-- {
+		s := m.swaps[i]
+		if !matchesFilter(&s, f) {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		out = append(out, &s)
+		if f.Limit > 0 && len(out) == f.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// matchesFilter applies a SwapFilter the way chquery.SwapWhere does
+func matchesFilter(s *models.SwapEvent, f storage.SwapFilter) bool {
+	switch {
+	case !f.From.IsZero() && s.Timestamp.Before(f.From),
+		!f.To.IsZero() && !s.Timestamp.Before(f.To),
+		f.Pair != "" && s.Pair != f.Pair,
+		f.Token != "" && s.TokenIn != f.Token && s.TokenOut != f.Token,
+		f.Dex != "" && s.Dex != f.Dex,
+		f.Wallet != "" && s.Wallet != f.Wallet:
+		return false
+	}
+	return true
+}
+
+// Len returns the number of stored swaps
+func (m *MemoryStore) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.swaps)
+}
+
+// Ping always succeeds
+func (m *MemoryStore) Ping(context.Context) error {
+	return nil
+}
+
+// Close does nothing; the swaps stay readable
+func (m *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ storage.SwapStore     = (*MemoryStore)(nil)
+	_ storage.SwapHistory   = (*MemoryStore)(nil)
+	_ storage.FinalityStore = (*MemoryStore)(nil)
+	_ storage.SwapCache     = (*MemoryCache)(nil)
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore(3)
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(n int, pair string) *models.SwapEvent {
+		s := swap(n)
+		s.Pair, s.Timestamp = pair, start.Add(time.Duration(n)*time.Minute)
+		return s
+	}
+
+	// inserted out of order, and one redelivered
+	for _, s := range []*models.SwapEvent{at(2, "SOL/USDC"), at(1, "SOL/USDC"), at(3, "BONK/SOL"), at(2, "SOL/USDC")} {
+		require.NoError(t, m.InsertSwap(ctx, s))
+	}
+	assert.Equal(t, 3, m.Len(), "the oldest swap is dropped past capacity")
+
+	got, err := m.GetSwap(ctx, swap(2).Signature)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	got.Pair = "changed"
+	got, err = m.GetSwap(ctx, swap(2).Signature)
+	require.NoError(t, err)
+	assert.Equal(t, "SOL/USDC", got.Pair, "callers get a copy")
+
+	got, err = m.GetSwap(ctx, swap(1).Signature)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	var scanned []string
+	require.NoError(t, m.ScanSwaps(ctx, storage.SwapQuery{From: start, To: start.Add(time.Hour), Pair: "SOL/USDC"}, func(s *models.SwapEvent) error {
+		scanned = append(scanned, s.Signature)
+		return nil
+	}))
+	assert.Equal(t, []string{swap(2).Signature, swap(2).Signature}, scanned)
+
+	stop := errors.New("stop")
+	n := 0
+	assert.ErrorIs(t, m.ScanSwaps(ctx, storage.SwapQuery{From: start, To: start.Add(time.Hour)}, func(*models.SwapEvent) error {
+		n++
+		return stop
+	}), stop)
+	assert.Equal(t, 1, n)
+
+	listed, err := m.ListSwaps(ctx, storage.SwapFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, swap(3).Signature, listed[0].Signature, "newest first")
+
+	listed, err = m.ListSwaps(ctx, storage.SwapFilter{Pair: "BONK/SOL"})
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+}
+
+func TestMemoryStoreFinality(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore(0)
+	for _, s := range []*models.SwapEvent{swap(1), swap(2), swap(2), swap(3)} {
+		require.NoError(t, m.InsertSwap(ctx, s))
+	}
+
+	require.NoError(t, m.MarkFinalized(ctx, []string{swap(2).Signature, "unknown"}))
+	got, err := m.GetSwap(ctx, swap(2).Signature)
+	require.NoError(t, err)
+	assert.True(t, got.Finalized)
+	listed, err := m.ListSwaps(ctx, storage.SwapFilter{})
+	require.NoError(t, err)
+	for _, s := range listed {
+		assert.Equal(t, s.Signature == swap(2).Signature, s.Finalized, s.Signature)
+	}
+
+	require.NoError(t, m.RetractSwaps(ctx, []string{swap(2).Signature, swap(3).Signature}))
+	assert.Equal(t, 1, m.Len(), "every copy is deleted")
+	got, err = m.GetSwap(ctx, swap(3).Signature)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,213 @@
+package cache
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+)
+
+// memStream is the in-memory counterpart of the Redis swap stream: a capped
+// log of swaps read through consumer groups. Each group sees every entry once;
+// an entry handed to a consumer stays pending until its handler succeeds and
+// is handed out again, to any member, once it has been idle for MinIdle.
+type memStream struct {
+	mu      sync.Mutex
+	maxLen  int
+	entries []memEntry // oldest first
+	lastID  uint64
+	groups  map[string]*memGroup
+	wake    chan struct{} // closed and replaced on every append
+	closed  bool
+}
+
+type memEntry struct {
+	id   uint64
+	swap *models.SwapEvent
+}
+
+type memGroup struct {
+	lastDelivered uint64
+	pending       map[uint64]*memPending
+}
+
+type memPending struct {
+	entry       memEntry
+	consumer    string
+	deliveredAt time.Time
+}
+
+func newMemStream(maxLen int) *memStream {
+	return &memStream{maxLen: maxLen, groups: make(map[string]*memGroup), wake: make(chan struct{})}
+}
+
+// AppendSwap appends a swap to the in-memory swap stream, dropping the oldest
+// entry past constants.StreamMaxLen
+func (m *MemoryCache) AppendSwap(_ context.Context, swap *models.SwapEvent) error {
+	m.stream.append(swap)
+	return nil
+}
+
+func (s *memStream) append(swap *models.SwapEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	s.entries = append(s.entries, memEntry{id: s.lastID, swap: swap})
+	if len(s.entries) > s.maxLen {
+		s.entries = s.entries[len(s.entries)-s.maxLen:]
+	}
+	close(s.wake)
+	s.wake = make(chan struct{})
+}
+
+// close wakes every consumer and makes them return
+func (s *memStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.wake)
+	}
+}
+
+// ConsumeSwaps reads the in-memory stream as cfg.Consumer in cfg.Group until
+// ctx is cancelled or the cache is closed, like RedisCache.ConsumeSwaps: an
+// entry is acknowledged once handler returns nil, and failed entries are
+// handed out again after cfg.MinIdle. StartID "0" starts a new group at the
+// oldest retained entry; anything else at new entries.
+func (m *MemoryCache) ConsumeSwaps(ctx context.Context, cfg storage.ConsumerConfig, handler storage.StreamHandler) error {
+	if cfg.Consumer == "" {
+		return fmt.Errorf("stream consumer name is required")
+	}
+	if cfg.Group == "" {
+		cfg.Group = constants.StreamDefaultGroup
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = constants.StreamReadBatchSize
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = constants.StreamReadBlock
+	}
+	if cfg.MinIdle <= 0 {
+		cfg.MinIdle = constants.StreamClaimMinIdle
+	}
+
+	s := m.stream
+	s.ensureGroup(cfg.Group, cfg.StartID)
+
+	// first retry what this consumer already had pending (e.g. before a restart)
+	batch := s.claim(cfg, func(p *memPending) bool { return p.consumer == cfg.Consumer })
+	claimAt := time.Now().Add(cfg.MinIdle)
+	for {
+		for _, e := range batch {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if handler(ctx, e.swap) == nil {
+				s.ack(cfg.Group, e.id)
+			}
+		}
+
+		if time.Now().After(claimAt) {
+			cutoff := time.Now().Add(-cfg.MinIdle)
+			batch = s.claim(cfg, func(p *memPending) bool { return p.deliveredAt.Before(cutoff) })
+			claimAt = time.Now().Add(cfg.MinIdle)
+			if len(batch) > 0 {
+				continue
+			}
+		}
+
+		var wake <-chan struct{}
+		batch, wake = s.read(cfg)
+		if batch != nil {
+			continue
+		}
+		if wake == nil {
+			return nil // cache closed
+		}
+		timer := time.NewTimer(min(cfg.Block, time.Until(claimAt)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (s *memStream) ensureGroup(name, startID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.groups[name]; ok {
+		return
+	}
+	g := &memGroup{lastDelivered: s.lastID, pending: make(map[uint64]*memPending)}
+	if startID == "0" {
+		g.lastDelivered = 0
+	}
+	s.groups[name] = g
+}
+
+// read hands cfg.Consumer up to cfg.BatchSize entries its group has not seen.
+// With none it returns a channel closed on the next append, or nil once the
+// stream is closed.
+func (s *memStream) read(cfg storage.ConsumerConfig) ([]memEntry, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, nil
+	}
+	g := s.groups[cfg.Group]
+	var batch []memEntry
+	now := time.Now()
+	for _, e := range s.entries {
+		if int64(len(batch)) >= cfg.BatchSize {
+			break
+		}
+		if e.id <= g.lastDelivered {
+			continue
+		}
+		batch = append(batch, e)
+		g.lastDelivered = e.id
+		g.pending[e.id] = &memPending{entry: e, consumer: cfg.Consumer, deliveredAt: now}
+	}
+	return batch, s.wake
+}
+
+// claim moves up to cfg.BatchSize pending entries of the group matching keep
+// to cfg.Consumer, oldest first
+func (s *memStream) claim(cfg storage.ConsumerConfig, keep func(*memPending) bool) []memEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := s.groups[cfg.Group]
+	var batch []memEntry
+	for _, p := range g.pending {
+		if keep(p) {
+			batch = append(batch, p.entry)
+		}
+	}
+	slices.SortFunc(batch, func(a, b memEntry) int { return cmp.Compare(a.id, b.id) })
+	if int64(len(batch)) > cfg.BatchSize {
+		batch = batch[:cfg.BatchSize]
+	}
+	now := time.Now()
+	for _, e := range batch {
+		p := g.pending[e.id]
+		p.consumer, p.deliveredAt = cfg.Consumer, now
+	}
+	return batch
+}
+
+func (s *memStream) ack(group string, id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.groups[group].pending, id)
+}
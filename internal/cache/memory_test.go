@@ -0,0 +1,251 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func swap(n int) *models.SwapEvent {
+	return &models.SwapEvent{Signature: fmt.Sprintf("sig%08d", n), Pair: "SOL/USDC"}
+}
+
+func TestMemoryCache_RecentSwapsRing(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(3, 0)
+
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, m.AddRecentSwap(ctx, swap(i)))
+	}
+
+	got, err := m.GetRecentSwaps(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, swap(5).Signature, got[0].Signature)
+	assert.Equal(t, swap(3).Signature, got[2].Signature)
+
+	got, err = m.GetRecentSwaps(ctx, 2)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+}
+
+func TestMemoryCache_PriceExpires(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(0, 20*time.Millisecond)
+
+	require.NoError(t, m.UpdatePrice(ctx, "SOL", 150))
+	p, err := m.GetPrice(ctx, "SOL")
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, 150.0, p.Price)
+
+	time.Sleep(30 * time.Millisecond)
+	p, err = m.GetPrice(ctx, "SOL")
+	require.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+// flakyCache is a SwapCache whose reads fail while down is set
+type flakyCache struct {
+	*MemoryCache
+	down bool
+}
+
+var errDown = errors.New("connection refused")
+
+func (f *flakyCache) GetRecentSwaps(ctx context.Context, limit int64) ([]*models.SwapEvent, error) {
+	if f.down {
+		return nil, errDown
+	}
+	return f.MemoryCache.GetRecentSwaps(ctx, limit)
+}
+
+func (f *flakyCache) GetPrice(ctx context.Context, token string) (*models.TokenPrice, error) {
+	if f.down {
+		return nil, errDown
+	}
+	return f.MemoryCache.GetPrice(ctx, token)
+}
+
+var _ storage.SwapCache = (*flakyCache)(nil)
+
+func TestFallbackCache_ServesLastReadsWhilePrimaryIsDown(t *testing.T) {
+	ctx := context.Background()
+	primary := &flakyCache{MemoryCache: NewMemoryCache(0, 0)}
+	require.NoError(t, primary.AddRecentSwap(ctx, swap(1)))
+	require.NoError(t, primary.UpdatePrice(ctx, "SOL", 150))
+
+	f := NewFallbackCache(primary, nil, nil)
+
+	// healthy reads populate the fallback
+	_, err := f.GetRecentSwaps(ctx, 100)
+	require.NoError(t, err)
+	_, err = f.GetPrice(ctx, "SOL")
+	require.NoError(t, err)
+	assert.False(t, f.Degraded())
+
+	primary.down = true
+
+	got, err := f.GetRecentSwaps(ctx, 100)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, swap(1).Signature, got[0].Signature)
+
+	p, err := f.GetPrice(ctx, "SOL")
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, 150.0, p.Price)
+	assert.True(t, f.Degraded())
+
+	primary.down = false
+	_, err = f.GetPrice(ctx, "SOL")
+	require.NoError(t, err)
+	assert.False(t, f.Degraded())
+}
+
+func TestMemoryCache_PriceHistory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(0, 0)
+	now := time.Now().UTC()
+
+	m.MergePriceHistory("SOL", []models.PricePoint{
+		{Price: 1, At: now.Add(-2 * time.Hour)}, // outside the window, trimmed
+		{Price: 3, At: now.Add(-time.Minute)},
+		{Price: 2, At: now.Add(-10 * time.Minute)},
+	})
+	m.SetPrice(models.TokenPrice{Token: "SOL", Price: 4, UpdatedAt: now})
+
+	got, err := m.GetPriceHistory(ctx, "SOL", now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, []float64{2, 3, 4}, []float64{got[0].Price, got[1].Price, got[2].Price})
+
+	got, err = m.GetPriceHistory(ctx, "SOL", now.Add(-5*time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+}
+
+func TestMemoryCache_RecentSwapsByPair(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(0, 0)
+
+	require.NoError(t, m.AddRecentSwap(ctx, swap(1)))
+	require.NoError(t, m.AddRecentSwap(ctx, &models.SwapEvent{Signature: "bonk0001", Pair: "BONK/SOL"}))
+	require.NoError(t, m.AddRecentSwap(ctx, swap(2)))
+
+	got, err := m.GetRecentSwapsByPair(ctx, "sol/usdc", 10)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, swap(2).Signature, got[0].Signature)
+
+	got, err = m.GetRecentSwapsByPair(ctx, "BONK/SOL", 10)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
+
+func TestMemoryCache_RetractionDropsRecentSwap(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(0, 0)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, m.AddRecentSwap(ctx, swap(i)))
+	}
+
+	require.NoError(t, m.PublishRetraction(ctx, &models.SwapRetraction{Swap: swap(2), Reason: models.RetractDroppedFork}))
+	got, err := m.GetRecentSwaps(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, []string{swap(3).Signature, swap(1).Signature}, []string{got[0].Signature, got[1].Signature})
+	got, err = m.GetRecentSwapsByPair(ctx, swap(2).Pair, 10)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+}
+
+func TestMemoryCache_PairListsAreNotCrowdedOut(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(3, 0)
+
+	require.NoError(t, m.AddRecentSwap(ctx, &models.SwapEvent{Signature: "bonk0001", Pair: "BONK/SOL"}))
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, m.AddRecentSwap(ctx, swap(i)))
+	}
+
+	// like the Redis lists, each pair keeps its own maxRecent swaps
+	got, err := m.GetRecentSwapsByPair(ctx, "BONK/SOL", 10)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+	got, err = m.GetRecentSwaps(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, got, 3)
+}
+
+func TestMemoryCache_ConsumerGroups(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	m := NewMemoryCache(0, 0)
+
+	require.NoError(t, m.ProcessSwap(ctx, swap(1))) // before any group: only "0" starts here
+
+	type delivery struct {
+		group, signature string
+	}
+	got := make(chan delivery, 20)
+	failOnce := map[string]bool{swap(2).Signature: true}
+	consume := func(cfg storage.ConsumerConfig) {
+		go func() {
+			_ = m.ConsumeSwaps(ctx, cfg, func(_ context.Context, s *models.SwapEvent) error {
+				got <- delivery{cfg.Group, s.Signature}
+				if cfg.Group == "retry" && failOnce[s.Signature] {
+					failOnce[s.Signature] = false
+					return errors.New("handler failed")
+				}
+				return nil
+			})
+		}()
+	}
+	consume(storage.ConsumerConfig{Group: "backlog", Consumer: "a", StartID: "0"})
+	consume(storage.ConsumerConfig{Group: "retry", Consumer: "a", MinIdle: 20 * time.Millisecond})
+	waitFor := func(want delivery) {
+		t.Helper()
+		select {
+		case d := <-got:
+			assert.Equal(t, want, d)
+		case <-ctx.Done():
+			t.Fatalf("no delivery of %v", want)
+		}
+	}
+	waitFor(delivery{"backlog", swap(1).Signature})
+	time.Sleep(20 * time.Millisecond) // let the retry group start at the end of the stream
+
+	require.NoError(t, m.AppendSwap(ctx, swap(2)))
+	seen := map[delivery]int{}
+	for range 3 { // backlog once, retry twice: failed, then claimed again
+		select {
+		case d := <-got:
+			seen[d]++
+		case <-ctx.Done():
+			t.Fatal("missing deliveries")
+		}
+	}
+	assert.Equal(t, map[delivery]int{{"backlog", swap(2).Signature}: 1, {"retry", swap(2).Signature}: 2}, seen)
+
+	assert.Error(t, m.ConsumeSwaps(ctx, storage.ConsumerConfig{}, nil), "a consumer name is required")
+
+	// closing the cache stops consumers
+	done := make(chan error, 1)
+	go func() { done <- m.ConsumeSwaps(ctx, storage.ConsumerConfig{Consumer: "b"}, nil) }()
+	require.NoError(t, m.Close())
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal("consumer kept running after Close")
+	}
+}
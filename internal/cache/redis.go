@@ -2,11 +2,18 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/codec"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
 
 	"github.com/redis/go-redis/v9"
@@ -15,14 +22,38 @@ import (
 
 // RedisCache implements the SwapCache interface using Redis
 type RedisCache struct {
-	client *redis.Client
-	logger *logrus.Logger
+	client   *redis.Client
+	logger   *logrus.Logger
+	priceTTL time.Duration
+
+	historyWindow    time.Duration
+	historyMaxPoints int64
+
+	maxRecent int64
+
+	codec *codec.SwapCodec // encoding of swap events in lists, pub/sub and the stream
 }
 
+// jsonCodec is the default swap encoding
+var jsonCodec, _ = codec.New(codec.EncodingJSON)
+
 // RedisConfig holds configuration for Redis connection
 type RedisConfig struct {
-	Addr   string
-	Logger *logrus.Logger
+	Addr     string
+	Username string // ACL user (optional)
+	Password string // optional
+	DB       int
+	TLS      bool // connect over TLS (managed Redis offerings)
+	Logger   *logrus.Logger
+
+	PriceTTL time.Duration // expiry of price entries (default constants.PriceTTL)
+
+	PriceHistoryWindow    time.Duration // age of the oldest kept history point (default constants.PriceHistoryWindow)
+	PriceHistoryMaxPoints int64         // newest history points kept per token (default constants.PriceHistoryMaxPoints)
+
+	MaxRecentSwaps int64 // length of the global and each per-pair recent list (default constants.MaxRecentSwaps)
+
+	Encoding string // swap event encoding written to Redis: json (default) or msgpack; both are always read
 }
 
 // NewRedisCache creates a new Redis cache with connection verification
@@ -31,59 +62,124 @@ func NewRedisCache(ctx context.Context, cfg RedisConfig) (*RedisCache, error) {
 		cfg.Logger = logrus.New()
 	}
 
-	client := redis.NewClient(&redis.Options{
-		Addr: cfg.Addr,
-		DB:   0,
-	})
+	client := NewRedisClient(cfg)
 
 	// Verify connection
 	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
+	c, err := NewSharedRedisCache(client, cfg)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
 	cfg.Logger.WithField("addr", cfg.Addr).Info("connected to Redis")
-	return NewRedisCacheFromClient(client, cfg.Logger), nil
+	return c, nil
+}
+
+// NewSharedRedisCache applies the cache settings of cfg on top of an existing
+// client (its connection fields are ignored), so one process can share a single
+// connection pool between the cache, flags and other Redis users
+func NewSharedRedisCache(client *redis.Client, cfg RedisConfig) (*RedisCache, error) {
+	swapCodec, err := codec.New(cfg.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewRedisCacheFromClient(client, cfg.Logger)
+	c.codec = swapCodec
+	if cfg.PriceTTL > 0 {
+		c.priceTTL = cfg.PriceTTL
+	}
+	if cfg.PriceHistoryWindow > 0 {
+		c.historyWindow = cfg.PriceHistoryWindow
+	}
+	if cfg.PriceHistoryMaxPoints > 0 {
+		c.historyMaxPoints = cfg.PriceHistoryMaxPoints
+	}
+	if cfg.MaxRecentSwaps > 0 {
+		c.maxRecent = cfg.MaxRecentSwaps
+	}
+	return c, nil
+}
+
+// NewRedisClient creates a Redis client from the connection fields of cfg.
+// Every component builds its client here so they share one configuration path.
+func NewRedisClient(cfg RedisConfig) *redis.Client {
+	opts := &redis.Options{
+		Addr:     cfg.Addr,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	}
+	if cfg.TLS {
+		host, _, err := net.SplitHostPort(cfg.Addr)
+		if err != nil {
+			host = cfg.Addr
+		}
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, ServerName: host}
+	}
+	return redis.NewClient(opts)
 }
+
+// NewRedisCacheFromClient wraps an existing client, e.g. one shared with the flags store
 func NewRedisCacheFromClient(client *redis.Client, logger *logrus.Logger) *RedisCache {
 	if logger == nil {
 		logger = logrus.New()
 	}
 	return &RedisCache{
-		client: client,
-		logger: logger,
+		client:   client,
+		logger:   logger,
+		priceTTL: constants.PriceTTL,
+
+		historyWindow:    constants.PriceHistoryWindow,
+		historyMaxPoints: constants.PriceHistoryMaxPoints,
+
+		maxRecent: constants.MaxRecentSwaps,
+
+		codec: jsonCodec,
 	}
 }
 
+// Client exposes the underlying Redis client for components that share the
+// connection (e.g. config reload subscriptions)
+func (r *RedisCache) Client() *redis.Client {
+	return r.client
+}
+
 // AddRecentSwap adds a swap to the recent swaps list
 func (r *RedisCache) AddRecentSwap(ctx context.Context, swap *models.SwapEvent) error {
-	data, err := json.Marshal(swap)
+	data, err := r.codec.Marshal(swap)
 	if err != nil {
 		return fmt.Errorf("failed to marshal swap: %w", err)
 	}
 
-	// Add to list (LPUSH = add to front)
-	if err := r.client.LPush(ctx, constants.RedisKeyRecentSwaps, data).Err(); err != nil {
+	pipe := r.client.TxPipeline()
+	r.queueRecentSwap(ctx, pipe, swap.Pair, data)
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("failed to push to Redis: %w", err)
 	}
 
-	// Trim to keep only last N swaps
-	if err := r.client.LTrim(ctx, constants.RedisKeyRecentSwaps, 0, int64(constants.MaxRecentSwaps-1)).Err(); err != nil {
-		return fmt.Errorf("failed to trim list: %w", err)
-	}
-
-	r.logger.WithFields(logrus.Fields{
+	logging.Swaps.Debug(logging.Entry(ctx, r.logger).WithFields(logrus.Fields{
 		"signature": swap.Signature[:8],
 		"pair":      swap.Pair,
-	}).Debug("added swap to cache")
+	}), "added swap to cache")
 
 	return nil
 }
 
-// UpdatePrice updates the current price for a token
+// UpdatePrice updates the current price for a token. The entry records when
+// it was written and expires after the configured price TTL, so a token that
+// stops trading does not keep serving its last price forever.
 func (r *RedisCache) UpdatePrice(ctx context.Context, token string, price float64) error {
-	key := constants.RedisKeyPricePrefix + token
-
-	if err := r.client.Set(ctx, key, price, 0).Err(); err != nil {
+	pipe := r.client.TxPipeline()
+	if err := r.queuePrice(ctx, pipe, token, price, time.Now().UTC()); err != nil {
+		return err
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("failed to set price: %w", err)
 	}
 
@@ -95,17 +191,88 @@ func (r *RedisCache) UpdatePrice(ctx context.Context, token string, price float6
 	return nil
 }
 
+// ProcessSwap records a swap everywhere the indexer writes it - the recent
+// lists, the token price and its history, per-venue prices, pub/sub and the stream - in one
+// MULTI/EXEC round trip instead of one per operation
+func (r *RedisCache) ProcessSwap(ctx context.Context, swap *models.SwapEvent) error {
+	data, err := r.codec.Marshal(swap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal swap: %w", err)
+	}
+
+	pipe := r.client.TxPipeline()
+	r.queueRecentSwap(ctx, pipe, swap.Pair, data)
+	now := time.Now().UTC()
+	if err := r.queuePrice(ctx, pipe, swap.TokenOut, swap.Price, now); err != nil {
+		return err
+	}
+	if err := r.queueMarkets(ctx, pipe, swap, now); err != nil {
+		return err
+	}
+	event, err := r.codec.MarshalEvent(swap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal swap for publish: %w", err)
+	}
+	queuePublish(ctx, pipe, swap, event)
+	r.xadd(ctx, pipe, data)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to process swap in Redis: %w", err)
+	}
+
+	logging.Swaps.Debug(logging.Entry(ctx, r.logger).WithFields(logrus.Fields{
+		"signature": swap.Signature[:8],
+		"pair":      swap.Pair,
+	}), "processed swap in cache")
+
+	return nil
+}
+
+// queueRecentSwap adds a swap to the global and the pair's list (LPUSH = add
+// to front), trimmed to the last N swaps
+func (r *RedisCache) queueRecentSwap(ctx context.Context, pipe redis.Pipeliner, pair string, data []byte) {
+	for _, key := range []string{constants.RedisKeyRecentSwaps, recentPairKey(pair)} {
+		pipe.LPush(ctx, key, data)
+		pipe.LTrim(ctx, key, 0, r.maxRecent-1)
+	}
+}
+
+// queuePrice sets the latest price plus a rolling history trimmed by age and count
+func (r *RedisCache) queuePrice(ctx context.Context, pipe redis.Pipeliner, token string, price float64, now time.Time) error {
+	data, err := json.Marshal(models.TokenPrice{Token: token, Price: price, UpdatedAt: now})
+	if err != nil {
+		return fmt.Errorf("failed to marshal price: %w", err)
+	}
+
+	histKey := constants.RedisKeyPriceHistoryPrefix + token
+	ms := now.UnixMilli()
+	pipe.Set(ctx, constants.RedisKeyPricePrefix+token, data, r.priceTTL)
+	pipe.ZAdd(ctx, histKey, redis.Z{Score: float64(ms), Member: historyMember(ms, price)})
+	pipe.ZRemRangeByScore(ctx, histKey, "-inf", "("+strconv.FormatInt(now.Add(-r.historyWindow).UnixMilli(), 10))
+	pipe.ZRemRangeByRank(ctx, histKey, 0, -r.historyMaxPoints-1)
+	pipe.Expire(ctx, histKey, r.historyWindow)
+	return nil
+}
+
 // GetRecentSwaps retrieves the most recent swaps
 func (r *RedisCache) GetRecentSwaps(ctx context.Context, limit int64) ([]*models.SwapEvent, error) {
-	data, err := r.client.LRange(ctx, constants.RedisKeyRecentSwaps, 0, limit-1).Result()
+	return r.recentSwaps(ctx, constants.RedisKeyRecentSwaps, limit)
+}
+
+// GetRecentSwapsByPair retrieves the most recent swaps of one pair
+func (r *RedisCache) GetRecentSwapsByPair(ctx context.Context, pair string, limit int64) ([]*models.SwapEvent, error) {
+	return r.recentSwaps(ctx, recentPairKey(pair), limit)
+}
+
+func (r *RedisCache) recentSwaps(ctx context.Context, key string, limit int64) ([]*models.SwapEvent, error) {
+	data, err := r.client.LRange(ctx, key, 0, limit-1).Result()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get recent swaps: %w", err)
+		return nil, apperr.Errorf(apperr.UpstreamUnavailable, "failed to get recent swaps: %w", err)
 	}
 
 	swaps := make([]*models.SwapEvent, 0, len(data))
 	for _, d := range data {
 		var swap models.SwapEvent
-		if err := json.Unmarshal([]byte(d), &swap); err != nil {
+		if err := r.codec.Unmarshal([]byte(d), &swap); err != nil {
 			r.logger.WithError(err).Warn("failed to unmarshal swap from cache")
 			continue
 		}
@@ -115,29 +282,85 @@ func (r *RedisCache) GetRecentSwaps(ctx context.Context, limit int64) ([]*models
 	return swaps, nil
 }
 
-// GetPrice retrieves the current price for a token
-func (r *RedisCache) GetPrice(ctx context.Context, token string) (float64, error) {
+// GetPrice retrieves the last price for a token; nil means no price is cached
+func (r *RedisCache) GetPrice(ctx context.Context, token string) (*models.TokenPrice, error) {
 	key := constants.RedisKeyPricePrefix + token
 
 	val, err := r.client.Get(ctx, key).Result()
 	if err == redis.Nil {
-		return 0, nil
+		return nil, nil
 	}
 	if err != nil {
-		return 0, fmt.Errorf("failed to get price: %w", err)
+		return nil, apperr.Errorf(apperr.UpstreamUnavailable, "failed to get price: %w", err)
+	}
+
+	var p models.TokenPrice
+	if err := json.Unmarshal([]byte(val), &p); err == nil && p.Token != "" {
+		return &p, nil
 	}
 
+	// entries written before timestamps were stored are a bare number
 	price, err := strconv.ParseFloat(val, 64)
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse price: %w", err)
+		return nil, fmt.Errorf("failed to parse price: %w", err)
 	}
+	return &models.TokenPrice{Token: token, Price: price}, nil
+}
+
+// GetPriceHistory retrieves the price points recorded for a token since the
+// given time, oldest first. History only reaches back PriceHistoryWindow.
+func (r *RedisCache) GetPriceHistory(ctx context.Context, token string, since time.Time) ([]models.PricePoint, error) {
+	key := constants.RedisKeyPriceHistoryPrefix + token
 
-	return price, nil
+	members, err := r.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, apperr.Errorf(apperr.UpstreamUnavailable, "failed to get price history: %w", err)
+	}
+
+	points := make([]models.PricePoint, 0, len(members))
+	for _, m := range members {
+		p, ok := parseHistoryMember(m)
+		if !ok {
+			continue
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// recentPairKey is the recent swaps list of a pair; pairs are stored upper-case
+func recentPairKey(pair string) string {
+	return constants.RedisKeyRecentPairPrefix + strings.ToUpper(pair)
+}
+
+// historyMember encodes a point as "<unix ms>:<price>"; the timestamp keeps
+// members unique when the same price repeats
+func historyMember(ms int64, price float64) string {
+	return strconv.FormatInt(ms, 10) + ":" + strconv.FormatFloat(price, 'g', -1, 64)
+}
+
+func parseHistoryMember(m string) (models.PricePoint, bool) {
+	msStr, priceStr, ok := strings.Cut(m, ":")
+	if !ok {
+		return models.PricePoint{}, false
+	}
+	ms, err := strconv.ParseInt(msStr, 10, 64)
+	if err != nil {
+		return models.PricePoint{}, false
+	}
+	price, err := strconv.ParseFloat(priceStr, 64)
+	if err != nil {
+		return models.PricePoint{}, false
+	}
+	return models.PricePoint{Price: price, At: time.UnixMilli(ms).UTC()}, true
 }
 
 // Ping checks if Redis is reachable
 func (r *RedisCache) Ping(ctx context.Context) error {
-	return r.client.Ping(ctx).Err()
+	return apperr.Wrap(apperr.UpstreamUnavailable, r.client.Ping(ctx).Err())
 }
 
 // Close closes the Redis connection
@@ -146,27 +369,45 @@ func (r *RedisCache) Close() error {
 	return r.client.Close()
 }
 
-// PublishSwap publishes a swap event to the Pub/Sub channel for real-time consumers
+// PublishSwap publishes a swap event to the Pub/Sub channel for real-time
+// consumers, wrapped in a versioned codec.Envelope
 func (r *RedisCache) PublishSwap(ctx context.Context, swap *models.SwapEvent) error {
-	data, err := json.Marshal(swap)
+	data, err := r.codec.MarshalEvent(swap)
 	if err != nil {
 		return fmt.Errorf("failed to marshal swap for publish: %w", err)
 	}
 
-	subscribers, err := r.client.Publish(ctx, constants.PubSubChannelSwaps, data).Result()
-	if err != nil {
-		return fmt.Errorf("failed to publish swap: %w", err)
+	pipe := r.client.Pipeline()
+	live := queuePublish(ctx, pipe, swap, data)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return apperr.Errorf(apperr.UpstreamUnavailable, "failed to publish swap: %w", err)
 	}
+	subscribers := live.Val()
 
-	r.logger.WithFields(logrus.Fields{
+	logging.Swaps.Debug(logging.Entry(ctx, r.logger).WithFields(logrus.Fields{
 		"signature":   swap.Signature[:8],
 		"pair":        swap.Pair,
 		"subscribers": subscribers,
-	}).Debug("published swap to channel")
+	}), "published swap to channel")
 
 	return nil
 }
 
+// queuePublish publishes an encoded swap on swaps:live and, when the swap
+// names its wallet, on that wallet's channel; it returns the swaps:live command
+func queuePublish(ctx context.Context, pipe redis.Pipeliner, swap *models.SwapEvent, event []byte) *redis.IntCmd {
+	live := pipe.Publish(ctx, constants.PubSubChannelSwaps, event)
+	if swap.Wallet != "" {
+		pipe.Publish(ctx, WalletChannel(swap.Wallet), event)
+	}
+	return live
+}
+
+// WalletChannel is the Pub/Sub channel of the swaps signed by one wallet
+func WalletChannel(wallet string) string {
+	return constants.PubSubChannelWallet + wallet
+}
+
 // SubscribeSwaps creates a subscription to the swaps channel and returns a channel
 // that receives swap events in real-time. The caller is responsible for reading
 // from the channel until the context is cancelled.
@@ -207,7 +448,7 @@ func (r *RedisCache) SubscribeSwaps(ctx context.Context) (<-chan *models.SwapEve
 				}
 
 				var swap models.SwapEvent
-				if err := json.Unmarshal([]byte(msg.Payload), &swap); err != nil {
+				if err := r.codec.Unmarshal([]byte(msg.Payload), &swap); err != nil {
 					r.logger.WithError(err).Warn("failed to unmarshal swap from pubsub")
 					continue
 				}
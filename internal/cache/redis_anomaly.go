@@ -0,0 +1,19 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// PublishAnomaly announces an anomaly on the alerts channel and keeps it in
+// the capped list of recent ones
+func (r *RedisCache) PublishAnomaly(ctx context.Context, a *models.Anomaly) error {
+	return r.publishRecent(ctx, constants.PubSubChannelAnomalies, constants.RedisKeyAnomaliesRecent, constants.AnomaliesRecentMax, a, "anomaly")
+}
+
+// RecentAnomalies returns up to limit anomalies, newest first
+func (r *RedisCache) RecentAnomalies(ctx context.Context, limit int64) ([]*models.Anomaly, error) {
+	return recentJSON[models.Anomaly](ctx, r, constants.RedisKeyAnomaliesRecent, limit, "anomaly")
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// PublishArbOpportunity announces an opportunity on the arb channel and
+// keeps it in the capped list of recent ones
+func (r *RedisCache) PublishArbOpportunity(ctx context.Context, op *models.ArbOpportunity) error {
+	return r.publishRecent(ctx, constants.PubSubChannelArb, constants.RedisKeyArbRecent, constants.ArbRecentMax, op, "opportunity")
+}
+
+// RecentArbOpportunities returns up to limit opportunities, newest first
+func (r *RedisCache) RecentArbOpportunities(ctx context.Context, limit int64) ([]*models.ArbOpportunity, error) {
+	return recentJSON[models.ArbOpportunity](ctx, r, constants.RedisKeyArbRecent, limit, "opportunity")
+}
+
+// publishRecent publishes v as JSON on channel and pushes it onto the capped
+// list key; what names v in errors
+func (r *RedisCache) publishRecent(ctx context.Context, channel, key string, max int64, v any, what string) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", what, err)
+	}
+	pipe := r.client.TxPipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, max-1)
+	pipe.Publish(ctx, channel, data)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish %s: %w", what, err)
+	}
+	return nil
+}
+
+// recentJSON decodes the newest limit entries of a list written by
+// publishRecent, skipping any that do not decode
+func recentJSON[T any](ctx context.Context, r *RedisCache, key string, limit int64, what string) ([]*T, error) {
+	vals, err := r.client.LRange(ctx, key, 0, limit-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s list: %w", what, err)
+	}
+	out := make([]*T, 0, len(vals))
+	for _, v := range vals {
+		var item T
+		if err := json.Unmarshal([]byte(v), &item); err != nil {
+			r.logger.WithError(err).Warnf("skipping undecodable %s", what)
+			continue
+		}
+		out = append(out, &item)
+	}
+	return out, nil
+}
@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetCheckpoint returns the saved poller cursor for source, or "" if none
+func (r *RedisCache) GetCheckpoint(ctx context.Context, source string) (string, error) {
+	cursor, err := r.client.Get(ctx, constants.RedisKeyCheckpointPrefix+source).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get checkpoint: %w", err)
+	}
+	return cursor, nil
+}
+
+// SetCheckpoint saves the poller cursor for source
+func (r *RedisCache) SetCheckpoint(ctx context.Context, source, cursor string) error {
+	if err := r.client.Set(ctx, constants.RedisKeyCheckpointPrefix+source, cursor, 0).Err(); err != nil {
+		return fmt.Errorf("failed to set checkpoint: %w", err)
+	}
+	return nil
+}
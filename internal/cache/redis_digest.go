@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// ClaimDigest reports whether the caller is the first to send the digests
+// of schedule for the period ending at end; replicas of the digest job
+// race for it and the losers skip the period
+func (r *RedisCache) ClaimDigest(ctx context.Context, schedule string, end time.Time) (bool, error) {
+	key := constants.RedisKeyDigestSentPrefix + schedule + ":" + strconv.FormatInt(end.Unix(), 10)
+	ok, err := r.client.SetNX(ctx, key, 1, constants.DigestSentTTL).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim %s digests: %w", schedule, err)
+	}
+	return ok, nil
+}
+
+// PublishDigest announces a subscription digest on the digests channel
+func (r *RedisCache) PublishDigest(ctx context.Context, d *models.Digest) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("failed to marshal digest: %w", err)
+	}
+	if err := r.client.Publish(ctx, constants.PubSubChannelDigests, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish digest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseDeadLettersScript moves a claim back to the head of the queue in
+// order. KEYS: queue, claim, claims; ARGV: worker.
+var releaseDeadLettersScript = redis.NewScript(`
+local n = 0
+while redis.call("LMOVE", KEYS[2], KEYS[1], "RIGHT", "LEFT") do
+	n = n + 1
+end
+redis.call("ZREM", KEYS[3], ARGV[1])
+return n
+`)
+
+// claimDeadLettersScript releases what the worker still held, then moves up
+// to ARGV[2] entries from the queue into its claim. KEYS: queue, claim,
+// claims; ARGV: worker, n, now.
+var claimDeadLettersScript = redis.NewScript(`
+while redis.call("LMOVE", KEYS[2], KEYS[1], "RIGHT", "LEFT") do end
+local out = {}
+for i = 1, tonumber(ARGV[2]) do
+	local v = redis.call("LMOVE", KEYS[1], KEYS[2], "LEFT", "RIGHT")
+	if not v then
+		break
+	end
+	out[#out + 1] = v
+end
+if #out > 0 then
+	redis.call("ZADD", KEYS[3], ARGV[3], ARGV[1])
+else
+	redis.call("ZREM", KEYS[3], ARGV[1])
+end
+return out
+`)
+
+// dropDeadLettersScript removes the ARGV entries from a claim.
+// KEYS: claim.
+var dropDeadLettersScript = redis.NewScript(`
+for i = 1, #ARGV do
+	redis.call("LREM", KEYS[1], 1, ARGV[i])
+end
+return #ARGV
+`)
+
+// ackDeadLetterScript drops the oldest claimed entry and requeues ARGV[2]
+// unless empty; 0 means the claim is gone. KEYS: queue, claim, claims;
+// ARGV: worker, requeue, now.
+var ackDeadLetterScript = redis.NewScript(`
+if not redis.call("LPOP", KEYS[2]) then
+	return 0
+end
+if ARGV[2] ~= "" then
+	redis.call("RPUSH", KEYS[1], ARGV[2])
+end
+if redis.call("LLEN", KEYS[2]) == 0 then
+	redis.call("ZREM", KEYS[3], ARGV[1])
+else
+	redis.call("ZADD", KEYS[3], ARGV[3], ARGV[1])
+end
+return 1
+`)
+
+// errDeadLetterClaimLost is returned by AckDeadLetter once the worker's
+// claim was handed back to the queue
+var errDeadLetterClaimLost = errors.New("dead letter claim expired")
+
+func deadLetterKeys(worker string) []string {
+	return []string{constants.RedisKeyDeadLetters, constants.RedisKeyDeadLetterClaimPrefix + worker, constants.RedisKeyDeadLetterClaims}
+}
+
+// PushDeadLetter appends an entry to the tail of the dead-letter list
+func (r *RedisCache) PushDeadLetter(ctx context.Context, dl *storage.DeadLetter) error {
+	data, err := json.Marshal(dl)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+	if err := r.client.RPush(ctx, constants.RedisKeyDeadLetters, data).Err(); err != nil {
+		return fmt.Errorf("failed to push dead letter: %w", err)
+	}
+	return nil
+}
+
+// ClaimDeadLetters moves up to n of the oldest entries into worker's claim
+// list. Claims of redrivers that acked nothing for DeadLetterClaimTimeout
+// (a crashed replica) are returned to the queue first. Entries that do not
+// decode are logged and dropped from the claim, so it holds exactly the
+// returned entries.
+func (r *RedisCache) ClaimDeadLetters(ctx context.Context, worker string, n int64) ([]*storage.DeadLetter, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	stale, err := r.client.ZRangeByScore(ctx, constants.RedisKeyDeadLetterClaims, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Add(-constants.DeadLetterClaimTimeout).UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letter claims: %w", err)
+	}
+	for _, w := range stale {
+		if err := r.ReleaseDeadLetters(ctx, w); err != nil {
+			return nil, err
+		}
+		r.logger.WithField("worker", w).Warn("returned a stale dead letter claim to the queue")
+	}
+
+	raw, err := claimDeadLettersScript.Run(ctx, r.client, deadLetterKeys(worker), worker, n, now.UnixMilli()).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim dead letters: %w", err)
+	}
+	out := make([]*storage.DeadLetter, 0, len(raw))
+	var undecodable []any
+	for _, item := range raw {
+		var dl storage.DeadLetter
+		if err := json.Unmarshal([]byte(item), &dl); err != nil || dl.Swap == nil {
+			r.logger.WithError(err).WithField("payload", item).Warn("dropping undecodable dead letter")
+			undecodable = append(undecodable, item)
+			continue
+		}
+		out = append(out, &dl)
+	}
+	if len(undecodable) > 0 {
+		if err := dropDeadLettersScript.Run(ctx, r.client, []string{constants.RedisKeyDeadLetterClaimPrefix + worker}, undecodable...).Err(); err != nil {
+			err = fmt.Errorf("failed to drop undecodable dead letters: %w", err)
+			return nil, errors.Join(err, r.ReleaseDeadLetters(ctx, worker))
+		}
+	}
+	return out, nil
+}
+
+// AckDeadLetter drops the oldest entry of worker's claim and, if requeue is
+// set, appends it to the queue atomically, so a failure in between neither
+// loses nor duplicates the entry
+func (r *RedisCache) AckDeadLetter(ctx context.Context, worker string, requeue *storage.DeadLetter) error {
+	var data []byte
+	if requeue != nil {
+		var err error
+		if data, err = json.Marshal(requeue); err != nil {
+			return fmt.Errorf("failed to marshal dead letter: %w", err)
+		}
+	}
+	ok, err := ackDeadLetterScript.Run(ctx, r.client, deadLetterKeys(worker), worker, data, time.Now().UnixMilli()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to ack dead letter: %w", err)
+	}
+	if ok == 0 {
+		return errDeadLetterClaimLost
+	}
+	return nil
+}
+
+// ReleaseDeadLetters returns the rest of worker's claim to the head of the queue
+func (r *RedisCache) ReleaseDeadLetters(ctx context.Context, worker string) error {
+	if err := releaseDeadLettersScript.Run(ctx, r.client, deadLetterKeys(worker), worker).Err(); err != nil {
+		return fmt.Errorf("failed to release dead letters: %w", err)
+	}
+	return nil
+}
+
+// DeadLetterCount returns the number of queued entries, not counting those
+// a redriver holds
+func (r *RedisCache) DeadLetterCount(ctx context.Context) (int64, error) {
+	n, err := r.client.LLen(ctx, constants.RedisKeyDeadLetters).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// queueMarkets records the swap's price for both of its tokens on the venue
+// that executed it, and adds the traded amounts to the venues' volume
+func (r *RedisCache) queueMarkets(ctx context.Context, pipe redis.Pipeliner, swap *models.SwapEvent, now time.Time) error {
+	if swap.AmountIn <= 0 || swap.AmountOut <= 0 {
+		return nil
+	}
+	pool := swap.PoolAddress
+	if pool == "" {
+		pool = swap.Pool
+	}
+	bucket := marketBucket(now)
+	for _, side := range []struct {
+		token, quote  string
+		amount, price float64
+	}{
+		{swap.TokenIn, swap.TokenOut, swap.AmountIn, swap.AmountOut / swap.AmountIn},
+		{swap.TokenOut, swap.TokenIn, swap.AmountOut, swap.AmountIn / swap.AmountOut},
+	} {
+		data, err := json.Marshal(models.MarketPrice{Dex: swap.Dex, Pool: pool, Quote: side.quote, Price: side.price, UpdatedAt: now})
+		if err != nil {
+			return fmt.Errorf("failed to marshal market price: %w", err)
+		}
+		field := marketField(side.quote, swap.Dex, pool)
+		key := constants.RedisKeyMarketsPrefix + side.token
+		volKey := marketVolumeKey(side.token, bucket)
+		pipe.HSet(ctx, key, field, data)
+		pipe.Expire(ctx, key, r.priceTTL)
+		pipe.HIncrByFloat(ctx, volKey, field, side.amount)
+		pipe.Expire(ctx, volKey, 2*constants.MarketVolumeWindow)
+	}
+	return nil
+}
+
+// GetMarkets returns the last price of token on every venue that traded it
+// within the price TTL, with each venue's recent volume
+func (r *RedisCache) GetMarkets(ctx context.Context, token string) ([]models.MarketPrice, error) {
+	bucket := marketBucket(time.Now())
+	pipe := r.client.Pipeline()
+	prices := pipe.HGetAll(ctx, constants.RedisKeyMarketsPrefix+token)
+	cur := pipe.HGetAll(ctx, marketVolumeKey(token, bucket))
+	prev := pipe.HGetAll(ctx, marketVolumeKey(token, bucket-1))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get markets: %w", err)
+	}
+
+	cutoff := time.Now().Add(-r.priceTTL)
+	out := make([]models.MarketPrice, 0, len(prices.Val()))
+	for field, data := range prices.Val() {
+		var m models.MarketPrice
+		if err := json.Unmarshal([]byte(data), &m); err != nil {
+			r.logger.WithError(err).Warn("skipping undecodable market price")
+			continue
+		}
+		if m.UpdatedAt.Before(cutoff) {
+			continue // the venue stopped trading; the hash lives on while others trade
+		}
+		for _, vols := range []map[string]string{cur.Val(), prev.Val()} {
+			if v, err := strconv.ParseFloat(vols[field], 64); err == nil {
+				m.Volume += v
+			}
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// marketField names a venue within a token's markets hash
+func marketField(quote, dex, pool string) string {
+	return strings.Join([]string{quote, dex, pool}, "|")
+}
+
+// marketBucket numbers the MarketVolumeWindow that t falls in
+func marketBucket(t time.Time) int64 {
+	return t.Unix() / int64(constants.MarketVolumeWindow/time.Second)
+}
+
+func marketVolumeKey(token string, bucket int64) string {
+	return constants.RedisKeyMarketVolumePrefix + token + ":" + strconv.FormatInt(bucket, 10)
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetProgramOverrides returns the program addresses added and removed through
+// the admin API, sorted
+func (r *RedisCache) GetProgramOverrides(ctx context.Context) (*models.ProgramOverrides, error) {
+	var added, removed *redis.StringSliceCmd
+	_, err := r.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		added = p.SMembers(ctx, constants.RedisKeyProgramsAdded)
+		removed = p.SMembers(ctx, constants.RedisKeyProgramsRemoved)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get program overrides: %w", err)
+	}
+
+	out := &models.ProgramOverrides{Added: added.Val(), Removed: removed.Val()}
+	slices.Sort(out.Added)
+	slices.Sort(out.Removed)
+	return out, nil
+}
+
+// AddProgram makes every indexer poll addr, undoing an earlier RemoveProgram
+func (r *RedisCache) AddProgram(ctx context.Context, addr string) error {
+	_, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.SAdd(ctx, constants.RedisKeyProgramsAdded, addr)
+		p.SRem(ctx, constants.RedisKeyProgramsRemoved, addr)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add program: %w", err)
+	}
+	return nil
+}
+
+// RemoveProgram stops every indexer from polling addr, even when it is listed
+// in PROGRAM_ADDRESSES
+func (r *RedisCache) RemoveProgram(ctx context.Context, addr string) error {
+	_, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.SRem(ctx, constants.RedisKeyProgramsAdded, addr)
+		p.SAdd(ctx, constants.RedisKeyProgramsRemoved, addr)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove program: %w", err)
+	}
+	return nil
+}
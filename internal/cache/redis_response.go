@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetResponse returns a cached API response, or nil when there is none
+func (r *RedisCache) GetResponse(ctx context.Context, key string) ([]byte, error) {
+	data, err := r.client.Get(ctx, constants.RedisKeyResponseCachePrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached response: %w", err)
+	}
+	return data, nil
+}
+
+// PutResponse caches an API response for ttl
+func (r *RedisCache) PutResponse(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	if err := r.client.Set(ctx, constants.RedisKeyResponseCachePrefix+key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// PublishRetraction drops a retracted swap from the recent lists and
+// announces it on the retractions channel. Entries are matched by
+// signature, not by their bytes: the same swap encodes differently under
+// another SWAP_ENCODING or once warmed from ClickHouse.
+func (r *RedisCache) PublishRetraction(ctx context.Context, rt *models.SwapRetraction) error {
+	event, err := json.Marshal(rt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal retraction: %w", err)
+	}
+	keys := []string{constants.RedisKeyRecentSwaps, recentPairKey(rt.Swap.Pair)}
+	entries := make(map[string][]string, len(keys))
+	for _, key := range keys {
+		if entries[key], err = r.recentEntries(ctx, key, rt.Swap.Signature); err != nil {
+			return err
+		}
+	}
+
+	pipe := r.client.TxPipeline()
+	for key, datas := range entries {
+		for _, data := range datas {
+			// by value, so entries pushed in the meantime do not shift it
+			pipe.LRem(ctx, key, 0, data)
+		}
+	}
+	pipe.Publish(ctx, constants.PubSubChannelRetractions, event)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish retraction: %w", err)
+	}
+	return nil
+}
+
+// recentEntries returns the raw entries of the recent list at key that hold
+// the swap with signature
+func (r *RedisCache) recentEntries(ctx context.Context, key, signature string) ([]string, error) {
+	datas, err := r.client.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	var out []string
+	for _, data := range datas {
+		var swap models.SwapEvent
+		if err := r.codec.Unmarshal([]byte(data), &swap); err == nil && swap.Signature == signature {
+			out = append(out, data)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// PutIndexerStatus stores a replica's status report; it expires after ttl
+func (r *RedisCache) PutIndexerStatus(ctx context.Context, st *models.IndexerStatus, ttl time.Duration) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to marshal indexer status: %w", err)
+	}
+	if err := r.client.Set(ctx, constants.RedisKeyIndexerStatusPrefix+st.Instance, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store indexer status: %w", err)
+	}
+	return nil
+}
+
+// ListIndexerStatus returns the latest report of every live replica, by instance
+func (r *RedisCache) ListIndexerStatus(ctx context.Context) ([]models.IndexerStatus, error) {
+	var keys []string
+	iter := r.client.Scan(ctx, 0, constants.RedisKeyIndexerStatusPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list indexer status: %w", err)
+	}
+	if len(keys) == 0 {
+		return []models.IndexerStatus{}, nil
+	}
+
+	vals, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexer status: %w", err)
+	}
+
+	out := make([]models.IndexerStatus, 0, len(vals))
+	for _, v := range vals {
+		s, ok := v.(string)
+		if !ok {
+			continue // expired between SCAN and MGET
+		}
+		var st models.IndexerStatus
+		if err := json.Unmarshal([]byte(s), &st); err != nil {
+			r.logger.WithError(err).Warn("skipping undecodable indexer status")
+			continue
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Instance < out[j].Instance })
+	return out, nil
+}
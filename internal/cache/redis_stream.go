@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// streamField is the entry field holding the JSON-encoded swap
+const streamField = "swap"
+
+// AppendSwap appends a swap to the swap stream. Unlike PublishSwap the entry
+// is retained (up to constants.StreamMaxLen), so consumers that were offline
+// catch up from where their group left off.
+func (r *RedisCache) AppendSwap(ctx context.Context, swap *models.SwapEvent) error {
+	data, err := r.codec.Marshal(swap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal swap for stream: %w", err)
+	}
+
+	id, err := r.xadd(ctx, r.client, data).Result()
+	if err != nil {
+		return fmt.Errorf("failed to append swap to stream: %w", err)
+	}
+
+	logging.Swaps.Debug(logging.Entry(ctx, r.logger).WithFields(logrus.Fields{
+		"signature": swap.Signature[:8],
+		"pair":      swap.Pair,
+		"id":        id,
+	}), "appended swap to stream")
+
+	return nil
+}
+
+// xadd appends an encoded swap to the stream on c (the client or a pipeline)
+func (r *RedisCache) xadd(ctx context.Context, c redis.Cmdable, data []byte) *redis.StringCmd {
+	return c.XAdd(ctx, &redis.XAddArgs{
+		Stream: constants.RedisStreamSwaps,
+		MaxLen: constants.StreamMaxLen,
+		Approx: true,
+		Values: map[string]any{streamField: data},
+	})
+}
+
+// ConsumeSwaps reads the swap stream as cfg.Consumer in cfg.Group and blocks
+// until ctx is cancelled. Each entry is acknowledged once handler returns nil.
+// Entries whose handler failed, or whose consumer died mid-batch, stay pending
+// and are claimed again after cfg.MinIdle, by this or any other group member.
+func (r *RedisCache) ConsumeSwaps(ctx context.Context, cfg storage.ConsumerConfig, handler storage.StreamHandler) error {
+	if cfg.Consumer == "" {
+		return fmt.Errorf("stream consumer name is required")
+	}
+	if cfg.Group == "" {
+		cfg.Group = constants.StreamDefaultGroup
+	}
+	if cfg.StartID == "" {
+		cfg.StartID = "$"
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = constants.StreamReadBatchSize
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = constants.StreamReadBlock
+	}
+	if cfg.MinIdle <= 0 {
+		cfg.MinIdle = constants.StreamClaimMinIdle
+	}
+
+	if err := r.ensureGroup(ctx, cfg.Group, cfg.StartID); err != nil {
+		return err
+	}
+
+	log := r.logger.WithFields(logrus.Fields{
+		"stream":   constants.RedisStreamSwaps,
+		"group":    cfg.Group,
+		"consumer": cfg.Consumer,
+	})
+	log.Info("consuming swap stream")
+
+	// first drain what this consumer already had pending (e.g. before a restart)
+	pendingID := "0"
+	claimAt := time.Now().Add(cfg.MinIdle)
+	claimCursor := "0-0"
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if time.Now().After(claimAt) {
+			next, err := r.claimStale(ctx, cfg, claimCursor, handler, log)
+			if err != nil && ctx.Err() == nil {
+				log.WithError(err).Warn("failed to claim pending swaps")
+			}
+			claimCursor = next
+			claimAt = time.Now().Add(cfg.MinIdle)
+		}
+
+		readID := ">"
+		if pendingID != "" {
+			readID = pendingID
+		}
+
+		streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    cfg.Group,
+			Consumer: cfg.Consumer,
+			Streams:  []string{constants.RedisStreamSwaps, readID},
+			Count:    cfg.BatchSize,
+			Block:    cfg.Block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			pendingID = ""
+			continue // block timed out with nothing new
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if isNoGroup(err) {
+				// stream or group deleted underneath us; recreate and carry on
+				if err := r.ensureGroup(ctx, cfg.Group, cfg.StartID); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("failed to read swap stream: %w", err)
+		}
+
+		for _, s := range streams {
+			if pendingID != "" && len(s.Messages) == 0 {
+				pendingID = "" // own backlog drained, switch to new entries
+			}
+			for _, msg := range s.Messages {
+				r.handleEntry(ctx, cfg.Group, msg, handler, log)
+				if pendingID != "" {
+					pendingID = msg.ID
+				}
+			}
+		}
+	}
+}
+
+// claimStale takes over entries another consumer left pending for too long
+// and returns the cursor for the next claim round
+func (r *RedisCache) claimStale(ctx context.Context, cfg storage.ConsumerConfig, cursor string, handler storage.StreamHandler, log *logrus.Entry) (string, error) {
+	msgs, next, err := r.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   constants.RedisStreamSwaps,
+		Group:    cfg.Group,
+		Consumer: cfg.Consumer,
+		MinIdle:  cfg.MinIdle,
+		Start:    cursor,
+		Count:    cfg.BatchSize,
+	}).Result()
+	if err != nil {
+		return "0-0", err
+	}
+	if len(msgs) > 0 {
+		log.WithField("count", len(msgs)).Info("retrying stale pending swaps")
+	}
+	for _, msg := range msgs {
+		r.handleEntry(ctx, cfg.Group, msg, handler, log)
+	}
+	return next, nil
+}
+
+// handleEntry decodes one stream entry, runs handler and acks on success.
+// Entries that cannot be decoded are acked so they do not block the group.
+func (r *RedisCache) handleEntry(ctx context.Context, group string, msg redis.XMessage, handler storage.StreamHandler, log *logrus.Entry) {
+	raw, _ := msg.Values[streamField].(string)
+
+	var swap models.SwapEvent
+	if err := r.codec.Unmarshal([]byte(raw), &swap); err != nil {
+		log.WithError(err).WithField("id", msg.ID).Warn("dropping undecodable stream entry")
+		r.ack(ctx, group, msg.ID, log)
+		return
+	}
+
+	if err := handler(ctx, &swap); err != nil {
+		log.WithError(err).WithField("id", msg.ID).Warn("swap handler failed, leaving pending for retry")
+		return
+	}
+	r.ack(ctx, group, msg.ID, log)
+}
+
+func (r *RedisCache) ack(ctx context.Context, group, id string, log *logrus.Entry) {
+	if err := r.client.XAck(ctx, constants.RedisStreamSwaps, group, id).Err(); err != nil {
+		log.WithError(err).WithField("id", id).Warn("failed to ack stream entry")
+	}
+}
+
+// ensureGroup creates the consumer group (and the stream) if needed
+func (r *RedisCache) ensureGroup(ctx context.Context, group, startID string) error {
+	err := r.client.XGroupCreateMkStream(ctx, constants.RedisStreamSwaps, group, startID).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s: %w", group, err)
+	}
+	return nil
+}
+
+func isNoGroup(err error) bool {
+	return strings.HasPrefix(err.Error(), "NOGROUP")
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// addSubscriptionScript sets an owner's subscription unless that would take
+// the owner past the limit; replacing an existing one always succeeds
+var addSubscriptionScript = redis.NewScript(`
+if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 0 and redis.call("HLEN", KEYS[1]) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+redis.call("SADD", KEYS[2], ARGV[4])
+return 1
+`)
+
+// deleteSubscriptionScript removes an owner's subscription, and the owner
+// from the index once it has none left
+var deleteSubscriptionScript = redis.NewScript(`
+local n = redis.call("HDEL", KEYS[1], ARGV[1])
+if redis.call("HLEN", KEYS[1]) == 0 then
+	redis.call("SREM", KEYS[2], ARGV[2])
+end
+return n
+`)
+
+// SubscriptionID is the ID of a subscription to target: the same for every
+// owner, so subscribing twice to a target replaces the first subscription
+func SubscriptionID(target string) string {
+	sum := sha256.Sum256([]byte(target))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ListSubscriptions returns owner's subscriptions, oldest first
+func (r *RedisCache) ListSubscriptions(ctx context.Context, owner string) ([]*models.Subscription, error) {
+	vals, err := r.client.HVals(ctx, subscriptionsKey(owner)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	return decodeSubscriptions(vals), nil
+}
+
+// AddSubscription stores sub under its target's ID, replacing owner's
+// earlier subscription to the target. It returns false when owner already
+// has limit other subscriptions.
+func (r *RedisCache) AddSubscription(ctx context.Context, owner string, sub *models.Subscription, limit int) (bool, error) {
+	sub.ID = SubscriptionID(sub.Target())
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal subscription: %w", err)
+	}
+	keys := []string{subscriptionsKey(owner), constants.RedisKeySubscriptionOwners}
+	n, err := addSubscriptionScript.Run(ctx, r.client, keys, sub.ID, data, limit, owner).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to add subscription: %w", err)
+	}
+	return n == 1, nil
+}
+
+// DeleteSubscription removes one of owner's subscriptions
+func (r *RedisCache) DeleteSubscription(ctx context.Context, owner, id string) (bool, error) {
+	keys := []string{subscriptionsKey(owner), constants.RedisKeySubscriptionOwners}
+	n, err := deleteSubscriptionScript.Run(ctx, r.client, keys, id, owner).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	return n == 1, nil
+}
+
+// AllSubscriptions returns the subscriptions of every owner, by owner
+func (r *RedisCache) AllSubscriptions(ctx context.Context) (map[string][]*models.Subscription, error) {
+	owners, err := r.client.SMembers(ctx, constants.RedisKeySubscriptionOwners).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscription owners: %w", err)
+	}
+	cmds := make([]*redis.StringSliceCmd, len(owners))
+	_, err = r.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, owner := range owners {
+			cmds[i] = p.HVals(ctx, subscriptionsKey(owner))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions: %w", err)
+	}
+
+	out := make(map[string][]*models.Subscription, len(owners))
+	for i, owner := range owners {
+		if subs := decodeSubscriptions(cmds[i].Val()); len(subs) > 0 {
+			out[owner] = subs
+		}
+	}
+	return out, nil
+}
+
+func subscriptionsKey(owner string) string {
+	return constants.RedisKeySubscriptionsPrefix + owner
+}
+
+// decodeSubscriptions parses stored subscriptions, skipping unreadable ones,
+// oldest first
+func decodeSubscriptions(vals []string) []*models.Subscription {
+	out := make([]*models.Subscription, 0, len(vals))
+	for _, v := range vals {
+		var sub models.Subscription
+		if err := json.Unmarshal([]byte(v), &sub); err == nil {
+			out = append(out, &sub)
+		}
+	}
+	slices.SortFunc(out, func(a, b *models.Subscription) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return out
+}
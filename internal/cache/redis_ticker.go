@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// PublishTickers publishes each ticker as JSON on swaps:ticker:<pair>, in
+// one round trip
+func (r *RedisCache) PublishTickers(ctx context.Context, tickers []*models.Ticker) error {
+	pipe := r.client.Pipeline()
+	for _, t := range tickers {
+		data, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Errorf("failed to marshal ticker: %w", err)
+		}
+		pipe.Publish(ctx, constants.PubSubChannelTicker+t.Pair, data)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish tickers: %w", err)
+	}
+	return nil
+}
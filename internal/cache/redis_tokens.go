@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetTokenDecimals returns the saved decimals of a mint; ok is false when
+// the mint has not been resolved yet
+func (r *RedisCache) GetTokenDecimals(ctx context.Context, mint string) (decimals uint8, ok bool, err error) {
+	v, err := r.client.HGet(ctx, constants.RedisKeyTokenDecimals, mint).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get token decimals: %w", err)
+	}
+	n, err := strconv.ParseUint(v, 10, 8)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid token decimals %q for %s", v, mint)
+	}
+	return uint8(n), true, nil
+}
+
+// SetTokenDecimals saves the decimals of a mint; they never change
+func (r *RedisCache) SetTokenDecimals(ctx context.Context, mint string, decimals uint8) error {
+	if err := r.client.HSet(ctx, constants.RedisKeyTokenDecimals, mint, decimals).Err(); err != nil {
+		return fmt.Errorf("failed to set token decimals: %w", err)
+	}
+	return nil
+}
+
+// GetTokenMetadata returns a registered mint, or nil if it was never looked up
+func (r *RedisCache) GetTokenMetadata(ctx context.Context, mint string) (*models.TokenMetadata, error) {
+	v, err := r.client.HGet(ctx, constants.RedisKeyTokenMetadata, mint).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token metadata: %w", err)
+	}
+	var meta models.TokenMetadata
+	if err := json.Unmarshal(v, &meta); err != nil {
+		return nil, fmt.Errorf("invalid token metadata for %s: %w", mint, err)
+	}
+	return &meta, nil
+}
+
+// ListTokenMetadata returns every registered mint; unreadable entries are
+// skipped
+func (r *RedisCache) ListTokenMetadata(ctx context.Context) ([]*models.TokenMetadata, error) {
+	all, err := r.client.HGetAll(ctx, constants.RedisKeyTokenMetadata).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list token metadata: %w", err)
+	}
+	out := make([]*models.TokenMetadata, 0, len(all))
+	for _, v := range all {
+		var meta models.TokenMetadata
+		if err := json.Unmarshal([]byte(v), &meta); err != nil {
+			continue
+		}
+		out = append(out, &meta)
+	}
+	return out, nil
+}
+
+// SaveTokenMetadata registers a mint; the first registration wins, so every
+// process ends up with the same label
+func (r *RedisCache) SaveTokenMetadata(ctx context.Context, meta *models.TokenMetadata) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal token metadata: %w", err)
+	}
+	if err := r.client.HSetNX(ctx, constants.RedisKeyTokenMetadata, meta.Mint, data).Err(); err != nil {
+		return fmt.Errorf("failed to save token metadata: %w", err)
+	}
+	return nil
+}
+
+// ClaimRelabel claims the relabel of mint's stored swaps for this process;
+// false means another process holds or finished it. The claim expires after
+// TokenRelabelTimeout unless FinishRelabel keeps it.
+func (r *RedisCache) ClaimRelabel(ctx context.Context, mint string) (bool, error) {
+	ok, err := r.client.SetNX(ctx, constants.RedisKeyTokenRelabelPrefix+mint, "running", constants.TokenRelabelTimeout).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim token relabel: %w", err)
+	}
+	return ok, nil
+}
+
+// FinishRelabel keeps the claim on mint for good once its relabel is done,
+// or drops it so a process learning the mint later tries again
+func (r *RedisCache) FinishRelabel(ctx context.Context, mint string, done bool) error {
+	key := constants.RedisKeyTokenRelabelPrefix + mint
+	var err error
+	if done {
+		err = r.client.Set(ctx, key, "done", 0).Err()
+	} else {
+		err = r.client.Del(ctx, key).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to finish token relabel: %w", err)
+	}
+	return nil
+}
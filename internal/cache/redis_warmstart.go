@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+)
+
+// WarmStartResult reports what WarmStart wrote
+type WarmStartResult struct {
+	Skipped string // why nothing was written, empty when the cache was filled
+	Swaps   int    // swaps in the global recent list
+	Pairs   int    // pairs given a recent list
+	Prices  int    // latest prices set
+}
+
+// WarmStart fills an empty cache from src so the API has recent swaps and
+// prices to serve before the indexer has caught up: the global and per-pair
+// recent lists get the newest stored swaps of the last window, and each
+// token its last price while that is younger than the price TTL. It does
+// nothing when swaps:recent already exists or another process holds the
+// warm-start lock, so every replica may call it on startup. Prices set
+// meanwhile by the indexer are never overwritten.
+func (r *RedisCache) WarmStart(ctx context.Context, src storage.RecentSwapSource, window time.Duration) (WarmStartResult, error) {
+	ok, err := r.client.SetNX(ctx, constants.RedisKeyWarmStartLock, 1, constants.WarmStartLockTTL).Result()
+	if err != nil {
+		return WarmStartResult{}, fmt.Errorf("failed to take warm-start lock: %w", err)
+	}
+	if !ok {
+		return WarmStartResult{Skipped: "another process is warming the cache"}, nil
+	}
+	defer r.client.Del(context.WithoutCancel(ctx), constants.RedisKeyWarmStartLock)
+
+	n, err := r.client.Exists(ctx, constants.RedisKeyRecentSwaps).Result()
+	if err != nil {
+		return WarmStartResult{}, fmt.Errorf("failed to check recent swaps: %w", err)
+	}
+	if n > 0 {
+		return WarmStartResult{Skipped: "cache already holds recent swaps"}, nil
+	}
+
+	now := time.Now().UTC()
+	swaps, err := src.RecentSwapsPerPair(ctx, now.Add(-window), int(r.maxRecent))
+	if err != nil {
+		return WarmStartResult{}, err
+	}
+	plan := planWarmStart(swaps, int(r.maxRecent), r.priceTTL, now)
+	if len(plan.recent) == 0 {
+		return WarmStartResult{Skipped: "no recent swaps stored"}, nil
+	}
+
+	pipe := r.client.TxPipeline()
+	for key, list := range plan.lists() {
+		values := make([]any, len(list))
+		for i, swap := range list {
+			data, err := r.codec.Marshal(swap)
+			if err != nil {
+				return WarmStartResult{}, fmt.Errorf("failed to marshal swap: %w", err)
+			}
+			values[i] = data
+		}
+		// RPUSH keeps swaps the indexer LPUSHed meanwhile in front
+		pipe.RPush(ctx, key, values...)
+		pipe.LTrim(ctx, key, 0, r.maxRecent-1)
+	}
+	for _, p := range plan.prices {
+		data, err := json.Marshal(p.price)
+		if err != nil {
+			return WarmStartResult{}, fmt.Errorf("failed to marshal price: %w", err)
+		}
+		pipe.SetNX(ctx, constants.RedisKeyPricePrefix+p.price.Token, data, p.ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return WarmStartResult{}, fmt.Errorf("failed to warm cache: %w", err)
+	}
+	return WarmStartResult{Swaps: len(plan.recent), Pairs: len(plan.pairs), Prices: len(plan.prices)}, nil
+}
+
+// warmStartPlan is what WarmStart writes, every list newest first
+type warmStartPlan struct {
+	recent []*models.SwapEvent            // the global list
+	pairs  map[string][]*models.SwapEvent // by pair
+	prices []warmPrice
+}
+
+type warmPrice struct {
+	price models.TokenPrice
+	ttl   time.Duration // what is left of the price TTL
+}
+
+// lists returns the plan's recent lists by Redis key
+func (p warmStartPlan) lists() map[string][]*models.SwapEvent {
+	out := make(map[string][]*models.SwapEvent, len(p.pairs)+1)
+	out[constants.RedisKeyRecentSwaps] = p.recent
+	for pair, list := range p.pairs {
+		out[recentPairKey(pair)] = list
+	}
+	return out
+}
+
+// planWarmStart orders swaps newest first into the global list, capped at
+// maxRecent, and per-pair lists, and takes each output token's last price
+// unless it is older than priceTTL
+func planWarmStart(swaps []*models.SwapEvent, maxRecent int, priceTTL time.Duration, now time.Time) warmStartPlan {
+	sorted := slices.Clone(swaps)
+	slices.SortStableFunc(sorted, func(a, b *models.SwapEvent) int {
+		return cmp.Or(b.Timestamp.Compare(a.Timestamp), cmp.Compare(b.Signature, a.Signature))
+	})
+
+	plan := warmStartPlan{
+		recent: sorted[:min(len(sorted), maxRecent)],
+		pairs:  make(map[string][]*models.SwapEvent),
+	}
+	seen := make(map[string]bool)
+	for _, swap := range sorted {
+		if len(plan.pairs[swap.Pair]) < maxRecent {
+			plan.pairs[swap.Pair] = append(plan.pairs[swap.Pair], swap)
+		}
+		if swap.TokenOut == "" || seen[swap.TokenOut] {
+			continue
+		}
+		seen[swap.TokenOut] = true
+		updated := swap.IndexedAt
+		if updated.IsZero() {
+			updated = swap.Timestamp
+		}
+		ttl := priceTTL - now.Sub(updated)
+		if ttl <= 0 {
+			continue
+		}
+		plan.prices = append(plan.prices, warmPrice{
+			price: models.TokenPrice{Token: swap.TokenOut, Price: swap.Price, UpdatedAt: updated.UTC()},
+			ttl:   ttl,
+		})
+	}
+	return plan
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ storage.RecentSwapSource = (*ClickHouseStore)(nil)
+
+func TestPlanWarmStart(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(sig, pair, out string, ago time.Duration, price float64) *models.SwapEvent {
+		return &models.SwapEvent{Signature: sig, Pair: pair, TokenOut: out, Price: price, Timestamp: now.Add(-ago)}
+	}
+	swaps := []*models.SwapEvent{
+		at("a1", "SOL/USDC", "USDC", 3*time.Minute, 1),
+		at("b1", "BONK/SOL", "SOL", 10*time.Minute, 0.00002),
+		at("a3", "SOL/USDC", "USDC", time.Minute, 3),
+		at("a2", "SOL/USDC", "USDC", 2*time.Minute, 2),
+		at("c1", "JUP/USDT", "USDT", 2*time.Hour, 1), // its price has expired
+	}
+	swaps[2].IndexedAt = now.Add(-30 * time.Second)
+
+	plan := planWarmStart(swaps, 3, time.Hour, now)
+	sigs := func(list []*models.SwapEvent) (out []string) {
+		for _, s := range list {
+			out = append(out, s.Signature)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"a3", "a2", "a1"}, sigs(plan.recent), "newest first, capped")
+	assert.Equal(t, []string{"a3", "a2", "a1"}, sigs(plan.pairs["SOL/USDC"]))
+	assert.Equal(t, []string{"b1"}, sigs(plan.pairs["BONK/SOL"]))
+	assert.Equal(t, []string{"c1"}, sigs(plan.pairs["JUP/USDT"]))
+
+	lists := plan.lists()
+	assert.Len(t, lists, 4)
+	assert.Contains(t, lists, constants.RedisKeyRecentSwaps)
+	assert.Contains(t, lists, recentPairKey("bonk/sol"))
+
+	require.Len(t, plan.prices, 2)
+	usdc, sol := plan.prices[0], plan.prices[1]
+	assert.Equal(t, models.TokenPrice{Token: "USDC", Price: 3, UpdatedAt: now.Add(-30 * time.Second)}, usdc.price, "from the newest swap, as of indexing")
+	assert.Equal(t, time.Hour-30*time.Second, usdc.ttl)
+	assert.Equal(t, "SOL", sol.price.Token)
+	assert.Equal(t, 50*time.Minute, sol.ttl)
+
+	assert.Empty(t, planWarmStart(nil, 3, time.Hour, now).recent)
+}
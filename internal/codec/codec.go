@@ -0,0 +1,116 @@
+// Package codec encodes swap events for Redis storage and pub/sub.
+//
+// JSON payloads are written as-is, as they always have been, except on
+// Pub/Sub, where MarshalEvent wraps them in a versioned Envelope. Binary
+// payloads are wrapped in a small versioned envelope:
+//
+//	0xC1 | version | format | payload
+//
+// 0xC1 is never a valid first byte of JSON, MessagePack or a protobuf
+// SwapEvent (whose first field key is at most 0x9A), so readers can decode
+// any kind without knowing which encoding the writer used. That
+// lets writers switch encodings while older entries are still in Redis.
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// Encoding names accepted by New
+const (
+	EncodingJSON     = constants.SwapEncodingJSON
+	EncodingMsgpack  = constants.SwapEncodingMsgpack
+	EncodingProtobuf = constants.SwapEncodingProtobuf // proto/swapindexer/v1/swap.proto
+)
+
+const (
+	envelopeMarker  = 0xC1
+	envelopeVersion = 1
+	formatMsgpack   = 'm'
+	formatProtobuf  = 'p'
+)
+
+var ErrUnknownEncoding = errors.New("unknown swap encoding")
+
+// SwapCodec encodes swap events in one format and decodes any supported one
+type SwapCodec struct {
+	format byte // envelope format; 0 writes plain JSON
+}
+
+// New returns a codec that writes the given encoding; empty means JSON
+func New(encoding string) (*SwapCodec, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", EncodingJSON:
+		return &SwapCodec{}, nil
+	case EncodingMsgpack:
+		return &SwapCodec{format: formatMsgpack}, nil
+	case EncodingProtobuf:
+		return &SwapCodec{format: formatProtobuf}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q (want json, msgpack or protobuf)", ErrUnknownEncoding, encoding)
+	}
+}
+
+// Name returns the encoding the codec writes
+func (c *SwapCodec) Name() string {
+	switch c.format {
+	case formatMsgpack:
+		return EncodingMsgpack
+	case formatProtobuf:
+		return EncodingProtobuf
+	default:
+		return EncodingJSON
+	}
+}
+
+// Marshal encodes a swap event
+func (c *SwapCodec) Marshal(swap *models.SwapEvent) ([]byte, error) {
+	if c.format == 0 {
+		return json.Marshal(swap)
+	}
+	buf := make([]byte, 3, 256)
+	buf[0], buf[1], buf[2] = envelopeMarker, envelopeVersion, c.format
+	if c.format == formatProtobuf {
+		return appendSwapProtobuf(buf, swap)
+	}
+	return appendSwapMsgpack(buf, swap), nil
+}
+
+// Unmarshal decodes a swap event written by any codec
+func (c *SwapCodec) Unmarshal(data []byte, swap *models.SwapEvent) error {
+	return Decode(data, swap)
+}
+
+// Decode decodes a swap event: bare or enveloped JSON, or enveloped binary
+func Decode(data []byte, swap *models.SwapEvent) error {
+	if len(data) == 0 || data[0] != envelopeMarker {
+		payload, ok, err := unwrapEnvelope(data)
+		if err != nil {
+			return err
+		}
+		if ok {
+			data = payload
+		}
+		return json.Unmarshal(data, swap)
+	}
+	if len(data) < 3 {
+		return fmt.Errorf("swap envelope truncated")
+	}
+	if data[1] != envelopeVersion {
+		return fmt.Errorf("unsupported swap envelope version %d", data[1])
+	}
+	switch data[2] {
+	case formatMsgpack:
+		return decodeSwapMsgpack(data[3:], swap)
+	case formatProtobuf:
+		return decodeSwapProtobuf(data[3:], swap)
+	default:
+		return fmt.Errorf("unsupported swap envelope format %q", data[2])
+	}
+}
@@ -0,0 +1,148 @@
+package codec
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSwap() *models.SwapEvent {
+	return &models.SwapEvent{
+		Signature: "5VERv8NMvzbJMEkV8xnrLkEaWRtSz9CosKDYjCJjBRnbJLgp8uirBgmQpjKhoR4tjF3ZpRzrFmBV6UjKdiSZkQUW",
+		Timestamp: time.Date(2025, 3, 1, 12, 30, 45, 123456789, time.UTC),
+		Pair:      "SOL/USDC",
+		TokenIn:   "SOL",
+		TokenOut:  "USDC",
+		AmountIn:  1.5,
+		AmountOut: 210.25,
+		Price:     140.1666,
+		Fee:       0.003,
+		Pool:      "OrcaWhirlpool",
+		Dex:       "Orca",
+
+		Slot:         324567890,
+		BlockTime:    1740832245,
+		AmountInRaw:  1500000000,
+		AmountOutRaw: 210250000,
+		DecimalsIn:   9,
+		DecimalsOut:  6,
+		ProgramID:    "whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc",
+		PoolAddress:  "Czfq3xZZDmsdGdUyrNLtRhGc47cXcZtLG4crryfu44zE",
+		Wallet:       "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
+
+		FeeLamports:      105000,
+		PriorityFee:      100000,
+		ComputeUnitPrice: 500000,
+
+		IndexedAt: time.Date(2025, 3, 1, 12, 30, 46, 250000000, time.UTC),
+		Finalized: true,
+		ValueUSD:  210.25,
+	}
+}
+
+func TestMsgpack_RoundTrip(t *testing.T) {
+	c, err := New(EncodingMsgpack)
+	require.NoError(t, err)
+
+	data, err := c.Marshal(testSwap())
+	require.NoError(t, err)
+	assert.Equal(t, byte(envelopeMarker), data[0])
+
+	js, _ := json.Marshal(testSwap())
+	assert.Less(t, len(data), len(js))
+
+	var got models.SwapEvent
+	require.NoError(t, c.Unmarshal(data, &got))
+	assert.Equal(t, *testSwap(), got)
+}
+
+func TestProtobuf_RoundTrip(t *testing.T) {
+	c, err := New(EncodingProtobuf)
+	require.NoError(t, err)
+	assert.Equal(t, EncodingProtobuf, c.Name())
+
+	data, err := c.Marshal(testSwap())
+	require.NoError(t, err)
+	assert.Equal(t, []byte{envelopeMarker, envelopeVersion, formatProtobuf}, data[:3])
+
+	var got models.SwapEvent
+	require.NoError(t, Decode(data, &got))
+	assert.Equal(t, *testSwap(), got)
+}
+
+func TestProtobuf_WireFormat(t *testing.T) {
+	// field 1 "ab", field 6 (amount_in) = 1.0, field 12 (slot) = 300, and
+	// an unknown field 99 that must be skipped
+	data := []byte{
+		0x0a, 0x02, 'a', 'b',
+		0x31, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f,
+		0x60, 0xac, 0x02,
+		0x98, 0x06, 0x01,
+	}
+	var got models.SwapEvent
+	require.NoError(t, decodeSwapProtobuf(data, &got))
+	assert.Equal(t, models.SwapEvent{Signature: "ab", Slot: 300, AmountIn: 1}, got)
+
+	again, err := appendSwapProtobuf(nil, &got)
+	require.NoError(t, err)
+	assert.Equal(t, data[:16], again)
+	assert.Error(t, decodeSwapProtobuf([]byte{0x0a, 0x05, 'a'}, &got))
+}
+
+func TestDecode_AcceptsEitherEncoding(t *testing.T) {
+	jsonCodec, err := New("")
+	require.NoError(t, err)
+	assert.Equal(t, EncodingJSON, jsonCodec.Name())
+
+	data, err := jsonCodec.Marshal(testSwap())
+	require.NoError(t, err)
+
+	var got models.SwapEvent
+	require.NoError(t, Decode(data, &got))
+	assert.Equal(t, testSwap().Signature, got.Signature)
+	assert.True(t, testSwap().Timestamp.Equal(got.Timestamp))
+}
+
+func TestDecode_RejectsUnknownEnvelope(t *testing.T) {
+	var got models.SwapEvent
+	assert.Error(t, Decode([]byte{envelopeMarker, 99, formatMsgpack}, &got))
+	assert.Error(t, Decode([]byte{envelopeMarker, envelopeVersion, 'x'}, &got))
+	assert.Error(t, Decode([]byte{envelopeMarker, envelopeVersion, formatMsgpack, 0x81}, &got))
+}
+
+func TestNew_UnknownEncoding(t *testing.T) {
+	_, err := New("xml")
+	assert.ErrorIs(t, err, ErrUnknownEncoding)
+}
+
+func TestMarshalEvent_Envelope(t *testing.T) {
+	jsonCodec, err := New(EncodingJSON)
+	require.NoError(t, err)
+
+	data, err := jsonCodec.MarshalEvent(testSwap())
+	require.NoError(t, err)
+	var env Envelope
+	require.NoError(t, json.Unmarshal(data, &env))
+	assert.Equal(t, SwapSchemaVersion, env.SchemaVersion)
+	assert.Equal(t, EventTypeSwap, env.EventType)
+
+	var got models.SwapEvent
+	require.NoError(t, Decode(data, &got))
+	assert.Equal(t, testSwap().Signature, got.Signature)
+	assert.Equal(t, testSwap().AmountOutRaw, got.AmountOutRaw)
+
+	newer := []byte(`{"schema_version":2,"event_type":"swap","payload":{"signature":"x"}}`)
+	assert.ErrorIs(t, Decode(newer, &got), ErrUnsupportedSchema)
+	assert.Error(t, Decode([]byte(`{"schema_version":1,"event_type":"ticker","payload":{}}`), &got))
+
+	msgpackCodec, err := New(EncodingMsgpack)
+	require.NoError(t, err)
+	data, err = msgpackCodec.MarshalEvent(testSwap())
+	require.NoError(t, err)
+	assert.Equal(t, byte(envelopeMarker), data[0], "binary encodings keep their own envelope")
+}
@@ -0,0 +1,64 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// SwapSchemaVersion is the version of the SwapEvent shape in published
+// envelopes. It is bumped when a field changes meaning or is removed; new
+// fields are added without a bump.
+const SwapSchemaVersion = 1
+
+// EventTypeSwap is the event type of a published swap
+const EventTypeSwap = "swap"
+
+// ErrUnsupportedSchema is returned for an envelope newer than this build reads
+var ErrUnsupportedSchema = errors.New("unsupported event schema version")
+
+// Envelope wraps JSON events published on Pub/Sub, so subscribers can tell
+// which shape the payload has before decoding it
+type Envelope struct {
+	SchemaVersion int             `json:"schema_version"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// MarshalEvent encodes a swap for publishing. JSON is wrapped in an Envelope;
+// binary encodings already carry their own versioned envelope.
+func (c *SwapCodec) MarshalEvent(swap *models.SwapEvent) ([]byte, error) {
+	if c.format != 0 {
+		return c.Marshal(swap)
+	}
+	payload, err := json.Marshal(swap)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Envelope{SchemaVersion: SwapSchemaVersion, EventType: EventTypeSwap, Payload: payload})
+}
+
+// unwrapEnvelope returns the swap payload of a JSON envelope; ok is false
+// for a bare SwapEvent, as published before envelopes
+func unwrapEnvelope(data []byte) (payload []byte, ok bool, err error) {
+	if !bytes.Contains(data, []byte(`"schema_version"`)) {
+		return nil, false, nil
+	}
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, false, err
+	}
+	if env.SchemaVersion == 0 || len(env.Payload) == 0 {
+		return nil, false, nil
+	}
+	if env.SchemaVersion > SwapSchemaVersion {
+		return nil, true, fmt.Errorf("%w: %d (this build reads up to %d)", ErrUnsupportedSchema, env.SchemaVersion, SwapSchemaVersion)
+	}
+	if env.EventType != EventTypeSwap {
+		return nil, true, fmt.Errorf("event type %q is not a swap", env.EventType)
+	}
+	return env.Payload, true, nil
+}
@@ -0,0 +1,380 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// A minimal MessagePack writer and reader for SwapEvent. The event is a map
+// keyed by its JSON field names, so any msgpack library can read it, and
+// unknown keys are skipped on decode so fields can be added later.
+
+// msgpack type bytes used below
+const (
+	mpNil     = 0xc0
+	mpFalse   = 0xc2
+	mpTrue    = 0xc3
+	mpExt8    = 0xc7
+	mpFloat32 = 0xca
+	mpFloat64 = 0xcb
+	mpUint8   = 0xcc
+	mpUint16  = 0xcd
+	mpUint32  = 0xce
+	mpUint64  = 0xcf
+	mpInt8    = 0xd0
+	mpInt16   = 0xd1
+	mpInt32   = 0xd2
+	mpInt64   = 0xd3
+	mpFixExt4 = 0xd6
+	mpFixExt8 = 0xd7
+	mpStr8    = 0xd9
+	mpStr16   = 0xda
+	mpStr32   = 0xdb
+	mpMap16   = 0xde
+	mpMap32   = 0xdf
+
+	extTimestamp = 0xff // -1
+)
+
+func appendSwapMsgpack(b []byte, s *models.SwapEvent) []byte {
+	b = append(b, mpMap16, 0, 26) // 26 entries
+	b = appendStr(appendStr(b, "signature"), s.Signature)
+	b = appendTime(appendStr(b, "timestamp"), s.Timestamp)
+	b = appendStr(appendStr(b, "pair"), s.Pair)
+	b = appendStr(appendStr(b, "token_in"), s.TokenIn)
+	b = appendStr(appendStr(b, "token_out"), s.TokenOut)
+	b = appendFloat(appendStr(b, "amount_in"), s.AmountIn)
+	b = appendFloat(appendStr(b, "amount_out"), s.AmountOut)
+	b = appendFloat(appendStr(b, "price"), s.Price)
+	b = appendFloat(appendStr(b, "fee"), s.Fee)
+	b = appendStr(appendStr(b, "pool"), s.Pool)
+	b = appendStr(appendStr(b, "dex"), s.Dex)
+	b = appendUint(appendStr(b, "slot"), s.Slot)
+	b = appendUint(appendStr(b, "block_time"), uint64(s.BlockTime))
+	b = appendUint(appendStr(b, "amount_in_raw"), s.AmountInRaw)
+	b = appendUint(appendStr(b, "amount_out_raw"), s.AmountOutRaw)
+	b = appendUint(appendStr(b, "decimals_in"), uint64(s.DecimalsIn))
+	b = appendUint(appendStr(b, "decimals_out"), uint64(s.DecimalsOut))
+	b = appendStr(appendStr(b, "program_id"), s.ProgramID)
+	b = appendStr(appendStr(b, "pool_address"), s.PoolAddress)
+	b = appendStr(appendStr(b, "wallet"), s.Wallet)
+	b = appendUint(appendStr(b, "fee_lamports"), s.FeeLamports)
+	b = appendUint(appendStr(b, "priority_fee"), s.PriorityFee)
+	b = appendUint(appendStr(b, "compute_unit_price"), s.ComputeUnitPrice)
+	b = appendTime(appendStr(b, "indexed_at"), s.IndexedAt)
+	b = appendBool(appendStr(b, "finalized"), s.Finalized)
+	b = appendFloat(appendStr(b, "value_usd"), s.ValueUSD)
+	return b
+}
+
+func appendStr(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, mpStr8, byte(n))
+	case n <= math.MaxUint16:
+		b = append(b, mpStr16)
+		b = binary.BigEndian.AppendUint16(b, uint16(n))
+	default:
+		b = append(b, mpStr32)
+		b = binary.BigEndian.AppendUint32(b, uint32(n))
+	}
+	return append(b, s...)
+}
+
+// appendUint writes v in the smallest unsigned form that holds it
+func appendUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, mpUint8, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, mpUint16), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, mpUint32), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, mpUint64), v)
+	}
+}
+
+func appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, mpTrue)
+	}
+	return append(b, mpFalse)
+}
+
+func appendFloat(b []byte, f float64) []byte {
+	b = append(b, mpFloat64)
+	return binary.BigEndian.AppendUint64(b, math.Float64bits(f))
+}
+
+// appendTime writes the msgpack timestamp extension in its 96-bit form
+func appendTime(b []byte, t time.Time) []byte {
+	b = append(b, mpExt8, 12, extTimestamp)
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+	return binary.BigEndian.AppendUint64(b, uint64(t.Unix()))
+}
+
+// reader walks a msgpack buffer
+type reader struct {
+	b   []byte
+	off int
+}
+
+func (r *reader) next(n int) ([]byte, error) {
+	if n < 0 || r.off+n > len(r.b) {
+		return nil, fmt.Errorf("msgpack: unexpected end of data")
+	}
+	p := r.b[r.off : r.off+n]
+	r.off += n
+	return p, nil
+}
+
+func (r *reader) byte() (byte, error) {
+	p, err := r.next(1)
+	if err != nil {
+		return 0, err
+	}
+	return p[0], nil
+}
+
+func (r *reader) uint(n int) (uint64, error) {
+	p, err := r.next(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range p {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// value decodes the next value into a string, float64, int64, bool,
+// time.Time or nil
+func (r *reader) value() (any, error) {
+	t, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case t <= 0x7f:
+		return int64(t), nil
+	case t >= 0xe0:
+		return int64(int8(t)), nil
+	case t&0xe0 == 0xa0:
+		return r.str(int(t & 0x1f))
+	case t&0xf0 == 0x80 || t&0xf0 == 0x90:
+		return nil, fmt.Errorf("msgpack: nested containers are not supported")
+	}
+
+	switch t {
+	case mpNil:
+		return nil, nil
+	case mpFalse:
+		return false, nil
+	case mpTrue:
+		return true, nil
+	case mpFloat32:
+		v, err := r.uint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case mpFloat64:
+		v, err := r.uint(8)
+		return math.Float64frombits(v), err
+	case mpUint8, mpUint16, mpUint32, mpUint64:
+		v, err := r.uint(1 << (t - mpUint8))
+		return int64(v), err
+	case mpInt8:
+		v, err := r.uint(1)
+		return int64(int8(v)), err
+	case mpInt16:
+		v, err := r.uint(2)
+		return int64(int16(v)), err
+	case mpInt32:
+		v, err := r.uint(4)
+		return int64(int32(v)), err
+	case mpInt64:
+		v, err := r.uint(8)
+		return int64(v), err
+	case mpStr8, mpStr16, mpStr32:
+		n, err := r.uint(1 << (t - mpStr8))
+		if err != nil {
+			return nil, err
+		}
+		return r.str(int(n))
+	case mpFixExt4:
+		return r.timestamp(4)
+	case mpFixExt8:
+		return r.timestamp(8)
+	case mpExt8:
+		n, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		return r.timestamp(int(n))
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported type 0x%02x", t)
+	}
+}
+
+func (r *reader) str(n int) (string, error) {
+	p, err := r.next(n)
+	return string(p), err
+}
+
+// timestamp reads the body of a timestamp extension of the given length
+func (r *reader) timestamp(n int) (time.Time, error) {
+	typ, err := r.byte()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if typ != extTimestamp {
+		return time.Time{}, fmt.Errorf("msgpack: unsupported extension type %d", int8(typ))
+	}
+	switch n {
+	case 4:
+		sec, err := r.uint(4)
+		return time.Unix(int64(sec), 0).UTC(), err
+	case 8:
+		v, err := r.uint(8)
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)).UTC(), err
+	case 12:
+		nsec, err := r.uint(4)
+		if err != nil {
+			return time.Time{}, err
+		}
+		sec, err := r.uint(8)
+		return time.Unix(int64(sec), int64(nsec)).UTC(), err
+	default:
+		return time.Time{}, fmt.Errorf("msgpack: invalid timestamp length %d", n)
+	}
+}
+
+func (r *reader) mapLen() (int, error) {
+	t, err := r.byte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case t&0xf0 == 0x80:
+		return int(t & 0x0f), nil
+	case t == mpMap16:
+		n, err := r.uint(2)
+		return int(n), err
+	case t == mpMap32:
+		n, err := r.uint(4)
+		return int(n), err
+	default:
+		return 0, fmt.Errorf("msgpack: expected map, got 0x%02x", t)
+	}
+}
+
+func decodeSwapMsgpack(data []byte, s *models.SwapEvent) error {
+	r := &reader{b: data}
+	n, err := r.mapLen()
+	if err != nil {
+		return err
+	}
+
+	*s = models.SwapEvent{}
+	for range n {
+		k, err := r.value()
+		if err != nil {
+			return err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return fmt.Errorf("msgpack: non-string map key")
+		}
+		v, err := r.value()
+		if err != nil {
+			return fmt.Errorf("msgpack: %s: %w", key, err)
+		}
+
+		switch key {
+		case "signature":
+			s.Signature = asString(v)
+		case "timestamp":
+			s.Timestamp, _ = v.(time.Time)
+		case "pair":
+			s.Pair = asString(v)
+		case "token_in":
+			s.TokenIn = asString(v)
+		case "token_out":
+			s.TokenOut = asString(v)
+		case "amount_in":
+			s.AmountIn = asFloat(v)
+		case "amount_out":
+			s.AmountOut = asFloat(v)
+		case "price":
+			s.Price = asFloat(v)
+		case "fee":
+			s.Fee = asFloat(v)
+		case "pool":
+			s.Pool = asString(v)
+		case "dex":
+			s.Dex = asString(v)
+		case "slot":
+			s.Slot = asUint(v)
+		case "block_time":
+			s.BlockTime = int64(asUint(v))
+		case "amount_in_raw":
+			s.AmountInRaw = asUint(v)
+		case "amount_out_raw":
+			s.AmountOutRaw = asUint(v)
+		case "decimals_in":
+			s.DecimalsIn = uint8(asUint(v))
+		case "decimals_out":
+			s.DecimalsOut = uint8(asUint(v))
+		case "program_id":
+			s.ProgramID = asString(v)
+		case "pool_address":
+			s.PoolAddress = asString(v)
+		case "wallet":
+			s.Wallet = asString(v)
+		case "fee_lamports":
+			s.FeeLamports = asUint(v)
+		case "priority_fee":
+			s.PriorityFee = asUint(v)
+		case "compute_unit_price":
+			s.ComputeUnitPrice = asUint(v)
+		case "indexed_at":
+			s.IndexedAt, _ = v.(time.Time)
+		case "finalized":
+			s.Finalized, _ = v.(bool)
+		case "value_usd":
+			s.ValueUSD = asFloat(v)
+		}
+	}
+	return nil
+}
+
+func asString(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// asUint returns an integer value; uint64s above MaxInt64 come back intact
+// because value() only reinterprets their bits
+func asUint(v any) uint64 {
+	n, _ := v.(int64)
+	return uint64(n)
+}
+
+func asFloat(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package codec
+
+import (
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	swapindexerv1 "github.com/aman-zulfiqar/solana-swap-indexer/proto/swapindexer/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SwapEvent in the wire format of proto/swapindexer/v1/swap.proto, through
+// the generated bindings. Unknown fields are skipped on decode so the schema
+// can grow.
+
+func appendSwapProtobuf(b []byte, s *models.SwapEvent) ([]byte, error) {
+	return proto.MarshalOptions{}.MarshalAppend(b, SwapToProto(s))
+}
+
+func decodeSwapProtobuf(data []byte, s *models.SwapEvent) error {
+	var msg swapindexerv1.SwapEvent
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	*s = *SwapFromProto(&msg)
+	return nil
+}
+
+// SwapToProto converts a swap to its protobuf message
+func SwapToProto(s *models.SwapEvent) *swapindexerv1.SwapEvent {
+	return &swapindexerv1.SwapEvent{
+		Signature:        s.Signature,
+		Timestamp:        TimestampToProto(s.Timestamp),
+		Pair:             s.Pair,
+		TokenIn:          s.TokenIn,
+		TokenOut:         s.TokenOut,
+		AmountIn:         s.AmountIn,
+		AmountOut:        s.AmountOut,
+		Price:            s.Price,
+		Fee:              s.Fee,
+		Pool:             s.Pool,
+		Dex:              s.Dex,
+		Slot:             s.Slot,
+		BlockTime:        s.BlockTime,
+		AmountInRaw:      s.AmountInRaw,
+		AmountOutRaw:     s.AmountOutRaw,
+		DecimalsIn:       uint32(s.DecimalsIn),
+		DecimalsOut:      uint32(s.DecimalsOut),
+		ProgramId:        s.ProgramID,
+		PoolAddress:      s.PoolAddress,
+		Wallet:           s.Wallet,
+		FeeLamports:      s.FeeLamports,
+		PriorityFee:      s.PriorityFee,
+		ComputeUnitPrice: s.ComputeUnitPrice,
+		IndexedAt:        TimestampToProto(s.IndexedAt),
+		Finalized:        s.Finalized,
+		ValueUsd:         s.ValueUSD,
+	}
+}
+
+// SwapFromProto converts a protobuf message back to a swap
+func SwapFromProto(m *swapindexerv1.SwapEvent) *models.SwapEvent {
+	return &models.SwapEvent{
+		Signature:        m.GetSignature(),
+		Timestamp:        TimestampFromProto(m.GetTimestamp()),
+		Pair:             m.GetPair(),
+		TokenIn:          m.GetTokenIn(),
+		TokenOut:         m.GetTokenOut(),
+		AmountIn:         m.GetAmountIn(),
+		AmountOut:        m.GetAmountOut(),
+		Price:            m.GetPrice(),
+		Fee:              m.GetFee(),
+		Pool:             m.GetPool(),
+		Dex:              m.GetDex(),
+		Slot:             m.GetSlot(),
+		BlockTime:        m.GetBlockTime(),
+		AmountInRaw:      m.GetAmountInRaw(),
+		AmountOutRaw:     m.GetAmountOutRaw(),
+		DecimalsIn:       uint8(m.GetDecimalsIn()),
+		DecimalsOut:      uint8(m.GetDecimalsOut()),
+		ProgramID:        m.GetProgramId(),
+		PoolAddress:      m.GetPoolAddress(),
+		Wallet:           m.GetWallet(),
+		FeeLamports:      m.GetFeeLamports(),
+		PriorityFee:      m.GetPriorityFee(),
+		ComputeUnitPrice: m.GetComputeUnitPrice(),
+		IndexedAt:        TimestampFromProto(m.GetIndexedAt()),
+		Finalized:        m.GetFinalized(),
+		ValueUSD:         m.GetValueUsd(),
+	}
+}
+
+// TimestampToProto converts t to a google.protobuf.Timestamp; the zero time
+// is left unset
+func TimestampToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// TimestampFromProto converts a google.protobuf.Timestamp to UTC time; an
+// unset one is the zero time
+func TimestampFromProto(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
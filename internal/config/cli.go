@@ -0,0 +1,126 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SourceFlag marks a setting given as a command-line flag
+const SourceFlag = "flag"
+
+// overrideFlags are the settings every binary accepts as a flag, by flag name
+var overrideFlags = []struct{ name, env, usage string }{
+	{"rpc-url", "SOLANA_RPC_URL", "Solana RPC endpoint"},
+	{"redis-addr", "REDIS_ADDR", "Redis address, host:port"},
+	{"redis-db", "REDIS_DB", "Redis database number"},
+	{"clickhouse-addr", "CLICKHOUSE_ADDR", "ClickHouse address, host:port"},
+	{"clickhouse-database", "CLICKHOUSE_DATABASE", "ClickHouse database"},
+	{"api-addr", "API_ADDR", "HTTP API listen address"},
+	{"metrics-addr", "METRICS_ADDR", "Prometheus /metrics listen address"},
+	{"app-env", "APP_ENV", "profile: dev, staging or prod"},
+	{"log-level", "LOG_LEVEL", "debug, info, warn or error"},
+	{"log-format", "LOG_FORMAT", "text or json"},
+}
+
+// Overrides are command-line flags that override the environment and the
+// config file (CLI > env > config file > profile). Bind them to a flag set,
+// then call Apply after parsing and before LoadFile.
+type Overrides struct {
+	flags map[string]*overrideValue // env var -> flag value
+	set   []string                  // KEY=VALUE pairs from -set
+	dev   overrideValue
+}
+
+// overrideValue is a string flag that remembers whether it was given
+type overrideValue struct {
+	val    string
+	given  bool
+	isBool bool
+}
+
+func (v *overrideValue) String() string { return v.val }
+
+func (v *overrideValue) Set(s string) error {
+	v.val, v.given = s, true
+	return nil
+}
+
+// IsBoolFlag lets -dev be given without a value
+func (v *overrideValue) IsBoolFlag() bool { return v.isBool }
+
+// Type names the value in pflag help, for the ssi command
+func (v *overrideValue) Type() string {
+	if v.isBool {
+		return "bool"
+	}
+	return "string"
+}
+
+// setFlag collects repeated -set KEY=VALUE flags
+type setFlag struct{ pairs *[]string }
+
+func (f setFlag) String() string {
+	if f.pairs == nil {
+		return ""
+	}
+	return strings.Join(*f.pairs, ",")
+}
+
+func (f setFlag) Type() string { return "stringArray" }
+
+func (f setFlag) Set(s string) error {
+	if key, _, ok := strings.Cut(s, "="); !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("want KEY=VALUE, got %q", s)
+	}
+	*f.pairs = append(*f.pairs, s)
+	return nil
+}
+
+// BindFlags registers the override flags on fs: -rpc-url, -redis-addr, the
+// other addresses, -app-env, -log-level, -dev and a repeatable -set
+// KEY=VALUE for any other setting
+func BindFlags(fs *flag.FlagSet) *Overrides {
+	o := &Overrides{flags: make(map[string]*overrideValue, len(overrideFlags))}
+	for _, f := range overrideFlags {
+		v := &overrideValue{}
+		o.flags[f.env] = v
+		fs.Var(v, f.name, f.usage+" (overrides "+f.env+")")
+	}
+	o.dev = overrideValue{isBool: true}
+	fs.Var(&o.dev, "dev", "development mode (overrides DEV)")
+	fs.Var(setFlag{&o.set}, "set", "override any setting as `KEY=VALUE` of its environment variable; repeatable")
+	return o
+}
+
+// Apply exports the flags that were given into the environment, where they
+// win over .env and the config file. Flags left unset change nothing.
+func (o *Overrides) Apply() error {
+	vals := map[string]string{}
+	for _, kv := range o.set {
+		key, val, _ := strings.Cut(kv, "=")
+		vals[strings.TrimSpace(key)] = val
+	}
+	for key, v := range o.flags {
+		if v.given {
+			vals[key] = v.val
+		}
+	}
+	if o.dev.given {
+		vals["DEV"] = o.dev.val
+	}
+
+	fileOwnedMu.Lock()
+	defer fileOwnedMu.Unlock()
+	for key, val := range vals {
+		if err := os.Setenv(key, val); err != nil {
+			return fmt.Errorf("set %s from flag: %w", key, err)
+		}
+		// a flag replaces any value the config file set earlier
+		delete(fileOwned, key)
+		delete(fileSource, key)
+		flagOwned[key] = val
+	}
+	return nil
+}
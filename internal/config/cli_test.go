@@ -0,0 +1,51 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverrides_WinOverEnvAndFile(t *testing.T) {
+	path := writeConfigFile(t, `
+redis:
+  addr: file-redis:6379
+rpc:
+  poll_interval: 45s
+`)
+	unsetEnv(t, "CONFIG_FILE", "POLL_INTERVAL", "DEV", "INDEXER_SINKS", "REDIS_DB")
+	t.Setenv("REDIS_ADDR", "env-redis:6379")
+	forgetLoaded(t)
+	t.Cleanup(func() {
+		fileOwnedMu.Lock()
+		defer fileOwnedMu.Unlock()
+		clear(flagOwned)
+	})
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	o := BindFlags(fs)
+	require.NoError(t, fs.Parse([]string{
+		"-redis-addr", "flag-redis:6379", "-dev", "-set", "POLL_INTERVAL=5s", "-set", "INDEXER_SINKS=clickhouse,webhook",
+	}))
+	require.NoError(t, o.Apply())
+	require.NoError(t, LoadFile(path))
+
+	assert.Equal(t, "flag-redis:6379", os.Getenv("REDIS_ADDR"), "flag must win over env")
+	assert.Equal(t, "5s", os.Getenv("POLL_INTERVAL"), "flag must win over the config file")
+	assert.Equal(t, "true", os.Getenv("DEV"))
+	assert.Equal(t, "clickhouse,webhook", os.Getenv("INDEXER_SINKS"))
+	_, set := os.LookupEnv("REDIS_DB")
+	assert.False(t, set, "flags not given change nothing")
+
+	sources := map[string]string{}
+	for _, s := range Effective() {
+		sources[s.Key] = s.Source
+	}
+	assert.Equal(t, SourceFlag, sources["REDIS_ADDR"])
+	assert.Equal(t, SourceFlag, sources["POLL_INTERVAL"])
+
+	assert.Error(t, fs.Parse([]string{"-set", "no-equals-sign"}))
+}
@@ -6,21 +6,37 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
 )
 
+// DefaultAIModel is the OpenRouter model used when AI_MODEL is not set
+const DefaultAIModel = "openai/gpt-4.1-mini"
+
+// DefaultAIRouterModel classifies /v1/ai/ask questions when AI_ROUTER_MODEL is not set
+const DefaultAIRouterModel = "openai/gpt-4.1-nano"
+
 type Config struct {
+	// Environment profile (APP_ENV); empty when none is selected
+	AppEnv string
+
 	// RPC settings
 	RPCUrl       string
 	PollInterval time.Duration
 
 	// Redis settings
-	RedisAddr string
+	RedisAddr     string
+	RedisUsername string // ACL user (Redis 6+)
+	RedisPassword string
+	RedisDB       int
+	RedisTLS      bool
 
 	// ClickHouse settings
-	ClickHouseAddr     string
-	ClickHouseDatabase string
-	ClickHouseUsername string
-	ClickHousePassword string
+	ClickHouseAddr       string
+	ClickHouseDatabase   string
+	ClickHouseUsername   string
+	ClickHousePassword   string
+	ClickHouseSlowInsert time.Duration // log inserts taking at least this long (0 never does)
 
 	// HTTP client settings
 	HTTPTimeout  time.Duration
@@ -28,16 +44,141 @@ type Config struct {
 	RetryBackoff time.Duration
 
 	// Stream provider
-	StreamProvider string
-	TritonAPIKey   string
+	StreamProvider     string
+	TritonAPIKey       string
+	StreamStallTimeout time.Duration // restart the provider after this long without swaps or polls (0: never)
+	StreamCommitment   string        // commitment of polled signatures, transactions and the chain tip
+	FinalityInterval   time.Duration // how often swaps indexed at confirmed are checked for finality (0: not tracked)
+	StreamMode         string        // signatures (page each program's signatures) or blocks (read every block)
+
+	// Indexer tuning (optional, defaults from constants)
+	SignatureBatchSize int
+	TxFetchDelay       time.Duration
+	ProgramAddresses   []string
+
+	// Indexer replicas (leader election per program address)
+	LeaderElection bool          // only the lease holder polls each program
+	LeaseTTL       time.Duration // how long a dead leader blocks takeover
+	InstanceID     string        // replica id in leases and status reports (default hostname-pid)
+	MetricsAddr    string        // standalone indexer serves /metrics here (empty: off)
+	DrainTimeout   time.Duration // how long shutdown waits for the in-flight swap
+
+	RecordFailedSwaps bool // store failed transactions of polled programs in failed_swaps
+	RegisterTokens    bool // label unknown mints with their Metaplex symbol and relabel stored swaps
+
+	// Ingestion pipeline stages run before the sinks
+	DedupWindow    int      // recently delivered swaps remembered to drop redeliveries (0: off)
+	DisabledStages []string // pipeline stages switched off, e.g. dedup
+
+	// Sinks every indexed swap is written to
+	Sinks           []string // sink types, in order (default clickhouse, redis)
+	BestEffortSinks []string // sinks whose failures are skipped instead of dead-lettered
+	SinkWebhookURL  string   // webhook sink endpoint
+	SinkFilePath    string   // file sink output (NDJSON)
+	SinkCSVPath     string   // csv sink output
+
+	// Ingestion filter, applied before any sink (toggle with the indexer.filters flag)
+	FilterMinAmount   float64  // smallest amount_in indexed
+	FilterAllowTokens []string // only swaps between these tokens are indexed
+	FilterDenyTokens  []string // swaps touching these tokens are dropped
+	FilterDexes       []string // only swaps on these DEXes are indexed
+
+	// Jupiter client (the base URL and API key are read by the API command)
+	JupiterTimeout        time.Duration // per attempt
+	JupiterMaxRetries     int           // retries after the first attempt on network errors, 429 and 5xx
+	JupiterRetryBackoff   time.Duration // first retry delay, doubled per retry, with jitter
+	JupiterMaxBackoff     time.Duration // longest single delay, Retry-After included
+	JupiterMaxConcurrency int           // Jupiter requests in flight at once
+	JupiterQuoteCacheTTL  time.Duration // identical quotes are served from Redis this long (0: off)
+
+	// Arbitrage detector (ssi arb, or the arb service of ssi all)
+	ArbThresholdBps    float64       // smallest cross-venue spread reported
+	ArbMaxPriceAge     time.Duration // venue prices older than this are not compared
+	ArbCooldown        time.Duration // the same pair and venues are reported at most this often
+	ArbJupiterInterval time.Duration // per-pair Jupiter quote refresh (0: DEX prices only)
+
+	// Volume anomaly detector (ssi anomalies, or the anomalies service of ssi all)
+	AnomalyStdDevs       float64 // spikes must exceed the hourly mean by this many standard deviations
+	AnomalyBaselineHours int     // completed hours in each pair's baseline
+	AnomalyMinHours      int     // baseline hours needed before a pair is judged
+
+	// Sandwich scanner (ssi mev, or the mev service of ssi all)
+	MEVScanInterval time.Duration // between scans of newly stored swaps
+	MEVLookback     time.Duration // how far back the first scan reaches without a checkpoint
+	MEVSettleDelay  time.Duration // swaps younger than this wait for the next scan
+
+	// Per-pair ticker (ssi ticker, or the ticker service of ssi all)
+	TickerInterval time.Duration // between tickers on swaps:ticker:<pair>
+
+	// Pub/Sub consumers (ssi subscriber, ticker, arb and anomalies)
+	ConsumerReorderWindow time.Duration // swaps are held this long to be dispatched in slot order per pair (0: as received)
 
 	// LLM / OpenRouter settings
 	OpenRouterAPIKey string
+	AIModel          string
+	AIRouterModel    string        // cheaper model that classifies questions before SQL generation
+	AIMaxLookback    time.Duration // generated SQL without a time filter is bounded to this far back (0: unbounded)
 
 	// API
-	APIAddr string
-	APIKey  string
-	DevMode bool
+	APIAddr     string
+	APIKey      string
+	APIKeys     []string // further accepted keys; /v1/ai limits and budgets apply per key
+	DevMode     bool
+	AIRateLimit float64 // requests per second per client on /v1/ai
+	AIRateBurst int
+
+	AIMonthlyBudgetUSD       float64 // estimated LLM spend allowed per client and month (0: unlimited)
+	AIPromptPricePerMTok     float64 // USD per million prompt tokens, for the budget
+	AICompletionPricePerMTok float64 // USD per million completion tokens, for the budget
+
+	ReadyMaxSlotLag int64 // /readyz fails when an indexer lags more slots than this (0: not checked)
+
+	SwapAPIEnabled bool          // serve POST /v1/swap/execute through the swap engine
+	IdempotencyTTL time.Duration // how long Idempotency-Key responses are replayed
+
+	ResponseCacheTTL    time.Duration // hot read endpoints are served from Redis this long (0: off)
+	WalletStatsCacheTTL time.Duration // /v1/wallets responses are served from Redis this long (0: off)
+	MarketsCacheTTL     time.Duration // /v1/pairs and /v1/dexes responses are served from Redis this long (0: off)
+
+	MaxBodyBytes        int64         // request bodies above this get 413
+	RequestTimeout      time.Duration // default per-request deadline (408 past it)
+	AIRequestTimeout    time.Duration // deadline on /v1/ai routes
+	QuoteRequestTimeout time.Duration // deadline on /v1/quote
+
+	ExportMaxRows int           // rows streamed per /v1/export/swaps request at most
+	ExportTimeout time.Duration // deadline on /v1/export/swaps
+
+	SubscriptionsMax int // pair and token subscriptions per API key at most
+
+	WebhookAllowedHosts []string // hosts client-chosen webhooks may target (empty: any public host)
+	WebhookAllowPrivate bool     // let client-chosen webhooks reach loopback and private addresses
+
+	TLSCertFile      string   // serve HTTPS with this certificate (with TLSKeyFile)
+	TLSKeyFile       string   // private key for TLSCertFile
+	AutocertHosts    []string // serve HTTPS with Let's Encrypt certificates for these hosts
+	AutocertCacheDir string   // where autocert keeps issued certificates
+	AutocertEmail    string   // ACME account contact (optional)
+	TLSRedirectAddr  string   // plain HTTP listener that redirects to HTTPS (optional)
+
+	GRPCAddr       string // serve the gRPC API here as well (empty: off)
+	GRPCMaxStreams int    // concurrent gRPC subscriptions per process
+
+	// Feature flags
+	FlagsHistoryLimit int // changes kept per flag in the audit history
+
+	// Prices
+	PriceTTL        time.Duration // Redis expiry of a token price after its last update
+	PriceStaleAfter time.Duration // age at which the API flags a price as stale
+
+	PriceHistoryWindow    time.Duration // how far back the rolling per-token history reaches
+	PriceHistoryMaxPoints int           // newest history points kept per token
+
+	// Recent swaps
+	MaxRecentSwaps int // length of the global and each per-pair recent swaps list
+
+	SwapEncoding string // json, msgpack or protobuf, for swap events written to Redis
+
+	CacheWarmStart bool // fill an empty Redis cache from ClickHouse on startup
 }
 
 // Load reads all configuration from environment variables
@@ -46,19 +187,31 @@ func Load() *Config {
 	// Validate all required env vars first
 	validateRequiredEnvVars()
 
+	appEnv, err := NormalizeProfile(os.Getenv("APP_ENV"))
+	if err != nil {
+		panic(err.Error())
+	}
+
 	return &Config{
+		AppEnv: appEnv,
+
 		// RPC
 		RPCUrl:       mustEnv("SOLANA_RPC_URL"),
 		PollInterval: mustDurationEnv("POLL_INTERVAL"),
 
 		// Redis
-		RedisAddr: mustEnv("REDIS_ADDR"),
+		RedisAddr:     mustEnv("REDIS_ADDR"),
+		RedisUsername: envOr("REDIS_USERNAME", ""),
+		RedisPassword: envOr("REDIS_PASSWORD", ""),
+		RedisDB:       intEnvOr("REDIS_DB", 0),
+		RedisTLS:      boolEnvOr("REDIS_TLS", false),
 
 		// ClickHouse
-		ClickHouseAddr:     mustEnv("CLICKHOUSE_ADDR"),
-		ClickHouseDatabase: mustEnv("CLICKHOUSE_DATABASE"),
-		ClickHouseUsername: mustEnv("CLICKHOUSE_USERNAME"),
-		ClickHousePassword: mustEnv("CLICKHOUSE_PASSWORD"),
+		ClickHouseAddr:       mustEnv("CLICKHOUSE_ADDR"),
+		ClickHouseDatabase:   mustEnv("CLICKHOUSE_DATABASE"),
+		ClickHouseUsername:   mustEnv("CLICKHOUSE_USERNAME"),
+		ClickHousePassword:   mustEnv("CLICKHOUSE_PASSWORD"),
+		ClickHouseSlowInsert: durationEnvOr("CLICKHOUSE_SLOW_INSERT", constants.ClickHouseSlowInsert),
 
 		// HTTP
 		HTTPTimeout:  mustDurationEnv("HTTP_TIMEOUT"),
@@ -66,16 +219,135 @@ func Load() *Config {
 		RetryBackoff: mustDurationEnv("RETRY_BACKOFF"),
 
 		// Stream
-		StreamProvider: mustEnv("STREAM_PROVIDER"),
-		TritonAPIKey:   mustEnv("TRITON_API_KEY"),
+		StreamProvider:     mustEnv("STREAM_PROVIDER"),
+		TritonAPIKey:       mustEnv("TRITON_API_KEY"),
+		StreamStallTimeout: durationEnvOr("STREAM_STALL_TIMEOUT", constants.StreamStallTimeout),
+		StreamCommitment:   strings.ToLower(envOr("STREAM_COMMITMENT", constants.StreamCommitment)),
+		FinalityInterval:   durationEnvOr("INDEXER_FINALITY_INTERVAL", constants.FinalityInterval),
+		StreamMode:         strings.ToLower(envOr("STREAM_MODE", constants.StreamMode)),
 
-		// LLM / OpenRouter
-		OpenRouterAPIKey: mustEnv("OPENROUTER_API_KEY"),
+		// Indexer
+		SignatureBatchSize: intEnvOr("SIGNATURE_BATCH_SIZE", constants.SignatureBatchSize),
+		TxFetchDelay:       durationEnvOr("TX_FETCH_DELAY", constants.DelayBetweenTxFetch),
+		ProgramAddresses:   listEnvOr("PROGRAM_ADDRESSES", []string{constants.ProgramAddresses["Orca"]}),
+
+		LeaderElection: boolEnvOr("INDEXER_LEADER_ELECTION", false),
+		LeaseTTL:       durationEnvOr("INDEXER_LEASE_TTL", constants.LeaderLeaseTTL),
+		InstanceID:     envOr("INDEXER_INSTANCE_ID", ""),
+		MetricsAddr:    envOr("METRICS_ADDR", ""),
+		DrainTimeout:   durationEnvOr("INDEXER_DRAIN_TIMEOUT", constants.DrainTimeout),
+
+		RecordFailedSwaps: boolEnvOr("INDEXER_RECORD_FAILED_SWAPS", false),
+		RegisterTokens:    boolEnvOr("INDEXER_REGISTER_TOKENS", true),
+
+		DedupWindow:    intEnvOr("INDEXER_DEDUP_WINDOW", constants.DedupWindow),
+		DisabledStages: listEnvOr("INDEXER_DISABLED_STAGES", nil),
+
+		Sinks:           listEnvOr("INDEXER_SINKS", []string{"clickhouse", "redis"}),
+		BestEffortSinks: listEnvOr("INDEXER_BEST_EFFORT_SINKS", nil),
+		SinkWebhookURL:  os.Getenv("INDEXER_SINK_WEBHOOK_URL"),
+		SinkFilePath:    os.Getenv("INDEXER_SINK_FILE_PATH"),
+		SinkCSVPath:     os.Getenv("INDEXER_SINK_CSV_PATH"),
+
+		FilterMinAmount:   floatEnvOr("INDEXER_FILTER_MIN_AMOUNT", 0),
+		FilterAllowTokens: listEnvOr("INDEXER_FILTER_ALLOW_TOKENS", nil),
+		FilterDenyTokens:  listEnvOr("INDEXER_FILTER_DENY_TOKENS", nil),
+		FilterDexes:       listEnvOr("INDEXER_FILTER_DEXES", nil),
+
+		// Jupiter
+		JupiterTimeout:        durationEnvOr("JUPITER_TIMEOUT", constants.JupiterTimeout),
+		JupiterMaxRetries:     intEnvOr("JUPITER_MAX_RETRIES", constants.JupiterMaxRetries),
+		JupiterRetryBackoff:   durationEnvOr("JUPITER_RETRY_BACKOFF", constants.JupiterRetryBackoff),
+		JupiterMaxBackoff:     durationEnvOr("JUPITER_MAX_BACKOFF", constants.JupiterMaxBackoff),
+		JupiterMaxConcurrency: intEnvOr("JUPITER_MAX_CONCURRENCY", constants.JupiterMaxConcurrency),
+		JupiterQuoteCacheTTL:  durationEnvOr("JUPITER_QUOTE_CACHE_TTL", constants.QuoteCacheTTL),
+
+		// Arbitrage detector
+		ArbThresholdBps:    floatEnvOr("ARB_THRESHOLD_BPS", constants.ArbThresholdBps),
+		ArbMaxPriceAge:     durationEnvOr("ARB_MAX_PRICE_AGE", constants.ArbMaxPriceAge),
+		ArbCooldown:        durationEnvOr("ARB_COOLDOWN", constants.ArbCooldown),
+		ArbJupiterInterval: durationEnvOr("ARB_JUPITER_INTERVAL", constants.ArbJupiterInterval),
+
+		// Volume anomaly detector
+		AnomalyStdDevs:       floatEnvOr("ANOMALY_STDDEVS", constants.AnomalyStdDevs),
+		AnomalyBaselineHours: intEnvOr("ANOMALY_BASELINE_HOURS", constants.AnomalyBaselineHours),
+		AnomalyMinHours:      intEnvOr("ANOMALY_MIN_HOURS", constants.AnomalyMinHours),
+
+		// Sandwich scanner
+		MEVScanInterval: durationEnvOr("MEV_SCAN_INTERVAL", constants.MEVScanInterval),
+		MEVLookback:     durationEnvOr("MEV_LOOKBACK", constants.MEVLookback),
+		MEVSettleDelay:  durationEnvOr("MEV_SETTLE_DELAY", constants.MEVSettleDelay),
+
+		// Per-pair ticker
+		TickerInterval: durationEnvOr("TICKER_INTERVAL", constants.TickerInterval),
+
+		// Pub/Sub consumers
+		ConsumerReorderWindow: durationEnvOr("CONSUMER_REORDER_WINDOW", 0),
+
+		// LLM / OpenRouter (optional; AI features stay off without a key)
+		OpenRouterAPIKey: envOr("OPENROUTER_API_KEY", ""),
+		AIModel:          envOr("AI_MODEL", DefaultAIModel),
+		AIRouterModel:    envOr("AI_ROUTER_MODEL", DefaultAIRouterModel),
+		AIMaxLookback:    durationEnvOr("AI_MAX_LOOKBACK", constants.AIMaxLookback),
 
 		// API
-		APIAddr: mustEnv("API_ADDR"),
-		APIKey:  mustEnv("API_KEY"),
-		DevMode: mustBoolEnv("DEV"),
+		APIAddr:     mustEnv("API_ADDR"),
+		APIKey:      mustEnv("API_KEY"),
+		APIKeys:     listEnvOr("API_KEYS", nil),
+		DevMode:     mustBoolEnv("DEV"),
+		AIRateLimit: floatEnvOr("AI_RATE_LIMIT", constants.AIRateLimit),
+		AIRateBurst: intEnvOr("AI_RATE_BURST", constants.AIRateBurst),
+
+		AIMonthlyBudgetUSD:       floatEnvOr("AI_MONTHLY_BUDGET_USD", 0),
+		AIPromptPricePerMTok:     floatEnvOr("AI_PROMPT_PRICE_PER_MTOK", constants.AIPromptPricePerMTok),
+		AICompletionPricePerMTok: floatEnvOr("AI_COMPLETION_PRICE_PER_MTOK", constants.AICompletionPricePerMTok),
+
+		ReadyMaxSlotLag: int64(intEnvOr("READY_MAX_SLOT_LAG", constants.ReadyMaxSlotLag)),
+
+		SwapAPIEnabled: boolEnvOr("SWAP_API_ENABLED", false),
+		IdempotencyTTL: durationEnvOr("IDEMPOTENCY_TTL", constants.IdempotencyTTL),
+
+		ResponseCacheTTL:    durationEnvOr("RESPONSE_CACHE_TTL", 0),
+		WalletStatsCacheTTL: durationEnvOr("WALLET_STATS_CACHE_TTL", constants.WalletStatsCacheTTL),
+		MarketsCacheTTL:     durationEnvOr("MARKETS_CACHE_TTL", constants.MarketsCacheTTL),
+
+		MaxBodyBytes:        int64(intEnvOr("MAX_REQUEST_BODY_BYTES", constants.MaxRequestBodyBytes)),
+		RequestTimeout:      durationEnvOr("REQUEST_TIMEOUT", constants.RequestTimeout),
+		AIRequestTimeout:    durationEnvOr("AI_REQUEST_TIMEOUT", constants.AIRequestTimeout),
+		QuoteRequestTimeout: durationEnvOr("QUOTE_REQUEST_TIMEOUT", constants.QuoteRequestTimeout),
+
+		ExportMaxRows: intEnvOr("EXPORT_MAX_ROWS", constants.ExportMaxRows),
+		ExportTimeout: durationEnvOr("EXPORT_TIMEOUT", constants.ExportTimeout),
+
+		SubscriptionsMax: intEnvOr("SUBSCRIPTIONS_MAX_PER_KEY", constants.SubscriptionsMaxPerKey),
+
+		WebhookAllowedHosts: listEnvOr("WEBHOOK_ALLOWED_HOSTS", nil),
+		WebhookAllowPrivate: boolEnvOr("WEBHOOK_ALLOW_PRIVATE", false),
+
+		TLSCertFile:      os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:       os.Getenv("TLS_KEY_FILE"),
+		AutocertHosts:    listEnvOr("TLS_AUTOCERT_HOSTS", nil),
+		AutocertCacheDir: envOr("TLS_AUTOCERT_CACHE_DIR", constants.AutocertCacheDir),
+		AutocertEmail:    os.Getenv("TLS_AUTOCERT_EMAIL"),
+		TLSRedirectAddr:  os.Getenv("TLS_REDIRECT_ADDR"),
+
+		GRPCAddr:       os.Getenv("GRPC_ADDR"),
+		GRPCMaxStreams: intEnvOr("GRPC_MAX_STREAMS", constants.GRPCMaxStreams),
+
+		// Feature flags
+		FlagsHistoryLimit: intEnvOr("FLAGS_HISTORY_LIMIT", 100),
+
+		// Prices
+		PriceTTL:        durationEnvOr("PRICE_TTL", constants.PriceTTL),
+		PriceStaleAfter: durationEnvOr("PRICE_STALE_AFTER", constants.PriceStaleAfter),
+
+		PriceHistoryWindow:    durationEnvOr("PRICE_HISTORY_WINDOW", constants.PriceHistoryWindow),
+		PriceHistoryMaxPoints: intEnvOr("PRICE_HISTORY_MAX_POINTS", constants.PriceHistoryMaxPoints),
+
+		// Recent swaps
+		MaxRecentSwaps: intEnvOr("RECENT_SWAPS_MAX", constants.MaxRecentSwaps),
+		SwapEncoding:   envOr("SWAP_ENCODING", constants.SwapEncodingJSON),
+		CacheWarmStart: boolEnvOr("CACHE_WARM_START", true),
 	}
 }
 
@@ -94,7 +366,6 @@ func validateRequiredEnvVars() {
 		"RETRY_BACKOFF",
 		"STREAM_PROVIDER",
 		"TRITON_API_KEY",
-		"OPENROUTER_API_KEY",
 		"API_ADDR",
 		"API_KEY",
 		"DEV",
@@ -155,7 +426,241 @@ func mustBoolEnv(key string) bool {
 	return boolVal
 }
 
-// Validate is optional since all fields are mustEnv-driven
+// envOr reads an optional string env, falling back to def
+func envOr(key, def string) string {
+	if val := strings.TrimSpace(os.Getenv(key)); val != "" {
+		return val
+	}
+	return def
+}
+
+// intEnvOr reads an optional int env, falling back to def; a set but invalid value panics
+func intEnvOr(key string, def int) int {
+	if strings.TrimSpace(os.Getenv(key)) == "" {
+		return def
+	}
+	return mustIntEnv(key)
+}
+
+// boolEnvOr reads an optional bool env, falling back to def; a set but invalid value panics
+func boolEnvOr(key string, def bool) bool {
+	if strings.TrimSpace(os.Getenv(key)) == "" {
+		return def
+	}
+	return mustBoolEnv(key)
+}
+
+// floatEnvOr reads an optional float env, falling back to def; a set but invalid value panics
+func floatEnvOr(key string, def float64) float64 {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		panic(fmt.Sprintf("invalid number for %s: %v (got: %q)", key, err, val))
+	}
+	return f
+}
+
+// durationEnvOr reads an optional duration env, falling back to def; a set but invalid value panics
+func durationEnvOr(key string, def time.Duration) time.Duration {
+	if strings.TrimSpace(os.Getenv(key)) == "" {
+		return def
+	}
+	return mustDurationEnv(key)
+}
+
+// listEnvOr reads an optional comma-separated env, falling back to def
+func listEnvOr(key string, def []string) []string {
+	var out []string
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	if len(out) == 0 {
+		return def
+	}
+	return out
+}
+
+// TryLoad is Load without the panic, for reloads where a bad edit must not
+// take down a running process
+func TryLoad() (cfg *Config, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			cfg, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+
+	cfg = Load()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks values that parse correctly but are out of range
 func (c *Config) Validate() error {
+	if c.SignatureBatchSize < 1 {
+		return fmt.Errorf("SIGNATURE_BATCH_SIZE must be >= 1 (got %d)", c.SignatureBatchSize)
+	}
+	if c.TxFetchDelay < 0 {
+		return fmt.Errorf("TX_FETCH_DELAY must not be negative (got %s)", c.TxFetchDelay)
+	}
+	if c.LeaseTTL < time.Second {
+		return fmt.Errorf("INDEXER_LEASE_TTL must be >= 1s (got %s)", c.LeaseTTL)
+	}
+	if c.DrainTimeout <= 0 {
+		return fmt.Errorf("INDEXER_DRAIN_TIMEOUT must be > 0 (got %s)", c.DrainTimeout)
+	}
+	if c.StreamStallTimeout < 0 {
+		return fmt.Errorf("STREAM_STALL_TIMEOUT must not be negative (got %s)", c.StreamStallTimeout)
+	}
+	if c.StreamCommitment != "confirmed" && c.StreamCommitment != "finalized" {
+		return fmt.Errorf("STREAM_COMMITMENT must be confirmed or finalized (got %q)", c.StreamCommitment)
+	}
+	if c.FinalityInterval != 0 && c.FinalityInterval < time.Second {
+		return fmt.Errorf("INDEXER_FINALITY_INTERVAL must be 0 or >= 1s (got %s)", c.FinalityInterval)
+	}
+	if c.StreamMode != "signatures" && c.StreamMode != "blocks" {
+		return fmt.Errorf("STREAM_MODE must be signatures or blocks (got %q)", c.StreamMode)
+	}
+	if c.StreamStallTimeout > 0 && c.StreamStallTimeout < 2*c.PollInterval {
+		return fmt.Errorf("STREAM_STALL_TIMEOUT must be at least twice POLL_INTERVAL (got %s, poll interval %s)", c.StreamStallTimeout, c.PollInterval)
+	}
+	if len(c.Sinks) == 0 {
+		return fmt.Errorf("INDEXER_SINKS must name at least one sink")
+	}
+	if c.DedupWindow < 0 {
+		return fmt.Errorf("INDEXER_DEDUP_WINDOW must not be negative (got %d)", c.DedupWindow)
+	}
+	if c.FilterMinAmount < 0 {
+		return fmt.Errorf("INDEXER_FILTER_MIN_AMOUNT must not be negative (got %g)", c.FilterMinAmount)
+	}
+	if c.JupiterTimeout <= 0 {
+		return fmt.Errorf("JUPITER_TIMEOUT must be > 0 (got %s)", c.JupiterTimeout)
+	}
+	if c.JupiterMaxRetries < 0 {
+		return fmt.Errorf("JUPITER_MAX_RETRIES must not be negative (got %d)", c.JupiterMaxRetries)
+	}
+	if c.JupiterRetryBackoff <= 0 || c.JupiterMaxBackoff < c.JupiterRetryBackoff {
+		return fmt.Errorf("JUPITER_RETRY_BACKOFF must be > 0 and <= JUPITER_MAX_BACKOFF (got %s, max %s)", c.JupiterRetryBackoff, c.JupiterMaxBackoff)
+	}
+	if c.JupiterMaxConcurrency < 1 {
+		return fmt.Errorf("JUPITER_MAX_CONCURRENCY must be >= 1 (got %d)", c.JupiterMaxConcurrency)
+	}
+	if c.JupiterQuoteCacheTTL < 0 || c.JupiterQuoteCacheTTL > constants.QuoteCacheMaxTTL {
+		return fmt.Errorf("JUPITER_QUOTE_CACHE_TTL must be between 0 and %s (got %s)", constants.QuoteCacheMaxTTL, c.JupiterQuoteCacheTTL)
+	}
+	if c.ArbThresholdBps <= 0 || c.ArbThresholdBps > 10000 {
+		return fmt.Errorf("ARB_THRESHOLD_BPS must be between 0 and 10000 (got %g)", c.ArbThresholdBps)
+	}
+	if c.ArbMaxPriceAge <= 0 || c.ArbCooldown < 0 || c.ArbJupiterInterval < 0 {
+		return fmt.Errorf("ARB_MAX_PRICE_AGE must be > 0, ARB_COOLDOWN and ARB_JUPITER_INTERVAL must not be negative (got %s, %s, %s)",
+			c.ArbMaxPriceAge, c.ArbCooldown, c.ArbJupiterInterval)
+	}
+	if c.AnomalyStdDevs <= 0 {
+		return fmt.Errorf("ANOMALY_STDDEVS must be > 0 (got %g)", c.AnomalyStdDevs)
+	}
+	if c.AnomalyBaselineHours < 2 || c.AnomalyMinHours < 2 || c.AnomalyMinHours > c.AnomalyBaselineHours {
+		return fmt.Errorf("ANOMALY_MIN_HOURS must be between 2 and ANOMALY_BASELINE_HOURS (got %d, baseline %d)", c.AnomalyMinHours, c.AnomalyBaselineHours)
+	}
+	if c.MEVScanInterval <= 0 || c.MEVLookback <= 0 || c.MEVSettleDelay < 0 {
+		return fmt.Errorf("MEV_SCAN_INTERVAL and MEV_LOOKBACK must be > 0, MEV_SETTLE_DELAY must not be negative (got %s, %s, %s)",
+			c.MEVScanInterval, c.MEVLookback, c.MEVSettleDelay)
+	}
+	if c.TickerInterval < time.Second || c.TickerInterval > constants.TickerWindow {
+		return fmt.Errorf("TICKER_INTERVAL must be between 1s and %s (got %s)", constants.TickerWindow, c.TickerInterval)
+	}
+	if c.ConsumerReorderWindow < 0 || c.ConsumerReorderWindow > constants.ConsumerMaxReorderWindow {
+		return fmt.Errorf("CONSUMER_REORDER_WINDOW must be between 0 and %s (got %s)", constants.ConsumerMaxReorderWindow, c.ConsumerReorderWindow)
+	}
+	if c.AIRateLimit <= 0 {
+		return fmt.Errorf("AI_RATE_LIMIT must be > 0 (got %g)", c.AIRateLimit)
+	}
+	if c.AIRateBurst < 1 {
+		return fmt.Errorf("AI_RATE_BURST must be >= 1 (got %d)", c.AIRateBurst)
+	}
+	if c.AIMaxLookback < 0 {
+		return fmt.Errorf("AI_MAX_LOOKBACK must not be negative (got %s)", c.AIMaxLookback)
+	}
+	if c.AIMonthlyBudgetUSD < 0 || c.AIPromptPricePerMTok < 0 || c.AICompletionPricePerMTok < 0 {
+		return fmt.Errorf("AI_MONTHLY_BUDGET_USD, AI_PROMPT_PRICE_PER_MTOK and AI_COMPLETION_PRICE_PER_MTOK must not be negative (got %g, %g, %g)",
+			c.AIMonthlyBudgetUSD, c.AIPromptPricePerMTok, c.AICompletionPricePerMTok)
+	}
+	if c.ReadyMaxSlotLag < 0 {
+		return fmt.Errorf("READY_MAX_SLOT_LAG must not be negative (got %d)", c.ReadyMaxSlotLag)
+	}
+	if c.IdempotencyTTL < time.Minute {
+		return fmt.Errorf("IDEMPOTENCY_TTL must be >= 1m (got %s)", c.IdempotencyTTL)
+	}
+	if c.ResponseCacheTTL < 0 || c.ResponseCacheTTL > constants.ResponseCacheMaxTTL {
+		return fmt.Errorf("RESPONSE_CACHE_TTL must be between 0 and %s (got %s)", constants.ResponseCacheMaxTTL, c.ResponseCacheTTL)
+	}
+	if c.WalletStatsCacheTTL < 0 || c.WalletStatsCacheTTL > constants.WalletStatsCacheMaxTTL {
+		return fmt.Errorf("WALLET_STATS_CACHE_TTL must be between 0 and %s (got %s)", constants.WalletStatsCacheMaxTTL, c.WalletStatsCacheTTL)
+	}
+	if c.MarketsCacheTTL < 0 || c.MarketsCacheTTL > constants.MarketsCacheMaxTTL {
+		return fmt.Errorf("MARKETS_CACHE_TTL must be between 0 and %s (got %s)", constants.MarketsCacheMaxTTL, c.MarketsCacheTTL)
+	}
+	if c.GRPCAddr != "" && c.GRPCAddr == c.APIAddr {
+		return fmt.Errorf("GRPC_ADDR must differ from API_ADDR (got %s)", c.GRPCAddr)
+	}
+	if c.GRPCMaxStreams < 1 {
+		return fmt.Errorf("GRPC_MAX_STREAMS must be >= 1 (got %d)", c.GRPCMaxStreams)
+	}
+	if c.MaxBodyBytes < 1 {
+		return fmt.Errorf("MAX_REQUEST_BODY_BYTES must be >= 1 (got %d)", c.MaxBodyBytes)
+	}
+	if c.RequestTimeout <= 0 || c.AIRequestTimeout <= 0 || c.QuoteRequestTimeout <= 0 {
+		return fmt.Errorf("REQUEST_TIMEOUT, AI_REQUEST_TIMEOUT and QUOTE_REQUEST_TIMEOUT must be > 0 (got %s, %s, %s)",
+			c.RequestTimeout, c.AIRequestTimeout, c.QuoteRequestTimeout)
+	}
+	if c.ExportMaxRows < 1 {
+		return fmt.Errorf("EXPORT_MAX_ROWS must be >= 1 (got %d)", c.ExportMaxRows)
+	}
+	if c.ExportTimeout <= 0 {
+		return fmt.Errorf("EXPORT_TIMEOUT must be > 0 (got %s)", c.ExportTimeout)
+	}
+	if c.SubscriptionsMax < 1 {
+		return fmt.Errorf("SUBSCRIPTIONS_MAX_PER_KEY must be >= 1 (got %d)", c.SubscriptionsMax)
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if c.TLSCertFile != "" && len(c.AutocertHosts) > 0 {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_AUTOCERT_HOSTS are mutually exclusive")
+	}
+	if c.TLSRedirectAddr != "" && c.TLSCertFile == "" && len(c.AutocertHosts) == 0 {
+		return fmt.Errorf("TLS_REDIRECT_ADDR needs TLS_CERT_FILE or TLS_AUTOCERT_HOSTS")
+	}
+	if c.FlagsHistoryLimit < 1 {
+		return fmt.Errorf("FLAGS_HISTORY_LIMIT must be >= 1 (got %d)", c.FlagsHistoryLimit)
+	}
+	if c.PriceTTL <= 0 {
+		return fmt.Errorf("PRICE_TTL must be > 0 (got %s)", c.PriceTTL)
+	}
+	if c.PriceStaleAfter <= 0 || c.PriceStaleAfter > c.PriceTTL {
+		return fmt.Errorf("PRICE_STALE_AFTER must be > 0 and <= PRICE_TTL (got %s, ttl %s)", c.PriceStaleAfter, c.PriceTTL)
+	}
+	if c.PriceHistoryWindow <= 0 {
+		return fmt.Errorf("PRICE_HISTORY_WINDOW must be > 0 (got %s)", c.PriceHistoryWindow)
+	}
+	if c.PriceHistoryMaxPoints < 1 {
+		return fmt.Errorf("PRICE_HISTORY_MAX_POINTS must be >= 1 (got %d)", c.PriceHistoryMaxPoints)
+	}
+	if c.RedisDB < 0 {
+		return fmt.Errorf("REDIS_DB must be >= 0 (got %d)", c.RedisDB)
+	}
+	if c.MaxRecentSwaps < 1 {
+		return fmt.Errorf("RECENT_SWAPS_MAX must be >= 1 (got %d)", c.MaxRecentSwaps)
+	}
+	switch strings.ToLower(strings.TrimSpace(c.SwapEncoding)) {
+	case "", constants.SwapEncodingJSON, constants.SwapEncodingMsgpack, constants.SwapEncodingProtobuf:
+	default:
+		return fmt.Errorf("SWAP_ENCODING must be json, msgpack or protobuf (got %q)", c.SwapEncoding)
+	}
 	return nil
 }
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// Where an effective setting came from
+const (
+	SourceEnv     = "env"     // process environment or .env
+	SourceFile    = "file"    // config file
+	SourceProfile = "profile" // APP_ENV profile default
+	SourceUnset   = "unset"   // not set anywhere; the built-in default applies
+)
+
+// secretKeys are never printed in full
+var secretKeys = map[string]bool{
+	"API_KEY":               true,
+	"API_KEYS":              true,
+	"OPENROUTER_API_KEY":    true,
+	"WALLET_PRIVATE_KEY":    true,
+	"CLICKHOUSE_PASSWORD":   true,
+	"REDIS_PASSWORD":        true,
+	"TRITON_API_KEY":        true,
+	"JUPITER_API_KEY":       true,
+	"VAULT_TOKEN":           true,
+	"AWS_SECRET_ACCESS_KEY": true,
+	"AWS_SESSION_TOKEN":     true,
+}
+
+// credentialEnv lists settings that are only read from the environment
+var credentialEnv = []string{
+	"CONFIG_FILE",
+	"VAULT_TOKEN",
+	"AWS_ACCESS_KEY_ID",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_SESSION_TOKEN",
+}
+
+// Setting is one effective configuration value
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Effective lists every known setting with its current, redacted value and
+// where it came from. Call it after LoadFile (and secrets loading).
+func Effective() []Setting {
+	keys := slices.Clone(credentialEnv)
+	for k := range (&File{}).env() {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	fileOwnedMu.Lock()
+	defer fileOwnedMu.Unlock()
+
+	out := make([]Setting, 0, len(keys))
+	for _, key := range keys {
+		s := Setting{Key: key, Source: SourceUnset}
+		if val, set := os.LookupEnv(key); set {
+			s.Value = val
+			s.Source = SourceEnv
+			if prev, owned := fileOwned[key]; owned && prev == val {
+				s.Source = fileSource[key]
+			}
+			if prev, owned := flagOwned[key]; owned && prev == val {
+				s.Source = SourceFlag
+			}
+		}
+		if secretKeys[key] {
+			s.Value = Redact(s.Value)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// Redact masks a secret, keeping the last four characters of long values so
+// operators can tell which key is deployed
+func Redact(v string) string {
+	v = strings.TrimSpace(v)
+	switch {
+	case v == "":
+		return ""
+	case len(v) <= 12:
+		return "****"
+	default:
+		return "****" + v[len(v)-4:]
+	}
+}
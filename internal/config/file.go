@@ -0,0 +1,571 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// fileOwned remembers which env vars LoadFile set (and to what), so a later
+// reload may overwrite or clear them without clobbering real env overrides.
+var (
+	fileOwnedMu sync.Mutex
+	fileOwned   = map[string]string{}
+	fileSource  = map[string]string{} // key -> "file" or "profile"
+	flagOwned   = map[string]string{} // key -> value exported by Overrides.Apply
+)
+
+// File mirrors the optional config.yaml (or config.toml, with the same keys
+// and tables). Every field maps onto the environment
+// variable noted next to it, so the file is just another source for the same
+// settings that Load (and the swap engine) already read from the environment.
+//
+// Precedence: CLI flags > environment (including .env) > config file.
+type File struct {
+	AppEnv string `yaml:"app_env"` // APP_ENV (dev, staging, prod)
+
+	RPC struct {
+		URL          string `yaml:"url"`           // SOLANA_RPC_URL
+		PollInterval string `yaml:"poll_interval"` // POLL_INTERVAL
+		HTTPTimeout  string `yaml:"http_timeout"`  // HTTP_TIMEOUT
+		MaxRetries   string `yaml:"max_retries"`   // MAX_RETRIES
+		RetryBackoff string `yaml:"retry_backoff"` // RETRY_BACKOFF
+	} `yaml:"rpc"`
+
+	Stream struct {
+		Provider     string `yaml:"provider"`       // STREAM_PROVIDER
+		TritonAPIKey string `yaml:"triton_api_key"` // TRITON_API_KEY
+		StallTimeout string `yaml:"stall_timeout"`  // STREAM_STALL_TIMEOUT
+		Commitment   string `yaml:"commitment"`     // STREAM_COMMITMENT
+		Mode         string `yaml:"mode"`           // STREAM_MODE
+	} `yaml:"stream"`
+
+	Redis struct {
+		Addr     string `yaml:"addr"`     // REDIS_ADDR
+		Username string `yaml:"username"` // REDIS_USERNAME
+		Password string `yaml:"password"` // REDIS_PASSWORD
+		DB       string `yaml:"db"`       // REDIS_DB
+		TLS      string `yaml:"tls"`      // REDIS_TLS
+
+		PriceTTL        string `yaml:"price_ttl"`         // PRICE_TTL
+		PriceStaleAfter string `yaml:"price_stale_after"` // PRICE_STALE_AFTER
+
+		PriceHistoryWindow    string `yaml:"price_history_window"`     // PRICE_HISTORY_WINDOW
+		PriceHistoryMaxPoints string `yaml:"price_history_max_points"` // PRICE_HISTORY_MAX_POINTS
+
+		RecentSwapsMax string `yaml:"recent_swaps_max"` // RECENT_SWAPS_MAX
+		SwapEncoding   string `yaml:"swap_encoding"`    // SWAP_ENCODING
+		WarmStart      string `yaml:"warm_start"`       // CACHE_WARM_START
+	} `yaml:"redis"`
+
+	ClickHouse struct {
+		Addr       string `yaml:"addr"`        // CLICKHOUSE_ADDR
+		Database   string `yaml:"database"`    // CLICKHOUSE_DATABASE
+		Username   string `yaml:"username"`    // CLICKHOUSE_USERNAME
+		Password   string `yaml:"password"`    // CLICKHOUSE_PASSWORD
+		SlowInsert string `yaml:"slow_insert"` // CLICKHOUSE_SLOW_INSERT
+	} `yaml:"clickhouse"`
+
+	API struct {
+		Addr string   `yaml:"addr"` // API_ADDR
+		Key  string   `yaml:"key"`  // API_KEY
+		Keys []string `yaml:"keys"` // API_KEYS
+		Dev  string   `yaml:"dev"`  // DEV
+
+		AIRateLimit string `yaml:"ai_rate_limit"` // AI_RATE_LIMIT (requests/sec per API key)
+		AIRateBurst string `yaml:"ai_rate_burst"` // AI_RATE_BURST
+
+		AIMonthlyBudgetUSD       string `yaml:"ai_monthly_budget_usd"`        // AI_MONTHLY_BUDGET_USD
+		AIPromptPricePerMTok     string `yaml:"ai_prompt_price_per_mtok"`     // AI_PROMPT_PRICE_PER_MTOK
+		AICompletionPricePerMTok string `yaml:"ai_completion_price_per_mtok"` // AI_COMPLETION_PRICE_PER_MTOK
+
+		ReadyMaxSlotLag string `yaml:"ready_max_slot_lag"` // READY_MAX_SLOT_LAG
+
+		SwapAPIEnabled string `yaml:"swap_api_enabled"` // SWAP_API_ENABLED
+		IdempotencyTTL string `yaml:"idempotency_ttl"`  // IDEMPOTENCY_TTL
+
+		FlagsHistoryLimit string `yaml:"flags_history_limit"` // FLAGS_HISTORY_LIMIT
+
+		ResponseCacheTTL    string `yaml:"response_cache_ttl"`     // RESPONSE_CACHE_TTL
+		WalletStatsCacheTTL string `yaml:"wallet_stats_cache_ttl"` // WALLET_STATS_CACHE_TTL
+		MarketsCacheTTL     string `yaml:"markets_cache_ttl"`      // MARKETS_CACHE_TTL
+
+		MaxRequestBodyBytes string `yaml:"max_request_body_bytes"` // MAX_REQUEST_BODY_BYTES
+		RequestTimeout      string `yaml:"request_timeout"`        // REQUEST_TIMEOUT
+		AIRequestTimeout    string `yaml:"ai_request_timeout"`     // AI_REQUEST_TIMEOUT
+		QuoteRequestTimeout string `yaml:"quote_request_timeout"`  // QUOTE_REQUEST_TIMEOUT
+
+		ExportMaxRows string `yaml:"export_max_rows"` // EXPORT_MAX_ROWS
+		ExportTimeout string `yaml:"export_timeout"`  // EXPORT_TIMEOUT
+
+		SubscriptionsMaxPerKey string `yaml:"subscriptions_max_per_key"` // SUBSCRIPTIONS_MAX_PER_KEY
+
+		WebhookAllowedHosts []string `yaml:"webhook_allowed_hosts"` // WEBHOOK_ALLOWED_HOSTS (comma-separated)
+		WebhookAllowPrivate string   `yaml:"webhook_allow_private"` // WEBHOOK_ALLOW_PRIVATE
+
+		TLS struct {
+			CertFile         string   `yaml:"cert_file"`          // TLS_CERT_FILE
+			KeyFile          string   `yaml:"key_file"`           // TLS_KEY_FILE
+			AutocertHosts    []string `yaml:"autocert_hosts"`     // TLS_AUTOCERT_HOSTS (comma-separated)
+			AutocertCacheDir string   `yaml:"autocert_cache_dir"` // TLS_AUTOCERT_CACHE_DIR
+			AutocertEmail    string   `yaml:"autocert_email"`     // TLS_AUTOCERT_EMAIL
+			RedirectAddr     string   `yaml:"redirect_addr"`      // TLS_REDIRECT_ADDR
+		} `yaml:"tls"`
+
+		GRPC struct {
+			Addr       string `yaml:"addr"`        // GRPC_ADDR
+			MaxStreams string `yaml:"max_streams"` // GRPC_MAX_STREAMS
+		} `yaml:"grpc"`
+	} `yaml:"api"`
+
+	AI struct {
+		OpenRouterAPIKey string `yaml:"openrouter_api_key"` // OPENROUTER_API_KEY
+		Model            string `yaml:"model"`              // AI_MODEL
+		RouterModel      string `yaml:"router_model"`       // AI_ROUTER_MODEL
+		MaxLookback      string `yaml:"max_lookback"`       // AI_MAX_LOOKBACK
+	} `yaml:"ai"`
+
+	Jupiter struct {
+		BaseURL        string `yaml:"base_url"`        // JUPITER_BASE_URL
+		APIKey         string `yaml:"api_key"`         // JUPITER_API_KEY
+		Timeout        string `yaml:"timeout"`         // JUPITER_TIMEOUT
+		MaxRetries     string `yaml:"max_retries"`     // JUPITER_MAX_RETRIES
+		RetryBackoff   string `yaml:"retry_backoff"`   // JUPITER_RETRY_BACKOFF
+		MaxBackoff     string `yaml:"max_backoff"`     // JUPITER_MAX_BACKOFF
+		MaxConcurrency string `yaml:"max_concurrency"` // JUPITER_MAX_CONCURRENCY
+		QuoteCacheTTL  string `yaml:"quote_cache_ttl"` // JUPITER_QUOTE_CACHE_TTL
+	} `yaml:"jupiter"`
+
+	Arb struct {
+		ThresholdBps    string `yaml:"threshold_bps"`    // ARB_THRESHOLD_BPS
+		MaxPriceAge     string `yaml:"max_price_age"`    // ARB_MAX_PRICE_AGE
+		Cooldown        string `yaml:"cooldown"`         // ARB_COOLDOWN
+		JupiterInterval string `yaml:"jupiter_interval"` // ARB_JUPITER_INTERVAL
+	} `yaml:"arb"`
+
+	Anomalies struct {
+		StdDevs       string `yaml:"stddevs"`        // ANOMALY_STDDEVS
+		BaselineHours string `yaml:"baseline_hours"` // ANOMALY_BASELINE_HOURS
+		MinHours      string `yaml:"min_hours"`      // ANOMALY_MIN_HOURS
+	} `yaml:"anomalies"`
+
+	MEV struct {
+		ScanInterval string `yaml:"scan_interval"` // MEV_SCAN_INTERVAL
+		Lookback     string `yaml:"lookback"`      // MEV_LOOKBACK
+		SettleDelay  string `yaml:"settle_delay"`  // MEV_SETTLE_DELAY
+	} `yaml:"mev"`
+
+	Ticker struct {
+		Interval string `yaml:"interval"` // TICKER_INTERVAL
+	} `yaml:"ticker"`
+
+	Consumer struct {
+		ReorderWindow string `yaml:"reorder_window"` // CONSUMER_REORDER_WINDOW
+	} `yaml:"consumer"`
+
+	Indexer struct {
+		LogLevel           string   `yaml:"log_level"`             // LOG_LEVEL
+		LogFormat          string   `yaml:"log_format"`            // LOG_FORMAT
+		LogSampleInitial   string   `yaml:"log_sample_initial"`    // LOG_SAMPLE_INITIAL
+		LogSampleAfter     string   `yaml:"log_sample_thereafter"` // LOG_SAMPLE_THEREAFTER
+		SignatureBatchSize string   `yaml:"signature_batch_size"`  // SIGNATURE_BATCH_SIZE
+		TxFetchDelay       string   `yaml:"tx_fetch_delay"`        // TX_FETCH_DELAY
+		ProgramAddresses   []string `yaml:"program_addresses"`     // PROGRAM_ADDRESSES (comma-separated)
+		LeaderElection     string   `yaml:"leader_election"`       // INDEXER_LEADER_ELECTION
+		LeaseTTL           string   `yaml:"lease_ttl"`             // INDEXER_LEASE_TTL
+		InstanceID         string   `yaml:"instance_id"`           // INDEXER_INSTANCE_ID
+		MetricsAddr        string   `yaml:"metrics_addr"`          // METRICS_ADDR
+		DrainTimeout       string   `yaml:"drain_timeout"`         // INDEXER_DRAIN_TIMEOUT
+		FinalityInterval   string   `yaml:"finality_interval"`     // INDEXER_FINALITY_INTERVAL
+		RecordFailedSwaps  string   `yaml:"record_failed_swaps"`   // INDEXER_RECORD_FAILED_SWAPS
+		RegisterTokens     string   `yaml:"register_tokens"`       // INDEXER_REGISTER_TOKENS
+		DedupWindow        string   `yaml:"dedup_window"`          // INDEXER_DEDUP_WINDOW
+		DisabledStages     []string `yaml:"disabled_stages"`       // INDEXER_DISABLED_STAGES (comma-separated)
+		Sinks              []string `yaml:"sinks"`                 // INDEXER_SINKS (comma-separated)
+		BestEffortSinks    []string `yaml:"best_effort_sinks"`     // INDEXER_BEST_EFFORT_SINKS (comma-separated)
+		SinkWebhookURL     string   `yaml:"sink_webhook_url"`      // INDEXER_SINK_WEBHOOK_URL
+		SinkFilePath       string   `yaml:"sink_file_path"`        // INDEXER_SINK_FILE_PATH
+		SinkCSVPath        string   `yaml:"sink_csv_path"`         // INDEXER_SINK_CSV_PATH
+
+		Filters struct {
+			MinAmount   string   `yaml:"min_amount"`   // INDEXER_FILTER_MIN_AMOUNT
+			AllowTokens []string `yaml:"allow_tokens"` // INDEXER_FILTER_ALLOW_TOKENS (comma-separated)
+			DenyTokens  []string `yaml:"deny_tokens"`  // INDEXER_FILTER_DENY_TOKENS (comma-separated)
+			Dexes       []string `yaml:"dexes"`        // INDEXER_FILTER_DEXES (comma-separated)
+		} `yaml:"filters"`
+	} `yaml:"indexer"`
+
+	Wallet struct {
+		PrivateKey       string `yaml:"private_key"`       // WALLET_PRIVATE_KEY
+		Commitment       string `yaml:"commitment"`        // WALLET_COMMITMENT
+		BlockhashRefresh string `yaml:"blockhash_refresh"` // WALLET_BLOCKHASH_REFRESH
+	} `yaml:"wallet"`
+
+	Secrets struct {
+		Provider        string `yaml:"provider"`         // SECRETS_PROVIDER (env, vault, aws)
+		RefreshInterval string `yaml:"refresh_interval"` // SECRETS_REFRESH_INTERVAL
+
+		Vault struct {
+			Addr       string `yaml:"addr"`        // VAULT_ADDR
+			SecretPath string `yaml:"secret_path"` // VAULT_SECRET_PATH
+			Namespace  string `yaml:"namespace"`   // VAULT_NAMESPACE
+		} `yaml:"vault"`
+
+		AWS struct {
+			Region   string `yaml:"region"`    // AWS_REGION
+			SecretID string `yaml:"secret_id"` // SECRETS_AWS_SECRET_ID
+		} `yaml:"aws"`
+	} `yaml:"secrets"`
+
+	SwapEngine struct {
+		PoolConfigPath    string `yaml:"pool_config_path"`   // SWAPENGINE_POOL_CONFIG_PATH
+		PoolSource        string `yaml:"pool_source"`        // SWAPENGINE_POOL_SOURCE
+		PoolStrict        string `yaml:"pool_strict"`        // SWAPENGINE_POOL_STRICT
+		RequireSimulation string `yaml:"require_simulation"` // SWAPENGINE_REQUIRE_SIMULATION
+
+		ComputeBudget struct {
+			Enabled             string `yaml:"enabled"`               // SWAPENGINE_COMPUTE_BUDGET
+			Margin              string `yaml:"margin"`                // SWAPENGINE_CU_MARGIN
+			PriorityFeeLamports string `yaml:"priority_fee_lamports"` // SWAPENGINE_PRIORITY_FEE_LAMPORTS
+		} `yaml:"compute_budget"`
+
+		Risk struct {
+			MaxSwapAmountSOL   string   `yaml:"max_swap_amount_sol"`  // SWAPENGINE_MAX_SWAP_AMOUNT_SOL
+			DailyLimitSOL      string   `yaml:"daily_limit_sol"`      // SWAPENGINE_DAILY_LIMIT_SOL
+			MaxPriceImpactBps  string   `yaml:"max_price_impact_bps"` // SWAPENGINE_MAX_PRICE_IMPACT_BPS
+			DefaultSlippageBps string   `yaml:"default_slippage_bps"` // SWAPENGINE_DEFAULT_SLIPPAGE_BPS
+			MaxSlippageBps     string   `yaml:"max_slippage_bps"`     // SWAPENGINE_MAX_SLIPPAGE_BPS
+			AllowedTokens      []string `yaml:"allowed_tokens"`       // SWAPENGINE_ALLOWED_TOKENS (comma-separated)
+			MinBalanceSOL      string   `yaml:"min_balance_sol"`      // SWAPENGINE_MIN_BALANCE_SOL
+			FailureCooldown    string   `yaml:"failure_cooldown"`     // SWAPENGINE_FAILURE_COOLDOWN
+			MaxRouteHops       string   `yaml:"max_route_hops"`       // SWAPENGINE_MAX_ROUTE_HOPS
+			ExcludedDexes      []string `yaml:"excluded_dexes"`       // SWAPENGINE_EXCLUDED_DEXES (comma-separated)
+			WalletLimits       []string `yaml:"wallet_limits"`        // SWAPENGINE_WALLET_LIMITS (ADDRESS:MAX_SWAP_SOL:DAILY_LIMIT_SOL, comma-separated)
+		} `yaml:"risk"`
+
+		Discovery struct {
+			Enabled    string   `yaml:"enabled"`     // SWAPENGINE_DISCOVERY_ENABLED
+			Programs   []string `yaml:"programs"`    // SWAPENGINE_DISCOVERY_PROGRAMS (comma-separated)
+			Mints      []string `yaml:"mints"`       // SWAPENGINE_DISCOVERY_MINTS (comma-separated)
+			MinReserve string   `yaml:"min_reserve"` // SWAPENGINE_DISCOVERY_MIN_RESERVE
+			Interval   string   `yaml:"interval"`    // SWAPENGINE_DISCOVERY_INTERVAL
+		} `yaml:"discovery"`
+	} `yaml:"swapengine"`
+}
+
+// LoadFile reads a YAML or TOML config file and exports its values into the
+// process environment, skipping any variable that is already set. An empty
+// path falls back to $CONFIG_FILE; if that is empty too, no file is read.
+// Remaining gaps are then filled from the APP_ENV profile (see profile.go),
+// if one is selected.
+// Call it after .env has been loaded and before Load. Calling it again picks up
+// edits to the file for every variable a previous call set.
+func LoadFile(path string) error {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		path = strings.TrimSpace(os.Getenv("CONFIG_FILE"))
+	}
+
+	vals := map[string]string{}
+	if path != "" {
+		f, err := ParseFile(path)
+		if err != nil {
+			return err
+		}
+		vals = f.env()
+	}
+
+	fileOwnedMu.Lock()
+	defer fileOwnedMu.Unlock()
+
+	// the profile may be picked by the real environment or by the file itself
+	appEnv := vals["APP_ENV"]
+	if cur, set := lookupUnowned("APP_ENV"); set {
+		appEnv = cur
+	}
+	defaults, err := profileDefaults(appEnv)
+	if err != nil {
+		return err
+	}
+	source := make(map[string]string, len(vals))
+	for key, val := range vals {
+		if val != "" {
+			source[key] = SourceFile
+		}
+	}
+	for key, val := range defaults {
+		if vals[key] == "" {
+			vals[key] = val
+			source[key] = SourceProfile
+		}
+	}
+
+	// keys set by a previous call but no longer provided must be cleared
+	for key := range fileOwned {
+		if _, ok := vals[key]; !ok {
+			vals[key] = ""
+		}
+	}
+
+	for key, val := range vals {
+		if _, set := lookupUnowned(key); set {
+			continue // real env override wins
+		}
+
+		if val == "" {
+			if _, owned := fileOwned[key]; owned {
+				_ = os.Unsetenv(key)
+				delete(fileOwned, key)
+				delete(fileSource, key)
+			}
+			continue
+		}
+		if err := os.Setenv(key, val); err != nil {
+			return fmt.Errorf("set %s from config file: %w", key, err)
+		}
+		fileOwned[key] = val
+		fileSource[key] = source[key]
+	}
+	return nil
+}
+
+// lookupUnowned returns an env var only if it was not set by LoadFile.
+// Callers must hold fileOwnedMu.
+func lookupUnowned(key string) (string, bool) {
+	cur, set := os.LookupEnv(key)
+	if !set {
+		return "", false
+	}
+	if prev, owned := fileOwned[key]; owned && cur == prev {
+		return "", false
+	}
+	return cur, true
+}
+
+// ParseFile decodes a config file: TOML for a .toml extension, YAML
+// otherwise. Unknown keys are rejected so typos surface at startup instead
+// of being silently ignored.
+func ParseFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		if data, err = tomlToYAML(data); err != nil {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// tomlToYAML re-encodes a TOML document as YAML, so both formats share the
+// field names and unknown-key checks of File's yaml tags
+func tomlToYAML(data []byte) ([]byte, error) {
+	var doc map[string]any
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc) == 0 {
+		return nil, nil
+	}
+	return yaml.Marshal(doc)
+}
+
+// env flattens the file into environment variable names and values
+func (f *File) env() map[string]string {
+	return map[string]string{
+		"APP_ENV": f.AppEnv,
+
+		"SOLANA_RPC_URL": f.RPC.URL,
+		"POLL_INTERVAL":  f.RPC.PollInterval,
+		"HTTP_TIMEOUT":   f.RPC.HTTPTimeout,
+		"MAX_RETRIES":    f.RPC.MaxRetries,
+		"RETRY_BACKOFF":  f.RPC.RetryBackoff,
+
+		"STREAM_PROVIDER":      f.Stream.Provider,
+		"TRITON_API_KEY":       f.Stream.TritonAPIKey,
+		"STREAM_STALL_TIMEOUT": f.Stream.StallTimeout,
+		"STREAM_COMMITMENT":    f.Stream.Commitment,
+		"STREAM_MODE":          f.Stream.Mode,
+
+		"REDIS_ADDR":        f.Redis.Addr,
+		"REDIS_USERNAME":    f.Redis.Username,
+		"REDIS_PASSWORD":    f.Redis.Password,
+		"REDIS_DB":          f.Redis.DB,
+		"REDIS_TLS":         f.Redis.TLS,
+		"PRICE_TTL":         f.Redis.PriceTTL,
+		"PRICE_STALE_AFTER": f.Redis.PriceStaleAfter,
+
+		"PRICE_HISTORY_WINDOW":     f.Redis.PriceHistoryWindow,
+		"PRICE_HISTORY_MAX_POINTS": f.Redis.PriceHistoryMaxPoints,
+		"RECENT_SWAPS_MAX":         f.Redis.RecentSwapsMax,
+		"SWAP_ENCODING":            f.Redis.SwapEncoding,
+		"CACHE_WARM_START":         f.Redis.WarmStart,
+
+		"CLICKHOUSE_ADDR":        f.ClickHouse.Addr,
+		"CLICKHOUSE_DATABASE":    f.ClickHouse.Database,
+		"CLICKHOUSE_USERNAME":    f.ClickHouse.Username,
+		"CLICKHOUSE_PASSWORD":    f.ClickHouse.Password,
+		"CLICKHOUSE_SLOW_INSERT": f.ClickHouse.SlowInsert,
+
+		"API_ADDR": f.API.Addr,
+		"API_KEY":  f.API.Key,
+		"API_KEYS": strings.Join(f.API.Keys, ","),
+		"DEV":      f.API.Dev,
+
+		"AI_RATE_LIMIT": f.API.AIRateLimit,
+		"AI_RATE_BURST": f.API.AIRateBurst,
+
+		"AI_MONTHLY_BUDGET_USD":        f.API.AIMonthlyBudgetUSD,
+		"AI_PROMPT_PRICE_PER_MTOK":     f.API.AIPromptPricePerMTok,
+		"AI_COMPLETION_PRICE_PER_MTOK": f.API.AICompletionPricePerMTok,
+
+		"READY_MAX_SLOT_LAG": f.API.ReadyMaxSlotLag,
+
+		"SWAP_API_ENABLED": f.API.SwapAPIEnabled,
+		"IDEMPOTENCY_TTL":  f.API.IdempotencyTTL,
+
+		"FLAGS_HISTORY_LIMIT": f.API.FlagsHistoryLimit,
+
+		"RESPONSE_CACHE_TTL":     f.API.ResponseCacheTTL,
+		"WALLET_STATS_CACHE_TTL": f.API.WalletStatsCacheTTL,
+		"MARKETS_CACHE_TTL":      f.API.MarketsCacheTTL,
+
+		"MAX_REQUEST_BODY_BYTES": f.API.MaxRequestBodyBytes,
+		"REQUEST_TIMEOUT":        f.API.RequestTimeout,
+		"AI_REQUEST_TIMEOUT":     f.API.AIRequestTimeout,
+		"QUOTE_REQUEST_TIMEOUT":  f.API.QuoteRequestTimeout,
+
+		"EXPORT_MAX_ROWS": f.API.ExportMaxRows,
+		"EXPORT_TIMEOUT":  f.API.ExportTimeout,
+
+		"SUBSCRIPTIONS_MAX_PER_KEY": f.API.SubscriptionsMaxPerKey,
+
+		"WEBHOOK_ALLOWED_HOSTS": strings.Join(f.API.WebhookAllowedHosts, ","),
+		"WEBHOOK_ALLOW_PRIVATE": f.API.WebhookAllowPrivate,
+
+		"TLS_CERT_FILE":          f.API.TLS.CertFile,
+		"TLS_KEY_FILE":           f.API.TLS.KeyFile,
+		"TLS_AUTOCERT_HOSTS":     strings.Join(f.API.TLS.AutocertHosts, ","),
+		"TLS_AUTOCERT_CACHE_DIR": f.API.TLS.AutocertCacheDir,
+		"TLS_AUTOCERT_EMAIL":     f.API.TLS.AutocertEmail,
+		"TLS_REDIRECT_ADDR":      f.API.TLS.RedirectAddr,
+
+		"GRPC_ADDR":        f.API.GRPC.Addr,
+		"GRPC_MAX_STREAMS": f.API.GRPC.MaxStreams,
+
+		"OPENROUTER_API_KEY": f.AI.OpenRouterAPIKey,
+		"AI_MODEL":           f.AI.Model,
+		"AI_ROUTER_MODEL":    f.AI.RouterModel,
+		"AI_MAX_LOOKBACK":    f.AI.MaxLookback,
+
+		"JUPITER_BASE_URL": f.Jupiter.BaseURL,
+		"JUPITER_API_KEY":  f.Jupiter.APIKey,
+
+		"JUPITER_TIMEOUT":         f.Jupiter.Timeout,
+		"JUPITER_MAX_RETRIES":     f.Jupiter.MaxRetries,
+		"JUPITER_RETRY_BACKOFF":   f.Jupiter.RetryBackoff,
+		"JUPITER_MAX_BACKOFF":     f.Jupiter.MaxBackoff,
+		"JUPITER_MAX_CONCURRENCY": f.Jupiter.MaxConcurrency,
+		"JUPITER_QUOTE_CACHE_TTL": f.Jupiter.QuoteCacheTTL,
+
+		"ARB_THRESHOLD_BPS":    f.Arb.ThresholdBps,
+		"ARB_MAX_PRICE_AGE":    f.Arb.MaxPriceAge,
+		"ARB_COOLDOWN":         f.Arb.Cooldown,
+		"ARB_JUPITER_INTERVAL": f.Arb.JupiterInterval,
+
+		"ANOMALY_STDDEVS":        f.Anomalies.StdDevs,
+		"ANOMALY_BASELINE_HOURS": f.Anomalies.BaselineHours,
+		"ANOMALY_MIN_HOURS":      f.Anomalies.MinHours,
+
+		"MEV_SCAN_INTERVAL": f.MEV.ScanInterval,
+		"MEV_LOOKBACK":      f.MEV.Lookback,
+		"MEV_SETTLE_DELAY":  f.MEV.SettleDelay,
+
+		"TICKER_INTERVAL": f.Ticker.Interval,
+
+		"CONSUMER_REORDER_WINDOW": f.Consumer.ReorderWindow,
+
+		"LOG_LEVEL":               f.Indexer.LogLevel,
+		"LOG_FORMAT":              f.Indexer.LogFormat,
+		"LOG_SAMPLE_INITIAL":      f.Indexer.LogSampleInitial,
+		"LOG_SAMPLE_THEREAFTER":   f.Indexer.LogSampleAfter,
+		"SIGNATURE_BATCH_SIZE":    f.Indexer.SignatureBatchSize,
+		"TX_FETCH_DELAY":          f.Indexer.TxFetchDelay,
+		"PROGRAM_ADDRESSES":       strings.Join(f.Indexer.ProgramAddresses, ","),
+		"INDEXER_LEADER_ELECTION": f.Indexer.LeaderElection,
+		"INDEXER_LEASE_TTL":       f.Indexer.LeaseTTL,
+		"INDEXER_INSTANCE_ID":     f.Indexer.InstanceID,
+		"METRICS_ADDR":            f.Indexer.MetricsAddr,
+		"INDEXER_DRAIN_TIMEOUT":   f.Indexer.DrainTimeout,
+
+		"INDEXER_FINALITY_INTERVAL":   f.Indexer.FinalityInterval,
+		"INDEXER_RECORD_FAILED_SWAPS": f.Indexer.RecordFailedSwaps,
+		"INDEXER_REGISTER_TOKENS":     f.Indexer.RegisterTokens,
+		"INDEXER_DEDUP_WINDOW":        f.Indexer.DedupWindow,
+		"INDEXER_DISABLED_STAGES":     strings.Join(f.Indexer.DisabledStages, ","),
+		"INDEXER_SINKS":               strings.Join(f.Indexer.Sinks, ","),
+		"INDEXER_BEST_EFFORT_SINKS":   strings.Join(f.Indexer.BestEffortSinks, ","),
+		"INDEXER_SINK_WEBHOOK_URL":    f.Indexer.SinkWebhookURL,
+		"INDEXER_SINK_FILE_PATH":      f.Indexer.SinkFilePath,
+		"INDEXER_SINK_CSV_PATH":       f.Indexer.SinkCSVPath,
+
+		"INDEXER_FILTER_MIN_AMOUNT":   f.Indexer.Filters.MinAmount,
+		"INDEXER_FILTER_ALLOW_TOKENS": strings.Join(f.Indexer.Filters.AllowTokens, ","),
+		"INDEXER_FILTER_DENY_TOKENS":  strings.Join(f.Indexer.Filters.DenyTokens, ","),
+		"INDEXER_FILTER_DEXES":        strings.Join(f.Indexer.Filters.Dexes, ","),
+
+		"WALLET_PRIVATE_KEY":       f.Wallet.PrivateKey,
+		"WALLET_COMMITMENT":        f.Wallet.Commitment,
+		"WALLET_BLOCKHASH_REFRESH": f.Wallet.BlockhashRefresh,
+
+		"SECRETS_PROVIDER":         f.Secrets.Provider,
+		"SECRETS_REFRESH_INTERVAL": f.Secrets.RefreshInterval,
+		"VAULT_ADDR":               f.Secrets.Vault.Addr,
+		"VAULT_SECRET_PATH":        f.Secrets.Vault.SecretPath,
+		"VAULT_NAMESPACE":          f.Secrets.Vault.Namespace,
+		"AWS_REGION":               f.Secrets.AWS.Region,
+		"SECRETS_AWS_SECRET_ID":    f.Secrets.AWS.SecretID,
+
+		"SWAPENGINE_POOL_CONFIG_PATH":      f.SwapEngine.PoolConfigPath,
+		"SWAPENGINE_POOL_SOURCE":           f.SwapEngine.PoolSource,
+		"SWAPENGINE_POOL_STRICT":           f.SwapEngine.PoolStrict,
+		"SWAPENGINE_REQUIRE_SIMULATION":    f.SwapEngine.RequireSimulation,
+		"SWAPENGINE_COMPUTE_BUDGET":        f.SwapEngine.ComputeBudget.Enabled,
+		"SWAPENGINE_CU_MARGIN":             f.SwapEngine.ComputeBudget.Margin,
+		"SWAPENGINE_PRIORITY_FEE_LAMPORTS": f.SwapEngine.ComputeBudget.PriorityFeeLamports,
+		"SWAPENGINE_MAX_SWAP_AMOUNT_SOL":   f.SwapEngine.Risk.MaxSwapAmountSOL,
+		"SWAPENGINE_DAILY_LIMIT_SOL":       f.SwapEngine.Risk.DailyLimitSOL,
+		"SWAPENGINE_MAX_PRICE_IMPACT_BPS":  f.SwapEngine.Risk.MaxPriceImpactBps,
+		"SWAPENGINE_DEFAULT_SLIPPAGE_BPS":  f.SwapEngine.Risk.DefaultSlippageBps,
+		"SWAPENGINE_MAX_SLIPPAGE_BPS":      f.SwapEngine.Risk.MaxSlippageBps,
+		"SWAPENGINE_ALLOWED_TOKENS":        strings.Join(f.SwapEngine.Risk.AllowedTokens, ","),
+		"SWAPENGINE_MIN_BALANCE_SOL":       f.SwapEngine.Risk.MinBalanceSOL,
+		"SWAPENGINE_FAILURE_COOLDOWN":      f.SwapEngine.Risk.FailureCooldown,
+
+		"SWAPENGINE_MAX_ROUTE_HOPS": f.SwapEngine.Risk.MaxRouteHops,
+		"SWAPENGINE_EXCLUDED_DEXES": strings.Join(f.SwapEngine.Risk.ExcludedDexes, ","),
+		"SWAPENGINE_WALLET_LIMITS":  strings.Join(f.SwapEngine.Risk.WalletLimits, ","),
+
+		"SWAPENGINE_DISCOVERY_ENABLED":     f.SwapEngine.Discovery.Enabled,
+		"SWAPENGINE_DISCOVERY_PROGRAMS":    strings.Join(f.SwapEngine.Discovery.Programs, ","),
+		"SWAPENGINE_DISCOVERY_MINTS":       strings.Join(f.SwapEngine.Discovery.Mints, ","),
+		"SWAPENGINE_DISCOVERY_MIN_RESERVE": f.SwapEngine.Discovery.MinReserve,
+		"SWAPENGINE_DISCOVERY_INTERVAL":    f.SwapEngine.Discovery.Interval,
+	}
+}
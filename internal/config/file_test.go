@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, body string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	return path
+}
+
+func TestLoadFile_EnvTakesPrecedence(t *testing.T) {
+	path := writeConfigFile(t, `
+rpc:
+  poll_interval: 45s
+redis:
+  addr: file-redis:6379
+swapengine:
+  risk:
+    allowed_tokens: [SOL, USDC]
+`)
+
+	t.Setenv("REDIS_ADDR", "env-redis:6379")
+	t.Setenv("POLL_INTERVAL", "")
+	os.Unsetenv("POLL_INTERVAL")
+	t.Setenv("SWAPENGINE_ALLOWED_TOKENS", "")
+	os.Unsetenv("SWAPENGINE_ALLOWED_TOKENS")
+
+	require.NoError(t, LoadFile(path))
+
+	assert.Equal(t, "env-redis:6379", os.Getenv("REDIS_ADDR"))
+	assert.Equal(t, "45s", os.Getenv("POLL_INTERVAL"))
+	assert.Equal(t, "SOL,USDC", os.Getenv("SWAPENGINE_ALLOWED_TOKENS"))
+}
+
+func TestLoadFile_EmptyPathIsNoop(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	assert.NoError(t, LoadFile(""))
+}
+
+func TestParseFile_RejectsUnknownKeys(t *testing.T) {
+	path := writeConfigFile(t, `
+redis:
+  adress: localhost:6379
+`)
+
+	_, err := ParseFile(path)
+	assert.Error(t, err)
+}
+
+func TestParseFile_TOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+app_env = "staging"
+
+[rpc]
+poll_interval = "45s"
+max_retries = 5
+
+[api]
+keys = ["k1", "k2"]
+dev = true
+
+[swapengine.risk]
+allowed_tokens = ["SOL", "USDC"]
+`), 0o600))
+
+	f, err := ParseFile(path)
+	require.NoError(t, err)
+	env := f.env()
+	assert.Equal(t, "staging", env["APP_ENV"])
+	assert.Equal(t, "45s", env["POLL_INTERVAL"])
+	assert.Equal(t, "5", env["MAX_RETRIES"])
+	assert.Equal(t, "k1,k2", env["API_KEYS"])
+	assert.Equal(t, "true", env["DEV"])
+	assert.Equal(t, "SOL,USDC", env["SWAPENGINE_ALLOWED_TOKENS"])
+
+	require.NoError(t, os.WriteFile(path, []byte("[redis]\nadress = \"localhost:6379\"\n"), 0o600))
+	_, err = ParseFile(path)
+	assert.Error(t, err, "unknown keys are rejected")
+
+	require.NoError(t, os.WriteFile(path, []byte("rpc:\n  poll_interval: 45s\n"), 0o600))
+	_, err = ParseFile(path)
+	assert.Error(t, err, "YAML in a .toml file")
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// Environment profiles selected by APP_ENV
+const (
+	ProfileDev     = "dev"
+	ProfileStaging = "staging"
+	ProfileProd    = "prod"
+)
+
+// publicMainnetRPC is fine for development but rate-limits hard under real load,
+// so prod has no RPC default and must set SOLANA_RPC_URL explicitly
+const publicMainnetRPC = "https://api.mainnet-beta.solana.com"
+
+// profiles hold per-environment defaults. They sit below the config file in
+// precedence (CLI > env > config file > profile), so a profile only decides
+// what a deployment did not.
+var profiles = map[string]map[string]string{
+	ProfileDev: {
+		"SOLANA_RPC_URL":       publicMainnetRPC,
+		"POLL_INTERVAL":        "30s",
+		"HTTP_TIMEOUT":         "30s",
+		"MAX_RETRIES":          "3",
+		"RETRY_BACKOFF":        "1s",
+		"STREAM_PROVIDER":      "rpc",
+		"SIGNATURE_BATCH_SIZE": "3",
+		"TX_FETCH_DELAY":       "3s",
+		"REDIS_ADDR":           "localhost:6379",
+		"CLICKHOUSE_ADDR":      "localhost:9000",
+		"CLICKHOUSE_DATABASE":  "solana",
+		"API_ADDR":             ":8090",
+		"DEV":                  "true",
+		"LOG_LEVEL":            "debug",
+		"AI_RATE_LIMIT":        "1",
+		"AI_RATE_BURST":        "5",
+
+		"SWAPENGINE_REQUIRE_SIMULATION": "false",
+	},
+	ProfileStaging: {
+		"SOLANA_RPC_URL":       publicMainnetRPC,
+		"POLL_INTERVAL":        "15s",
+		"HTTP_TIMEOUT":         "20s",
+		"MAX_RETRIES":          "5",
+		"RETRY_BACKOFF":        "2s",
+		"STREAM_PROVIDER":      "rpc",
+		"SIGNATURE_BATCH_SIZE": "5",
+		"TX_FETCH_DELAY":       "1s",
+		"DEV":                  "false",
+		"LOG_LEVEL":            "info",
+		"AI_RATE_LIMIT":        "0.2",
+		"AI_RATE_BURST":        "2",
+
+		"SWAPENGINE_REQUIRE_SIMULATION": "true",
+	},
+	ProfileProd: {
+		"POLL_INTERVAL":        "10s",
+		"HTTP_TIMEOUT":         "15s",
+		"MAX_RETRIES":          "5",
+		"RETRY_BACKOFF":        "2s",
+		"SIGNATURE_BATCH_SIZE": "10",
+		"TX_FETCH_DELAY":       "500ms",
+		"DEV":                  "false",
+		"LOG_LEVEL":            "info",
+		"AI_RATE_LIMIT":        "0.2",
+		"AI_RATE_BURST":        "2",
+
+		"SWAPENGINE_REQUIRE_SIMULATION": "true",
+	},
+}
+
+// NormalizeProfile maps APP_ENV spellings onto a profile name; empty means no profile
+func NormalizeProfile(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "":
+		return "", nil
+	case "dev", "development", "local":
+		return ProfileDev, nil
+	case "staging", "stage":
+		return ProfileStaging, nil
+	case "prod", "production":
+		return ProfileProd, nil
+	default:
+		return "", fmt.Errorf("unknown APP_ENV %q (want dev, staging or prod)", name)
+	}
+}
+
+// profileDefaults returns a copy of the defaults for an APP_ENV value
+func profileDefaults(appEnv string) (map[string]string, error) {
+	name, err := NormalizeProfile(appEnv)
+	if err != nil || name == "" {
+		return nil, err
+	}
+	return maps.Clone(profiles[name]), nil
+}
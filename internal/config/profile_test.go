@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unsetEnv clears keys for the duration of a test
+func unsetEnv(t *testing.T, keys ...string) {
+	for _, k := range keys {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+}
+
+// forgetLoaded undoes everything LoadFile exported once the test ends
+func forgetLoaded(t *testing.T) {
+	t.Cleanup(func() {
+		fileOwnedMu.Lock()
+		defer fileOwnedMu.Unlock()
+		for k := range fileOwned {
+			os.Unsetenv(k)
+			delete(fileOwned, k)
+			delete(fileSource, k)
+		}
+	})
+}
+
+func TestLoadFile_ProfileFillsGaps(t *testing.T) {
+	unsetEnv(t, "CONFIG_FILE", "POLL_INTERVAL", "SOLANA_RPC_URL", "DEV", "TX_FETCH_DELAY")
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("TX_FETCH_DELAY", "2s")
+	forgetLoaded(t)
+
+	require.NoError(t, LoadFile(""))
+
+	assert.Equal(t, "10s", os.Getenv("POLL_INTERVAL"))
+	assert.Equal(t, "false", os.Getenv("DEV"))
+	assert.Equal(t, "2s", os.Getenv("TX_FETCH_DELAY"), "env must win over profile")
+	_, set := os.LookupEnv("SOLANA_RPC_URL")
+	assert.False(t, set, "prod has no RPC default")
+}
+
+func TestLoadFile_FileWinsOverProfile(t *testing.T) {
+	unsetEnv(t, "APP_ENV", "POLL_INTERVAL", "LOG_LEVEL")
+	forgetLoaded(t)
+	path := writeConfigFile(t, `
+app_env: dev
+rpc:
+  poll_interval: 1m
+`)
+
+	require.NoError(t, LoadFile(path))
+
+	assert.Equal(t, "1m", os.Getenv("POLL_INTERVAL"))
+	assert.Equal(t, "debug", os.Getenv("LOG_LEVEL"))
+}
+
+func TestLoadFile_UnknownProfile(t *testing.T) {
+	unsetEnv(t, "CONFIG_FILE")
+	t.Setenv("APP_ENV", "qa")
+
+	assert.Error(t, LoadFile(""))
+}
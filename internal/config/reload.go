@@ -0,0 +1,174 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// ReloadChannel is the Redis Pub/Sub channel used to ask running services to
+// reload their configuration (see RequestReload)
+const ReloadChannel = "config:reload"
+
+// ReloadHook is called after every successful reload with the previous and the
+// freshly loaded configuration. Hooks read settings that are not part of Config
+// (e.g. swap engine risk limits) straight from the refreshed environment.
+type ReloadHook func(prev, next *Config)
+
+// Reloader re-reads the config file and environment on SIGHUP or on a message
+// published to ReloadChannel (see ReloadPublisher), and hands the result to
+// registered hooks. Only settings a hook explicitly applies change at runtime; everything else
+// (connections, addresses, credentials) still needs a restart.
+type Reloader struct {
+	path   string
+	logger *logrus.Logger
+
+	mu      sync.Mutex
+	current *Config
+	hooks   []ReloadHook
+}
+
+// NewReloader creates a reloader seeded with the config the process started with
+func NewReloader(path string, cfg *Config, logger *logrus.Logger) *Reloader {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &Reloader{path: path, logger: logger, current: cfg}
+}
+
+// OnReload registers a hook to run after each successful reload
+func (r *Reloader) OnReload(hook ReloadHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, hook)
+}
+
+// Current returns the most recently loaded configuration
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload re-reads the config file and environment. An invalid result is
+// logged and rejected, leaving the running configuration untouched.
+func (r *Reloader) Reload(source string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := LoadFile(r.path); err != nil {
+		r.logger.WithError(err).WithField("source", source).Error("config reload failed")
+		return err
+	}
+
+	next, err := TryLoad()
+	if err != nil {
+		r.logger.WithError(err).WithField("source", source).Error("config reload rejected")
+		return err
+	}
+
+	prev := r.current
+	r.current = next
+
+	r.logger.WithFields(logrus.Fields{
+		"event":   "config_changed",
+		"source":  source,
+		"changed": ChangedKeys(prev, next),
+	}).Info("configuration reloaded")
+
+	for _, hook := range r.hooks {
+		hook(prev, next)
+	}
+	return nil
+}
+
+// Run reloads on SIGHUP and, if client is non-nil, on messages published to
+// ReloadChannel. It blocks until ctx is cancelled.
+func (r *Reloader) Run(ctx context.Context, client *redis.Client) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var msgs <-chan *redis.Message
+	if client != nil {
+		pubsub := client.Subscribe(ctx, ReloadChannel)
+		defer pubsub.Close()
+		msgs = pubsub.Channel()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			_ = r.Reload("sighup")
+		case _, ok := <-msgs:
+			if !ok {
+				msgs = nil
+				continue
+			}
+			_ = r.Reload("admin")
+		}
+	}
+}
+
+// ReloadPublisher broadcasts reload requests to every service running a Reloader
+type ReloadPublisher struct {
+	client redis.Cmdable
+}
+
+// NewReloadPublisher creates a publisher on the given Redis client
+func NewReloadPublisher(client redis.Cmdable) *ReloadPublisher {
+	return &ReloadPublisher{client: client}
+}
+
+// RequestReload publishes to ReloadChannel and returns how many services
+// received the request
+func (p *ReloadPublisher) RequestReload(ctx context.Context) (int64, error) {
+	n, err := p.client.Publish(ctx, ReloadChannel, "reload").Result()
+	if err != nil {
+		return 0, fmt.Errorf("publish config reload: %w", err)
+	}
+	return n, nil
+}
+
+// ChangedKeys lists the env names of runtime-reloadable settings that differ
+// between two configs
+func ChangedKeys(prev, next *Config) []string {
+	changed := []string{}
+	if prev == nil || next == nil {
+		return changed
+	}
+	if prev.PollInterval != next.PollInterval {
+		changed = append(changed, "POLL_INTERVAL")
+	}
+	if !slices.Equal(prev.ProgramAddresses, next.ProgramAddresses) {
+		changed = append(changed, "PROGRAM_ADDRESSES")
+	}
+	if prev.SignatureBatchSize != next.SignatureBatchSize {
+		changed = append(changed, "SIGNATURE_BATCH_SIZE")
+	}
+	if prev.TxFetchDelay != next.TxFetchDelay {
+		changed = append(changed, "TX_FETCH_DELAY")
+	}
+	if prev.FilterMinAmount != next.FilterMinAmount {
+		changed = append(changed, "INDEXER_FILTER_MIN_AMOUNT")
+	}
+	if !slices.Equal(prev.FilterAllowTokens, next.FilterAllowTokens) {
+		changed = append(changed, "INDEXER_FILTER_ALLOW_TOKENS")
+	}
+	if !slices.Equal(prev.FilterDenyTokens, next.FilterDenyTokens) {
+		changed = append(changed, "INDEXER_FILTER_DENY_TOKENS")
+	}
+	if !slices.Equal(prev.FilterDexes, next.FilterDexes) {
+		changed = append(changed, "INDEXER_FILTER_DEXES")
+	}
+	return changed
+}
@@ -4,19 +4,288 @@ import "time"
 
 // Redis keys
 const (
-	RedisKeyRecentSwaps = "swaps:recent"
-	RedisKeyPricePrefix = "price:"
+	RedisKeyRecentSwaps        = "swaps:recent"
+	RedisKeyRecentPairPrefix   = "swaps:recent:" // per-pair list, e.g. swaps:recent:SOL/USDC
+	RedisKeyPricePrefix        = "price:"
+	RedisKeyPriceHistoryPrefix = "price:history:"        // sorted set scored by unix millis
+	RedisKeyMarketsPrefix      = "price:markets:"        // hash of per-venue prices, field quote|dex|pool
+	RedisKeyMarketVolumePrefix = "price:markets:volume:" // per-venue volume, one hash per window bucket
 )
 
 // Redis Pub/Sub channels
 const (
-	PubSubChannelSwaps = "swaps:live"
+	PubSubChannelSwaps     = "swaps:live"
+	PubSubChannelArb       = "arb:opportunities" // cross-venue price gaps (JSON models.ArbOpportunity)
+	PubSubChannelAnomalies = "alerts:anomalies"  // volume and trade-count spikes (JSON models.Anomaly)
+	PubSubChannelTicker    = "swaps:ticker:"     // prefix of per-pair ticker channels (JSON models.Ticker)
+	PubSubChannelWallet    = "swaps:wallet:"     // prefix of per-wallet swap channels, e.g. swaps:wallet:<address>
+
+	// swaps withdrawn because their fork was dropped (JSON models.SwapRetraction)
+	PubSubChannelRetractions = "swaps:retracted"
+
+	// hourly and daily subscription summaries (JSON models.Digest)
+	PubSubChannelDigests = "alerts:digests"
+)
+
+// Warm start of an empty Redis cache from ClickHouse (CACHE_WARM_START)
+const (
+	RedisKeyWarmStartLock = "cache:warmstart:lock" // held by the process filling the cache
+	WarmStartLockTTL      = time.Minute
+	WarmStartWindow       = 24 * time.Hour   // only pairs traded this recently get a recent list
+	WarmStartTimeout      = 30 * time.Second // startup waits this long at most
+)
+
+// Redis Streams
+const (
+	RedisStreamSwaps    = "swaps:stream"
+	StreamMaxLen        = 100000 // approximate cap on retained swap entries
+	StreamDefaultGroup  = "swap-consumers"
+	StreamClaimMinIdle  = time.Minute // pending entries idle this long are retried by another consumer
+	StreamReadBlock     = 5 * time.Second
+	StreamReadBatchSize = 100
+)
+
+// Dead-letter queue for swaps a sink failed to accept
+const (
+	RedisKeyDeadLetters       = "swaps:dlq"
+	DeadLetterRedriveInterval = 30 * time.Second
+	DeadLetterRedriveBatch    = 100
+
+	RedisKeyDeadLetterClaimPrefix = "swaps:dlq:claimed:" // list of the entries one redriver holds
+	RedisKeyDeadLetterClaims      = "swaps:dlq:claims"   // sorted set of redrivers by last ack, unix millis
+	DeadLetterClaimTimeout        = 5 * time.Minute      // a claim silent this long goes back to the queue
+)
+
+// Swap event encodings (SWAP_ENCODING)
+const (
+	SwapEncodingJSON     = "json"
+	SwapEncodingMsgpack  = "msgpack"
+	SwapEncodingProtobuf = "protobuf" // proto/swapindexer/v1/swap.proto
+)
+
+// ReplayDefaultRate is how many swaps per second `indexer replay` publishes by default
+const ReplayDefaultRate = 100
+
+// DrainTimeout bounds how long a stopping indexer waits for its in-flight swap
+const DrainTimeout = 30 * time.Second
+
+// DedupWindow is how many recently indexed swaps the indexer remembers to
+// drop redeliveries of
+const DedupWindow = 10000
+
+// StreamCommitment is the commitment the poller reads signatures, transactions
+// and the chain tip at
+const StreamCommitment = "confirmed"
+
+// Finality tracking of swaps indexed at confirmed commitment: they are
+// checked every FinalityInterval until finalized or retracted, for at most
+// FinalityTimeout (a block finalizes about 13s after it is confirmed)
+const (
+	FinalityInterval    = 10 * time.Second
+	FinalityTimeout     = 5 * time.Minute
+	FinalityMaxTracked  = 100000 // swaps tracked at once; past it new swaps stay unfinalized
+	FinalityStatusBatch = 256    // signatures per getSignatureStatuses call, the RPC maximum
+)
+
+// StreamMode is the default ingestion mode of the poller (see stream.ModeSignatures)
+const StreamMode = "signatures"
+
+// MaxBlocksPerPoll caps the blocks read per poll in the blocks stream mode,
+// so a poller far behind the tip still checkpoints and beats regularly
+const MaxBlocksPerPoll = 100
+
+// ClickHouseSlowInsert is how long a ClickHouse insert may take before it is
+// logged as slow
+const ClickHouseSlowInsert = 500 * time.Millisecond
+
+// StreamStallTimeout is how long the stream provider may go without a swap or
+// a successful poll before it is restarted
+const StreamStallTimeout = 5 * time.Minute
+
+// Leader election and shared poller checkpoints
+const (
+	RedisKeyLeaderPrefix     = "leader:indexer:"          // lease per program address
+	RedisKeyCheckpointPrefix = "indexer:checkpoint:"      // newest handled signature per program address
+	RedisKeyProgramsAdded    = "indexer:programs:added"   // program addresses added through the admin API
+	RedisKeyProgramsRemoved  = "indexer:programs:removed" // program addresses removed through the admin API
+	LeaderLeaseTTL           = 15 * time.Second
+)
+
+// Pair and token subscriptions of API keys (/v1/subscriptions)
+const (
+	RedisKeySubscriptionsPrefix = "subscriptions:"       // hash of ID to JSON models.Subscription per owner
+	RedisKeySubscriptionOwners  = "subscriptions:owners" // owners with at least one subscription
+	SubscriptionsMaxPerKey      = 50
+	SubscriptionsRefresh        = 30 * time.Second // how often the webhook router rereads them
+	SubscriptionWebhookTimeout  = 5 * time.Second
+	SubscriptionWebhookWorkers  = 4
+
+	RedisKeyDigestSentPrefix = "digests:sent:" // claim of one period's digests, e.g. digests:sent:hourly:<unix end>
+	DigestSentTTL            = 48 * time.Hour
+	DigestQueryTimeout       = 30 * time.Second // per subscription
+)
+
+// RedisKeyTokenDecimals is a hash of SPL mint address to decimals, filled by
+// the token resolver on first sight of a mint
+const RedisKeyTokenDecimals = "tokens:decimals"
+
+// RedisKeyTokenMetadata is a hash of SPL mint address to JSON
+// models.TokenMetadata, filled by the token registry for mints outside
+// TokenSymbols
+const RedisKeyTokenMetadata = "tokens:metadata"
+
+// RedisKeyTokenRelabelPrefix claims, per mint, the relabel of its stored
+// swaps for one process: it expires after TokenRelabelTimeout while the
+// relabel runs and is kept once it is done
+const RedisKeyTokenRelabelPrefix = "tokens:relabel:"
+
+// Token registry
+const (
+	TokenRegistryQueueSize = 256              // unknown mints waiting for a metadata lookup
+	TokenBackfillDelay     = 30 * time.Second // wait for in-flight swaps to land before relabeling stored ones
+	TokenRelabelTimeout    = 10 * time.Minute // a relabel's copy and its delete, waited for on every replica
+)
+
+// Indexer status reports (GET /v1/admin/indexer/status)
+const (
+	RedisKeyIndexerStatusPrefix = "indexer:status:" // one JSON report per replica
+	IndexerStatusInterval       = 10 * time.Second
+	ReadyMaxSlotLag             = 300 // /readyz fails beyond this lag (about two minutes of slots)
+)
+
+// Idempotency-Key records for POST /v1/swap/execute
+const (
+	RedisKeyIdempotencyPrefix = "idempotency:"
+	IdempotencyTTL            = 24 * time.Hour // how long a key is remembered after its request finished
+)
+
+// AI quotas per API key (AI_RATE_LIMIT, AI_MONTHLY_BUDGET_USD). Prices are
+// USD per million tokens, those of the default model openai/gpt-4.1-mini.
+const (
+	RedisKeyAIRatePrefix     = "ai:rate:"   // GCRA arrival time per key
+	RedisKeyAIBudgetPrefix   = "ai:budget:" // micro-dollars spent per month and key, e.g. ai:budget:2025-03:<key>
+	AIBudgetRetention        = 40 * 24 * time.Hour
+	AIRateLimit              = 0.2 // requests per second
+	AIRateBurst              = 2
+	AIPromptPricePerMTok     = 0.40
+	AICompletionPricePerMTok = 1.60
+)
+
+// AIMaxLookback bounds generated SQL that has no time filter (AI_MAX_LOOKBACK)
+const AIMaxLookback = 90 * 24 * time.Hour
+
+// API request limits (defaults of MAX_REQUEST_BODY_BYTES and the *_TIMEOUT settings)
+const (
+	MaxRequestBodyBytes = 1 << 20 // larger bodies are refused with 413
+	RequestTimeout      = 30 * time.Second
+	AIRequestTimeout    = 60 * time.Second // LLM round trips plus ClickHouse
+	QuoteRequestTimeout = 12 * time.Second // Jupiter, retries included
+)
+
+// Native HTTPS (TLS_AUTOCERT_CACHE_DIR)
+const AutocertCacheDir = "certs" // Let's Encrypt account and certificates
+
+// API micro-cache for hot read endpoints (RESPONSE_CACHE_TTL)
+const (
+	RedisKeyResponseCachePrefix = "api:cache:" // one response per path and query
+	ResponseCacheMaxTTL         = time.Second  // longer would serve visibly stale swaps
+)
+
+// Wallet profiles (WALLET_STATS_CACHE_TTL): ClickHouse aggregations over days
+// of swaps, shared between callers for this long
+const (
+	WalletStatsCacheTTL    = 30 * time.Second
+	WalletStatsCacheMaxTTL = 10 * time.Minute
+)
+
+// Market listings (MARKETS_CACHE_TTL): /v1/pairs and /v1/dexes scan many stored
+// swaps, so answers are shared between callers for this long
+const (
+	MarketsCacheTTL    = 30 * time.Second
+	MarketsCacheMaxTTL = 10 * time.Minute
+)
+
+// Swap exports (EXPORT_MAX_ROWS, EXPORT_TIMEOUT): /v1/export/swaps streams
+// straight from ClickHouse, so each request is capped in rows and time
+const (
+	ExportMaxRows = 100_000
+	ExportTimeout = 5 * time.Minute
+)
+
+// Arbitrage detector (ARB_* settings)
+const (
+	RedisKeyArbRecent  = "arb:recent" // newest opportunities first
+	ArbRecentMax       = 200          // opportunities kept for GET /v1/arb/opportunities
+	ArbThresholdBps    = 50.0         // spreads below this are ignored
+	ArbMaxPriceAge     = 30 * time.Second
+	ArbCooldown        = 30 * time.Second // between reports of the same pair and venues
+	ArbJupiterInterval = 10 * time.Second // per-pair Jupiter quote refresh
+)
+
+// Volume anomaly detector (ANOMALY_* settings)
+const (
+	RedisKeyAnomaliesRecent = "anomalies:recent" // newest anomalies first
+	AnomaliesRecentMax      = 500                // anomalies kept for GET /v1/anomalies
+	AnomalyStdDevs          = 3.0                // spikes must exceed the hourly mean by this many standard deviations
+	AnomalyBaselineHours    = 24                 // completed hours in each pair's baseline
+	AnomalyMinHours         = 6                  // hours of baseline needed before a pair is judged
+)
+
+// Sandwich scanner (MEV_* settings)
+const (
+	MEVScanInterval = time.Minute
+	MEVLookback     = time.Hour        // first scan without a checkpoint
+	MEVSettleDelay  = 30 * time.Second // swaps younger than this wait for the next scan
+)
+
+// Per-pair ticker (TICKER_* settings)
+const (
+	TickerInterval = 5 * time.Second // between ticker rounds
+	TickerWindow   = time.Minute     // volume and trade-count window
+)
+
+// Pub/Sub consumers (CONSUMER_* settings)
+const (
+	ConsumerMaxReorderWindow = 30 * time.Second // CONSUMER_REORDER_WINDOW at most
+)
+
+// gRPC API (GRPC_* settings)
+const (
+	GRPCMaxStreams = 1000 // concurrent SubscribeSwaps/SubscribePrices streams per process
+)
+
+// Jupiter client defaults (JUPITER_* settings)
+const (
+	JupiterTimeout        = 12 * time.Second
+	JupiterMaxRetries     = 2
+	JupiterRetryBackoff   = 250 * time.Millisecond
+	JupiterMaxBackoff     = 5 * time.Second
+	JupiterMaxConcurrency = 16
+)
+
+// Jupiter quote cache (GET /v1/quote)
+const (
+	RedisKeyQuotePrefix = "jupiter:quote:" // one JSON quote per request hash
+	QuoteCacheTTL       = time.Second      // dashboards polling the same quote share one Jupiter call
+	QuoteCacheMaxTTL    = 10 * time.Second // quotes go stale with the next few slots
+)
+
+// Price freshness
+const (
+	PriceTTL        = 15 * time.Minute // Redis drops a price this long after its last update
+	PriceStaleAfter = 2 * time.Minute  // Older prices are served with stale=true
+
+	PriceHistoryWindow    = time.Hour // Points older than this are trimmed from the rolling history
+	PriceHistoryMaxPoints = 720       // Newest points kept per token (one every 5s over an hour)
+
+	MarketVolumeWindow = 15 * time.Minute // Per-venue volume weighting GET /v1/prices/:token/markets
 )
 
 // Limits
 const (
-	MaxRecentSwaps     = 100
-	SignatureBatchSize = 3 // Reduced to avoid rate limits on public RPC
+	MaxRecentSwaps      = 100    // default length of each recent swaps list
+	MemoryStoreMaxSwaps = 100000 // swaps an in-memory store keeps before dropping the oldest
+	SignatureBatchSize  = 3      // Reduced to avoid rate limits on public RPC
 )
 
 // Rate limiting
@@ -42,6 +311,24 @@ var ProgramAddresses = map[string]string{
 	"OrcaWhirlpool": "whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc",
 }
 
+// Transaction fees
+const (
+	ComputeBudgetProgram = "ComputeBudget111111111111111111111111111111"
+	LamportsPerSignature = 5000 // base fee; anything a transaction pays above it is priority fee
+)
+
+// TokenDecimals seeds the token resolver with the decimals of well-known
+// mints; any other mint is read from chain on first sight
+var TokenDecimals = map[string]uint8{
+	"So11111111111111111111111111111111111111112":  9, // SOL
+	"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": 6, // USDC
+	"Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB": 6, // USDT
+	"mSoLzYCxHdYgdzU16g5QSh3i5K3z3KZK7ytfqcJm7So":  9, // mSOL
+	"DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263": 5, // BONK
+	"JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN":  6, // JUP
+	"4k3Dyjzvzp8eMZWUXbBCjEvwSkkk59S5iCNLY3QrkX6R": 6, // RAY
+}
+
 // Token mint addresses to symbols
 var TokenSymbols = map[string]string{
 	"So11111111111111111111111111111111111111112":  "SOL",
@@ -74,6 +361,47 @@ var TokenSymbols = map[string]string{
 	"P1K5H7P3V2D4N6B8X2V4J5K3L1H6F2Y3D5T7C4R9":     "MNGO-SOL LP",
 }
 
+// Token categories in the ClickHouse tokens table; mints registered from
+// chain have none
+const (
+	TokenCategoryNative     = "native"
+	TokenCategoryStablecoin = "stablecoin"
+	TokenCategoryStaking    = "liquid-staking"
+	TokenCategoryWrapped    = "wrapped"
+	TokenCategoryDeFi       = "defi"
+	TokenCategoryMeme       = "meme"
+	TokenCategoryLP         = "lp"
+)
+
+// TokenDetail names and classifies a symbol of TokenSymbols
+type TokenDetail struct {
+	Name     string
+	Category string
+}
+
+// TokenDetails describes the symbols of TokenSymbols; symbols ending in
+// " LP" are TokenCategoryLP
+var TokenDetails = map[string]TokenDetail{
+	"SOL":    {"Wrapped SOL", TokenCategoryNative},
+	"USDC":   {"USD Coin", TokenCategoryStablecoin},
+	"USDT":   {"Tether USD", TokenCategoryStablecoin},
+	"mSOL":   {"Marinade staked SOL", TokenCategoryStaking},
+	"ETH":    {"Ether (Wormhole)", TokenCategoryWrapped},
+	"BTC":    {"Bitcoin (Wormhole)", TokenCategoryWrapped},
+	"BTC-w":  {"Wrapped Bitcoin", TokenCategoryWrapped},
+	"ADA":    {"Cardano", TokenCategoryWrapped},
+	"DOGE":   {"Dogecoin", TokenCategoryWrapped},
+	"BONK":   {"Bonk", TokenCategoryMeme},
+	"POPCAT": {"Popcat", TokenCategoryMeme},
+	"KIN":    {"Kin", TokenCategoryMeme},
+	"JUP":    {"Jupiter", TokenCategoryDeFi},
+	"RAY":    {"Raydium", TokenCategoryDeFi},
+	"SRM":    {"Serum", TokenCategoryDeFi},
+	"FTT":    {"FTX Token", TokenCategoryDeFi},
+	"MNGO":   {"Mango", TokenCategoryDeFi},
+	"COPE":   {"Cope", TokenCategoryDeFi},
+}
+
 // Pool names by DEX
 const (
 	PoolJupiterAgg = "JupiterAggregator"
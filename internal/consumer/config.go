@@ -0,0 +1,124 @@
+package consumer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// FileConfig is a consumer described in YAML:
+//
+//	workers: 4
+//	reorder_window: 2s
+//	routes:
+//	  - channel: swaps:live
+//	    pairs: [SOL/USDC]
+//	    sinks:
+//	      - type: stdout
+//	      - type: webhook
+//	        url: https://example.com/hook
+type FileConfig struct {
+	Workers       int           `yaml:"workers"`
+	QueueSize     int           `yaml:"queue_size"`
+	ReorderWindow time.Duration `yaml:"reorder_window"` // see Config.ReorderWindow
+	Routes        []RouteConfig `yaml:"routes"`
+}
+
+// RouteConfig sends the messages of one channel or pattern to a set of sinks
+type RouteConfig struct {
+	Channel string       `yaml:"channel"` // channel or glob pattern (default swaps:live)
+	Pairs   []string     `yaml:"pairs"`   // only swaps of these pairs (default: all)
+	Sinks   []SinkConfig `yaml:"sinks"`
+}
+
+// LoadConfig reads a consumer config file; unknown keys are rejected
+func LoadConfig(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read consumer config: %w", err)
+	}
+
+	var fc FileConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse consumer config %s: %w", path, err)
+	}
+	if len(fc.Routes) == 0 {
+		return nil, fmt.Errorf("consumer config %s: no routes", path)
+	}
+	return &fc, nil
+}
+
+// Build creates the consumer and its sinks. The returned func closes the
+// sinks once the consumer has stopped.
+func (fc *FileConfig) Build(logger *logrus.Logger) (*Consumer, func() error, error) {
+	c := New(Config{Workers: fc.Workers, QueueSize: fc.QueueSize, ReorderWindow: fc.ReorderWindow, Logger: logger})
+
+	var sinks []Sink
+	closeAll := func() error {
+		var errs []error
+		for _, s := range sinks {
+			errs = append(errs, s.Close())
+		}
+		return errors.Join(errs...)
+	}
+
+	for i, rc := range fc.Routes {
+		if len(rc.Sinks) == 0 {
+			_ = closeAll()
+			return nil, nil, fmt.Errorf("route %d: no sinks", i)
+		}
+		channel := rc.Channel
+		if channel == "" {
+			channel = constants.PubSubChannelSwaps
+		}
+
+		var routeSinks []Sink
+		for _, sc := range rc.Sinks {
+			s, err := NewSink(sc)
+			if err != nil {
+				_ = closeAll()
+				return nil, nil, fmt.Errorf("route %d (%s): %w", i, channel, err)
+			}
+			sinks = append(sinks, s)
+			routeSinks = append(routeSinks, s)
+		}
+		c.Handle(channel, fanOut(routeSinks, rc.Pairs))
+	}
+	return c, closeAll, nil
+}
+
+// fanOut hands a message to every sink, optionally only swaps of some pairs
+func fanOut(sinks []Sink, pairs []string) Handler {
+	return func(ctx context.Context, msg *Message) error {
+		if len(pairs) > 0 && (msg.Swap == nil || !containsFold(pairs, msg.Swap.Pair)) {
+			return nil
+		}
+		var errs []error
+		for _, s := range sinks {
+			if err := s.Handle(ctx, msg); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,299 @@
+// Package consumer is a small framework for services that react to the swap
+// events the indexer publishes. Handlers are registered per Redis Pub/Sub
+// channel or glob pattern; messages are dispatched on a bounded worker pool
+// with panic recovery and metrics, and the bundled sinks (stdout, file, CSV,
+// webhook) let most consumers be assembled from a config file.
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/codec"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// Message is one event delivered to a handler
+type Message struct {
+	Channel string            // channel (or stream) it arrived on
+	Payload []byte            // raw payload as published
+	Swap    *models.SwapEvent // decoded payload; nil if it is not a swap event
+}
+
+// Handler processes one message. Returning an error counts the message as
+// failed; for stream consumers it also leaves the event pending for retry.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Config tunes a Consumer
+type Config struct {
+	Workers   int // messages handled concurrently (default 1, which keeps order)
+	QueueSize int // Pub/Sub messages buffered ahead of the workers (default 1000)
+	Logger    *logrus.Logger
+
+	// ReorderWindow holds Pub/Sub swaps up to this long so each pair's are
+	// dispatched in non-decreasing slot order, as candle builders need
+	// (0: dispatched as they arrive). The order only holds with one worker.
+	ReorderWindow time.Duration
+}
+
+// route is a handler registered for a channel or pattern
+type route struct {
+	pattern string
+	handler Handler
+}
+
+// Consumer dispatches messages to the handlers registered for their channel
+type Consumer struct {
+	cfg    Config
+	logger *logrus.Logger
+
+	mu     sync.RWMutex
+	routes []route
+}
+
+// New creates a consumer with no handlers
+func New(cfg Config) *Consumer {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize < 1 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	return &Consumer{cfg: cfg, logger: cfg.Logger}
+}
+
+// Handle registers h for a channel, or for every channel matching a Redis
+// glob pattern (* and ?), e.g. "swaps:*". A message matching several routes
+// is passed to each of them in registration order.
+func (c *Consumer) Handle(pattern string, h Handler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.routes = append(c.routes, route{pattern: pattern, handler: h})
+}
+
+// Patterns returns the registered channels and patterns, without duplicates
+func (c *Consumer) Patterns() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	seen := map[string]bool{}
+	var out []string
+	for _, r := range c.routes {
+		if !seen[r.pattern] {
+			seen[r.pattern] = true
+			out = append(out, r.pattern)
+		}
+	}
+	return out
+}
+
+// Dispatch runs every handler registered for msg's channel and returns the
+// first error. A panicking handler is recovered and reported as an error.
+func (c *Consumer) Dispatch(ctx context.Context, msg *Message) error {
+	c.mu.RLock()
+	routes := make([]route, 0, len(c.routes))
+	for _, r := range c.routes {
+		if globMatch(r.pattern, msg.Channel) {
+			routes = append(routes, r)
+		}
+	}
+	c.mu.RUnlock()
+
+	if len(routes) == 0 {
+		messagesTotal.With("", resultUnrouted).Inc()
+		return nil
+	}
+
+	var first error
+	for _, r := range routes {
+		if err := c.run(ctx, r, msg); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// run calls one handler with panic recovery and metrics
+func (c *Consumer) run(ctx context.Context, r route, msg *Message) (err error) {
+	start := time.Now()
+	defer func() {
+		result := resultOK
+		if p := recover(); p != nil {
+			err = fmt.Errorf("handler for %s panicked: %v", r.pattern, p)
+			result = resultPanic
+		} else if err != nil {
+			result = resultError
+		}
+		messagesTotal.With(r.pattern, result).Inc()
+		handleDuration.With(r.pattern).Observe(time.Since(start).Seconds())
+		if err != nil {
+			c.logger.WithError(err).WithFields(logrus.Fields{
+				"route":   r.pattern,
+				"channel": msg.Channel,
+			}).Warn("consumer handler failed")
+		}
+	}()
+	return r.handler(ctx, msg)
+}
+
+// Run subscribes to every registered channel and pattern and dispatches
+// messages on the worker pool until ctx is cancelled, after reordering swaps
+// by slot within Config.ReorderWindow. Pub/Sub has no redelivery: messages
+// arriving while the queue is full are dropped.
+func (c *Consumer) Run(ctx context.Context, client *redis.Client) error {
+	var channels, patterns []string
+	for _, p := range c.Patterns() {
+		if isPattern(p) {
+			patterns = append(patterns, p)
+		} else {
+			channels = append(channels, p)
+		}
+	}
+	if len(channels)+len(patterns) == 0 {
+		return fmt.Errorf("consumer has no handlers")
+	}
+
+	pubsub := client.Subscribe(ctx)
+	defer pubsub.Close()
+	if len(channels) > 0 {
+		if err := pubsub.Subscribe(ctx, channels...); err != nil {
+			return fmt.Errorf("subscribe %v: %w", channels, err)
+		}
+	}
+	if len(patterns) > 0 {
+		if err := pubsub.PSubscribe(ctx, patterns...); err != nil {
+			return fmt.Errorf("psubscribe %v: %w", patterns, err)
+		}
+	}
+	c.logger.WithFields(logrus.Fields{
+		"channels":       channels,
+		"patterns":       patterns,
+		"workers":        c.cfg.Workers,
+		"reorder_window": c.cfg.ReorderWindow,
+	}).Info("consumer subscribed")
+
+	queue := make(chan *Message, c.cfg.QueueSize)
+	var wg sync.WaitGroup
+	for range c.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range queue {
+				_ = c.Dispatch(ctx, msg)
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(queue)
+
+	enqueue := func(msg *Message) {
+		select {
+		case queue <- msg:
+		default:
+			droppedTotal.With().Inc()
+			c.logger.WithField("channel", msg.Channel).Warn("consumer queue full, dropping message")
+		}
+	}
+
+	// without a window, messages go straight to the queue; tick stays nil
+	var (
+		reorder *reorderBuffer
+		tick    <-chan time.Time
+	)
+	if c.cfg.ReorderWindow > 0 {
+		reorder = newReorderBuffer(c.cfg.ReorderWindow)
+		ticker := time.NewTicker(reorder.tick())
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			if reorder != nil {
+				// held swaps are not redelivered either, so hand them over in order
+				for _, msg := range reorder.flush() {
+					enqueue(msg)
+				}
+			}
+			return nil
+		case now := <-tick:
+			for _, msg := range reorder.release(now) {
+				enqueue(msg)
+			}
+		case m, ok := <-ch:
+			if !ok {
+				return fmt.Errorf("pubsub channel closed")
+			}
+			msg := newMessage(m.Channel, []byte(m.Payload))
+			if reorder != nil {
+				msg = reorder.add(msg, time.Now())
+			}
+			if msg != nil {
+				enqueue(msg)
+			}
+		}
+	}
+}
+
+// RunStream reads the durable swap stream as a member of a consumer group and
+// dispatches each event as a message on channel constants.RedisStreamSwaps.
+// Events whose handlers fail stay pending and are redelivered.
+func (c *Consumer) RunStream(ctx context.Context, src storage.SwapCache, cc storage.ConsumerConfig) error {
+	return src.ConsumeSwaps(ctx, cc, func(ctx context.Context, swap *models.SwapEvent) error {
+		return c.Dispatch(ctx, &Message{Channel: constants.RedisStreamSwaps, Swap: swap})
+	})
+}
+
+// newMessage wraps a Pub/Sub payload, decoding it if it is a swap event
+func newMessage(channel string, payload []byte) *Message {
+	msg := &Message{Channel: channel, Payload: payload}
+	var swap models.SwapEvent
+	if err := codec.Decode(payload, &swap); err == nil && swap.Signature != "" {
+		msg.Swap = &swap
+	}
+	return msg
+}
+
+// isPattern reports whether p is a Redis glob pattern rather than a channel
+func isPattern(p string) bool {
+	return strings.ContainsAny(p, "*?")
+}
+
+// globMatch matches s against a Redis-style glob supporting * and ?
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for pattern = pattern[1:]; ; s = s[1:] {
+				if globMatch(pattern, s) {
+					return true
+				}
+				if s == "" {
+					return false
+				}
+			}
+		case '?':
+			if s == "" {
+				return false
+			}
+		default:
+			if s == "" || s[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return s == ""
+}
@@ -0,0 +1,234 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func swapMsg(channel, pair string) *Message {
+	return &Message{Channel: channel, Swap: &models.SwapEvent{
+		Signature: "sig-" + pair,
+		Timestamp: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Pair:      pair,
+		AmountIn:  1.5,
+	}}
+}
+
+func TestGlobMatch(t *testing.T) {
+	assert.True(t, globMatch("swaps:live", "swaps:live"))
+	assert.True(t, globMatch("swaps:*", "swaps:live:SOL/USDC"))
+	assert.True(t, globMatch("swaps:?ive", "swaps:live"))
+	assert.True(t, globMatch("*", ""))
+	assert.False(t, globMatch("swaps:*", "prices:SOL"))
+	assert.False(t, globMatch("swaps:live", "swaps:live2"))
+}
+
+func TestConsumer_DispatchRoutesAndRecovers(t *testing.T) {
+	c := New(Config{Logger: quietLogger()})
+	var got []string
+	c.Handle("swaps:live", func(_ context.Context, m *Message) error {
+		got = append(got, "exact:"+m.Channel)
+		return nil
+	})
+	c.Handle("swaps:*", func(_ context.Context, m *Message) error {
+		got = append(got, "pattern:"+m.Channel)
+		return nil
+	})
+	c.Handle("boom", func(context.Context, *Message) error { panic("handler bug") })
+
+	require.NoError(t, c.Dispatch(context.Background(), swapMsg("swaps:live", "SOL/USDC")))
+	assert.Equal(t, []string{"exact:swaps:live", "pattern:swaps:live"}, got)
+	assert.Equal(t, []string{"swaps:live", "swaps:*", "boom"}, c.Patterns())
+
+	err := c.Dispatch(context.Background(), swapMsg("boom", "SOL/USDC"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "panicked")
+	assert.Equal(t, float64(1), messagesTotal.With("boom", resultPanic).Value())
+
+	assert.NoError(t, c.Dispatch(context.Background(), swapMsg("other", "SOL/USDC")), "unrouted messages are ignored")
+}
+
+func TestFileConfig_BuildWithSinks(t *testing.T) {
+	dir := t.TempDir()
+	var hooked []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var swap models.SwapEvent
+		_ = json.NewDecoder(r.Body).Decode(&swap)
+		hooked = append(hooked, r.Header.Get("X-Swap-Channel")+" "+swap.Pair)
+		if swap.Pair == "BONK/SOL" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer hook.Close()
+
+	cfgPath := filepath.Join(dir, "consumers.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`
+workers: 2
+reorder_window: 1500ms
+routes:
+  - channel: swaps:live
+    pairs: [sol/usdc]
+    sinks:
+      - type: csv
+        path: `+filepath.Join(dir, "swaps.csv")+`
+      - type: file
+        path: `+filepath.Join(dir, "swaps.ndjson")+`
+  - channel: "swaps:*"
+    sinks:
+      - type: webhook
+        url: `+hook.URL+`
+        timeout: 2s
+`), 0o600))
+
+	fc, err := LoadConfig(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, fc.ReorderWindow)
+	c, closeSinks, err := fc.Build(quietLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, c.Dispatch(ctx, swapMsg("swaps:live", "SOL/USDC")))
+	assert.Error(t, c.Dispatch(ctx, swapMsg("swaps:live", "BONK/SOL")), "the webhook rejected it")
+	require.NoError(t, closeSinks())
+
+	csvData, err := os.ReadFile(filepath.Join(dir, "swaps.csv"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(csvData)), "\n")
+	require.Len(t, lines, 2, "header and the SOL/USDC swap; BONK/SOL is filtered by pairs")
+	assert.True(t, strings.HasPrefix(lines[0], "signature,timestamp,pair"))
+	assert.Contains(t, lines[1], "sig-SOL/USDC,2026-01-01T00:00:00Z,SOL/USDC")
+
+	ndjson, err := os.ReadFile(filepath.Join(dir, "swaps.ndjson"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(ndjson), "\n"))
+
+	assert.Equal(t, []string{"swaps:live SOL/USDC", "swaps:live BONK/SOL"}, hooked)
+}
+
+func TestLoadConfig_Rejects(t *testing.T) {
+	dir := t.TempDir()
+	write := func(body string) string {
+		p := filepath.Join(dir, "c.yaml")
+		require.NoError(t, os.WriteFile(p, []byte(body), 0o600))
+		return p
+	}
+
+	_, err := LoadConfig(write("workers: 1\n"))
+	assert.Error(t, err, "no routes")
+	_, err = LoadConfig(write("routes: [{sinks: [{type: stdout}]}]\nworkrs: 1\n"))
+	assert.Error(t, err, "unknown key")
+
+	fc, err := LoadConfig(write("routes: [{sinks: [{type: kafka}]}]\n"))
+	require.NoError(t, err)
+	_, _, err = fc.Build(quietLogger())
+	assert.Error(t, err)
+}
+
+func TestNewMessage_DecodesSwaps(t *testing.T) {
+	data, _ := json.Marshal(&models.SwapEvent{Signature: "abc", Pair: "SOL/USDC"})
+	msg := newMessage("swaps:live", data)
+	require.NotNil(t, msg.Swap)
+	assert.Equal(t, "SOL/USDC", msg.Swap.Pair)
+
+	assert.Nil(t, newMessage("config:reload", []byte("reload")).Swap)
+}
+
+func TestReorderBuffer(t *testing.T) {
+	b := newReorderBuffer(time.Second)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	swap := func(channel, pair string, slot uint64) *Message {
+		return &Message{Channel: channel, Swap: &models.SwapEvent{Signature: fmt.Sprintf("%s-%d", pair, slot), Pair: pair, Slot: slot}}
+	}
+	sigs := func(msgs []*Message) []string {
+		out := []string{}
+		for _, m := range msgs {
+			out = append(out, m.Swap.Signature)
+		}
+		slices.Sort(out) // pairs are released in map order; each pair's order is checked below
+		return out
+	}
+	ordered := func(msgs []*Message, pair string) []uint64 {
+		var out []uint64
+		for _, m := range msgs {
+			if m.Swap.Pair == pair {
+				out = append(out, m.Swap.Slot)
+			}
+		}
+		return out
+	}
+
+	lateBefore := outOfOrderTotal.With().Value()
+	reload := &Message{Channel: "config:reload"}
+	assert.Same(t, reload, b.add(reload, at(0)), "messages other than swaps are not held")
+
+	assert.Nil(t, b.add(swap("swaps:live", "SOL/USDC", 12), at(0)))
+	assert.Nil(t, b.add(swap("swaps:live", "SOL/USDC", 10), at(300)))
+	assert.Nil(t, b.add(swap("swaps:live", "BONK/SOL", 11), at(400)))
+	assert.Nil(t, b.add(swap("swaps:live", "SOL/USDC", 15), at(600)))
+	assert.Empty(t, b.release(at(999)))
+
+	// slot 12 is due and takes the lower slot 10 with it; 15 is not due yet
+	out := b.release(at(1000))
+	assert.Equal(t, []uint64{10, 12}, ordered(out, "SOL/USDC"))
+	assert.Empty(t, ordered(out, "BONK/SOL"))
+
+	// a slot below one released is too late to reorder and goes out at once
+	late := swap("swaps:live", "SOL/USDC", 11)
+	assert.Same(t, late, b.add(late, at(1100)))
+	assert.Equal(t, lateBefore+1, outOfOrderTotal.With().Value())
+	// the same pair on another channel is ordered separately
+	assert.Nil(t, b.add(swap("swaps:wallet:abc", "SOL/USDC", 11), at(1100)))
+
+	out = b.release(at(1600))
+	assert.Equal(t, []string{"BONK/SOL-11", "SOL/USDC-15"}, sigs(out))
+
+	assert.Nil(t, b.add(swap("swaps:live", "SOL/USDC", 15), at(1700)), "equal slots are in order")
+	assert.Equal(t, []string{"SOL/USDC-11", "SOL/USDC-15"}, sigs(b.flush()))
+	assert.Empty(t, b.flush())
+}
+
+func TestReorderBufferForgetsIdlePairs(t *testing.T) {
+	b := newReorderBuffer(time.Second)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	swap := func(pair string, slot uint64) *Message {
+		return &Message{Channel: "swaps:live", Swap: &models.SwapEvent{Pair: pair, Slot: slot}}
+	}
+
+	assert.Nil(t, b.add(swap("SOL/USDC", 10), start))
+	assert.Nil(t, b.add(swap("BONK/SOL", 10), start))
+	require.Len(t, b.release(start.Add(time.Second)), 2)
+	assert.Len(t, b.last, 2)
+
+	// BONK/SOL keeps trading, SOL/USDC goes quiet
+	for s := 2; s <= reorderIdleWindows+2; s++ {
+		now := start.Add(time.Duration(s) * time.Second)
+		assert.Nil(t, b.add(swap("BONK/SOL", uint64(10+s)), now.Add(-time.Second)))
+		b.release(now)
+	}
+	assert.Len(t, b.last, 1, "the idle pair is forgotten")
+	_, ok := b.last[reorderKey{"swaps:live", "BONK/SOL"}]
+	assert.True(t, ok)
+}
@@ -0,0 +1,22 @@
+package consumer
+
+import "github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
+
+// Handler outcomes reported by consumer_messages_total
+const (
+	resultOK       = "ok"
+	resultError    = "error"
+	resultPanic    = "panic"
+	resultUnrouted = "unrouted"
+)
+
+var (
+	messagesTotal = metrics.Default.Counter("consumer_messages_total",
+		"Messages handled by consumer routes, by route and result (ok, error, panic, unrouted).", "route", "result")
+	handleDuration = metrics.Default.Histogram("consumer_handle_duration_seconds",
+		"Time a route's handler took per message.", nil, "route")
+	droppedTotal = metrics.Default.Counter("consumer_dropped_total",
+		"Pub/Sub messages dropped because the worker queue was full.")
+	outOfOrderTotal = metrics.Default.Counter("consumer_out_of_order_total",
+		"Swaps dispatched after a higher slot of their pair because they arrived later than the reorder window.")
+)
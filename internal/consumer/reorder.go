@@ -0,0 +1,133 @@
+package consumer
+
+import (
+	"slices"
+	"time"
+)
+
+// reorderIdleWindows is how many windows a pair may go without a release
+// before its highest released slot is forgotten; a swap that late is out of
+// order whether or not it is reported as such
+const reorderIdleWindows = 10
+
+// reorderBuffer holds swap messages for up to a window so each channel's
+// swaps of a pair are dispatched in non-decreasing slot order. The indexer
+// fetches transactions in parallel, so it publishes swaps close to, but not
+// exactly in, slot order; a swap held for the window is released together
+// with every lower slot of its pair received meanwhile.
+type reorderBuffer struct {
+	window time.Duration
+	held   map[reorderKey][]heldMessage // by slot, then arrival
+	last   map[reorderKey]releasedSlot  // highest slot released, dropped once idle
+	swept  time.Time                    // last check for idle pairs
+}
+
+// releasedSlot is the highest slot released for a key, and when
+type releasedSlot struct {
+	slot uint64
+	at   time.Time
+}
+
+// reorderKey is the stream of swaps kept in order: one pair on one channel
+type reorderKey struct {
+	channel, pair string
+}
+
+type heldMessage struct {
+	msg      *Message
+	deadline time.Time
+}
+
+func newReorderBuffer(window time.Duration) *reorderBuffer {
+	return &reorderBuffer{
+		window: window,
+		held:   make(map[reorderKey][]heldMessage),
+		last:   make(map[reorderKey]releasedSlot),
+	}
+}
+
+// add holds msg until its window has passed. Messages that are not swaps are
+// returned to be dispatched right away, and so are swaps of a slot below one
+// already released: they arrived later than the window allows and are
+// delivered out of order rather than lost.
+func (b *reorderBuffer) add(msg *Message, now time.Time) *Message {
+	if msg.Swap == nil {
+		return msg
+	}
+	key := reorderKey{msg.Channel, msg.Swap.Pair}
+	if last, ok := b.last[key]; ok && msg.Swap.Slot < last.slot {
+		outOfOrderTotal.With().Inc()
+		return msg
+	}
+	held := b.held[key]
+	// after every held message of the same slot, so equal slots keep their arrival order
+	i, _ := slices.BinarySearchFunc(held, msg.Swap.Slot+1, func(h heldMessage, slot uint64) int {
+		if h.msg.Swap.Slot < slot {
+			return -1
+		}
+		return 1
+	})
+	b.held[key] = slices.Insert(held, i, heldMessage{msg: msg, deadline: now.Add(b.window)})
+	return nil
+}
+
+// release returns the messages due at now, in slot order per pair: each held
+// message whose window has passed, with every lower slot of its pair. Once a
+// window it also forgets the pairs idle for reorderIdleWindows windows, so
+// pairs that stop trading do not pile up.
+func (b *reorderBuffer) release(now time.Time) []*Message {
+	var out []*Message
+	for key, held := range b.held {
+		n := 0
+		for i, h := range held {
+			if !h.deadline.After(now) {
+				n = i + 1
+			}
+		}
+		out = b.releaseFirst(out, key, n, now)
+	}
+	if now.Sub(b.swept) >= b.window {
+		b.swept = now
+		idle := now.Add(-reorderIdleWindows * b.window)
+		for key, last := range b.last {
+			if _, held := b.held[key]; !held && last.at.Before(idle) {
+				delete(b.last, key)
+			}
+		}
+	}
+	return out
+}
+
+// flush returns every held message, in slot order per pair
+func (b *reorderBuffer) flush() []*Message {
+	var out []*Message
+	now := time.Now()
+	for key, held := range b.held {
+		out = b.releaseFirst(out, key, len(held), now)
+	}
+	return out
+}
+
+// releaseFirst appends the n lowest slots held for key to out, released at now
+func (b *reorderBuffer) releaseFirst(out []*Message, key reorderKey, n int, now time.Time) []*Message {
+	if n == 0 {
+		return out
+	}
+	held := b.held[key]
+	for _, h := range held[:n] {
+		out = append(out, h.msg)
+	}
+	b.last[key] = releasedSlot{slot: held[n-1].msg.Swap.Slot, at: now}
+	if n == len(held) {
+		delete(b.held, key)
+	} else {
+		b.held[key] = slices.Delete(held, 0, n)
+	}
+	return out
+}
+
+// tick is how often held messages are checked: a tenth of the window, so a
+// swap waits at most 10% longer than the window
+func (b *reorderBuffer) tick() time.Duration {
+	return max(b.window/10, 10*time.Millisecond)
+}
@@ -0,0 +1,265 @@
+package consumer
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// Sink is a ready-made handler destination
+type Sink interface {
+	Handle(ctx context.Context, msg *Message) error
+	io.Closer
+}
+
+// Sink types accepted in SinkConfig.Type
+const (
+	SinkStdout  = "stdout"
+	SinkFile    = "file"
+	SinkCSV     = "csv"
+	SinkWebhook = "webhook"
+)
+
+// Output formats of the stdout sink
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+)
+
+// SinkConfig describes one sink in a consumer config file
+type SinkConfig struct {
+	Type    string            `yaml:"type"`    // stdout, file, csv or webhook
+	Format  string            `yaml:"format"`  // stdout: table (default) or json
+	Path    string            `yaml:"path"`    // file, csv: output path (appended to)
+	URL     string            `yaml:"url"`     // webhook: endpoint receiving a POST per message
+	Headers map[string]string `yaml:"headers"` // webhook: extra request headers
+	Timeout time.Duration     `yaml:"timeout"` // webhook: per-request timeout (default 5s)
+}
+
+// NewSink builds the sink described by cfg
+func NewSink(cfg SinkConfig) (Sink, error) {
+	switch cfg.Type {
+	case SinkStdout:
+		switch cfg.Format {
+		case "", FormatTable:
+			return &stdoutSink{w: os.Stdout, table: true}, nil
+		case FormatJSON:
+			return &stdoutSink{w: os.Stdout}, nil
+		default:
+			return nil, fmt.Errorf("stdout sink: unknown format %q (want table or json)", cfg.Format)
+		}
+	case SinkFile:
+		f, err := openAppend(cfg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("file sink: %w", err)
+		}
+		return &fileSink{f: f}, nil
+	case SinkCSV:
+		return newCSVSink(cfg.Path)
+	case SinkWebhook:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook sink: url is required")
+		}
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		return &webhookSink{url: cfg.URL, headers: cfg.Headers, client: &http.Client{Timeout: timeout}}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q (want stdout, file, csv or webhook)", cfg.Type)
+	}
+}
+
+func openAppend(path string) (*os.File, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
+
+// messageJSON is the JSON form of a message: the swap if it decoded, else
+// the raw payload
+func messageJSON(msg *Message) ([]byte, error) {
+	if msg.Swap != nil {
+		return json.Marshal(msg.Swap)
+	}
+	if json.Valid(msg.Payload) {
+		return msg.Payload, nil
+	}
+	return json.Marshal(map[string]string{"channel": msg.Channel, "payload": string(msg.Payload)})
+}
+
+// stdoutSink prints swaps as table rows (the live viewer) or JSON lines
+type stdoutSink struct {
+	mu    sync.Mutex
+	w     io.Writer
+	table bool
+}
+
+func (s *stdoutSink) Handle(_ context.Context, msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.table && msg.Swap != nil {
+		_, err := fmt.Fprintln(s.w, FormatSwapRow(msg.Swap))
+		return err
+	}
+	data, err := messageJSON(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "%s\n", data)
+	return err
+}
+
+func (s *stdoutSink) Close() error { return nil }
+
+// FormatSwapRow renders a swap as one row of the live viewer table
+func FormatSwapRow(swap *models.SwapEvent) string {
+	// Truncate pair for display
+	pair := swap.Pair
+	if len(pair) > 18 {
+		pair = pair[:18]
+	}
+
+	// Format amounts with token symbols
+	amountIn := fmt.Sprintf("%.4f %s", swap.AmountIn, truncateToken(swap.TokenIn))
+	amountOut := fmt.Sprintf("%.4f %s", swap.AmountOut, truncateToken(swap.TokenOut))
+
+	// Truncate signature
+	sig := swap.Signature
+	if len(sig) > 8 {
+		sig = sig[:8]
+	}
+
+	return fmt.Sprintf("[%s] %-18s │ %20s │ %20s │ %12.6f │ %s",
+		swap.Timestamp.Format("15:04:05"),
+		pair,
+		amountIn,
+		amountOut,
+		swap.Price,
+		sig,
+	)
+}
+
+func truncateToken(token string) string {
+	if len(token) > 12 {
+		return token[:4] + "..." + token[len(token)-4:]
+	}
+	return token
+}
+
+// fileSink appends one JSON document per message (NDJSON)
+type fileSink struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func (s *fileSink) Handle(_ context.Context, msg *Message) error {
+	data, err := messageJSON(msg)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.f.Write(append(data, '\n'))
+	return err
+}
+
+func (s *fileSink) Close() error { return s.f.Close() }
+
+// csvHeader is the column order of the CSV sink
+var csvHeader = []string{
+	"signature", "timestamp", "pair", "token_in", "token_out",
+	"amount_in", "amount_out", "price", "fee", "pool", "dex",
+	"slot", "amount_in_raw", "amount_out_raw", "program_id", "pool_address",
+}
+
+// csvSink appends swaps as CSV rows; messages that are not swaps are skipped
+type csvSink struct {
+	mu sync.Mutex
+	f  *os.File
+	w  *csv.Writer
+}
+
+func newCSVSink(path string) (*csvSink, error) {
+	f, err := openAppend(path)
+	if err != nil {
+		return nil, fmt.Errorf("csv sink: %w", err)
+	}
+	s := &csvSink{f: f, w: csv.NewWriter(f)}
+
+	// a new (empty) file gets the header row
+	if info, err := f.Stat(); err == nil && info.Size() == 0 {
+		_ = s.w.Write(csvHeader)
+		s.w.Flush()
+	}
+	return s, s.w.Error()
+}
+
+func (s *csvSink) Handle(_ context.Context, msg *Message) error {
+	swap := msg.Swap
+	if swap == nil {
+		return nil
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	u := func(v uint64) string { return strconv.FormatUint(v, 10) }
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.w.Write([]string{
+		swap.Signature, swap.Timestamp.UTC().Format(time.RFC3339Nano), swap.Pair, swap.TokenIn, swap.TokenOut,
+		f(swap.AmountIn), f(swap.AmountOut), f(swap.Price), f(swap.Fee), swap.Pool, swap.Dex,
+		u(swap.Slot), u(swap.AmountInRaw), u(swap.AmountOutRaw), swap.ProgramID, swap.PoolAddress,
+	})
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func (s *csvSink) Close() error { return s.f.Close() }
+
+// webhookSink POSTs each message as JSON; non-2xx responses are errors
+type webhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (s *webhookSink) Handle(ctx context.Context, msg *Message) error {
+	data, err := messageJSON(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Swap-Channel", msg.Channel)
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook: %s returned %d", s.url, resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error { return nil }
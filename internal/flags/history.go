@@ -0,0 +1,127 @@
+package flags
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const historyPrefix = "flags:history:"
+
+// DefaultHistoryLimit is how many changes are kept per flag
+const DefaultHistoryLimit = 100
+
+// Actor identifies who made a flag change
+type Actor struct {
+	Name   string `json:"name,omitempty"`    // free-form caller identity (X-Actor header)
+	APIKey string `json:"api_key,omitempty"` // fingerprint of the API key used, never the key
+	IP     string `json:"ip,omitempty"`      // remote address of the request
+}
+
+// HistoryEntry is one recorded change to a flag
+type HistoryEntry struct {
+	Op    ChangeOp  `json:"op"`
+	Key   string    `json:"key"`
+	Old   *Flag     `json:"old,omitempty"` // nil when the flag was created
+	New   *Flag     `json:"new,omitempty"` // nil when the flag was deleted
+	Actor Actor     `json:"actor"`
+	At    time.Time `json:"at"`
+}
+
+type actorCtxKey struct{}
+
+// WithActor attaches the caller's identity to ctx so store writes are
+// attributed to it in the flag history
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorCtxKey{}, a)
+}
+
+// ActorFrom returns the actor attached to ctx, if any
+func ActorFrom(ctx context.Context) Actor {
+	a, _ := ctx.Value(actorCtxKey{}).(Actor)
+	return a
+}
+
+// KeyFingerprint returns a short, stable identifier for an API key that is
+// safe to store and show
+func KeyFingerprint(key string) string {
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// SetHistoryLimit changes how many entries are kept per flag; n <= 0 restores
+// DefaultHistoryLimit
+func (s *Store) SetHistoryLimit(n int) {
+	if n <= 0 {
+		n = DefaultHistoryLimit
+	}
+	s.historyLimit = n
+}
+
+// HistoryLimit returns how many entries are kept per flag
+func (s *Store) HistoryLimit() int {
+	return s.historyLimit
+}
+
+// History returns up to limit recorded changes to a flag, newest first. The
+// history outlives the flag itself, so deleted flags can still be audited.
+func (s *Store) History(ctx context.Context, key string, limit int) ([]HistoryEntry, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > s.historyLimit {
+		limit = s.historyLimit
+	}
+
+	vals, err := s.client.LRange(ctx, historyKey(key), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get flag history: %w", err)
+	}
+
+	out := make([]HistoryEntry, 0, len(vals))
+	for _, v := range vals {
+		var e HistoryEntry
+		if err := json.Unmarshal([]byte(v), &e); err != nil {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// current returns the stored flag or nil, for recording the old value of a change
+func (s *Store) current(ctx context.Context, key string) *Flag {
+	val, err := s.client.Get(ctx, flagKey(key)).Result()
+	if err != nil {
+		return nil
+	}
+	var f Flag
+	if err := json.Unmarshal([]byte(val), &f); err != nil {
+		return nil
+	}
+	return &f
+}
+
+// recordHistory queues a history entry, trimmed to the cap, in the same
+// transaction as the write
+func (s *Store) recordHistory(ctx context.Context, pipe redis.Pipeliner, e HistoryEntry) {
+	e.Actor = ActorFrom(ctx)
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	pipe.LPush(ctx, historyKey(e.Key), b)
+	pipe.LTrim(ctx, historyKey(e.Key), 0, int64(s.historyLimit-1))
+}
+
+func historyKey(key string) string {
+	return historyPrefix + key
+}
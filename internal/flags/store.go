@@ -5,50 +5,138 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 const (
-	indexKey    = "flags:index"
-	valuePrefix = "flags:"
+	indexKey       = "flags:keys"  // sorted set, all scores 0, ordered by key
+	legacyIndexKey = "flags:index" // plain set used before namespaced listing
+	valuePrefix    = "flags:"
 )
 
-var keyRe = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`)
+// Page sizes for ListPage
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 500
+)
+
+var (
+	keyRe    = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`)
+	prefixRe = regexp.MustCompile(`^[a-zA-Z0-9._-]{0,128}$`)
+)
+
+// reservedKeys would store their value over the indexes, which share the
+// flags: prefix with the values
+var reservedKeys = map[string]bool{
+	indexKey[len(valuePrefix):]:       true,
+	legacyIndexKey[len(valuePrefix):]: true,
+}
 
 type Store struct {
-	client redis.Cmdable
+	client       redis.Cmdable
+	historyLimit int
+	migrated     atomic.Bool // legacy set index converted
+}
+
+// ListOptions selects a page of flags. Keys are hierarchical by convention
+// (e.g. "engine.kill_switch"), so Prefix "engine." lists one namespace.
+type ListOptions struct {
+	Prefix string // only keys starting with this
+	After  string // cursor: the Next value of the previous page
+	Limit  int    // page size, default DefaultPageSize, max MaxPageSize
+}
+
+// Page is one page of flags; Next is empty on the last page
+type Page struct {
+	Items []*Flag `json:"items"`
+	Next  string  `json:"next_cursor,omitempty"`
 }
 
 func NewStore(client redis.Cmdable) (*Store, error) {
 	if client == nil {
 		return nil, fmt.Errorf("redis client is nil")
 	}
-	return &Store{client: client}, nil
+	return &Store{client: client, historyLimit: DefaultHistoryLimit}, nil
 }
 
 func ValidateKey(key string) error {
 	if !keyRe.MatchString(key) {
 		return fmt.Errorf("invalid flag key")
 	}
+	if reservedKeys[key] {
+		return fmt.Errorf("invalid flag key: %q is reserved", key)
+	}
+	return nil
+}
+
+// ValidatePrefix checks a namespace prefix for listing; empty matches every key
+func ValidatePrefix(prefix string) error {
+	if !prefixRe.MatchString(prefix) {
+		return fmt.Errorf("invalid flag prefix")
+	}
 	return nil
 }
 
+// Upsert creates or updates a boolean flag
 func (s *Store) Upsert(ctx context.Context, key string, value bool) (*Flag, error) {
+	return s.Set(ctx, key, TypeBool, json.RawMessage(strconv.FormatBool(value)))
+}
+
+// Set creates or updates a flag with a typed value. An empty typ is inferred
+// from the value; a value that does not match typ is rejected with ErrInvalidType.
+func (s *Store) Set(ctx context.Context, key string, typ Type, value json.RawMessage) (*Flag, error) {
+	return s.SetScheduled(ctx, key, typ, value, Schedule{})
+}
+
+// SetScheduled is Set with an activation window. The flag only takes effect
+// from sched.ActiveFrom, and Redis deletes it at sched.ExpiresAt so a
+// temporary toggle cannot be left on by accident.
+func (s *Store) SetScheduled(ctx context.Context, key string, typ Type, value json.RawMessage, sched Schedule) (*Flag, error) {
 	if err := ValidateKey(key); err != nil {
 		return nil, err
 	}
+	if typ == "" {
+		typ = InferType(value)
+	}
+	data, err := ValidateValue(typ, value)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if err := sched.Validate(now); err != nil {
+		return nil, err
+	}
 
-	flag := &Flag{Key: key, Value: value, UpdatedAt: time.Now().UTC()}
+	flag := &Flag{
+		Key:        key,
+		Type:       typ,
+		Data:       data,
+		ActiveFrom: utcPtr(sched.ActiveFrom),
+		ExpiresAt:  utcPtr(sched.ExpiresAt),
+		UpdatedAt:  now,
+	}
+	if typ == TypeBool {
+		flag.Value = string(data) == "true"
+	}
 	b, err := json.Marshal(flag)
 	if err != nil {
 		return nil, fmt.Errorf("marshal flag: %w", err)
 	}
 
+	old := s.current(ctx, key)
+
 	pipe := s.client.TxPipeline()
 	pipe.Set(ctx, flagKey(key), b, 0)
-	pipe.SAdd(ctx, indexKey, key)
+	if flag.ExpiresAt != nil {
+		pipe.PExpireAt(ctx, flagKey(key), *flag.ExpiresAt)
+	}
+	pipe.ZAdd(ctx, indexKey, redis.Z{Member: key})
+	publishChange(ctx, pipe, Change{Op: OpSet, Key: key, Flag: flag})
+	s.recordHistory(ctx, pipe, HistoryEntry{Op: OpSet, Key: key, Old: old, New: flag, At: now})
 	if _, err := pipe.Exec(ctx); err != nil {
 		return nil, fmt.Errorf("upsert flag: %w", err)
 	}
@@ -73,16 +161,121 @@ func (s *Store) Get(ctx context.Context, key string) (*Flag, error) {
 	if err := json.Unmarshal([]byte(val), &f); err != nil {
 		return nil, fmt.Errorf("unmarshal flag: %w", err)
 	}
+	if f.ExpiresAt != nil && !time.Now().Before(*f.ExpiresAt) {
+		return nil, ErrNotFound // expired; Redis removes it momentarily
+	}
 	return &f, nil
 }
 
+// getActive is Get for the typed getters: a flag whose window has not
+// started yet returns ErrNotActive
+func (s *Store) getActive(ctx context.Context, key string) (*Flag, error) {
+	f, err := s.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !f.ActiveAt(time.Now()) {
+		return nil, fmt.Errorf("%w: %s starts at %s", ErrNotActive, key, f.ActiveFrom.Format(time.RFC3339))
+	}
+	return f, nil
+}
+
+// GetBool returns the value of a bool flag
+func (s *Store) GetBool(ctx context.Context, key string) (bool, error) {
+	f, err := s.getActive(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	return f.Bool()
+}
+
+// GetInt returns the value of an int flag
+func (s *Store) GetInt(ctx context.Context, key string) (int64, error) {
+	f, err := s.getActive(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return f.Int()
+}
+
+// GetFloat returns the value of a float (or int) flag
+func (s *Store) GetFloat(ctx context.Context, key string) (float64, error) {
+	f, err := s.getActive(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return f.Float()
+}
+
+// GetString returns the value of a string flag
+func (s *Store) GetString(ctx context.Context, key string) (string, error) {
+	f, err := s.getActive(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return f.Str()
+}
+
+// GetJSON decodes the value of a flag into out
+func (s *Store) GetJSON(ctx context.Context, key string, out any) error {
+	f, err := s.getActive(ctx, key)
+	if err != nil {
+		return err
+	}
+	return f.Decode(out)
+}
+
+// List returns every flag, fetched page by page
 func (s *Store) List(ctx context.Context) ([]*Flag, error) {
-	keys, err := s.client.SMembers(ctx, indexKey).Result()
+	out := []*Flag{}
+	opts := ListOptions{Limit: MaxPageSize}
+	for {
+		page, err := s.ListPage(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page.Items...)
+		if page.Next == "" {
+			return out, nil
+		}
+		opts.After = page.Next
+	}
+}
+
+// ListPage returns flags in key order, optionally limited to a namespace
+// prefix. Only one page of flags is read from Redis per call.
+func (s *Store) ListPage(ctx context.Context, opts ListOptions) (*Page, error) {
+	if err := ValidatePrefix(opts.Prefix); err != nil {
+		return nil, err
+	}
+	if opts.Limit <= 0 || opts.Limit > MaxPageSize {
+		opts.Limit = DefaultPageSize
+	}
+	if err := s.migrateIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	// all index members score 0, so ZRANGEBYLEX walks them in key order;
+	// '~' sorts after every character allowed in a key
+	lo, hi := "-", "+"
+	if opts.Prefix != "" {
+		lo, hi = "["+opts.Prefix, "("+opts.Prefix+"~"
+	}
+	if opts.After != "" && opts.After >= opts.Prefix {
+		lo = "(" + opts.After
+	}
+
+	keys, err := s.client.ZRangeByLex(ctx, indexKey, &redis.ZRangeBy{
+		Min: lo, Max: hi, Count: int64(opts.Limit + 1),
+	}).Result()
 	if err != nil {
 		return nil, fmt.Errorf("list flags index: %w", err)
 	}
-	if len(keys) == 0 {
-		return []*Flag{}, nil
+
+	page := &Page{Items: []*Flag{}}
+	if len(keys) > opts.Limit {
+		keys = keys[:opts.Limit]
+		page.Next = keys[len(keys)-1]
 	}
 
 	redisKeys := make([]string, 0, len(keys))
@@ -93,7 +286,7 @@ func (s *Store) List(ctx context.Context) ([]*Flag, error) {
 		redisKeys = append(redisKeys, flagKey(k))
 	}
 	if len(redisKeys) == 0 {
-		return []*Flag{}, nil
+		return page, nil
 	}
 
 	vals, err := s.client.MGet(ctx, redisKeys...).Result()
@@ -101,9 +294,12 @@ func (s *Store) List(ctx context.Context) ([]*Flag, error) {
 		return nil, fmt.Errorf("mget flags: %w", err)
 	}
 
-	out := make([]*Flag, 0, len(vals))
-	for _, v := range vals {
+	now := time.Now()
+	var stale []interface{}
+	for i, v := range vals {
 		if v == nil {
+			// expired (or deleted) since it was indexed
+			stale = append(stale, redisKeys[i][len(valuePrefix):])
 			continue
 		}
 		s, ok := v.(string)
@@ -114,10 +310,43 @@ func (s *Store) List(ctx context.Context) ([]*Flag, error) {
 		if err := json.Unmarshal([]byte(s), &f); err != nil {
 			continue
 		}
-		out = append(out, &f)
+		if f.ExpiresAt != nil && !now.Before(*f.ExpiresAt) {
+			continue
+		}
+		page.Items = append(page.Items, &f)
 	}
 
-	return out, nil
+	if len(stale) > 0 {
+		_ = s.client.ZRem(ctx, indexKey, stale...).Err()
+	}
+
+	return page, nil
+}
+
+// migrateIndex moves keys from the unordered set index used by older
+// versions into the sorted index, once per process
+func (s *Store) migrateIndex(ctx context.Context) error {
+	if s.migrated.Load() {
+		return nil
+	}
+	keys, err := s.client.SMembers(ctx, legacyIndexKey).Result()
+	if err != nil {
+		return fmt.Errorf("read legacy flags index: %w", err)
+	}
+	if len(keys) > 0 {
+		members := make([]redis.Z, len(keys))
+		for i, k := range keys {
+			members[i] = redis.Z{Member: k}
+		}
+		pipe := s.client.TxPipeline()
+		pipe.ZAdd(ctx, indexKey, members...)
+		pipe.Del(ctx, legacyIndexKey)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("migrate flags index: %w", err)
+		}
+	}
+	s.migrated.Store(true)
+	return nil
 }
 
 func (s *Store) Delete(ctx context.Context, key string) error {
@@ -125,9 +354,16 @@ func (s *Store) Delete(ctx context.Context, key string) error {
 		return err
 	}
 
+	old := s.current(ctx, key)
+
 	pipe := s.client.TxPipeline()
 	pipe.Del(ctx, flagKey(key))
-	pipe.SRem(ctx, indexKey, key)
+	pipe.ZRem(ctx, indexKey, key)
+	pipe.SRem(ctx, legacyIndexKey, key)
+	publishChange(ctx, pipe, Change{Op: OpDelete, Key: key})
+	if old != nil {
+		s.recordHistory(ctx, pipe, HistoryEntry{Op: OpDelete, Key: key, Old: old, At: time.Now().UTC()})
+	}
 	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("delete flag: %w", err)
 	}
@@ -135,6 +371,23 @@ func (s *Store) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// publishChange queues a change notification in the same transaction as the write
+func publishChange(ctx context.Context, pipe redis.Pipeliner, ch Change) {
+	b, err := json.Marshal(ch)
+	if err != nil {
+		return
+	}
+	pipe.Publish(ctx, ChangesChannel, b)
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
 func flagKey(key string) string {
 	return valuePrefix + key
 }
@@ -6,45 +6,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/redistest"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func setupTestRedis(t *testing.T) *redis.Client {
-	client := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379",
-		DB:   1, // Use different DB for tests
-	})
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	// Test connection
-	err := client.Ping(ctx).Err()
-	if err != nil {
-		t.Skipf("Redis not available: %v", err)
-	}
-
-	// Clear test DB
-	err = client.FlushDB(ctx).Err()
-	require.NoError(t, err)
-
-	return client
-}
-
-// cleanupTestRedis intentionally accepts *testing.T for future debugging use, even if 't' is currently unused.
-func cleanupTestRedis(_ *testing.T, client *redis.Client) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	_ = client.FlushDB(ctx).Err()
-	_ = client.Close()
+	return redistest.Client(t, redistest.DBFlags)
 }
 
 func TestStore_Upsert(t *testing.T) {
 	client := setupTestRedis(t)
-	defer cleanupTestRedis(t, client)
 
 	store, err := NewStore(client)
 	require.NoError(t, err)
@@ -81,7 +54,6 @@ func TestStore_Upsert(t *testing.T) {
 
 func TestStore_Get(t *testing.T) {
 	client := setupTestRedis(t)
-	defer cleanupTestRedis(t, client)
 
 	store, err := NewStore(client)
 	require.NoError(t, err)
@@ -109,7 +81,6 @@ func TestStore_Get(t *testing.T) {
 
 func TestStore_Delete(t *testing.T) {
 	client := setupTestRedis(t)
-	defer cleanupTestRedis(t, client)
 
 	store, err := NewStore(client)
 	require.NoError(t, err)
@@ -140,7 +111,6 @@ func TestStore_Delete(t *testing.T) {
 
 func TestStore_List(t *testing.T) {
 	client := setupTestRedis(t)
-	defer cleanupTestRedis(t, client)
 
 	store, err := NewStore(client)
 	require.NoError(t, err)
@@ -184,7 +154,6 @@ func TestStore_List(t *testing.T) {
 
 func TestStore_ConcurrentOperations(t *testing.T) {
 	client := setupTestRedis(t)
-	defer cleanupTestRedis(t, client)
 
 	store, err := NewStore(client)
 	require.NoError(t, err)
@@ -227,7 +196,6 @@ func TestStore_ConcurrentOperations(t *testing.T) {
 
 func TestStore_InvalidKeys(t *testing.T) {
 	client := setupTestRedis(t)
-	defer cleanupTestRedis(t, client)
 
 	store, err := NewStore(client)
 	require.NoError(t, err)
@@ -252,7 +220,6 @@ func TestStore_InvalidKeys(t *testing.T) {
 
 func TestStore_KeyValidation(t *testing.T) {
 	client := setupTestRedis(t)
-	defer cleanupTestRedis(t, client)
 
 	store, err := NewStore(client)
 	require.NoError(t, err)
@@ -281,6 +248,8 @@ func TestStore_KeyValidation(t *testing.T) {
 		"flag:with:colons",
 		"flag\twith\ttabs",
 		"flag\nwith\nnewlines",
+		"keys",
+		"index",
 	}
 
 	for _, key := range invalidKeys {
@@ -288,3 +257,183 @@ func TestStore_KeyValidation(t *testing.T) {
 		assert.Error(t, err, "Key %s should be invalid", key)
 	}
 }
+
+func TestStore_TypedValues(t *testing.T) {
+	client := setupTestRedis(t)
+
+	store, err := NewStore(client)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = store.Set(ctx, "whale.threshold_usd", TypeFloat, []byte(`250000.5`))
+	require.NoError(t, err)
+	_, err = store.Set(ctx, "ai.model", "", []byte(`"openai/gpt-4.1-mini"`))
+	require.NoError(t, err)
+	_, err = store.Set(ctx, "swaps.max_batch", TypeInt, []byte(`50`))
+	require.NoError(t, err)
+	_, err = store.Set(ctx, "risk.limits", TypeJSON, []byte(`{"daily": 10}`))
+	require.NoError(t, err)
+
+	f, err := store.GetFloat(ctx, "whale.threshold_usd")
+	assert.NoError(t, err)
+	assert.Equal(t, 250000.5, f)
+
+	model, err := store.GetString(ctx, "ai.model")
+	assert.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4.1-mini", model)
+
+	n, err := store.GetInt(ctx, "swaps.max_batch")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), n)
+
+	var limits struct{ Daily int }
+	assert.NoError(t, store.GetJSON(ctx, "risk.limits", &limits))
+	assert.Equal(t, 10, limits.Daily)
+
+	// wrong getter for the type
+	_, err = store.GetBool(ctx, "swaps.max_batch")
+	assert.ErrorIs(t, err, ErrWrongType)
+
+	// value does not match declared type
+	_, err = store.Set(ctx, "swaps.max_batch", TypeInt, []byte(`"fifty"`))
+	assert.ErrorIs(t, err, ErrInvalidType)
+}
+
+func TestStore_ScheduledFlags(t *testing.T) {
+	client := setupTestRedis(t)
+
+	store, err := NewStore(client)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	// expires shortly: enforced by Redis and hidden from List afterwards
+	exp := now.Add(300 * time.Millisecond)
+	f, err := store.SetScheduled(ctx, "maintenance.read_only", TypeBool, []byte(`true`), Schedule{ExpiresAt: &exp})
+	require.NoError(t, err)
+	require.NotNil(t, f.ExpiresAt)
+
+	on, err := store.GetBool(ctx, "maintenance.read_only")
+	assert.NoError(t, err)
+	assert.True(t, on)
+
+	time.Sleep(400 * time.Millisecond)
+	_, err = store.Get(ctx, "maintenance.read_only")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	items, err := store.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+
+	// scheduled for later: listed, but typed getters treat it as not active
+	from := now.Add(time.Hour)
+	_, err = store.SetScheduled(ctx, "promo.banner", TypeString, []byte(`"hello"`), Schedule{ActiveFrom: &from})
+	require.NoError(t, err)
+
+	_, err = store.GetString(ctx, "promo.banner")
+	assert.ErrorIs(t, err, ErrNotActive)
+
+	// windows in the past are rejected
+	past := now.Add(-time.Minute)
+	_, err = store.SetScheduled(ctx, "x", TypeBool, []byte(`true`), Schedule{ExpiresAt: &past})
+	assert.ErrorIs(t, err, ErrSchedule)
+}
+
+func TestStore_History(t *testing.T) {
+	client := setupTestRedis(t)
+
+	store, err := NewStore(client)
+	require.NoError(t, err)
+	store.SetHistoryLimit(3)
+
+	ctx := WithActor(context.Background(), Actor{Name: "alice", APIKey: KeyFingerprint("secret")})
+
+	for _, v := range []bool{true, false, true, false} {
+		_, err := store.Upsert(ctx, "hist.flag", v)
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.Delete(ctx, "hist.flag"))
+
+	items, err := store.History(context.Background(), "hist.flag", 0)
+	require.NoError(t, err)
+	require.Len(t, items, 3) // capped
+
+	// newest first, and the history survives the delete
+	assert.Equal(t, OpDelete, items[0].Op)
+	assert.Nil(t, items[0].New)
+	require.NotNil(t, items[0].Old)
+	assert.False(t, items[0].Old.Value)
+
+	assert.Equal(t, OpSet, items[1].Op)
+	assert.True(t, items[1].Old.Value)
+	assert.False(t, items[1].New.Value)
+
+	assert.Equal(t, "alice", items[0].Actor.Name)
+	assert.Equal(t, KeyFingerprint("secret"), items[0].Actor.APIKey)
+	assert.NotContains(t, items[0].Actor.APIKey, "secret")
+
+	// no history for a flag that never existed
+	items, err = store.History(context.Background(), "never.set", 10)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestStore_ListPage(t *testing.T) {
+	client := setupTestRedis(t)
+
+	store, err := NewStore(client)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, k := range []string{"engine.a", "engine.b", "engine.c", "engineering", "indexer.paused"} {
+		_, err := store.Upsert(ctx, k, true)
+		require.NoError(t, err)
+	}
+
+	page, err := store.ListPage(ctx, ListOptions{Prefix: "engine.", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "engine.a", page.Items[0].Key)
+	assert.Equal(t, "engine.b", page.Items[1].Key)
+	assert.Equal(t, "engine.b", page.Next)
+
+	page, err = store.ListPage(ctx, ListOptions{Prefix: "engine.", After: page.Next, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "engine.c", page.Items[0].Key)
+	assert.Empty(t, page.Next)
+
+	all, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 5)
+
+	_, err = store.ListPage(ctx, ListOptions{Prefix: "bad:prefix"})
+	assert.Error(t, err)
+}
+
+func TestStore_MigratesLegacyIndex(t *testing.T) {
+	client := setupTestRedis(t)
+
+	ctx := context.Background()
+	require.NoError(t, client.Set(ctx, "flags:old.flag", `{"key":"old.flag","value":true}`, 0).Err())
+	require.NoError(t, client.SAdd(ctx, "flags:index", "old.flag").Err())
+
+	store, err := NewStore(client)
+	require.NoError(t, err)
+
+	items, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "old.flag", items[0].Key)
+	assert.Zero(t, client.Exists(ctx, "flags:index").Val())
+}
+
+func TestValidateKey_Reserved(t *testing.T) {
+	assert.NoError(t, ValidateKey("engine.kill_switch"))
+	for _, key := range []string{"keys", "index"} {
+		assert.ErrorContains(t, ValidateKey(key), "reserved", key)
+	}
+	assert.NoError(t, ValidateKey("keys.enabled"), "only the exact index names are reserved")
+}
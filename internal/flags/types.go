@@ -1,14 +1,256 @@
 package flags
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
-var ErrNotFound = errors.New("flag not found")
+// Well-known flags read by the services themselves
+const (
+	KeyIndexerPaused        = "indexer.paused"         // bool: stop polling for new swaps
+	KeyIndexerFilters       = "indexer.filters"        // bool: apply the INDEXER_FILTER_* ingestion filter (default true)
+	KeyEngineKillSwitch     = "engine.kill_switch"     // bool: refuse to execute swaps
+	KeyEngineRisk           = "engine.risk"            // json: swapengine.RiskOverrides tightening the configured risk limits
+	KeyEngineCooldownBypass = "engine.cooldown_bypass" // bool: swap through pairs cooling down after a failed execution
+	KeyLogLevelPrefix       = "log.level."             // string, one per module (log.level.stream): that module's log level
+)
+
+var (
+	ErrNotFound    = errors.New("flag not found")
+	ErrInvalidType = errors.New("invalid flag type")
+	ErrWrongType   = errors.New("flag has a different type")
+	ErrNotActive   = errors.New("flag not active yet")
+	ErrSchedule    = errors.New("invalid flag schedule")
+)
+
+// Type is the kind of value a flag holds
+type Type string
+
+const (
+	TypeBool   Type = "bool"
+	TypeInt    Type = "int"
+	TypeFloat  Type = "float"
+	TypeString Type = "string"
+	TypeJSON   Type = "json"
+)
 
+// Flag is a feature flag. Data holds the JSON-encoded value for every type;
+// Value mirrors it for bool flags so existing boolean callers keep working.
+//
+// A flag may be scheduled: it only takes effect from ActiveFrom and is removed
+// by the store at ExpiresAt.
 type Flag struct {
-	Key       string    `json:"key"`
-	Value     bool      `json:"value"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Key        string          `json:"key"`
+	Type       Type            `json:"type"`
+	Value      bool            `json:"-"`
+	Data       json.RawMessage `json:"-"`
+	ActiveFrom *time.Time      `json:"active_from,omitempty"`
+	ExpiresAt  *time.Time      `json:"expires_at,omitempty"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// Schedule limits when a flag is in effect; zero fields mean no limit
+type Schedule struct {
+	ActiveFrom *time.Time
+	ExpiresAt  *time.Time
+}
+
+// Validate rejects windows that are already over or end before they start
+func (sc Schedule) Validate(now time.Time) error {
+	if sc.ExpiresAt != nil && !sc.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: expires_at must be in the future", ErrSchedule)
+	}
+	if sc.ActiveFrom != nil && sc.ExpiresAt != nil && !sc.ExpiresAt.After(*sc.ActiveFrom) {
+		return fmt.Errorf("%w: expires_at must be after active_from", ErrSchedule)
+	}
+	return nil
+}
+
+// Schedule returns the flag's activation window
+func (f *Flag) Schedule() Schedule {
+	return Schedule{ActiveFrom: f.ActiveFrom, ExpiresAt: f.ExpiresAt}
+}
+
+// ActiveAt reports whether the flag is in effect at t
+func (f *Flag) ActiveAt(t time.Time) bool {
+	if f.ActiveFrom != nil && t.Before(*f.ActiveFrom) {
+		return false
+	}
+	if f.ExpiresAt != nil && !t.Before(*f.ExpiresAt) {
+		return false
+	}
+	return true
+}
+
+// flagJSON is the wire and storage format, with the typed value under "value"
+type flagJSON struct {
+	Key        string          `json:"key"`
+	Type       Type            `json:"type"`
+	Value      json.RawMessage `json:"value"`
+	Active     bool            `json:"active"`
+	ActiveFrom *time.Time      `json:"active_from,omitempty"`
+	ExpiresAt  *time.Time      `json:"expires_at,omitempty"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+func (f Flag) MarshalJSON() ([]byte, error) {
+	data := f.Data
+	if len(data) == 0 {
+		data = json.RawMessage(strconv.FormatBool(f.Value))
+	}
+	typ := f.Type
+	if typ == "" {
+		typ = TypeBool
+	}
+	return json.Marshal(flagJSON{
+		Key:        f.Key,
+		Type:       typ,
+		Value:      data,
+		Active:     f.ActiveAt(time.Now()),
+		ActiveFrom: f.ActiveFrom,
+		ExpiresAt:  f.ExpiresAt,
+		UpdatedAt:  f.UpdatedAt,
+	})
+}
+
+func (f *Flag) UnmarshalJSON(b []byte) error {
+	var raw flagJSON
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	// flags written before typed values existed carry no type and are booleans
+	typ := raw.Type
+	if typ == "" {
+		typ = TypeBool
+	}
+
+	*f = Flag{
+		Key:        raw.Key,
+		Type:       typ,
+		Data:       raw.Value,
+		ActiveFrom: raw.ActiveFrom,
+		ExpiresAt:  raw.ExpiresAt,
+		UpdatedAt:  raw.UpdatedAt,
+	}
+	if typ == TypeBool {
+		_ = json.Unmarshal(raw.Value, &f.Value)
+	}
+	return nil
+}
+
+// Bool returns the value of a bool flag
+func (f *Flag) Bool() (bool, error) {
+	if f.Type != TypeBool {
+		return false, fmt.Errorf("%w: %s is %s, not bool", ErrWrongType, f.Key, f.Type)
+	}
+	return f.Value, nil
+}
+
+// Int returns the value of an int flag
+func (f *Flag) Int() (int64, error) {
+	if f.Type != TypeInt {
+		return 0, fmt.Errorf("%w: %s is %s, not int", ErrWrongType, f.Key, f.Type)
+	}
+	return strconv.ParseInt(string(f.Data), 10, 64)
+}
+
+// Float returns the value of a float or int flag
+func (f *Flag) Float() (float64, error) {
+	if f.Type != TypeFloat && f.Type != TypeInt {
+		return 0, fmt.Errorf("%w: %s is %s, not float", ErrWrongType, f.Key, f.Type)
+	}
+	return strconv.ParseFloat(string(f.Data), 64)
+}
+
+// Str returns the value of a string flag
+func (f *Flag) Str() (string, error) {
+	if f.Type != TypeString {
+		return "", fmt.Errorf("%w: %s is %s, not string", ErrWrongType, f.Key, f.Type)
+	}
+	var s string
+	err := json.Unmarshal(f.Data, &s)
+	return s, err
+}
+
+// Decode unmarshals the flag value into out; it works for every type
+func (f *Flag) Decode(out any) error {
+	return json.Unmarshal(f.Data, out)
+}
+
+// ParseType validates a type name; an empty name is returned as-is so callers
+// can fall back to InferType
+func ParseType(s string) (Type, error) {
+	switch t := Type(strings.ToLower(strings.TrimSpace(s))); t {
+	case "", TypeBool, TypeInt, TypeFloat, TypeString, TypeJSON:
+		return t, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want bool, int, float, string or json)", ErrInvalidType, s)
+	}
+}
+
+// InferType picks a type from the shape of a JSON value
+func InferType(raw json.RawMessage) Type {
+	v := bytes.TrimSpace(raw)
+	switch {
+	case bytes.Equal(v, []byte("true")), bytes.Equal(v, []byte("false")):
+		return TypeBool
+	case len(v) > 0 && v[0] == '"':
+		return TypeString
+	case len(v) > 0 && (v[0] == '-' || (v[0] >= '0' && v[0] <= '9')):
+		if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return TypeInt
+		}
+		return TypeFloat
+	default:
+		return TypeJSON
+	}
+}
+
+// ValidateValue checks that raw is a valid value for typ and returns it compacted
+func ValidateValue(typ Type, raw json.RawMessage) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, fmt.Errorf("%w: value is required", ErrInvalidType)
+	}
+	if !json.Valid(raw) {
+		return nil, fmt.Errorf("%w: value is not valid JSON", ErrInvalidType)
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidType, err)
+	}
+	v := buf.Bytes()
+
+	switch typ {
+	case TypeBool:
+		var b bool
+		if err := json.Unmarshal(v, &b); err != nil {
+			return nil, fmt.Errorf("%w: bool flag needs true or false", ErrInvalidType)
+		}
+	case TypeInt:
+		if _, err := strconv.ParseInt(string(v), 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: int flag needs an integer", ErrInvalidType)
+		}
+	case TypeFloat:
+		var n float64
+		if err := json.Unmarshal(v, &n); err != nil {
+			return nil, fmt.Errorf("%w: float flag needs a number", ErrInvalidType)
+		}
+	case TypeString:
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			return nil, fmt.Errorf("%w: string flag needs a JSON string", ErrInvalidType)
+		}
+	case TypeJSON:
+		// any valid JSON
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidType, typ)
+	}
+	return json.RawMessage(v), nil
 }
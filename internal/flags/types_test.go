@@ -0,0 +1,46 @@
+package flags
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlag_UntypedRecordsAreBool(t *testing.T) {
+	var f Flag
+	require.NoError(t, json.Unmarshal([]byte(`{"key":"old.flag","value":true,"updated_at":"2024-01-01T00:00:00Z"}`), &f))
+
+	assert.Equal(t, TypeBool, f.Type)
+	assert.True(t, f.Value)
+
+	b, err := json.Marshal(f)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"key":"old.flag","type":"bool","value":true,"active":true,"updated_at":"2024-01-01T00:00:00Z"}`, string(b))
+}
+
+func TestInferType(t *testing.T) {
+	cases := map[string]Type{
+		`true`:     TypeBool,
+		`42`:       TypeInt,
+		`-1.5`:     TypeFloat,
+		`"gpt"`:    TypeString,
+		`{"a": 1}`: TypeJSON,
+		`[1, 2]`:   TypeJSON,
+	}
+	for raw, want := range cases {
+		assert.Equal(t, want, InferType(json.RawMessage(raw)), raw)
+	}
+}
+
+func TestFlag_ActiveAt(t *testing.T) {
+	now := time.Now()
+	from, until := now.Add(time.Hour), now.Add(2*time.Hour)
+	f := &Flag{Key: "k", Type: TypeBool, ActiveFrom: &from, ExpiresAt: &until}
+
+	assert.False(t, f.ActiveAt(now))
+	assert.True(t, f.ActiveAt(from))
+	assert.False(t, f.ActiveAt(until))
+}
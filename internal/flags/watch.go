@@ -0,0 +1,290 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ChangesChannel is the Redis Pub/Sub channel every flag write is announced on
+const ChangesChannel = "flags:changes"
+
+// DefaultResync is how often a Watcher reloads the full flag set to pick up
+// expirations and any change notification it missed
+const DefaultResync = 30 * time.Second
+
+// ChangeOp is the kind of flag change
+type ChangeOp string
+
+const (
+	OpSet    ChangeOp = "set"
+	OpDelete ChangeOp = "delete"
+)
+
+// Change is published on ChangesChannel after a flag is written or deleted
+type Change struct {
+	Op   ChangeOp `json:"op"`
+	Key  string   `json:"key"`
+	Flag *Flag    `json:"flag,omitempty"` // nil for deletes
+}
+
+// subscriber is implemented by *redis.Client and friends, but not by every
+// redis.Cmdable (e.g. pipelines), so Watch falls back to polling without it
+type subscriber interface {
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+}
+
+// Watcher keeps an in-process snapshot of all flags, updated from change
+// notifications, so hot paths can read flags without a Redis round trip
+type Watcher struct {
+	store  *Store
+	resync time.Duration
+
+	mu       sync.RWMutex
+	snapshot map[string]*Flag
+	handlers []func(Change)
+	checked  time.Time // when activation was last evaluated
+}
+
+// Watch loads every flag and keeps the snapshot current until ctx is
+// cancelled. resync <= 0 uses DefaultResync.
+func (s *Store) Watch(ctx context.Context, resync time.Duration) (*Watcher, error) {
+	if resync <= 0 {
+		resync = DefaultResync
+	}
+	w := &Watcher{store: s, resync: resync, snapshot: make(map[string]*Flag)}
+
+	// subscribe before the initial load so no change falls in between
+	var msgs <-chan *redis.Message
+	var pubsub *redis.PubSub
+	if sub, ok := s.client.(subscriber); ok {
+		pubsub = sub.Subscribe(ctx, ChangesChannel)
+		if _, err := pubsub.Receive(ctx); err != nil {
+			_ = pubsub.Close()
+			return nil, err
+		}
+		msgs = pubsub.Channel()
+	}
+
+	if err := w.reload(ctx); err != nil {
+		if pubsub != nil {
+			_ = pubsub.Close()
+		}
+		return nil, err
+	}
+
+	go w.run(ctx, pubsub, msgs)
+	return w, nil
+}
+
+// OnChange registers fn to run (on the watcher goroutine) after every change,
+// including a scheduled flag reaching its ActiveFrom
+func (w *Watcher) OnChange(fn func(Change)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, fn)
+}
+
+// Get returns a flag if it exists and is currently active
+func (w *Watcher) Get(key string) (*Flag, bool) {
+	w.mu.RLock()
+	f, ok := w.snapshot[key]
+	w.mu.RUnlock()
+	if !ok || !f.ActiveAt(time.Now()) {
+		return nil, false
+	}
+	return f, true
+}
+
+// Bool returns a bool flag, or def if it is missing, inactive or not a bool
+func (w *Watcher) Bool(key string, def bool) bool {
+	if f, ok := w.Get(key); ok {
+		if v, err := f.Bool(); err == nil {
+			return v
+		}
+	}
+	return def
+}
+
+// Int returns an int flag, or def if it is missing, inactive or not an int
+func (w *Watcher) Int(key string, def int64) int64 {
+	if f, ok := w.Get(key); ok {
+		if v, err := f.Int(); err == nil {
+			return v
+		}
+	}
+	return def
+}
+
+// Float returns a numeric flag, or def if it is missing, inactive or not a number
+func (w *Watcher) Float(key string, def float64) float64 {
+	if f, ok := w.Get(key); ok {
+		if v, err := f.Float(); err == nil {
+			return v
+		}
+	}
+	return def
+}
+
+// String returns a string flag, or def if it is missing, inactive or not a string
+func (w *Watcher) String(key, def string) string {
+	if f, ok := w.Get(key); ok {
+		if v, err := f.Str(); err == nil {
+			return v
+		}
+	}
+	return def
+}
+
+// Snapshot returns a copy of all flags currently known, active or not
+func (w *Watcher) Snapshot() []*Flag {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	out := make([]*Flag, 0, len(w.snapshot))
+	for _, f := range w.snapshot {
+		out = append(out, f)
+	}
+	return out
+}
+
+func (w *Watcher) run(ctx context.Context, pubsub *redis.PubSub, msgs <-chan *redis.Message) {
+	if pubsub != nil {
+		defer pubsub.Close()
+	}
+
+	ticker := time.NewTicker(w.resync)
+	defer ticker.Stop()
+
+	// fires at the earliest ActiveFrom still ahead
+	activation := time.NewTimer(w.untilActivation(time.Now()))
+	defer activation.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case msg, ok := <-msgs:
+			if !ok {
+				msgs = nil
+				continue
+			}
+			var ch Change
+			if err := json.Unmarshal([]byte(msg.Payload), &ch); err != nil || ch.Key == "" {
+				continue
+			}
+			w.apply(ch)
+
+		case <-ticker.C:
+			_ = w.reload(ctx)
+
+		case <-activation.C:
+			w.activate(time.Now())
+		}
+		activation.Reset(w.untilActivation(time.Now()))
+	}
+}
+
+// untilActivation returns how long until the next flag in the snapshot
+// reaches its ActiveFrom, or the resync interval when none is pending
+func (w *Watcher) untilActivation(now time.Time) time.Duration {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	next := w.resync
+	for _, f := range w.snapshot {
+		if f.ActiveFrom != nil && f.ActiveFrom.After(now) {
+			next = min(next, f.ActiveFrom.Sub(now))
+		}
+	}
+	return next
+}
+
+// activate notifies handlers of every flag that took effect since
+// activation was last evaluated
+func (w *Watcher) activate(now time.Time) {
+	w.mu.Lock()
+	var changes []Change
+	for key, f := range w.snapshot {
+		if !f.ActiveAt(w.checked) && f.ActiveAt(now) {
+			changes = append(changes, Change{Op: OpSet, Key: key, Flag: f})
+		}
+	}
+	w.checked = now
+	handlers := w.handlers
+	w.mu.Unlock()
+
+	for _, ch := range changes {
+		for _, fn := range handlers {
+			fn(ch)
+		}
+	}
+}
+
+// apply updates the snapshot with a single change and notifies handlers
+func (w *Watcher) apply(ch Change) {
+	w.mu.Lock()
+	switch ch.Op {
+	case OpSet:
+		if ch.Flag == nil {
+			w.mu.Unlock()
+			return
+		}
+		w.snapshot[ch.Key] = ch.Flag
+	case OpDelete:
+		delete(w.snapshot, ch.Key)
+	default:
+		w.mu.Unlock()
+		return
+	}
+	handlers := w.handlers
+	w.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(ch)
+	}
+}
+
+// reload replaces the snapshot with the store's current flags and emits a
+// change for every difference (expired flags show up as deletes, flags that
+// took effect since the last pass as sets)
+func (w *Watcher) reload(ctx context.Context) error {
+	items, err := w.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	next := make(map[string]*Flag, len(items))
+	for _, f := range items {
+		next[f.Key] = f
+	}
+
+	now := time.Now()
+	w.mu.Lock()
+	prev, checked := w.snapshot, w.checked
+	w.snapshot, w.checked = next, now
+	handlers := w.handlers
+	w.mu.Unlock()
+
+	var changes []Change
+	for key, f := range next {
+		old, ok := prev[key]
+		if !ok || !old.UpdatedAt.Equal(f.UpdatedAt) || old.ActiveAt(checked) != f.ActiveAt(now) {
+			changes = append(changes, Change{Op: OpSet, Key: key, Flag: f})
+		}
+	}
+	for key := range prev {
+		if _, ok := next[key]; !ok {
+			changes = append(changes, Change{Op: OpDelete, Key: key})
+		}
+	}
+
+	for _, ch := range changes {
+		for _, fn := range handlers {
+			fn(ch)
+		}
+	}
+	return nil
+}
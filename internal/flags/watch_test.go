@@ -0,0 +1,83 @@
+package flags
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_FollowsChanges(t *testing.T) {
+	client := setupTestRedis(t)
+
+	store, err := NewStore(client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err = store.Upsert(ctx, KeyIndexerPaused, false)
+	require.NoError(t, err)
+
+	w, err := store.Watch(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, w.Bool(KeyIndexerPaused, true))
+
+	changes := make(chan Change, 4)
+	w.OnChange(func(ch Change) { changes <- ch })
+
+	_, err = store.Upsert(ctx, KeyIndexerPaused, true)
+	require.NoError(t, err)
+
+	select {
+	case ch := <-changes:
+		assert.Equal(t, OpSet, ch.Op)
+		assert.Equal(t, KeyIndexerPaused, ch.Key)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change notification")
+	}
+	assert.True(t, w.Bool(KeyIndexerPaused, false))
+
+	require.NoError(t, store.Delete(ctx, KeyIndexerPaused))
+	select {
+	case ch := <-changes:
+		assert.Equal(t, OpDelete, ch.Op)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no delete notification")
+	}
+	assert.False(t, w.Bool(KeyIndexerPaused, false))
+}
+
+func TestWatcher_NotifiesActivation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	from := now.Add(50 * time.Millisecond)
+	w := &Watcher{resync: time.Minute, checked: now, snapshot: map[string]*Flag{
+		KeyIndexerPaused: {Key: KeyIndexerPaused, Type: TypeBool, Value: true, Data: []byte("true"), ActiveFrom: &from, UpdatedAt: now},
+	}}
+	changes := make(chan Change, 4)
+	w.OnChange(func(ch Change) { changes <- ch })
+	assert.False(t, w.Bool(KeyIndexerPaused, false), "scheduled, not active yet")
+
+	go w.run(ctx, nil, nil)
+
+	select {
+	case ch := <-changes:
+		assert.Equal(t, OpSet, ch.Op)
+		assert.Equal(t, KeyIndexerPaused, ch.Key)
+		assert.False(t, time.Now().Before(from))
+	case <-time.After(2 * time.Second):
+		t.Fatal("no notification when the flag took effect")
+	}
+	assert.True(t, w.Bool(KeyIndexerPaused, false))
+
+	select {
+	case ch := <-changes:
+		t.Fatalf("activation notified twice: %+v", ch)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,178 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	swapindexerv1 "github.com/aman-zulfiqar/solana-swap-indexer/proto/swapindexer/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves s on an in-memory listener and returns a client
+// connection to it; cancelling stop shuts the server down
+func newTestClient(t *testing.T, s *Server) (*grpc.ClientConn, context.CancelFunc) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, lis) }()
+	t.Cleanup(func() {
+		stop()
+		require.NoError(t, <-done)
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn, stop
+}
+
+func newClient(t *testing.T, s *Server) swapindexerv1.SwapIndexerClient {
+	conn, _ := newTestClient(t, s)
+	return swapindexerv1.NewSwapIndexerClient(conn)
+}
+
+func recvPrice(t *testing.T, stream grpc.ServerStreamingClient[swapindexerv1.TokenPrice]) models.TokenPrice {
+	t.Helper()
+	p, err := stream.Recv()
+	require.NoError(t, err)
+	return models.TokenPrice{Token: p.GetToken(), Price: p.GetPrice()}
+}
+
+func TestSubscribeSwaps(t *testing.T) {
+	mem := cache.NewMemoryCache(10, time.Minute)
+	client := newClient(t, New(mem, Config{}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.SubscribeSwaps(ctx, &swapindexerv1.SwapFilter{Pairs: []string{"sol/usdc"}, MinAmountIn: 1})
+	require.NoError(t, err)
+
+	// The subscription starts after the request arrives; publish until it is seen
+	go func() {
+		for ctx.Err() == nil {
+			_ = mem.PublishSwap(ctx, &models.SwapEvent{Signature: "small", Pair: "SOL/USDC", AmountIn: 0.5})
+			_ = mem.PublishSwap(ctx, &models.SwapEvent{Signature: "other", Pair: "BONK/SOL", AmountIn: 5})
+			_ = mem.PublishSwap(ctx, &models.SwapEvent{Signature: "match", Pair: "SOL/USDC", AmountIn: 2, Price: 151})
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	for range 2 {
+		swap, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, "match", swap.GetSignature())
+		assert.Equal(t, 151.0, swap.GetPrice())
+	}
+}
+
+func TestSubscribePrices(t *testing.T) {
+	mem := cache.NewMemoryCache(10, time.Minute)
+	require.NoError(t, mem.UpdatePrice(context.Background(), "SOL", 150))
+	client := newClient(t, New(mem, Config{}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.SubscribePrices(ctx, &swapindexerv1.PriceSubscription{Tokens: []string{"sol", "JUP"}}) // no cached price of JUP yet
+	require.NoError(t, err)
+
+	// the snapshot is sent after subscribing, so later swaps are not missed
+	assert.Equal(t, models.TokenPrice{Token: "SOL", Price: 150}, recvPrice(t, stream))
+
+	require.NoError(t, mem.PublishSwap(ctx, &models.SwapEvent{TokenIn: "SOL", TokenOut: "USDC", Price: 0.0066}))
+	require.NoError(t, mem.PublishSwap(ctx, &models.SwapEvent{TokenIn: "USDC", TokenOut: "SOL", Price: 151}))
+	require.NoError(t, mem.PublishSwap(ctx, &models.SwapEvent{TokenIn: "USDC", TokenOut: "JUP", Price: 0.9}))
+	assert.Equal(t, models.TokenPrice{Token: "SOL", Price: 151}, recvPrice(t, stream))
+	assert.Equal(t, models.TokenPrice{Token: "JUP", Price: 0.9}, recvPrice(t, stream))
+}
+
+func TestSubscribePricesRequiresTokens(t *testing.T) {
+	client := newClient(t, New(cache.NewMemoryCache(10, time.Minute), Config{}))
+	stream, err := client.SubscribePrices(context.Background(), &swapindexerv1.PriceSubscription{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "tokens")
+}
+
+func TestSubscriptionEndsOnShutdown(t *testing.T) {
+	conn, stop := newTestClient(t, New(cache.NewMemoryCache(10, time.Minute), Config{}))
+	stream, err := swapindexerv1.NewSwapIndexerClient(conn).SubscribeSwaps(context.Background(), &swapindexerv1.SwapFilter{})
+	require.NoError(t, err)
+	_, err = stream.Header() // the call is open on the server
+	require.NoError(t, err)
+
+	stop()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestUnary(t *testing.T) {
+	mem := cache.NewMemoryCache(10, time.Minute)
+	ctx := context.Background()
+	require.NoError(t, mem.AddRecentSwap(ctx, &models.SwapEvent{Signature: "a", Pair: "SOL/USDC"}))
+	require.NoError(t, mem.AddRecentSwap(ctx, &models.SwapEvent{Signature: "b", Pair: "BONK/SOL"}))
+	require.NoError(t, mem.AddRecentSwap(ctx, &models.SwapEvent{Signature: "c", Pair: "SOL/USDC"}))
+	require.NoError(t, mem.UpdatePrice(ctx, "SOL", 150))
+	conn, _ := newTestClient(t, New(mem, Config{}))
+	client := swapindexerv1.NewSwapIndexerClient(conn)
+
+	t.Run("recent swaps by pair", func(t *testing.T) {
+		resp, err := client.GetRecentSwaps(ctx, &swapindexerv1.RecentSwapsRequest{Pair: "sol/usdc"})
+		require.NoError(t, err)
+		var sigs []string
+		for _, s := range resp.GetItems() {
+			sigs = append(sigs, s.GetSignature())
+		}
+		assert.Equal(t, []string{"c", "a"}, sigs)
+	})
+
+	t.Run("limit out of range", func(t *testing.T) {
+		_, err := client.GetRecentSwaps(ctx, &swapindexerv1.RecentSwapsRequest{Limit: 500})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, "limit must be between 1 and 200", status.Convert(err).Message())
+	})
+
+	t.Run("price", func(t *testing.T) {
+		resp, err := client.GetPrice(ctx, &swapindexerv1.PriceRequest{Token: "sol"})
+		require.NoError(t, err)
+		assert.Equal(t, "SOL", resp.GetToken())
+		assert.Equal(t, 150.0, resp.GetPrice())
+		assert.False(t, resp.GetStale())
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		err := conn.Invoke(ctx, "/swapindexer.v1.SwapIndexer/Nope", &swapindexerv1.PriceRequest{}, &swapindexerv1.PriceResponse{})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
+
+func TestAPIKey(t *testing.T) {
+	client := newClient(t, New(cache.NewMemoryCache(10, time.Minute), Config{APIKey: "secret"}))
+	req := &swapindexerv1.PriceRequest{Token: "SOL"}
+
+	_, err := client.GetPrice(context.Background(), req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret")
+	_, err = client.GetPrice(ctx, req)
+	assert.NoError(t, err)
+
+	stream, err := client.SubscribeSwaps(context.Background(), &swapindexerv1.SwapFilter{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
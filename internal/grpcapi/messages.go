@@ -0,0 +1,99 @@
+package grpcapi
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/codec"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	swapindexerv1 "github.com/aman-zulfiqar/solana-swap-indexer/proto/swapindexer/v1"
+)
+
+// Conversions between the messages of proto/swapindexer/v1/api.proto and
+// the models the service reads
+
+// SwapFilter selects the swaps of a SubscribeSwaps stream. Empty lists match
+// everything; names match case-insensitively and tokens match either leg.
+type SwapFilter struct {
+	Pairs       []string
+	Tokens      []string
+	Dexes       []string
+	Wallets     []string // case-sensitive (base58)
+	MinAmountIn float64
+}
+
+// Match reports whether swap passes the filter
+func (f *SwapFilter) Match(swap *models.SwapEvent) bool {
+	if swap.AmountIn < f.MinAmountIn {
+		return false
+	}
+	if len(f.Pairs) > 0 && !containsFold(f.Pairs, swap.Pair) {
+		return false
+	}
+	if len(f.Tokens) > 0 && !containsFold(f.Tokens, swap.TokenIn) && !containsFold(f.Tokens, swap.TokenOut) {
+		return false
+	}
+	if len(f.Dexes) > 0 && !containsFold(f.Dexes, swap.Dex) {
+		return false
+	}
+	if len(f.Wallets) > 0 {
+		for _, w := range f.Wallets {
+			if w == swap.Wallet {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// newSwapFilter reads a SwapFilter message
+func newSwapFilter(m *swapindexerv1.SwapFilter) *SwapFilter {
+	return &SwapFilter{
+		Pairs:       m.GetPairs(),
+		Tokens:      m.GetTokens(),
+		Dexes:       m.GetDexes(),
+		Wallets:     m.GetWallets(),
+		MinAmountIn: m.GetMinAmountIn(),
+	}
+}
+
+func tokenPriceProto(p *models.TokenPrice) *swapindexerv1.TokenPrice {
+	return &swapindexerv1.TokenPrice{Token: p.Token, Price: p.Price, UpdatedAt: codec.TimestampToProto(p.UpdatedAt)}
+}
+
+func recentSwapsProto(items []*models.SwapEvent) *swapindexerv1.RecentSwapsResponse {
+	resp := &swapindexerv1.RecentSwapsResponse{}
+	for _, s := range items {
+		if s != nil {
+			resp.Items = append(resp.Items, codec.SwapToProto(s))
+		}
+	}
+	return resp
+}
+
+// priceResponseProto builds a PriceResponse; p is nil for unknown tokens
+func priceResponseProto(token string, p *models.TokenPrice, stale bool) *swapindexerv1.PriceResponse {
+	resp := &swapindexerv1.PriceResponse{Token: token, Stale: stale}
+	if p != nil {
+		resp.Price, resp.UpdatedAt = p.Price, codec.TimestampToProto(p.UpdatedAt)
+	}
+	return resp
+}
+
+func priceHistoryProto(token string, window time.Duration, points []models.PricePoint) *swapindexerv1.PriceHistoryResponse {
+	resp := &swapindexerv1.PriceHistoryResponse{Token: token, Window: window.String()}
+	for _, pt := range points {
+		resp.Points = append(resp.Points, &swapindexerv1.PricePoint{Price: pt.Price, At: codec.TimestampToProto(pt.At)})
+	}
+	return resp
+}
@@ -0,0 +1,10 @@
+package grpcapi
+
+import "github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
+
+var (
+	grpcCalls = metrics.Default.Counter("grpc_calls_total",
+		"gRPC calls by method and status code.", "method", "code")
+	grpcStreams = metrics.Default.Gauge("grpc_streams_open",
+		"Open SubscribeSwaps and SubscribePrices streams.")
+)
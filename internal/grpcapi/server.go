@@ -0,0 +1,238 @@
+// Package grpcapi serves the SwapIndexer gRPC service of
+// proto/swapindexer/v1/api.proto: server-streaming subscriptions to swaps and
+// prices bridged from the Redis feed, plus unary lookups of recent swaps and
+// prices. It runs on google.golang.org/grpc with the generated bindings.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	swapindexerv1 "github.com/aman-zulfiqar/solana-swap-indexer/proto/swapindexer/v1"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Source is what the service reads (implemented by *cache.FallbackCache and
+// the other storage.SwapCache implementations)
+type Source interface {
+	GetRecentSwaps(ctx context.Context, limit int64) ([]*models.SwapEvent, error)
+	GetRecentSwapsByPair(ctx context.Context, pair string, limit int64) ([]*models.SwapEvent, error)
+	GetPrice(ctx context.Context, token string) (*models.TokenPrice, error)
+	GetPriceHistory(ctx context.Context, token string, since time.Time) ([]models.PricePoint, error)
+	SubscribeSwaps(ctx context.Context) (<-chan *models.SwapEvent, error)
+}
+
+// Config configures the server
+type Config struct {
+	Addr            string        // listen address (GRPC_ADDR)
+	APIKey          string        // if set, calls must send it as x-api-key metadata
+	MaxStreams      int           // concurrent subscriptions (default constants.GRPCMaxStreams)
+	PriceStaleAfter time.Duration // GetPrice reports older prices as stale (default constants.PriceStaleAfter)
+	TLSCertFile     string        // serve TLS with this PEM certificate and key; plaintext HTTP/2 otherwise
+	TLSKeyFile      string
+	Logger          *logrus.Logger
+}
+
+// Limits
+const (
+	maxRequestBytes = 64 << 10
+	unaryTimeout    = 5 * time.Second
+	maxPriceTokens  = 100
+)
+
+// Server is the gRPC server
+type Server struct {
+	swapindexerv1.UnimplementedSwapIndexerServer
+
+	cfg     Config
+	src     Source
+	streams chan struct{} // one slot per open subscription
+	logger  *logrus.Logger
+
+	mu   sync.Mutex
+	srv  *grpc.Server
+	base context.Context // the context given to Serve
+}
+
+// New creates a server reading from src
+func New(src Source, cfg Config) *Server {
+	if cfg.MaxStreams <= 0 {
+		cfg.MaxStreams = constants.GRPCMaxStreams
+	}
+	if cfg.PriceStaleAfter <= 0 {
+		cfg.PriceStaleAfter = constants.PriceStaleAfter
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return &Server{cfg: cfg, src: src, streams: make(chan struct{}, cfg.MaxStreams), logger: logger}
+}
+
+// ListenAndServe serves on cfg.Addr until ctx is cancelled or Shutdown is
+// called
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, lis)
+}
+
+// Serve serves on lis until ctx is cancelled or Shutdown is called; open
+// subscriptions end with UNAVAILABLE once ctx is done so clients reconnect
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(maxRequestBytes),
+		grpc.ChainUnaryInterceptor(s.interceptUnary),
+		grpc.ChainStreamInterceptor(s.interceptStream),
+	}
+	if s.cfg.TLSCertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(s.cfg.TLSCertFile, s.cfg.TLSKeyFile)
+		if err != nil {
+			_ = lis.Close()
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	srv := grpc.NewServer(opts...)
+	swapindexerv1.RegisterSwapIndexerServer(srv, s)
+	s.mu.Lock()
+	s.srv, s.base = srv, ctx
+	s.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, func() { _ = s.Shutdown(context.Background()) })
+	defer stop()
+
+	if err := srv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting calls and waits for running ones to finish, or
+// closes them all once ctx is done; subscriptions only end once the context
+// given to Serve is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	srv := s.srv
+	s.mu.Unlock()
+	if srv == nil {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		srv.Stop()
+		return ctx.Err()
+	}
+}
+
+// interceptUnary authenticates a unary call and bounds how long it runs
+func (s *Server) interceptUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() { s.count(info.FullMethod, err) }()
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, unaryTimeout)
+	defer cancel()
+	resp, err = handler(ctx, req)
+	return resp, s.contextStatus(ctx, err)
+}
+
+// interceptStream authenticates a subscription, holds one of the
+// MaxStreams slots while it is open and ends it when the server stops
+func (s *Server) interceptStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() { s.count(info.FullMethod, err) }()
+	if err := s.authorize(ss.Context()); err != nil {
+		return err
+	}
+	select {
+	case s.streams <- struct{}{}:
+		defer func() { <-s.streams }()
+	default:
+		return status.Error(codes.ResourceExhausted, "too many open subscriptions")
+	}
+	grpcStreams.With().Add(1)
+	defer grpcStreams.With().Add(-1)
+
+	ctx, cancel := context.WithCancel(ss.Context())
+	defer cancel()
+	s.mu.Lock()
+	base := s.base
+	s.mu.Unlock()
+	if base != nil {
+		stop := context.AfterFunc(base, cancel)
+		defer stop()
+	}
+
+	_ = ss.SendHeader(nil) // subscribers see the headers before the first message
+
+	method := path.Base(info.FullMethod)
+	s.logger.WithField("method", method).Debug("grpc subscription opened")
+	defer s.logger.WithField("method", method).Debug("grpc subscription closed")
+	return s.contextStatus(ctx, handler(srv, &serverStream{ServerStream: ss, ctx: ctx}))
+}
+
+// serverStream is a stream whose context also ends with the server
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+
+// authorize checks the x-api-key metadata when an API key is configured
+func (s *Server) authorize(ctx context.Context) error {
+	if s.cfg.APIKey == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get("x-api-key"); len(keys) == 0 || keys[0] != s.cfg.APIKey {
+		return status.Error(codes.Unauthenticated, "missing or invalid x-api-key")
+	}
+	return nil
+}
+
+// count records a finished call in grpc_calls_total
+func (s *Server) count(fullMethod string, err error) {
+	grpcCalls.With(path.Base(fullMethod), strconv.Itoa(int(status.Code(err)))).Inc()
+}
+
+// contextStatus turns the error of a call whose context ended into the
+// matching status: DEADLINE_EXCEEDED for a client or unary deadline,
+// CANCELLED when the client went away and UNAVAILABLE when the server is
+// shutting down
+func (s *Server) contextStatus(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	s.mu.Lock()
+	base := s.base
+	s.mu.Unlock()
+	switch {
+	case base != nil && base.Err() != nil:
+		return status.Error(codes.Unavailable, "server is shutting down")
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	}
+	return status.Error(codes.Canceled, "call cancelled")
+}
@@ -0,0 +1,169 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/codec"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	swapindexerv1 "github.com/aman-zulfiqar/solana-swap-indexer/proto/swapindexer/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SubscribeSwaps streams every swap on the feed that matches the filter
+func (s *Server) SubscribeSwaps(req *swapindexerv1.SwapFilter, stream grpc.ServerStreamingServer[swapindexerv1.SwapEvent]) error {
+	ctx := stream.Context()
+	filter := newSwapFilter(req)
+	swaps, err := s.src.SubscribeSwaps(ctx)
+	if err != nil {
+		return status.Error(codes.Unavailable, "swap feed unavailable")
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case swap, ok := <-swaps:
+			if !ok {
+				return s.feedClosed(ctx)
+			}
+			if !filter.Match(swap) {
+				continue
+			}
+			if err := stream.Send(codec.SwapToProto(swap)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// SubscribePrices sends the cached price of each requested token, then the
+// price every swap into one of them sets (the same update the indexer writes
+// to Redis)
+func (s *Server) SubscribePrices(req *swapindexerv1.PriceSubscription, stream grpc.ServerStreamingServer[swapindexerv1.TokenPrice]) error {
+	ctx := stream.Context()
+	want := make(map[string]bool, len(req.GetTokens()))
+	for _, t := range req.GetTokens() {
+		if t = strings.ToUpper(strings.TrimSpace(t)); t != "" {
+			want[t] = true
+		}
+	}
+	if len(want) == 0 || len(want) > maxPriceTokens {
+		return status.Errorf(codes.InvalidArgument, "tokens must list between 1 and %d tokens", maxPriceTokens)
+	}
+
+	// Subscribe before reading the snapshot so no update falls in between
+	swaps, err := s.src.SubscribeSwaps(ctx)
+	if err != nil {
+		return status.Error(codes.Unavailable, "swap feed unavailable")
+	}
+	for token := range want {
+		p, err := s.src.GetPrice(ctx, token)
+		if err != nil {
+			return status.Errorf(codes.Unavailable, "failed to get price of %s", token)
+		}
+		if p == nil {
+			continue
+		}
+		if err := stream.Send(tokenPriceProto(p)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case swap, ok := <-swaps:
+			if !ok {
+				return s.feedClosed(ctx)
+			}
+			if !want[swap.TokenOut] {
+				continue
+			}
+			p := models.TokenPrice{Token: swap.TokenOut, Price: swap.Price, UpdatedAt: time.Now().UTC()}
+			if err := stream.Send(tokenPriceProto(&p)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// feedClosed is the error of a subscription whose swap feed ended
+func (s *Server) feedClosed(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	s.logger.Warn("swap feed closed, ending grpc subscription")
+	return status.Error(codes.Unavailable, "swap feed closed")
+}
+
+// GetRecentSwaps answers like GET /v1/swaps/recent
+func (s *Server) GetRecentSwaps(ctx context.Context, req *swapindexerv1.RecentSwapsRequest) (*swapindexerv1.RecentSwapsResponse, error) {
+	limit := req.GetLimit()
+	if limit == 0 {
+		limit = 100
+	}
+	if limit < 1 || limit > 200 {
+		return nil, status.Error(codes.InvalidArgument, "limit must be between 1 and 200")
+	}
+
+	var (
+		items []*models.SwapEvent
+		err   error
+	)
+	if pair := strings.ToUpper(strings.TrimSpace(req.GetPair())); pair != "" {
+		items, err = s.src.GetRecentSwapsByPair(ctx, pair, limit)
+	} else {
+		items, err = s.src.GetRecentSwaps(ctx, limit)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, "failed to get swaps")
+	}
+	return recentSwapsProto(items), nil
+}
+
+// GetPrice answers like GET /v1/prices/{token}
+func (s *Server) GetPrice(ctx context.Context, req *swapindexerv1.PriceRequest) (*swapindexerv1.PriceResponse, error) {
+	token, err := tokenArg(req.GetToken())
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.src.GetPrice(ctx, token)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, "failed to get price")
+	}
+	stale := p == nil || p.StaleAt(time.Now(), s.cfg.PriceStaleAfter)
+	return priceResponseProto(token, p, stale), nil
+}
+
+// GetPriceHistory answers like GET /v1/prices/{token}/history
+func (s *Server) GetPriceHistory(ctx context.Context, req *swapindexerv1.PriceHistoryRequest) (*swapindexerv1.PriceHistoryResponse, error) {
+	token, err := tokenArg(req.GetToken())
+	if err != nil {
+		return nil, err
+	}
+	window := 15 * time.Minute
+	if req.GetWindow() != "" {
+		window, err = time.ParseDuration(req.GetWindow())
+		if err != nil || window < time.Second || window > 24*time.Hour {
+			return nil, status.Error(codes.InvalidArgument, "window must be a duration between 1s and 24h")
+		}
+	}
+	points, err := s.src.GetPriceHistory(ctx, token, time.Now().Add(-window))
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, "failed to get price history")
+	}
+	return priceHistoryProto(token, window, points), nil
+}
+
+// tokenArg returns the token of a request, upper-cased
+func tokenArg(token string) (string, error) {
+	token = strings.ToUpper(strings.TrimSpace(token))
+	if token == "" || len(token) > 64 {
+		return "", status.Error(codes.InvalidArgument, "token is required (at most 64 characters)")
+	}
+	return token, nil
+}
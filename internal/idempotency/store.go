@@ -0,0 +1,143 @@
+// Package idempotency records the outcome of requests that carry an
+// Idempotency-Key, so a client retrying after a timeout gets the stored
+// response back instead of repeating a side effect such as an on-chain swap.
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrInProgress is returned while the first request with a key is still running
+	ErrInProgress = errors.New("a request with this idempotency key is in progress")
+	// ErrMismatch is returned when a key is reused for a different request
+	ErrMismatch = errors.New("idempotency key was used for a different request")
+)
+
+// keyRe bounds client-chosen keys (UUIDs, ULIDs, "order-123:retry" ...)
+var keyRe = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,255}$`)
+
+// ValidateKey checks an Idempotency-Key header value
+func ValidateKey(key string) error {
+	if !keyRe.MatchString(key) {
+		return fmt.Errorf("invalid idempotency key (1-255 characters of A-Z a-z 0-9 . _ : -)")
+	}
+	return nil
+}
+
+// Record is what is stored under a key. Status is 0 while the request runs.
+type Record struct {
+	Fingerprint string          `json:"fingerprint"` // hash of the request the key was first used for
+	Status      int             `json:"status,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+// Completed reports whether the response has been recorded
+func (r *Record) Completed() bool {
+	return r.Status != 0
+}
+
+// Store keeps idempotency records in Redis for a fixed TTL
+type Store struct {
+	client redis.Cmdable
+	prefix string
+	ttl    time.Duration
+}
+
+// NewStore creates a store; ttl <= 0 uses constants.IdempotencyTTL
+func NewStore(client redis.Cmdable, ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = constants.IdempotencyTTL
+	}
+	return &Store{client: client, prefix: constants.RedisKeyIdempotencyPrefix, ttl: ttl}
+}
+
+// Begin claims key for the request with the given fingerprint. It returns
+// (nil, nil) when the caller now owns the key and must Complete it, or the
+// completed record of an earlier request to replay. A key still being worked
+// on yields ErrInProgress and a key used for another request ErrMismatch.
+//
+// A claim is held for the whole TTL: if the process dies mid-request the key
+// stays in progress rather than letting a retry run the side effect twice.
+func (s *Store) Begin(ctx context.Context, key, fingerprint string) (*Record, error) {
+	b, err := json.Marshal(Record{Fingerprint: fingerprint, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		return nil, err
+	}
+	// a record can expire between SETNX and GET; one retry covers that
+	for attempt := 0; attempt < 2; attempt++ {
+		ok, err := s.client.SetNX(ctx, s.prefix+key, b, s.ttl).Result()
+		if err != nil {
+			return nil, fmt.Errorf("claim idempotency key: %w", err)
+		}
+		if ok {
+			return nil, nil
+		}
+
+		data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read idempotency key: %w", err)
+		}
+		var rec Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("decode idempotency record: %w", err)
+		}
+		if rec.Fingerprint != fingerprint {
+			return nil, ErrMismatch
+		}
+		if !rec.Completed() {
+			return nil, ErrInProgress
+		}
+		return &rec, nil
+	}
+	return nil, ErrInProgress
+}
+
+// Complete stores the response of a claimed key for replay; the TTL restarts
+// so retries are answered for a full TTL after the request finished
+func (s *Store) Complete(ctx context.Context, key, fingerprint string, status int, body []byte) error {
+	now := time.Now().UTC()
+	rec := Record{Fingerprint: fingerprint, Status: status, Body: body, CreatedAt: now, CompletedAt: &now}
+	if created, err := s.get(ctx, key); err == nil {
+		rec.CreatedAt = created.CreatedAt
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, s.prefix+key, b, s.ttl).Err(); err != nil {
+		return fmt.Errorf("store idempotency record: %w", err)
+	}
+	return nil
+}
+
+// Release forgets a claimed key whose request had no side effect (e.g. it was
+// refused), so a retry with the same key runs again
+func (s *Store) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+func (s *Store) get(ctx context.Context, key string) (*Record, error) {
+	data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var rec Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
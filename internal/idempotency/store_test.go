@@ -0,0 +1,49 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/redistest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestRedis(t *testing.T) *redis.Client {
+	return redistest.Client(t, redistest.DBIdempotency)
+}
+
+func TestValidateKey(t *testing.T) {
+	assert.NoError(t, ValidateKey("3f1c2a9e-7b44-4d1e-9a8c-0f6b2e5d7c11"))
+	assert.NoError(t, ValidateKey("order-42:retry.1"))
+	assert.Error(t, ValidateKey(""))
+	assert.Error(t, ValidateKey("has space"))
+	assert.Error(t, ValidateKey(string(make([]byte, 256))))
+}
+
+func TestStoreLifecycle(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(setupTestRedis(t), time.Minute)
+
+	rec, err := s.Begin(ctx, "k1", "fp-a")
+	require.NoError(t, err)
+	assert.Nil(t, rec, "first request owns the key")
+
+	_, err = s.Begin(ctx, "k1", "fp-a")
+	assert.ErrorIs(t, err, ErrInProgress)
+
+	_, err = s.Begin(ctx, "k1", "fp-b")
+	assert.ErrorIs(t, err, ErrMismatch)
+
+	require.NoError(t, s.Complete(ctx, "k1", "fp-a", 200, []byte(`{"signature":"sig"}`)))
+
+	rec, err = s.Begin(ctx, "k1", "fp-a")
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+	assert.True(t, rec.Completed())
+	assert.Equal(t, 200, rec.Status)
+	assert.JSONEq(t, `{"signature":"sig"}`, string(rec.Body))
+	assert.NotNil(t, rec.CompletedAt)
+}
@@ -0,0 +1,140 @@
+package indexer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/tokens"
+	"github.com/gagliardetto/solana-go"
+)
+
+// Enrichers returns the stages that label tokens and value swaps in USD, in
+// that order; registry labels mints outside constants.TokenSymbols with
+// their Metaplex symbol (nil: they are shortened)
+func Enrichers(registry *tokens.Registry) []SwapProcessor {
+	var labeler TokenLabeler
+	if registry != nil {
+		labeler = registry
+	}
+	return []SwapProcessor{NewTokenProcessor(labeler), NewUSDProcessor(0)}
+}
+
+// TokenLabeler names token mints (implemented by *tokens.Registry)
+type TokenLabeler interface {
+	Label(mint string) string
+}
+
+// TokenProcessor replaces the mints the poller puts in TokenIn and TokenOut
+// with token labels and rebuilds Pair from them. Tokens that are not a mint
+// address, e.g. on swaps labeled already, are left as they are.
+type TokenProcessor struct {
+	labeler TokenLabeler
+}
+
+// NewTokenProcessor labels mints with labeler; nil labels the mints of
+// constants.TokenSymbols and shortens the others
+func NewTokenProcessor(labeler TokenLabeler) *TokenProcessor {
+	return &TokenProcessor{labeler: labeler}
+}
+
+func (p *TokenProcessor) Name() string { return StageTokens }
+
+func (p *TokenProcessor) Process(_ context.Context, swap *models.SwapEvent) (string, error) {
+	in, out := p.label(swap.TokenIn), p.label(swap.TokenOut)
+	if in != swap.TokenIn || out != swap.TokenOut {
+		swap.TokenIn, swap.TokenOut = in, out
+		swap.Pair = in + "/" + out
+	}
+	return "", nil
+}
+
+func (p *TokenProcessor) label(token string) string {
+	if _, err := solana.PublicKeyFromBase58(token); err != nil {
+		return token
+	}
+	if p.labeler != nil {
+		return p.labeler.Label(token)
+	}
+	if symbol, ok := constants.TokenSymbols[token]; ok {
+		return symbol
+	}
+	return tokens.ShortLabel(token)
+}
+
+// USDProcessor sets ValueUSD: the amount of a stablecoin leg, else either
+// leg at the USD price its token last traded at against a stablecoin, if
+// that trade is at most maxAge older or newer than the swap. Swaps it cannot
+// value keep 0. Run it after the tokens stage, as it goes by token labels.
+type USDProcessor struct {
+	maxAge time.Duration
+
+	mu     sync.Mutex
+	prices map[string]usdPrice // by token label
+}
+
+type usdPrice struct {
+	price float64
+	at    time.Time
+}
+
+// NewUSDProcessor values swaps with USD prices up to maxAge apart from them
+// (default constants.PriceStaleAfter)
+func NewUSDProcessor(maxAge time.Duration) *USDProcessor {
+	if maxAge <= 0 {
+		maxAge = constants.PriceStaleAfter
+	}
+	return &USDProcessor{maxAge: maxAge, prices: map[string]usdPrice{}}
+}
+
+func (p *USDProcessor) Name() string { return StageUSD }
+
+func (p *USDProcessor) Process(_ context.Context, swap *models.SwapEvent) (string, error) {
+	switch {
+	case isStablecoin(swap.TokenIn):
+		swap.ValueUSD = swap.AmountIn
+		p.learn(swap.TokenOut, swap.AmountIn, swap.AmountOut, swap.Timestamp)
+	case isStablecoin(swap.TokenOut):
+		swap.ValueUSD = swap.AmountOut
+		p.learn(swap.TokenIn, swap.AmountOut, swap.AmountIn, swap.Timestamp)
+	default:
+		if price, ok := p.price(swap.TokenIn, swap.Timestamp); ok {
+			swap.ValueUSD = swap.AmountIn * price
+		} else if price, ok := p.price(swap.TokenOut, swap.Timestamp); ok {
+			swap.ValueUSD = swap.AmountOut * price
+		}
+	}
+	return "", nil
+}
+
+// learn records token's USD price from usd dollars traded for amount of it,
+// unless a later trade set it already
+func (p *USDProcessor) learn(token string, usd, amount float64, at time.Time) {
+	if amount <= 0 || isStablecoin(token) {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if last, ok := p.prices[token]; ok && last.at.After(at) {
+		return
+	}
+	p.prices[token] = usdPrice{price: usd / amount, at: at}
+}
+
+// price returns token's USD price if it was learned within maxAge of at
+func (p *USDProcessor) price(token string, at time.Time) (float64, bool) {
+	p.mu.Lock()
+	last, ok := p.prices[token]
+	p.mu.Unlock()
+	if !ok || at.Sub(last.at).Abs() > p.maxAge {
+		return 0, false
+	}
+	return last.price, true
+}
+
+// isStablecoin reports whether a token label is a dollar stablecoin
+func isStablecoin(token string) bool {
+	return constants.TokenDetails[token].Category == constants.TokenCategoryStablecoin
+}
@@ -0,0 +1,97 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mintSOL   = "So11111111111111111111111111111111111111112"
+	mintUSDC  = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	mintOther = "whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc"
+)
+
+type fakeLabeler map[string]string
+
+func (l fakeLabeler) Label(mint string) string { return l[mint] }
+
+func TestTokenProcessor(t *testing.T) {
+	ctx := context.Background()
+
+	s := &models.SwapEvent{TokenIn: mintSOL, TokenOut: mintOther, Pair: mintSOL + "/" + mintOther}
+	_, err := NewTokenProcessor(nil).Process(ctx, s)
+	require.NoError(t, err)
+	assert.Equal(t, "SOL", s.TokenIn)
+	assert.Equal(t, "whir...tyCc", s.TokenOut, "unknown mints are shortened")
+	assert.Equal(t, "SOL/whir...tyCc", s.Pair)
+
+	s = &models.SwapEvent{TokenIn: mintOther, TokenOut: mintUSDC}
+	_, err = NewTokenProcessor(fakeLabeler{mintOther: "ORCA", mintUSDC: "USDC"}).Process(ctx, s)
+	require.NoError(t, err)
+	assert.Equal(t, "ORCA/USDC", s.Pair, "the labeler names the mints")
+
+	s = &models.SwapEvent{TokenIn: "SOL", TokenOut: "USDC", Pair: "SOL/USDC"}
+	_, err = NewTokenProcessor(fakeLabeler{}).Process(ctx, s)
+	require.NoError(t, err)
+	assert.Equal(t, "SOL/USDC", s.Pair, "labels are left as they are")
+}
+
+func TestUSDProcessor(t *testing.T) {
+	ctx := context.Background()
+	p := NewUSDProcessor(time.Minute)
+	now := time.Now()
+
+	buy := &models.SwapEvent{TokenIn: "USDC", TokenOut: "SOL", AmountIn: 300, AmountOut: 2, Timestamp: now}
+	_, err := p.Process(ctx, buy)
+	require.NoError(t, err)
+	assert.Equal(t, 300.0, buy.ValueUSD, "valued by the stablecoin leg")
+
+	cross := &models.SwapEvent{TokenIn: "SOL", TokenOut: "BONK", AmountIn: 0.5, Timestamp: now.Add(30 * time.Second)}
+	_, err = p.Process(ctx, cross)
+	require.NoError(t, err)
+	assert.InDelta(t, 75.0, cross.ValueUSD, 1e-9, "valued at the SOL price learned from the USDC swap")
+
+	cross = &models.SwapEvent{TokenIn: "BONK", TokenOut: "SOL", AmountOut: 1, Timestamp: now}
+	_, err = p.Process(ctx, cross)
+	require.NoError(t, err)
+	assert.InDelta(t, 150.0, cross.ValueUSD, 1e-9, "valued by the out leg when the in leg has no price")
+
+	stale := &models.SwapEvent{TokenIn: "SOL", TokenOut: "BONK", AmountIn: 1, Timestamp: now.Add(2 * time.Minute)}
+	_, err = p.Process(ctx, stale)
+	require.NoError(t, err)
+	assert.Zero(t, stale.ValueUSD, "prices older than maxAge are not used")
+}
+
+func TestIndexer_EnrichStages(t *testing.T) {
+	ctx := context.Background()
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	mc := cache.NewMemoryCache(10, 0)
+
+	idx := New(Config{
+		Cache:          mc,
+		Store:          &fakeStore{},
+		Logger:         logger,
+		Processors:     Enrichers(nil),
+		DisabledStages: []string{StageUSD},
+	})
+
+	s := &models.SwapEvent{Signature: "sig1", TokenIn: mintSOL, TokenOut: mintUSDC, AmountOut: 150, Price: 150}
+	require.NoError(t, idx.ProcessSwap(ctx, s))
+	assert.Equal(t, "SOL/USDC", s.Pair)
+	assert.Zero(t, s.ValueUSD, "usd stage switched off")
+
+	require.True(t, idx.SetStageEnabled(StageUSD, true))
+	require.True(t, idx.SetStageEnabled(StageTokens, false))
+	s = &models.SwapEvent{Signature: "sig2", TokenIn: mintSOL, TokenOut: mintUSDC, AmountOut: 150, Price: 150}
+	require.NoError(t, idx.ProcessSwap(ctx, s))
+	assert.Equal(t, mintSOL, s.TokenIn, "tokens stage switched off")
+	assert.Zero(t, s.ValueUSD, "mints are not valued")
+}
@@ -0,0 +1,71 @@
+package indexer
+
+import (
+	"strings"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// Reasons a swap is filtered out, as reported by the
+// indexer_swaps_filtered_total metric
+const (
+	FilterMinAmount = "min_amount"
+	FilterDenied    = "token_denied"
+	FilterNotAllow  = "token_not_allowed"
+	FilterDex       = "dex"
+)
+
+// Filter selects which swaps are indexed. Swaps it rejects are acknowledged
+// but never written to any sink, so small deployments can keep only the pairs
+// they care about. Token and DEX names match case-insensitively; the zero
+// Filter keeps everything.
+type Filter struct {
+	MinAmount   float64  // smallest amount_in kept, in UI units of the input token
+	AllowTokens []string // if set, both legs of a swap must be listed
+	DenyTokens  []string // swaps touching any of these are dropped
+	Dexes       []string // if set, only swaps on these DEXes are kept
+}
+
+// FilterFromConfig returns the ingestion filter set by INDEXER_FILTER_*
+func FilterFromConfig(cfg *config.Config) Filter {
+	return Filter{
+		MinAmount:   cfg.FilterMinAmount,
+		AllowTokens: cfg.FilterAllowTokens,
+		DenyTokens:  cfg.FilterDenyTokens,
+		Dexes:       cfg.FilterDexes,
+	}
+}
+
+// Reject returns why swap is filtered out, or "" if it is kept
+func (f Filter) Reject(swap *models.SwapEvent) string {
+	if swap.AmountIn < f.MinAmount {
+		return FilterMinAmount
+	}
+	if containsFold(f.DenyTokens, swap.TokenIn) || containsFold(f.DenyTokens, swap.TokenOut) {
+		return FilterDenied
+	}
+	if len(f.AllowTokens) > 0 && (!containsFold(f.AllowTokens, swap.TokenIn) || !containsFold(f.AllowTokens, swap.TokenOut)) {
+		return FilterNotAllow
+	}
+	if len(f.Dexes) > 0 && !containsFold(f.Dexes, swap.Dex) {
+		return FilterDex
+	}
+	return ""
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterReloadHook applies edited INDEXER_FILTER_* settings to idx
+func FilterReloadHook(idx *Indexer) config.ReloadHook {
+	return func(_, next *config.Config) {
+		idx.SetFilter(FilterFromConfig(next))
+	}
+}
@@ -0,0 +1,48 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Reject(t *testing.T) {
+	f := Filter{
+		MinAmount:   1,
+		AllowTokens: []string{"SOL", "USDC", "BONK"},
+		DenyTokens:  []string{"bonk"},
+		Dexes:       []string{"orca"},
+	}
+	swap := func(in, out, dex string, amount float64) *models.SwapEvent {
+		return &models.SwapEvent{TokenIn: in, TokenOut: out, Dex: dex, AmountIn: amount}
+	}
+
+	assert.Empty(t, f.Reject(swap("SOL", "USDC", "Orca", 2)))
+	assert.Equal(t, FilterMinAmount, f.Reject(swap("SOL", "USDC", "Orca", 0.5)))
+	assert.Equal(t, FilterDenied, f.Reject(swap("SOL", "BONK", "Orca", 2)))
+	assert.Equal(t, FilterNotAllow, f.Reject(swap("SOL", "JUP", "Orca", 2)))
+	assert.Equal(t, FilterDex, f.Reject(swap("SOL", "USDC", "Raydium", 2)))
+
+	assert.Empty(t, Filter{}.Reject(swap("JUP", "WIF", "Raydium", 0.001)), "the zero filter keeps everything")
+}
+
+func TestIndexer_FilteredSwapSkipsSinks(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{}
+	idx := newTestIndexer(store, nil)
+	idx.SetFilter(Filter{Dexes: []string{"Raydium"}})
+
+	s := swap(1)
+	s.Dex = "Orca"
+	require.NoError(t, idx.ProcessSwap(ctx, s), "a filtered swap is acknowledged")
+	assert.Empty(t, store.swaps)
+
+	// indexer.filters=false indexes everything again
+	idx.SetFilterEnabled(false)
+	require.NoError(t, idx.ProcessSwap(ctx, s))
+	assert.Equal(t, []string{s.Signature}, store.swaps)
+}
@@ -0,0 +1,270 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// SignatureStatuses reads where indexed transactions stand on chain
+// (implemented by *rpc.Client)
+type SignatureStatuses interface {
+	GetSlot(ctx context.Context, commitment string) (int64, error)
+	GetSignatureStatuses(ctx context.Context, signatures []string) ([]*rpc.SignatureStatus, error)
+	GetSignatureStatus(ctx context.Context, signature string) (*rpc.SignatureStatus, error)
+}
+
+// FinalityTracker follows swaps indexed at confirmed commitment until their
+// block is finalized. Each round it asks the RPC node for the statuses of the
+// swaps at or below the finalized slot: finalized ones are marked so in the
+// store, and ones the node no longer knows, even searching its history, were
+// on a fork the cluster dropped. Those are retracted in the store and a
+// models.SwapRetraction is published so live consumers can back them out.
+// Swaps still unresolved after the timeout stop being tracked and stay
+// unfinalized.
+type FinalityTracker struct {
+	chain      SignatureStatuses
+	store      storage.FinalityStore
+	publisher  storage.RetractionPublisher
+	interval   time.Duration
+	timeout    time.Duration
+	maxTracked int
+	logger     *logrus.Logger
+
+	mu      sync.Mutex
+	pending map[string]trackedSwap // by signature
+}
+
+type trackedSwap struct {
+	swap  *models.SwapEvent
+	since time.Time
+}
+
+// FinalityConfig holds the dependencies of a FinalityTracker
+type FinalityConfig struct {
+	Chain     SignatureStatuses
+	Store     storage.FinalityStore
+	Publisher storage.RetractionPublisher // optional; without it retracted swaps only leave the store
+	Logger    *logrus.Logger
+
+	Interval   time.Duration // between rounds (default constants.FinalityInterval)
+	Timeout    time.Duration // how long a swap is tracked at most (default constants.FinalityTimeout)
+	MaxTracked int           // swaps tracked at once (default constants.FinalityMaxTracked)
+}
+
+// NewFinalityTracker creates a tracker; it follows swaps handed to Track and
+// resolves them while Run runs
+func NewFinalityTracker(cfg FinalityConfig) *FinalityTracker {
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = constants.FinalityInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = constants.FinalityTimeout
+	}
+	if cfg.MaxTracked <= 0 {
+		cfg.MaxTracked = constants.FinalityMaxTracked
+	}
+	return &FinalityTracker{
+		chain:      cfg.Chain,
+		store:      cfg.Store,
+		publisher:  cfg.Publisher,
+		interval:   cfg.Interval,
+		timeout:    cfg.Timeout,
+		maxTracked: cfg.MaxTracked,
+		logger:     cfg.Logger,
+		pending:    make(map[string]trackedSwap),
+	}
+}
+
+// Track follows swap until it is finalized or retracted. Swaps already
+// finalized or without a slot are ignored, and so are new swaps while the
+// tracker is full.
+func (f *FinalityTracker) Track(swap *models.SwapEvent) {
+	if swap.Finalized || swap.Slot == 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.pending[swap.Signature]; ok {
+		return
+	}
+	if len(f.pending) >= f.maxTracked {
+		finalityTotal.With("untracked").Inc()
+		return
+	}
+	f.pending[swap.Signature] = trackedSwap{swap: swap, since: time.Now()}
+	finalityPending.With().Set(float64(len(f.pending)))
+}
+
+// Pending returns how many swaps are awaiting finalization
+func (f *FinalityTracker) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.pending)
+}
+
+// Run resolves tracked swaps every interval until ctx is cancelled
+func (f *FinalityTracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := f.Check(ctx); err != nil && ctx.Err() == nil {
+				f.logger.WithError(err).Warn("failed to check swap finality")
+			}
+		}
+	}
+}
+
+// Check runs one round: it marks the tracked swaps whose block is finalized,
+// retracts those on dropped forks and stops tracking those past the timeout.
+// Swaps it could not resolve because of an error are retried next round.
+func (f *FinalityTracker) Check(ctx context.Context) error {
+	f.mu.Lock()
+	tracked := make([]trackedSwap, 0, len(f.pending))
+	for _, t := range f.pending {
+		tracked = append(tracked, t)
+	}
+	f.mu.Unlock()
+	if len(tracked) == 0 {
+		return nil
+	}
+
+	root, err := f.chain.GetSlot(ctx, "finalized")
+	if err != nil {
+		return err
+	}
+	// a swap above the finalized slot cannot be resolved yet
+	var due []trackedSwap
+	for _, t := range tracked {
+		if t.swap.Slot <= uint64(root) {
+			due = append(due, t)
+		}
+	}
+
+	finalized, dropped, errs := f.statuses(ctx, due)
+	if err := f.store.MarkFinalized(ctx, signatures(finalized)); err != nil {
+		errs = append(errs, err)
+		finalized = nil
+	}
+	f.resolve(finalized, "finalized")
+	retracted, err := f.retract(ctx, dropped)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	f.resolve(retracted, "retracted")
+
+	var expired []trackedSwap
+	cutoff := time.Now().Add(-f.timeout)
+	f.mu.Lock()
+	for _, t := range f.pending {
+		if t.since.Before(cutoff) {
+			expired = append(expired, t)
+		}
+	}
+	f.mu.Unlock()
+	f.resolve(expired, "expired")
+	return errors.Join(errs...)
+}
+
+// statuses sorts due swaps into finalized ones and ones on dropped forks; the
+// rest are still pending. A swap missing from the node's recent status cache
+// is only taken as dropped once a search of its full history misses it too.
+func (f *FinalityTracker) statuses(ctx context.Context, due []trackedSwap) (finalized, dropped []trackedSwap, errs []error) {
+	var missing []trackedSwap
+	for start := 0; start < len(due); start += constants.FinalityStatusBatch {
+		batch := due[start:min(start+constants.FinalityStatusBatch, len(due))]
+		statuses, err := f.chain.GetSignatureStatuses(ctx, signatures(batch))
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		for i, st := range statuses {
+			switch {
+			case st == nil:
+				missing = append(missing, batch[i])
+			case st.ConfirmationStatus == "finalized":
+				finalized = append(finalized, batch[i])
+			}
+		}
+	}
+	for _, t := range missing {
+		st, err := f.chain.GetSignatureStatus(ctx, t.swap.Signature)
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		switch {
+		case st == nil:
+			dropped = append(dropped, t)
+		case st.ConfirmationStatus == "finalized":
+			finalized = append(finalized, t)
+		}
+	}
+	return finalized, dropped, errs
+}
+
+// retract removes swaps on dropped forks from the store and publishes their
+// retractions. It returns the swaps fully retracted, so a failed publish is
+// retried without publishing the others again.
+func (f *FinalityTracker) retract(ctx context.Context, dropped []trackedSwap) ([]trackedSwap, error) {
+	if len(dropped) == 0 {
+		return nil, nil
+	}
+	if err := f.store.RetractSwaps(ctx, signatures(dropped)); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for i, t := range dropped {
+		if f.publisher != nil {
+			rt := &models.SwapRetraction{Swap: t.swap, Reason: models.RetractDroppedFork, RetractedAt: now}
+			if err := f.publisher.PublishRetraction(ctx, rt); err != nil {
+				return dropped[:i], err
+			}
+		}
+		f.logger.WithFields(logrus.Fields{
+			"signature": t.swap.Signature,
+			"pair":      t.swap.Pair,
+			"slot":      t.swap.Slot,
+		}).Warn("swap retracted, its fork was dropped")
+	}
+	return dropped, nil
+}
+
+// resolve stops tracking swaps, counting them under outcome
+func (f *FinalityTracker) resolve(swaps []trackedSwap, outcome string) {
+	if len(swaps) == 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, t := range swaps {
+		if _, ok := f.pending[t.swap.Signature]; ok {
+			delete(f.pending, t.swap.Signature)
+			finalityTotal.With(outcome).Inc()
+		}
+	}
+	finalityPending.With().Set(float64(len(f.pending)))
+}
+
+func signatures(swaps []trackedSwap) []string {
+	sigs := make([]string, len(swaps))
+	for i, t := range swaps {
+		sigs[i] = t.swap.Signature
+	}
+	return sigs
+}
@@ -0,0 +1,129 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChain answers signature statuses from recent (the status cache) and
+// history (the ledger)
+type fakeChain struct {
+	root    int64
+	recent  map[string]*rpc.SignatureStatus
+	history map[string]*rpc.SignatureStatus
+	down    bool
+}
+
+func (c *fakeChain) GetSlot(context.Context, string) (int64, error) {
+	if c.down {
+		return 0, errors.New("rpc unavailable")
+	}
+	return c.root, nil
+}
+
+func (c *fakeChain) GetSignatureStatuses(_ context.Context, sigs []string) ([]*rpc.SignatureStatus, error) {
+	out := make([]*rpc.SignatureStatus, len(sigs))
+	for i, sig := range sigs {
+		out[i] = c.recent[sig]
+	}
+	return out, nil
+}
+
+func (c *fakeChain) GetSignatureStatus(_ context.Context, sig string) (*rpc.SignatureStatus, error) {
+	return c.history[sig], nil
+}
+
+type fakeRetractions struct {
+	down bool
+	got  []*models.SwapRetraction
+}
+
+func (p *fakeRetractions) PublishRetraction(_ context.Context, rt *models.SwapRetraction) error {
+	if p.down {
+		return errors.New("redis unavailable")
+	}
+	p.got = append(p.got, rt)
+	return nil
+}
+
+func TestFinalityTracker(t *testing.T) {
+	ctx := context.Background()
+	finalized := &rpc.SignatureStatus{ConfirmationStatus: "finalized"}
+	chain := &fakeChain{
+		root: 200,
+		recent: map[string]*rpc.SignatureStatus{
+			"final":   finalized,
+			"pending": {ConfirmationStatus: "confirmed"},
+		},
+		history: map[string]*rpc.SignatureStatus{"old": finalized},
+	}
+	store := cache.NewMemoryStore(0)
+	pub := &fakeRetractions{down: true}
+	f := NewFinalityTracker(FinalityConfig{Chain: chain, Store: store, Publisher: pub, Timeout: time.Hour})
+
+	swaps := map[string]*models.SwapEvent{
+		"final":   {Signature: "final", Pair: "SOL/USDC", Slot: 150},
+		"pending": {Signature: "pending", Pair: "SOL/USDC", Slot: 190},
+		"old":     {Signature: "old", Pair: "SOL/USDC", Slot: 100},    // out of the status cache, found in history
+		"forked":  {Signature: "forked", Pair: "SOL/USDC", Slot: 180}, // known nowhere: its fork was dropped
+		"recent":  {Signature: "recent", Pair: "SOL/USDC", Slot: 250}, // above the finalized slot, not checked yet
+	}
+	for _, s := range swaps {
+		require.NoError(t, store.InsertSwap(ctx, s))
+		f.Track(s)
+	}
+	f.Track(&models.SwapEvent{Signature: "read-finalized", Slot: 150, Finalized: true})
+	assert.Equal(t, 5, f.Pending())
+
+	// the retraction is retried while it cannot be published
+	require.Error(t, f.Check(ctx))
+	assert.Equal(t, 3, f.Pending(), "final and old are done; forked, pending and recent remain")
+	for sig, want := range map[string]bool{"final": true, "old": true, "pending": false} {
+		got, err := store.GetSwap(ctx, sig)
+		require.NoError(t, err)
+		assert.Equal(t, want, got.Finalized, sig)
+	}
+
+	pub.down = false
+	require.NoError(t, f.Check(ctx))
+	assert.Equal(t, 2, f.Pending())
+	require.Len(t, pub.got, 1)
+	assert.Equal(t, "forked", pub.got[0].Swap.Signature)
+	assert.Equal(t, models.RetractDroppedFork, pub.got[0].Reason)
+	got, err := store.GetSwap(ctx, "forked")
+	require.NoError(t, err)
+	assert.Nil(t, got, "retracted swaps are deleted")
+
+	// an RPC failure leaves everything tracked
+	chain.down = true
+	require.Error(t, f.Check(ctx))
+	assert.Equal(t, 2, f.Pending())
+}
+
+func TestFinalityTrackerTimeout(t *testing.T) {
+	chain := &fakeChain{root: 100, recent: map[string]*rpc.SignatureStatus{"a": {ConfirmationStatus: "confirmed"}}}
+	f := NewFinalityTracker(FinalityConfig{Chain: chain, Store: cache.NewMemoryStore(0), Timeout: time.Nanosecond, MaxTracked: 1})
+	f.Track(&models.SwapEvent{Signature: "a", Slot: 90})
+	f.Track(&models.SwapEvent{Signature: "b", Slot: 95})
+	assert.Equal(t, 1, f.Pending(), "past MaxTracked new swaps are not tracked")
+
+	time.Sleep(time.Millisecond)
+	require.NoError(t, f.Check(context.Background()))
+	assert.Zero(t, f.Pending(), "unresolved past the timeout")
+}
+
+func TestIndexerTracksFinality(t *testing.T) {
+	f := NewFinalityTracker(FinalityConfig{Chain: &fakeChain{}, Store: cache.NewMemoryStore(0)})
+	idx := New(Config{Cache: cache.NewMemoryCache(10, 0), Store: &fakeStore{}, Finality: f})
+	require.NoError(t, idx.ProcessSwap(context.Background(), &models.SwapEvent{Signature: "sig", Pair: "SOL/USDC", Slot: 1}))
+	assert.Equal(t, 1, f.Pending())
+}
@@ -0,0 +1,404 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/leader"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// Indexer orchestrates swap event processing. Each swap first goes through a
+// pipeline of stages (dedup, token labels, USD value, filter) that may enrich
+// or drop it, then to the sinks. A swap is acknowledged to the
+// stream provider (which then checkpoints past it) only once every sink has
+// accepted it or the sinks that failed are recorded in the dead-letter queue,
+// so a briefly unavailable sink never silently loses a swap. Delivery is
+// at-least-once: a swap may reach a sink more than once.
+type Indexer struct {
+	cache       storage.SwapCache
+	store       storage.SwapStore
+	deadLetters storage.DeadLetterQueue
+	logger      *logrus.Logger
+	provider    string
+	instance    string
+
+	drainTimeout time.Duration
+
+	stages   []*stage
+	filter   *filterProcessor
+	sinks    []Sink
+	finality *FinalityTracker
+}
+
+// Config holds the dependencies of an Indexer
+type Config struct {
+	Cache       storage.SwapCache
+	Store       storage.SwapStore
+	DeadLetters storage.DeadLetterQueue // optional; without it a failed sink blocks the checkpoint until it recovers
+	Logger      *logrus.Logger
+
+	// Instance names this replica's claim on the dead-letter queue; it must
+	// differ between replicas (default leader.DefaultID())
+	Instance string
+
+	// Provider names the stream provider swaps come from; it labels their
+	// end-to-end latency (default "rpc")
+	Provider string
+
+	// DrainTimeout bounds how long the swap in flight at shutdown may take to
+	// finish its sink writes (default constants.DrainTimeout)
+	DrainTimeout time.Duration
+
+	// Filter drops swaps before they reach any sink (zero value: keep all)
+	Filter Filter
+
+	// DedupWindow is how many recently delivered swaps are remembered to
+	// drop redeliveries (0 = no dedup stage)
+	DedupWindow int
+
+	// Processors run after dedup and before the filter, e.g. to enrich swaps
+	// (see Enrichers)
+	Processors []SwapProcessor
+
+	// DisabledStages are pipeline stages switched off at start, by name
+	DisabledStages []string
+
+	// Sinks every swap is written to, in order (nil: Store, then Cache)
+	Sinks []Sink
+
+	// Finality follows each written swap until its block is finalized, and
+	// retracts it if its fork is dropped (nil: swaps are not followed)
+	Finality *FinalityTracker
+}
+
+// New creates a new indexer with the given dependencies
+func New(cfg Config) *Indexer {
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = "rpc"
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = constants.DrainTimeout
+	}
+	if cfg.Instance == "" {
+		cfg.Instance = leader.DefaultID()
+	}
+	idx := &Indexer{
+		cache:       cfg.Cache,
+		store:       cfg.Store,
+		deadLetters: cfg.DeadLetters,
+		logger:      cfg.Logger,
+		provider:    cfg.Provider,
+		instance:    cfg.Instance,
+
+		drainTimeout: cfg.DrainTimeout,
+		filter:       &filterProcessor{},
+		sinks:        cfg.Sinks,
+		finality:     cfg.Finality,
+	}
+	if idx.sinks == nil {
+		idx.sinks = []Sink{{SwapSink: storeSink{cfg.Store}}, {SwapSink: cacheSink{cfg.Cache}}}
+	}
+	idx.SetFilter(cfg.Filter)
+
+	var processors []SwapProcessor
+	if cfg.DedupWindow > 0 {
+		processors = append(processors, NewDedupProcessor(cfg.DedupWindow))
+	}
+	processors = append(processors, cfg.Processors...)
+	processors = append(processors, idx.filter)
+	for _, p := range processors {
+		idx.stages = append(idx.stages, &stage{SwapProcessor: p})
+	}
+	for _, name := range cfg.DisabledStages {
+		if !idx.SetStageEnabled(name, false) {
+			idx.logger.WithField("stage", name).Warn("unknown pipeline stage, not disabled")
+		}
+	}
+	return idx
+}
+
+// SetFilter replaces the ingestion filter; swaps already in flight keep the old one
+func (idx *Indexer) SetFilter(f Filter) {
+	idx.filter.filter.Store(&f)
+}
+
+// SetFilterEnabled switches the ingestion filter on or off without forgetting it
+func (idx *Indexer) SetFilterEnabled(enabled bool) {
+	idx.SetStageEnabled(StageFilter, enabled)
+}
+
+// SetStageEnabled switches a pipeline stage on or off; it reports false when
+// there is no stage of that name
+func (idx *Indexer) SetStageEnabled(name string, enabled bool) bool {
+	found := false
+	for _, s := range idx.stages {
+		if strings.EqualFold(s.Name(), name) {
+			s.disabled.Store(!enabled)
+			found = true
+		}
+	}
+	return found
+}
+
+// ProcessSwap runs a swap through the pipeline and writes it to every sink.
+// It returns nil once the swap is safe (dropped by a stage, written
+// everywhere, or queued for redrive) and an error when it must be delivered
+// again.
+func (idx *Indexer) ProcessSwap(ctx context.Context, swap *models.SwapEvent) error {
+	// sinks and stages log with the swap's signature and pair
+	ctx = logging.WithFields(ctx, logrus.Fields{
+		logging.FieldSignature: logging.ShortSignature(swap.Signature),
+		logging.FieldPair:      swap.Pair,
+	})
+	log := logging.Entry(ctx, idx.logger).WithFields(logrus.Fields{
+		"amount_in": swap.AmountIn,
+		"token_in":  swap.TokenIn,
+	})
+
+	for _, s := range idx.stages {
+		if s.disabled.Load() {
+			continue
+		}
+		reason, err := s.Process(ctx, swap)
+		if err != nil {
+			log.WithError(err).WithField("stage", s.Name()).Warn("pipeline stage failed")
+			return fmt.Errorf("%s: %w", s.Name(), err)
+		}
+		if reason != "" {
+			swapsFiltered.With(reason).Inc()
+			logging.Swaps.Debug(log.WithFields(logrus.Fields{"stage": s.Name(), "reason": reason}), "swap filtered out")
+			return nil
+		}
+	}
+
+	start := time.Now()
+	defer func() { processDuration.With().Observe(time.Since(start).Seconds()) }()
+
+	swap.IndexedAt = start.UTC()
+	failed, err := idx.writeSinks(ctx, swap, nil)
+	if err == nil {
+		swapsProcessed.With(swap.Dex).Inc()
+		idx.observeEndToEnd(swap)
+		idx.commit(swap)
+		log.Info("swap processed successfully")
+		return nil
+	}
+	log.WithError(err).WithField("sinks", failed).Warn("failed to write swap to sinks")
+
+	if idx.deadLetters == nil {
+		return err
+	}
+	dl := &storage.DeadLetter{
+		Swap:     swap,
+		Sinks:    failed,
+		Error:    err.Error(),
+		FailedAt: time.Now().UTC(),
+	}
+	if dlqErr := idx.deadLetters.PushDeadLetter(ctx, dl); dlqErr != nil {
+		return errors.Join(err, dlqErr)
+	}
+
+	swapsProcessed.With(swap.Dex).Inc()
+	deadLettered.With().Inc()
+	idx.observeEndToEnd(swap)
+	idx.commit(swap)
+	log.WithField("sinks", failed).Warn("swap queued to dead-letter queue")
+	return nil
+}
+
+// observeEndToEnd records the time from swap's block time to its indexing.
+// Swaps without a block time are skipped, and clock skew never yields a
+// negative latency.
+func (idx *Indexer) observeEndToEnd(swap *models.SwapEvent) {
+	if swap.BlockTime <= 0 {
+		return
+	}
+	latency := swap.IndexedAt.Sub(time.Unix(swap.BlockTime, 0))
+	endToEndLatency.With(idx.provider).Observe(max(latency, 0).Seconds())
+}
+
+// commit tells the stages that track delivered swaps that swap is safe, and
+// hands it to the finality tracker
+func (idx *Indexer) commit(swap *models.SwapEvent) {
+	for _, s := range idx.stages {
+		if c, ok := s.SwapProcessor.(Committer); ok {
+			c.Commit(swap)
+		}
+	}
+	if idx.finality != nil {
+		idx.finality.Track(swap)
+	}
+}
+
+// writeSinks writes a swap to the named sinks (every sink when names is nil)
+// and returns the ones that failed; failures of best-effort sinks are only
+// logged
+func (idx *Indexer) writeSinks(ctx context.Context, swap *models.SwapEvent, names []string) ([]string, error) {
+	var (
+		failed []string
+		errs   []error
+	)
+	for _, name := range names {
+		if !slices.ContainsFunc(idx.sinks, func(s Sink) bool { return s.Name() == name }) {
+			failed = append(failed, name)
+			errs = append(errs, fmt.Errorf("%s: unknown sink", name))
+		}
+	}
+	for _, sink := range idx.sinks {
+		name := sink.Name()
+		if names != nil && !slices.Contains(names, name) {
+			continue
+		}
+		start := time.Now()
+		err := sink.WriteSwap(ctx, swap)
+		sinkWriteDuration.With(name).Observe(time.Since(start).Seconds())
+		if err == nil {
+			continue
+		}
+		sinkFailures.With(name).Inc()
+		if sink.BestEffort {
+			idx.logger.WithError(err).WithFields(logrus.Fields{"sink": name, "signature": swap.Signature}).Warn("best-effort sink failed, swap skipped")
+			continue
+		}
+		failed = append(failed, name)
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+	return failed, errors.Join(errs...)
+}
+
+// RedriveDeadLetters retries up to one batch of dead letters against the sinks
+// that missed them. The batch is claimed first, so replicas redriving at the
+// same time get different entries. Entries that fail again go back to the
+// end of the queue. It returns how many entries were fully delivered.
+func (idx *Indexer) RedriveDeadLetters(ctx context.Context) (int, error) {
+	if idx.deadLetters == nil {
+		return 0, nil
+	}
+
+	batch, err := idx.deadLetters.ClaimDeadLetters(ctx, idx.instance, constants.DeadLetterRedriveBatch)
+	if err != nil || len(batch) == 0 {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, dl := range batch {
+		var requeue *storage.DeadLetter
+		failed, err := idx.writeSinks(ctx, dl.Swap, dl.Sinks)
+		if err == nil {
+			delivered++
+		} else {
+			dl.Sinks = failed
+			dl.Error = err.Error()
+			dl.Attempts++
+			requeue = dl
+		}
+		if err := idx.deadLetters.AckDeadLetter(ctx, idx.instance, requeue); err != nil {
+			// the unhandled rest of the batch goes back for the next round
+			return delivered, errors.Join(err, idx.deadLetters.ReleaseDeadLetters(ctx, idx.instance))
+		}
+	}
+	return delivered, nil
+}
+
+// runRedrive redrives the dead-letter queue periodically until ctx is cancelled
+func (idx *Indexer) runRedrive(ctx context.Context) {
+	ticker := time.NewTicker(constants.DeadLetterRedriveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := idx.RedriveDeadLetters(ctx)
+			if err != nil && ctx.Err() == nil {
+				idx.logger.WithError(err).Warn("failed to redrive dead letters")
+			}
+			if n > 0 {
+				idx.logger.WithField("count", n).Info("redrove dead-lettered swaps")
+			}
+		}
+	}
+}
+
+// Run feeds swaps from src into ProcessSwap, redrives the dead-letter queue
+// and tracks the finality of written swaps until ctx is cancelled.
+// Cancelling ctx only stops pulling new events: the swap in flight keeps a
+// live context for its sink writes (and the provider saves its checkpoint)
+// for up to the drain timeout, and Run returns once it is done, so the
+// caller can close connections afterwards.
+func (idx *Indexer) Run(ctx context.Context, src storage.StreamProvider) error {
+	if idx.deadLetters != nil {
+		go idx.runRedrive(ctx)
+	}
+	if idx.finality != nil {
+		go idx.finality.Run(ctx)
+	}
+
+	workCtx, cancelWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelWork()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+		}
+		idx.logger.WithField("timeout", idx.drainTimeout).Info("draining in-flight swap")
+		select {
+		case <-done:
+		case <-time.After(idx.drainTimeout):
+			idx.logger.Warn("drain timeout reached, abandoning in-flight swap")
+			cancelWork()
+		}
+	}()
+
+	err := src.Start(ctx, func(swap *models.SwapEvent) error {
+		return idx.ProcessSwap(workCtx, swap)
+	})
+	if err != nil && err != context.Canceled {
+		return err
+	}
+	return nil
+}
+
+// Close closes all connections
+func (idx *Indexer) Close() error {
+	var errs []error
+
+	if err := idx.cache.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("cache close: %w", err))
+	}
+
+	if err := idx.store.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("store close: %w", err))
+	}
+
+	for _, s := range idx.sinks {
+		if c, ok := s.SwapSink.(interface{ Close() error }); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s sink close: %w", s.Name(), err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("close errors: %v", errs)
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore is a SwapStore whose inserts fail while down is set
+type fakeStore struct {
+	down  bool
+	swaps []string
+}
+
+func (s *fakeStore) InsertSwap(ctx context.Context, swap *models.SwapEvent) error {
+	if s.down {
+		return errors.New("clickhouse unavailable")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.swaps = append(s.swaps, swap.Signature)
+	return nil
+}
+func (s *fakeStore) Ping(context.Context) error { return nil }
+func (s *fakeStore) Close() error               { return nil }
+
+// fakeDLQ is an in-memory DeadLetterQueue; acks fail once ackFailAfter
+// more have succeeded, if set
+type fakeDLQ struct {
+	down         bool
+	entries      []*storage.DeadLetter
+	claimed      map[string][]*storage.DeadLetter
+	ackFailAfter *int
+}
+
+func (q *fakeDLQ) PushDeadLetter(_ context.Context, dl *storage.DeadLetter) error {
+	if q.down {
+		return errors.New("redis unavailable")
+	}
+	q.entries = append(q.entries, dl)
+	return nil
+}
+func (q *fakeDLQ) ClaimDeadLetters(ctx context.Context, worker string, n int64) ([]*storage.DeadLetter, error) {
+	_ = q.ReleaseDeadLetters(ctx, worker)
+	if q.claimed == nil {
+		q.claimed = map[string][]*storage.DeadLetter{}
+	}
+	k := min(int(n), len(q.entries))
+	batch := slices.Clone(q.entries[:k])
+	q.entries = q.entries[k:]
+	q.claimed[worker] = batch
+	return batch, nil
+}
+func (q *fakeDLQ) AckDeadLetter(_ context.Context, worker string, requeue *storage.DeadLetter) error {
+	if q.ackFailAfter != nil {
+		if *q.ackFailAfter == 0 {
+			return errors.New("redis unavailable")
+		}
+		*q.ackFailAfter--
+	}
+	if len(q.claimed[worker]) == 0 {
+		return errors.New("claim lost")
+	}
+	q.claimed[worker] = q.claimed[worker][1:]
+	if requeue != nil {
+		q.entries = append(q.entries, requeue)
+	}
+	return nil
+}
+func (q *fakeDLQ) ReleaseDeadLetters(_ context.Context, worker string) error {
+	q.entries = append(q.claimed[worker], q.entries...)
+	delete(q.claimed, worker)
+	return nil
+}
+func (q *fakeDLQ) DeadLetterCount(context.Context) (int64, error) {
+	return int64(len(q.entries)), nil
+}
+
+func newTestIndexer(store *fakeStore, dlq storage.DeadLetterQueue) *Indexer {
+	return newTestReplica(store, dlq, "")
+}
+
+func newTestReplica(store *fakeStore, dlq storage.DeadLetterQueue, instance string) *Indexer {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return New(Config{Cache: cache.NewMemoryCache(10, 0), Store: store, DeadLetters: dlq, Logger: logger, Instance: instance})
+}
+
+func swap(n int) *models.SwapEvent {
+	return &models.SwapEvent{Signature: fmt.Sprintf("sig%08d", n), Pair: "SOL/USDC", TokenOut: "USDC", Price: 1}
+}
+
+func TestIndexer_FailedSinkIsDeadLettered(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{down: true}
+	dlq := &fakeDLQ{}
+	idx := newTestIndexer(store, dlq)
+
+	require.NoError(t, idx.ProcessSwap(ctx, swap(1)), "a dead-lettered swap is acknowledged")
+	require.Len(t, dlq.entries, 1)
+	assert.Equal(t, []string{storage.SinkStore}, dlq.entries[0].Sinks)
+
+	// the cache sink still got the swap
+	recent, err := idx.cache.GetRecentSwaps(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, recent, 1)
+
+	// still down: the entry is requeued with another attempt
+	n, err := idx.RedriveDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	require.Len(t, dlq.entries, 1)
+	assert.Equal(t, 1, dlq.entries[0].Attempts)
+
+	store.down = false
+	n, err = idx.RedriveDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Empty(t, dlq.entries)
+	assert.Equal(t, []string{swap(1).Signature}, store.swaps)
+}
+
+func TestIndexer_RedriveClaimsEntries(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{down: true}
+	dlq := &fakeDLQ{}
+	idx := newTestReplica(store, dlq, "a")
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, idx.ProcessSwap(ctx, swap(i)))
+	}
+	signatures := func() (out []string) {
+		for _, dl := range dlq.entries {
+			out = append(out, dl.Swap.Signature)
+		}
+		return out
+	}
+
+	// the second ack fails: the first entry is requeued once, and the rest
+	// of the claim goes back to the head of the queue
+	one := 1
+	dlq.ackFailAfter = &one
+	_, err := idx.RedriveDeadLetters(ctx)
+	require.Error(t, err)
+	assert.Equal(t, []string{swap(2).Signature, swap(3).Signature, swap(1).Signature}, signatures(), "nothing lost or duplicated")
+	assert.Equal(t, 1, dlq.entries[2].Attempts)
+	assert.Empty(t, dlq.claimed["a"])
+
+	// another replica only redrives what "a" does not hold
+	dlq.ackFailAfter, store.down = nil, false
+	_, err = dlq.ClaimDeadLetters(ctx, "a", 1)
+	require.NoError(t, err)
+	n, err := newTestReplica(store, dlq, "b").RedriveDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{swap(3).Signature, swap(1).Signature}, store.swaps)
+	assert.Empty(t, dlq.entries)
+}
+
+func TestIndexer_UnsafeSwapIsNotAcknowledged(t *testing.T) {
+	ctx := context.Background()
+
+	// no dead-letter queue: the provider must deliver the swap again
+	idx := newTestIndexer(&fakeStore{down: true}, nil)
+	assert.Error(t, idx.ProcessSwap(ctx, swap(1)))
+
+	// dead-letter queue unavailable as well
+	idx = newTestIndexer(&fakeStore{down: true}, &fakeDLQ{down: true})
+	assert.Error(t, idx.ProcessSwap(ctx, swap(1)))
+}
+
+func TestIndexer_EndToEndLatency(t *testing.T) {
+	ctx := context.Background()
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	idx := New(Config{Cache: cache.NewMemoryCache(10, 0), Store: &fakeStore{}, Logger: logger, Provider: "test-e2e"})
+	assert.Nil(t, EndToEndLatency("test-e2e"))
+
+	s := swap(1)
+	s.BlockTime = time.Now().Add(-3 * time.Second).Unix()
+	require.NoError(t, idx.ProcessSwap(ctx, s))
+	assert.WithinDuration(t, time.Now(), s.IndexedAt, time.Second)
+
+	// a swap without a block time is indexed but not measured
+	require.NoError(t, idx.ProcessSwap(ctx, swap(2)))
+
+	lat := EndToEndLatency("test-e2e")
+	require.NotNil(t, lat)
+	assert.Equal(t, uint64(1), lat.Swaps)
+	assert.InDelta(t, 3000, lat.P50Ms, 1500)
+	assert.LessOrEqual(t, lat.P50Ms, lat.P99Ms)
+}
+
+// stoppingProvider delivers one swap only after it has been told to stop,
+// like a poller that was mid-transaction when the shutdown signal arrived
+type stoppingProvider struct {
+	err error
+}
+
+func (p *stoppingProvider) Start(ctx context.Context, handler storage.SwapHandler) error {
+	<-ctx.Done()
+	p.err = handler(swap(1))
+	return ctx.Err()
+}
+func (p *stoppingProvider) Stop() error { return nil }
+
+func TestIndexer_RunDrainsInFlightSwap(t *testing.T) {
+	store := &fakeStore{}
+	idx := newTestIndexer(store, nil)
+	idx.drainTimeout = time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	src := &stoppingProvider{}
+	done := make(chan error)
+	go func() { done <- idx.Run(ctx, src) }()
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.NoError(t, src.err, "the in-flight swap keeps a live context")
+	assert.Equal(t, []string{swap(1).Signature}, store.swaps)
+}
@@ -0,0 +1,25 @@
+package indexer
+
+import "github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
+
+var (
+	swapsProcessed = metrics.Default.Counter("indexer_swaps_processed_total",
+		"Swaps accepted by every sink or dead-lettered, by DEX.", "dex")
+	processDuration = metrics.Default.Histogram("indexer_process_duration_seconds",
+		"Time to write one swap to all sinks.", nil)
+	sinkFailures = metrics.Default.Counter("indexer_sink_failures_total",
+		"Swap writes a sink rejected, by sink.", "sink")
+	sinkWriteDuration = metrics.Default.Histogram("indexer_sink_write_seconds",
+		"Time to write one swap to a sink, by sink.", nil, "sink")
+	swapsFiltered = metrics.Default.Counter("indexer_swaps_filtered_total",
+		"Swaps dropped by a pipeline stage (filter, dedup) before reaching any sink, by reason.", "reason")
+	deadLettered = metrics.Default.Counter("indexer_dead_lettered_total",
+		"Swaps queued to the dead-letter queue.")
+	endToEndLatency = metrics.Default.Histogram("indexer_end_to_end_latency_seconds",
+		"Time from a swap's block time to the indexer processing it, by stream provider.",
+		[]float64{0.5, 1, 2, 3, 5, 7.5, 10, 15, 20, 30, 45, 60, 120, 300}, "provider")
+	finalityTotal = metrics.Default.Counter("indexer_finality_total",
+		"Swaps indexed at confirmed commitment that stopped being tracked, by outcome: finalized, retracted (dropped fork), expired (unresolved past the timeout) or untracked (tracker full).", "outcome")
+	finalityPending = metrics.Default.Gauge("indexer_finality_pending",
+		"Swaps indexed at confirmed commitment awaiting finalization.")
+)
@@ -0,0 +1,119 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// Names of the built-in pipeline stages
+const (
+	StageDedup  = "dedup"
+	StageTokens = "tokens"
+	StageUSD    = "usd"
+	StageFilter = "filter"
+)
+
+// SwapProcessor is one stage of the pipeline a swap goes through before the
+// sinks: it may enrich the swap in place or drop it. Process returns the
+// reason a swap is dropped ("" to pass it on), or an error to have the swap
+// delivered again.
+type SwapProcessor interface {
+	Name() string
+	Process(ctx context.Context, swap *models.SwapEvent) (drop string, err error)
+}
+
+// Committer is implemented by stages that need to know when a swap they
+// passed on is safe: written to every sink or queued for redrive
+type Committer interface {
+	Commit(swap *models.SwapEvent)
+}
+
+// ProcessorFunc adapts a function to a SwapProcessor named name
+func ProcessorFunc(name string, fn func(ctx context.Context, swap *models.SwapEvent) (string, error)) SwapProcessor {
+	return funcProcessor{name: name, fn: fn}
+}
+
+type funcProcessor struct {
+	name string
+	fn   func(ctx context.Context, swap *models.SwapEvent) (string, error)
+}
+
+func (p funcProcessor) Name() string { return p.name }
+
+func (p funcProcessor) Process(ctx context.Context, swap *models.SwapEvent) (string, error) {
+	return p.fn(ctx, swap)
+}
+
+// stage is a pipeline stage with its on/off switch
+type stage struct {
+	SwapProcessor
+	disabled atomic.Bool
+}
+
+// DropDuplicate is the reason the dedup stage drops a swap
+const DropDuplicate = "duplicate"
+
+// DedupProcessor drops swaps the indexer delivered recently, e.g. when a
+// restarted poller re-reads transactions past its last checkpoint. A swap
+// counts as delivered only once committed, so one the sinks failed to take
+// is still let through when it comes again.
+type DedupProcessor struct {
+	mu    sync.Mutex
+	seen  map[string]struct{}
+	order []string // ring of the keys in seen, oldest at next
+	next  int
+}
+
+// NewDedupProcessor remembers the last size delivered swaps
+func NewDedupProcessor(size int) *DedupProcessor {
+	return &DedupProcessor{seen: make(map[string]struct{}, size), order: make([]string, size)}
+}
+
+func (d *DedupProcessor) Name() string { return StageDedup }
+
+func (d *DedupProcessor) Process(_ context.Context, swap *models.SwapEvent) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.seen[dedupKey(swap)]; ok {
+		return DropDuplicate, nil
+	}
+	return "", nil
+}
+
+func (d *DedupProcessor) Commit(swap *models.SwapEvent) {
+	if len(d.order) == 0 {
+		return
+	}
+	key := dedupKey(swap)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.seen[key]; ok {
+		return
+	}
+	delete(d.seen, d.order[d.next])
+	d.order[d.next] = key
+	d.seen[key] = struct{}{}
+	d.next = (d.next + 1) % len(d.order)
+}
+
+// dedupKey identifies a swap; one transaction may hold several. It leaves
+// out the token labels, which the tokens stage rewrites between Process and
+// Commit.
+func dedupKey(swap *models.SwapEvent) string {
+	return fmt.Sprintf("%s|%s|%d|%d", swap.Signature, swap.PoolAddress, swap.AmountInRaw, swap.AmountOutRaw)
+}
+
+// filterProcessor drops the swaps the ingestion filter rejects
+type filterProcessor struct {
+	filter atomic.Pointer[Filter]
+}
+
+func (p *filterProcessor) Name() string { return StageFilter }
+
+func (p *filterProcessor) Process(_ context.Context, swap *models.SwapEvent) (string, error) {
+	return p.filter.Load().Reject(swap), nil
+}
@@ -0,0 +1,72 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexer_PipelineStages(t *testing.T) {
+	ctx := context.Background()
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	store := &fakeStore{}
+
+	var seen []string
+	tag := ProcessorFunc("tag", func(_ context.Context, s *models.SwapEvent) (string, error) {
+		seen = append(seen, s.Signature)
+		s.Dex = "Tagged"
+		return "", nil
+	})
+	idx := New(Config{
+		Cache:       cache.NewMemoryCache(10, 0),
+		Store:       store,
+		Logger:      logger,
+		Filter:      Filter{Dexes: []string{"Tagged"}},
+		DedupWindow: 2,
+		Processors:  []SwapProcessor{tag},
+	})
+
+	require.NoError(t, idx.ProcessSwap(ctx, swap(1)))
+	assert.Equal(t, []string{swap(1).Signature}, store.swaps, "enriched before the filter ran")
+
+	require.NoError(t, idx.ProcessSwap(ctx, swap(1)))
+	assert.Len(t, store.swaps, 1, "redelivery dropped")
+	assert.Len(t, seen, 1, "dropped before later stages")
+
+	require.True(t, idx.SetStageEnabled(StageDedup, false))
+	require.NoError(t, idx.ProcessSwap(ctx, swap(1)))
+	assert.Len(t, store.swaps, 2, "dedup switched off")
+	assert.False(t, idx.SetStageEnabled("nope", false))
+
+	idx.SetStageEnabled(StageDedup, true)
+	require.NoError(t, idx.ProcessSwap(ctx, swap(2)))
+	require.NoError(t, idx.ProcessSwap(ctx, swap(3)))
+	require.NoError(t, idx.ProcessSwap(ctx, swap(1)))
+	assert.Len(t, store.swaps, 5, "only the last two swaps are remembered")
+}
+
+func TestDedupProcessor_OnlyCommittedSwapsAreDuplicates(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{down: true}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	idx := New(Config{Cache: cache.NewMemoryCache(10, 0), Store: store, Logger: logger, DedupWindow: 10})
+
+	require.Error(t, idx.ProcessSwap(ctx, swap(1)), "no dead-letter queue")
+	store.down = false
+	require.NoError(t, idx.ProcessSwap(ctx, swap(1)))
+	assert.Len(t, store.swaps, 1, "the retry of a failed swap goes through")
+
+	failing := ProcessorFunc("lookup", func(context.Context, *models.SwapEvent) (string, error) {
+		return "", errors.New("metadata unavailable")
+	})
+	idx = New(Config{Cache: cache.NewMemoryCache(10, 0), Store: store, Logger: logger, Processors: []SwapProcessor{failing}})
+	assert.ErrorContains(t, idx.ProcessSwap(ctx, swap(2)), "lookup: metadata unavailable")
+}
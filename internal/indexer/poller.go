@@ -0,0 +1,214 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/leader"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/stream"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/tokens"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// RPCURL returns the RPC endpoint for the configured stream provider
+func RPCURL(cfg *config.Config) (string, error) {
+	if cfg.StreamProvider != "triton" {
+		return cfg.RPCUrl, nil
+	}
+	if cfg.TritonAPIKey == "" {
+		return "", fmt.Errorf("TRITON_API_KEY required when using triton provider")
+	}
+	return fmt.Sprintf("https://api.mainnet.solana.triton.one/%s", cfg.TritonAPIKey), nil
+}
+
+// PollerOptions holds the optional collaborators of a poller
+type PollerOptions struct {
+	Checkpoints storage.CheckpointStore // shared cursor per program address
+	Elector     *leader.Elector         // when set, only programs this replica leads are polled
+	FailedSwaps storage.FailedSwapStore // failed transactions, kept when INDEXER_RECORD_FAILED_SWAPS is on
+	Decimals    tokens.DecimalsCache    // shared mint decimals, learned from parsed balances
+	Logger      *logrus.Logger
+}
+
+// NewPoller creates the RPC poller described by cfg
+func NewPoller(cfg *config.Config, opts PollerOptions) (*stream.RPCPoller, error) {
+	logger := opts.Logger
+	rpcURL, err := RPCURL(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var gate func(string) bool
+	if opts.Elector != nil {
+		gate = opts.Elector.IsLeader
+	}
+
+	var failedSwaps storage.FailedSwapStore
+	if cfg.RecordFailedSwaps {
+		failedSwaps = opts.FailedSwaps
+	}
+
+	rpcClient := newRPCClient(cfg, rpcURL, logger)
+
+	var decimals *tokens.Resolver
+	if opts.Decimals != nil {
+		decimals = tokens.NewResolver(rpcClient, opts.Decimals, logger)
+	}
+
+	return stream.NewRPCPoller(stream.RPCPollerConfig{
+		RPCClient:        rpcClient,
+		ProgramAddresses: cfg.ProgramAddresses,
+		PollInterval:     cfg.PollInterval,
+		BatchSize:        cfg.SignatureBatchSize,
+		TxFetchDelay:     cfg.TxFetchDelay,
+		Commitment:       cfg.StreamCommitment,
+		Mode:             cfg.StreamMode,
+		Logger:           logger,
+		Checkpoints:      opts.Checkpoints,
+		Gate:             gate,
+		FailedSwaps:      failedSwaps,
+		Decimals:         decimals,
+	}), nil
+}
+
+// TokenStore is the ClickHouse side of the token registry
+// (implemented by *cache.ClickHouseStore)
+type TokenStore interface {
+	tokens.SwapRelabeler
+	tokens.TokenCatalog
+}
+
+// NewTokenRegistry fills the ClickHouse tokens table and creates the registry
+// that labels mints outside the static token list with their Metaplex
+// symbol, sharing registrations through store and relabeling stored swaps. It
+// returns nil when INDEXER_REGISTER_TOKENS is off. The caller runs it.
+func NewTokenRegistry(ctx context.Context, cfg *config.Config, store tokens.MetadataStore, swaps TokenStore, logger *logrus.Logger) (*tokens.Registry, error) {
+	if err := tokens.SyncCatalog(ctx, swaps, store); err != nil {
+		logger.WithError(err).Warn("failed to fill the tokens table")
+	}
+	if !cfg.RegisterTokens {
+		return nil, nil
+	}
+	rpcURL, err := RPCURL(cfg)
+	if err != nil {
+		return nil, err
+	}
+	registry := tokens.NewRegistry(tokens.RegistryConfig{
+		Reader:    newRPCClient(cfg, rpcURL, logger),
+		Store:     store,
+		Relabeler: swaps,
+		Catalog:   swaps,
+		Logger:    logger,
+	})
+	if err := registry.Load(ctx); err != nil {
+		logger.WithError(err).Warn("failed to load registered tokens, looking them up again")
+	}
+	return registry, nil
+}
+
+// FinalityTrackerFromConfig creates the tracker following swaps indexed at
+// confirmed commitment until their block is finalized, checking every
+// INDEXER_FINALITY_INTERVAL. It returns nil when the poller reads finalized
+// transactions or the interval is 0. The indexer runs it.
+func FinalityTrackerFromConfig(cfg *config.Config, store storage.FinalityStore, publisher storage.RetractionPublisher, logger *logrus.Logger) (*FinalityTracker, error) {
+	if cfg.StreamCommitment == "finalized" || cfg.FinalityInterval <= 0 {
+		return nil, nil
+	}
+	rpcURL, err := RPCURL(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewFinalityTracker(FinalityConfig{
+		Chain:     newRPCClient(cfg, rpcURL, logger),
+		Store:     store,
+		Publisher: publisher,
+		Logger:    logger,
+		Interval:  cfg.FinalityInterval,
+	}), nil
+}
+
+func newRPCClient(cfg *config.Config, rpcURL string, logger *logrus.Logger) *rpc.Client {
+	return rpc.NewClient(rpc.ClientConfig{
+		BaseURL:      rpcURL,
+		Timeout:      cfg.HTTPTimeout,
+		MaxRetries:   cfg.MaxRetries,
+		RetryBackoff: cfg.RetryBackoff,
+		Logger:       logger,
+	})
+}
+
+// NewSupervisor wraps the poller so it is restarted when it exits or goes
+// STREAM_STALL_TIMEOUT without a swap or a successful poll
+func NewSupervisor(cfg *config.Config, poller *stream.RPCPoller, logger *logrus.Logger) *stream.Supervisor {
+	return stream.NewSupervisor(poller, stream.SupervisorConfig{
+		Name:       cfg.StreamProvider,
+		StallAfter: cfg.StreamStallTimeout,
+		Logger:     logger,
+	})
+}
+
+// WatchFlags keeps the poller paused while the indexer.paused flag is set and
+// the ingestion filter off while indexer.filters is false, reacting to flag
+// flips within seconds instead of polling Redis
+func WatchFlags(ctx context.Context, store *flags.Store, poller *stream.RPCPoller, idx *Indexer, logger *logrus.Logger) {
+	watcher, err := store.Watch(ctx, 0)
+	if err != nil {
+		logger.WithError(err).Warn("failed to watch feature flags")
+		return
+	}
+	poller.SetPaused(watcher.Bool(flags.KeyIndexerPaused, false))
+	idx.SetFilterEnabled(watcher.Bool(flags.KeyIndexerFilters, true))
+	watcher.OnChange(func(ch flags.Change) {
+		switch ch.Key {
+		case flags.KeyIndexerPaused:
+			poller.SetPaused(watcher.Bool(flags.KeyIndexerPaused, false))
+		case flags.KeyIndexerFilters:
+			idx.SetFilterEnabled(watcher.Bool(flags.KeyIndexerFilters, true))
+		}
+	})
+}
+
+// InstanceID names this replica in leases and status reports
+func InstanceID(cfg *config.Config) string {
+	if cfg.InstanceID != "" {
+		return cfg.InstanceID
+	}
+	return leader.DefaultID()
+}
+
+// NewElector creates the leader elector for the polled program addresses, or
+// nil when INDEXER_LEADER_ELECTION is off. The caller runs it.
+func NewElector(cfg *config.Config, client *redis.Client, logger *logrus.Logger) *leader.Elector {
+	if !cfg.LeaderElection {
+		return nil
+	}
+	e := leader.NewElector(client, leader.Config{
+		ID:     InstanceID(cfg),
+		TTL:    cfg.LeaseTTL,
+		Logger: logger,
+	})
+	if cfg.StreamMode == stream.ModeBlocks {
+		e.SetNames([]string{stream.BlockLease}) // one replica reads blocks for every program
+	} else {
+		e.SetNames(cfg.ProgramAddresses)
+	}
+	return e
+}
+
+// ReloadHook applies the reloadable polling settings to poller, and
+// PROGRAM_ADDRESSES with the admin overrides re-read from Redis to programs
+func ReloadHook(poller *stream.RPCPoller, programs *Programs) config.ReloadHook {
+	return func(prev, next *config.Config) {
+		if prev.PollInterval != next.PollInterval {
+			poller.SetPollInterval(next.PollInterval)
+		}
+		programs.Refresh(context.Background(), next.ProgramAddresses)
+		poller.SetBatchSize(next.SignatureBatchSize)
+		poller.SetTxFetchDelay(next.TxFetchDelay)
+	}
+}
@@ -0,0 +1,71 @@
+package indexer
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/leader"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/stream"
+	"github.com/sirupsen/logrus"
+)
+
+// ProgramOverrideStore holds the program addresses added and removed through
+// the admin API (implemented by *cache.RedisCache)
+type ProgramOverrideStore interface {
+	GetProgramOverrides(ctx context.Context) (*models.ProgramOverrides, error)
+}
+
+// Programs keeps the poller (and elector) on PROGRAM_ADDRESSES plus the
+// admin API's overrides
+type Programs struct {
+	store   ProgramOverrideStore // optional
+	poller  *stream.RPCPoller
+	elector *leader.Elector // optional
+	logger  *logrus.Logger
+
+	mu        sync.Mutex
+	base      []string
+	overrides models.ProgramOverrides
+	current   []string
+}
+
+// NewPrograms creates the program list for poller, starting from base
+func NewPrograms(base []string, store ProgramOverrideStore, poller *stream.RPCPoller, elector *leader.Elector, logger *logrus.Logger) *Programs {
+	return &Programs{store: store, poller: poller, elector: elector, logger: logger, base: base, current: base}
+}
+
+// Refresh re-reads the overrides and applies them on top of base. If they
+// cannot be read the previous overrides stay in effect.
+func (p *Programs) Refresh(ctx context.Context, base []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.base = base
+	if p.store != nil {
+		ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		defer cancel()
+		if o, err := p.store.GetProgramOverrides(ctx); err != nil {
+			p.logger.WithError(err).Warn("failed to read program overrides, keeping the previous ones")
+		} else {
+			p.overrides = *o
+		}
+	}
+
+	next := p.overrides.Apply(p.base)
+	if len(next) == 0 {
+		p.logger.Warn("program overrides remove every program, keeping the current list")
+		return
+	}
+	if slices.Equal(next, p.current) {
+		return
+	}
+	p.current = next
+	p.poller.SetProgramAddresses(next)
+	if p.elector != nil && p.poller.Mode() != stream.ModeBlocks {
+		p.elector.SetNames(next)
+	}
+	p.logger.WithField("programs", next).Info("polled programs updated")
+}
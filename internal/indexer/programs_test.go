@@ -0,0 +1,45 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/stream"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeOverrides struct{ o models.ProgramOverrides }
+
+func (f *fakeOverrides) GetProgramOverrides(context.Context) (*models.ProgramOverrides, error) {
+	return &f.o, nil
+}
+
+func polled(p *stream.RPCPoller) []string {
+	var out []string
+	for _, st := range p.Stats() {
+		out = append(out, st.Program)
+	}
+	return out
+}
+
+func TestProgramsApplyOverrides(t *testing.T) {
+	poller := stream.NewRPCPoller(stream.RPCPollerConfig{ProgramAddresses: []string{"prog-a", "prog-b"}})
+	store := &fakeOverrides{}
+	programs := NewPrograms([]string{"prog-a", "prog-b"}, store, poller, nil, logrus.New())
+	ctx := context.Background()
+
+	store.o = models.ProgramOverrides{Added: []string{"prog-c", "prog-a"}, Removed: []string{"prog-b"}}
+	programs.Refresh(ctx, []string{"prog-a", "prog-b"})
+	assert.Equal(t, []string{"prog-a", "prog-c"}, polled(poller))
+
+	// a reload of PROGRAM_ADDRESSES keeps the overrides
+	programs.Refresh(ctx, []string{"prog-b", "prog-d"})
+	assert.Equal(t, []string{"prog-d", "prog-c", "prog-a"}, polled(poller))
+
+	// removing everything would stop ingestion, so it is ignored
+	store.o = models.ProgramOverrides{Removed: []string{"prog-b", "prog-d"}}
+	programs.Refresh(ctx, []string{"prog-b", "prog-d"})
+	assert.Equal(t, []string{"prog-d", "prog-c", "prog-a"}, polled(poller))
+}
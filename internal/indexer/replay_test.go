@@ -0,0 +1,79 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHistory is a SwapHistory over an in-memory slice
+type fakeHistory struct {
+	swaps []*models.SwapEvent
+}
+
+func (h *fakeHistory) ScanSwaps(_ context.Context, q storage.SwapQuery, fn func(*models.SwapEvent) error) error {
+	for _, s := range h.swaps {
+		if s.Timestamp.Before(q.From) || !s.Timestamp.Before(q.To) || (q.Pair != "" && s.Pair != q.Pair) {
+			continue
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fakePublisher records published signatures
+type fakePublisher struct {
+	published []string
+}
+
+func (p *fakePublisher) PublishSwap(_ context.Context, swap *models.SwapEvent) error {
+	p.published = append(p.published, swap.Signature)
+	return nil
+}
+
+func TestReplay_PublishesMatchingSwapsAtRate(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := &fakeHistory{}
+	for i := range 6 {
+		s := swap(i)
+		s.Timestamp = base.Add(time.Duration(i) * time.Minute)
+		if i%2 == 1 {
+			s.Pair = "BONK/SOL"
+		}
+		history.swaps = append(history.swaps, s)
+	}
+	pub := &fakePublisher{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	start := time.Now()
+	n, err := Replay(context.Background(), ReplayConfig{History: history, Publisher: pub, Rate: 20, Logger: logger}, storage.SwapQuery{
+		From: base,
+		To:   base.Add(5 * time.Minute),
+		Pair: "SOL/USDC",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []string{swap(0).Signature, swap(2).Signature, swap(4).Signature}, pub.published)
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond, "three swaps at 20/s take two intervals")
+}
+
+func TestReplay_StopsWhenCancelled(t *testing.T) {
+	history := &fakeHistory{swaps: []*models.SwapEvent{swap(1), swap(2)}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	n, err := Replay(ctx, ReplayConfig{History: history, Publisher: &fakePublisher{}, Rate: 1}, storage.SwapQuery{To: time.Now()})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, n)
+}
@@ -0,0 +1,179 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/consumer"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+)
+
+// Built-in sink types accepted in INDEXER_SINKS
+const (
+	SinkClickHouse = "clickhouse" // the swaps table; named storage.SinkStore
+	SinkRedis      = "redis"      // recent lists, price, Pub/Sub and stream; named storage.SinkCache
+	SinkWebhook    = "webhook"    // POST of each swap as JSON
+	SinkFile       = "file"       // NDJSON, one swap per line
+	SinkCSV        = "csv"
+)
+
+// Sink is a destination of the indexer with how its failures are handled
+type Sink struct {
+	storage.SwapSink
+
+	// BestEffort sinks are skipped on failure: the write is counted and
+	// logged but never dead-lettered, so it cannot hold up the checkpoint
+	BestEffort bool
+}
+
+// SinkConfig describes one sink chosen by INDEXER_SINKS
+type SinkConfig struct {
+	Type    string            // a registered sink type
+	URL     string            // webhook: endpoint receiving a POST per swap
+	Headers map[string]string // webhook: extra request headers
+	Path    string            // file, csv: output path (appended to)
+}
+
+// SinkDeps are the connections the built-in sinks write through
+type SinkDeps struct {
+	Cache storage.SwapCache
+	Store storage.SwapStore
+}
+
+// SinkFactory builds a sink of one type
+type SinkFactory func(cfg SinkConfig, deps SinkDeps) (storage.SwapSink, error)
+
+var (
+	sinkMu        sync.RWMutex
+	sinkFactories = map[string]SinkFactory{
+		SinkClickHouse: func(_ SinkConfig, deps SinkDeps) (storage.SwapSink, error) {
+			if deps.Store == nil {
+				return nil, fmt.Errorf("clickhouse sink: no store")
+			}
+			return storeSink{deps.Store}, nil
+		},
+		SinkRedis: func(_ SinkConfig, deps SinkDeps) (storage.SwapSink, error) {
+			if deps.Cache == nil {
+				return nil, fmt.Errorf("redis sink: no cache")
+			}
+			return cacheSink{deps.Cache}, nil
+		},
+		SinkWebhook: consumerSinkFactory(consumer.SinkWebhook),
+		SinkFile:    consumerSinkFactory(consumer.SinkFile),
+		SinkCSV:     consumerSinkFactory(consumer.SinkCSV),
+	}
+)
+
+// RegisterSink makes a sink type available to INDEXER_SINKS. It is meant to
+// be called from an init function, and panics if typ is already registered.
+func RegisterSink(typ string, factory SinkFactory) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	typ = strings.ToLower(typ)
+	if factory == nil {
+		panic("indexer: RegisterSink factory is nil")
+	}
+	if _, dup := sinkFactories[typ]; dup {
+		panic("indexer: RegisterSink called twice for sink type " + typ)
+	}
+	sinkFactories[typ] = factory
+}
+
+// SinkTypes returns the registered sink types, sorted
+func SinkTypes() []string {
+	sinkMu.RLock()
+	defer sinkMu.RUnlock()
+	types := make([]string, 0, len(sinkFactories))
+	for typ := range sinkFactories {
+		types = append(types, typ)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// NewSink builds a sink of a registered type
+func NewSink(cfg SinkConfig, deps SinkDeps) (storage.SwapSink, error) {
+	sinkMu.RLock()
+	factory, ok := sinkFactories[strings.ToLower(cfg.Type)]
+	sinkMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown sink type %q (want one of %s)", cfg.Type, strings.Join(SinkTypes(), ", "))
+	}
+	return factory(cfg, deps)
+}
+
+// SinksFromConfig builds the sinks set by INDEXER_SINKS, marking those in
+// INDEXER_BEST_EFFORT_SINKS best-effort
+func SinksFromConfig(cfg *config.Config, deps SinkDeps) ([]Sink, error) {
+	var sinks []Sink
+	for _, typ := range cfg.Sinks {
+		sc := SinkConfig{Type: typ, URL: cfg.SinkWebhookURL, Path: cfg.SinkFilePath}
+		if strings.EqualFold(typ, SinkCSV) {
+			sc.Path = cfg.SinkCSVPath
+		}
+		s, err := NewSink(sc, deps)
+		if err != nil {
+			closeSinks(sinks)
+			return nil, err
+		}
+		best := containsFold(cfg.BestEffortSinks, typ) || containsFold(cfg.BestEffortSinks, s.Name())
+		sinks = append(sinks, Sink{SwapSink: s, BestEffort: best})
+	}
+	return sinks, nil
+}
+
+func closeSinks(sinks []Sink) {
+	for _, s := range sinks {
+		if c, ok := s.SwapSink.(interface{ Close() error }); ok {
+			_ = c.Close()
+		}
+	}
+}
+
+// storeSink writes swaps to the SwapStore (ClickHouse)
+type storeSink struct{ store storage.SwapStore }
+
+func (s storeSink) Name() string { return storage.SinkStore }
+
+func (s storeSink) WriteSwap(ctx context.Context, swap *models.SwapEvent) error {
+	return s.store.InsertSwap(ctx, swap)
+}
+
+// cacheSink writes swaps to the SwapCache: cache, price, Pub/Sub and stream
+// writes in a single Redis round trip
+type cacheSink struct{ cache storage.SwapCache }
+
+func (s cacheSink) Name() string { return storage.SinkCache }
+
+func (s cacheSink) WriteSwap(ctx context.Context, swap *models.SwapEvent) error {
+	return s.cache.ProcessSwap(ctx, swap)
+}
+
+// consumerSink writes swaps through one of the subscriber's sinks
+type consumerSink struct {
+	name string
+	sink consumer.Sink
+}
+
+func consumerSinkFactory(typ string) SinkFactory {
+	return func(cfg SinkConfig, _ SinkDeps) (storage.SwapSink, error) {
+		s, err := consumer.NewSink(consumer.SinkConfig{Type: typ, URL: cfg.URL, Headers: cfg.Headers, Path: cfg.Path})
+		if err != nil {
+			return nil, err
+		}
+		return &consumerSink{name: typ, sink: s}, nil
+	}
+}
+
+func (s *consumerSink) Name() string { return s.name }
+
+func (s *consumerSink) WriteSwap(ctx context.Context, swap *models.SwapEvent) error {
+	return s.sink.Handle(ctx, &consumer.Message{Channel: "indexer", Swap: swap})
+}
+
+func (s *consumerSink) Close() error { return s.sink.Close() }
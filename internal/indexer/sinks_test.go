@@ -0,0 +1,84 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSink is a SwapSink whose writes fail while down is set
+type fakeSink struct {
+	name  string
+	down  bool
+	swaps []string
+}
+
+func (s *fakeSink) Name() string { return s.name }
+
+func (s *fakeSink) WriteSwap(_ context.Context, swap *models.SwapEvent) error {
+	if s.down {
+		return errors.New(s.name + " unavailable")
+	}
+	s.swaps = append(s.swaps, swap.Signature)
+	return nil
+}
+
+func TestIndexer_Sinks(t *testing.T) {
+	ctx := context.Background()
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	primary := &fakeSink{name: "primary"}
+	extra := &fakeSink{name: "extra", down: true}
+	dlq := &fakeDLQ{}
+	idx := New(Config{
+		DeadLetters: dlq,
+		Logger:      logger,
+		Sinks:       []Sink{{SwapSink: primary}, {SwapSink: extra, BestEffort: true}},
+	})
+
+	require.NoError(t, idx.ProcessSwap(ctx, swap(1)))
+	assert.Equal(t, []string{"sig00000001"}, primary.swaps)
+	assert.Empty(t, dlq.entries, "a best-effort sink failure is not dead-lettered")
+
+	primary.down = true
+	require.NoError(t, idx.ProcessSwap(ctx, swap(2)))
+	require.Len(t, dlq.entries, 1)
+	assert.Equal(t, []string{"primary"}, dlq.entries[0].Sinks)
+
+	primary.down, extra.down = false, false
+	delivered, err := idx.RedriveDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, []string{"sig00000001", "sig00000002"}, primary.swaps)
+	assert.Empty(t, extra.swaps, "a redrive only writes the sinks that missed the swap")
+}
+
+func TestSinksFromConfig(t *testing.T) {
+	custom := &fakeSink{name: "custom"}
+	RegisterSink("test-custom", func(SinkConfig, SinkDeps) (storage.SwapSink, error) { return custom, nil })
+	assert.Contains(t, SinkTypes(), "test-custom")
+	assert.Panics(t, func() { RegisterSink("test-custom", nil) })
+
+	cfg := &config.Config{Sinks: []string{"clickhouse", "test-custom"}, BestEffortSinks: []string{"custom"}}
+	sinks, err := SinksFromConfig(cfg, SinkDeps{Store: &fakeStore{}})
+	require.NoError(t, err)
+	require.Len(t, sinks, 2)
+	assert.Equal(t, storage.SinkStore, sinks[0].Name())
+	assert.False(t, sinks[0].BestEffort)
+	assert.Same(t, custom, sinks[1].SwapSink)
+	assert.True(t, sinks[1].BestEffort, "best-effort by sink name")
+
+	_, err = SinksFromConfig(&config.Config{Sinks: []string{"kafka"}}, SinkDeps{})
+	assert.ErrorContains(t, err, `unknown sink type "kafka"`)
+
+	_, err = SinksFromConfig(&config.Config{Sinks: []string{"redis"}}, SinkDeps{})
+	assert.Error(t, err, "redis sink without a cache")
+}
@@ -0,0 +1,105 @@
+package indexer
+
+import (
+	"context"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/stream"
+	"github.com/sirupsen/logrus"
+)
+
+// StatusStore receives the periodic status reports of a replica
+type StatusStore interface {
+	PutIndexerStatus(ctx context.Context, st *models.IndexerStatus, ttl time.Duration) error
+}
+
+// StatusReporter builds this replica's status from its metrics and publishes
+// it, so the API can summarise every running indexer
+type StatusReporter struct {
+	instance string
+	poller   *stream.RPCPoller
+	stream   *stream.Supervisor // optional
+	started  time.Time
+
+	lastAt    time.Time
+	lastSwaps float64
+}
+
+// NewStatusReporter creates a reporter for the replica named instance
+func NewStatusReporter(instance string, poller *stream.RPCPoller) *StatusReporter {
+	now := time.Now().UTC()
+	return &StatusReporter{instance: instance, poller: poller, started: now, lastAt: now}
+}
+
+// WithStream adds the supervisor's view of the stream provider to the reports
+func (s *StatusReporter) WithStream(sup *stream.Supervisor) *StatusReporter {
+	s.stream = sup
+	return s
+}
+
+// Status returns the current status; the swap rate covers the time since the previous call
+func (s *StatusReporter) Status() *models.IndexerStatus {
+	now := time.Now().UTC()
+	processed := swapsProcessed.Sum()
+	latency := processDuration.With()
+
+	st := &models.IndexerStatus{
+		Instance:       s.instance,
+		StartedAt:      s.started,
+		UpdatedAt:      now,
+		SwapsProcessed: processed,
+		SinkFailures:   sinkFailures.Sum(),
+		DeadLettered:   deadLettered.Sum(),
+
+		ProcessLatencyP50Ms: latency.Quantile(0.5) * 1000,
+		ProcessLatencyP99Ms: latency.Quantile(0.99) * 1000,
+
+		ChainSlot: s.poller.ChainSlot(),
+		Programs:  s.poller.Stats(),
+	}
+	if s.stream != nil {
+		h := s.stream.Health()
+		st.Stream = &h
+		st.EndToEnd = EndToEndLatency(h.Provider)
+	}
+	if elapsed := now.Sub(s.lastAt).Seconds(); elapsed > 0 {
+		st.SwapsPerSecond = (processed - s.lastSwaps) / elapsed
+	}
+	s.lastAt, s.lastSwaps = now, processed
+	return st
+}
+
+// EndToEndLatency summarises the end-to-end latency of the swaps this process
+// indexed from provider, or returns nil before the first one
+func EndToEndLatency(provider string) *models.EndToEndLatency {
+	h := endToEndLatency.With(provider)
+	if h.Count() == 0 {
+		return nil
+	}
+	return &models.EndToEndLatency{
+		Provider: provider,
+		Swaps:    h.Count(),
+		P50Ms:    h.Quantile(0.5) * 1000,
+		P95Ms:    h.Quantile(0.95) * 1000,
+		P99Ms:    h.Quantile(0.99) * 1000,
+	}
+}
+
+// Run publishes the status every interval until ctx is cancelled. Each report
+// expires after three intervals, so replicas that stop drop out of the summary.
+func (s *StatusReporter) Run(ctx context.Context, store StatusStore, interval time.Duration, logger *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := store.PutIndexerStatus(ctx, s.Status(), 3*interval); err != nil && ctx.Err() == nil {
+				logger.WithError(err).Warn("failed to publish indexer status")
+			}
+		}
+	}
+}
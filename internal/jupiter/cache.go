@@ -0,0 +1,108 @@
+package jupiter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/redis/go-redis/v9"
+)
+
+// QuoteCache keeps Jupiter quotes in Redis for a short TTL, so clients polling
+// the same quote share one upstream call
+type QuoteCache struct {
+	client redis.Cmdable
+	prefix string
+	ttl    time.Duration
+}
+
+// NewQuoteCache creates a cache; ttl <= 0 uses constants.QuoteCacheTTL
+func NewQuoteCache(client redis.Cmdable, ttl time.Duration) *QuoteCache {
+	if ttl <= 0 {
+		ttl = constants.QuoteCacheTTL
+	}
+	return &QuoteCache{client: client, prefix: constants.RedisKeyQuotePrefix, ttl: ttl}
+}
+
+// Get returns the cached quote for req, or (nil, nil) on a miss
+func (c *QuoteCache) Get(ctx context.Context, req QuoteRequest) (*QuoteResponse, error) {
+	data, err := c.client.Get(ctx, c.prefix+QuoteKey(req)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cached quote: %w", err)
+	}
+	var out QuoteResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("decode cached quote: %w", err)
+	}
+	return &out, nil
+}
+
+// Set stores the quote for req for the cache TTL
+func (c *QuoteCache) Set(ctx context.Context, req QuoteRequest, quote *QuoteResponse) error {
+	b, err := json.Marshal(quote)
+	if err != nil {
+		return err
+	}
+	if err := c.client.Set(ctx, c.prefix+QuoteKey(req), b, c.ttl).Err(); err != nil {
+		return fmt.Errorf("store cached quote: %w", err)
+	}
+	return nil
+}
+
+// QuoteKey hashes every parameter that changes Jupiter's answer. DEX lists are
+// sorted so "Orca,Raydium" and "Raydium,Orca" share an entry.
+func QuoteKey(req QuoteRequest) string {
+	sorted := func(v []string) string {
+		v = slices.Clone(v)
+		slices.Sort(v)
+		return strings.Join(v, ",")
+	}
+	optBool := func(b *bool) string {
+		if b == nil {
+			return ""
+		}
+		return fmt.Sprint(*b)
+	}
+	optUint := func(v any) string {
+		switch n := v.(type) {
+		case *uint16:
+			if n != nil {
+				return fmt.Sprint(*n)
+			}
+		case *uint64:
+			if n != nil {
+				return fmt.Sprint(*n)
+			}
+		}
+		return ""
+	}
+
+	fields := []string{
+		req.InputMint,
+		req.OutputMint,
+		req.Amount,
+		optUint(req.SlippageBps),
+		req.SwapMode,
+		sorted(req.Dexes),
+		sorted(req.ExcludeDexes),
+		optBool(req.RestrictIntermediateTokens),
+		optBool(req.OnlyDirectRoutes),
+		optBool(req.AsLegacyTransaction),
+		optUint(req.PlatformFeeBps),
+		optUint(req.MaxAccounts),
+		req.InstructionVersion,
+		optBool(req.DynamicSlippage),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "|")))
+	return hex.EncodeToString(sum[:])
+}
@@ -3,37 +3,97 @@ package jupiter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+)
+
+// Defaults used when a ClientConfig field is zero
+const (
+	DefaultTimeout        = constants.JupiterTimeout
+	DefaultMaxRetries     = constants.JupiterMaxRetries
+	DefaultRetryBackoff   = constants.JupiterRetryBackoff
+	DefaultMaxBackoff     = constants.JupiterMaxBackoff
+	DefaultMaxConcurrency = constants.JupiterMaxConcurrency
 )
 
+// Client calls the Jupiter Swap API. Network errors, 429 and 5xx responses are
+// retried with exponential backoff and jitter; a 429 Retry-After is honoured.
 type Client struct {
 	BaseURL string
 	APIKey  string
 	HTTP    *http.Client
+
+	maxRetries   int
+	retryBackoff time.Duration
+	maxBackoff   time.Duration
+	sem          chan struct{} // bounds concurrent requests
+}
+
+// ClientConfig holds configuration for the Jupiter client
+type ClientConfig struct {
+	BaseURL        string
+	APIKey         string
+	Timeout        time.Duration // per attempt
+	MaxRetries     int           // attempts after the first (0: no retries)
+	RetryBackoff   time.Duration // delay before the first retry, doubled each time
+	MaxBackoff     time.Duration // cap on a single delay, Retry-After included
+	MaxConcurrency int           // requests in flight at once; callers beyond wait
 }
 
+// NewClient creates a client with the default retry and concurrency settings
 func NewClient(baseURL, apiKey string) *Client {
-	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	return NewClientWithConfig(ClientConfig{BaseURL: baseURL, APIKey: apiKey, MaxRetries: DefaultMaxRetries})
+}
+
+// NewClientWithConfig creates a client, filling zero durations and
+// concurrency with defaults
+func NewClientWithConfig(cfg ClientConfig) *Client {
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
 	if baseURL == "" {
 		baseURL = "https://api.jup.ag/swap/v1"
 	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	cfg.MaxRetries = max(cfg.MaxRetries, 0)
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	if cfg.MaxConcurrency <= 0 {
+		cfg.MaxConcurrency = DefaultMaxConcurrency
+	}
 	return &Client{
 		BaseURL: baseURL,
-		APIKey:  strings.TrimSpace(apiKey),
+		APIKey:  strings.TrimSpace(cfg.APIKey),
 		HTTP: &http.Client{
-			Timeout: 12 * time.Second,
+			Timeout: cfg.Timeout,
 		},
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff,
+		maxBackoff:   cfg.MaxBackoff,
+		sem:          make(chan struct{}, cfg.MaxConcurrency),
 	}
 }
 
+// HTTPError is a non-2xx answer from Jupiter; it matches the apperr code of
+// its status (429 is apperr.RateLimited, 5xx apperr.UpstreamUnavailable)
 type HTTPError struct {
 	StatusCode int
 	Body       []byte
+	RetryAfter time.Duration // from the Retry-After header, 0 when absent
 }
 
 func (e *HTTPError) Error() string {
@@ -44,15 +104,21 @@ func (e *HTTPError) Error() string {
 	return fmt.Sprintf("jupiter http %d: %s", e.StatusCode, b)
 }
 
+// Is reports whether target is the apperr code of the status
+func (e *HTTPError) Is(target error) bool {
+	c, ok := target.(apperr.Code)
+	return ok && c != "" && c == apperr.ForStatus(e.StatusCode)
+}
+
 func (c *Client) Quote(ctx context.Context, req QuoteRequest) (*QuoteResponse, error) {
 	if strings.TrimSpace(req.InputMint) == "" {
-		return nil, fmt.Errorf("inputMint is required")
+		return nil, apperr.New(apperr.ValidationFailed, "inputMint is required")
 	}
 	if strings.TrimSpace(req.OutputMint) == "" {
-		return nil, fmt.Errorf("outputMint is required")
+		return nil, apperr.New(apperr.ValidationFailed, "outputMint is required")
 	}
 	if strings.TrimSpace(req.Amount) == "" {
-		return nil, fmt.Errorf("amount is required")
+		return nil, apperr.New(apperr.ValidationFailed, "amount is required")
 	}
 
 	q := url.Values{}
@@ -94,7 +160,69 @@ func (c *Client) Quote(ctx context.Context, req QuoteRequest) (*QuoteResponse, e
 		q.Set("dynamicSlippage", fmt.Sprintf("%t", *req.DynamicSlippage))
 	}
 
-	u := c.BaseURL + "/quote?" + q.Encode()
+	var out QuoteResponse
+	if err := c.get(ctx, c.BaseURL+"/quote?"+q.Encode(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// get performs a GET with retries and decodes the JSON body into out
+func (c *Client) get(ctx context.Context, u string, out any) error {
+	if c.sem != nil {
+		select {
+		case c.sem <- struct{}{}:
+			defer func() { <-c.sem }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	backoff := c.retryBackoff
+	for attempt := 1; ; attempt++ {
+		body, err := c.do(ctx, u)
+		if err == nil {
+			if err := json.Unmarshal(body, out); err != nil {
+				return fmt.Errorf("failed to decode jupiter quote response: %w", err)
+			}
+			return nil
+		}
+		if attempt > c.maxRetries || !retryable(ctx, err) {
+			return attemptsError(attempt, err)
+		}
+
+		wait := jitter(backoff)
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+			wait = httpErr.RetryAfter
+		}
+		wait = min(wait, c.maxBackoff)
+		// Give up now rather than sleep past the caller's deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return attemptsError(attempt, err)
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return attemptsError(attempt, err)
+		case <-t.C:
+		}
+		backoff *= 2
+	}
+}
+
+// attemptsError notes how many attempts were made before err
+func attemptsError(attempts int, err error) error {
+	if attempts == 1 {
+		return err
+	}
+	return fmt.Errorf("jupiter request failed after %d attempts: %w", attempts, err)
+}
+
+// do performs a single attempt
+func (c *Client) do(ctx context.Context, u string) ([]byte, error) {
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
@@ -106,18 +234,60 @@ func (c *Client) Quote(ctx context.Context, req QuoteRequest) (*QuoteResponse, e
 
 	res, err := c.HTTP.Do(httpReq)
 	if err != nil {
-		return nil, err
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, apperr.Wrap(apperr.UpstreamUnavailable, err)
 	}
 	defer res.Body.Close()
 
 	body, _ := io.ReadAll(res.Body)
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return nil, &HTTPError{StatusCode: res.StatusCode, Body: body}
+		return nil, &HTTPError{
+			StatusCode: res.StatusCode,
+			Body:       body,
+			RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"), time.Now()),
+		}
 	}
+	return body, nil
+}
 
-	var out QuoteResponse
-	if err := json.Unmarshal(body, &out); err != nil {
-		return nil, fmt.Errorf("failed to decode jupiter quote response: %w", err)
+// retryable reports whether a failed attempt is worth repeating: transport
+// errors, 429 and 5xx, but never once the caller's context is done
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
 	}
-	return &out, nil
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+	}
+	return true
+}
+
+// jitter returns a random delay in [d/2, d]
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
 }
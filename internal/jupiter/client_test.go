@@ -0,0 +1,82 @@
+package jupiter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testQuote = QuoteRequest{InputMint: "So11111111111111111111111111111111111111112", OutputMint: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", Amount: "1000"}
+
+func TestQuoteRetriesRateLimitAndServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte(`{"inAmount":"1000","outAmount":"42"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClientWithConfig(ClientConfig{BaseURL: srv.URL, MaxRetries: 2, RetryBackoff: time.Millisecond})
+	out, err := c.Quote(context.Background(), testQuote)
+	require.NoError(t, err)
+	assert.Equal(t, "42", out.OutAmount)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestQuoteDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := NewClientWithConfig(ClientConfig{BaseURL: srv.URL, MaxRetries: 3, RetryBackoff: time.Millisecond})
+	_, err := c.Quote(context.Background(), testQuote)
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestQuoteGivesUpWhenRetryAfterPassesDeadline(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := NewClientWithConfig(ClientConfig{BaseURL: srv.URL, MaxRetries: 3, MaxBackoff: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := c.Quote(ctx, testQuote)
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, 30*time.Second, httpErr.RetryAfter)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, 10*time.Second, parseRetryAfter(now.Add(10*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+}
@@ -0,0 +1,222 @@
+// Package leader implements Redis lease based leader election, so several
+// replicas can run for availability while only one of them does the work for
+// each named resource (e.g. a polled program address) at a time.
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// renewScript extends a lease only while it is still held by the caller
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes a lease only while it is still held by the caller
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Config holds configuration for an Elector
+type Config struct {
+	Prefix string        // lease key prefix, e.g. "leader:indexer:" (default constants.RedisKeyLeaderPrefix)
+	ID     string        // unique replica id (default hostname-pid)
+	TTL    time.Duration // lease lifetime; renewed every TTL/3 (default constants.LeaderLeaseTTL)
+	Logger *logrus.Logger
+}
+
+// Elector contends for a lease on each of a set of names and keeps the ones it
+// wins renewed. A replica that dies stops renewing and its leases expire, so
+// another replica takes over within one TTL.
+type Elector struct {
+	client redis.Cmdable
+	prefix string
+	id     string
+	ttl    time.Duration
+	logger *logrus.Logger
+
+	mu        sync.RWMutex
+	names     []string
+	heldUntil map[string]time.Time // local deadline of each held lease
+}
+
+// NewElector creates an elector; call SetNames and Run to start contending
+func NewElector(client redis.Cmdable, cfg Config) *Elector {
+	if cfg.Prefix == "" {
+		cfg.Prefix = constants.RedisKeyLeaderPrefix
+	}
+	if cfg.ID == "" {
+		cfg.ID = DefaultID()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = constants.LeaderLeaseTTL
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	return &Elector{
+		client:    client,
+		prefix:    cfg.Prefix,
+		id:        cfg.ID,
+		ttl:       cfg.TTL,
+		logger:    cfg.Logger,
+		heldUntil: make(map[string]time.Time),
+	}
+}
+
+// DefaultID identifies this process as hostname-pid
+func DefaultID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// ID returns the replica id written into held leases
+func (e *Elector) ID() string {
+	return e.id
+}
+
+// SetNames replaces the set of names to contend for. Leases on names that are
+// dropped are released on the next round.
+func (e *Elector) SetNames(names []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.names = append([]string(nil), names...)
+}
+
+// IsLeader reports whether this replica currently holds the lease on name.
+// A lease counts as lost once its local deadline passes, even if a renewal
+// has not failed yet, so two replicas never both believe they lead.
+func (e *Elector) IsLeader(name string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return time.Now().Before(e.heldUntil[name])
+}
+
+// Run contends for and renews leases until ctx is cancelled, then releases
+// every held lease so a standby takes over immediately
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		e.round(ctx)
+		select {
+		case <-ctx.Done():
+			e.releaseAll()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// round renews held leases, tries to acquire the others and releases dropped ones
+func (e *Elector) round(ctx context.Context) {
+	e.mu.RLock()
+	names := append([]string(nil), e.names...)
+	var dropped []string
+	for name := range e.heldUntil {
+		if !slices.Contains(names, name) {
+			dropped = append(dropped, name)
+		}
+	}
+	e.mu.RUnlock()
+
+	if len(dropped) > 0 {
+		// bounded like releaseAll, so a stalled Redis cannot hold up the election loop
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		for _, name := range dropped {
+			e.release(releaseCtx, name)
+		}
+		cancel()
+	}
+
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return
+		}
+		start := time.Now()
+		held, err := e.acquireOrRenew(ctx, name)
+		if err != nil {
+			e.logger.WithError(err).WithField("name", name).Warn("leader lease check failed")
+		}
+
+		e.mu.Lock()
+		wasHeld := time.Now().Before(e.heldUntil[name])
+		if held {
+			// measured from before the request, so the local deadline never outlives the lease
+			e.heldUntil[name] = start.Add(e.ttl)
+		} else if err == nil {
+			delete(e.heldUntil, name)
+		}
+		e.mu.Unlock()
+
+		switch {
+		case held && !wasHeld:
+			e.logger.WithFields(logrus.Fields{"name": name, "id": e.id}).Info("acquired leadership")
+		case !held && wasHeld && err == nil:
+			e.logger.WithFields(logrus.Fields{"name": name, "id": e.id}).Warn("lost leadership")
+		}
+	}
+}
+
+// acquireOrRenew extends the lease if this replica holds it, otherwise tries to take it
+func (e *Elector) acquireOrRenew(ctx context.Context, name string) (bool, error) {
+	key := e.prefix + name
+	renewed, err := renewScript.Run(ctx, e.client, []string{key}, e.id, e.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("renew lease: %w", err)
+	}
+	if renewed == 1 {
+		return true, nil
+	}
+	ok, err := e.client.SetNX(ctx, key, e.id, e.ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("acquire lease: %w", err)
+	}
+	return ok, nil
+}
+
+func (e *Elector) releaseAll() {
+	e.mu.RLock()
+	var held []string
+	for name := range e.heldUntil {
+		held = append(held, name)
+	}
+	e.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for _, name := range held {
+		e.release(ctx, name)
+	}
+}
+
+func (e *Elector) release(ctx context.Context, name string) {
+	e.mu.Lock()
+	delete(e.heldUntil, name)
+	e.mu.Unlock()
+
+	if err := releaseScript.Run(ctx, e.client, []string{e.prefix + name}, e.id).Err(); err != nil {
+		e.logger.WithError(err).WithField("name", name).Warn("failed to release leader lease")
+		return
+	}
+	e.logger.WithField("name", name).Info("released leadership")
+}
@@ -0,0 +1,75 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/redistest"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestRedis(t *testing.T) *redis.Client {
+	return redistest.Client(t, redistest.DBLeader)
+}
+
+func newTestElector(client *redis.Client, id string) *Elector {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	e := NewElector(client, Config{Prefix: "test:leader:", ID: id, TTL: time.Second, Logger: logger})
+	e.SetNames([]string{"program-a"})
+	return e
+}
+
+func TestElector_SingleLeaderAndTakeover(t *testing.T) {
+	client := setupTestRedis(t)
+
+	a := newTestElector(client, "a")
+	b := newTestElector(client, "b")
+
+	ctx := context.Background()
+	a.round(ctx)
+	b.round(ctx)
+	assert.True(t, a.IsLeader("program-a"))
+	assert.False(t, b.IsLeader("program-a"))
+
+	// renewals keep the lease with a
+	a.round(ctx)
+	b.round(ctx)
+	assert.True(t, a.IsLeader("program-a"))
+	assert.False(t, b.IsLeader("program-a"))
+
+	// a stops renewing (crashed): b takes over once the lease expires
+	time.Sleep(1100 * time.Millisecond)
+	assert.False(t, a.IsLeader("program-a"), "local deadline passed")
+	b.round(ctx)
+	assert.True(t, b.IsLeader("program-a"))
+
+	// a comes back and must not reclaim it
+	a.round(ctx)
+	assert.False(t, a.IsLeader("program-a"))
+}
+
+func TestElector_ReleaseHandsOver(t *testing.T) {
+	client := setupTestRedis(t)
+
+	a := newTestElector(client, "a")
+	b := newTestElector(client, "b")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.Run(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return a.IsLeader("program-a") }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	b.round(context.Background())
+	assert.True(t, b.IsLeader("program-a"), "released lease is taken immediately")
+}
@@ -0,0 +1,111 @@
+// Package logging configures the logrus loggers of every service from the
+// environment (LOG_LEVEL, LOG_FORMAT, LOG_SAMPLE_*), carries log fields such
+// as the request id, signature and pair through a context, and samples the
+// per-swap debug logs that would otherwise flood a busy indexer.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Formats accepted in LOG_FORMAT
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Field names carried through a context
+const (
+	FieldRequestID = "request_id"
+	FieldSignature = "signature"
+	FieldPair      = "pair"
+)
+
+// Configure applies LOG_LEVEL (falling back to def), LOG_FORMAT and the
+// sampling of Swaps to logger. The text format keeps the logger's timestamp
+// layout; JSON logs carry RFC 3339 timestamps.
+func Configure(logger *logrus.Logger, def logrus.Level) error {
+	logger.SetLevel(def)
+	if v := strings.TrimSpace(os.Getenv("LOG_LEVEL")); v != "" {
+		level, err := logrus.ParseLevel(v)
+		if err != nil {
+			return fmt.Errorf("invalid LOG_LEVEL %q (want debug, info, warn or error)", v)
+		}
+		logger.SetLevel(level)
+	}
+
+	switch format := strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT"))); format {
+	case "", FormatText:
+	case FormatJSON:
+		logger.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	default:
+		return fmt.Errorf("invalid LOG_FORMAT %q (want text or json)", format)
+	}
+
+	initial, err := intEnv("LOG_SAMPLE_INITIAL", DefaultSampleInitial)
+	if err != nil {
+		return err
+	}
+	thereafter, err := intEnv("LOG_SAMPLE_THEREAFTER", DefaultSampleThereafter)
+	if err != nil {
+		return err
+	}
+	Swaps.Configure(initial, thereafter)
+	return nil
+}
+
+func intEnv(key string, def int) (int, error) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q (want a non-negative integer)", key, v)
+	}
+	return n, nil
+}
+
+type fieldsKey struct{}
+
+// WithFields returns a context whose log entries (see Entry) carry fields,
+// on top of any the context already had
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	merged := make(logrus.Fields, len(fields))
+	if prev, ok := ctx.Value(fieldsKey{}).(logrus.Fields); ok {
+		for k, v := range prev {
+			merged[k] = v
+		}
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// Fields returns the log fields of ctx
+func Fields(ctx context.Context) logrus.Fields {
+	fields, _ := ctx.Value(fieldsKey{}).(logrus.Fields)
+	return fields
+}
+
+// Entry returns an entry of logger with the fields of ctx; a nil logger
+// falls back to the standard one
+func Entry(ctx context.Context, logger *logrus.Logger) *logrus.Entry {
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return logger.WithContext(ctx).WithFields(Fields(ctx))
+}
+
+// ShortSignature is the prefix of a signature used in log fields
+func ShortSignature(sig string) string {
+	return sig[:min(8, len(sig))]
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("LOG_SAMPLE_INITIAL", "")
+	t.Setenv("LOG_SAMPLE_THEREAFTER", "")
+	logger := logrus.New()
+	require.NoError(t, Configure(logger, logrus.InfoLevel))
+	assert.Equal(t, logrus.WarnLevel, logger.GetLevel())
+
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	ctx := WithFields(context.Background(), logrus.Fields{FieldRequestID: "req-1"})
+	ctx = WithFields(ctx, logrus.Fields{FieldPair: "SOL/USDC"})
+	Entry(ctx, logger).Warn("hello")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "hello", line["msg"])
+	assert.Equal(t, "req-1", line[FieldRequestID])
+	assert.Equal(t, "SOL/USDC", line[FieldPair])
+
+	t.Setenv("LOG_FORMAT", "xml")
+	assert.Error(t, Configure(logrus.New(), logrus.InfoLevel))
+	t.Setenv("LOG_FORMAT", "")
+	t.Setenv("LOG_LEVEL", "loud")
+	assert.Error(t, Configure(logrus.New(), logrus.InfoLevel))
+}
+
+func TestSampler(t *testing.T) {
+	s := NewSampler(3, 5)
+	allowed := 0
+	for range 23 {
+		if s.Allow() {
+			allowed++
+		}
+	}
+	// a second boundary during the loop could only let more through
+	assert.GreaterOrEqual(t, allowed, 3+4)
+
+	s.Configure(0, 0)
+	for range 10 {
+		assert.True(t, s.Allow(), "sampling off")
+	}
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetLevel(logrus.InfoLevel)
+	s.Debug(logrus.NewEntry(logger), "dropped below debug")
+	assert.Empty(t, buf.String())
+}
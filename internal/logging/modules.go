@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Modules whose log level can be changed at runtime, through the
+// log.level.<module> flags
+const (
+	ModuleStream     = "stream"     // chain poller and its supervisor
+	ModuleCache      = "cache"      // Redis and ClickHouse
+	ModuleIndexer    = "indexer"    // pipeline, sinks and dead letters
+	ModuleSwapEngine = "swapengine" // swap execution
+	ModuleAI         = "ai"         // AI agent
+	ModuleAPI        = "api"        // HTTP requests and handlers
+)
+
+// Modules lists every module, sorted
+var Modules = []string{ModuleAI, ModuleAPI, ModuleCache, ModuleIndexer, ModuleStream, ModuleSwapEngine}
+
+// FieldModule is the field every module logger adds
+const FieldModule = "module"
+
+type module struct {
+	name   string
+	base   *logrus.Logger
+	logger *logrus.Logger
+}
+
+var (
+	modulesMu sync.Mutex
+	modules   []*module
+	levels    = map[string]logrus.Level{} // set by SetModuleLevel
+)
+
+// Module returns the logger of one module of base's service. It writes
+// where and how base does, adds a module field, and starts at base's level
+// unless SetModuleLevel gave the module its own. Repeated calls return the
+// same logger.
+func Module(base *logrus.Logger, name string) *logrus.Logger {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	for _, m := range modules {
+		if m.base == base && m.name == name {
+			return m.logger
+		}
+	}
+
+	hooks := make(logrus.LevelHooks, len(base.Hooks))
+	for level, hs := range base.Hooks {
+		hooks[level] = slices.Clone(hs)
+	}
+	hooks.Add(moduleHook(name))
+	level, ok := levels[name]
+	if !ok {
+		level = base.GetLevel()
+	}
+	logger := &logrus.Logger{
+		Out:          base.Out,
+		Hooks:        hooks,
+		Formatter:    base.Formatter,
+		ReportCaller: base.ReportCaller,
+		Level:        level,
+		ExitFunc:     base.ExitFunc,
+	}
+	modules = append(modules, &module{name: name, base: base, logger: logger})
+	return logger
+}
+
+// SetModuleLevel sets the level of every logger of module name, including
+// ones created later; it reports false for a name that is not one of Modules
+func SetModuleLevel(name string, level logrus.Level) bool {
+	if !slices.Contains(Modules, name) {
+		return false
+	}
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	levels[name] = level
+	for _, m := range modules {
+		if m.name == name {
+			m.logger.SetLevel(level)
+		}
+	}
+	return true
+}
+
+// ResetModuleLevel puts module name back on its service's level
+func ResetModuleLevel(name string) {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	delete(levels, name)
+	for _, m := range modules {
+		if m.name == name {
+			m.logger.SetLevel(m.base.GetLevel())
+		}
+	}
+}
+
+// moduleHook adds the module field to every entry
+type moduleHook string
+
+func (h moduleHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (h moduleHook) Fire(e *logrus.Entry) error {
+	if _, ok := e.Data[FieldModule]; !ok {
+		e.Data[FieldModule] = string(h)
+	}
+	return nil
+}
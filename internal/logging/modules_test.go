@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModule_WritesLikeBaseWithItsOwnLevel(t *testing.T) {
+	t.Cleanup(func() { ResetModuleLevel(ModuleStream) })
+	var buf bytes.Buffer
+	base := logrus.New()
+	base.SetOutput(&buf)
+	base.SetFormatter(&logrus.JSONFormatter{})
+	base.SetLevel(logrus.InfoLevel)
+
+	stream := Module(base, ModuleStream)
+	assert.Same(t, stream, Module(base, ModuleStream))
+	assert.Equal(t, logrus.InfoLevel, stream.GetLevel())
+
+	stream.Debug("dropped")
+	assert.Zero(t, buf.Len())
+
+	require.True(t, SetModuleLevel(ModuleStream, logrus.DebugLevel))
+	stream.Debug("polled")
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "polled", line["msg"])
+	assert.Equal(t, ModuleStream, line[FieldModule])
+
+	// other modules and the base logger keep their level
+	assert.Equal(t, logrus.InfoLevel, Module(base, ModuleCache).GetLevel())
+	assert.Equal(t, logrus.InfoLevel, base.GetLevel())
+
+	ResetModuleLevel(ModuleStream)
+	assert.Equal(t, logrus.InfoLevel, stream.GetLevel())
+}
+
+func TestSetModuleLevel_AppliesToLaterLoggers(t *testing.T) {
+	t.Cleanup(func() { ResetModuleLevel(ModuleAI) })
+	require.True(t, SetModuleLevel(ModuleAI, logrus.WarnLevel))
+	assert.Equal(t, logrus.WarnLevel, Module(logrus.New(), ModuleAI).GetLevel())
+
+	assert.False(t, SetModuleLevel("nope", logrus.DebugLevel))
+}
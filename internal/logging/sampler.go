@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Defaults of LOG_SAMPLE_INITIAL and LOG_SAMPLE_THEREAFTER
+const (
+	DefaultSampleInitial    = 100
+	DefaultSampleThereafter = 100
+)
+
+// Swaps samples the per-swap debug logs (each parsed, cached, stored and
+// published swap)
+var Swaps = NewSampler(DefaultSampleInitial, DefaultSampleThereafter)
+
+// Sampler lets the first initial entries of every second through, then one
+// in every thereafter. Thereafter 0 drops the rest of the second; initial 0
+// with thereafter 0 turns sampling off.
+type Sampler struct {
+	mu         sync.Mutex
+	initial    int
+	thereafter int
+	second     int64 // unix second being counted
+	count      int
+}
+
+// NewSampler creates a sampler; see Sampler
+func NewSampler(initial, thereafter int) *Sampler {
+	return &Sampler{initial: initial, thereafter: thereafter}
+}
+
+// Configure changes the sampling rates
+func (s *Sampler) Configure(initial, thereafter int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.initial, s.thereafter = initial, thereafter
+}
+
+// Allow reports whether the next entry is logged
+func (s *Sampler) Allow() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.initial == 0 && s.thereafter == 0 {
+		return true
+	}
+	if now := time.Now().Unix(); now != s.second {
+		s.second, s.count = now, 0
+	}
+	s.count++
+	if s.count <= s.initial {
+		return true
+	}
+	return s.thereafter > 0 && (s.count-s.initial)%s.thereafter == 0
+}
+
+// Debug logs msg at debug level if the entry's logger has it enabled and the
+// sampler allows it
+func (s *Sampler) Debug(entry *logrus.Entry, msg string) {
+	if entry.Logger.IsLevelEnabled(logrus.DebugLevel) && s.Allow() {
+		entry.Debug(msg)
+	}
+}
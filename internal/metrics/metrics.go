@@ -0,0 +1,350 @@
+// Package metrics is a small, dependency-free metrics registry that renders
+// the Prometheus text exposition format. It covers what the services need
+// (counters, gauges and histograms with labels) without pulling in the
+// Prometheus client library.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Default is the process-wide registry served on /metrics
+var Default = NewRegistry()
+
+// DefaultBuckets are latency buckets in seconds, from 5ms to 10s
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry holds metric families by name
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]family
+}
+
+// family is one named metric with all its label combinations
+type family interface {
+	write(w io.Writer, name string)
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+// register returns the family already registered under name, or stores f.
+// Registering the same name twice with a different kind panics.
+func register[F family](r *Registry, name string, f F) F {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.families[name]; ok {
+		same, ok := existing.(F)
+		if !ok {
+			panic(fmt.Sprintf("metrics: %s registered twice with different types", name))
+		}
+		return same
+	}
+	r.families[name] = f
+	return f
+}
+
+// Counter registers (or returns) a counter family
+func (r *Registry) Counter(name, help string, labels ...string) *CounterVec {
+	return register(r, name, &CounterVec{vec: newVec[Counter](help, labels)})
+}
+
+// Gauge registers (or returns) a gauge family
+func (r *Registry) Gauge(name, help string, labels ...string) *GaugeVec {
+	return register(r, name, &GaugeVec{vec: newVec[Gauge](help, labels)})
+}
+
+// Histogram registers (or returns) a histogram family; nil buckets means DefaultBuckets
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return register(r, name, &HistogramVec{vec: newVec[Histogram](help, labels), buckets: b})
+}
+
+// Write renders every family in the Prometheus text format, sorted by name
+func (r *Registry) Write(w io.Writer) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fams := make([]family, len(names))
+	for i, name := range names {
+		fams[i] = r.families[name]
+	}
+	r.mu.RUnlock()
+
+	for i, f := range fams {
+		f.write(w, names[i])
+	}
+}
+
+// Handler serves the registry for Prometheus to scrape
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// vec maps label values to one series each
+type vec[S any] struct {
+	help   string
+	labels []string
+
+	mu     sync.RWMutex
+	series map[string]*S
+	values map[string][]string
+}
+
+func newVec[S any](help string, labels []string) vec[S] {
+	return vec[S]{help: help, labels: labels, series: make(map[string]*S), values: make(map[string][]string)}
+}
+
+// get returns the series for the label values, creating it with mk if needed
+func (v *vec[S]) get(values []string, mk func() *S) *S {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: got %d label values, want %d (%v)", len(values), len(v.labels), v.labels))
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	s, ok := v.series[key]
+	v.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if s, ok := v.series[key]; ok {
+		return s
+	}
+	s = mk()
+	v.series[key] = s
+	v.values[key] = append([]string(nil), values...)
+	return s
+}
+
+// each visits the series in a stable order
+func (v *vec[S]) each(fn func(labels string, s *S)) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	type entry struct {
+		labels string
+		s      *S
+	}
+	entries := make([]entry, len(keys))
+	for i, k := range keys {
+		entries[i] = entry{labelString(v.labels, v.values[k]), v.series[k]}
+	}
+	v.mu.RUnlock()
+
+	for _, e := range entries {
+		fn(e.labels, e.s)
+	}
+}
+
+// labelString renders {a="x",b="y"}, or "" without labels
+func labelString(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, n := range names {
+		pairs[i] = fmt.Sprintf("%s=%q", n, values[i])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// withExtra inserts an extra label pair into an already rendered label string
+func withExtra(labels, name, value string) string {
+	pair := fmt.Sprintf("%s=%q", name, value)
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// atomicFloat is a float64 updated with compare-and-swap
+type atomicFloat struct{ bits atomic.Uint64 }
+
+func (a *atomicFloat) add(d float64) {
+	for {
+		old := a.bits.Load()
+		if a.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+d)) {
+			return
+		}
+	}
+}
+
+func (a *atomicFloat) set(f float64) { a.bits.Store(math.Float64bits(f)) }
+func (a *atomicFloat) load() float64 { return math.Float64frombits(a.bits.Load()) }
+
+// Counter is a monotonically increasing value
+type Counter struct{ v atomicFloat }
+
+// Inc adds one
+func (c *Counter) Inc() { c.v.add(1) }
+
+// Add adds d, which must not be negative
+func (c *Counter) Add(d float64) {
+	if d < 0 {
+		return
+	}
+	c.v.add(d)
+}
+
+// Value returns the current count
+func (c *Counter) Value() float64 { return c.v.load() }
+
+// CounterVec is a counter family
+type CounterVec struct{ vec vec[Counter] }
+
+// With returns the counter for the given label values (in registration order)
+func (c *CounterVec) With(values ...string) *Counter {
+	return c.vec.get(values, func() *Counter { return &Counter{} })
+}
+
+// Sum returns the total over every label combination
+func (c *CounterVec) Sum() float64 {
+	var total float64
+	c.vec.each(func(_ string, s *Counter) { total += s.Value() })
+	return total
+}
+
+func (c *CounterVec) write(w io.Writer, name string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, c.vec.help, name)
+	c.vec.each(func(labels string, s *Counter) {
+		fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(s.Value()))
+	})
+}
+
+// Gauge is a value that can go up and down
+type Gauge struct{ v atomicFloat }
+
+// Set replaces the value
+func (g *Gauge) Set(f float64) { g.v.set(f) }
+
+// Add adds d (which may be negative)
+func (g *Gauge) Add(d float64) { g.v.add(d) }
+
+// Value returns the current value
+func (g *Gauge) Value() float64 { return g.v.load() }
+
+// GaugeVec is a gauge family
+type GaugeVec struct{ vec vec[Gauge] }
+
+// With returns the gauge for the given label values (in registration order)
+func (g *GaugeVec) With(values ...string) *Gauge {
+	return g.vec.get(values, func() *Gauge { return &Gauge{} })
+}
+
+func (g *GaugeVec) write(w io.Writer, name string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, g.vec.help, name)
+	g.vec.each(func(labels string, s *Gauge) {
+		fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(s.Value()))
+	})
+}
+
+// Histogram counts observations into cumulative buckets
+type Histogram struct {
+	upper  []float64
+	counts []atomic.Uint64 // per bucket (non-cumulative); last is +Inf
+	sum    atomicFloat
+	count  atomic.Uint64
+}
+
+// Observe records one value
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upper, v) // first bucket with upper >= v
+	h.counts[i].Add(1)
+	h.sum.add(v)
+	h.count.Add(1)
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() uint64 { return h.count.Load() }
+
+// Sum returns the sum of observations
+func (h *Histogram) Sum() float64 { return h.sum.load() }
+
+// Quantile estimates the q-quantile (0..1) by linear interpolation within
+// buckets, like Prometheus' histogram_quantile
+func (h *Histogram) Quantile(q float64) float64 {
+	total := h.count.Load()
+	if total == 0 {
+		return 0
+	}
+	rank := q * float64(total)
+	var cum uint64
+	lower := 0.0
+	for i, upper := range h.upper {
+		n := h.counts[i].Load()
+		if float64(cum+n) >= rank {
+			if n == 0 {
+				return upper
+			}
+			return lower + (upper-lower)*(rank-float64(cum))/float64(n)
+		}
+		cum += n
+		lower = upper
+	}
+	return lower // beyond the last finite bucket
+}
+
+// HistogramVec is a histogram family
+type HistogramVec struct {
+	vec     vec[Histogram]
+	buckets []float64
+}
+
+// With returns the histogram for the given label values (in registration order)
+func (h *HistogramVec) With(values ...string) *Histogram {
+	return h.vec.get(values, func() *Histogram {
+		return &Histogram{upper: h.buckets, counts: make([]atomic.Uint64, len(h.buckets)+1)}
+	})
+}
+
+func (h *HistogramVec) write(w io.Writer, name string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, h.vec.help, name)
+	h.vec.each(func(labels string, s *Histogram) {
+		var cum uint64
+		for i, upper := range s.upper {
+			cum += s.counts[i].Load()
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, withExtra(labels, "le", formatFloat(upper)), cum)
+		}
+		cum += s.counts[len(s.upper)].Load()
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, withExtra(labels, "le", "+Inf"), cum)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, formatFloat(s.Sum()))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, labels, cum)
+	})
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_TextFormat(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("swaps_total", "Swaps seen.", "dex")
+	c.With("Orca").Inc()
+	c.With("Orca").Add(2)
+	c.With("Jupiter").Inc()
+	r.Gauge("lag_slots", "Slot lag.").With().Set(12)
+	h := r.Histogram("latency_seconds", "Latency.", []float64{0.1, 1})
+	h.With().Observe(0.05)
+	h.With().Observe(0.5)
+	h.With().Observe(3)
+
+	var buf bytes.Buffer
+	r.Write(&buf)
+
+	assert.Equal(t, `# HELP lag_slots Slot lag.
+# TYPE lag_slots gauge
+lag_slots 12
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 3.55
+latency_seconds_count 3
+# HELP swaps_total Swaps seen.
+# TYPE swaps_total counter
+swaps_total{dex="Jupiter"} 1
+swaps_total{dex="Orca"} 3
+`, buf.String())
+	assert.Equal(t, 4.0, c.Sum())
+}
+
+func TestRegistry_SameNameReturnsSameFamily(t *testing.T) {
+	r := NewRegistry()
+	a := r.Counter("x_total", "X.")
+	b := r.Counter("x_total", "X.")
+	a.With().Inc()
+	assert.Equal(t, 1.0, b.With().Value())
+
+	require.Panics(t, func() { r.Gauge("x_total", "X.") })
+	require.Panics(t, func() { a.With("unexpected") })
+}
+
+func TestHistogram_Quantile(t *testing.T) {
+	h := NewRegistry().Histogram("q_seconds", "Q.", []float64{1, 2, 4}).With()
+	assert.Zero(t, h.Quantile(0.5))
+
+	for _, v := range []float64{0.5, 1.5, 1.5, 3} {
+		h.Observe(v)
+	}
+	assert.InDelta(t, 1.5, h.Quantile(0.5), 1e-9) // rank 2 of 4: halfway through the (1,2] bucket
+	assert.InDelta(t, 4.0, h.Quantile(1), 1e-9)
+}
@@ -0,0 +1,10 @@
+package mev
+
+import "github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
+
+var (
+	sandwichesTotal = metrics.Default.Counter("mev_sandwiches_total",
+		"Sandwich attacks found among stored swaps.")
+	scannedTotal = metrics.Default.Counter("mev_swaps_scanned_total",
+		"Stored swaps scanned for sandwich attacks.")
+)
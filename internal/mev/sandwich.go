@@ -0,0 +1,95 @@
+// Package mev finds sandwich attacks among stored swaps: an attacker buys a
+// token right before a victim buys it in the same pool, then sells it right
+// after, pocketing the price move the victim's trade causes.
+//
+// Swaps do not record their position within a slot, so a sandwich is
+// matched by shape rather than order: in one slot and pool, a wallet buys
+// and sells the same token at a profit, the sell is within SellTolerance of
+// the buy, and another wallet bought the token in between (in the same slot).
+package mev
+
+import (
+	"math"
+	"sort"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// SellTolerance is how far the attacker's sell may differ from the amount
+// it bought, as a fraction
+const SellTolerance = 0.1
+
+// venueKey groups the swaps that can form a sandwich
+type venueKey struct {
+	slot uint64
+	pool string
+}
+
+// FindSandwiches returns the sandwiches among swaps, one per victim. Swaps
+// without a slot, pool address or wallet are ignored.
+func FindSandwiches(swaps []*models.SwapEvent) []models.Sandwich {
+	groups := make(map[venueKey][]*models.SwapEvent)
+	var keys []venueKey
+	for _, s := range swaps {
+		if s.Slot == 0 || s.PoolAddress == "" || s.Wallet == "" {
+			continue
+		}
+		k := venueKey{s.Slot, s.PoolAddress}
+		if groups[k] == nil {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], s)
+	}
+
+	var out []models.Sandwich
+	for _, k := range keys {
+		out = append(out, inVenue(groups[k])...)
+	}
+	return out
+}
+
+// inVenue matches sandwiches among the swaps of one slot and pool
+func inVenue(swaps []*models.SwapEvent) []models.Sandwich {
+	if len(swaps) < 3 {
+		return nil
+	}
+	// deterministic results when one victim matches several attackers
+	sort.SliceStable(swaps, func(i, j int) bool { return swaps[i].Signature < swaps[j].Signature })
+
+	var out []models.Sandwich
+	victims := make(map[string]bool)
+	for _, front := range swaps {
+		for _, back := range swaps {
+			if back.Wallet != front.Wallet || back.TokenIn != front.TokenOut || back.TokenOut != front.TokenIn {
+				continue
+			}
+			// the sell closes the position bought in front, at a profit
+			if math.Abs(back.AmountIn-front.AmountOut) > SellTolerance*front.AmountOut || back.AmountOut <= front.AmountIn {
+				continue
+			}
+			for _, victim := range swaps {
+				if victim.Wallet == front.Wallet || victim.TokenIn != front.TokenIn || victim.TokenOut != front.TokenOut || victims[victim.Signature] {
+					continue
+				}
+				victims[victim.Signature] = true
+				out = append(out, models.Sandwich{
+					VictimSignature: victim.Signature,
+					FrontSignature:  front.Signature,
+					BackSignature:   back.Signature,
+					Slot:            victim.Slot,
+					Timestamp:       victim.Timestamp,
+					PoolAddress:     victim.PoolAddress,
+					Dex:             victim.Dex,
+					Pair:            victim.Pair,
+					Attacker:        front.Wallet,
+					Victim:          victim.Wallet,
+					Token:           front.TokenOut,
+					ProfitToken:     front.TokenIn,
+					Profit:          back.AmountOut - front.AmountIn,
+					VictimAmount:    victim.AmountIn,
+				})
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+package mev
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var t0 = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func swap(sig, wallet, in, out string, amountIn, amountOut float64, slot uint64) *models.SwapEvent {
+	return &models.SwapEvent{
+		Signature: sig, Wallet: wallet, TokenIn: in, TokenOut: out,
+		AmountIn: amountIn, AmountOut: amountOut, Slot: slot,
+		Timestamp: t0.Add(time.Duration(slot) * time.Second), PoolAddress: "pool1", Dex: "Raydium", Pair: "BONK/USDC",
+	}
+}
+
+func TestFindSandwiches(t *testing.T) {
+	swaps := []*models.SwapEvent{
+		swap("a-front", "bot", "USDC", "BONK", 100, 1000, 7),
+		swap("b-victim", "alice", "USDC", "BONK", 500, 4700, 7),
+		swap("c-back", "bot", "BONK", "USDC", 1000, 104, 7),
+		swap("d-other", "bob", "BONK", "USDC", 50, 5, 7), // wrong direction
+		// same shape in another slot: no victim there
+		swap("e-front", "bot", "USDC", "BONK", 100, 1000, 8),
+		swap("f-back", "bot", "BONK", "USDC", 1000, 101, 8),
+		swap("g-other", "carol", "BONK", "USDC", 10, 1, 8),
+	}
+	found := FindSandwiches(swaps)
+	require.Len(t, found, 1)
+	s := found[0]
+	assert.Equal(t, "b-victim", s.VictimSignature)
+	assert.Equal(t, "a-front", s.FrontSignature)
+	assert.Equal(t, "c-back", s.BackSignature)
+	assert.Equal(t, "bot", s.Attacker)
+	assert.Equal(t, "alice", s.Victim)
+	assert.Equal(t, "BONK", s.Token)
+	assert.Equal(t, "USDC", s.ProfitToken)
+	assert.InDelta(t, 4, s.Profit, 1e-9)
+	assert.InDelta(t, 500, s.VictimAmount, 1e-9)
+
+	// a sell far from the amount bought is not the same position
+	swaps[2].AmountIn, swaps[2].AmountOut = 500, 52
+	assert.Empty(t, FindSandwiches(swaps))
+}
+
+type fakeHistory []*models.SwapEvent
+
+func (f fakeHistory) ScanSwaps(_ context.Context, q storage.SwapQuery, fn func(*models.SwapEvent) error) error {
+	for _, s := range f {
+		if s.Timestamp.Before(q.From) || !s.Timestamp.Before(q.To) {
+			continue
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fakeStore struct {
+	inserted []models.Sandwich
+}
+
+func (f *fakeStore) InsertSandwiches(_ context.Context, s []models.Sandwich) error {
+	f.inserted = append(f.inserted, s...)
+	return nil
+}
+
+func (f *fakeStore) MEVStats(context.Context, time.Time, int) (*models.MEVStats, error) {
+	return &models.MEVStats{}, nil
+}
+
+func TestScannerScan(t *testing.T) {
+	history := fakeHistory{
+		swap("a-front", "bot", "USDC", "BONK", 100, 1000, 7),
+		swap("b-victim", "alice", "USDC", "BONK", 500, 4700, 7),
+		swap("c-back", "bot", "BONK", "USDC", 1000, 104, 7),
+		swap("d-front", "bot", "USDC", "BONK", 100, 1000, 9),
+		swap("e-victim", "bob", "USDC", "BONK", 200, 1900, 9),
+		swap("f-back", "bot", "BONK", "USDC", 1000, 102, 9),
+	}
+	store := &fakeStore{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	s := NewScanner(ScannerConfig{History: history, Store: store, Logger: logger})
+
+	n, err := s.Scan(context.Background(), storage.SwapQuery{From: t0, To: t0.Add(time.Minute)})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.Len(t, store.inserted, 2)
+	assert.Equal(t, "b-victim", store.inserted[0].VictimSignature)
+	assert.Equal(t, "e-victim", store.inserted[1].VictimSignature)
+
+	// slot 9 falls outside the range
+	store.inserted = nil
+	n, err = s.Scan(context.Background(), storage.SwapQuery{From: t0, To: t0.Add(8 * time.Second)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
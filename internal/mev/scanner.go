@@ -0,0 +1,151 @@
+package mev
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// checkpointSource is where the scanner saves the end of the last range it
+// scanned (as RFC 3339)
+const checkpointSource = "mev:sandwiches"
+
+// ScannerConfig holds the collaborators and pacing of a Scanner
+type ScannerConfig struct {
+	History     storage.SwapHistory
+	Store       storage.MEVStore
+	Checkpoints storage.CheckpointStore // optional; without it every start looks back Lookback
+	Interval    time.Duration           // between scans (default constants.MEVScanInterval)
+	Lookback    time.Duration           // first scan without a checkpoint starts this far back (default constants.MEVLookback)
+	Settle      time.Duration           // the newest swaps are left for the next scan (default constants.MEVSettleDelay)
+	Logger      *logrus.Logger
+}
+
+// Scanner periodically scans newly stored swaps for sandwiches
+type Scanner struct {
+	cfg ScannerConfig
+	now func() time.Time
+}
+
+// NewScanner creates a scanner, filling in defaults
+func NewScanner(cfg ScannerConfig) *Scanner {
+	if cfg.Interval <= 0 {
+		cfg.Interval = constants.MEVScanInterval
+	}
+	if cfg.Lookback <= 0 {
+		cfg.Lookback = constants.MEVLookback
+	}
+	if cfg.Settle < 0 {
+		cfg.Settle = 0
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	return &Scanner{cfg: cfg, now: time.Now}
+}
+
+// Run scans every Interval until ctx is cancelled, resuming from the saved
+// checkpoint. Each scan ends Settle before now, so the swaps of a slot still
+// being indexed are scanned together next time.
+func (s *Scanner) Run(ctx context.Context) error {
+	from, err := s.resume(ctx)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		to := s.now().UTC().Add(-s.cfg.Settle)
+		if to.After(from) {
+			if _, err := s.Scan(ctx, storage.SwapQuery{From: from, To: to}); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				s.cfg.Logger.WithError(err).Warn("mev scan failed, retrying next interval")
+			} else {
+				from = to
+				s.save(ctx, to)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan looks for sandwiches among the swaps in q, records them and returns
+// how many were found. Scanning a range again records the same sandwiches.
+func (s *Scanner) Scan(ctx context.Context, q storage.SwapQuery) (int, error) {
+	// Swaps come oldest first; a slot's swaps share its block time, so every
+	// slot is complete once the timestamp moves on
+	var (
+		batch   []*models.SwapEvent
+		found   []models.Sandwich
+		scanned int
+	)
+	err := s.cfg.History.ScanSwaps(ctx, q, func(swap *models.SwapEvent) error {
+		scanned++
+		if len(batch) > 0 && !swap.Timestamp.Equal(batch[0].Timestamp) {
+			found = append(found, FindSandwiches(batch)...)
+			batch = batch[:0]
+		}
+		batch = append(batch, swap)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan swaps: %w", err)
+	}
+	found = append(found, FindSandwiches(batch)...)
+	scannedTotal.With().Add(float64(scanned))
+
+	if len(found) > 0 {
+		if err := s.cfg.Store.InsertSandwiches(ctx, found); err != nil {
+			return 0, err
+		}
+		sandwichesTotal.With().Add(float64(len(found)))
+	}
+	s.cfg.Logger.WithFields(logrus.Fields{
+		"from":       q.From.Format(time.RFC3339),
+		"to":         q.To.Format(time.RFC3339),
+		"swaps":      scanned,
+		"sandwiches": len(found),
+	}).Info("mev scan finished")
+	return len(found), nil
+}
+
+// resume returns where the next scan starts
+func (s *Scanner) resume(ctx context.Context) (time.Time, error) {
+	start := s.now().UTC().Add(-s.cfg.Lookback)
+	if s.cfg.Checkpoints == nil {
+		return start, nil
+	}
+	cursor, err := s.cfg.Checkpoints.GetCheckpoint(ctx, checkpointSource)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read mev checkpoint: %w", err)
+	}
+	if cursor == "" {
+		return start, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, cursor)
+	if err != nil {
+		s.cfg.Logger.WithField("cursor", cursor).Warn("ignoring unreadable mev checkpoint")
+		return start, nil
+	}
+	return t, nil
+}
+
+func (s *Scanner) save(ctx context.Context, t time.Time) {
+	if s.cfg.Checkpoints == nil {
+		return
+	}
+	if err := s.cfg.Checkpoints.SetCheckpoint(ctx, checkpointSource, t.Format(time.RFC3339Nano)); err != nil {
+		s.cfg.Logger.WithError(err).Warn("failed to save mev checkpoint")
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Anomaly metrics
+const (
+	AnomalyVolume = "volume" // amount of the pair's input token traded
+	AnomalyTrades = "trades" // number of swaps
+)
+
+// Anomaly is an hour in which a pair traded far more than its baseline.
+// Value is the running total when the spike was detected, so it only grows
+// for the rest of the hour.
+type Anomaly struct {
+	Pair       string    `json:"pair"`
+	Metric     string    `json:"metric"` // volume or trades
+	Hour       time.Time `json:"hour"`   // start of the hour (UTC)
+	Value      float64   `json:"value"`
+	Mean       float64   `json:"mean"`    // hourly mean over the baseline
+	StdDev     float64   `json:"std_dev"` // hourly standard deviation over the baseline
+	ZScore     float64   `json:"z_score"` // (Value - Mean) / StdDev, StdDev floored at 10% of Mean
+	Baseline   int       `json:"baseline_hours"`
+	DetectedAt time.Time `json:"detected_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ArbOpportunity is a price gap for one pair between two venues (DEXes or
+// Jupiter). Prices are quote tokens per base token, taken from the venues'
+// latest swaps or quotes, so they include fees and price impact.
+type ArbOpportunity struct {
+	Pair       string    `json:"pair"`       // BASE/QUOTE, tokens in alphabetical order
+	BuyVenue   string    `json:"buy_venue"`  // venue with the lower price: buy BASE here
+	SellVenue  string    `json:"sell_venue"` // venue with the higher price: sell BASE here
+	BuyPrice   float64   `json:"buy_price"`  // QUOTE per BASE at BuyVenue
+	SellPrice  float64   `json:"sell_price"` // QUOTE per BASE at SellVenue
+	SpreadBps  float64   `json:"spread_bps"` // (SellPrice/BuyPrice - 1) * 10000
+	BuyAt      time.Time `json:"buy_at"`     // when BuyPrice was observed
+	SellAt     time.Time `json:"sell_at"`    // when SellPrice was observed
+	DetectedAt time.Time `json:"detected_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// SwapExecution is one swap the swap engine attempted past simulation, with
+// the compute it was budgeted and used, for tuning the compute budget
+type SwapExecution struct {
+	ExecutionID      string    `json:"execution_id"`
+	Signature        string    `json:"signature"` // empty when the transaction was never sent
+	Timestamp        time.Time `json:"timestamp"`
+	Wallet           string    `json:"wallet"`
+	Pool             string    `json:"pool"`
+	InputMint        string    `json:"input_mint"`
+	OutputMint       string    `json:"output_mint"`
+	AmountIn         uint64    `json:"amount_in"`    // raw units
+	ExpectedOut      uint64    `json:"expected_out"` // raw units, from the quote
+	Success          bool      `json:"success"`
+	Error            string    `json:"error"`
+	ErrorKind        string    `json:"error_kind"`         // e.g. "slippage_exceeded", "" on success
+	ErrorCode        int64     `json:"error_code"`         // custom program error code; -1 if none
+	SimulatedUnits   uint64    `json:"simulated_units"`    // compute units the simulation consumed
+	ComputeUnitLimit uint32    `json:"compute_unit_limit"` // limit requested (0 = runtime default)
+	ComputeUnitPrice uint64    `json:"compute_unit_price"` // micro-lamports per compute unit bid
+	ActualUnits      uint64    `json:"actual_units"`       // compute units the landed transaction consumed
+	FeeLamports      uint64    `json:"fee_lamports"`       // total fee paid, base and priority
+	DurationMS       int64     `json:"duration_ms"`
+}
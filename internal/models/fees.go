@@ -0,0 +1,21 @@
+package models
+
+// LandingCost averages what swaps paid to land on chain. Only swaps with a
+// recorded fee count. Fees are in lamports, ComputeUnitPrice in micro-lamports.
+type LandingCost struct {
+	Dex                 string  `json:"dex,omitempty"`
+	Pair                string  `json:"pair,omitempty"`
+	Swaps               uint64  `json:"swaps"`
+	AvgFeeLamports      float64 `json:"avg_fee_lamports"`
+	AvgPriorityFee      float64 `json:"avg_priority_fee"`
+	AvgComputeUnitPrice float64 `json:"avg_compute_unit_price"`
+	PrioritizedShare    float64 `json:"prioritized_share"` // fraction of swaps that paid a priority fee
+}
+
+// FeeStats is the landing cost of swaps over a window, overall, per DEX and
+// for the busiest pairs
+type FeeStats struct {
+	LandingCost
+	ByDex  []LandingCost `json:"by_dex"`
+	ByPair []LandingCost `json:"by_pair"`
+}
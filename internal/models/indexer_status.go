@@ -0,0 +1,85 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// IndexerStatus is the health summary an indexer replica reports periodically
+type IndexerStatus struct {
+	Instance  string    `json:"instance"`
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	SwapsProcessed float64 `json:"swaps_processed"`  // swaps accepted by every sink (or dead-lettered)
+	SwapsPerSecond float64 `json:"swaps_per_second"` // over the last report interval
+	SinkFailures   float64 `json:"sink_failures"`
+	DeadLettered   float64 `json:"dead_lettered"`
+
+	ProcessLatencyP50Ms float64 `json:"process_latency_p50_ms"`
+	ProcessLatencyP99Ms float64 `json:"process_latency_p99_ms"`
+
+	EndToEnd *EndToEndLatency `json:"end_to_end_latency,omitempty"` // nil before the first swap or when the stream provider is not supervised
+
+	ChainSlot int64           `json:"chain_slot"`
+	Programs  []ProgramStatus `json:"programs"`
+
+	Stream *StreamHealth `json:"stream,omitempty"` // nil when the stream provider is not supervised
+}
+
+// EndToEndLatency is the time from a swap's block time to the indexer
+// processing it (SwapEvent.IndexedAt) on one stream provider. Block times are
+// whole seconds, so values are accurate to about a second.
+type EndToEndLatency struct {
+	Provider string  `json:"provider"`
+	Swaps    uint64  `json:"swaps"`
+	P50Ms    float64 `json:"p50_ms"`
+	P95Ms    float64 `json:"p95_ms"`
+	P99Ms    float64 `json:"p99_ms"`
+}
+
+// StreamHealth is the supervisor's view of a replica's stream provider
+type StreamHealth struct {
+	Provider          string     `json:"provider"`
+	LastEventAt       time.Time  `json:"last_event_at"` // last swap or heartbeat (successful poll)
+	SecondsSinceEvent float64    `json:"seconds_since_event"`
+	StallAfterSeconds float64    `json:"stall_after_seconds"`
+	Stalled           bool       `json:"stalled"` // silent past the threshold; a restart is under way
+	Restarts          int64      `json:"restarts"`
+	LastRestartAt     *time.Time `json:"last_restart_at,omitempty"`
+	LastRestartReason string     `json:"last_restart_reason,omitempty"` // stalled or exited
+}
+
+// ProgramStatus is the poller's progress on one program address
+type ProgramStatus struct {
+	Program          string  `json:"program"`
+	Dex              string  `json:"dex"`
+	Active           bool    `json:"active"` // polled by this replica (false when another replica leads it)
+	Swaps            float64 `json:"swaps"`
+	NotSwaps         float64 `json:"not_swaps"`
+	ParseFailures    float64 `json:"parse_failures"`
+	ParseSuccessRate float64 `json:"parse_success_rate"` // (swaps + not_swaps) / all fetched; 1 before any fetch
+	LastIndexedSlot  int64   `json:"last_indexed_slot"`
+	SlotLag          int64   `json:"slot_lag"`
+}
+
+// ProgramOverrides are program addresses added or removed at runtime through
+// the admin API; every indexer applies them on top of PROGRAM_ADDRESSES
+type ProgramOverrides struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// Apply returns base plus Added minus Removed, base entries first
+func (o ProgramOverrides) Apply(base []string) []string {
+	out := make([]string, 0, len(base)+len(o.Added))
+	seen := make(map[string]bool, len(base)+len(o.Added))
+	for _, addr := range append(append([]string(nil), base...), o.Added...) {
+		if seen[addr] || slices.Contains(o.Removed, addr) {
+			continue
+		}
+		seen[addr] = true
+		out = append(out, addr)
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// Candle is the OHLCV summary of one pair over one interval. Prices are in
+// the pair's quote token per base token, volume in the base token.
+type Candle struct {
+	Pair      string    `json:"pair"`
+	Start     time.Time `json:"start"`
+	Open      float64   `json:"open"`
+	High      float64   `json:"high"`
+	Low       float64   `json:"low"`
+	Close     float64   `json:"close"`
+	Volume    float64   `json:"volume"`
+	VolumeUSD float64   `json:"volume_usd"`
+	Trades    uint64    `json:"trades"`
+}
+
+// PairSummary is one pair's trading over a window. Volume is in the base
+// token; VolumeUSD only counts swaps with a stablecoin leg.
+type PairSummary struct {
+	Pair      string    `json:"pair"`
+	Trades    uint64    `json:"trades"`
+	Volume    float64   `json:"volume"`
+	VolumeUSD float64   `json:"volume_usd"`
+	LastPrice float64   `json:"last_price"`
+	LastTrade time.Time `json:"last_trade"`
+}
+
+// PairListing describes one indexed pair: when it was first and last traded,
+// the DEXes it trades on, and its last 24 hours. Volume24h is in TokenIn;
+// VolumeUSD24h only counts swaps with a stablecoin leg.
+type PairListing struct {
+	Pair         string    `json:"pair"`
+	TokenIn      string    `json:"token_in"`
+	TokenOut     string    `json:"token_out"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+	Trades24h    uint64    `json:"trades_24h"`
+	Volume24h    float64   `json:"volume_24h"`
+	VolumeUSD24h float64   `json:"volume_usd_24h"`
+	Dexes        []string  `json:"dexes"`
+}
+
+// DexStats compares DEXes over a window. USD figures only count swaps with a
+// stablecoin leg, so they add up across pairs.
+type DexStats struct {
+	Trades    uint64       `json:"trades"`
+	VolumeUSD float64      `json:"volume_usd"`
+	Dexes     []DexSummary `json:"dexes"` // by USD volume, then trades
+}
+
+// DexSummary is one DEX's trading over a window and its share of the market
+type DexSummary struct {
+	Dex         string    `json:"dex"`
+	Trades      uint64    `json:"trades"`
+	VolumeUSD   float64   `json:"volume_usd"`
+	AvgTradeUSD float64   `json:"avg_trade_usd"` // over swaps with a stablecoin leg
+	TradeShare  float64   `json:"trade_share"`   // fraction of all trades
+	VolumeShare float64   `json:"volume_share"`  // fraction of all USD volume
+	Wallets     uint64    `json:"wallets"`       // distinct traders
+	LastTrade   time.Time `json:"last_trade"`
+}
+
+// TokenSummary is one token's trading over a window, on either side of a
+// swap. Name and Category come from the tokens table, if it lists the token.
+type TokenSummary struct {
+	Token     string    `json:"token"`
+	Name      string    `json:"name"`
+	Category  string    `json:"category"`
+	Trades    uint64    `json:"trades"`
+	Pairs     uint64    `json:"pairs"` // distinct pairs traded
+	VolumeUSD float64   `json:"volume_usd"`
+	LastTrade time.Time `json:"last_trade"`
+}
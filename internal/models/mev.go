@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Sandwich is a victim swap bracketed by an attacker's buy and sell of the
+// same token in the same slot and pool. Profit is what the attacker got back
+// minus what it spent, in ProfitToken, before fees.
+type Sandwich struct {
+	VictimSignature string    `json:"victim_signature"`
+	FrontSignature  string    `json:"front_signature"` // attacker's buy
+	BackSignature   string    `json:"back_signature"`  // attacker's sell
+	Slot            uint64    `json:"slot"`
+	Timestamp       time.Time `json:"timestamp"`
+	PoolAddress     string    `json:"pool_address"`
+	Dex             string    `json:"dex"`
+	Pair            string    `json:"pair"` // victim's pair
+	Attacker        string    `json:"attacker"`
+	Victim          string    `json:"victim"`
+	Token           string    `json:"token"`        // bought by the attacker and the victim
+	ProfitToken     string    `json:"profit_token"` // spent and recovered by the attacker
+	Profit          float64   `json:"profit"`
+	VictimAmount    float64   `json:"victim_amount"` // ProfitToken the victim spent
+}
+
+// AttackerStats is one attacker's sandwiches in a window
+type AttackerStats struct {
+	Attacker   string  `json:"attacker"`
+	Sandwiches uint64  `json:"sandwiches"`
+	ProfitUSD  float64 `json:"profit_usd"`
+}
+
+// PoolMEV counts the sandwiches in one pool
+type PoolMEV struct {
+	PoolAddress string `json:"pool_address"`
+	Dex         string `json:"dex"`
+	Pair        string `json:"pair"`
+	Sandwiches  uint64 `json:"sandwiches"`
+}
+
+// MEVStats aggregates detected sandwiches over a window. USD figures only
+// count sandwiches whose ProfitToken is a stablecoin.
+type MEVStats struct {
+	Sandwiches      uint64             `json:"sandwiches"`
+	Attackers       uint64             `json:"attackers"`
+	Victims         uint64             `json:"victims"`
+	ProfitUSD       float64            `json:"profit_usd"`
+	VictimVolumeUSD float64            `json:"victim_volume_usd"`
+	ProfitByToken   map[string]float64 `json:"profit_by_token"`
+	TopAttackers    []AttackerStats    `json:"top_attackers"`
+	TopPools        []PoolMEV          `json:"top_pools"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// TokenPrice is the last observed price of a token
+type TokenPrice struct {
+	Token     string    `json:"token"`
+	Price     float64   `json:"price"`
+	UpdatedAt time.Time `json:"updated_at"` // zero for prices written before timestamps were stored
+}
+
+// PricePoint is one observation in a token's rolling price history
+type PricePoint struct {
+	Price float64   `json:"price"`
+	At    time.Time `json:"at"`
+}
+
+// StaleAt reports whether the price is older than maxAge at now
+func (p *TokenPrice) StaleAt(now time.Time, maxAge time.Duration) bool {
+	return p.UpdatedAt.IsZero() || now.Sub(p.UpdatedAt) > maxAge
+}
+
+// MarketPrice is the last price of a token on one venue: a DEX pool quoted
+// in one token. Volume is the token amount traded there over the last
+// constants.MarketVolumeWindow to twice that.
+type MarketPrice struct {
+	Dex       string    `json:"dex"`
+	Pool      string    `json:"pool"`
+	Quote     string    `json:"quote"` // token the price is expressed in
+	Price     float64   `json:"price"` // Quote per token
+	Volume    float64   `json:"volume"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Digest schedules a subscription can ask for
+const (
+	DigestHourly = "hourly"
+	DigestDaily  = "daily"
+)
+
+// Subscription is an API key's interest in one pair or one token, set
+// through /v1/subscriptions. Exactly one of Pair and Token is set. Swaps
+// it matches are posted to WebhookURL, if any, and summarised in its
+// Digest.
+type Subscription struct {
+	ID         string    `json:"id"`
+	Pair       string    `json:"pair,omitempty"`        // BASE/QUOTE, upper case
+	Token      string    `json:"token,omitempty"`       // symbol or mint, either side of a swap
+	WebhookURL string    `json:"webhook_url,omitempty"` // receives every matching swap
+	Digest     string    `json:"digest,omitempty"`      // hourly or daily (empty: none)
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Target names what the subscription follows, e.g. pair:SOL/USDC; an API
+// key has at most one subscription per target
+func (s *Subscription) Target() string {
+	if s.Pair != "" {
+		return "pair:" + strings.ToUpper(s.Pair)
+	}
+	return "token:" + strings.ToUpper(s.Token)
+}
+
+// Matches reports whether swap is of the subscribed pair, or has the
+// subscribed token on either side
+func (s *Subscription) Matches(swap *SwapEvent) bool {
+	if s.Pair != "" {
+		return strings.EqualFold(s.Pair, swap.Pair)
+	}
+	return s.Token != "" && (strings.EqualFold(s.Token, swap.TokenIn) || strings.EqualFold(s.Token, swap.TokenOut))
+}
+
+// Digest summarises the swaps a subscription matched over one hour or day.
+// Volume is in the subscribed token, or the pair's base token. Prices are
+// the pair's quote per base, or the token's price in USDC/USDT from swaps
+// against a stablecoin; they are 0 without such swaps.
+type Digest struct {
+	Owner          string      `json:"owner"` // key:<API key fingerprint>, or anonymous
+	SubscriptionID string      `json:"subscription_id"`
+	Pair           string      `json:"pair,omitempty"`
+	Token          string      `json:"token,omitempty"`
+	Schedule       string      `json:"schedule"` // hourly or daily
+	From           time.Time   `json:"from"`     // inclusive
+	To             time.Time   `json:"to"`       // exclusive
+	Trades         uint64      `json:"trades"`
+	Volume         float64     `json:"volume"`
+	VolumeUSD      float64     `json:"volume_usd"` // swaps with a stablecoin leg
+	OpenPrice      float64     `json:"open_price"`
+	ClosePrice     float64     `json:"close_price"`
+	PriceChangePct float64     `json:"price_change_pct"`
+	Biggest        *DigestSwap `json:"biggest_swap,omitempty"`
+}
+
+// DigestSwap is the largest swap of a digest: by USD value, then amount
+type DigestSwap struct {
+	Signature string  `json:"signature"`
+	Amount    float64 `json:"amount"`
+	AmountUSD float64 `json:"amount_usd"`
+}
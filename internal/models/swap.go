@@ -14,4 +14,70 @@ type SwapEvent struct {
 	Fee       float64   `json:"fee"`
 	Pool      string    `json:"pool"`
 	Dex       string    `json:"dex"` // e.g., "Raydium", "Orca"
+
+	// On-chain position and exact amounts; zero on swaps indexed before they
+	// were recorded. AmountIn/AmountOut are the raw amounts scaled by
+	// 10^-decimals and lose precision for large values.
+	Slot         uint64 `json:"slot"`
+	BlockTime    int64  `json:"block_time"`     // unix seconds, as reported by the chain
+	AmountInRaw  uint64 `json:"amount_in_raw"`  // base units of TokenIn
+	AmountOutRaw uint64 `json:"amount_out_raw"` // base units of TokenOut
+	DecimalsIn   uint8  `json:"decimals_in"`
+	DecimalsOut  uint8  `json:"decimals_out"`
+	ProgramID    string `json:"program_id"`   // DEX program that executed the swap
+	PoolAddress  string `json:"pool_address"` // pool account the swap traded against
+	Wallet       string `json:"wallet"`       // fee payer (first signer) of the transaction
+
+	// Landing cost of the transaction; zero on swaps indexed before it was recorded
+	FeeLamports      uint64 `json:"fee_lamports"`       // total fee paid, base and priority
+	PriorityFee      uint64 `json:"priority_fee"`       // lamports paid above the base fee
+	ComputeUnitPrice uint64 `json:"compute_unit_price"` // micro-lamports per compute unit bid via the compute budget program
+
+	// IndexedAt is when the indexer finished processing the swap, just before
+	// writing it out; IndexedAt minus BlockTime is its end-to-end latency.
+	// Zero on swaps indexed before it was recorded.
+	IndexedAt time.Time `json:"indexed_at"`
+
+	// Finalized is set once the swap's block is finalized, so it can no
+	// longer be rolled back. Swaps indexed at confirmed commitment start
+	// unfinalized; those whose fork is dropped are retracted instead (see
+	// SwapRetraction).
+	Finalized bool `json:"finalized"`
+
+	// ValueUSD is the swap's value in US dollars, set by the indexer from a
+	// stablecoin leg or a recent USD price of either token. Zero when neither
+	// leg could be valued, and on swaps indexed before it was recorded.
+	ValueUSD float64 `json:"value_usd"`
+}
+
+// Reasons a swap is retracted
+const (
+	RetractDroppedFork = "dropped_fork" // the transaction is not in the finalized chain
+)
+
+// SwapRetraction withdraws a swap indexed at confirmed commitment whose
+// transaction never made it into the finalized chain: its block was on a fork
+// the cluster dropped. The swap has been deleted from storage; consumers that
+// aggregated it (volume, PnL, candles) should back it out.
+type SwapRetraction struct {
+	Swap        *SwapEvent `json:"swap"`
+	Reason      string     `json:"reason"`
+	RetractedAt time.Time  `json:"retracted_at"`
+}
+
+// FailedSwap is a transaction to a DEX program that landed on chain but
+// failed, e.g. on slippage or a stale quote. No tokens moved, so only the
+// program, the wallet and the error are known.
+type FailedSwap struct {
+	Signature        string    `json:"signature"`
+	Timestamp        time.Time `json:"timestamp"`
+	Slot             uint64    `json:"slot"`
+	BlockTime        int64     `json:"block_time"`
+	ProgramID        string    `json:"program_id"`
+	Dex              string    `json:"dex"`
+	Wallet           string    `json:"wallet"`            // fee payer (first signer) of the transaction
+	ErrorClass       string    `json:"error_class"`       // e.g. "InstructionError/Custom", "AccountInUse"
+	ErrorCode        int64     `json:"error_code"`        // program's custom error code; -1 when there is none
+	InstructionIndex int       `json:"instruction_index"` // failing instruction; -1 when the error is not an instruction's
+	Error            string    `json:"error"`             // the transaction error as JSON
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Ticker is a compact per-pair summary published every few seconds for
+// consumers that do not need every swap. Pair is BASE/QUOTE in alphabetical
+// order; Price is QUOTE per BASE and Volume is in BASE.
+type Ticker struct {
+	Pair      string    `json:"pair"`
+	Price     float64   `json:"price"`  // last traded price
+	Volume    float64   `json:"volume"` // traded over the last minute
+	Trades    int       `json:"trades"` // swaps over the last minute
+	Timestamp time.Time `json:"timestamp"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// TokenMetadata describes a mint of the static token list, or one registered
+// from its Metaplex metadata account. Symbol is the label swaps of the mint
+// carry: for registered mints the on-chain symbol, suffixed with the start of
+// the mint when another mint already uses it. It is empty when the mint has
+// no metadata, and swaps keep the shortened mint. Category is only known for
+// the static list (constants.TokenCategories).
+type TokenMetadata struct {
+	Mint         string    `json:"mint"`
+	Symbol       string    `json:"symbol"`
+	Name         string    `json:"name,omitempty"`
+	URI          string    `json:"uri,omitempty"`
+	Category     string    `json:"category,omitempty"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
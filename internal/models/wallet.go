@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// WalletSummary is one trader wallet's activity over a window. Volume is
+// measured on the stablecoin leg (USDC/USDT), so swaps without one count as
+// trades but add no volume.
+type WalletSummary struct {
+	Wallet     string    `json:"wallet"`
+	Trades     uint64    `json:"trades"`
+	VolumeUSD  float64   `json:"volume_usd"`
+	Pairs      uint64    `json:"pairs"` // distinct pairs traded
+	FirstTrade time.Time `json:"first_trade"`
+	LastTrade  time.Time `json:"last_trade"`
+}
+
+// PairActivity is a wallet's trading in one pair
+type PairActivity struct {
+	Pair      string  `json:"pair"`
+	Trades    uint64  `json:"trades"`
+	VolumeUSD float64 `json:"volume_usd"`
+}
+
+// WalletStats profiles one trader wallet over a window
+type WalletStats struct {
+	WalletSummary
+	AvgTradeUSD   float64        `json:"avg_trade_usd"`  // over the trades with a stablecoin leg
+	FavoritePairs []PairActivity `json:"favorite_pairs"` // most traded first
+	Heatmap       [7][24]uint64  `json:"heatmap"`        // trades by UTC weekday (0 = Sunday) and hour
+}
@@ -0,0 +1,154 @@
+// Package netguard keeps webhooks chosen by API clients off the networks the
+// services run in. Webhook URLs are checked when they are submitted, and the
+// HTTP client that posts to them refuses, at dial time, every address that
+// is not public, so a host name resolving (or later re-resolving) to a
+// loopback, private or link-local address cannot be reached either.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrBlocked is returned for webhooks pointing at a non-public address or at
+// a host outside the allowlist
+var ErrBlocked = errors.New("webhook target not allowed")
+
+// Policy says where webhooks may point
+type Policy struct {
+	AllowedHosts []string // host names webhooks may target, "*.example.com" for subdomains (empty: any public host)
+	AllowPrivate bool     // also allow loopback, private and link-local addresses (local development only)
+}
+
+// CheckURL checks that raw is an http(s) URL the policy allows. Host names
+// are not resolved here: the address they resolve to is checked by Client
+// on every connection.
+func (p Policy) CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	return p.checkURL(u)
+}
+
+func (p Policy) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", ErrBlocked)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrBlocked)
+	}
+	if len(p.AllowedHosts) > 0 && !p.hostAllowed(host) {
+		return fmt.Errorf("%w: host %s is not in the allowlist", ErrBlocked, host)
+	}
+	if p.AllowPrivate {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s is a loopback host", ErrBlocked, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !PublicAddr(addr) {
+		return fmt.Errorf("%w: %s is not a public address", ErrBlocked, host)
+	}
+	return nil
+}
+
+func (p Policy) hostAllowed(host string) bool {
+	for _, allowed := range p.AllowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+			continue
+		}
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Client returns an HTTP client for webhooks that checks every request,
+// redirects included, against the policy and refuses to connect to
+// non-public addresses after DNS resolution. It ignores HTTP_PROXY and
+// friends, since through a proxy the dial check would only see the proxy.
+func (p Policy) Client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	if !p.AllowPrivate {
+		dialer.Control = controlPublic
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: &guardedTransport{policy: p, next: transport}}
+}
+
+// guardedTransport checks each request URL before handing it on
+type guardedTransport struct {
+	policy Policy
+	next   http.RoundTripper
+}
+
+func (t *guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.policy.checkURL(req.URL); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// controlPublic is a net.Dialer Control hook: it runs once the address is
+// resolved, right before connecting
+func controlPublic(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrBlocked, address, err)
+	}
+	if !PublicAddr(ap.Addr()) {
+		return fmt.Errorf("%w: %s is not a public address", ErrBlocked, ap.Addr())
+	}
+	return nil
+}
+
+// nonPublic are the special-purpose ranges netip has no predicate for
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, broadcast
+	netip.MustParsePrefix("::/96"),          // IPv4-compatible
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local NAT64
+	netip.MustParsePrefix("2002::/16"),      // 6to4, which embeds any IPv4 address
+	netip.MustParsePrefix("2001::/32"),      // Teredo
+}
+
+// PublicAddr reports whether addr is a globally routable unicast address:
+// not loopback, private, link-local, multicast, unspecified or otherwise
+// reserved. IPv4-mapped IPv6 addresses are judged by their IPv4 address.
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, p := range nonPublic {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package netguard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicAddr(t *testing.T) {
+	for _, s := range []string{"1.1.1.1", "93.184.216.34", "2606:4700:4700::1111"} {
+		assert.True(t, PublicAddr(netip.MustParseAddr(s)), s)
+	}
+	for _, s := range []string{
+		"127.0.0.1", "10.0.0.1", "172.16.5.4", "192.168.1.1", "169.254.169.254", "100.64.0.1",
+		"0.0.0.0", "255.255.255.255", "224.0.0.1",
+		"::1", "::", "fe80::1", "fd00::1", "ff02::1", "::ffff:127.0.0.1", "::ffff:169.254.169.254",
+		"64:ff9b::a00:1", "2002:7f00:1::",
+	} {
+		assert.False(t, PublicAddr(netip.MustParseAddr(s)), s)
+	}
+}
+
+func TestPolicyCheckURL(t *testing.T) {
+	var open Policy
+	assert.NoError(t, open.CheckURL("https://example.com/hook"))
+	assert.NoError(t, open.CheckURL("http://1.1.1.1:8080/hook"))
+	for _, bad := range []string{
+		"ftp://example.com", "https:///hook", "http://localhost:8080", "http://api.localhost",
+		"http://127.0.0.1/hook", "http://[::1]/hook", "http://169.254.169.254/latest/meta-data",
+		"http://10.1.2.3", "http://[::ffff:192.168.0.1]/",
+	} {
+		assert.ErrorIs(t, open.CheckURL(bad), ErrBlocked, bad)
+	}
+
+	listed := Policy{AllowedHosts: []string{"hooks.example.com", "*.partner.io"}}
+	assert.NoError(t, listed.CheckURL("https://hooks.example.com/x"))
+	assert.NoError(t, listed.CheckURL("https://eu.partner.io/x"))
+	assert.ErrorIs(t, listed.CheckURL("https://partner.io/x"), ErrBlocked)
+	assert.ErrorIs(t, listed.CheckURL("https://example.com/x"), ErrBlocked)
+	assert.ErrorIs(t, listed.CheckURL("https://evilpartner.io/x"), ErrBlocked)
+
+	assert.NoError(t, Policy{AllowPrivate: true}.CheckURL("http://127.0.0.1:8080"))
+}
+
+func TestPolicyClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	post := func(p Policy, url string) error {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, strings.NewReader("{}"))
+		require.NoError(t, err)
+		resp, err := p.Client(time.Second).Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	assert.ErrorIs(t, post(Policy{}, srv.URL), ErrBlocked)
+	// past the URL check, the dialer refuses the resolved address itself
+	req := httptest.NewRequest(http.MethodPost, srv.URL, nil)
+	_, err := Policy{}.Client(time.Second).Transport.(*guardedTransport).next.RoundTrip(req)
+	assert.ErrorIs(t, err, ErrBlocked)
+	assert.NoError(t, controlPublic("tcp4", "1.1.1.1:443", nil))
+	assert.NoError(t, post(Policy{AllowPrivate: true}, srv.URL))
+	assert.ErrorIs(t, post(Policy{AllowPrivate: true, AllowedHosts: []string{"example.com"}}, srv.URL), ErrBlocked)
+}
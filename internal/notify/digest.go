@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/netguard"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// DigestPublisher dedupes and announces digests (implemented by *cache.RedisCache)
+type DigestPublisher interface {
+	ClaimDigest(ctx context.Context, schedule string, end time.Time) (bool, error)
+	PublishDigest(ctx context.Context, d *models.Digest) error
+}
+
+// DigesterConfig holds the dependencies of a Digester
+type DigesterConfig struct {
+	Store     storage.SubscriptionStore
+	Stats     storage.DigestSource
+	Publisher DigestPublisher
+	Timeout   time.Duration   // per webhook request (default constants.SubscriptionWebhookTimeout)
+	Webhooks  netguard.Policy // where webhooks may point (default: any public host)
+	Logger    *logrus.Logger
+}
+
+// Digester summarises the swaps of each subscription with a digest
+// schedule: at the top of every UTC hour for hourly ones, and at midnight
+// UTC for daily ones. A digest is published on the digests channel and
+// posted to the subscription's webhook, if any; periods without swaps are
+// skipped. Each period is claimed in Redis first, so replicas send it once,
+// and periods missed while no digester ran are not sent later.
+type Digester struct {
+	store     storage.SubscriptionStore
+	stats     storage.DigestSource
+	publisher DigestPublisher
+	client    *http.Client
+	logger    *logrus.Logger
+}
+
+// NewDigester creates a digester
+func NewDigester(cfg DigesterConfig) *Digester {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = constants.SubscriptionWebhookTimeout
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	return &Digester{
+		store:     cfg.Store,
+		stats:     cfg.Stats,
+		publisher: cfg.Publisher,
+		client:    cfg.Webhooks.Client(cfg.Timeout),
+		logger:    cfg.Logger,
+	}
+}
+
+// Run sends the digests of each period as it ends until ctx is cancelled
+func (d *Digester) Run(ctx context.Context) {
+	for {
+		end := time.Now().UTC().Truncate(time.Hour).Add(time.Hour)
+		timer := time.NewTimer(time.Until(end))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		schedules := []string{models.DigestHourly}
+		if end.Hour() == 0 {
+			schedules = append(schedules, models.DigestDaily)
+		}
+		for _, schedule := range schedules {
+			if err := d.RunPeriod(ctx, schedule, end); err != nil && ctx.Err() == nil {
+				d.logger.WithError(err).WithField("schedule", schedule).Warn("failed to send digests")
+			}
+		}
+	}
+}
+
+// RunPeriod sends the digests of schedule for the period ending at end,
+// unless another digester already claimed it
+func (d *Digester) RunPeriod(ctx context.Context, schedule string, end time.Time) error {
+	period, ok := digestPeriods[schedule]
+	if !ok {
+		return fmt.Errorf("unknown digest schedule %q", schedule)
+	}
+	claimed, err := d.publisher.ClaimDigest(ctx, schedule, end)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		d.logger.WithField("schedule", schedule).Debug("digests already sent by another process")
+		return nil
+	}
+	all, err := d.store.AllSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for owner, subs := range all {
+		for _, sub := range subs {
+			if sub.Digest != schedule {
+				continue
+			}
+			sent, err := d.send(ctx, owner, sub, end.Add(-period), end)
+			switch {
+			case err != nil:
+				digestsSent.With(schedule, "failed").Inc()
+				errs = append(errs, fmt.Errorf("subscription %s of %s: %w", sub.ID, owner, err))
+			case sent:
+				digestsSent.With(schedule, "sent").Inc()
+			default:
+				digestsSent.With(schedule, "empty").Inc()
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var digestPeriods = map[string]time.Duration{
+	models.DigestHourly: time.Hour,
+	models.DigestDaily:  24 * time.Hour,
+}
+
+// send compiles and delivers one digest; false means there were no swaps
+func (d *Digester) send(ctx context.Context, owner string, sub *models.Subscription, from, to time.Time) (bool, error) {
+	qctx, cancel := context.WithTimeout(ctx, constants.DigestQueryTimeout)
+	digest, err := d.stats.Digest(qctx, storage.DigestQuery{Pair: sub.Pair, Token: sub.Token, From: from, To: to})
+	cancel()
+	if err != nil {
+		return false, err
+	}
+	if digest.Trades == 0 {
+		return false, nil
+	}
+	digest.Owner, digest.SubscriptionID, digest.Schedule = owner, sub.ID, sub.Digest
+
+	if err := d.publisher.PublishDigest(ctx, digest); err != nil {
+		return false, err
+	}
+	if sub.WebhookURL != "" {
+		if err := post(ctx, d.client, sub.WebhookURL, &Delivery{Subscriptions: []string{sub.ID}, Digest: digest}); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/netguard"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStats has swaps for the pairs and tokens in trades only
+type fakeStats struct {
+	trades  map[string]uint64
+	queries []storage.DigestQuery
+}
+
+func (f *fakeStats) Digest(_ context.Context, q storage.DigestQuery) (*models.Digest, error) {
+	f.queries = append(f.queries, q)
+	n := f.trades[q.Pair+q.Token]
+	return &models.Digest{Pair: q.Pair, Token: q.Token, From: q.From, To: q.To, Trades: n, Volume: float64(n)}, nil
+}
+
+// fakePublisher lets each period be claimed once and records what it published
+type fakePublisher struct {
+	claimed   map[string]bool
+	published []*models.Digest
+}
+
+func (f *fakePublisher) ClaimDigest(_ context.Context, schedule string, end time.Time) (bool, error) {
+	key := schedule + end.String()
+	if f.claimed[key] {
+		return false, nil
+	}
+	f.claimed[key] = true
+	return true, nil
+}
+
+func (f *fakePublisher) PublishDigest(_ context.Context, d *models.Digest) error {
+	f.published = append(f.published, d)
+	return nil
+}
+
+func TestDigester(t *testing.T) {
+	ctx := context.Background()
+	hook := newHookServer(t, http.StatusOK)
+	store := &fakeStore{subs: map[string][]*models.Subscription{
+		"key:alice": {
+			{ID: "a1", Pair: "SOL/USDC", WebhookURL: hook.URL, Digest: models.DigestHourly},
+			{ID: "a2", Token: "BONK", Digest: models.DigestDaily},
+			{ID: "a3", Token: "JUP", WebhookURL: hook.URL}, // no digest
+		},
+		"key:bob": {
+			{ID: "b1", Token: "WIF", Digest: models.DigestHourly}, // no swaps
+		},
+	}}
+	stats := &fakeStats{trades: map[string]uint64{"SOL/USDC": 3, "BONK": 7, "JUP": 1}}
+	pub := &fakePublisher{claimed: map[string]bool{}}
+	// the hook server listens on loopback
+	d := NewDigester(DigesterConfig{Store: store, Stats: stats, Publisher: pub, Webhooks: netguard.Policy{AllowPrivate: true}})
+
+	end := time.Date(2026, 5, 1, 13, 0, 0, 0, time.UTC)
+	require.NoError(t, d.RunPeriod(ctx, models.DigestHourly, end))
+	require.Len(t, stats.queries, 2, "only hourly subscriptions are summarised")
+	for _, q := range stats.queries {
+		assert.Equal(t, end.Add(-time.Hour), q.From)
+		assert.Equal(t, end, q.To)
+	}
+
+	require.Len(t, pub.published, 1, "digests without swaps are skipped")
+	got := pub.published[0]
+	assert.Equal(t, "key:alice", got.Owner)
+	assert.Equal(t, "a1", got.SubscriptionID)
+	assert.Equal(t, models.DigestHourly, got.Schedule)
+	assert.Equal(t, uint64(3), got.Trades)
+
+	require.Len(t, hook.got, 1)
+	assert.Equal(t, []string{"a1"}, hook.got[0].Subscriptions)
+	assert.Nil(t, hook.got[0].Swap)
+	require.NotNil(t, hook.got[0].Digest)
+	assert.Equal(t, "SOL/USDC", hook.got[0].Digest.Pair)
+
+	// another replica finds the period claimed
+	require.NoError(t, d.RunPeriod(ctx, models.DigestHourly, end))
+	assert.Len(t, pub.published, 1)
+
+	midnight := time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, d.RunPeriod(ctx, models.DigestDaily, midnight))
+	require.Len(t, pub.published, 2)
+	assert.Equal(t, "BONK", pub.published[1].Token)
+	assert.Equal(t, midnight.Add(-24*time.Hour), pub.published[1].From)
+	assert.Len(t, hook.got, 1, "a2 has no webhook")
+
+	assert.Error(t, d.RunPeriod(ctx, "weekly", end))
+
+	// by default webhooks on private addresses get nothing
+	d = NewDigester(DigesterConfig{Store: store, Stats: stats, Publisher: pub})
+	assert.ErrorIs(t, d.RunPeriod(ctx, models.DigestHourly, end.Add(time.Hour)), netguard.ErrBlocked)
+	assert.Len(t, hook.got, 1)
+}
@@ -0,0 +1,12 @@
+package notify
+
+import "github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
+
+var (
+	webhookDeliveries = metrics.Default.Counter("subscription_webhook_deliveries_total",
+		"Swaps posted to subscription webhooks, by outcome (ok, failed).", "outcome")
+	subscriptionWebhooks = metrics.Default.Gauge("subscription_webhooks",
+		"Subscriptions with a webhook the router currently delivers to.")
+	digestsSent = metrics.Default.Counter("subscription_digests_total",
+		"Subscription digests by schedule (hourly, daily) and outcome (sent, empty, failed).", "schedule", "outcome")
+)
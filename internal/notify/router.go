@@ -0,0 +1,160 @@
+// Package notify tells API keys about the swaps of the pairs and tokens
+// they subscribed to through /v1/subscriptions. The Router posts each
+// matching swap of the live feed to the subscription's webhook; the
+// Digester sends hourly or daily summaries instead.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/netguard"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// Delivery is posted to a webhook for each swap its subscriptions match,
+// and for each digest of its subscriptions; exactly one of Swap and Digest
+// is set
+type Delivery struct {
+	Subscriptions []string          `json:"subscriptions"` // IDs of the matched subscriptions with this webhook
+	Swap          *models.SwapEvent `json:"swap,omitempty"`
+	Digest        *models.Digest    `json:"digest,omitempty"`
+}
+
+// RouterConfig holds the dependencies of a Router
+type RouterConfig struct {
+	Store    storage.SubscriptionStore
+	Refresh  time.Duration   // how often subscriptions are reread (default constants.SubscriptionsRefresh)
+	Timeout  time.Duration   // per webhook request (default constants.SubscriptionWebhookTimeout)
+	Webhooks netguard.Policy // where webhooks may point (default: any public host)
+	Logger   *logrus.Logger
+}
+
+// Router posts swaps to the webhooks of the subscriptions they match. It
+// works from a copy of the subscriptions, reread every Refresh, so a new
+// subscription takes up to that long to receive swaps.
+type Router struct {
+	store   storage.SubscriptionStore
+	refresh time.Duration
+	client  *http.Client
+	logger  *logrus.Logger
+
+	mu   sync.RWMutex
+	subs []*models.Subscription // those with a webhook
+}
+
+// NewRouter creates a router; it routes nothing until the first Refresh
+func NewRouter(cfg RouterConfig) *Router {
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = constants.SubscriptionsRefresh
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = constants.SubscriptionWebhookTimeout
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	return &Router{
+		store:   cfg.Store,
+		refresh: cfg.Refresh,
+		client:  cfg.Webhooks.Client(cfg.Timeout),
+		logger:  cfg.Logger,
+	}
+}
+
+// Run rereads the subscriptions every refresh interval until ctx is
+// cancelled; the previous copy is kept while the store is unreachable
+func (r *Router) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.refresh)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+			r.logger.WithError(err).Warn("failed to refresh subscriptions")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh rereads the subscriptions that have a webhook
+func (r *Router) Refresh(ctx context.Context) error {
+	all, err := r.store.AllSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	var subs []*models.Subscription
+	for _, owned := range all {
+		for _, sub := range owned {
+			if sub.WebhookURL != "" {
+				subs = append(subs, sub)
+			}
+		}
+	}
+	r.mu.Lock()
+	r.subs = subs
+	r.mu.Unlock()
+	subscriptionWebhooks.With().Set(float64(len(subs)))
+	return nil
+}
+
+// Route posts swap once to each webhook of the subscriptions it matches
+func (r *Router) Route(ctx context.Context, swap *models.SwapEvent) error {
+	byURL := make(map[string][]string)
+	r.mu.RLock()
+	for _, sub := range r.subs {
+		if sub.Matches(swap) {
+			byURL[sub.WebhookURL] = append(byURL[sub.WebhookURL], sub.ID)
+		}
+	}
+	r.mu.RUnlock()
+
+	var errs []error
+	for url, ids := range byURL {
+		if err := post(ctx, r.client, url, &Delivery{Subscriptions: ids, Swap: swap}); err != nil {
+			webhookDeliveries.With("failed").Inc()
+			errs = append(errs, err)
+			continue
+		}
+		webhookDeliveries.With("ok").Inc()
+	}
+	return errors.Join(errs...)
+}
+
+// post sends one delivery through a netguard client; non-2xx responses are
+// errors
+func post(ctx context.Context, client *http.Client, url string, d *Delivery) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("subscription webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("subscription webhook: %s returned %d", url, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/netguard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore serves AllSubscriptions; the rest of storage.SubscriptionStore
+// is not used by the router
+type fakeStore struct {
+	subs map[string][]*models.Subscription
+	down bool
+}
+
+func (f *fakeStore) ListSubscriptions(context.Context, string) ([]*models.Subscription, error) {
+	return nil, nil
+}
+
+func (f *fakeStore) AddSubscription(context.Context, string, *models.Subscription, int) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeStore) DeleteSubscription(context.Context, string, string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeStore) AllSubscriptions(context.Context) (map[string][]*models.Subscription, error) {
+	if f.down {
+		return nil, errors.New("redis unavailable")
+	}
+	return f.subs, nil
+}
+
+// hookServer records the deliveries posted to it
+type hookServer struct {
+	*httptest.Server
+	mu  sync.Mutex
+	got []Delivery
+}
+
+func newHookServer(t *testing.T, status int) *hookServer {
+	h := &hookServer{}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var d Delivery
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&d))
+		h.mu.Lock()
+		h.got = append(h.got, d)
+		h.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(h.Close)
+	return h
+}
+
+func TestRouter(t *testing.T) {
+	ctx := context.Background()
+	alice, bob, broken := newHookServer(t, http.StatusOK), newHookServer(t, http.StatusOK), newHookServer(t, http.StatusInternalServerError)
+	store := &fakeStore{subs: map[string][]*models.Subscription{
+		"key:alice": {
+			{ID: "a1", Pair: "SOL/USDC", WebhookURL: alice.URL},
+			{ID: "a2", Token: "usdc", WebhookURL: alice.URL},
+			{ID: "a3", Token: "BONK", Digest: models.DigestDaily}, // no webhook
+		},
+		"key:bob": {
+			{ID: "b1", Token: "BONK", WebhookURL: bob.URL},
+			{ID: "b2", Pair: "JUP/USDC", WebhookURL: broken.URL},
+		},
+	}}
+	// the hook servers listen on loopback
+	r := NewRouter(RouterConfig{Store: store, Webhooks: netguard.Policy{AllowPrivate: true}})
+
+	swap := &models.SwapEvent{Signature: "sig1", Pair: "SOL/USDC", TokenIn: "SOL", TokenOut: "USDC"}
+	require.NoError(t, r.Route(ctx, swap), "nothing is routed before the first refresh")
+	assert.Empty(t, alice.got)
+
+	require.NoError(t, r.Refresh(ctx))
+	require.NoError(t, r.Route(ctx, swap))
+	require.Len(t, alice.got, 1, "one post per webhook")
+	assert.ElementsMatch(t, []string{"a1", "a2"}, alice.got[0].Subscriptions)
+	assert.Equal(t, "sig1", alice.got[0].Swap.Signature)
+	assert.Empty(t, bob.got)
+
+	require.NoError(t, r.Route(ctx, &models.SwapEvent{Signature: "sig2", Pair: "BONK/SOL", TokenIn: "BONK", TokenOut: "SOL"}))
+	require.Len(t, bob.got, 1)
+	assert.Equal(t, []string{"b1"}, bob.got[0].Subscriptions)
+
+	assert.Error(t, r.Route(ctx, &models.SwapEvent{Signature: "sig3", Pair: "JUP/USDC", TokenIn: "JUP", TokenOut: "USDC"}),
+		"a failing webhook is reported")
+	assert.Len(t, alice.got, 2, "the others still receive the swap")
+
+	// the last subscriptions are kept while the store is down
+	store.down = true
+	require.Error(t, r.Refresh(ctx))
+	require.NoError(t, r.Route(ctx, swap))
+	assert.Len(t, alice.got, 3)
+
+	// by default webhooks on private addresses get nothing
+	r = NewRouter(RouterConfig{Store: store})
+	store.down = false
+	require.NoError(t, r.Refresh(ctx))
+	assert.ErrorIs(t, r.Route(ctx, swap), netguard.ErrBlocked)
+	assert.Len(t, alice.got, 3)
+}
@@ -0,0 +1,139 @@
+package orca
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// TokenSwapAccountSize is the length of an SPL token-swap (Orca legacy) swap account
+const TokenSwapAccountSize = 324
+
+// TokenSwapFees mirrors the fee schedule stored in a swap account
+type TokenSwapFees struct {
+	TradeFeeNumerator           uint64
+	TradeFeeDenominator         uint64
+	OwnerTradeFeeNumerator      uint64
+	OwnerTradeFeeDenominator    uint64
+	OwnerWithdrawFeeNumerator   uint64
+	OwnerWithdrawFeeDenominator uint64
+	HostFeeNumerator            uint64
+	HostFeeDenominator          uint64
+}
+
+// TokenSwapAccount is a decoded swap account
+type TokenSwapAccount struct {
+	Version        uint8
+	IsInitialized  bool
+	BumpSeed       uint8
+	TokenProgramID solana.PublicKey
+	VaultA         solana.PublicKey
+	VaultB         solana.PublicKey
+	PoolMint       solana.PublicKey
+	TokenMintA     solana.PublicKey
+	TokenMintB     solana.PublicKey
+	FeeAccount     solana.PublicKey
+	Fees           TokenSwapFees
+	CurveType      uint8
+}
+
+// DecodeTokenSwapAccount parses raw swap account data
+//
+// Layout: version u8 | is_initialized u8 | bump_seed u8 | 7 pubkeys
+// (token program, vault A, vault B, pool mint, mint A, mint B, fee account)
+// | 8 fee u64s | curve type u8 | 32 bytes curve parameters
+func DecodeTokenSwapAccount(data []byte) (*TokenSwapAccount, error) {
+	if len(data) < TokenSwapAccountSize {
+		return nil, fmt.Errorf("swap account too short: %d bytes, want %d", len(data), TokenSwapAccountSize)
+	}
+
+	acc := &TokenSwapAccount{
+		Version:       data[0],
+		IsInitialized: data[1] == 1,
+		BumpSeed:      data[2],
+	}
+	if !acc.IsInitialized {
+		return nil, fmt.Errorf("swap account is not initialized")
+	}
+
+	off := 3
+	key := func() solana.PublicKey {
+		pk := solana.PublicKeyFromBytes(data[off : off+32])
+		off += 32
+		return pk
+	}
+	acc.TokenProgramID = key()
+	acc.VaultA = key()
+	acc.VaultB = key()
+	acc.PoolMint = key()
+	acc.TokenMintA = key()
+	acc.TokenMintB = key()
+	acc.FeeAccount = key()
+
+	u64 := func() uint64 {
+		v := binary.LittleEndian.Uint64(data[off : off+8])
+		off += 8
+		return v
+	}
+	acc.Fees = TokenSwapFees{
+		TradeFeeNumerator:           u64(),
+		TradeFeeDenominator:         u64(),
+		OwnerTradeFeeNumerator:      u64(),
+		OwnerTradeFeeDenominator:    u64(),
+		OwnerWithdrawFeeNumerator:   u64(),
+		OwnerWithdrawFeeDenominator: u64(),
+		HostFeeNumerator:            u64(),
+		HostFeeDenominator:          u64(),
+	}
+	acc.CurveType = data[off]
+
+	return acc, nil
+}
+
+// SwapFee combines trade and owner trade fees into the single fraction
+// charged on input, which is what CalculateLegacySwapOutput expects
+func (f TokenSwapFees) SwapFee() (numerator, denominator uint64) {
+	switch {
+	case f.OwnerTradeFeeNumerator == 0 || f.OwnerTradeFeeDenominator == 0:
+		return f.TradeFeeNumerator, f.TradeFeeDenominator
+	case f.TradeFeeNumerator == 0 || f.TradeFeeDenominator == 0:
+		return f.OwnerTradeFeeNumerator, f.OwnerTradeFeeDenominator
+	case f.TradeFeeDenominator == f.OwnerTradeFeeDenominator:
+		return f.TradeFeeNumerator + f.OwnerTradeFeeNumerator, f.TradeFeeDenominator
+	}
+	return f.TradeFeeNumerator*f.OwnerTradeFeeDenominator + f.OwnerTradeFeeNumerator*f.TradeFeeDenominator,
+		f.TradeFeeDenominator * f.OwnerTradeFeeDenominator
+}
+
+// SwapAuthority derives the pool authority PDA from the swap account and its bump seed
+func SwapAuthority(programID, swapAccount solana.PublicKey, bump uint8) (solana.PublicKey, error) {
+	return solana.CreateProgramAddress([][]byte{swapAccount[:], {bump}}, programID)
+}
+
+// ToLegacyPool converts a decoded swap account into a ready-to-use pool
+func (acc *TokenSwapAccount) ToLegacyPool(name string, programID, swapAccount solana.PublicKey) (LegacyPool, error) {
+	authority, err := SwapAuthority(programID, swapAccount, acc.BumpSeed)
+	if err != nil {
+		return LegacyPool{}, fmt.Errorf("derive authority: %w", err)
+	}
+	num, den := acc.Fees.SwapFee()
+	if den == 0 {
+		return LegacyPool{}, fmt.Errorf("swap account has no trade fee denominator")
+	}
+
+	return LegacyPool{
+		Name:           name,
+		ProgramID:      programID,
+		SwapAccount:    swapAccount,
+		Authority:      authority,
+		TokenMintA:     acc.TokenMintA,
+		TokenMintB:     acc.TokenMintB,
+		VaultA:         acc.VaultA,
+		VaultB:         acc.VaultB,
+		PoolMint:       acc.PoolMint,
+		FeeAccount:     acc.FeeAccount,
+		FeeNumerator:   num,
+		FeeDenominator: den,
+	}, nil
+}
@@ -0,0 +1,128 @@
+package orca
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeTokenSwap is the inverse of DecodeTokenSwapAccount
+func encodeTokenSwap(acc TokenSwapAccount) []byte {
+	data := make([]byte, TokenSwapAccountSize)
+	data[0], data[1], data[2] = acc.Version, 1, acc.BumpSeed
+	off := 3
+	for _, k := range []solana.PublicKey{acc.TokenProgramID, acc.VaultA, acc.VaultB, acc.PoolMint, acc.TokenMintA, acc.TokenMintB, acc.FeeAccount} {
+		copy(data[off:], k[:])
+		off += 32
+	}
+	f := acc.Fees
+	for _, v := range []uint64{f.TradeFeeNumerator, f.TradeFeeDenominator, f.OwnerTradeFeeNumerator, f.OwnerTradeFeeDenominator,
+		f.OwnerWithdrawFeeNumerator, f.OwnerWithdrawFeeDenominator, f.HostFeeNumerator, f.HostFeeDenominator} {
+		binary.LittleEndian.PutUint64(data[off:], v)
+		off += 8
+	}
+	data[off] = acc.CurveType
+	return data
+}
+
+type fakeFetcher struct {
+	data  []byte
+	owner solana.PublicKey
+}
+
+func (f fakeFetcher) FetchTokenSwapAccount(context.Context, solana.PublicKey) (*TokenSwapAccount, solana.PublicKey, error) {
+	acc, err := DecodeTokenSwapAccount(f.data)
+	return acc, f.owner, err
+}
+
+func testSwapAccount(t *testing.T) (solana.PublicKey, TokenSwapAccount) {
+	t.Helper()
+	programID := solana.MustPublicKeyFromBase58(LegacyProgramID)
+	swap := solana.NewWallet().PublicKey()
+	_, bump, err := solana.FindProgramAddress([][]byte{swap[:]}, programID)
+	require.NoError(t, err)
+	return swap, TokenSwapAccount{
+		Version:        1,
+		BumpSeed:       bump,
+		TokenProgramID: solana.TokenProgramID,
+		VaultA:         solana.NewWallet().PublicKey(),
+		VaultB:         solana.NewWallet().PublicKey(),
+		PoolMint:       solana.NewWallet().PublicKey(),
+		TokenMintA:     solana.SolMint,
+		TokenMintB:     solana.NewWallet().PublicKey(),
+		FeeAccount:     solana.NewWallet().PublicKey(),
+		Fees: TokenSwapFees{
+			TradeFeeNumerator: 25, TradeFeeDenominator: 10000,
+			OwnerTradeFeeNumerator: 5, OwnerTradeFeeDenominator: 10000,
+		},
+	}
+}
+
+func writePools(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pools.json")
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	return path
+}
+
+func TestDecodeTokenSwapAccount(t *testing.T) {
+	swap, want := testSwapAccount(t)
+	got, err := DecodeTokenSwapAccount(encodeTokenSwap(want))
+	require.NoError(t, err)
+	want.IsInitialized = true
+	assert.Equal(t, want, *got)
+
+	_, err = DecodeTokenSwapAccount(make([]byte, 10))
+	assert.Error(t, err)
+	_, err = DecodeTokenSwapAccount(make([]byte, TokenSwapAccountSize))
+	assert.ErrorContains(t, err, "not initialized")
+
+	pool, err := got.ToLegacyPool("p", solana.MustPublicKeyFromBase58(LegacyProgramID), swap)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(30), pool.FeeNumerator)
+	assert.Equal(t, uint64(10000), pool.FeeDenominator)
+
+	pda, _, _ := solana.FindProgramAddress([][]byte{swap[:]}, solana.MustPublicKeyFromBase58(LegacyProgramID))
+	assert.Equal(t, pda, pool.Authority)
+}
+
+func TestSwapFeeMixedDenominators(t *testing.T) {
+	num, den := TokenSwapFees{TradeFeeNumerator: 1, TradeFeeDenominator: 400, OwnerTradeFeeNumerator: 1, OwnerTradeFeeDenominator: 2000}.SwapFee()
+	assert.Equal(t, uint64(2400), num)
+	assert.Equal(t, uint64(800000), den) // 0.3%
+}
+
+func TestNewPoolRegistryFromChain(t *testing.T) {
+	swap, acc := testSwapAccount(t)
+	fetcher := fakeFetcher{data: encodeTokenSwap(acc), owner: solana.MustPublicKeyFromBase58(LegacyProgramID)}
+	ctx := context.Background()
+
+	reg, err := NewPoolRegistryFromChain(ctx, fetcher, writePools(t, `[{"name":"SOL-X","swap_account":"`+swap.String()+`"}]`))
+	require.NoError(t, err)
+	pool, err := reg.FindPoolByMints(acc.TokenMintB, solana.SolMint)
+	require.NoError(t, err)
+	assert.Equal(t, acc.VaultA, pool.VaultA)
+	assert.Equal(t, swap, pool.SwapAccount)
+
+	// static fields that agree with the chain are accepted
+	_, err = NewPoolRegistryFromChain(ctx, fetcher, writePools(t, `[{"name":"SOL-X","swap_account":"`+swap.String()+
+		`","vault_a":"`+acc.VaultA.String()+`","fee_numerator":3,"fee_denominator":1000}]`))
+	assert.NoError(t, err)
+
+	// ...and disagreeing ones fail the load
+	_, err = NewPoolRegistryFromChain(ctx, fetcher, writePools(t, `[{"name":"SOL-X","swap_account":"`+swap.String()+
+		`","vault_a":"`+acc.VaultB.String()+`","fee_numerator":25,"fee_denominator":10000}]`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "vault_a: "+acc.VaultA.String()+" on chain")
+	assert.Contains(t, err.Error(), "fee_numerator: 30/10000 on chain")
+
+	fetcher.owner = solana.TokenProgramID
+	_, err = NewPoolRegistryFromChain(ctx, fetcher, writePools(t, `[{"name":"SOL-X","swap_account":"`+swap.String()+`"}]`))
+	assert.ErrorContains(t, err, "swap account is owned by")
+}
@@ -2,7 +2,9 @@ package orca
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"strconv"
 
 	"github.com/gagliardetto/solana-go"
 
@@ -53,15 +55,17 @@ func (c *Client) getTokenAccountBalance(
 	// Call(ctx, method, params, result) error
 
 	var result struct {
-		Context struct {
-			Slot uint64 `json:"slot"`
-		} `json:"context"`
-		Value struct {
-			Amount         string   `json:"amount"`
-			Decimals       uint8    `json:"decimals"`
-			UiAmount       *float64 `json:"uiAmount"`
-			UiAmountString string   `json:"uiAmountString"`
-		} `json:"value"`
+		Result struct {
+			Context struct {
+				Slot uint64 `json:"slot"`
+			} `json:"context"`
+			Value struct {
+				Amount         string   `json:"amount"`
+				Decimals       uint8    `json:"decimals"`
+				UiAmount       *float64 `json:"uiAmount"`
+				UiAmountString string   `json:"uiAmountString"`
+			} `json:"value"`
+		} `json:"result"`
 		Error *rpc.RPCError `json:"error"`
 	}
 
@@ -77,7 +81,7 @@ func (c *Client) getTokenAccountBalance(
 
 	// Parse amount string to uint64
 	var amount uint64
-	_, err = fmt.Sscanf(result.Value.Amount, "%d", &amount)
+	_, err = fmt.Sscanf(result.Result.Value.Amount, "%d", &amount)
 	if err != nil {
 		return 0, fmt.Errorf("invalid amount format: %w", err)
 	}
@@ -85,6 +89,177 @@ func (c *Client) getTokenAccountBalance(
 	return amount, nil
 }
 
+// FetchTokenSwapAccount loads and decodes a swap account, returning it with
+// the program that owns it
+func (c *Client) FetchTokenSwapAccount(
+	ctx context.Context,
+	swapAccount solana.PublicKey,
+) (*TokenSwapAccount, solana.PublicKey, error) {
+
+	var resp struct {
+		Result struct {
+			Value *struct {
+				Data  []string `json:"data"`
+				Owner string   `json:"owner"`
+			} `json:"value"`
+		} `json:"result"`
+		Error *rpc.RPCError `json:"error"`
+	}
+
+	params := []interface{}{
+		swapAccount.String(),
+		map[string]interface{}{"encoding": "base64", "commitment": "confirmed"},
+	}
+	if err := c.rpcClient.Call(ctx, "getAccountInfo", params, &resp); err != nil {
+		return nil, solana.PublicKey{}, fmt.Errorf("RPC call failed: %w", err)
+	}
+	if resp.Error != nil {
+		return nil, solana.PublicKey{}, fmt.Errorf("getAccountInfo error: %s", resp.Error.Message)
+	}
+	if resp.Result.Value == nil {
+		return nil, solana.PublicKey{}, fmt.Errorf("account %s not found", swapAccount)
+	}
+	if len(resp.Result.Value.Data) == 0 {
+		return nil, solana.PublicKey{}, fmt.Errorf("account %s has no data", swapAccount)
+	}
+
+	owner, err := solana.PublicKeyFromBase58(resp.Result.Value.Owner)
+	if err != nil {
+		return nil, solana.PublicKey{}, fmt.Errorf("invalid owner: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Result.Value.Data[0])
+	if err != nil {
+		return nil, solana.PublicKey{}, fmt.Errorf("invalid account data: %w", err)
+	}
+	acc, err := DecodeTokenSwapAccount(data)
+	if err != nil {
+		return nil, solana.PublicKey{}, err
+	}
+	return acc, owner, nil
+}
+
+// ProgramSwapAccount is a swap account found by FetchProgramSwapAccounts
+type ProgramSwapAccount struct {
+	Address solana.PublicKey
+	Account *TokenSwapAccount
+}
+
+// FetchProgramSwapAccounts lists every initialized swap account owned by
+// programID. Accounts that fail to decode are skipped.
+func (c *Client) FetchProgramSwapAccounts(
+	ctx context.Context,
+	programID solana.PublicKey,
+) ([]ProgramSwapAccount, error) {
+
+	var resp struct {
+		Result []struct {
+			Pubkey  string `json:"pubkey"`
+			Account struct {
+				Data []string `json:"data"`
+			} `json:"account"`
+		} `json:"result"`
+		Error *rpc.RPCError `json:"error"`
+	}
+
+	params := []interface{}{
+		programID.String(),
+		map[string]interface{}{
+			"encoding":   "base64",
+			"commitment": "confirmed",
+			"filters":    []interface{}{map[string]interface{}{"dataSize": TokenSwapAccountSize}},
+		},
+	}
+	if err := c.rpcClient.Call(ctx, "getProgramAccounts", params, &resp); err != nil {
+		return nil, fmt.Errorf("RPC call failed: %w", err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("getProgramAccounts error: %s", resp.Error.Message)
+	}
+
+	out := make([]ProgramSwapAccount, 0, len(resp.Result))
+	for _, item := range resp.Result {
+		addr, err := solana.PublicKeyFromBase58(item.Pubkey)
+		if err != nil || len(item.Account.Data) == 0 {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(item.Account.Data[0])
+		if err != nil {
+			continue
+		}
+		acc, err := DecodeTokenSwapAccount(data)
+		if err != nil {
+			continue
+		}
+		out = append(out, ProgramSwapAccount{Address: addr, Account: acc})
+	}
+	return out, nil
+}
+
+// FetchTokenSupply returns the raw supply of a mint (e.g. a pool's LP mint)
+func (c *Client) FetchTokenSupply(ctx context.Context, mint solana.PublicKey) (uint64, error) {
+	var resp struct {
+		Result struct {
+			Value struct {
+				Amount string `json:"amount"`
+			} `json:"value"`
+		} `json:"result"`
+		Error *rpc.RPCError `json:"error"`
+	}
+
+	if err := c.rpcClient.Call(ctx, "getTokenSupply", []interface{}{mint.String()}, &resp); err != nil {
+		return 0, fmt.Errorf("RPC call failed: %w", err)
+	}
+	if resp.Error != nil {
+		return 0, fmt.Errorf("getTokenSupply error: %s", resp.Error.Message)
+	}
+	return strconv.ParseUint(resp.Result.Value.Amount, 10, 64)
+}
+
+// FetchOwnerTokenBalance sums the owner's token accounts for mint; an owner
+// without any account has a balance of 0
+func (c *Client) FetchOwnerTokenBalance(ctx context.Context, owner, mint solana.PublicKey) (uint64, error) {
+	var resp struct {
+		Result struct {
+			Value []struct {
+				Account struct {
+					Data struct {
+						Parsed struct {
+							Info struct {
+								TokenAmount struct {
+									Amount string `json:"amount"`
+								} `json:"tokenAmount"`
+							} `json:"info"`
+						} `json:"parsed"`
+					} `json:"data"`
+				} `json:"account"`
+			} `json:"value"`
+		} `json:"result"`
+		Error *rpc.RPCError `json:"error"`
+	}
+
+	params := []interface{}{
+		owner.String(),
+		map[string]interface{}{"mint": mint.String()},
+		map[string]interface{}{"encoding": "jsonParsed", "commitment": "confirmed"},
+	}
+	if err := c.rpcClient.Call(ctx, "getTokenAccountsByOwner", params, &resp); err != nil {
+		return 0, fmt.Errorf("RPC call failed: %w", err)
+	}
+	if resp.Error != nil {
+		return 0, fmt.Errorf("getTokenAccountsByOwner error: %s", resp.Error.Message)
+	}
+
+	var total uint64
+	for _, acc := range resp.Result.Value {
+		n, err := strconv.ParseUint(acc.Account.Data.Parsed.Info.TokenAmount.Amount, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount format: %w", err)
+		}
+		total += n
+	}
+	return total, nil
+}
+
 // Close cleans up resources (if your RPC client needs cleanup)
 func (c *Client) Close() error {
 	// Add cleanup if needed
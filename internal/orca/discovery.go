@@ -0,0 +1,139 @@
+package orca
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/sirupsen/logrus"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+)
+
+// DiscoveryConfig controls which on-chain pools are registered automatically
+type DiscoveryConfig struct {
+	ProgramIDs []solana.PublicKey // swap programs to scan (default: legacy Orca)
+	Mints      []solana.PublicKey // both pool mints must be listed; empty allows any
+	MinReserve uint64             // minimum raw vault balance on each side
+	Interval   time.Duration      // time between scans (0 scans once)
+	Logger     *logrus.Logger
+}
+
+// discoveryClient is the part of *Client the discoverer needs
+type discoveryClient interface {
+	FetchProgramSwapAccounts(ctx context.Context, programID solana.PublicKey) ([]ProgramSwapAccount, error)
+	FetchVaultBalances(ctx context.Context, vaultA, vaultB solana.PublicKey) (uint64, uint64, error)
+}
+
+// Discoverer scans swap programs and registers pools that pass the mint
+// whitelist and liquidity floor, so FindPoolByMints also covers pairs that
+// are missing from the config file
+type Discoverer struct {
+	client   discoveryClient
+	registry *PoolRegistry
+	cfg      DiscoveryConfig
+	mints    map[solana.PublicKey]bool
+}
+
+// NewDiscoverer creates a discoverer registering into registry
+func NewDiscoverer(client discoveryClient, registry *PoolRegistry, cfg DiscoveryConfig) *Discoverer {
+	if len(cfg.ProgramIDs) == 0 {
+		cfg.ProgramIDs = []solana.PublicKey{solana.MustPublicKeyFromBase58(LegacyProgramID)}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	mints := make(map[solana.PublicKey]bool, len(cfg.Mints))
+	for _, m := range cfg.Mints {
+		mints[m] = true
+	}
+	return &Discoverer{client: client, registry: registry, cfg: cfg, mints: mints}
+}
+
+// Run scans immediately and then every Interval until ctx is cancelled
+func (d *Discoverer) Run(ctx context.Context) {
+	for {
+		if added, err := d.Discover(ctx); err != nil {
+			d.cfg.Logger.WithError(err).Warn("pool discovery failed")
+		} else if added > 0 {
+			d.cfg.Logger.WithFields(logrus.Fields{
+				"added": added,
+				"pools": d.registry.PoolCount(),
+			}).Info("registered discovered pools")
+		}
+
+		if d.cfg.Interval <= 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.cfg.Interval):
+		}
+	}
+}
+
+// Discover runs one scan over every program and returns how many pools were added.
+// A failing program is reported but does not stop the others from being scanned.
+func (d *Discoverer) Discover(ctx context.Context) (int, error) {
+	var (
+		found   []LegacyPool
+		lastErr error
+	)
+	for _, programID := range d.cfg.ProgramIDs {
+		accounts, err := d.client.FetchProgramSwapAccounts(ctx, programID)
+		if err != nil {
+			lastErr = fmt.Errorf("scan %s: %w", programID, err)
+			continue
+		}
+
+		for _, a := range accounts {
+			if !d.allowed(a.Account) || d.registry.HasSwapAccount(a.Address) {
+				continue
+			}
+			if d.cfg.MinReserve > 0 {
+				reserveA, reserveB, err := d.client.FetchVaultBalances(ctx, a.Account.VaultA, a.Account.VaultB)
+				if err != nil {
+					if ctx.Err() != nil {
+						return 0, ctx.Err()
+					}
+					continue
+				}
+				if reserveA < d.cfg.MinReserve || reserveB < d.cfg.MinReserve {
+					continue
+				}
+			}
+
+			pool, err := a.Account.ToLegacyPool(discoveredPoolName(a), programID, a.Address)
+			if err != nil {
+				continue
+			}
+			pool.Discovered = true
+			found = append(found, pool)
+		}
+	}
+
+	return d.registry.Register(found...), lastErr
+}
+
+// allowed applies the mint whitelist
+func (d *Discoverer) allowed(acc *TokenSwapAccount) bool {
+	if len(d.mints) == 0 {
+		return true
+	}
+	return d.mints[acc.TokenMintA] && d.mints[acc.TokenMintB]
+}
+
+// discoveredPoolName is "SOL-USDC-<swap prefix>", keeping names unique when
+// several pools trade the same pair
+func discoveredPoolName(a ProgramSwapAccount) string {
+	return fmt.Sprintf("%s-%s-%s", mintSymbol(a.Account.TokenMintA), mintSymbol(a.Account.TokenMintB), a.Address.String()[:6])
+}
+
+func mintSymbol(mint solana.PublicKey) string {
+	if sym, ok := constants.TokenSymbols[mint.String()]; ok {
+		return sym
+	}
+	return mint.String()[:6]
+}
@@ -0,0 +1,78 @@
+package orca
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeScan struct {
+	accounts []ProgramSwapAccount
+	reserves map[solana.PublicKey]uint64
+}
+
+func (f fakeScan) FetchProgramSwapAccounts(context.Context, solana.PublicKey) ([]ProgramSwapAccount, error) {
+	return f.accounts, nil
+}
+
+func (f fakeScan) FetchVaultBalances(_ context.Context, a, b solana.PublicKey) (uint64, uint64, error) {
+	return f.reserves[a], f.reserves[b], nil
+}
+
+func TestDiscoverRegistersWhitelistedLiquidPools(t *testing.T) {
+	usdc := solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	scan := fakeScan{reserves: map[solana.PublicKey]uint64{}}
+	add := func(mintB solana.PublicKey, reserve uint64) ProgramSwapAccount {
+		swap, acc := testSwapAccount(t)
+		acc.TokenMintB = mintB
+		scan.reserves[acc.VaultA], scan.reserves[acc.VaultB] = reserve, reserve
+		a := ProgramSwapAccount{Address: swap, Account: &acc}
+		scan.accounts = append(scan.accounts, a)
+		return a
+	}
+	liquid := add(usdc, 5_000_000)
+	add(usdc, 10)                              // below the liquidity floor
+	add(solana.NewWallet().PublicKey(), 1<<40) // mint not whitelisted
+
+	reg := &PoolRegistry{}
+	d := NewDiscoverer(scan, reg, DiscoveryConfig{Mints: []solana.PublicKey{solana.SolMint, usdc}, MinReserve: 1_000_000})
+
+	added, err := d.Discover(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	pool, err := reg.FindPoolByMints(usdc, solana.SolMint)
+	require.NoError(t, err)
+	assert.True(t, pool.Discovered)
+	assert.Equal(t, liquid.Address, pool.SwapAccount)
+	assert.Equal(t, "SOL-USDC-"+liquid.Address.String()[:6], pool.Name)
+
+	added, err = d.Discover(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, added, "known pools are not registered twice")
+}
+
+func TestReplaceConfiguredKeepsDiscoveredPools(t *testing.T) {
+	static := LegacyPool{Name: "static", SwapAccount: solana.NewWallet().PublicKey()}
+	found := LegacyPool{Name: "found", SwapAccount: solana.NewWallet().PublicKey(), Discovered: true}
+	reg := &PoolRegistry{pools: []LegacyPool{static}}
+	reg.Register(found)
+
+	// the reloaded file now lists the discovered pool itself
+	promoted := found
+	promoted.Discovered = false
+	reg.ReplaceConfigured([]LegacyPool{promoted})
+
+	pools := reg.GetAllPools()
+	require.Len(t, pools, 1)
+	assert.Equal(t, "found", pools[0].Name)
+	assert.False(t, pools[0].Discovered)
+
+	other := LegacyPool{Name: "other", SwapAccount: solana.NewWallet().PublicKey(), Discovered: true}
+	reg.Register(other)
+	reg.ReplaceConfigured(nil)
+	assert.Equal(t, []LegacyPool{other}, reg.GetAllPools())
+}
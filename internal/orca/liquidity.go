@@ -0,0 +1,221 @@
+package orca
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// SPL Token Swap instruction discriminators for liquidity
+const (
+	depositAllTokenTypesIx  = 2
+	withdrawAllTokenTypesIx = 3
+)
+
+// BuildDepositInstruction constructs a DepositAllTokenTypes instruction that
+// mints exactly poolTokenAmount LP tokens, taking at most maxTokenA/maxTokenB
+// from the user's accounts
+func BuildDepositInstruction(
+	pool *LegacyPool,
+	poolTokenAmount uint64,
+	maxTokenA uint64,
+	maxTokenB uint64,
+	userAuthority solana.PublicKey, // The signer (user's wallet)
+	userTokenA solana.PublicKey, // User's token A source account
+	userTokenB solana.PublicKey, // User's token B source account
+	userPoolToken solana.PublicKey, // User's LP token destination account
+) (solana.Instruction, error) {
+
+	if pool == nil {
+		return nil, fmt.Errorf("pool cannot be nil")
+	}
+	if poolTokenAmount == 0 {
+		return nil, fmt.Errorf("pool token amount must be > 0")
+	}
+
+	// SPL Token Swap DepositAllTokenTypes account order:
+	// 0. swap_state
+	// 1. authority
+	// 2. user_transfer_authority (signer)
+	// 3. user token A source
+	// 4. user token B source
+	// 5. pool vault A
+	// 6. pool vault B
+	// 7. pool_mint
+	// 8. user LP token destination
+	// 9. token_program
+	accounts := []*solana.AccountMeta{
+		{PublicKey: pool.SwapAccount, IsWritable: false, IsSigner: false},
+		{PublicKey: pool.Authority, IsWritable: false, IsSigner: false},
+		{PublicKey: userAuthority, IsWritable: false, IsSigner: true},
+		{PublicKey: userTokenA, IsWritable: true, IsSigner: false},
+		{PublicKey: userTokenB, IsWritable: true, IsSigner: false},
+		{PublicKey: pool.VaultA, IsWritable: true, IsSigner: false},
+		{PublicKey: pool.VaultB, IsWritable: true, IsSigner: false},
+		{PublicKey: pool.PoolMint, IsWritable: true, IsSigner: false},
+		{PublicKey: userPoolToken, IsWritable: true, IsSigner: false},
+		{PublicKey: solana.TokenProgramID, IsWritable: false, IsSigner: false},
+	}
+
+	return solana.NewInstruction(
+		pool.ProgramID,
+		accounts,
+		liquidityData(depositAllTokenTypesIx, poolTokenAmount, maxTokenA, maxTokenB),
+	), nil
+}
+
+// BuildWithdrawInstruction constructs a WithdrawAllTokenTypes instruction that
+// burns poolTokenAmount LP tokens for at least minTokenA/minTokenB
+func BuildWithdrawInstruction(
+	pool *LegacyPool,
+	poolTokenAmount uint64,
+	minTokenA uint64,
+	minTokenB uint64,
+	userAuthority solana.PublicKey, // The signer (user's wallet)
+	userPoolToken solana.PublicKey, // User's LP token source account
+	userTokenA solana.PublicKey, // User's token A destination account
+	userTokenB solana.PublicKey, // User's token B destination account
+) (solana.Instruction, error) {
+
+	if pool == nil {
+		return nil, fmt.Errorf("pool cannot be nil")
+	}
+	if poolTokenAmount == 0 {
+		return nil, fmt.Errorf("pool token amount must be > 0")
+	}
+
+	// SPL Token Swap WithdrawAllTokenTypes account order:
+	// 0. swap_state
+	// 1. authority
+	// 2. user_transfer_authority (signer)
+	// 3. pool_mint
+	// 4. user LP token source
+	// 5. pool vault A
+	// 6. pool vault B
+	// 7. user token A destination
+	// 8. user token B destination
+	// 9. fee_account (receives the owner withdraw fee)
+	// 10. token_program
+	accounts := []*solana.AccountMeta{
+		{PublicKey: pool.SwapAccount, IsWritable: false, IsSigner: false},
+		{PublicKey: pool.Authority, IsWritable: false, IsSigner: false},
+		{PublicKey: userAuthority, IsWritable: false, IsSigner: true},
+		{PublicKey: pool.PoolMint, IsWritable: true, IsSigner: false},
+		{PublicKey: userPoolToken, IsWritable: true, IsSigner: false},
+		{PublicKey: pool.VaultA, IsWritable: true, IsSigner: false},
+		{PublicKey: pool.VaultB, IsWritable: true, IsSigner: false},
+		{PublicKey: userTokenA, IsWritable: true, IsSigner: false},
+		{PublicKey: userTokenB, IsWritable: true, IsSigner: false},
+		{PublicKey: pool.FeeAccount, IsWritable: true, IsSigner: false},
+		{PublicKey: solana.TokenProgramID, IsWritable: false, IsSigner: false},
+	}
+
+	return solana.NewInstruction(
+		pool.ProgramID,
+		accounts,
+		liquidityData(withdrawAllTokenTypesIx, poolTokenAmount, minTokenA, minTokenB),
+	), nil
+}
+
+// liquidityData encodes [discriminator | pool_token_amount | token_a | token_b] (u64 little-endian)
+func liquidityData(ix byte, poolTokenAmount, tokenA, tokenB uint64) []byte {
+	data := make([]byte, 25)
+	data[0] = ix
+	binary.LittleEndian.PutUint64(data[1:9], poolTokenAmount)
+	binary.LittleEndian.PutUint64(data[9:17], tokenA)
+	binary.LittleEndian.PutUint64(data[17:25], tokenB)
+	return data
+}
+
+// PoolTokensForDeposit returns how many LP tokens can be minted with at most
+// amountA and amountB, i.e. the side that runs out first decides
+func PoolTokensForDeposit(amountA, amountB, reserveA, reserveB, lpSupply uint64) uint64 {
+	if reserveA == 0 || reserveB == 0 || lpSupply == 0 {
+		return 0
+	}
+	byA := mulDiv(amountA, lpSupply, reserveA, false)
+	byB := mulDiv(amountB, lpSupply, reserveB, false)
+	return min(byA, byB)
+}
+
+// DepositAmounts returns the token amounts the program takes to mint
+// poolTokens LP tokens (rounded up, as the program does)
+func DepositAmounts(poolTokens, reserveA, reserveB, lpSupply uint64) (amountA, amountB uint64) {
+	if lpSupply == 0 {
+		return 0, 0
+	}
+	return mulDiv(poolTokens, reserveA, lpSupply, true), mulDiv(poolTokens, reserveB, lpSupply, true)
+}
+
+// WithdrawAmounts returns the token amounts burning poolTokens LP tokens
+// yields before the owner withdraw fee (rounded down, as the program does)
+func WithdrawAmounts(poolTokens, reserveA, reserveB, lpSupply uint64) (amountA, amountB uint64) {
+	if lpSupply == 0 {
+		return 0, 0
+	}
+	return mulDiv(poolTokens, reserveA, lpSupply, false), mulDiv(poolTokens, reserveB, lpSupply, false)
+}
+
+// mulDiv computes a*b/c without overflow, saturating at MaxUint64
+func mulDiv(a, b, c uint64, roundUp bool) uint64 {
+	n := new(big.Int).Mul(new(big.Int).SetUint64(a), new(big.Int).SetUint64(b))
+	d := new(big.Int).SetUint64(c)
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if roundUp && r.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if !q.IsUint64() {
+		return ^uint64(0)
+	}
+	return q.Uint64()
+}
+
+// Position is an owner's LP stake in one pool
+type Position struct {
+	Pool      *LegacyPool
+	LPBalance uint64  // LP tokens held by the owner
+	LPSupply  uint64  // total LP tokens outstanding
+	Share     float64 // LPBalance / LPSupply
+	AmountA   uint64  // token A redeemable now (before withdraw fee)
+	AmountB   uint64  // token B redeemable now (before withdraw fee)
+	Timestamp int64
+}
+
+// FetchPosition reads the owner's LP balance, the LP supply and the pool
+// reserves, and values the position at current reserves
+func FetchPosition(
+	ctx context.Context,
+	client *Client,
+	pool *LegacyPool,
+	owner solana.PublicKey,
+) (*Position, error) {
+
+	balance, err := client.FetchOwnerTokenBalance(ctx, owner, pool.PoolMint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch LP balance: %w", err)
+	}
+	pos := &Position{Pool: pool, LPBalance: balance, Timestamp: time.Now().Unix()}
+	if balance == 0 {
+		return pos, nil
+	}
+
+	supply, err := client.FetchTokenSupply(ctx, pool.PoolMint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch LP supply: %w", err)
+	}
+	state, err := RefreshPoolState(ctx, client, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	pos.LPSupply = supply
+	if supply > 0 {
+		pos.Share = float64(balance) / float64(supply)
+	}
+	pos.AmountA, pos.AmountB = WithdrawAmounts(balance, state.ReserveA, state.ReserveB, supply)
+	return pos, nil
+}
@@ -0,0 +1,61 @@
+package orca
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLiquidityInstructions(t *testing.T) {
+	swap, acc := testSwapAccount(t)
+	pool, err := acc.ToLegacyPool("SOL-USDC", solana.MustPublicKeyFromBase58(LegacyProgramID), swap)
+	require.NoError(t, err)
+	user := solana.NewWallet().PublicKey()
+	ata, atb, lp := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+
+	dep, err := BuildDepositInstruction(&pool, 100, 200, 300, user, ata, atb, lp)
+	require.NoError(t, err)
+	data, err := dep.Data()
+	require.NoError(t, err)
+	require.Len(t, data, 25)
+	assert.Equal(t, byte(2), data[0])
+	assert.Equal(t, uint64(100), binary.LittleEndian.Uint64(data[1:9]))
+	assert.Equal(t, uint64(200), binary.LittleEndian.Uint64(data[9:17]))
+	assert.Equal(t, uint64(300), binary.LittleEndian.Uint64(data[17:25]))
+	accs := dep.Accounts()
+	require.Len(t, accs, 10)
+	assert.True(t, accs[2].IsSigner)
+	assert.Equal(t, pool.PoolMint, accs[7].PublicKey)
+	assert.Equal(t, lp, accs[8].PublicKey)
+
+	wd, err := BuildWithdrawInstruction(&pool, 100, 1, 2, user, lp, ata, atb)
+	require.NoError(t, err)
+	data, err = wd.Data()
+	require.NoError(t, err)
+	assert.Equal(t, byte(3), data[0])
+	accs = wd.Accounts()
+	require.Len(t, accs, 11)
+	assert.Equal(t, lp, accs[4].PublicKey)
+	assert.Equal(t, pool.FeeAccount, accs[9].PublicKey)
+	assert.True(t, accs[9].IsWritable)
+
+	_, err = BuildDepositInstruction(&pool, 0, 1, 1, user, ata, atb, lp)
+	assert.Error(t, err)
+}
+
+func TestLiquidityMath(t *testing.T) {
+	// pool holds 1000 A / 4000 B with 2000 LP outstanding
+	assert.Equal(t, uint64(200), PoolTokensForDeposit(100, 1000, 1000, 4000, 2000), "token A limits the deposit")
+	assert.Equal(t, uint64(50), PoolTokensForDeposit(1000, 100, 1000, 4000, 2000), "token B limits the deposit")
+	assert.Zero(t, PoolTokensForDeposit(100, 100, 0, 4000, 2000))
+
+	a, b := DepositAmounts(3, 1000, 4000, 2000)
+	assert.Equal(t, []uint64{2, 6}, []uint64{a, b}, "deposits round up")
+	a, b = WithdrawAmounts(3, 1000, 4000, 2000)
+	assert.Equal(t, []uint64{1, 6}, []uint64{a, b}, "withdrawals round down")
+
+	assert.Equal(t, ^uint64(0), mulDiv(^uint64(0), 4, 2, false), "saturates on overflow")
+}
@@ -0,0 +1,301 @@
+package orca
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// PoolFieldError is one invalid field of a pool config entry
+type PoolFieldError struct {
+	Index   int    // position of the entry in the file
+	Pool    string // entry name (may be empty)
+	Field   string // JSON field, e.g. "vault_a"
+	Message string
+}
+
+func (e PoolFieldError) Error() string {
+	return fmt.Sprintf("pool %d (%s): %s: %s", e.Index, e.Pool, e.Field, e.Message)
+}
+
+// PoolConfigError is returned in strict mode when any entry is invalid
+type PoolConfigError struct {
+	Errors []PoolFieldError
+}
+
+func (e *PoolConfigError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return fmt.Sprintf("%d invalid pool field(s): %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// PoolLoadOptions control how a pool config file is loaded
+type PoolLoadOptions struct {
+	Source  string             // PoolSourceFile (default) or PoolSourceChain
+	Strict  bool               // fail on any invalid entry instead of skipping it
+	Fetcher SwapAccountFetcher // reads swap accounts; required for PoolSourceChain
+}
+
+// PoolLoadSummary reports what a load kept and what it skipped
+type PoolLoadSummary struct {
+	Path    string
+	Source  string
+	Entries int              // entries in the file
+	Loaded  int              // entries registered
+	Skipped int              // entries dropped because of Errors
+	Errors  []PoolFieldError // every problem found, grouped by entry
+}
+
+func (s *PoolLoadSummary) String() string {
+	return fmt.Sprintf("%d/%d pools loaded from %s (%s), %d skipped", s.Loaded, s.Entries, s.Path, s.Source, s.Skipped)
+}
+
+// SwapAccountFetcher loads decoded swap accounts; *Client implements it
+type SwapAccountFetcher interface {
+	FetchTokenSwapAccount(ctx context.Context, swapAccount solana.PublicKey) (*TokenSwapAccount, solana.PublicKey, error)
+}
+
+// LoadPoolRegistry loads a pool config file. Invalid entries are skipped and
+// reported in the summary, unless opts.Strict is set, in which case any
+// invalid entry fails the load with a *PoolConfigError. An unreadable file
+// always fails.
+//
+// With PoolSourceChain entries only need name and swap_account (program_id
+// defaults to the legacy program); every other field is derived from the
+// swap account, and fields that are filled in must match chain state.
+func LoadPoolRegistry(ctx context.Context, path string, opts PoolLoadOptions) (*PoolRegistry, *PoolLoadSummary, error) {
+	if opts.Source == "" {
+		opts.Source = PoolSourceFile
+	}
+	if opts.Source == PoolSourceChain && opts.Fetcher == nil {
+		return nil, nil, fmt.Errorf("pool source %s needs an RPC client", PoolSourceChain)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load pools: failed to read config file: %w", err)
+	}
+	var configs []LegacyPoolConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, nil, fmt.Errorf("failed to load pools: failed to parse JSON: %w", err)
+	}
+
+	summary := &PoolLoadSummary{Path: path, Source: opts.Source, Entries: len(configs)}
+	pools := make([]LegacyPool, 0, len(configs))
+	names := make(map[string]int, len(configs))
+	accounts := make(map[solana.PublicKey]int, len(configs))
+
+	for i, cfg := range configs {
+		var (
+			pool LegacyPool
+			errs []fieldProblem
+		)
+		if opts.Source == PoolSourceChain {
+			pool, errs = resolvePoolConfig(ctx, opts.Fetcher, cfg)
+		} else {
+			pool, errs = parsePoolConfig(cfg)
+		}
+
+		if len(errs) == 0 {
+			if j, dup := names[cfg.Name]; dup {
+				errs = append(errs, fieldProblem{"name", fmt.Sprintf("duplicate of pool %d", j)})
+			}
+			if j, dup := accounts[pool.SwapAccount]; dup {
+				errs = append(errs, fieldProblem{"swap_account", fmt.Sprintf("duplicate of pool %d", j)})
+			}
+		}
+		if len(errs) > 0 {
+			for _, p := range errs {
+				summary.Errors = append(summary.Errors, PoolFieldError{Index: i, Pool: cfg.Name, Field: p.field, Message: p.msg})
+			}
+			summary.Skipped++
+			continue
+		}
+
+		names[cfg.Name], accounts[pool.SwapAccount] = i, i
+		pools = append(pools, pool)
+	}
+	summary.Loaded = len(pools)
+
+	if opts.Strict && len(summary.Errors) > 0 {
+		return nil, summary, fmt.Errorf("failed to load pools: %w", &PoolConfigError{Errors: summary.Errors})
+	}
+	return &PoolRegistry{pools: pools}, summary, nil
+}
+
+// NewPoolRegistry loads pools from a JSON file, failing on any invalid entry
+func NewPoolRegistry(configPath string) (*PoolRegistry, error) {
+	reg, _, err := LoadPoolRegistry(context.Background(), configPath, PoolLoadOptions{Strict: true})
+	return reg, err
+}
+
+// NewPoolRegistryFromChain builds the registry from on-chain swap accounts,
+// failing on any invalid entry or mismatch with chain state
+func NewPoolRegistryFromChain(
+	ctx context.Context,
+	fetcher SwapAccountFetcher,
+	configPath string,
+) (*PoolRegistry, error) {
+
+	reg, _, err := LoadPoolRegistry(ctx, configPath, PoolLoadOptions{Source: PoolSourceChain, Strict: true, Fetcher: fetcher})
+	return reg, err
+}
+
+// LoadLegacyPoolsFromJSON reads and parses pool configurations, failing on any invalid entry
+func LoadLegacyPoolsFromJSON(path string) ([]LegacyPool, error) {
+	reg, err := NewPoolRegistry(path)
+	if err != nil {
+		return nil, err
+	}
+	return reg.GetAllPools(), nil
+}
+
+// fieldProblem is a PoolFieldError before it is tied to an entry
+type fieldProblem struct {
+	field, msg string
+}
+
+// keyParser parses base58 fields, collecting a problem per bad field
+type keyParser struct {
+	problems []fieldProblem
+}
+
+// required parses a mandatory public key field
+func (p *keyParser) required(field, value string) solana.PublicKey {
+	if value == "" {
+		p.problems = append(p.problems, fieldProblem{field, "required"})
+		return solana.PublicKey{}
+	}
+	return p.optional(field, value)
+}
+
+// optional parses a public key field that may be empty
+func (p *keyParser) optional(field, value string) solana.PublicKey {
+	if value == "" {
+		return solana.PublicKey{}
+	}
+	pk, err := solana.PublicKeyFromBase58(value)
+	if err != nil {
+		p.problems = append(p.problems, fieldProblem{field, fmt.Sprintf("invalid public key %q", value)})
+	}
+	return pk
+}
+
+// parsePoolConfig converts a config entry to a LegacyPool, reporting every invalid field
+func parsePoolConfig(cfg LegacyPoolConfig) (LegacyPool, []fieldProblem) {
+	var p keyParser
+	if strings.TrimSpace(cfg.Name) == "" {
+		p.problems = append(p.problems, fieldProblem{"name", "required"})
+	}
+
+	pool := LegacyPool{
+		Name:           cfg.Name,
+		ProgramID:      p.required("program_id", cfg.ProgramID),
+		SwapAccount:    p.required("swap_account", cfg.SwapAccount),
+		Authority:      p.required("authority", cfg.Authority),
+		TokenMintA:     p.required("token_mint_a", cfg.TokenMintA),
+		TokenMintB:     p.required("token_mint_b", cfg.TokenMintB),
+		VaultA:         p.required("vault_a", cfg.VaultA),
+		VaultB:         p.required("vault_b", cfg.VaultB),
+		PoolMint:       p.required("pool_mint", cfg.PoolMint),
+		FeeAccount:     p.required("fee_account", cfg.FeeAccount),
+		FeeNumerator:   cfg.FeeNumerator,
+		FeeDenominator: cfg.FeeDenominator,
+	}
+
+	// Parse optional host fee account
+	if cfg.HostFeeAccount != "" {
+		hostFee := p.optional("host_fee_account", cfg.HostFeeAccount)
+		pool.HostFeeAccount = &hostFee
+	}
+
+	if cfg.TokenMintA != "" && cfg.TokenMintA == cfg.TokenMintB {
+		p.problems = append(p.problems, fieldProblem{"token_mint_b", "same mint as token_mint_a"})
+	}
+	switch {
+	case cfg.FeeDenominator == 0:
+		p.problems = append(p.problems, fieldProblem{"fee_denominator", "must be > 0"})
+	case cfg.FeeNumerator >= cfg.FeeDenominator:
+		p.problems = append(p.problems, fieldProblem{"fee_numerator", "must be < fee_denominator"})
+	}
+
+	return pool, p.problems
+}
+
+// resolvePoolConfig fetches one pool's swap account and reconciles it with the static entry
+func resolvePoolConfig(
+	ctx context.Context,
+	fetcher SwapAccountFetcher,
+	cfg LegacyPoolConfig,
+) (LegacyPool, []fieldProblem) {
+
+	var p keyParser
+	if strings.TrimSpace(cfg.Name) == "" {
+		p.problems = append(p.problems, fieldProblem{"name", "required"})
+	}
+	swapAccount := p.required("swap_account", cfg.SwapAccount)
+	programID := solana.MustPublicKeyFromBase58(LegacyProgramID)
+	if cfg.ProgramID != "" {
+		programID = p.optional("program_id", cfg.ProgramID)
+	}
+	var hostFee *solana.PublicKey
+	if cfg.HostFeeAccount != "" {
+		pk := p.optional("host_fee_account", cfg.HostFeeAccount)
+		hostFee = &pk
+	}
+	if len(p.problems) > 0 {
+		return LegacyPool{}, p.problems
+	}
+
+	acc, owner, err := fetcher.FetchTokenSwapAccount(ctx, swapAccount)
+	if err != nil {
+		return LegacyPool{}, []fieldProblem{{"swap_account", fmt.Sprintf("fetch swap account: %v", err)}}
+	}
+	if !owner.Equals(programID) {
+		return LegacyPool{}, []fieldProblem{{"program_id", fmt.Sprintf("swap account is owned by %s, not %s", owner, programID)}}
+	}
+
+	pool, err := acc.ToLegacyPool(cfg.Name, programID, swapAccount)
+	if err != nil {
+		return LegacyPool{}, []fieldProblem{{"swap_account", err.Error()}}
+	}
+	pool.HostFeeAccount = hostFee
+
+	return pool, checkPoolConfig(cfg, pool)
+}
+
+// checkPoolConfig compares the populated static fields with the on-chain pool
+func checkPoolConfig(cfg LegacyPoolConfig, pool LegacyPool) []fieldProblem {
+	var problems []fieldProblem
+	keys := []struct {
+		field, want string
+		got         solana.PublicKey
+	}{
+		{"authority", cfg.Authority, pool.Authority},
+		{"token_mint_a", cfg.TokenMintA, pool.TokenMintA},
+		{"token_mint_b", cfg.TokenMintB, pool.TokenMintB},
+		{"vault_a", cfg.VaultA, pool.VaultA},
+		{"vault_b", cfg.VaultB, pool.VaultB},
+		{"pool_mint", cfg.PoolMint, pool.PoolMint},
+		{"fee_account", cfg.FeeAccount, pool.FeeAccount},
+	}
+	for _, k := range keys {
+		if k.want != "" && k.want != k.got.String() {
+			problems = append(problems, fieldProblem{k.field, fmt.Sprintf("%s on chain, config has %s", k.got, k.want)})
+		}
+	}
+
+	// Fees are compared as fractions so 25/10000 matches 1/400
+	if cfg.FeeDenominator != 0 &&
+		cfg.FeeNumerator*pool.FeeDenominator != pool.FeeNumerator*cfg.FeeDenominator {
+		problems = append(problems, fieldProblem{"fee_numerator", fmt.Sprintf("%d/%d on chain, config has %d/%d",
+			pool.FeeNumerator, pool.FeeDenominator, cfg.FeeNumerator, cfg.FeeDenominator)})
+	}
+	return problems
+}
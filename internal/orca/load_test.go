@@ -0,0 +1,58 @@
+package orca
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testMintA = "So11111111111111111111111111111111111111112"
+	testMintB = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	testKey   = "11111111111111111111111111111111"
+)
+
+func poolEntry(name, swap, vaultA string) string {
+	return `{"name":"` + name + `","program_id":"` + LegacyProgramID + `","swap_account":"` + swap +
+		`","authority":"` + testKey + `","token_mint_a":"` + testMintA + `","token_mint_b":"` + testMintB +
+		`","vault_a":"` + vaultA + `","vault_b":"` + testKey + `","pool_mint":"` + testKey +
+		`","fee_account":"` + testKey + `","fee_numerator":25,"fee_denominator":10000}`
+}
+
+func TestLoadPoolRegistrySkipsInvalidEntries(t *testing.T) {
+	path := writePools(t, `[`+
+		poolEntry("good", testMintB, testKey)+`,`+
+		poolEntry("typo", testKey, "VAULT_A_HERE")+`,`+
+		`{"name":"","swap_account":"`+testKey+`","fee_numerator":5,"fee_denominator":5},`+
+		poolEntry("good", testMintA, testKey)+
+		`]`)
+
+	reg, summary, err := LoadPoolRegistry(context.Background(), path, PoolLoadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, reg.PoolCount())
+	assert.Equal(t, 4, summary.Entries)
+	assert.Equal(t, 1, summary.Loaded)
+	assert.Equal(t, 3, summary.Skipped)
+
+	byField := map[string]string{}
+	for _, fe := range summary.Errors {
+		byField[fmt.Sprintf("%d.%s", fe.Index, fe.Field)] = fe.Message
+	}
+	assert.Contains(t, summary.Errors, PoolFieldError{Index: 1, Pool: "typo", Field: "vault_a", Message: `invalid public key "VAULT_A_HERE"`})
+	assert.Equal(t, "required", byField["2.name"])
+	assert.Equal(t, "required", byField["2.authority"])
+	assert.Equal(t, "must be < fee_denominator", byField["2.fee_numerator"])
+	assert.Equal(t, "duplicate of pool 0", byField["3.name"])
+
+	_, summary, err = LoadPoolRegistry(context.Background(), path, PoolLoadOptions{Strict: true})
+	var cfgErr *PoolConfigError
+	require.True(t, errors.As(err, &cfgErr))
+	assert.Equal(t, summary.Errors, cfgErr.Errors)
+
+	_, err = NewPoolRegistry(path)
+	assert.ErrorContains(t, err, `pool 1 (typo): vault_a: invalid public key "VAULT_A_HERE"`)
+}
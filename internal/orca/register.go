@@ -1,13 +1,18 @@
 package orca
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"sync"
 
 	"github.com/gagliardetto/solana-go"
 )
 
+// Pool sources understood by the registry
+const (
+	PoolSourceFile  = "file"  // pools.json is trusted as written
+	PoolSourceChain = "chain" // accounts, mints and fees are read from the swap account
+)
+
 // LegacyPoolConfig represents a pool entry in the JSON config
 type LegacyPoolConfig struct {
 	Name           string `json:"name"`
@@ -40,83 +45,75 @@ type LegacyPool struct {
 	HostFeeAccount *solana.PublicKey
 	FeeNumerator   uint64
 	FeeDenominator uint64
+	Discovered     bool // registered by a program scan rather than the config file
 }
 
-// PoolRegistry holds all configured pools
+// PoolRegistry holds all configured pools. Configured pools come first, so
+// lookups prefer them over discovered pools for the same pair.
 type PoolRegistry struct {
+	mu    sync.RWMutex
 	pools []LegacyPool
 }
 
-// NewPoolRegistry loads pools from a JSON file
-func NewPoolRegistry(configPath string) (*PoolRegistry, error) {
-	pools, err := LoadLegacyPoolsFromJSON(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load pools: %w", err)
-	}
+// Register adds pools whose swap account is not registered yet and returns
+// how many were added
+func (r *PoolRegistry) Register(pools ...LegacyPool) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	return &PoolRegistry{
-		pools: pools,
-	}, nil
+	added := 0
+	for _, p := range pools {
+		if r.hasSwapAccount(p.SwapAccount) {
+			continue
+		}
+		r.pools = append(r.pools, p)
+		added++
+	}
+	return added
 }
 
-// LoadLegacyPoolsFromJSON reads and parses pool configurations
-func LoadLegacyPoolsFromJSON(path string) ([]LegacyPool, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
+// ReplaceConfigured swaps in a freshly loaded set of configured pools, keeping
+// discovered pools the new set does not cover. Pools handed out earlier are
+// not modified, so swaps in flight finish against the config they started with.
+func (r *PoolRegistry) ReplaceConfigured(pools []LegacyPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	var configs []LegacyPoolConfig
-	if err := json.Unmarshal(data, &configs); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	configured := make(map[solana.PublicKey]bool, len(pools))
+	for _, p := range pools {
+		configured[p.SwapAccount] = true
 	}
-
-	pools := make([]LegacyPool, 0, len(configs))
-	for i, cfg := range configs {
-		pool, err := parsePoolConfig(cfg)
-		if err != nil {
-			return nil, fmt.Errorf("pool %d (%s): %w", i, cfg.Name, err)
+	next := append(make([]LegacyPool, 0, len(pools)+len(r.pools)), pools...)
+	for _, p := range r.pools {
+		if p.Discovered && !configured[p.SwapAccount] {
+			next = append(next, p)
 		}
-		pools = append(pools, pool)
 	}
-
-	return pools, nil
+	r.pools = next
 }
 
-// parsePoolConfig converts a config struct to a LegacyPool with validation
-func parsePoolConfig(cfg LegacyPoolConfig) (LegacyPool, error) {
-	if cfg.FeeDenominator == 0 {
-		return LegacyPool{}, fmt.Errorf("fee_denominator must be > 0")
-	}
-
-	pool := LegacyPool{
-		Name:           cfg.Name,
-		ProgramID:      solana.MustPublicKeyFromBase58(cfg.ProgramID),
-		SwapAccount:    solana.MustPublicKeyFromBase58(cfg.SwapAccount),
-		Authority:      solana.MustPublicKeyFromBase58(cfg.Authority),
-		TokenMintA:     solana.MustPublicKeyFromBase58(cfg.TokenMintA),
-		TokenMintB:     solana.MustPublicKeyFromBase58(cfg.TokenMintB),
-		VaultA:         solana.MustPublicKeyFromBase58(cfg.VaultA),
-		VaultB:         solana.MustPublicKeyFromBase58(cfg.VaultB),
-		PoolMint:       solana.MustPublicKeyFromBase58(cfg.PoolMint),
-		FeeAccount:     solana.MustPublicKeyFromBase58(cfg.FeeAccount),
-		FeeNumerator:   cfg.FeeNumerator,
-		FeeDenominator: cfg.FeeDenominator,
-	}
+// HasSwapAccount reports whether a pool with this swap account is registered
+func (r *PoolRegistry) HasSwapAccount(swapAccount solana.PublicKey) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.hasSwapAccount(swapAccount)
+}
 
-	// Parse optional host fee account
-	if cfg.HostFeeAccount != "" {
-		hostFee := solana.MustPublicKeyFromBase58(cfg.HostFeeAccount)
-		pool.HostFeeAccount = &hostFee
+func (r *PoolRegistry) hasSwapAccount(swapAccount solana.PublicKey) bool {
+	for i := range r.pools {
+		if r.pools[i].SwapAccount.Equals(swapAccount) {
+			return true
+		}
 	}
-
-	return pool, nil
+	return false
 }
 
 // FindPoolByMints searches for a pool matching the given token pair
 func (r *PoolRegistry) FindPoolByMints(
 	mintA, mintB solana.PublicKey,
 ) (*LegacyPool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
 	for i := range r.pools {
 		pool := &r.pools[i]
@@ -133,6 +130,9 @@ func (r *PoolRegistry) FindPoolByMints(
 
 // FindPoolByName searches for a pool by its name
 func (r *PoolRegistry) FindPoolByName(name string) (*LegacyPool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	for i := range r.pools {
 		if r.pools[i].Name == name {
 			return &r.pools[i], nil
@@ -143,10 +143,14 @@ func (r *PoolRegistry) FindPoolByName(name string) (*LegacyPool, error) {
 
 // GetAllPools returns all registered pools
 func (r *PoolRegistry) GetAllPools() []LegacyPool {
-	return r.pools
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]LegacyPool(nil), r.pools...)
 }
 
 // PoolCount returns the number of registered pools
 func (r *PoolRegistry) PoolCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return len(r.pools)
 }
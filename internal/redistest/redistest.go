@@ -0,0 +1,66 @@
+// Package redistest connects tests to a local Redis. go test runs packages
+// in parallel, so every package gets a database of its own: a package
+// flushing its database before and after each test never deletes the keys
+// of a test running in another package.
+package redistest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Databases of the packages testing against Redis, one each
+const (
+	DBFlags       = 1
+	DBIntegration = 2 // tests/
+	DBAIQuota     = 3
+	DBIdempotency = 4
+	DBLeader      = 5
+)
+
+// Connect returns a client of database db at REDIS_ADDR (default
+// localhost:6379), emptied now and again when the test ends, or an error
+// when Redis is not reachable
+func Connect(t testing.TB, db int) (*redis.Client, error) {
+	t.Helper()
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr, DB: db})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	if err := client.FlushDB(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("flush Redis database %d: %w", db, err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = client.FlushDB(ctx).Err()
+		_ = client.Close()
+	})
+	return client, nil
+}
+
+// Client is Connect for tests that need Redis: they are skipped when it is
+// not reachable
+func Client(t testing.TB, db int) *redis.Client {
+	t.Helper()
+	client, err := Connect(t, db)
+	if err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+	return client
+}
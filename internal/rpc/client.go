@@ -3,12 +3,14 @@ package rpc
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
 	"github.com/sirupsen/logrus"
 )
 
@@ -110,17 +112,17 @@ func (c *Client) doRequest(ctx context.Context, data []byte) ([]byte, error) {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, apperr.Errorf(apperr.UpstreamUnavailable, "request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle rate limiting
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, fmt.Errorf("rate limited (429)")
+		return nil, apperr.New(apperr.RateLimited, "rate limited (429)")
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, apperr.Errorf(apperr.UpstreamUnavailable, "unexpected status code: %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -147,15 +149,17 @@ func (c *Client) GetSignaturesForAddress(ctx context.Context, address string, op
 	return &result, nil
 }
 
-// GetTransaction fetches full transaction details
-func (c *Client) GetTransaction(ctx context.Context, signature string) (*TransactionResponse, error) {
-	params := []interface{}{
-		signature,
-		map[string]interface{}{
-			"encoding":                       "jsonParsed",
-			"maxSupportedTransactionVersion": 0,
-		},
+// GetTransaction fetches full transaction details at the given commitment
+// ("confirmed" or "finalized"; empty uses the node default)
+func (c *Client) GetTransaction(ctx context.Context, signature, commitment string) (*TransactionResponse, error) {
+	opts := map[string]interface{}{
+		"encoding":                       "jsonParsed",
+		"maxSupportedTransactionVersion": 0,
+	}
+	if commitment != "" {
+		opts["commitment"] = commitment
 	}
+	params := []interface{}{signature, opts}
 
 	var result TransactionResponse
 	if err := c.Call(ctx, "getTransaction", params, &result); err != nil {
@@ -168,3 +172,155 @@ func (c *Client) GetTransaction(ctx context.Context, signature string) (*Transac
 
 	return &result, nil
 }
+
+// GetSignatureStatus returns the status of a transaction, searching the
+// node's full history; nil when the node does not know the signature
+func (c *Client) GetSignatureStatus(ctx context.Context, signature string) (*SignatureStatus, error) {
+	params := []interface{}{[]string{signature}, map[string]interface{}{"searchTransactionHistory": true}}
+
+	var result SignatureStatusesResponse
+	if err := c.Call(ctx, "getSignatureStatuses", params, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if len(result.Result.Value) == 0 {
+		return nil, nil
+	}
+	return result.Result.Value[0], nil
+}
+
+// GetSignatureStatuses returns the statuses of up to 256 transactions, in
+// order, from the node's recent status cache only (about the last 300
+// slots); a nil entry is a signature the cache does not hold
+func (c *Client) GetSignatureStatuses(ctx context.Context, signatures []string) ([]*SignatureStatus, error) {
+	params := []interface{}{signatures, map[string]interface{}{"searchTransactionHistory": false}}
+
+	var result SignatureStatusesResponse
+	if err := c.Call(ctx, "getSignatureStatuses", params, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if len(result.Result.Value) != len(signatures) {
+		return nil, fmt.Errorf("getSignatureStatuses returned %d statuses for %d signatures", len(result.Result.Value), len(signatures))
+	}
+	return result.Result.Value, nil
+}
+
+// GetBlock fetches a block with every transaction in jsonParsed form
+// (without rewards) at the given commitment. Slots without a block fail with
+// an *RPCError, e.g. ErrCodeSlotSkipped.
+func (c *Client) GetBlock(ctx context.Context, slot int64, commitment string) (*BlockResponse, error) {
+	opts := map[string]interface{}{
+		"encoding":                       "jsonParsed",
+		"transactionDetails":             "full",
+		"rewards":                        false,
+		"maxSupportedTransactionVersion": 0,
+	}
+	if commitment != "" {
+		opts["commitment"] = commitment
+	}
+
+	var result BlockResponse
+	if err := c.Call(ctx, "getBlock", []interface{}{slot, opts}, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &result, nil
+}
+
+// SPL mint account layout (Token and Token-2022 share the first 82 bytes)
+const (
+	mintSize           = 82
+	mintDecimalsOffset = 44
+	mintInitOffset     = 45
+)
+
+// ErrAccountNotFound is returned by GetAccountData for an address that holds
+// no account
+var ErrAccountNotFound = apperr.New(apperr.NotFound, "account not found")
+
+// GetAccountData returns the raw data of the account at address
+func (c *Client) GetAccountData(ctx context.Context, address string) ([]byte, error) {
+	params := []interface{}{address, map[string]interface{}{"encoding": "base64"}}
+
+	var result AccountInfoResponse
+	if err := c.Call(ctx, "getAccountInfo", params, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	value := result.Result.Value
+	if value == nil || len(value.Data) == 0 {
+		return nil, fmt.Errorf("%s: %w", address, ErrAccountNotFound)
+	}
+	data, err := base64.StdEncoding.DecodeString(value.Data[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid account data: %w", err)
+	}
+	return data, nil
+}
+
+// GetMintDecimals reads the decimals of an SPL token mint from its account
+func (c *Client) GetMintDecimals(ctx context.Context, mint string) (uint8, error) {
+	data, err := c.GetAccountData(ctx, mint)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < mintSize || data[mintInitOffset] != 1 {
+		return 0, fmt.Errorf("account %s is not an initialized mint", mint)
+	}
+	return data[mintDecimalsOffset], nil
+}
+
+// GetHealth returns nil if the node reports itself healthy
+func (c *Client) GetHealth(ctx context.Context) error {
+	var result HealthResponse
+	if err := c.Call(ctx, "getHealth", []interface{}{}, &result); err != nil {
+		return err
+	}
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.Result != "ok" {
+		return fmt.Errorf("node unhealthy: %q", result.Result)
+	}
+
+	return nil
+}
+
+// GetSlot returns the slot the node has reached at the given commitment
+// ("processed", "confirmed" or "finalized"; empty uses the node default)
+func (c *Client) GetSlot(ctx context.Context, commitment string) (int64, error) {
+	params := []interface{}{}
+	if commitment != "" {
+		params = append(params, map[string]interface{}{"commitment": commitment})
+	}
+
+	var result SlotResponse
+	if err := c.Call(ctx, "getSlot", params, &result); err != nil {
+		return 0, err
+	}
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.Result, nil
+}
@@ -1,15 +1,25 @@
 package rpc
 
+import "encoding/json"
+
 // RPCError represents a JSON-RPC error response
 type RPCError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"` // e.g. the failed preflight simulation of sendTransaction
 }
 
 func (e *RPCError) Error() string {
 	return e.Message
 }
 
+// JSON-RPC error codes of getBlock for slots without a block
+const (
+	ErrCodeBlockNotAvailable          = -32004 // not produced or not yet confirmed; try again later
+	ErrCodeSlotSkipped                = -32007 // skipped by its leader, or missing from the node
+	ErrCodeLongTermStorageSlotSkipped = -32009
+)
+
 // SignatureInfo represents a transaction signature from getSignaturesForAddress
 type SignatureInfo struct {
 	Signature string      `json:"signature"`
@@ -24,6 +34,23 @@ type SignaturesResponse struct {
 	Error  *RPCError       `json:"error"`
 }
 
+// SignatureStatus is where a transaction stands on chain, from
+// getSignatureStatuses
+type SignatureStatus struct {
+	Slot               int64       `json:"slot"`
+	Err                interface{} `json:"err"`                // non-nil when the transaction failed
+	ConfirmationStatus string      `json:"confirmationStatus"` // processed, confirmed or finalized
+}
+
+// SignatureStatusesResponse is the response from getSignatureStatuses; a
+// null entry is a signature the node does not know
+type SignatureStatusesResponse struct {
+	Result struct {
+		Value []*SignatureStatus `json:"value"`
+	} `json:"result"`
+	Error *RPCError `json:"error"`
+}
+
 // TokenAmount represents token balance information
 type TokenAmount struct {
 	Amount         string  `json:"amount"`
@@ -36,16 +63,20 @@ type TokenAmount struct {
 type TokenBalance struct {
 	AccountIndex  int         `json:"accountIndex"`
 	Mint          string      `json:"mint"`
+	Owner         string      `json:"owner"` // wallet or program account owning the token account
 	UITokenAmount TokenAmount `json:"uiTokenAmount"`
 }
 
 // TransactionMeta contains metadata about a transaction
 type TransactionMeta struct {
 	Err               interface{}    `json:"err"`
+	Fee               uint64         `json:"fee"` // lamports paid, base and priority fee
 	PreBalances       []int64        `json:"preBalances"`
 	PostBalances      []int64        `json:"postBalances"`
 	PreTokenBalances  []TokenBalance `json:"preTokenBalances"`
 	PostTokenBalances []TokenBalance `json:"postTokenBalances"`
+
+	ComputeUnitsConsumed uint64 `json:"computeUnitsConsumed"`
 }
 
 // AccountKey represents an account in a transaction
@@ -53,14 +84,24 @@ type AccountKey struct {
 	Pubkey string `json:"pubkey"`
 }
 
+// Instruction is a top-level instruction of a jsonParsed transaction. Data
+// (base58) is only set for programs the node does not parse, such as the
+// compute budget program.
+type Instruction struct {
+	ProgramID string `json:"programId"`
+	Data      string `json:"data"`
+}
+
 // TransactionMessage contains the transaction message
 type TransactionMessage struct {
-	AccountKeys []AccountKey `json:"accountKeys"`
+	AccountKeys  []AccountKey  `json:"accountKeys"`
+	Instructions []Instruction `json:"instructions"`
 }
 
 // Transaction represents a parsed transaction
 type Transaction struct {
-	Message TransactionMessage `json:"message"`
+	Signatures []string           `json:"signatures"`
+	Message    TransactionMessage `json:"message"`
 }
 
 // TransactionResult contains the full transaction data
@@ -75,8 +116,47 @@ type TransactionResponse struct {
 	Error  *RPCError          `json:"error"`
 }
 
+// Block is a confirmed block with its full transactions
+type Block struct {
+	BlockTime    int64               `json:"blockTime"`
+	ParentSlot   int64               `json:"parentSlot"`
+	Transactions []TransactionResult `json:"transactions"`
+}
+
+// BlockResponse is the response from getBlock
+type BlockResponse struct {
+	Result *Block    `json:"result"`
+	Error  *RPCError `json:"error"`
+}
+
 // BalanceChange represents a token balance change in a swap
 type BalanceChange struct {
-	Mint   string
-	Amount float64
+	Mint     string
+	Owner    string
+	Amount   float64 // UI units
+	Raw      int64   // base units
+	Decimals uint8
+}
+
+// AccountInfoResponse is the response from getAccountInfo with base64 data
+type AccountInfoResponse struct {
+	Result struct {
+		Value *struct {
+			Data  []string `json:"data"` // [base64 data, "base64"]
+			Owner string   `json:"owner"`
+		} `json:"value"`
+	} `json:"result"`
+	Error *RPCError `json:"error"`
+}
+
+// HealthResponse is the response from getHealth
+type HealthResponse struct {
+	Result string    `json:"result"`
+	Error  *RPCError `json:"error"`
+}
+
+// SlotResponse is the response from getSlot
+type SlotResponse struct {
+	Result int64     `json:"result"`
+	Error  *RPCError `json:"error"`
 }
@@ -0,0 +1,178 @@
+// Package secrets fetches credentials (OpenRouter key, wallet key, ClickHouse
+// login) from an external secret store at startup and keeps them fresh.
+//
+// Secrets are exported into the process environment, so config.Load and the
+// swap engine pick them up exactly as if they had been set in .env.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ManagedKeys are the environment variables a provider may supply. Anything
+// else found in the secret store is ignored.
+var ManagedKeys = []string{
+	"OPENROUTER_API_KEY",
+	"WALLET_PRIVATE_KEY",
+	"CLICKHOUSE_USERNAME",
+	"CLICKHOUSE_PASSWORD",
+	"REDIS_PASSWORD",
+}
+
+// Provider reads a set of secrets keyed by environment variable name
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// Manager loads secrets from a Provider into the environment and re-fetches
+// them periodically so rotated values are picked up without a restart
+type Manager struct {
+	provider Provider
+	interval time.Duration
+	logger   *logrus.Logger
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// NewManager creates a manager; an interval <= 0 disables rotation checks
+func NewManager(p Provider, interval time.Duration, logger *logrus.Logger) *Manager {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &Manager{
+		provider: p,
+		interval: interval,
+		logger:   logger,
+		values:   make(map[string]string),
+	}
+}
+
+// NewFromEnv builds a manager from SECRETS_PROVIDER (env, vault, aws).
+// It returns nil when secrets come from the environment, which is the default.
+func NewFromEnv(logger *logrus.Logger) (*Manager, error) {
+	var p Provider
+	switch name := strings.ToLower(strings.TrimSpace(os.Getenv("SECRETS_PROVIDER"))); name {
+	case "", "env":
+		return nil, nil
+	case "vault":
+		v, err := NewVaultFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		p = v
+	case "aws":
+		a, err := NewAWSFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		p = a
+	default:
+		return nil, fmt.Errorf("unknown SECRETS_PROVIDER %q (want env, vault or aws)", name)
+	}
+
+	var interval time.Duration
+	if raw := strings.TrimSpace(os.Getenv("SECRETS_REFRESH_INTERVAL")); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SECRETS_REFRESH_INTERVAL %q: %w", raw, err)
+		}
+		interval = d
+	}
+
+	return NewManager(p, interval, logger), nil
+}
+
+// Load fetches secrets and exports them into the environment, overriding any
+// value from .env or the config file. It returns the keys whose value changed.
+func (m *Manager) Load(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	fetched, err := m.provider.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch secrets from %s: %w", m.provider.Name(), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var changed []string
+	for _, key := range ManagedKeys {
+		val, ok := fetched[key]
+		if !ok || val == "" {
+			continue
+		}
+		if m.values[key] == val {
+			continue
+		}
+		if err := os.Setenv(key, val); err != nil {
+			return changed, fmt.Errorf("set %s from %s: %w", key, m.provider.Name(), err)
+		}
+		m.values[key] = val
+		changed = append(changed, key)
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"provider": m.provider.Name(),
+		"keys":     changed,
+	}).Debug("secrets loaded")
+	return changed, nil
+}
+
+// Run re-fetches secrets every refresh interval and calls onRotate with the
+// keys that changed. It blocks until ctx is cancelled and returns immediately
+// if rotation is disabled.
+func (m *Manager) Run(ctx context.Context, onRotate func(changed []string)) {
+	if m.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := m.Load(ctx)
+			if err != nil {
+				m.logger.WithError(err).Warn("secret refresh failed, keeping current values")
+				continue
+			}
+			if len(changed) == 0 {
+				continue
+			}
+			m.logger.WithFields(logrus.Fields{
+				"provider": m.provider.Name(),
+				"keys":     changed,
+			}).Info("secrets rotated")
+			if onRotate != nil {
+				onRotate(changed)
+			}
+		}
+	}
+}
+
+// LoadFromEnv is NewFromEnv followed by an initial Load. Call it after the
+// config file has been applied and before config.Load. The returned manager
+// is nil when no provider is configured.
+func LoadFromEnv(ctx context.Context, logger *logrus.Logger) (*Manager, error) {
+	m, err := NewFromEnv(logger)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	if _, err := m.Load(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
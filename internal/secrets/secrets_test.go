@@ -0,0 +1,62 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVault_FetchKVv2(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/secret/data/indexer", r.URL.Path)
+		assert.Equal(t, "test-token", r.Header.Get("X-Vault-Token"))
+		_, _ = w.Write([]byte(`{"data":{"data":{"OPENROUTER_API_KEY":"sk-vault","MAX_RETRIES":3},"metadata":{"version":2}}}`))
+	}))
+	defer srv.Close()
+
+	v := &Vault{Addr: srv.URL, Token: "test-token", Path: "secret/data/indexer", HTTP: srv.Client()}
+	got, err := v.Fetch(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "sk-vault", got["OPENROUTER_API_KEY"])
+	assert.Equal(t, "3", got["MAX_RETRIES"])
+}
+
+type staticProvider map[string]string
+
+func (p staticProvider) Name() string { return "static" }
+
+func (p staticProvider) Fetch(context.Context) (map[string]string, error) { return p, nil }
+
+func TestManager_LoadExportsManagedKeysOnly(t *testing.T) {
+	t.Setenv("CLICKHOUSE_PASSWORD", "from-dotenv")
+	t.Setenv("REDIS_ADDR", "env-redis:6379")
+
+	p := staticProvider{
+		"CLICKHOUSE_PASSWORD": "from-vault",
+		"REDIS_ADDR":          "vault-redis:6379",
+	}
+	m := NewManager(p, 0, nil)
+
+	changed, err := m.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CLICKHOUSE_PASSWORD"}, changed)
+	assert.Equal(t, "from-vault", os.Getenv("CLICKHOUSE_PASSWORD"))
+	assert.Equal(t, "env-redis:6379", os.Getenv("REDIS_ADDR"))
+
+	// unchanged values are not reported again
+	changed, err = m.Load(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	p["CLICKHOUSE_PASSWORD"] = "rotated"
+	changed, err = m.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CLICKHOUSE_PASSWORD"}, changed)
+	assert.Equal(t, "rotated", os.Getenv("CLICKHOUSE_PASSWORD"))
+}
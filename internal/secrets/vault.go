@@ -0,0 +1,107 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Vault reads secrets from a HashiCorp Vault KV engine (v1 or v2) using
+// token auth. Each key in the secret is an environment variable name.
+type Vault struct {
+	Addr      string
+	Token     string
+	Path      string // e.g. secret/data/solana-indexer (KV v2)
+	Namespace string // Vault Enterprise namespace (optional)
+	HTTP      *http.Client
+}
+
+// NewVaultFromEnv configures Vault from VAULT_ADDR, VAULT_TOKEN,
+// VAULT_SECRET_PATH and the optional VAULT_NAMESPACE
+func NewVaultFromEnv() (*Vault, error) {
+	v := &Vault{
+		Addr:      strings.TrimRight(strings.TrimSpace(os.Getenv("VAULT_ADDR")), "/"),
+		Token:     strings.TrimSpace(os.Getenv("VAULT_TOKEN")),
+		Path:      strings.Trim(strings.TrimSpace(os.Getenv("VAULT_SECRET_PATH")), "/"),
+		Namespace: strings.TrimSpace(os.Getenv("VAULT_NAMESPACE")),
+		HTTP:      &http.Client{Timeout: 10 * time.Second},
+	}
+
+	var missing []string
+	if v.Addr == "" {
+		missing = append(missing, "VAULT_ADDR")
+	}
+	if v.Token == "" {
+		missing = append(missing, "VAULT_TOKEN")
+	}
+	if v.Path == "" {
+		missing = append(missing, "VAULT_SECRET_PATH")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("vault secrets provider requires %s", strings.Join(missing, ", "))
+	}
+	return v, nil
+}
+
+func (v *Vault) Name() string { return "vault" }
+
+// Fetch reads the secret at Path
+func (v *Vault) Fetch(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.Addr+"/v1/"+v.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	res, err := v.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault http %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// KV v2 nests the secret under data.data; KV v1 puts it directly under data
+	var out struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	data := out.Data
+	if nested, ok := data["data"]; ok {
+		if _, hasMeta := data["metadata"]; hasMeta {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return nil, fmt.Errorf("failed to decode vault kv v2 data: %w", err)
+			}
+		}
+	}
+
+	return flatten(data), nil
+}
+
+// flatten turns JSON values into strings; non-string values keep their JSON form
+func flatten(data map[string]json.RawMessage) map[string]string {
+	vals := make(map[string]string, len(data))
+	for k, raw := range data {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			vals[k] = s
+			continue
+		}
+		vals[k] = string(raw)
+	}
+	return vals
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/ai"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+)
+
+// answerByIntent answers the questions /v1/ai/ask needs no SQL for: price
+// lookups come straight from Redis, and quotes, swaps and unrelated questions
+// are refused with a pointer to what to use instead. It returns two nils when
+// the question should go through SQL generation.
+func (h *Handlers) answerByIntent(ctx context.Context, cls *ai.Classification) (*AIAskResponse, *ErrorResponse) {
+	refuse := func(msg, hint string) *ErrorResponse {
+		return &ErrorResponse{Error: msg, Code: http.StatusUnprocessableEntity, Hint: hint}
+	}
+	switch cls.Intent {
+	case ai.IntentPrice:
+		if answer := h.priceAnswer(ctx, cls.Token); answer != "" {
+			return &AIAskResponse{Intent: string(cls.Intent), Answer: answer}, nil
+		}
+	case ai.IntentQuote:
+		return nil, refuse("ai does not quote swaps", "use GET /v1/quote with inputMint, outputMint and a raw amount for a live quote")
+	case ai.IntentExecution:
+		return nil, refuse("ai does not execute swaps", "use POST /v1/swap/execute to make a swap")
+	case ai.IntentOutOfScope:
+		return nil, refuse("question is out of scope", "ask about indexed Solana DEX swaps: prices, volumes, pairs, wallets, fees")
+	}
+	return nil, nil
+}
+
+// priceAnswer words the cached price of token, or returns "" when none is
+// cached and the swaps table has to answer
+func (h *Handlers) priceAnswer(ctx context.Context, token string) string {
+	price, err := h.Cache.GetPrice(ctx, token)
+	if err != nil || price == nil {
+		return ""
+	}
+	answer := fmt.Sprintf("%s last traded at %s", token, strconv.FormatFloat(price.Price, 'g', 6, 64))
+	if price.UpdatedAt.IsZero() {
+		return answer + "."
+	}
+	staleAfter := h.PriceStaleAfter
+	if staleAfter <= 0 {
+		staleAfter = constants.PriceStaleAfter
+	}
+	age := time.Since(price.UpdatedAt).Round(time.Second)
+	answer += fmt.Sprintf(", %s ago.", age)
+	if price.StaleAt(time.Now(), staleAfter) {
+		answer += " No swap has been indexed since, so it may be out of date."
+	}
+	return answer
+}
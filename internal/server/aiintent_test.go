@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/ai"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnswerByIntent(t *testing.T) {
+	ctx := context.Background()
+	mem := cache.NewMemoryCache(10, 0)
+	mem.SetPrice(models.TokenPrice{Token: "SOL", Price: 187.4213, UpdatedAt: time.Now().Add(-90 * time.Second)})
+	mem.SetPrice(models.TokenPrice{Token: "BONK", Price: 0.0000213, UpdatedAt: time.Now().Add(-2 * time.Hour)})
+	h := &Handlers{Cache: mem, PriceStaleAfter: time.Hour}
+
+	resp, refusal := h.answerByIntent(ctx, &ai.Classification{Intent: ai.IntentPrice, Token: "SOL"})
+	require.Nil(t, refusal)
+	require.NotNil(t, resp)
+	assert.Equal(t, "price", resp.Intent)
+	assert.Empty(t, resp.SQL)
+	assert.Equal(t, "SOL last traded at 187.421, 1m30s ago.", resp.Answer)
+
+	resp, _ = h.answerByIntent(ctx, &ai.Classification{Intent: ai.IntentPrice, Token: "BONK"})
+	require.NotNil(t, resp)
+	assert.Contains(t, resp.Answer, "2.13e-05")
+	assert.Contains(t, resp.Answer, "may be out of date")
+
+	resp, refusal = h.answerByIntent(ctx, &ai.Classification{Intent: ai.IntentPrice, Token: "WIF"})
+	assert.Nil(t, resp, "uncached prices go to SQL")
+	assert.Nil(t, refusal)
+
+	resp, refusal = h.answerByIntent(ctx, &ai.Classification{Intent: ai.IntentAnalytics})
+	assert.Nil(t, resp)
+	assert.Nil(t, refusal)
+
+	for intent, hint := range map[ai.Intent]string{
+		ai.IntentQuote:      "/v1/quote",
+		ai.IntentExecution:  "/v1/swap/execute",
+		ai.IntentOutOfScope: "Solana DEX swaps",
+	} {
+		resp, refusal = h.answerByIntent(ctx, &ai.Classification{Intent: intent})
+		assert.Nil(t, resp)
+		require.NotNil(t, refusal, intent)
+		assert.Equal(t, http.StatusUnprocessableEntity, refusal.Code)
+		assert.Contains(t, refusal.Hint, hint)
+	}
+}
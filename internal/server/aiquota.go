@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/ai"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/aiquota"
+	"github.com/labstack/echo/v4"
+)
+
+// AIRateLimiter admits /v1/ai requests per client
+// (implemented by *aiquota.Limiter)
+type AIRateLimiter interface {
+	Allow(ctx context.Context, key string) (bool, time.Duration, error)
+}
+
+// AIBudget caps the estimated LLM spend of each client per month
+// (implemented by *aiquota.Budget)
+type AIBudget interface {
+	Limit() float64
+	Remaining(ctx context.Context, key string, now time.Time) (float64, error)
+	Charge(ctx context.Context, key string, u ai.Usage, now time.Time) (float64, error)
+}
+
+// Response headers describing the caller's AI budget
+const (
+	headerAIBudgetLimit     = "X-AI-Budget-Limit"     // USD per month
+	headerAIBudgetRemaining = "X-AI-Budget-Remaining" // USD left this month
+	headerAIBudgetReset     = "X-AI-Budget-Reset"     // RFC3339 start of the next month
+)
+
+// aiClient identifies who a /v1/ai request is accounted to: the fingerprint
+// of its API key, or its IP when the API runs without keys
+func aiClient(c echo.Context) string {
+	if id, ok := c.Get(apiKeyIDContextKey).(string); ok && id != "" {
+		return "key:" + id
+	}
+	return "ip:" + c.RealIP()
+}
+
+// aiRateLimit refuses requests over the client's rate with 429 and a
+// Retry-After. When the limiter is unreachable requests are let through.
+func (h *Handlers) aiRateLimit(limiter AIRateLimiter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ok, wait, err := limiter.Allow(c.Request().Context(), aiClient(c))
+			if err != nil {
+				if h.Logger != nil {
+					h.log(c).WithError(err).Warn("ai rate limiter unavailable, allowing request")
+				}
+				return next(c)
+			}
+			if !ok {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				return h.err(c, http.StatusTooManyRequests, "ai rate limit exceeded", nil)
+			}
+			return next(c)
+		}
+	}
+}
+
+// aiBudgetExhausted sets the budget headers and reports whether client has
+// nothing left to spend this month. A budget that cannot be read does not
+// block the request.
+func (h *Handlers) aiBudgetExhausted(c echo.Context, client string, now time.Time) bool {
+	if h.AIBudget == nil {
+		return false
+	}
+	remaining, err := h.AIBudget.Remaining(c.Request().Context(), client, now)
+	if err != nil {
+		if h.Logger != nil {
+			h.log(c).WithError(err).Warn("ai budget unavailable, allowing request")
+		}
+		return false
+	}
+	setAIBudgetHeaders(c, h.AIBudget.Limit(), remaining, now)
+	return remaining <= 0
+}
+
+// chargeAI adds the cost of u to client's spend and updates the budget
+// headers. It runs even when the request timed out, since the tokens were
+// billed anyway.
+func (h *Handlers) chargeAI(c echo.Context, client string, u ai.Usage, now time.Time) {
+	if h.AIBudget == nil || u == (ai.Usage{}) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request().Context()), 2*time.Second)
+	defer cancel()
+	remaining, err := h.AIBudget.Charge(ctx, client, u, now)
+	if err != nil {
+		if h.Logger != nil {
+			h.log(c).WithError(err).WithField("client", client).Warn("failed to charge ai budget")
+		}
+		return
+	}
+	setAIBudgetHeaders(c, h.AIBudget.Limit(), remaining, now)
+}
+
+func setAIBudgetHeaders(c echo.Context, limit, remaining float64, now time.Time) {
+	hdr := c.Response().Header()
+	hdr.Set(headerAIBudgetLimit, strconv.FormatFloat(limit, 'f', 4, 64))
+	hdr.Set(headerAIBudgetRemaining, strconv.FormatFloat(max(remaining, 0), 'f', 4, 64))
+	hdr.Set(headerAIBudgetReset, aiquota.Reset(now).Format(time.RFC3339))
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/ai"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAIRate admits allow requests per client, then asks to wait a second
+type fakeAIRate struct {
+	allow int
+	seen  map[string]int
+}
+
+func (f *fakeAIRate) Allow(_ context.Context, key string) (bool, time.Duration, error) {
+	f.seen[key]++
+	if f.seen[key] > f.allow {
+		return false, 1500 * time.Millisecond, nil
+	}
+	return true, 0, nil
+}
+
+type fakeAIBudget struct {
+	limit float64
+	spent map[string]float64
+}
+
+func (f *fakeAIBudget) Limit() float64 { return f.limit }
+
+func (f *fakeAIBudget) Remaining(_ context.Context, key string, _ time.Time) (float64, error) {
+	return f.limit - f.spent[key], nil
+}
+
+func (f *fakeAIBudget) Charge(_ context.Context, key string, u ai.Usage, _ time.Time) (float64, error) {
+	f.spent[key] += float64(u.PromptTokens+u.CompletionTokens) / 1000
+	return f.limit - f.spent[key], nil
+}
+
+func TestAIRateLimitPerKey(t *testing.T) {
+	limiter := &fakeAIRate{allow: 1, seen: map[string]int{}}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{AIRate: limiter}, ServerConfig{APIKey: "k1", APIKeys: []string{"k2"}})
+
+	ask := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/ai/ask", nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.NotEqual(t, http.StatusTooManyRequests, ask("k1").Code)
+	rec := ask("k1")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.NotEqual(t, http.StatusTooManyRequests, ask("k2").Code, "second key has its own allowance")
+	assert.Equal(t, http.StatusUnauthorized, ask("k3").Code)
+	assert.Len(t, limiter.seen, 2)
+}
+
+func TestAPIKeysScope(t *testing.T) {
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{AIRate: &fakeAIRate{allow: 10, seen: map[string]int{}}}, ServerConfig{APIKey: "k1", APIKeys: []string{"k2"}})
+
+	call := func(method, path, key string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, r := range [][2]string{
+		{http.MethodPost, "/v1/admin/config/reload"},
+		{http.MethodGet, "/v1/admin/indexer/status"},
+		{http.MethodGet, "/v1/flags"},
+		{http.MethodPost, "/v1/swap/execute"},
+		{http.MethodPut, "/v1/swap/risk-config"},
+		{http.MethodPost, "/v1/tx/send"},
+	} {
+		assert.Equal(t, http.StatusForbidden, call(r[0], r[1], "k2"), r[1])
+		assert.NotEqual(t, http.StatusForbidden, call(r[0], r[1], "k1"), r[1])
+		assert.Equal(t, http.StatusUnauthorized, call(r[0], r[1], "k3"), r[1])
+	}
+	assert.NotEqual(t, http.StatusForbidden, call(http.MethodPost, "/v1/ai/ask", "k2"))
+	assert.NotEqual(t, http.StatusForbidden, call(http.MethodGet, "/v1/swap/risk-config", "k2"))
+}
+
+func TestAIBudget(t *testing.T) {
+	budget := &fakeAIBudget{limit: 1, spent: map[string]float64{"key:a": 0.25}}
+	h := &Handlers{AIBudget: budget}
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	ctx := func() (echo.Context, *httptest.ResponseRecorder) {
+		rec := httptest.NewRecorder()
+		return echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/v1/ai/ask", nil), rec), rec
+	}
+
+	c, rec := ctx()
+	require.False(t, h.aiBudgetExhausted(c, "key:a", now))
+	assert.Equal(t, "1.0000", rec.Header().Get(headerAIBudgetLimit))
+	assert.Equal(t, "0.7500", rec.Header().Get(headerAIBudgetRemaining))
+	assert.Equal(t, "2025-04-01T00:00:00Z", rec.Header().Get(headerAIBudgetReset))
+
+	h.chargeAI(c, "key:a", ai.Usage{PromptTokens: 600, CompletionTokens: 400}, now)
+	assert.Equal(t, "0.0000", rec.Header().Get(headerAIBudgetRemaining), "overspend is reported as zero")
+
+	c, _ = ctx()
+	assert.True(t, h.aiBudgetExhausted(c, "key:a", now))
+	assert.False(t, h.aiBudgetExhausted(c, "key:b", now))
+	assert.False(t, (&Handlers{}).aiBudgetExhausted(c, "key:a", now), "no budget configured")
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// AnomalyReader returns the most recent volume anomalies, newest first
+// (implemented by *cache.RedisCache)
+type AnomalyReader interface {
+	RecentAnomalies(ctx context.Context, limit int64) ([]*models.Anomaly, error)
+}
+
+// RecentAnomalies lists the latest hourly volume and trade-count spikes found by
+// the anomaly detector (ssi anomalies). Accepts limit (default 50, max 500)
+// and optional pair and metric (volume or trades) filters.
+func (h *Handlers) RecentAnomalies(c echo.Context) error {
+	if h.Anomalies == nil {
+		return h.err(c, http.StatusBadRequest, "anomaly detector is not enabled", nil)
+	}
+	req := AnomaliesRequest{Limit: 50}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+	pair := strings.ToUpper(strings.TrimSpace(req.Pair))
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 2*time.Second)
+	defer cancel()
+
+	// Filters need the whole retained list
+	n := int64(req.Limit)
+	if pair != "" || req.Metric != "" {
+		n = constants.AnomaliesRecentMax
+	}
+	found, err := h.Anomalies.RecentAnomalies(ctx, n)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to get anomalies", err)
+	}
+	kept := found[:0]
+	for _, a := range found {
+		if (pair == "" || a.Pair == pair) && (req.Metric == "" || a.Metric == req.Metric) && len(kept) < req.Limit {
+			kept = append(kept, a)
+		}
+	}
+	return c.JSON(http.StatusOK, AnomaliesResponse{Anomalies: kept, Count: len(kept)})
+}
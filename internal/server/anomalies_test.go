@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAnomalies struct {
+	found []*models.Anomaly
+}
+
+func (f *fakeAnomalies) RecentAnomalies(_ context.Context, limit int64) ([]*models.Anomaly, error) {
+	return f.found[:min(int(limit), len(f.found))], nil
+}
+
+func TestRecentAnomalies(t *testing.T) {
+	anomalies := &fakeAnomalies{found: []*models.Anomaly{
+		{Pair: "SOL/USDC", Metric: models.AnomalyVolume, ZScore: 8},
+		{Pair: "BONK/SOL", Metric: models.AnomalyTrades, ZScore: 5},
+		{Pair: "SOL/USDC", Metric: models.AnomalyTrades, ZScore: 4},
+	}}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Anomalies: anomalies}, ServerConfig{})
+
+	rec := get(t, e, "/v1/anomalies?pair=sol/usdc&metric=trades", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp AnomaliesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, 1, resp.Count)
+	assert.InDelta(t, 4, resp.Anomalies[0].ZScore, 1e-9)
+
+	rec = get(t, e, "/v1/anomalies?limit=2", "")
+	resp = AnomaliesResponse{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 2, resp.Count)
+
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/anomalies?metric=price", "").Code)
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{})
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/anomalies", "").Code)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// ArbReader returns the most recent arbitrage opportunities, newest first
+// (implemented by *cache.RedisCache)
+type ArbReader interface {
+	RecentArbOpportunities(ctx context.Context, limit int64) ([]*models.ArbOpportunity, error)
+}
+
+// ArbOpportunities lists the latest cross-venue price gaps found by the
+// arbitrage detector (ssi arb). Accepts limit (default 50, max 200) and an
+// optional pair in either token order.
+func (h *Handlers) ArbOpportunities(c echo.Context) error {
+	if h.Arb == nil {
+		return h.err(c, http.StatusBadRequest, "arbitrage detector is not enabled", nil)
+	}
+	req := ArbOpportunitiesRequest{Limit: 50}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 2*time.Second)
+	defer cancel()
+
+	// Filtering by pair needs the whole retained list
+	n := int64(req.Limit)
+	if req.Pair != "" {
+		n = constants.ArbRecentMax
+	}
+	ops, err := h.Arb.RecentArbOpportunities(ctx, n)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to get arbitrage opportunities", err)
+	}
+	if req.Pair != "" {
+		want := canonicalPair(req.Pair)
+		kept := ops[:0]
+		for _, op := range ops {
+			if op.Pair == want && len(kept) < req.Limit {
+				kept = append(kept, op)
+			}
+		}
+		ops = kept
+	}
+	return c.JSON(http.StatusOK, ArbOpportunitiesResponse{Opportunities: ops, Count: len(ops)})
+}
+
+// canonicalPair upper-cases a pair and orders its tokens alphabetically, as
+// the detector names pairs
+func canonicalPair(pair string) string {
+	base, quote, _ := strings.Cut(strings.ToUpper(strings.TrimSpace(pair)), "/")
+	if quote < base {
+		base, quote = quote, base
+	}
+	return base + "/" + quote
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeArb struct {
+	ops   []*models.ArbOpportunity
+	limit int64
+}
+
+func (f *fakeArb) RecentArbOpportunities(_ context.Context, limit int64) ([]*models.ArbOpportunity, error) {
+	f.limit = limit
+	return f.ops[:min(int(limit), len(f.ops))], nil
+}
+
+func TestArbOpportunities(t *testing.T) {
+	arb := &fakeArb{ops: []*models.ArbOpportunity{
+		{Pair: "SOL/USDC", BuyVenue: "Orca", SellVenue: "Raydium", SpreadBps: 80},
+		{Pair: "BONK/SOL", BuyVenue: "Raydium", SellVenue: "Jupiter", SpreadBps: 120},
+		{Pair: "SOL/USDC", BuyVenue: "Jupiter", SellVenue: "Orca", SpreadBps: 60},
+	}}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Arb: arb}, ServerConfig{})
+
+	rec := get(t, e, "/v1/arb/opportunities?limit=2", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp ArbOpportunitiesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 2, resp.Count)
+	assert.EqualValues(t, 2, arb.limit)
+
+	// either token order matches the canonical pair
+	rec = get(t, e, "/v1/arb/opportunities?pair=usdc/sol", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	resp = ArbOpportunitiesResponse{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, 2, resp.Count)
+	assert.Equal(t, "Raydium", resp.Opportunities[0].SellVenue)
+
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/arb/opportunities?limit=500", "").Code)
+}
+
+func TestArbOpportunitiesDisabled(t *testing.T) {
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{})
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/arb/opportunities", "").Code)
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// drainer counts in-flight requests and open streams so Shutdown can let them
+// finish. Once draining, new requests are refused with 503.
+type drainer struct {
+	mu       sync.Mutex
+	draining bool
+	deadline time.Time // end of the drain window (zero: none)
+	inflight int
+	idle     chan struct{} // closed when inflight reaches 0 while draining
+	streams  map[int]func()
+	nextID   int
+}
+
+func newDrainer() *drainer {
+	return &drainer{streams: make(map[int]func())}
+}
+
+// enter registers a request, or reports false once draining has started
+func (d *drainer) enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.inflight++
+	return true
+}
+
+// leave marks a request registered by enter as finished
+func (d *drainer) leave() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.inflight--
+	if d.draining && d.inflight == 0 {
+		close(d.idle)
+	}
+}
+
+// addStream registers the close func of a long-lived stream (WebSocket or
+// SSE); it is called once when draining starts. The returned func
+// unregisters the stream and must be called when the handler returns.
+func (d *drainer) addStream(closeFn func()) func() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id := d.nextID
+	d.nextID++
+	d.streams[id] = closeFn
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.streams, id)
+	}
+}
+
+// drain stops accepting requests, asks open streams to close and waits for
+// in-flight requests to finish or ctx to end
+func (d *drainer) drain(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.draining {
+		d.draining = true
+		d.deadline, _ = ctx.Deadline()
+		d.idle = make(chan struct{})
+		if d.inflight == 0 {
+			close(d.idle)
+		}
+	}
+	idle := d.idle
+	closers := make([]func(), 0, len(d.streams))
+	for id, fn := range d.streams {
+		closers = append(closers, fn)
+		delete(d.streams, id)
+	}
+	d.mu.Unlock()
+
+	for _, fn := range closers {
+		fn() // sends the close frame; the stream handler then returns
+	}
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryAfter is the number of seconds left in the drain window (at least 1)
+func (d *drainer) retryAfter() int {
+	d.mu.Lock()
+	deadline := d.deadline
+	d.mu.Unlock()
+	if deadline.IsZero() {
+		return 1
+	}
+	return max(1, int(math.Ceil(time.Until(deadline).Seconds())))
+}
+
+// drainGuard tracks each request in d and answers 503 with Retry-After once
+// the server is shutting down
+func (h *Handlers) drainGuard(d *drainer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !d.enter() {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(d.retryAfter()))
+				c.Response().Header().Set(echo.HeaderConnection, "close")
+				return h.err(c, http.StatusServiceUnavailable, "server is shutting down", nil)
+			}
+			defer d.leave()
+			return next(c)
+		}
+	}
+}
+
+// trackStream registers a long-lived stream of the current request, see
+// drainer.addStream. closeFn should send the protocol's close frame (a
+// WebSocket close, or a final SSE event) and make the handler return.
+func (h *Handlers) trackStream(closeFn func()) (release func()) {
+	if h.drain == nil {
+		return func() {}
+	}
+	return h.drain.addStream(closeFn)
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	h := &Handlers{}
+	e := echo.New()
+	RegisterRoutes(e, h, ServerConfig{})
+
+	started, release := make(chan struct{}), make(chan struct{})
+	e.GET("/slow", func(c echo.Context) error {
+		close(started)
+		<-release
+		return c.JSON(http.StatusOK, HealthResponse{OK: true})
+	})
+	streamClosed := make(chan struct{})
+	done := h.trackStream(func() { close(streamClosed) })
+	defer done()
+
+	slow := httptest.NewRecorder()
+	go e.ServeHTTP(slow, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	drained := make(chan error, 1)
+	go func() { drained <- h.drain.drain(ctx) }()
+
+	select {
+	case <-streamClosed:
+	case <-time.After(time.Second):
+		t.Fatal("stream was not closed")
+	}
+
+	// New requests are refused while the slow one finishes
+	require.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		return rec.Code == http.StatusServiceUnavailable && rec.Header().Get("Retry-After") != ""
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case <-drained:
+		t.Fatal("drain returned with a request in flight")
+	default:
+	}
+	close(release)
+	require.NoError(t, <-drained)
+	assert.Equal(t, http.StatusOK, slow.Code)
+}
+
+func TestDrainTimeout(t *testing.T) {
+	d := newDrainer()
+	require.True(t, d.enter())
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.drain(ctx), context.DeadlineExceeded)
+	assert.False(t, d.enter())
+	d.leave()
+}
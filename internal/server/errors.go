@@ -3,6 +3,7 @@ package server
 import (
 	"net/http"
 
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
 	"github.com/labstack/echo/v4"
 )
 
@@ -17,9 +18,20 @@ func NotFoundJSON() echo.HTTPErrorHandler {
 
 		// Handle Echo HTTP errors (like 404, 400, etc.)
 		if he, ok := err.(*echo.HTTPError); ok {
-			_ = c.JSON(he.Code, ErrorResponse{
-				Error: http.StatusText(he.Code),
-				Code:  he.Code,
+			resp := ErrorResponse{Error: http.StatusText(he.Code), Code: he.Code}
+			if he.Code < 500 {
+				resp.Category = string(apperr.ForStatus(he.Code))
+			}
+			_ = c.JSON(he.Code, resp)
+			return
+		}
+
+		// Errors with an apperr code (a missing account, an unreachable upstream)
+		if code := apperr.CodeOf(err); code != "" {
+			_ = c.JSON(code.HTTPStatus(), ErrorResponse{
+				Error:    http.StatusText(code.HTTPStatus()),
+				Code:     code.HTTPStatus(),
+				Category: string(code),
 			})
 			return
 		}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// weakETag builds a weak entity tag from the given parts, e.g. W/"SOL/USDC-5xYz"
+func weakETag(parts ...string) string {
+	return `W/"` + strings.ReplaceAll(strings.Join(parts, "-"), `"`, "") + `"`
+}
+
+// ifNoneMatch reports whether the request's If-None-Match header matches etag.
+// Comparison is weak (RFC 9110 §13.1.2): the W/ prefix is ignored on both sides.
+func ifNoneMatch(c echo.Context, etag string) bool {
+	header := c.Request().Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// setETag tags the response. Responses carrying an ETag may be stored but must
+// be revalidated (no-cache instead of the global no-store), so polling clients
+// send If-None-Match and get a cheap 304 until the data changes.
+func setETag(c echo.Context, etag string) {
+	h := c.Response().Header()
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", "no-cache")
+}
+
+// notModified tags the response and, when the client already holds that
+// version, writes a bodiless 304
+func notModified(c echo.Context, etag string) bool {
+	setETag(c, etag)
+	if !ifNoneMatch(c, etag) {
+		return false
+	}
+	c.Response().Header().Del(echo.HeaderContentType)
+	c.Response().WriteHeader(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, e *echo.Echo, path, ifNoneMatch string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRecentSwapsETag(t *testing.T) {
+	mem := cache.NewMemoryCache(10, 0)
+	ctx := context.Background()
+	require.NoError(t, mem.AddRecentSwap(ctx, &models.SwapEvent{Signature: "sig1", Pair: "SOL/USDC"}))
+
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Cache: mem}, ServerConfig{})
+
+	rec := get(t, e, "/v1/swaps/recent?limit=5", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	assert.Equal(t, `W/"-5-sig1"`, etag)
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+
+	rec = get(t, e, "/v1/swaps/recent?limit=5", etag)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// a different limit is a different representation
+	rec = get(t, e, "/v1/swaps/recent?limit=6", etag)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	require.NoError(t, mem.AddRecentSwap(ctx, &models.SwapEvent{Signature: "sig2", Pair: "SOL/USDC"}))
+	rec = get(t, e, "/v1/swaps/recent?limit=5", etag)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `W/"-5-sig2"`, rec.Header().Get("ETag"))
+}
+
+func TestPriceETag(t *testing.T) {
+	mem := cache.NewMemoryCache(10, 0)
+	mem.SetPrice(models.TokenPrice{Token: "SOL", Price: 150, UpdatedAt: time.Now()})
+
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Cache: mem}, ServerConfig{})
+
+	rec := get(t, e, "/v1/prices/sol", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// weak comparison ignores the W/ prefix; lists are accepted
+	rec = get(t, e, "/v1/prices/SOL", `"other", `+etag[2:])
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	mem.SetPrice(models.TokenPrice{Token: "SOL", Price: 151, UpdatedAt: time.Now().Add(time.Second)})
+	rec = get(t, e, "/v1/prices/SOL", etag)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/labstack/echo/v4"
+)
+
+// SwapExporter streams stored swaps, oldest first
+// (implemented by *cache.ClickHouseStore)
+type SwapExporter interface {
+	ScanSwaps(ctx context.Context, q storage.SwapQuery, fn func(*models.SwapEvent) error) error
+}
+
+// exportStatusTrailer is sent after the last row: complete, truncated (row
+// cap reached) or failed. The status code is written before the first row, so
+// only the trailer can tell a cut export from a finished one.
+const exportStatusTrailer = "X-Export-Status"
+
+// exportFlushRows is how many rows are buffered between flushes
+const exportFlushRows = 500
+
+// errExportCapped stops a scan once the row cap is reached
+var errExportCapped = errors.New("export row cap reached")
+
+// ExportSwaps streams the stored swaps between from and to (default: the
+// last 24 hours), oldest first, as NDJSON or CSV with chunked encoding.
+// Accepts format (ndjson, csv), from and to (RFC3339), pair and limit
+// (default and max ExportMaxRows).
+func (h *Handlers) ExportSwaps(c echo.Context) error {
+	if h.Exports == nil {
+		return h.err(c, http.StatusBadRequest, "swap exports are not configured", nil)
+	}
+	maxRows := h.ExportMaxRows
+	if maxRows <= 0 {
+		maxRows = constants.ExportMaxRows
+	}
+	req := ExportSwapsRequest{Format: "ndjson", Limit: maxRows}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+	to, from := req.To, req.From
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.IsZero() {
+		from = to.Add(-24 * time.Hour)
+	}
+	if !from.Before(to) {
+		return h.err(c, http.StatusBadRequest, "from must be before to", nil)
+	}
+	limit := min(req.Limit, maxRows)
+
+	w := c.Response()
+	enc := newSwapEncoder(req.Format, w)
+	name := "swaps-" + from.UTC().Format("20060102T150405Z") + "-" + to.UTC().Format("20060102T150405Z") + "." + req.Format
+	w.Header().Set(echo.HeaderContentType, enc.contentType)
+	w.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+name+`"`)
+	w.Header().Set("X-Export-Max-Rows", strconv.Itoa(limit))
+	w.Header().Set("Trailer", exportStatusTrailer)
+
+	// The route deadline (EXPORT_TIMEOUT) bounds the export, not the server's
+	// write timeout sized for ordinary responses
+	ctx := c.Request().Context()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = http.NewResponseController(w).SetWriteDeadline(deadline.Add(5 * time.Second))
+	}
+	w.WriteHeader(http.StatusOK)
+	if err := enc.header(); err != nil {
+		return nil
+	}
+
+	rows := 0
+	q := storage.SwapQuery{From: from, To: to, Pair: strings.ToUpper(strings.TrimSpace(req.Pair)), Limit: limit + 1}
+	err := h.Exports.ScanSwaps(ctx, q, func(swap *models.SwapEvent) error {
+		if rows == limit {
+			return errExportCapped
+		}
+		if err := enc.write(swap); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := enc.flush(); err != nil {
+				return err
+			}
+			w.Flush()
+		}
+		return nil
+	})
+	if ferr := enc.flush(); err == nil {
+		err = ferr
+	}
+
+	status := "complete"
+	switch {
+	case errors.Is(err, errExportCapped):
+		status = "truncated"
+	case err != nil:
+		status = "failed"
+		if h.Logger != nil {
+			h.log(c).WithError(err).WithField("rows", rows).Warn("swap export failed")
+		}
+	}
+	w.Header().Set(exportStatusTrailer, status)
+	return nil
+}
+
+// exportColumns are the CSV columns, in the order of swapRecord
+var exportColumns = []string{
+	"signature", "timestamp", "pair", "token_in", "token_out", "amount_in", "amount_out", "price", "fee",
+	"pool", "dex", "slot", "block_time", "amount_in_raw", "amount_out_raw", "decimals_in", "decimals_out",
+	"program_id", "pool_address", "wallet", "fee_lamports", "priority_fee", "compute_unit_price",
+	"indexed_at", "finalized",
+}
+
+// swapRecord formats a swap as a CSV row
+func swapRecord(s *models.SwapEvent) []string {
+	f := func(x float64) string { return strconv.FormatFloat(x, 'f', -1, 64) }
+	u := func(x uint64) string { return strconv.FormatUint(x, 10) }
+	indexedAt := ""
+	if !s.IndexedAt.IsZero() {
+		indexedAt = s.IndexedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return []string{
+		s.Signature, s.Timestamp.UTC().Format(time.RFC3339Nano), s.Pair, s.TokenIn, s.TokenOut,
+		f(s.AmountIn), f(s.AmountOut), f(s.Price), f(s.Fee), s.Pool, s.Dex,
+		u(s.Slot), strconv.FormatInt(s.BlockTime, 10), u(s.AmountInRaw), u(s.AmountOutRaw),
+		u(uint64(s.DecimalsIn)), u(uint64(s.DecimalsOut)), s.ProgramID, s.PoolAddress, s.Wallet,
+		u(s.FeeLamports), u(s.PriorityFee), u(s.ComputeUnitPrice), indexedAt,
+		strconv.FormatBool(s.Finalized),
+	}
+}
+
+// swapEncoder writes swaps as NDJSON (one JSON object per line) or CSV with a
+// header row
+type swapEncoder struct {
+	contentType string
+	csv         *csv.Writer   // nil for NDJSON
+	json        *json.Encoder // nil for CSV
+}
+
+func newSwapEncoder(format string, w io.Writer) *swapEncoder {
+	if format == "csv" {
+		return &swapEncoder{contentType: "text/csv; charset=utf-8", csv: csv.NewWriter(w)}
+	}
+	return &swapEncoder{contentType: "application/x-ndjson", json: json.NewEncoder(w)}
+}
+
+func (e *swapEncoder) header() error {
+	if e.csv == nil {
+		return nil
+	}
+	return e.csv.Write(exportColumns)
+}
+
+func (e *swapEncoder) write(s *models.SwapEvent) error {
+	if e.csv == nil {
+		return e.json.Encode(s)
+	}
+	return e.csv.Write(swapRecord(s))
+}
+
+// flush writes buffered CSV rows; NDJSON is not buffered
+func (e *swapEncoder) flush() error {
+	if e.csv == nil {
+		return nil
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExporter struct {
+	swaps []*models.SwapEvent
+	q     storage.SwapQuery
+}
+
+func (f *fakeExporter) ScanSwaps(_ context.Context, q storage.SwapQuery, fn func(*models.SwapEvent) error) error {
+	f.q = q
+	for i, s := range f.swaps {
+		if q.Limit > 0 && i == q.Limit {
+			break
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExportSwaps(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	exporter := &fakeExporter{}
+	for i := range 3 {
+		exporter.swaps = append(exporter.swaps, &models.SwapEvent{
+			Signature: "sig" + string(rune('a'+i)), Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Pair: "SOL/USDC", AmountIn: 1.5, Slot: uint64(100 + i), Wallet: "w, with comma",
+		})
+	}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Exports: exporter, ExportMaxRows: 10}, ServerConfig{})
+
+	rec := get(t, e, "/v1/export/swaps?from=2025-01-01T00:00:00Z&to=2025-01-02T00:00:00Z&pair=sol/usdc", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "complete", rec.Result().Trailer.Get(exportStatusTrailer))
+	assert.Equal(t, storage.SwapQuery{From: start, To: start.Add(24 * time.Hour), Pair: "SOL/USDC", Limit: 11}, exporter.q)
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 3)
+	var first models.SwapEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "siga", first.Signature)
+	assert.EqualValues(t, 100, first.Slot)
+
+	rec = get(t, e, "/v1/export/swaps?format=csv&limit=2", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), ".csv")
+	assert.Equal(t, "truncated", rec.Result().Trailer.Get(exportStatusTrailer))
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3, "header and two rows")
+	assert.Equal(t, exportColumns, records[0])
+	assert.Equal(t, "w, with comma", records[1][19])
+	assert.Equal(t, "2025-01-01T00:00:00Z", records[1][1])
+
+	rec = get(t, e, "/v1/export/swaps?limit=500", "")
+	assert.Equal(t, "10", rec.Header().Get("X-Export-Max-Rows"), "capped at ExportMaxRows")
+
+	for _, bad := range []string{"format=xml", "from=yesterday", "from=2025-01-02T00:00:00Z&to=2025-01-01T00:00:00Z", "pair=SOL"} {
+		assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/export/swaps?"+bad, "").Code, bad)
+	}
+
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{})
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/export/swaps", "").Code)
+}
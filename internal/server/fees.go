@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// FeeAnalytics averages the fees swaps paid to land
+// (implemented by *cache.ClickHouseStore)
+type FeeAnalytics interface {
+	FeeStats(ctx context.Context, since time.Time, top int) (*models.FeeStats, error)
+}
+
+// FeeStats reports the average landing cost of swaps (fee, priority fee and
+// compute unit price), overall, per DEX and for the busiest pairs. Accepts
+// window (Go duration, default 24h, max 720h) and top (default 10, max 50).
+func (h *Handlers) FeeStats(c echo.Context) error {
+	if h.Fees == nil {
+		return h.err(c, http.StatusBadRequest, "fee analytics is not configured", nil)
+	}
+	req := FeeStatsRequest{Window: 24 * time.Hour, Top: 10}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	stats, err := h.Fees.FeeStats(ctx, time.Now().Add(-req.Window), req.Top)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to get fee stats", err)
+	}
+	return c.JSON(http.StatusOK, FeeStatsResponse{Window: req.Window.String(), FeeStats: stats})
+}
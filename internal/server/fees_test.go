@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFees struct {
+	since time.Time
+	top   int
+}
+
+func (f *fakeFees) FeeStats(_ context.Context, since time.Time, top int) (*models.FeeStats, error) {
+	f.since, f.top = since, top
+	return &models.FeeStats{
+		LandingCost: models.LandingCost{Swaps: 4, AvgFeeLamports: 80000, AvgPriorityFee: 75000},
+		ByDex:       []models.LandingCost{{Dex: "Orca", Swaps: 4, AvgFeeLamports: 80000, AvgPriorityFee: 75000}},
+		ByPair:      []models.LandingCost{{Pair: "SOL/USDC", Swaps: 4, AvgFeeLamports: 80000, AvgPriorityFee: 75000}},
+	}, nil
+}
+
+func TestFeeStats(t *testing.T) {
+	fees := &fakeFees{}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Fees: fees}, ServerConfig{})
+
+	rec := get(t, e, "/v1/fees/stats?window=1h&top=3", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp FeeStatsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "1h0m0s", resp.Window)
+	assert.EqualValues(t, 4, resp.Swaps)
+	require.Len(t, resp.ByDex, 1)
+	assert.Equal(t, "Orca", resp.ByDex[0].Dex)
+	assert.InDelta(t, 75000, resp.ByPair[0].AvgPriorityFee, 1e-9)
+	assert.Equal(t, 3, fees.top)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), fees.since, time.Minute)
+
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/fees/stats?window=1000h", "").Code)
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{})
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/fees/stats", "").Code)
+}
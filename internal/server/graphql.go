@@ -0,0 +1,523 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/labstack/echo/v4"
+)
+
+// SwapExplorer answers the ClickHouse queries behind /graphql
+// (implemented by *cache.ClickHouseStore)
+type SwapExplorer interface {
+	storage.SwapExplorer
+}
+
+// GraphQL limits
+const (
+	graphqlMaxWindow      = 720 * time.Hour // pairs, tokens, wallets and candles look back at most this far
+	graphqlMaxSwaps       = 500
+	graphqlMaxCandles     = 1000
+	graphqlMaxRanked      = 500 // pairs, tokens and top wallets
+	graphqlMaxDepth       = 10
+	graphqlFavoritePairs  = 5
+	graphqlRequestTimeout = 10 * time.Second
+)
+
+// graphqlSDL is the schema of /graphql. Field names match the JSON of the
+// REST endpoints. Top-level fields are nullable so one failing resolver
+// leaves the others' data in place.
+const graphqlSDL = `
+scalar Time
+
+"Counts, slots and base-unit amounts, which overflow Int's 32 bits"
+scalar Uint64
+
+"Unix timestamps"
+scalar Int64
+
+type Query {
+	swaps(pair: String, token: String, dex: String, wallet: String, from: String, to: String, limit: Int = 50, offset: Int = 0): [Swap!]
+	candles(pair: String!, interval: String = "1h", from: String, to: String, limit: Int = 500): [Candle!]
+	pairs(window: String = "24h", limit: Int = 50, offset: Int = 0): [Pair!]
+	tokens(window: String = "24h", limit: Int = 50, offset: Int = 0, category: String): [Token!]
+	wallet(address: String!, window: String = "168h"): WalletStats
+	top_wallets(by: String = "volume", window: String = "24h", limit: Int = 20): [WalletSummary!]
+}
+
+type Swap {
+	signature: String!
+	timestamp: Time!
+	pair: String!
+	token_in: String!
+	token_out: String!
+	amount_in: Float!
+	amount_out: Float!
+	price: Float!
+	fee: Float!
+	pool: String!
+	dex: String!
+	slot: Uint64!
+	block_time: Int64!
+	amount_in_raw: Uint64!
+	amount_out_raw: Uint64!
+	decimals_in: Int!
+	decimals_out: Int!
+	program_id: String!
+	pool_address: String!
+	wallet: String!
+	fee_lamports: Uint64!
+	priority_fee: Uint64!
+	compute_unit_price: Uint64!
+	indexed_at: Time!
+	finalized: Boolean!
+	value_usd: Float!
+}
+
+type Candle {
+	pair: String!
+	start: Time!
+	open: Float!
+	high: Float!
+	low: Float!
+	close: Float!
+	volume: Float!
+	volume_usd: Float!
+	trades: Uint64!
+}
+
+type Pair {
+	pair: String!
+	trades: Uint64!
+	volume: Float!
+	volume_usd: Float!
+	last_price: Float!
+	last_trade: Time!
+}
+
+type Token {
+	token: String!
+	name: String!
+	category: String!
+	trades: Uint64!
+	pairs: Uint64!
+	volume_usd: Float!
+	last_trade: Time!
+	"Current price from the Redis price cache"
+	price: Float
+}
+
+type WalletSummary {
+	wallet: String!
+	trades: Uint64!
+	volume_usd: Float!
+	pairs: Uint64!
+	first_trade: Time!
+	last_trade: Time!
+}
+
+type WalletStats {
+	wallet: String!
+	trades: Uint64!
+	volume_usd: Float!
+	pairs: Uint64!
+	first_trade: Time!
+	last_trade: Time!
+	avg_trade_usd: Float!
+	favorite_pairs: [PairActivity!]!
+	heatmap: [[Uint64!]!]!
+}
+
+type PairActivity {
+	pair: String!
+	trades: Uint64!
+	volume_usd: Float!
+}
+`
+
+// graphqlRequest is the body of POST /graphql
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// GraphQL runs a GraphQL query over swaps, candles, pairs, tokens and wallet
+// stats. POST takes a JSON body {query, operationName, variables}; GET takes
+// the same as query parameters (variables JSON-encoded). Requests that
+// cannot run (syntax errors, unknown fields, missing variables) get 400;
+// resolver errors come back in errors next to the rest of the data.
+func (h *Handlers) GraphQL(c echo.Context) error {
+	if h.Explorer == nil {
+		return h.err(c, http.StatusBadRequest, "GraphQL is not enabled", nil)
+	}
+	var req graphqlRequest
+	if c.Request().Method == http.MethodGet {
+		req.Query = c.QueryParam("query")
+		req.OperationName = c.QueryParam("operationName")
+		if v := c.QueryParam("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return h.err(c, http.StatusBadRequest, "variables must be a JSON object", nil)
+			}
+		}
+	} else if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return h.err(c, http.StatusBadRequest, "invalid JSON body", map[string]any{"err": err.Error()})
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return h.err(c, http.StatusBadRequest, "query is required", nil)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), graphqlRequestTimeout)
+	defer cancel()
+
+	h.graphqlOnce.Do(func() {
+		h.graphqlSchema = graphql.MustParseSchema(graphqlSDL, &graphqlResolver{h: h},
+			graphql.UseFieldResolvers(), graphql.MaxDepth(graphqlMaxDepth))
+	})
+	resp := h.graphqlSchema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	if resp.Data == nil {
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// graphqlResolver resolves the Query type over Explorer, Wallets and Cache
+type graphqlResolver struct {
+	h *Handlers
+}
+
+type swapsArgs struct {
+	Pair, Token, Dex, Wallet, From, To *string
+	Limit, Offset                      int32
+}
+
+func (r *graphqlResolver) Swaps(ctx context.Context, args swapsArgs) (*[]*swapResolver, error) {
+	f := storage.SwapFilter{
+		Pair:   normalizePair(deref(args.Pair)),
+		Token:  strings.ToUpper(strings.TrimSpace(deref(args.Token))),
+		Dex:    deref(args.Dex),
+		Wallet: deref(args.Wallet),
+		Offset: int(args.Offset),
+	}
+	var err error
+	if f.Limit, err = limitArg(args.Limit, args.Offset, graphqlMaxSwaps); err != nil {
+		return nil, err
+	}
+	if f.From, err = timeArg("from", args.From); err != nil {
+		return nil, err
+	}
+	if f.To, err = timeArg("to", args.To); err != nil {
+		return nil, err
+	}
+	swaps, err := r.h.Explorer.ListSwaps(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*swapResolver, 0, len(swaps))
+	for _, s := range swaps {
+		out = append(out, &swapResolver{*s})
+	}
+	return &out, nil
+}
+
+type candlesArgs struct {
+	Pair     string
+	Interval string
+	From, To *string
+	Limit    int32
+}
+
+func (r *graphqlResolver) Candles(ctx context.Context, args candlesArgs) (*[]*candleResolver, error) {
+	q := storage.CandleQuery{Pair: normalizePair(args.Pair)}
+	var err error
+	if q.Interval, err = time.ParseDuration(args.Interval); err != nil ||
+		q.Interval < time.Minute || q.Interval > 24*time.Hour || q.Interval%time.Second != 0 {
+		return nil, fmt.Errorf("interval must be a whole number of seconds between 1m and 24h")
+	}
+	if q.Limit, err = limitArg(args.Limit, 0, graphqlMaxCandles); err != nil {
+		return nil, err
+	}
+	if q.To, err = timeArg("to", args.To); err != nil {
+		return nil, err
+	}
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From, err = timeArg("from", args.From); err != nil {
+		return nil, err
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-time.Duration(q.Limit) * q.Interval)
+	}
+	if q.To.Sub(q.From) > graphqlMaxWindow {
+		return nil, fmt.Errorf("from and to must be at most %s apart", graphqlMaxWindow)
+	}
+	candles, err := r.h.Explorer.Candles(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*candleResolver, 0, len(candles))
+	for _, c := range candles {
+		out = append(out, &candleResolver{c})
+	}
+	return &out, nil
+}
+
+type marketArgs struct {
+	Window        string
+	Limit, Offset int32
+	Category      *string // tokens only
+}
+
+func (r *graphqlResolver) Pairs(ctx context.Context, args marketArgs) (*[]*pairResolver, error) {
+	q, err := marketQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := r.h.Explorer.Pairs(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*pairResolver, 0, len(pairs))
+	for _, p := range pairs {
+		out = append(out, &pairResolver{p})
+	}
+	return &out, nil
+}
+
+func (r *graphqlResolver) Tokens(ctx context.Context, args marketArgs) (*[]*tokenResolver, error) {
+	q, err := marketQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	q.Category = strings.ToLower(strings.TrimSpace(deref(args.Category)))
+	tokens, err := r.h.Explorer.Tokens(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*tokenResolver, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, &tokenResolver{TokenSummary: t, h: r.h})
+	}
+	return &out, nil
+}
+
+func (r *graphqlResolver) Wallet(ctx context.Context, args struct{ Address, Window string }) (*walletStatsResolver, error) {
+	if r.h.Wallets == nil {
+		return nil, fmt.Errorf("wallet analytics is not enabled")
+	}
+	w, err := windowArg(args.Window)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := r.h.Wallets.WalletStats(ctx, args.Address, time.Now().Add(-w), graphqlFavoritePairs)
+	if err != nil || stats == nil {
+		return nil, err
+	}
+	return &walletStatsResolver{walletSummaryResolver{stats.WalletSummary}, stats}, nil
+}
+
+type topWalletsArgs struct {
+	By, Window string
+	Limit      int32
+}
+
+func (r *graphqlResolver) TopWallets(ctx context.Context, args topWalletsArgs) (*[]*walletSummaryResolver, error) {
+	if r.h.Wallets == nil {
+		return nil, fmt.Errorf("wallet analytics is not enabled")
+	}
+	if args.By != storage.WalletOrderVolume && args.By != storage.WalletOrderTrades {
+		return nil, fmt.Errorf("by must be %s or %s", storage.WalletOrderVolume, storage.WalletOrderTrades)
+	}
+	w, err := windowArg(args.Window)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := limitArg(args.Limit, 0, graphqlMaxRanked)
+	if err != nil {
+		return nil, err
+	}
+	wallets, err := r.h.Wallets.TopWallets(ctx, storage.WalletQuery{Since: time.Now().Add(-w), OrderBy: args.By, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*walletSummaryResolver, 0, len(wallets))
+	for _, ws := range wallets {
+		out = append(out, &walletSummaryResolver{ws})
+	}
+	return &out, nil
+}
+
+// The resolvers below expose the models' fields, converting those whose Go
+// type has no matching GraphQL scalar
+
+type swapResolver struct{ models.SwapEvent }
+
+func (r *swapResolver) Timestamp() graphql.Time    { return graphql.Time{Time: r.SwapEvent.Timestamp} }
+func (r *swapResolver) Slot() uint64Scalar         { return uint64Scalar(r.SwapEvent.Slot) }
+func (r *swapResolver) BlockTime() int64Scalar     { return int64Scalar(r.SwapEvent.BlockTime) }
+func (r *swapResolver) AmountInRaw() uint64Scalar  { return uint64Scalar(r.SwapEvent.AmountInRaw) }
+func (r *swapResolver) AmountOutRaw() uint64Scalar { return uint64Scalar(r.SwapEvent.AmountOutRaw) }
+func (r *swapResolver) DecimalsIn() int32          { return int32(r.SwapEvent.DecimalsIn) }
+func (r *swapResolver) DecimalsOut() int32         { return int32(r.SwapEvent.DecimalsOut) }
+func (r *swapResolver) FeeLamports() uint64Scalar  { return uint64Scalar(r.SwapEvent.FeeLamports) }
+func (r *swapResolver) PriorityFee() uint64Scalar  { return uint64Scalar(r.SwapEvent.PriorityFee) }
+func (r *swapResolver) ComputeUnitPrice() uint64Scalar {
+	return uint64Scalar(r.SwapEvent.ComputeUnitPrice)
+}
+func (r *swapResolver) IndexedAt() graphql.Time { return graphql.Time{Time: r.SwapEvent.IndexedAt} }
+
+type candleResolver struct{ models.Candle }
+
+func (r *candleResolver) Start() graphql.Time  { return graphql.Time{Time: r.Candle.Start} }
+func (r *candleResolver) Trades() uint64Scalar { return uint64Scalar(r.Candle.Trades) }
+
+type pairResolver struct{ models.PairSummary }
+
+func (r *pairResolver) Trades() uint64Scalar    { return uint64Scalar(r.PairSummary.Trades) }
+func (r *pairResolver) LastTrade() graphql.Time { return graphql.Time{Time: r.PairSummary.LastTrade} }
+
+type tokenResolver struct {
+	models.TokenSummary
+	h *Handlers
+}
+
+func (r *tokenResolver) Trades() uint64Scalar    { return uint64Scalar(r.TokenSummary.Trades) }
+func (r *tokenResolver) Pairs() uint64Scalar     { return uint64Scalar(r.TokenSummary.Pairs) }
+func (r *tokenResolver) LastTrade() graphql.Time { return graphql.Time{Time: r.TokenSummary.LastTrade} }
+
+// Price is the token's current price from the Redis price cache
+func (r *tokenResolver) Price(ctx context.Context) (*float64, error) {
+	if r.h.Cache == nil {
+		return nil, nil
+	}
+	price, err := r.h.Cache.GetPrice(ctx, r.Token)
+	if err != nil || price == nil {
+		return nil, err
+	}
+	return &price.Price, nil
+}
+
+type walletSummaryResolver struct{ models.WalletSummary }
+
+func (r *walletSummaryResolver) Trades() uint64Scalar { return uint64Scalar(r.WalletSummary.Trades) }
+func (r *walletSummaryResolver) Pairs() uint64Scalar  { return uint64Scalar(r.WalletSummary.Pairs) }
+func (r *walletSummaryResolver) FirstTrade() graphql.Time {
+	return graphql.Time{Time: r.WalletSummary.FirstTrade}
+}
+func (r *walletSummaryResolver) LastTrade() graphql.Time {
+	return graphql.Time{Time: r.WalletSummary.LastTrade}
+}
+
+type walletStatsResolver struct {
+	walletSummaryResolver
+	stats *models.WalletStats
+}
+
+func (r *walletStatsResolver) AvgTradeUSD() float64 { return r.stats.AvgTradeUSD }
+
+func (r *walletStatsResolver) FavoritePairs() []*pairActivityResolver {
+	out := make([]*pairActivityResolver, 0, len(r.stats.FavoritePairs))
+	for _, p := range r.stats.FavoritePairs {
+		out = append(out, &pairActivityResolver{p})
+	}
+	return out
+}
+
+func (r *walletStatsResolver) Heatmap() [][]uint64Scalar {
+	out := make([][]uint64Scalar, len(r.stats.Heatmap))
+	for day, hours := range r.stats.Heatmap {
+		out[day] = make([]uint64Scalar, len(hours))
+		for hour, n := range hours {
+			out[day][hour] = uint64Scalar(n)
+		}
+	}
+	return out
+}
+
+type pairActivityResolver struct{ models.PairActivity }
+
+func (r *pairActivityResolver) Trades() uint64Scalar { return uint64Scalar(r.PairActivity.Trades) }
+
+// uint64Scalar is the Uint64 scalar, written as a JSON number
+type uint64Scalar uint64
+
+func (uint64Scalar) ImplementsGraphQLType(name string) bool { return name == "Uint64" }
+
+func (*uint64Scalar) UnmarshalGraphQL(any) error {
+	return fmt.Errorf("Uint64 is an output type only")
+}
+
+// int64Scalar is the Int64 scalar, written as a JSON number
+type int64Scalar int64
+
+func (int64Scalar) ImplementsGraphQLType(name string) bool { return name == "Int64" }
+
+func (*int64Scalar) UnmarshalGraphQL(any) error {
+	return fmt.Errorf("Int64 is an output type only")
+}
+
+// normalizePair upper-cases a pair argument
+func normalizePair(pair string) string {
+	return strings.ToUpper(strings.TrimSpace(pair))
+}
+
+// deref returns the value of an optional string argument, "" when absent
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// limitArg checks the limit argument against 1..max and offset against 0
+func limitArg(limit, offset int32, max int) (int, error) {
+	if limit < 1 || int(limit) > max {
+		return 0, fmt.Errorf("limit must be between 1 and %d", max)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("offset must not be negative")
+	}
+	return int(limit), nil
+}
+
+// timeArg parses an optional RFC 3339 argument
+func timeArg(name string, s *string) (time.Time, error) {
+	if deref(s) == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, *s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 time", name)
+	}
+	return t, nil
+}
+
+// windowArg parses the window argument (Go duration, 1m to graphqlMaxWindow)
+func windowArg(window string) (time.Duration, error) {
+	w, err := time.ParseDuration(window)
+	if err != nil || w < time.Minute || w > graphqlMaxWindow {
+		return 0, fmt.Errorf("window must be a duration between 1m and %s", graphqlMaxWindow)
+	}
+	return w, nil
+}
+
+// marketQuery reads the window and paging of pairs and tokens
+func marketQuery(args marketArgs) (storage.MarketQuery, error) {
+	w, err := windowArg(args.Window)
+	if err != nil {
+		return storage.MarketQuery{}, err
+	}
+	limit, err := limitArg(args.Limit, args.Offset, graphqlMaxRanked)
+	if err != nil {
+		return storage.MarketQuery{}, err
+	}
+	return storage.MarketQuery{Since: time.Now().Add(-w), Limit: limit, Offset: int(args.Offset)}, nil
+}
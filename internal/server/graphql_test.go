@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExplorer struct {
+	filter storage.SwapFilter
+	candle storage.CandleQuery
+	market storage.MarketQuery
+}
+
+func (f *fakeExplorer) ListSwaps(_ context.Context, q storage.SwapFilter) ([]*models.SwapEvent, error) {
+	f.filter = q
+	return []*models.SwapEvent{{Signature: "sig1", Pair: "SOL/USDC", AmountIn: 2, Wallet: testWallet, Slot: 1 << 40}}, nil
+}
+
+func (f *fakeExplorer) Candles(_ context.Context, q storage.CandleQuery) ([]models.Candle, error) {
+	f.candle = q
+	return []models.Candle{{Pair: q.Pair, Open: 150, Close: 152, Trades: 4}}, nil
+}
+
+func (f *fakeExplorer) Pairs(context.Context, storage.MarketQuery) ([]models.PairSummary, error) {
+	return []models.PairSummary{{Pair: "SOL/USDC", Trades: 10}}, nil
+}
+
+func (f *fakeExplorer) Tokens(_ context.Context, q storage.MarketQuery) ([]models.TokenSummary, error) {
+	f.market = q
+	return []models.TokenSummary{{Token: "SOL", Name: "Wrapped SOL", Category: "native", Trades: 10}}, nil
+}
+
+func postGraphQL(e *echo.Echo, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGraphQL(t *testing.T) {
+	explorer := &fakeExplorer{}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Explorer: explorer, Wallets: &fakeWallets{}}, ServerConfig{})
+
+	rec := postGraphQL(e, `{
+		"query": "query($pair: String!) { swaps(pair: $pair, limit: 5) { signature amount_in } candles(pair: $pair, interval: \"15m\") { open close trades } wallet(address: \"`+testWallet+`\") { trades favorite_pairs { pair } } }",
+		"variables": {"pair": "sol/usdc"}
+	}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"data": {
+		"swaps": [{"signature": "sig1", "amount_in": 2}],
+		"candles": [{"open": 150, "close": 152, "trades": 4}],
+		"wallet": {"trades": 3, "favorite_pairs": [{"pair": "SOL/USDC"}]}
+	}}`, rec.Body.String())
+	assert.Equal(t, storage.SwapFilter{Pair: "SOL/USDC", Limit: 5}, explorer.filter)
+	assert.Equal(t, 15*time.Minute, explorer.candle.Interval)
+	assert.Equal(t, 500*15*time.Minute, explorer.candle.To.Sub(explorer.candle.From))
+
+	// GET, with a resolver error next to the other fields
+	q := url.Values{"query": {`{ pairs { pair } tokens(limit: 0) { token price } }`}}
+	rec = get(t, e, "/graphql?"+q.Encode(), "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Data   map[string]json.RawMessage `json:"data"`
+		Errors []struct{ Message string } `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.JSONEq(t, `[{"pair": "SOL/USDC"}]`, string(resp.Data["pairs"]))
+	assert.JSONEq(t, `null`, string(resp.Data["tokens"]))
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "limit must be between 1 and 500", resp.Errors[0].Message)
+
+	rec = postGraphQL(e, `{"query": "{ tokens(category: \" Native \") { token name category } }"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"data": {"tokens": [{"token": "SOL", "name": "Wrapped SOL", "category": "native"}]}}`, rec.Body.String())
+	assert.Equal(t, "native", explorer.market.Category)
+
+	// aliases, fragments and counts beyond 32 bits
+	rec = postGraphQL(e, `{"query": "{ recent: swaps(limit: 1) { ...s } } fragment s on Swap { signature slot }"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"data": {"recent": [{"signature": "sig1", "slot": 1099511627776}]}}`, rec.Body.String())
+
+	rec = postGraphQL(e, `{"query": "{ swaps { nope } }"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `Cannot query field \"nope\" on type \"Swap\".`)
+	assert.Equal(t, http.StatusBadRequest, postGraphQL(e, `{"query": ""}`).Code)
+
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{})
+	assert.Equal(t, http.StatusBadRequest, postGraphQL(e, `{"query": "{ pairs { pair } }"}`).Code)
+}
@@ -4,38 +4,165 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/ai"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/jupiter"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/netguard"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 )
 
+// pairRe matches a trading pair such as SOL/USDC, SOL-USDC LP/USDC or a pair
+// involving an unknown mint address
+var pairRe = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 ._-]{0,63}/[A-Z0-9][A-Z0-9 ._-]{0,63}$`)
+
 // Handlers contains all dependencies for API endpoint handlers
 type Handlers struct {
-	Cache        storage.SwapCache // Redis-backed swap data cache
-	Flags        *flags.Store      // Redis-backed feature flags store
-	AI           *ai.Agent         // AI agent for natural language queries
-	AIBaseConfig ai.AgentConfig    // Base configuration for AI agents
-	DevMode      bool              // Enable detailed error responses in development
-	Logger       *logrus.Logger    // Structured logger
-	Jupiter      *jupiter.Client   // Jupiter Quote API client (optional)
+	Cache        storage.SwapCache   // Redis-backed swap data cache
+	Flags        *flags.Store        // Redis-backed feature flags store (optional)
+	AI           *ai.Agent           // AI agent for natural language queries
+	AIBaseConfig ai.AgentConfig      // Base configuration for AI agents
+	DevMode      bool                // Enable detailed error responses in development
+	Logger       *logrus.Logger      // Structured logger
+	Jupiter      *jupiter.Client     // Jupiter Quote API client (optional)
+	Quotes       QuoteCache          // Short-lived cache of Jupiter quotes (optional)
+	Routes       RouteChecker        // Risk verdict on /v1/quote routes (optional)
+	Reloads      ReloadRequester     // Broadcasts config reload requests (optional)
+	Indexers     IndexerStatusLister // Status reports of running indexers (optional)
+	Swaps        SwapExecutor        // Swap engine behind POST /v1/swap/execute (optional)
+	Idempotency  IdempotencyStore    // Responses replayed for retried Idempotency-Keys (optional)
+	Pools        PoolManager         // Swap engine pool registry behind /v1/pools (optional)
+	Risk         RiskConfigurator    // Swap engine risk limits behind /v1/swap/risk-config (optional)
+	Responses    ResponseCache       // Micro-cache for hot read endpoints (optional, see ServerConfig.ResponseCacheTTL)
+	Wallets      WalletAnalytics     // ClickHouse aggregates behind /v1/wallets (optional)
+	Arb          ArbReader           // Opportunities found by the arbitrage detector (optional)
+	Markets      MarketReader        // Per-venue token prices behind /v1/prices/:token/markets (optional)
+	Anomalies    AnomalyReader       // Spikes found by the volume anomaly detector (optional)
+	MEV          MEVAnalytics        // Sandwich attack aggregates behind /v1/mev/stats (optional)
+	Fees         FeeAnalytics        // Landing cost aggregates behind /v1/fees/stats (optional)
+	Explorer     SwapExplorer        // ClickHouse queries behind /graphql (optional)
+	Listings     MarketListings      // Pair directory and DEX comparison behind /v1/pairs and /v1/dexes (optional)
+	Programs     ProgramOverrides    // Program addresses indexers poll on top of PROGRAM_ADDRESSES (optional)
+	SwapStore    SwapLookup          // Stored swaps behind /v1/swaps/:signature (optional; without it only recent swaps are found)
+	Chain        SignatureChecker    // Explains signatures /v1/swaps/:signature did not find (optional)
+	Simulator    TxSimulator         // Simulates client-built transactions behind /v1/tx/simulate (optional)
+	Relay        TxRelay             // Sends client-signed transactions behind /v1/tx/send (optional)
+	Exports      SwapExporter        // Stored swaps streamed by /v1/export/swaps (optional)
+	AIRate       AIRateLimiter       // Per-client rate on /v1/ai shared by replicas (optional; in-memory per process without it)
+	AIBudget     AIBudget            // Monthly LLM spend per client on /v1/ai (optional)
+
+	Subscriptions storage.SubscriptionStore // Pair and token subscriptions per API key behind /v1/subscriptions (optional)
+
+	PriceStaleAfter time.Duration // Prices older than this are flagged stale (default constants.PriceStaleAfter)
+	MaxSlotLag      int64         // /readyz fails when an indexer lags more slots than this (0: not checked)
+	MaxRecentSwaps  int           // Length of the recent swaps list searched by signature (default constants.MaxRecentSwaps)
+	ExportMaxRows   int           // Rows per /v1/export/swaps request at most (default constants.ExportMaxRows)
+
+	SubscriptionsMax int             // Subscriptions per API key at most (default constants.SubscriptionsMaxPerKey)
+	Webhooks         netguard.Policy // Where webhook_url of /v1/tx/send and /v1/subscriptions may point (default: any public host)
+
+	aiMu  sync.RWMutex // guards AI and AIBaseConfig once the server is running
+	drain *drainer     // in-flight requests and streams, set by RegisterRoutes
+
+	webhookOnce   sync.Once    // builds webhookClient on the first relayed transaction with a webhook
+	webhookClient *http.Client // posts TxWebhooks within the Webhooks policy
+
+	graphqlOnce   sync.Once       // builds graphqlSchema on the first /graphql request
+	graphqlSchema *graphql.Schema // graphqlSDL resolved over Explorer, Wallets and Cache
+}
+
+// SetAI swaps the AI agent and its base config (e.g. after a credential
+// rotation) and returns the previous agent so the caller can close it
+func (h *Handlers) SetAI(agent *ai.Agent, base ai.AgentConfig) *ai.Agent {
+	h.aiMu.Lock()
+	defer h.aiMu.Unlock()
+	prev := h.AI
+	h.AI = agent
+	h.AIBaseConfig = base
+	return prev
+}
+
+// aiAgent returns the current AI agent and base config
+func (h *Handlers) aiAgent() (*ai.Agent, ai.AgentConfig) {
+	h.aiMu.RLock()
+	defer h.aiMu.RUnlock()
+	return h.AI, h.AIBaseConfig
+}
+
+// ReloadRequester asks running services to reload their configuration
+type ReloadRequester interface {
+	RequestReload(ctx context.Context) (int64, error)
+}
+
+// IndexerStatusLister returns the latest status report of every live indexer replica
+type IndexerStatusLister interface {
+	ListIndexerStatus(ctx context.Context) ([]models.IndexerStatus, error)
+}
+
+// ProgramOverrides edits the program addresses added and removed at runtime
+// (implemented by *cache.RedisCache)
+type ProgramOverrides interface {
+	GetProgramOverrides(ctx context.Context) (*models.ProgramOverrides, error)
+	AddProgram(ctx context.Context, addr string) error
+	RemoveProgram(ctx context.Context, addr string) error
 }
 
 // err returns a standardized JSON error response
 // In dev mode, includes additional error details for debugging
 func (h *Handlers) err(c echo.Context, code int, msg string, details any) error {
+	return h.writeErr(c, h.errResponse(code, msg, details))
+}
+
+// fail answers err with the status of its apperr code (404 not found, 503
+// upstream unavailable, ...), or fallback for an error without one
+func (h *Handlers) fail(c echo.Context, fallback int, msg string, err error) error {
+	resp := h.errResponse(fallback, msg, map[string]any{"err": err.Error()})
+	if code := apperr.CodeOf(err); code != "" {
+		resp.Code, resp.Category = code.HTTPStatus(), string(code)
+	}
+	return h.writeErr(c, resp)
+}
+
+// writeErr writes an error response; a server-side failure after the route
+// timeout expired is reported as 408
+func (h *Handlers) writeErr(c echo.Context, resp ErrorResponse) error {
+	if resp.Code >= 500 && routeTimedOut(c) {
+		resp = h.errResponse(http.StatusRequestTimeout, "request timed out", nil)
+	}
+	return c.JSON(resp.Code, resp)
+}
+
+// log returns the handler logger with the request's log fields (request id)
+func (h *Handlers) log(c echo.Context) *logrus.Entry {
+	return logging.Entry(c.Request().Context(), h.Logger)
+}
+
+// errResponse builds the body written by err
+func (h *Handlers) errResponse(code int, msg string, details any) ErrorResponse {
 	resp := ErrorResponse{Error: msg, Code: code}
+	if code < 500 {
+		// 400, 404 and 429 say what went wrong; server-side failures get a
+		// code from their error (see fail)
+		resp.Category = string(apperr.ForStatus(code))
+	}
 	if h.DevMode && details != nil {
 		resp.Details = details
 	}
-	return c.JSON(code, resp)
+	return resp
 }
 
 // withTimeout creates a context with timeout, defaulting to 10 seconds if duration <= 0
@@ -46,7 +173,8 @@ func (h *Handlers) withTimeout(ctx context.Context, d time.Duration) (context.Co
 	return context.WithTimeout(ctx, d)
 }
 
-// Health returns a simple health check endpoint
+// Health returns a simple health check endpoint (kept for clients of
+// /v1/health; probes should use /healthz and /readyz, which need no API key)
 func (h *Handlers) Health(c echo.Context) error {
 	return c.JSON(http.StatusOK, HealthResponse{OK: true})
 }
@@ -56,73 +184,162 @@ func (h *Handlers) Echo(c echo.Context) error {
 	var v any
 	dec := json.NewDecoder(c.Request().Body)
 	if err := dec.Decode(&v); err != nil {
-		return h.err(c, http.StatusBadRequest, "invalid json", nil)
+		return h.invalidField(c, "body", "json", "invalid json")
 	}
 	return c.JSON(http.StatusOK, v)
 }
 
 // RecentSwaps returns the most recent swap events with optional limit parameter
-// Accepts limit query parameter (default: 100, range: 1-200)
+// Accepts limit query parameter (default: 100, range: 1-200) and pair (e.g. SOL/USDC)
 func (h *Handlers) RecentSwaps(c echo.Context) error {
-	limitStr := c.QueryParam("limit")
-	limit := 100
-	if limitStr != "" {
-		n, err := strconv.Atoi(limitStr)
-		if err != nil {
-			return h.err(c, http.StatusBadRequest, "invalid limit", map[string]any{"limit": "must be an integer"})
-		}
-		limit = n
-	}
-	if limit < 1 || limit > 200 {
-		return h.err(c, http.StatusBadRequest, "invalid limit", map[string]any{"limit": "min 1 max 200"})
+	req := RecentSwapsRequest{Limit: 100}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
 	}
+	pair := strings.ToUpper(strings.TrimSpace(req.Pair))
+	limit := req.Limit
 
 	ctx, cancel := h.withTimeout(c.Request().Context(), 5*time.Second)
 	defer cancel()
 
-	items, err := h.Cache.GetRecentSwaps(ctx, int64(limit))
+	fetch := func(n int) ([]*models.SwapEvent, error) {
+		if pair != "" {
+			return h.Cache.GetRecentSwapsByPair(ctx, pair, int64(n))
+		}
+		return h.Cache.GetRecentSwaps(ctx, int64(n))
+	}
+	// The list only changes when a newer swap lands, so the newest signature
+	// versions it; pollers revalidating with If-None-Match cost a single-item read
+	etag := func(items []*models.SwapEvent) string {
+		head := "empty"
+		if len(items) > 0 && items[0] != nil {
+			head = items[0].Signature
+		}
+		return weakETag(pair, strconv.Itoa(limit), head)
+	}
+
+	if c.Request().Header.Get("If-None-Match") != "" {
+		latest, err := fetch(1)
+		if err != nil {
+			return h.fail(c, http.StatusInternalServerError, "failed to get swaps", err)
+		}
+		if notModified(c, etag(latest)) {
+			return nil
+		}
+	}
+
+	items, err := fetch(limit)
 	if err != nil {
-		return h.err(c, http.StatusInternalServerError, "failed to get swaps", nil)
+		return h.fail(c, http.StatusInternalServerError, "failed to get swaps", err)
 	}
+	setETag(c, etag(items))
 	return c.JSON(http.StatusOK, map[string]any{"items": items})
 }
 
-// Price returns the current price for a given token symbol
+// Price returns the last price for a given token symbol with its age
 // Token parameter is case-insensitive and will be normalized to uppercase
+// Unknown and expired tokens return price 0 with stale=true
 func (h *Handlers) Price(c echo.Context) error {
-	token := strings.TrimSpace(c.Param("token"))
-	if token == "" {
-		return h.err(c, http.StatusBadRequest, "invalid token", nil)
+	var req TokenRequest
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
 	}
-	token = strings.ToUpper(token)
+	token := strings.ToUpper(strings.TrimSpace(req.Token))
 
 	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
 	defer cancel()
 
 	price, err := h.Cache.GetPrice(ctx, token)
 	if err != nil {
-		return h.err(c, http.StatusInternalServerError, "failed to get price", nil)
+		return h.fail(c, http.StatusInternalServerError, "failed to get price", err)
+	}
+	if price == nil {
+		// never seen, or expired after PRICE_TTL without a new swap
+		if notModified(c, weakETag(token, "none")) {
+			return nil
+		}
+		return c.JSON(http.StatusOK, PriceResponse{Token: token, Stale: true})
+	}
+
+	staleAfter := h.PriceStaleAfter
+	if staleAfter <= 0 {
+		staleAfter = constants.PriceStaleAfter
+	}
+	resp := PriceResponse{Token: token, Price: price.Price, Stale: price.StaleAt(time.Now(), staleAfter)}
+	// updated_at versions the price; the stale flag is part of the body, so its flip is too
+	if notModified(c, weakETag(token, strconv.FormatInt(price.UpdatedAt.UnixNano(), 10), strconv.FormatBool(resp.Stale))) {
+		return nil
 	}
-	return c.JSON(http.StatusOK, PriceResponse{Token: token, Price: price})
+	if !price.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &price.UpdatedAt
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// PriceHistory returns the rolling price history of a token for sparklines
+// Accepts window query parameter (Go duration, default: 15m, max: 24h); Redis
+// only keeps PRICE_HISTORY_WINDOW of history, so longer windows return what exists
+func (h *Handlers) PriceHistory(c echo.Context) error {
+	req := PriceHistoryRequest{Window: 15 * time.Minute}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+	token := strings.ToUpper(strings.TrimSpace(req.Token))
+	window := req.Window
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	points, err := h.Cache.GetPriceHistory(ctx, token, time.Now().Add(-window))
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to get price history", err)
+	}
+	return c.JSON(http.StatusOK, PriceHistoryResponse{Token: token, Window: window.String(), Points: points})
 }
 
-// FlagsUpsert creates or updates a feature flag with the given key and value
-// Validates key format and returns the created/updated flag
+// requirePrimaryKey answers with 403 requests authenticated by one of the
+// further API_KEYS: only API_KEY may trade, relay transactions, edit flags
+// and use the admin routes
+func (h *Handlers) requirePrimaryKey(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if scoped, _ := c.Get(apiKeyScopedContextKey).(bool); scoped {
+			return h.err(c, http.StatusForbidden, "this API key may not use this endpoint", nil)
+		}
+		return next(c)
+	}
+}
+
+// requireFlags answers the flag routes with 400 when no flags store is
+// configured, e.g. in standalone mode
+func (h *Handlers) requireFlags(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if h.Flags == nil {
+			return h.err(c, http.StatusBadRequest, "feature flags are not configured", nil)
+		}
+		return next(c)
+	}
+}
+
+// FlagsUpsert creates or updates a feature flag with the given key and typed value
+// Validates key format and value type and returns the created/updated flag
 func (h *Handlers) FlagsUpsert(c echo.Context) error {
 	var req FlagUpsertRequest
-	if err := c.Bind(&req); err != nil {
-		return h.err(c, http.StatusBadRequest, "invalid json", nil)
-	}
-	if err := flags.ValidateKey(req.Key); err != nil {
-		return h.err(c, http.StatusBadRequest, "invalid key", map[string]any{"key": "invalid format"})
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
 	}
+	typ, _ := flags.ParseType(req.Type) // checked by the flag_type rule
 
 	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
 	defer cancel()
 
-	out, err := h.Flags.Upsert(ctx, req.Key, req.Value)
+	sched, _, err := flagSchedule(req.FlagScheduleRequest, time.Now())
 	if err != nil {
-		return h.err(c, http.StatusInternalServerError, "failed to upsert flag", nil)
+		return h.invalidField(c, "schedule", "schedule", err.Error())
+	}
+
+	out, err := h.Flags.SetScheduled(flags.WithActor(ctx, flagActor(c)), req.Key, typ, req.Value, sched)
+	if err != nil {
+		return h.flagWriteErr(c, err, "failed to upsert flag")
 	}
 	return c.JSON(http.StatusOK, out)
 }
@@ -130,32 +347,98 @@ func (h *Handlers) FlagsUpsert(c echo.Context) error {
 // FlagsUpdate updates an existing feature flag with the given key
 // Validates key format and returns the updated flag
 func (h *Handlers) FlagsUpdate(c echo.Context) error {
-	key := c.Param("key")
-	if err := flags.ValidateKey(key); err != nil {
-		return h.err(c, http.StatusBadRequest, "invalid key", map[string]any{"key": "invalid format"})
-	}
 	var req FlagUpdateRequest
-	if err := c.Bind(&req); err != nil {
-		return h.err(c, http.StatusBadRequest, "invalid json", nil)
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
 	}
+	key := req.Key
+	typ, _ := flags.ParseType(req.Type) // checked by the flag_type rule
 
 	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
 	defer cancel()
 
-	out, err := h.Flags.Upsert(ctx, key, req.Value)
+	sched, hasSched, err := flagSchedule(req.FlagScheduleRequest, time.Now())
+	if err != nil {
+		return h.invalidField(c, "schedule", "schedule", err.Error())
+	}
+
+	// keep the existing type and schedule unless the request changes them
+	if cur, err := h.Flags.Get(ctx, key); err == nil {
+		if typ == "" {
+			typ = cur.Type
+		}
+		if !hasSched {
+			sched = cur.Schedule()
+		}
+	}
+
+	out, err := h.Flags.SetScheduled(flags.WithActor(ctx, flagActor(c)), key, typ, req.Value, sched)
 	if err != nil {
-		return h.err(c, http.StatusInternalServerError, "failed to update flag", nil)
+		return h.flagWriteErr(c, err, "failed to update flag")
 	}
 	return c.JSON(http.StatusOK, out)
 }
 
+// flagSchedule converts request fields into a flags.Schedule; ok is false
+// when no schedule field was set
+func flagSchedule(r FlagScheduleRequest, now time.Time) (sched flags.Schedule, ok bool, err error) {
+	if r.TTL == "" && r.ActiveFrom == nil && r.ExpiresAt == nil {
+		return sched, false, nil
+	}
+	if r.TTL != "" && r.ExpiresAt != nil {
+		return sched, true, fmt.Errorf("set ttl or expires_at, not both")
+	}
+
+	sched.ActiveFrom = r.ActiveFrom
+	sched.ExpiresAt = r.ExpiresAt
+	if r.TTL != "" {
+		ttl, err := time.ParseDuration(r.TTL)
+		if err != nil || ttl <= 0 {
+			return sched, true, fmt.Errorf("ttl must be a positive duration like 30m or 2h")
+		}
+		// a TTL counts from activation when the flag is scheduled for later
+		start := now
+		if r.ActiveFrom != nil && r.ActiveFrom.After(now) {
+			start = *r.ActiveFrom
+		}
+		exp := start.Add(ttl)
+		sched.ExpiresAt = &exp
+	}
+	return sched, true, nil
+}
+
+// flagActor identifies the caller of a flag write for the audit history
+func flagActor(c echo.Context) flags.Actor {
+	a := flags.Actor{
+		Name: strings.TrimSpace(c.Request().Header.Get("X-Actor")),
+		IP:   c.RealIP(),
+	}
+	if id, ok := c.Get(apiKeyIDContextKey).(string); ok {
+		a.APIKey = id
+	}
+	return a
+}
+
+// flagWriteErr maps store errors from a flag write onto responses
+func (h *Handlers) flagWriteErr(c echo.Context, err error, msg string) error {
+	switch {
+	case errors.Is(err, flags.ErrInvalidType):
+		return h.invalidField(c, "value", "type", err.Error())
+	case errors.Is(err, flags.ErrSchedule):
+		return h.invalidField(c, "schedule", "schedule", err.Error())
+	default:
+		return h.err(c, http.StatusInternalServerError, msg, nil)
+	}
+}
+
 // FlagsGet retrieves a feature flag by its key
 // Returns 404 if flag doesn't exist
 func (h *Handlers) FlagsGet(c echo.Context) error {
-	key := c.Param("key")
-	if err := flags.ValidateKey(key); err != nil {
-		return h.err(c, http.StatusBadRequest, "invalid key", map[string]any{"key": "invalid format"})
+	var req FlagKeyRequest
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
 	}
+	key := req.Key
 
 	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
 	defer cancel()
@@ -170,62 +453,101 @@ func (h *Handlers) FlagsGet(c echo.Context) error {
 	return c.JSON(http.StatusOK, out)
 }
 
-// FlagsList returns all feature flags in the system
+// FlagsList returns one page of feature flags in key order
+// Accepts prefix (namespace, e.g. "engine."), limit (default: 100, range: 1-500)
+// and cursor (next_cursor of the previous page) query parameters
 func (h *Handlers) FlagsList(c echo.Context) error {
+	req := FlagsListRequest{Limit: flags.DefaultPageSize}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+	opts := flags.ListOptions{Prefix: req.Prefix, After: req.Cursor, Limit: req.Limit}
+
 	ctx, cancel := h.withTimeout(c.Request().Context(), 5*time.Second)
 	defer cancel()
 
-	items, err := h.Flags.List(ctx)
+	page, err := h.Flags.ListPage(ctx, opts)
 	if err != nil {
 		return h.err(c, http.StatusInternalServerError, "failed to list flags", nil)
 	}
-	return c.JSON(http.StatusOK, map[string]any{"items": items})
+	return c.JSON(http.StatusOK, page)
 }
 
 // FlagsDelete removes a feature flag by its key
 // Returns 204 No Content on successful deletion
 func (h *Handlers) FlagsDelete(c echo.Context) error {
-	key := c.Param("key")
-	if err := flags.ValidateKey(key); err != nil {
-		return h.err(c, http.StatusBadRequest, "invalid key", map[string]any{"key": "invalid format"})
+	var req FlagKeyRequest
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
 	}
+	key := req.Key
 
 	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
 	defer cancel()
 
-	if err := h.Flags.Delete(ctx, key); err != nil {
+	if err := h.Flags.Delete(flags.WithActor(ctx, flagActor(c)), key); err != nil {
 		return h.err(c, http.StatusInternalServerError, "failed to delete flag", nil)
 	}
 	return c.NoContent(http.StatusNoContent)
 }
 
+// FlagsHistory returns the recorded changes to a flag, newest first
+// Accepts limit query parameter (default: 50, max: FLAGS_HISTORY_LIMIT); deleted flags keep their history
+func (h *Handlers) FlagsHistory(c echo.Context) error {
+	maxLimit := h.Flags.HistoryLimit()
+	req := FlagsHistoryRequest{Limit: min(50, maxLimit)}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+	// the upper bound is FLAGS_HISTORY_LIMIT, so it cannot live in the tag
+	if req.Limit > maxLimit {
+		return h.invalidField(c, "limit", "max", fmt.Sprintf("max %d", maxLimit))
+	}
+	key, limit := req.Key, req.Limit
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	items, err := h.Flags.History(ctx, key, limit)
+	if err != nil {
+		return h.err(c, http.StatusInternalServerError, "failed to get flag history", nil)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
 // AIAsk processes natural language questions about swap data using AI
+// Questions are classified first: price questions are answered from Redis,
+// quotes, swaps and unrelated questions are refused with 422, the rest go
+// through SQL generation
+// sql_only returns the SQL without running it; dry_run adds ClickHouse's
+// EXPLAIN plan and estimate of what it would read
 // Supports optional model override for one-off requests
 // Returns SQL query and answer with execution time
 func (h *Handlers) AIAsk(c echo.Context) error {
-	if h.AI == nil {
+	agent, base := h.aiAgent()
+	if agent == nil {
 		return h.err(c, http.StatusBadRequest, "ai is not configured", nil)
 	}
 
 	var req AIAskRequest
-	if err := c.Bind(&req); err != nil {
-		return h.err(c, http.StatusBadRequest, "invalid json", nil)
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
 	}
 	req.Question = strings.TrimSpace(req.Question)
-	if req.Question == "" {
-		return h.err(c, http.StatusBadRequest, "question is required", map[string]any{"question": "required"})
-	}
 
 	ctx, cancel := h.withTimeout(c.Request().Context(), 45*time.Second)
 	defer cancel()
 
 	start := time.Now()
+	client := aiClient(c)
+	if h.aiBudgetExhausted(c, client, start) {
+		return h.err(c, http.StatusPaymentRequired, "monthly ai budget exhausted", nil)
+	}
 
 	// Use default AI agent or create temporary one with custom model
-	agent := h.AI
 	var tmp *ai.Agent
 	if m := strings.TrimSpace(req.Model); m != "" {
-		cfg := h.AIBaseConfig
+		cfg := base
 		cfg.Model = m
 		a, err := ai.NewAgent(ctx, cfg)
 		if err != nil {
@@ -238,10 +560,140 @@ func (h *Handlers) AIAsk(c echo.Context) error {
 		}()
 	}
 
-	res, err := agent.Ask(ctx, req.Question)
+	// A cheap model routes the question first; only analytics needs SQL.
+	// Callers asking for the SQL itself skip routing.
+	var usage ai.Usage
+	if !req.SQLOnly && !req.DryRun {
+		cls, err := agent.Classify(ctx, req.Question)
+		if err != nil && h.Logger != nil {
+			h.log(c).WithError(err).Warn("ai question classification failed, answering with sql")
+		}
+		usage = cls.Usage
+		if resp, refusal := h.answerByIntent(ctx, cls); resp != nil || refusal != nil {
+			h.chargeAI(c, client, usage, start)
+			if refusal != nil {
+				return c.JSON(refusal.Code, refusal)
+			}
+			resp.TookMs = time.Since(start).Milliseconds()
+			return c.JSON(http.StatusOK, resp)
+		}
+	}
+
+	res, err := agent.Ask(ctx, req.Question, ai.AskOptions{AllTime: req.AllTime, SQLOnly: req.SQLOnly, DryRun: req.DryRun})
+	if res != nil {
+		usage = usage.Add(res.Usage)
+	}
+	h.chargeAI(c, client, usage, start)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "ai ask failed", err)
+	}
+
+	resp := AIAskResponse{Intent: string(ai.IntentAnalytics), SQL: res.SQL, Answer: res.Answer, TookMs: time.Since(start).Milliseconds()}
+	if res.Lookback > 0 {
+		resp.Lookback = res.Lookback.String()
+	}
+	if e := res.Estimate; e != nil {
+		resp.Estimate = &AIEstimate{Rows: e.Rows, Marks: e.Marks, Parts: e.Parts, Plan: e.Plan}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ConfigReload asks running services (indexer, etc.) to reload their config
+// Returns how many services received the request
+func (h *Handlers) ConfigReload(c echo.Context) error {
+	if h.Reloads == nil {
+		return h.err(c, http.StatusBadRequest, "config reload is not configured", nil)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	n, err := h.Reloads.RequestReload(ctx)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to request config reload", err)
+	}
+
+	h.log(c).WithField("receivers", n).Info("config reload requested")
+	return c.JSON(http.StatusOK, ConfigReloadResponse{OK: true, Receivers: n})
+}
+
+// IndexerStatus summarises every running indexer replica: throughput, parse
+// success per DEX, processing latency and slot lag
+func (h *Handlers) IndexerStatus(c echo.Context) error {
+	if h.Indexers == nil {
+		return h.err(c, http.StatusBadRequest, "indexer status is not configured", nil)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	instances, err := h.Indexers.ListIndexerStatus(ctx)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to read indexer status", err)
+	}
+
+	return c.JSON(http.StatusOK, IndexerStatusResponse{Instances: instances, Count: len(instances)})
+}
+
+// IndexerPrograms lists the program addresses added and removed through the
+// admin API; indexers poll PROGRAM_ADDRESSES plus added minus removed
+func (h *Handlers) IndexerPrograms(c echo.Context) error {
+	if h.Programs == nil {
+		return h.err(c, http.StatusBadRequest, "program overrides are not configured", nil)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	o, err := h.Programs.GetProgramOverrides(ctx)
 	if err != nil {
-		return h.err(c, http.StatusInternalServerError, "ai ask failed", map[string]any{"err": err.Error()})
+		return h.fail(c, http.StatusInternalServerError, "failed to read program overrides", err)
+	}
+	return c.JSON(http.StatusOK, ProgramOverridesResponse{Added: o.Added, Removed: o.Removed})
+}
+
+// IndexerProgramAdd starts polling a program on every indexer
+func (h *Handlers) IndexerProgramAdd(c echo.Context) error {
+	return h.editPrograms(c, "add", func(ctx context.Context, addr string) error { return h.Programs.AddProgram(ctx, addr) })
+}
+
+// IndexerProgramRemove stops polling a program on every indexer, including
+// one listed in PROGRAM_ADDRESSES
+func (h *Handlers) IndexerProgramRemove(c echo.Context) error {
+	return h.editPrograms(c, "remove", func(ctx context.Context, addr string) error { return h.Programs.RemoveProgram(ctx, addr) })
+}
+
+// editPrograms applies one override and asks running indexers to reload
+func (h *Handlers) editPrograms(c echo.Context, action string, edit func(ctx context.Context, addr string) error) error {
+	if h.Programs == nil {
+		return h.err(c, http.StatusBadRequest, "program overrides are not configured", nil)
+	}
+	var req ProgramRequest
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	if err := edit(ctx, req.Address); err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to "+action+" program", err)
+	}
+	o, err := h.Programs.GetProgramOverrides(ctx)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to read program overrides", err)
+	}
+
+	resp := ProgramOverridesResponse{Added: o.Added, Removed: o.Removed}
+	if h.Reloads != nil {
+		// the override is stored either way; indexers that miss this pick it up on their next reload
+		n, err := h.Reloads.RequestReload(ctx)
+		if err != nil {
+			h.log(c).WithError(err).Warn("failed to broadcast program change")
+		}
+		resp.Receivers = n
 	}
 
-	return c.JSON(http.StatusOK, AIAskResponse{SQL: res.SQL, Answer: res.Answer, TookMs: time.Since(start).Milliseconds()})
+	h.log(c).WithFields(logrus.Fields{"action": action, "program": req.Address, "receivers": resp.Receivers}).Info("indexer programs changed")
+	return c.JSON(http.StatusOK, resp)
 }
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// readyCheckTimeout bounds each dependency check of /readyz
+const readyCheckTimeout = 2 * time.Second
+
+// Healthz is the liveness probe: it answers as long as the process serves
+// HTTP and never touches dependencies, so an outage elsewhere cannot get the
+// API restarted
+func (h *Handlers) Healthz(c echo.Context) error {
+	return c.JSON(http.StatusOK, HealthResponse{OK: true})
+}
+
+// Readyz is the readiness probe: 200 when Redis answers, no indexer replica
+// lags more than MaxSlotLag slots behind the chain and none has a stalled
+// stream provider, 503 otherwise.
+// Every check is reported so the failing dependency is visible in the body.
+func (h *Handlers) Readyz(c echo.Context) error {
+	ctx := c.Request().Context()
+	resp := ReadyResponse{Ready: true, Checks: map[string]ReadyCheck{}}
+
+	resp.Checks["redis"] = runCheck(ctx, func(ctx context.Context) (string, error) {
+		return "", h.Cache.Ping(ctx)
+	})
+	if h.Indexers != nil && h.MaxSlotLag > 0 {
+		resp.Checks["ingestion"] = runCheck(ctx, h.checkIngestion)
+	}
+	if h.Indexers != nil {
+		resp.Checks["stream"] = runCheck(ctx, h.checkStream)
+	}
+
+	for _, chk := range resp.Checks {
+		if !chk.OK {
+			resp.Ready = false
+		}
+	}
+	if !resp.Ready {
+		return c.JSON(http.StatusServiceUnavailable, resp)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// checkIngestion fails when an actively polled program lags beyond MaxSlotLag.
+// Without any indexer reporting there is nothing to compare, so it passes.
+func (h *Handlers) checkIngestion(ctx context.Context) (string, error) {
+	instances, err := h.Indexers.ListIndexerStatus(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(instances) == 0 {
+		return "no indexer reporting", nil
+	}
+
+	var worst int64
+	var where string
+	for _, in := range instances {
+		for _, p := range in.Programs {
+			if p.Active && p.SlotLag > worst {
+				worst, where = p.SlotLag, fmt.Sprintf("%s on %s", p.Program, in.Instance)
+			}
+		}
+	}
+	if worst > h.MaxSlotLag {
+		return "", fmt.Errorf("slot lag %d > %d (%s)", worst, h.MaxSlotLag, where)
+	}
+	return fmt.Sprintf("max slot lag %d", worst), nil
+}
+
+// checkStream fails while any replica's stream provider has gone silent past
+// its stall timeout, i.e. while its supervisor is restarting it
+func (h *Handlers) checkStream(ctx context.Context) (string, error) {
+	instances, err := h.Indexers.ListIndexerStatus(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var stalled []string
+	var quietest float64
+	for _, in := range instances {
+		if in.Stream == nil {
+			continue
+		}
+		if in.Stream.Stalled {
+			stalled = append(stalled, fmt.Sprintf("%s on %s (%.0fs since last event)", in.Stream.Provider, in.Instance, in.Stream.SecondsSinceEvent))
+		}
+		quietest = max(quietest, in.Stream.SecondsSinceEvent)
+	}
+	if len(stalled) > 0 {
+		return "", fmt.Errorf("stream stalled: %s", strings.Join(stalled, ", "))
+	}
+	return fmt.Sprintf("last event at most %.0fs ago", quietest), nil
+}
+
+// runCheck times one readiness check
+func runCheck(ctx context.Context, check func(context.Context) (string, error)) ReadyCheck {
+	ctx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check(ctx)
+	out := ReadyCheck{OK: err == nil, Detail: detail, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		out.Error = err.Error()
+	}
+	return out
+}
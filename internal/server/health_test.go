@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeIndexers []models.IndexerStatus
+
+func (f fakeIndexers) ListIndexerStatus(context.Context) ([]models.IndexerStatus, error) {
+	return f, nil
+}
+
+func TestProbesSkipAPIKey(t *testing.T) {
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Cache: cache.NewMemoryCache(10, 0)}, ServerConfig{APIKey: "secret"})
+
+	assert.Equal(t, http.StatusOK, get(t, e, "/healthz", "").Code)
+	assert.Equal(t, http.StatusOK, get(t, e, "/readyz", "").Code)
+	assert.NotEqual(t, http.StatusOK, get(t, e, "/v1/health", "").Code, "other routes still need the key")
+}
+
+func TestReadyzSlotLag(t *testing.T) {
+	lagging := fakeIndexers{{
+		Instance: "indexer-1",
+		Programs: []models.ProgramStatus{
+			{Program: "prog-a", Active: true, SlotLag: 900},
+			{Program: "prog-b", Active: false, SlotLag: 5000}, // led by another replica
+		},
+	}}
+	h := &Handlers{Cache: cache.NewMemoryCache(10, 0), Indexers: lagging, MaxSlotLag: 300}
+	e := echo.New()
+	RegisterRoutes(e, h, ServerConfig{})
+
+	rec := get(t, e, "/readyz", "")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var resp ReadyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.False(t, resp.Ready)
+	assert.True(t, resp.Checks["redis"].OK)
+	assert.Equal(t, "slot lag 900 > 300 (prog-a on indexer-1)", resp.Checks["ingestion"].Error)
+
+	h.MaxSlotLag = 1000
+	rec = get(t, e, "/readyz", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "max slot lag 900")
+
+	// liveness never looks at dependencies
+	h.MaxSlotLag = 1
+	assert.Equal(t, http.StatusOK, get(t, e, "/healthz", "").Code)
+}
+
+func TestReadyzStreamStalled(t *testing.T) {
+	indexers := fakeIndexers{
+		{Instance: "indexer-1", Stream: &models.StreamHealth{Provider: "rpc", SecondsSinceEvent: 12}},
+		{Instance: "indexer-2", Stream: &models.StreamHealth{Provider: "rpc", SecondsSinceEvent: 400, Stalled: true}},
+	}
+	h := &Handlers{Cache: cache.NewMemoryCache(10, 0), Indexers: indexers}
+	e := echo.New()
+	RegisterRoutes(e, h, ServerConfig{})
+
+	rec := get(t, e, "/readyz", "")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var resp ReadyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "stream stalled: rpc on indexer-2 (400s since last event)", resp.Checks["stream"].Error)
+
+	h.Indexers = indexers[:1]
+	rec = get(t, e, "/readyz", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "last event at most 12s ago")
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// IndexerLatency reports p50/p95/p99 of the time from a swap's block time to
+// its indexing, per stream provider, from the status reports of the running
+// indexer replicas
+func (h *Handlers) IndexerLatency(c echo.Context) error {
+	if h.Indexers == nil {
+		return h.err(c, http.StatusBadRequest, "indexer status is not configured", nil)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	instances, err := h.Indexers.ListIndexerStatus(ctx)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to read indexer status", err)
+	}
+
+	byProvider := map[string]*ProviderLatency{}
+	for _, st := range instances {
+		e := st.EndToEnd
+		if e == nil {
+			continue
+		}
+		p := byProvider[e.Provider]
+		if p == nil {
+			p = &ProviderLatency{Provider: e.Provider}
+			byProvider[e.Provider] = p
+		}
+		p.Swaps += e.Swaps
+		p.P50Ms = max(p.P50Ms, e.P50Ms)
+		p.P95Ms = max(p.P95Ms, e.P95Ms)
+		p.P99Ms = max(p.P99Ms, e.P99Ms)
+		p.Instances = append(p.Instances, InstanceLatency{
+			Instance: st.Instance,
+			Swaps:    e.Swaps,
+			P50Ms:    e.P50Ms,
+			P95Ms:    e.P95Ms,
+			P99Ms:    e.P99Ms,
+		})
+	}
+
+	resp := IndexerLatencyResponse{Providers: make([]ProviderLatency, 0, len(byProvider))}
+	for _, p := range byProvider {
+		resp.Providers = append(resp.Providers, *p)
+	}
+	sort.Slice(resp.Providers, func(i, j int) bool { return resp.Providers[i].Provider < resp.Providers[j].Provider })
+	return c.JSON(http.StatusOK, resp)
+}
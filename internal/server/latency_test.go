@@ -0,0 +1,41 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexerLatency(t *testing.T) {
+	indexers := fakeIndexers{
+		{Instance: "indexer-1", EndToEnd: &models.EndToEndLatency{Provider: "rpc", Swaps: 100, P50Ms: 900, P95Ms: 2500, P99Ms: 4000}},
+		{Instance: "indexer-2", EndToEnd: &models.EndToEndLatency{Provider: "rpc", Swaps: 50, P50Ms: 1200, P95Ms: 2000, P99Ms: 6000}},
+		{Instance: "indexer-3", EndToEnd: &models.EndToEndLatency{Provider: "triton", Swaps: 10, P50Ms: 400, P95Ms: 800, P99Ms: 900}},
+		{Instance: "indexer-4"}, // nothing indexed yet
+	}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Indexers: indexers}, ServerConfig{})
+
+	rec := get(t, e, "/v1/admin/indexer/latency", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp IndexerLatencyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Providers, 2)
+
+	rpc := resp.Providers[0]
+	assert.Equal(t, "rpc", rpc.Provider)
+	assert.Equal(t, uint64(150), rpc.Swaps)
+	assert.Equal(t, []float64{1200, 2500, 6000}, []float64{rpc.P50Ms, rpc.P95Ms, rpc.P99Ms}, "slowest replica per quantile")
+	require.Len(t, rpc.Instances, 2)
+	assert.Equal(t, "indexer-2", rpc.Instances[1].Instance)
+	assert.Equal(t, "triton", resp.Providers[1].Provider)
+
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{})
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/admin/indexer/latency", "").Code)
+}
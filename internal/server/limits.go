@@ -0,0 +1,83 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// routeTimeoutKey marks requests running under routeTimeout
+const routeTimeoutKey = "route_timeout"
+
+// bodyLimit answers 413 when a request body exceeds max bytes. The body is
+// read up front (bodies here are small JSON documents), so handlers and the
+// binder never see a truncated body.
+func (h *Handlers) bodyLimit(max int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if max <= 0 || req.Body == nil || req.Body == http.NoBody {
+				return next(c)
+			}
+			if req.ContentLength > max {
+				return h.tooLarge(c, max)
+			}
+			data, err := io.ReadAll(io.LimitReader(req.Body, max+1))
+			_ = req.Body.Close()
+			if err != nil {
+				return h.err(c, http.StatusBadRequest, "failed to read request body", map[string]any{"err": err.Error()})
+			}
+			if int64(len(data)) > max {
+				return h.tooLarge(c, max)
+			}
+			req.Body = io.NopCloser(bytes.NewReader(data))
+			req.ContentLength = int64(len(data))
+			return next(c)
+		}
+	}
+}
+
+// tooLarge writes the 413 for bodyLimit
+func (h *Handlers) tooLarge(c echo.Context, max int64) error {
+	return h.err(c, http.StatusRequestEntityTooLarge, "request body too large", map[string]any{"limit_bytes": max})
+}
+
+// routeTimeout bounds the request context to the deadline of the matched
+// route: perRoute[c.Path()] when present (0: none, for handlers that manage
+// their own), def otherwise. Handler timeouts are derived from it, so they end
+// no later; an error the handler reports once the deadline has passed becomes
+// 408 (see err), and a handler that returns without writing gets one.
+func (h *Handlers) routeTimeout(def time.Duration, perRoute map[string]time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			d, ok := perRoute[c.Path()]
+			if !ok {
+				d = def
+			}
+			if d <= 0 {
+				return next(c)
+			}
+			ctx, cancel := context.WithTimeout(c.Request().Context(), d)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+			c.Set(routeTimeoutKey, d)
+
+			err := next(c)
+			if !c.Response().Committed && routeTimedOut(c) {
+				return h.err(c, http.StatusRequestTimeout, "request timed out", nil)
+			}
+			return err
+		}
+	}
+}
+
+// routeTimedOut reports whether the request ran past its routeTimeout
+func routeTimedOut(c echo.Context) bool {
+	_, ok := c.Get(routeTimeoutKey).(time.Duration)
+	return ok && errors.Is(c.Request().Context().Err(), context.DeadlineExceeded)
+}
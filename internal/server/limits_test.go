@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimit(t *testing.T) {
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{MaxBodyBytes: 16})
+
+	post := func(body string, chunked bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/echo", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if chunked {
+			req.ContentLength = -1 // length unknown up front
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, post(`{"a":1}`, false).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post(`{"a":"0123456789abcdef"}`, false).Code)
+
+	rec := post(`{"a":"0123456789abcdef"}`, true)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "request body too large", resp.Error)
+}
+
+func TestRouteTimeout(t *testing.T) {
+	h := &Handlers{}
+	e := echo.New()
+	e.HTTPErrorHandler = NotFoundJSON()
+	e.Use(h.routeTimeout(20*time.Millisecond, map[string]time.Duration{"/free": 0}))
+	slow := func(c echo.Context) error {
+		select {
+		case <-c.Request().Context().Done():
+			return h.err(c, http.StatusInternalServerError, "backend failed", nil)
+		case <-time.After(200 * time.Millisecond):
+			return c.JSON(http.StatusOK, HealthResponse{OK: true})
+		}
+	}
+	e.GET("/slow", slow)
+	e.GET("/free", slow)
+	e.GET("/quiet", func(c echo.Context) error {
+		<-c.Request().Context().Done()
+		return context.Cause(c.Request().Context())
+	})
+
+	rec := get(t, e, "/slow", "")
+	assert.Equal(t, http.StatusRequestTimeout, rec.Code)
+	assert.JSONEq(t, `{"error":"request timed out","code":408}`, rec.Body.String())
+
+	assert.Equal(t, http.StatusRequestTimeout, get(t, e, "/quiet", "").Code)
+	assert.Equal(t, http.StatusOK, get(t, e, "/free", "").Code)
+}
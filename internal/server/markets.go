@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// MarketReader returns the last price of a token on each venue
+// (implemented by *cache.RedisCache)
+type MarketReader interface {
+	GetMarkets(ctx context.Context, token string) ([]models.MarketPrice, error)
+}
+
+// PriceMarkets compares a token's last price across DEX pools quoted in one
+// token (quote, default: the quote with the most volume). Fresh venues give
+// a volume-weighted mid and the widest spread; each venue's deviation from
+// the mid shows which one is stale or out of line.
+func (h *Handlers) PriceMarkets(c echo.Context) error {
+	if h.Markets == nil {
+		return h.err(c, http.StatusBadRequest, "per-venue prices are not enabled", nil)
+	}
+	var req PriceMarketsRequest
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+	token := strings.ToUpper(strings.TrimSpace(req.Token))
+	quote := strings.ToUpper(strings.TrimSpace(req.Quote))
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	all, err := h.Markets.GetMarkets(ctx, token)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to get markets", err)
+	}
+	if quote == "" {
+		quote = busiestQuote(all)
+	}
+
+	staleAfter := h.PriceStaleAfter
+	if staleAfter <= 0 {
+		staleAfter = constants.PriceStaleAfter
+	}
+	resp := summarizeMarkets(token, quote, all, time.Now(), staleAfter)
+	return c.JSON(http.StatusOK, resp)
+}
+
+// busiestQuote returns the quote token with the most volume across venues
+func busiestQuote(markets []models.MarketPrice) string {
+	vol := make(map[string]float64)
+	for _, m := range markets {
+		vol[m.Quote] += m.Volume
+	}
+	best := ""
+	for q, v := range vol {
+		if best == "" || v > vol[best] || v == vol[best] && q < best {
+			best = q
+		}
+	}
+	return best
+}
+
+// summarizeMarkets keeps the venues quoted in quote and derives the mid and
+// spread from those updated within staleAfter. Without volume the mid is a
+// plain average.
+func summarizeMarkets(token, quote string, all []models.MarketPrice, now time.Time, staleAfter time.Duration) PriceMarketsResponse {
+	resp := PriceMarketsResponse{Token: token, Quote: quote, Markets: []MarketEntry{}}
+	var (
+		weighted, volume, sum float64
+		lo, hi                = math.Inf(1), math.Inf(-1)
+		fresh                 int
+	)
+	for _, m := range all {
+		if m.Quote != quote {
+			continue
+		}
+		e := MarketEntry{MarketPrice: m, Stale: now.Sub(m.UpdatedAt) > staleAfter}
+		resp.Markets = append(resp.Markets, e)
+		if e.Stale {
+			continue
+		}
+		fresh++
+		sum += m.Price
+		weighted += m.Price * m.Volume
+		volume += m.Volume
+		lo, hi = math.Min(lo, m.Price), math.Max(hi, m.Price)
+	}
+	sort.Slice(resp.Markets, func(i, j int) bool { return resp.Markets[i].Volume > resp.Markets[j].Volume })
+	resp.Count = len(resp.Markets)
+	if fresh == 0 {
+		return resp
+	}
+
+	mid := sum / float64(fresh)
+	if volume > 0 {
+		mid = weighted / volume
+	}
+	spread := (hi - lo) / mid * 10000
+	resp.Mid, resp.SpreadBps = &mid, &spread
+	for i := range resp.Markets {
+		dev := (resp.Markets[i].Price/mid - 1) * 10000
+		resp.Markets[i].DeviationBps = &dev
+	}
+	return resp
+}
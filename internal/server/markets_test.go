@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMarkets struct {
+	markets []models.MarketPrice
+}
+
+func (f *fakeMarkets) GetMarkets(_ context.Context, token string) ([]models.MarketPrice, error) {
+	if token != "SOL" {
+		return nil, nil
+	}
+	return f.markets, nil
+}
+
+func TestPriceMarkets(t *testing.T) {
+	now := time.Now().UTC()
+	markets := &fakeMarkets{markets: []models.MarketPrice{
+		{Dex: "Orca", Pool: "p1", Quote: "USDC", Price: 100, Volume: 30, UpdatedAt: now},
+		{Dex: "Raydium", Pool: "p2", Quote: "USDC", Price: 102, Volume: 10, UpdatedAt: now},
+		{Dex: "Meteora", Pool: "p3", Quote: "USDC", Price: 90, Volume: 5, UpdatedAt: now.Add(-time.Hour)},
+		{Dex: "Orca", Pool: "p4", Quote: "BONK", Price: 5e6, Volume: 1, UpdatedAt: now},
+	}}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Markets: markets}, ServerConfig{})
+
+	rec := get(t, e, "/v1/prices/sol/markets", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp PriceMarketsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "USDC", resp.Quote, "defaults to the quote with the most volume")
+	require.Equal(t, 3, resp.Count)
+	assert.Equal(t, "Orca", resp.Markets[0].Dex)
+
+	// the stale Meteora price is listed but left out of mid and spread
+	require.NotNil(t, resp.Mid)
+	assert.InDelta(t, 100.5, *resp.Mid, 1e-9)
+	assert.InDelta(t, 2/100.5*10000, *resp.SpreadBps, 1e-6)
+	assert.True(t, resp.Markets[2].Stale)
+	assert.InDelta(t, (90/100.5-1)*10000, *resp.Markets[2].DeviationBps, 1e-6)
+
+	rec = get(t, e, "/v1/prices/SOL/markets?quote=bonk", "")
+	resp = PriceMarketsResponse{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Count)
+	assert.InDelta(t, 0, *resp.SpreadBps, 1e-9)
+
+	// unknown token: no venues and no mid
+	rec = get(t, e, "/v1/prices/XYZ/markets", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	resp = PriceMarketsResponse{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Zero(t, resp.Count)
+	assert.Nil(t, resp.Mid)
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+// API key tiers reported by the http_* metrics
+const (
+	tierPublic = "public" // no key checked: auth is off, or a probe/metrics route
+	tierAdmin  = "admin"  // authenticated with API_KEY
+	tierScoped = "scoped" // authenticated with one of API_KEYS
+)
+
+// requestBuckets extend metrics.DefaultBuckets to the AI route deadline
+var requestBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60}
+
+var (
+	httpRequests = metrics.Default.Counter("http_requests_total",
+		"API requests answered, by route template, method, status code and API key tier.", "route", "method", "code", "tier")
+	httpDuration = metrics.Default.Histogram("http_request_duration_seconds",
+		"Time to answer an API request, by route template, method and API key tier.", requestBuckets, "route", "method", "tier")
+)
+
+// requestMetrics records the duration and status code of every request under
+// its route template (so /v1/prices/:token is one series). It runs outside the
+// other middleware, so 401, 408, 413 and 503 answers are counted too.
+func requestMetrics(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+		if err != nil {
+			c.Error(err) // settle the status code now; the error is handled
+		}
+
+		route := c.Path()
+		if route == "/metrics" {
+			return nil // scrapes would swamp the series they read
+		}
+		if route == "" || c.Response().Status == http.StatusNotFound && route == "/*" {
+			route = "unmatched"
+		}
+		method, tier := c.Request().Method, requestTier(c)
+		httpRequests.With(route, method, strconv.Itoa(c.Response().Status), tier).Inc()
+		httpDuration.With(route, method, tier).Observe(time.Since(start).Seconds())
+		return nil
+	}
+}
+
+// requestTier is the API key tier of the request
+func requestTier(c echo.Context) string {
+	if id, ok := c.Get(apiKeyIDContextKey).(string); !ok || id == "" {
+		return tierPublic
+	}
+	if scoped, _ := c.Get(apiKeyScopedContextKey).(bool); scoped {
+		return tierScoped
+	}
+	return tierAdmin
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{APIKey: "secret", APIKeys: []string{"reader"}})
+
+	do := func(path, key string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	okBefore := httpRequests.With("/v1/health", http.MethodGet, "200", tierAdmin).Value()
+	deniedBefore := httpRequests.With("/v1/health", http.MethodGet, "401", tierPublic).Value()
+	probeBefore := httpDuration.With("/healthz", http.MethodGet, tierPublic).Count()
+	missingBefore := httpRequests.With("unmatched", http.MethodGet, "404", tierAdmin).Value()
+	scopedBefore := httpRequests.With("/v1/health", http.MethodGet, "200", tierScoped).Value()
+
+	assert.Equal(t, http.StatusOK, do("/v1/health", "secret"))
+	assert.Equal(t, http.StatusUnauthorized, do("/v1/health", "wrong"))
+	assert.Equal(t, http.StatusOK, do("/healthz", ""))
+	assert.Equal(t, http.StatusNotFound, do("/v1/nope/123", "secret"))
+	assert.Equal(t, http.StatusOK, do("/v1/health", "reader"))
+
+	assert.Equal(t, okBefore+1, httpRequests.With("/v1/health", http.MethodGet, "200", tierAdmin).Value())
+	assert.Equal(t, deniedBefore+1, httpRequests.With("/v1/health", http.MethodGet, "401", tierPublic).Value())
+	assert.Equal(t, probeBefore+1, httpDuration.With("/healthz", http.MethodGet, tierPublic).Count())
+	assert.Equal(t, missingBefore+1, httpRequests.With("unmatched", http.MethodGet, "404", tierAdmin).Value())
+	assert.Equal(t, scopedBefore+1, httpRequests.With("/v1/health", http.MethodGet, "200", tierScoped).Value())
+	assert.Equal(t, okBefore+1, httpRequests.With("/v1/health", http.MethodGet, "200", tierAdmin).Value(), "scoped keys are not counted as admin")
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// MEVAnalytics aggregates the sandwich attacks recorded by ssi mev
+// (implemented by *cache.ClickHouseStore)
+type MEVAnalytics interface {
+	MEVStats(ctx context.Context, since time.Time, top int) (*models.MEVStats, error)
+}
+
+// MEVStats summarises sandwich attacks: counts, extracted value, and the top
+// attackers and pools. Accepts window (Go duration, default 24h, max 720h)
+// and top (default 10, max 50).
+func (h *Handlers) MEVStats(c echo.Context) error {
+	if h.MEV == nil {
+		return h.err(c, http.StatusBadRequest, "MEV analytics is not enabled", nil)
+	}
+	req := MEVStatsRequest{Window: 24 * time.Hour, Top: 10}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	stats, err := h.MEV.MEVStats(ctx, time.Now().Add(-req.Window), req.Top)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to get MEV stats", err)
+	}
+	return c.JSON(http.StatusOK, MEVStatsResponse{Window: req.Window.String(), MEVStats: stats})
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMEV struct {
+	since time.Time
+	top   int
+}
+
+func (f *fakeMEV) MEVStats(_ context.Context, since time.Time, top int) (*models.MEVStats, error) {
+	f.since, f.top = since, top
+	return &models.MEVStats{Sandwiches: 3, Attackers: 1, Victims: 3, ProfitUSD: 12.5}, nil
+}
+
+func TestMEVStats(t *testing.T) {
+	mev := &fakeMEV{}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{MEV: mev}, ServerConfig{})
+
+	rec := get(t, e, "/v1/mev/stats?window=6h&top=5", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp MEVStatsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "6h0m0s", resp.Window)
+	assert.EqualValues(t, 3, resp.Sandwiches)
+	assert.InDelta(t, 12.5, resp.ProfitUSD, 1e-9)
+	assert.Equal(t, 5, mev.top)
+	assert.WithinDuration(t, time.Now().Add(-6*time.Hour), mev.since, time.Minute)
+
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/mev/stats?top=100", "").Code)
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{})
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/mev/stats", "").Code)
+}
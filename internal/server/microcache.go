@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ResponseCache stores whole API responses for a very short time;
+// *cache.RedisCache implements it
+type ResponseCache interface {
+	GetResponse(ctx context.Context, key string) ([]byte, error)
+	PutResponse(ctx context.Context, key string, data []byte, ttl time.Duration) error
+}
+
+// cachedResponse is what the micro-cache stores per request
+type cachedResponse struct {
+	ETag string `json:"etag,omitempty"`
+	Body []byte `json:"body"`
+}
+
+// microCache serves identical GETs from store for ttl, so many dashboards
+// polling the same endpoint cost one backend read per ttl rather than one per
+// request. Only 200 responses are stored; responses carry X-Cache: HIT or MISS.
+// With no store or ttl <= 0 it does nothing.
+func (h *Handlers) microCache(store ResponseCache, ttl time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if store == nil || ttl <= 0 {
+			return next
+		}
+		return func(c echo.Context) error {
+			if c.Request().Method != http.MethodGet {
+				return next(c)
+			}
+			ctx := c.Request().Context()
+			// Encode sorts the parameters, so ?a=1&b=2 and ?b=2&a=1 share an entry
+			key := c.Request().URL.Path + "?" + c.QueryParams().Encode()
+
+			data, err := store.GetResponse(ctx, key)
+			if err != nil && h.Logger != nil {
+				h.log(c).WithError(err).Warn("response cache read failed")
+			}
+			var hit cachedResponse
+			if data != nil && json.Unmarshal(data, &hit) == nil {
+				c.Response().Header().Set("X-Cache", "HIT")
+				if hit.ETag != "" && notModified(c, hit.ETag) {
+					return nil
+				}
+				return c.JSONBlob(http.StatusOK, hit.Body)
+			}
+
+			c.Response().Header().Set("X-Cache", "MISS")
+			w := &captureWriter{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = w
+			err = next(c)
+			c.Response().Writer = w.ResponseWriter
+			if err != nil || c.Response().Status != http.StatusOK {
+				return err
+			}
+
+			entry, err := json.Marshal(cachedResponse{ETag: c.Response().Header().Get("ETag"), Body: w.body.Bytes()})
+			if err == nil {
+				err = store.PutResponse(ctx, key, entry, ttl)
+			}
+			if err != nil && h.Logger != nil {
+				h.log(c).WithError(err).Warn("response cache write failed")
+			}
+			return nil
+		}
+	}
+}
+
+// captureWriter keeps a copy of the body written through it
+type captureWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResponseCache map[string][]byte
+
+func (f fakeResponseCache) GetResponse(_ context.Context, key string) ([]byte, error) {
+	return f[key], nil
+}
+
+func (f fakeResponseCache) PutResponse(_ context.Context, key string, data []byte, _ time.Duration) error {
+	f[key] = data
+	return nil
+}
+
+func TestMicroCacheServesRepeatedPolls(t *testing.T) {
+	mem := cache.NewMemoryCache(10, 0)
+	ctx := context.Background()
+	require.NoError(t, mem.AddRecentSwap(ctx, &models.SwapEvent{Signature: "sig1", Pair: "SOL/USDC"}))
+
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Cache: mem, Responses: fakeResponseCache{}}, ServerConfig{ResponseCacheTTL: 250 * time.Millisecond})
+
+	first := get(t, e, "/v1/swaps/recent?limit=5&pair=SOL/USDC", "")
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+
+	// a newer swap is not visible until the entry expires
+	require.NoError(t, mem.AddRecentSwap(ctx, &models.SwapEvent{Signature: "sig2", Pair: "SOL/USDC"}))
+	second := get(t, e, "/v1/swaps/recent?pair=SOL/USDC&limit=5", "")
+	require.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+
+	rec := get(t, e, "/v1/swaps/recent?pair=SOL/USDC&limit=5", first.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// different query, different entry
+	assert.Equal(t, "MISS", get(t, e, "/v1/swaps/recent?limit=6", "").Header().Get("X-Cache"))
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/labstack/echo/v4"
+)
+
+// MarketListings lists what the indexer has seen trade
+// (implemented by *cache.ClickHouseStore)
+type MarketListings interface {
+	ListPairs(ctx context.Context, q storage.PairListQuery) ([]models.PairListing, error)
+	DexStats(ctx context.Context, since time.Time) (*models.DexStats, error)
+}
+
+// ListPairs lists every indexed pair with its first and last swap, the DEXes
+// it trades on and its last 24 hours, busiest first. Accepts token and dex
+// filters, limit (default 100, max 1000) and offset.
+func (h *Handlers) ListPairs(c echo.Context) error {
+	if h.Listings == nil {
+		return h.err(c, http.StatusBadRequest, "market listings are not configured", nil)
+	}
+	req := ListPairsRequest{Limit: 100}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 15*time.Second)
+	defer cancel()
+
+	pairs, err := h.Listings.ListPairs(ctx, storage.PairListQuery{
+		Token:  strings.TrimSpace(req.Token),
+		Dex:    strings.TrimSpace(req.Dex),
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to list pairs", err)
+	}
+	return c.JSON(http.StatusOK, ListPairsResponse{Pairs: pairs, Count: len(pairs)})
+}
+
+// DexStats compares DEXes: trades, USD volume, average trade size and market
+// share of each. Accepts window (Go duration, default 24h, max 720h).
+func (h *Handlers) DexStats(c echo.Context) error {
+	if h.Listings == nil {
+		return h.err(c, http.StatusBadRequest, "market listings are not configured", nil)
+	}
+	req := DexStatsRequest{Window: 24 * time.Hour}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 15*time.Second)
+	defer cancel()
+
+	stats, err := h.Listings.DexStats(ctx, time.Now().Add(-req.Window))
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to get dex stats", err)
+	}
+	return c.JSON(http.StatusOK, DexStatsResponse{Window: req.Window.String(), DexStats: stats})
+}
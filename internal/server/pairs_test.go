@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeListings struct {
+	pairs storage.PairListQuery
+	since time.Time
+}
+
+func (f *fakeListings) ListPairs(_ context.Context, q storage.PairListQuery) ([]models.PairListing, error) {
+	f.pairs = q
+	first := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	return []models.PairListing{{
+		Pair: "SOL/USDC", TokenIn: "SOL", TokenOut: "USDC",
+		FirstSeen: first, LastSeen: first.Add(24 * time.Hour),
+		Trades24h: 12, Volume24h: 30, VolumeUSD24h: 4500, Dexes: []string{"Orca", "Raydium"},
+	}}, nil
+}
+
+func (f *fakeListings) DexStats(_ context.Context, since time.Time) (*models.DexStats, error) {
+	f.since = since
+	return &models.DexStats{Trades: 4, VolumeUSD: 1000, Dexes: []models.DexSummary{
+		{Dex: "Raydium", Trades: 3, VolumeUSD: 750, AvgTradeUSD: 250, TradeShare: 0.75, VolumeShare: 0.75},
+		{Dex: "Orca", Trades: 1, VolumeUSD: 250, AvgTradeUSD: 250, TradeShare: 0.25, VolumeShare: 0.25},
+	}}, nil
+}
+
+func TestListPairs(t *testing.T) {
+	listings := &fakeListings{}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Listings: listings}, ServerConfig{})
+
+	rec := get(t, e, "/v1/pairs?token=mSOL&dex=Orca&limit=10&offset=20", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp ListPairsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, 1, resp.Count)
+	assert.Equal(t, []string{"Orca", "Raydium"}, resp.Pairs[0].Dexes)
+	assert.EqualValues(t, 12, resp.Pairs[0].Trades24h)
+	assert.Equal(t, storage.PairListQuery{Token: "mSOL", Dex: "Orca", Limit: 10, Offset: 20}, listings.pairs, "token kept as stored")
+
+	rec = get(t, e, "/v1/pairs", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 100, listings.pairs.Limit)
+
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/pairs?limit=5000", "").Code)
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{})
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/pairs", "").Code)
+}
+
+func TestDexStats(t *testing.T) {
+	listings := &fakeListings{}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Listings: listings}, ServerConfig{})
+
+	rec := get(t, e, "/v1/dexes?window=6h", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp DexStatsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "6h0m0s", resp.Window)
+	require.Len(t, resp.Dexes, 2)
+	assert.Equal(t, 0.75, resp.Dexes[0].VolumeShare)
+	assert.WithinDuration(t, time.Now().Add(-6*time.Hour), listings.since, time.Minute)
+
+	assert.Equal(t, http.StatusOK, get(t, e, "/v1/dexes", "").Code)
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), listings.since, time.Minute)
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/dexes?window=1000h", "").Code)
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// PoolManager lists and reloads the swap engine's pool registry
+// (implemented by *swapengine.Engine)
+type PoolManager interface {
+	ListPools(ctx context.Context) []swapengine.PoolStatus
+	ReloadPools(ctx context.Context) (*swapengine.PoolReloadResult, error)
+}
+
+// PoolsList lists every registered pool with its current reserves
+func (h *Handlers) PoolsList(c echo.Context) error {
+	if h.Pools == nil {
+		return h.err(c, http.StatusBadRequest, "swap engine is not enabled", nil)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	pools := h.Pools.ListPools(ctx)
+	resp := PoolsResponse{Pools: make([]PoolResponse, len(pools)), Count: len(pools)}
+	for i, p := range pools {
+		resp.Pools[i] = PoolResponse(p)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// PoolsReload re-reads the pool config into the running swap engine
+func (h *Handlers) PoolsReload(c echo.Context) error {
+	if h.Pools == nil {
+		return h.err(c, http.StatusBadRequest, "swap engine is not enabled", nil)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 2*time.Minute)
+	defer cancel()
+
+	res, err := h.Pools.ReloadPools(ctx)
+	if res == nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to reload pools", err)
+	}
+
+	resp := PoolsReloadResponse{
+		OK:         true,
+		Path:       res.Path,
+		Source:     res.Source,
+		Configured: res.Configured,
+		Skipped:    res.Skipped,
+		Discovered: res.Discovered,
+		Total:      res.Total,
+	}
+	for _, fe := range res.Errors {
+		resp.Errors = append(resp.Errors, PoolLoadError(fe))
+	}
+	if err != nil {
+		resp.Warning = err.Error()
+	}
+	h.log(c).WithFields(logrus.Fields{"pools": res.Total, "skipped": res.Skipped}).Info("pool registry reloaded")
+	return c.JSON(http.StatusOK, resp)
+}
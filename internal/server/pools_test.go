@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/orca"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePools struct {
+	pools     []swapengine.PoolStatus
+	reloadErr error
+	reloads   int
+}
+
+func (f *fakePools) ListPools(context.Context) []swapengine.PoolStatus { return f.pools }
+
+func (f *fakePools) ReloadPools(context.Context) (*swapengine.PoolReloadResult, error) {
+	if f.reloadErr != nil {
+		return nil, f.reloadErr
+	}
+	f.reloads++
+	return &swapengine.PoolReloadResult{
+		Path: "pools.json", Source: "file", Configured: len(f.pools), Skipped: 1, Total: len(f.pools),
+		Errors: []orca.PoolFieldError{{Index: 2, Pool: "bad", Field: "vault_a", Message: "required"}},
+	}, nil
+}
+
+func TestPoolsListAndReload(t *testing.T) {
+	pools := &fakePools{pools: []swapengine.PoolStatus{
+		{Name: "SOL-USDC-legacy", FeeBps: 30, ReserveA: 10, ReserveB: 20},
+		{Name: "SOL-USDT-abc123", Discovered: true, Error: "vault A: not found"},
+	}}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Pools: pools, Logger: logrus.New()}, ServerConfig{})
+
+	rec := get(t, e, "/v1/pools", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list PoolsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Equal(t, 2, list.Count)
+	assert.Equal(t, uint64(20), list.Pools[0].ReserveB)
+	assert.True(t, list.Pools[1].Discovered)
+	assert.Equal(t, "vault A: not found", list.Pools[1].Error)
+
+	reload := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/admin/pools/reload", nil))
+		return rec
+	}
+	rec = reload()
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"ok":true,"path":"pools.json","source":"file","configured":2,"skipped":1,"discovered":0,"total":2,
+		"errors":[{"index":2,"pool":"bad","field":"vault_a","message":"required"}]}`, rec.Body.String())
+
+	pools.reloadErr = errors.New("pool 0: swap_account: invalid")
+	assert.Equal(t, http.StatusInternalServerError, reload().Code)
+	assert.Equal(t, 1, pools.reloads)
+}
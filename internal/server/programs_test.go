@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProgram = "whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc"
+
+type fakePrograms struct{ o models.ProgramOverrides }
+
+func (f *fakePrograms) GetProgramOverrides(context.Context) (*models.ProgramOverrides, error) {
+	o := f.o
+	return &o, nil
+}
+
+func (f *fakePrograms) AddProgram(_ context.Context, addr string) error {
+	f.o.Removed = slices.DeleteFunc(f.o.Removed, func(s string) bool { return s == addr })
+	f.o.Added = append(f.o.Added, addr)
+	return nil
+}
+
+func (f *fakePrograms) RemoveProgram(_ context.Context, addr string) error {
+	f.o.Added = slices.DeleteFunc(f.o.Added, func(s string) bool { return s == addr })
+	f.o.Removed = append(f.o.Removed, addr)
+	return nil
+}
+
+type fakeReloads struct{ n int }
+
+func (f *fakeReloads) RequestReload(context.Context) (int64, error) {
+	f.n++
+	return 2, nil
+}
+
+func TestIndexerPrograms(t *testing.T) {
+	programs, reloads := &fakePrograms{}, &fakeReloads{}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Programs: programs, Reloads: reloads, Logger: logrus.New()}, ServerConfig{})
+
+	send := func(method, path, body string) (*httptest.ResponseRecorder, ProgramOverridesResponse) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		var resp ProgramOverridesResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	rec, resp := send(http.MethodPost, "/v1/admin/indexer/programs", `{"address":"`+testProgram+`"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, []string{testProgram}, resp.Added)
+	assert.Equal(t, int64(2), resp.Receivers)
+
+	rec, resp = send(http.MethodDelete, "/v1/admin/indexer/programs/"+testProgram, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, resp.Added)
+	assert.Equal(t, []string{testProgram}, resp.Removed)
+	assert.Equal(t, 2, reloads.n)
+
+	rec, _ = send(http.MethodPost, "/v1/admin/indexer/programs", `{"address":"not-a-key"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "must be a base58 program address")
+	assert.Equal(t, 2, reloads.n, "invalid requests change nothing")
+
+	rec = get(t, e, "/v1/admin/indexer/programs", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"added":[],"removed":["`+testProgram+`"]}`, rec.Body.String())
+}
@@ -1,12 +1,17 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/jupiter"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
 	"github.com/labstack/echo/v4"
 )
 
@@ -28,128 +33,150 @@ func splitCSVQuery(values []string) []string {
 	return out
 }
 
-func (h *Handlers) Quote(c echo.Context) error {
-	if h.Jupiter == nil {
-		return h.err(c, http.StatusBadRequest, "jupiter is not configured", nil)
-	}
+// QuoteRequest holds the query parameters of GET /v1/quote, passed through to Jupiter
+type QuoteRequest struct {
+	InputMint  string `query:"inputMint" validate:"required"`
+	OutputMint string `query:"outputMint" validate:"required"`
+	Amount     string `query:"amount" validate:"required,uint64"` // raw amount, before decimals
+
+	SlippageBps                *uint16  `query:"slippageBps"`
+	SwapMode                   string   `query:"swapMode" validate:"omitempty,oneof=ExactIn ExactOut"`
+	Dexes                      []string `query:"dexes"`        // comma-separated or repeated
+	ExcludeDexes               []string `query:"excludeDexes"` // comma-separated or repeated
+	RestrictIntermediateTokens *bool    `query:"restrictIntermediateTokens"`
+	OnlyDirectRoutes           *bool    `query:"onlyDirectRoutes"`
+	AsLegacyTransaction        *bool    `query:"asLegacyTransaction"`
+	PlatformFeeBps             *uint16  `query:"platformFeeBps"`
+	MaxAccounts                *uint64  `query:"maxAccounts"`
+	InstructionVersion         string   `query:"instructionVersion" validate:"omitempty,oneof=V1 V2"`
+	DynamicSlippage            *bool    `query:"dynamicSlippage"`
+
+	Risk bool `query:"risk"` // not passed on: add the swap engine's risk verdict
+}
 
-	inputMint := strings.TrimSpace(c.QueryParam("inputMint"))
-	outputMint := strings.TrimSpace(c.QueryParam("outputMint"))
-	amountStr := strings.TrimSpace(c.QueryParam("amount"))
+// QuoteCache keeps recent Jupiter quotes; *jupiter.QuoteCache implements it
+type QuoteCache interface {
+	Get(ctx context.Context, req jupiter.QuoteRequest) (*jupiter.QuoteResponse, error)
+	Set(ctx context.Context, req jupiter.QuoteRequest, quote *jupiter.QuoteResponse) error
+}
 
-	if inputMint == "" {
-		return h.err(c, http.StatusBadRequest, "invalid inputMint", map[string]any{"inputMint": "required"})
-	}
-	if outputMint == "" {
-		return h.err(c, http.StatusBadRequest, "invalid outputMint", map[string]any{"outputMint": "required"})
-	}
-	if amountStr == "" {
-		return h.err(c, http.StatusBadRequest, "invalid amount", map[string]any{"amount": "required"})
-	}
-	if _, err := strconv.ParseUint(amountStr, 10, 64); err != nil {
-		return h.err(c, http.StatusBadRequest, "invalid amount", map[string]any{"amount": "must be uint64"})
-	}
+// RouteChecker holds Jupiter routes to the swap engine's risk limits
+// (implemented by *swapengine.Engine)
+type RouteChecker interface {
+	CheckJupiterRoute(ctx context.Context, q *jupiter.QuoteResponse) (*swapengine.RiskCheckResult, *swapengine.QuoteResult, error)
+}
 
-	var slippageBps *uint16
-	if v := strings.TrimSpace(c.QueryParam("slippageBps")); v != "" {
-		n, err := strconv.ParseUint(v, 10, 16)
-		if err != nil {
-			return h.err(c, http.StatusBadRequest, "invalid slippageBps", map[string]any{"slippageBps": "must be uint16"})
-		}
-		tmp := uint16(n)
-		slippageBps = &tmp
-	}
+// QuoteResponse is Jupiter's quote plus whether it was served from the cache
+// and, when asked for with the swap engine enabled, its risk verdict
+type QuoteResponse struct {
+	*jupiter.QuoteResponse
+	Cached bool       `json:"cached"`
+	Risk   *QuoteRisk `json:"risk,omitempty"`
+}
 
-	swapMode := strings.TrimSpace(c.QueryParam("swapMode"))
-	if swapMode != "" && swapMode != "ExactIn" && swapMode != "ExactOut" {
-		return h.err(c, http.StatusBadRequest, "invalid swapMode", map[string]any{"swapMode": "must be ExactIn or ExactOut"})
-	}
+// QuoteRisk is the swap engine's verdict on executing a quoted route. When
+// the check itself fails, Error says why and Allowed is false.
+type QuoteRisk struct {
+	Allowed      bool     `json:"allowed"`
+	Error        string   `json:"error,omitempty"`
+	Reason       string   `json:"reason,omitempty"`
+	SwapValueSOL float64  `json:"swap_value_sol"`
+	Hops         int      `json:"hops"`
+	MaxRouteHops int      `json:"max_route_hops,omitempty"` // 0 = no limit
+	Dexes        []string `json:"dexes"`
+	ExcludedDex  string   `json:"excluded_dex,omitempty"`
+}
 
-	var restrictIntermediateTokens *bool
-	if v := strings.TrimSpace(c.QueryParam("restrictIntermediateTokens")); v != "" {
-		b, err := strconv.ParseBool(v)
-		if err != nil {
-			return h.err(c, http.StatusBadRequest, "invalid restrictIntermediateTokens", map[string]any{"restrictIntermediateTokens": "must be boolean"})
-		}
-		restrictIntermediateTokens = &b
+// Quote proxies a Jupiter quote; every invalid parameter is reported at once.
+// With a QuoteCache, identical requests within its TTL share one Jupiter call.
+// With risk=true and a RouteChecker, the route is checked against the risk
+// limits; that costs a balance lookup, so plain quotes skip it.
+func (h *Handlers) Quote(c echo.Context) error {
+	if h.Jupiter == nil {
+		return h.err(c, http.StatusBadRequest, "jupiter is not configured", nil)
 	}
 
-	var onlyDirectRoutes *bool
-	if v := strings.TrimSpace(c.QueryParam("onlyDirectRoutes")); v != "" {
-		b, err := strconv.ParseBool(v)
-		if err != nil {
-			return h.err(c, http.StatusBadRequest, "invalid onlyDirectRoutes", map[string]any{"onlyDirectRoutes": "must be boolean"})
-		}
-		onlyDirectRoutes = &b
+	var req QuoteRequest
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
 	}
 
-	var asLegacyTransaction *bool
-	if v := strings.TrimSpace(c.QueryParam("asLegacyTransaction")); v != "" {
-		b, err := strconv.ParseBool(v)
-		if err != nil {
-			return h.err(c, http.StatusBadRequest, "invalid asLegacyTransaction", map[string]any{"asLegacyTransaction": "must be boolean"})
+	ctx, cancel := h.withTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	jreq := jupiter.QuoteRequest{
+		InputMint:                  req.InputMint,
+		OutputMint:                 req.OutputMint,
+		Amount:                     req.Amount,
+		SlippageBps:                req.SlippageBps,
+		SwapMode:                   req.SwapMode,
+		Dexes:                      req.Dexes,
+		ExcludeDexes:               req.ExcludeDexes,
+		RestrictIntermediateTokens: req.RestrictIntermediateTokens,
+		OnlyDirectRoutes:           req.OnlyDirectRoutes,
+		AsLegacyTransaction:        req.AsLegacyTransaction,
+		PlatformFeeBps:             req.PlatformFeeBps,
+		MaxAccounts:                req.MaxAccounts,
+		InstructionVersion:         req.InstructionVersion,
+		DynamicSlippage:            req.DynamicSlippage,
+	}
+
+	// Cache errors only cost the upstream call they would have saved
+	if h.Quotes != nil {
+		cached, err := h.Quotes.Get(ctx, jreq)
+		if err != nil && h.Logger != nil {
+			h.log(c).WithError(err).Warn("quote cache read failed")
+		}
+		if cached != nil {
+			return c.JSON(http.StatusOK, QuoteResponse{QuoteResponse: cached, Cached: true, Risk: h.quoteRisk(ctx, c, req, cached)})
 		}
-		asLegacyTransaction = &b
 	}
 
-	var platformFeeBps *uint16
-	if v := strings.TrimSpace(c.QueryParam("platformFeeBps")); v != "" {
-		n, err := strconv.ParseUint(v, 10, 16)
-		if err != nil {
-			return h.err(c, http.StatusBadRequest, "invalid platformFeeBps", map[string]any{"platformFeeBps": "must be uint16"})
+	out, err := h.Jupiter.Quote(ctx, jreq)
+	if err != nil {
+		// Still rate limited after the client's retries: tell the caller when to come back
+		var httpErr *jupiter.HTTPError
+		if errors.Is(err, apperr.RateLimited) {
+			if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(httpErr.RetryAfter.Seconds()))))
+			}
+			return h.fail(c, http.StatusBadGateway, "jupiter rate limited", err)
 		}
-		tmp := uint16(n)
-		platformFeeBps = &tmp
+		return h.fail(c, http.StatusBadGateway, "jupiter quote failed", err)
 	}
 
-	var maxAccounts *uint64
-	if v := strings.TrimSpace(c.QueryParam("maxAccounts")); v != "" {
-		n, err := strconv.ParseUint(v, 10, 64)
-		if err != nil {
-			return h.err(c, http.StatusBadRequest, "invalid maxAccounts", map[string]any{"maxAccounts": "must be uint64"})
+	if h.Quotes != nil {
+		if err := h.Quotes.Set(ctx, jreq, out); err != nil && h.Logger != nil {
+			h.log(c).WithError(err).Warn("quote cache write failed")
 		}
-		maxAccounts = &n
 	}
+	return c.JSON(http.StatusOK, QuoteResponse{QuoteResponse: out, Risk: h.quoteRisk(ctx, c, req, out)})
+}
 
-	instructionVersion := strings.TrimSpace(c.QueryParam("instructionVersion"))
-	if instructionVersion != "" && instructionVersion != "V1" && instructionVersion != "V2" {
-		return h.err(c, http.StatusBadRequest, "invalid instructionVersion", map[string]any{"instructionVersion": "must be V1 or V2"})
+// quoteRisk checks a quoted route against the current risk limits; it is
+// nil unless req asks for it and a RouteChecker is configured
+func (h *Handlers) quoteRisk(ctx context.Context, c echo.Context, req QuoteRequest, q *jupiter.QuoteResponse) *QuoteRisk {
+	if !req.Risk || h.Routes == nil {
+		return nil
 	}
-
-	var dynamicSlippage *bool
-	if v := strings.TrimSpace(c.QueryParam("dynamicSlippage")); v != "" {
-		b, err := strconv.ParseBool(v)
-		if err != nil {
-			return h.err(c, http.StatusBadRequest, "invalid dynamicSlippage", map[string]any{"dynamicSlippage": "must be boolean"})
+	res, quote, err := h.Routes.CheckJupiterRoute(ctx, q)
+	if err != nil {
+		if h.Logger != nil {
+			h.log(c).WithError(err).Warn("quote risk check failed")
+		}
+		out := &QuoteRisk{Error: err.Error(), Dexes: []string{}}
+		if quote != nil {
+			out.Hops, out.Dexes = quote.Hops, quote.Dexes
 		}
-		dynamicSlippage = &b
+		return out
 	}
-
-	dexes := splitCSVQuery(c.QueryParams()["dexes"])
-	excludeDexes := splitCSVQuery(c.QueryParams()["excludeDexes"])
-
-	ctx, cancel := h.withTimeout(c.Request().Context(), 10*time.Second)
-	defer cancel()
-
-	out, err := h.Jupiter.Quote(ctx, jupiter.QuoteRequest{
-		InputMint:                  inputMint,
-		OutputMint:                 outputMint,
-		Amount:                     amountStr,
-		SlippageBps:                slippageBps,
-		SwapMode:                   swapMode,
-		Dexes:                      dexes,
-		ExcludeDexes:               excludeDexes,
-		RestrictIntermediateTokens: restrictIntermediateTokens,
-		OnlyDirectRoutes:           onlyDirectRoutes,
-		AsLegacyTransaction:        asLegacyTransaction,
-		PlatformFeeBps:             platformFeeBps,
-		MaxAccounts:                maxAccounts,
-		InstructionVersion:         instructionVersion,
-		DynamicSlippage:            dynamicSlippage,
-	})
-	if err != nil {
-		return h.err(c, http.StatusBadGateway, "jupiter quote failed", map[string]any{"err": err.Error()})
+	return &QuoteRisk{
+		Allowed:      res.Allowed,
+		Reason:       res.Reason,
+		SwapValueSOL: res.SwapValueSOL,
+		Hops:         quote.Hops,
+		MaxRouteHops: res.MaxRouteHops,
+		Dexes:        quote.Dexes,
+		ExcludedDex:  res.ExcludedDex,
 	}
-
-	return c.JSON(http.StatusOK, out)
 }
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/jupiter"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQuoteCache map[string]*jupiter.QuoteResponse
+
+func (f fakeQuoteCache) Get(_ context.Context, req jupiter.QuoteRequest) (*jupiter.QuoteResponse, error) {
+	return f[jupiter.QuoteKey(req)], nil
+}
+
+func (f fakeQuoteCache) Set(_ context.Context, req jupiter.QuoteRequest, quote *jupiter.QuoteResponse) error {
+	f[jupiter.QuoteKey(req)] = quote
+	return nil
+}
+
+func TestQuoteServedFromCache(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`{"inAmount":"100","outAmount":"42"}`))
+	}))
+	defer upstream.Close()
+
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{
+		Jupiter: jupiter.NewClient(upstream.URL, ""),
+		Quotes:  fakeQuoteCache{},
+	}, ServerConfig{})
+
+	quote := func(path string) (out struct {
+		OutAmount string `json:"outAmount"`
+		Cached    bool   `json:"cached"`
+	}) {
+		rec := get(t, e, path, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+		return out
+	}
+
+	first := quote("/v1/quote?inputMint=SOL&outputMint=USDC&amount=100&dexes=Orca,Raydium")
+	assert.Equal(t, "42", first.OutAmount)
+	assert.False(t, first.Cached)
+
+	second := quote("/v1/quote?inputMint=SOL&outputMint=USDC&amount=100&dexes=Raydium,Orca")
+	assert.Equal(t, "42", second.OutAmount)
+	assert.True(t, second.Cached, "DEX order does not change the cache key")
+	assert.Equal(t, int32(1), calls.Load())
+
+	assert.False(t, quote("/v1/quote?inputMint=SOL&outputMint=USDC&amount=200").Cached)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestQuoteErrorsCarryTheirCode(t *testing.T) {
+	status := http.StatusTooManyRequests
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(status)
+	}))
+	defer upstream.Close()
+
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{
+		Jupiter: jupiter.NewClientWithConfig(jupiter.ClientConfig{BaseURL: upstream.URL}),
+	}, ServerConfig{})
+
+	quote := func() (*httptest.ResponseRecorder, ErrorResponse) {
+		rec := get(t, e, "/v1/quote?inputMint=SOL&outputMint=USDC&amount=100", "")
+		var body ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec, body
+	}
+
+	rec, body := quote()
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "7", rec.Header().Get("Retry-After"))
+	assert.Equal(t, string(apperr.RateLimited), body.Category)
+
+	status = http.StatusInternalServerError
+	rec, body = quote()
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, string(apperr.UpstreamUnavailable), body.Category)
+}
+
+type fakeRouteChecker struct {
+	excluded string
+	err      error
+	calls    *int
+}
+
+func (f fakeRouteChecker) CheckJupiterRoute(_ context.Context, q *jupiter.QuoteResponse) (*swapengine.RiskCheckResult, *swapengine.QuoteResult, error) {
+	*f.calls++
+	quote, err := swapengine.QuoteFromJupiter(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	if f.err != nil {
+		return nil, quote, f.err
+	}
+	res := &swapengine.RiskCheckResult{Allowed: true, SwapValueSOL: 0.5, MaxRouteHops: 2}
+	if slices.Contains(quote.Dexes, f.excluded) {
+		res.Allowed, res.ExcludedDex, res.Reason = false, f.excluded, "route uses excluded DEX "+f.excluded
+	}
+	return res, quote, nil
+}
+
+func TestQuoteReportsRiskVerdict(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"inAmount":"100","outAmount":"42","routePlan":[` +
+			`{"swapInfo":{"label":"Orca","inputMint":"a","outputMint":"b"}},` +
+			`{"swapInfo":{"label":"Phoenix","inputMint":"b","outputMint":"c"}}]}`))
+	}))
+	defer upstream.Close()
+
+	var calls int
+	checker := fakeRouteChecker{excluded: "Phoenix", calls: &calls}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Jupiter: jupiter.NewClient(upstream.URL, ""), Routes: checker}, ServerConfig{})
+
+	quote := func(query string) QuoteResponse {
+		rec := get(t, e, "/v1/quote?inputMint=SOL&outputMint=USDC&amount=100"+query, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var out QuoteResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+		return out
+	}
+
+	// plain quotes skip the check and its balance lookup
+	assert.Nil(t, quote("").Risk)
+	assert.Zero(t, calls)
+
+	out := quote("&risk=true")
+	require.NotNil(t, out.Risk)
+	assert.False(t, out.Risk.Allowed)
+	assert.Empty(t, out.Risk.Error)
+	assert.Equal(t, "Phoenix", out.Risk.ExcludedDex)
+	assert.Equal(t, 2, out.Risk.Hops)
+	assert.Equal(t, []string{"Orca", "Phoenix"}, out.Risk.Dexes)
+
+	// a failed check is reported, not dropped
+	checker.err = errors.New("failed to get balance: rpc down")
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{Jupiter: jupiter.NewClient(upstream.URL, ""), Routes: checker}, ServerConfig{})
+	out = quote("&risk=true")
+	require.NotNil(t, out.Risk)
+	assert.False(t, out.Risk.Allowed)
+	assert.Equal(t, "failed to get balance: rpc down", out.Risk.Error)
+	assert.Equal(t, 2, out.Risk.Hops)
+}
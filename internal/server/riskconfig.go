@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// RiskConfigurator reads and tightens the swap engine's risk limits
+// (implemented by *swapengine.Engine)
+type RiskConfigurator interface {
+	RiskState() swapengine.RiskState
+	SetRiskOverrides(ctx context.Context, o swapengine.RiskOverrides) (swapengine.RiskState, error)
+}
+
+// RiskConfigGet returns the risk limits in force with their configured base
+// and the runtime overrides
+func (h *Handlers) RiskConfigGet(c echo.Context) error {
+	if h.Risk == nil {
+		return h.err(c, http.StatusBadRequest, "swap engine is not enabled", nil)
+	}
+	return c.JSON(http.StatusOK, riskConfigResponse(h.Risk.RiskState()))
+}
+
+// RiskConfigUpdate replaces the runtime risk overrides. Overrides may only
+// tighten the configured limits; an empty body clears them.
+func (h *Handlers) RiskConfigUpdate(c echo.Context) error {
+	if h.Risk == nil {
+		return h.err(c, http.StatusBadRequest, "swap engine is not enabled", nil)
+	}
+	var req RiskConfigRequest
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	state, err := h.Risk.SetRiskOverrides(flags.WithActor(ctx, flagActor(c)), swapengine.RiskOverrides(req))
+	if errors.Is(err, swapengine.ErrRiskOverride) {
+		return h.invalid(c, riskValidationError(err))
+	}
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to update risk config", err)
+	}
+	h.log(c).WithFields(logrus.Fields{"actor": flagActor(c).Name, "ip": c.RealIP()}).Info("swap engine risk config updated")
+	return c.JSON(http.StatusOK, riskConfigResponse(state))
+}
+
+// riskValidationError lists each field rejected by RiskOverrides.Validate
+func riskValidationError(err error) *ValidationError {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	ve := &ValidationError{}
+	for _, e := range errs {
+		var re *swapengine.RiskOverrideError
+		if errors.As(e, &re) {
+			ve.Errors = append(ve.Errors, FieldError{Field: re.Field, Rule: "risk", Message: re.Message})
+		}
+	}
+	return ve
+}
+
+func riskConfigResponse(s swapengine.RiskState) RiskConfigResponse {
+	resp := RiskConfigResponse{
+		Config:    riskLimits(s.Config),
+		Base:      riskLimits(s.Base),
+		Overrides: RiskConfigRequest(s.Overrides),
+		FlagError: s.FlagError,
+	}
+	if !s.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &s.UpdatedAt
+	}
+	return resp
+}
+
+func riskLimits(rc swapengine.RiskConfig) RiskLimits {
+	var wallets map[string]WalletRiskLimits
+	if len(rc.WalletLimits) > 0 {
+		wallets = make(map[string]WalletRiskLimits, len(rc.WalletLimits))
+		for addr, l := range rc.WalletLimits {
+			wallets[addr] = WalletRiskLimits(l)
+		}
+	}
+	return RiskLimits{
+		MaxSwapAmountSOL:   rc.MaxSwapAmountSOL,
+		DailyLimitSOL:      rc.DailyLimitSOL,
+		MaxPriceImpactBps:  rc.MaxPriceImpactBps,
+		DefaultSlippageBps: rc.DefaultSlippageBps,
+		MaxSlippageBps:     rc.MaxSlippageBps,
+		AllowedTokens:      rc.AllowedTokens,
+		MaxRouteHops:       rc.MaxRouteHops,
+		ExcludedDexes:      rc.ExcludedDexes,
+		RequireSimulation:  rc.RequireSimulation,
+		MinBalanceSOL:      rc.MinBalanceSOL,
+		FailureCooldown:    rc.FailureCooldown.String(),
+		WalletLimits:       wallets,
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRisk struct {
+	base  swapengine.RiskConfig
+	state swapengine.RiskState
+	actor flags.Actor
+}
+
+func (f *fakeRisk) RiskState() swapengine.RiskState { return f.state }
+
+func (f *fakeRisk) SetRiskOverrides(ctx context.Context, o swapengine.RiskOverrides) (swapengine.RiskState, error) {
+	if err := o.Validate(f.base); err != nil {
+		return swapengine.RiskState{}, err
+	}
+	f.actor = flags.ActorFrom(ctx)
+	f.state = swapengine.RiskState{Config: o.Apply(f.base), Base: f.base, Overrides: o}
+	return f.state, nil
+}
+
+func TestRiskConfig(t *testing.T) {
+	base := swapengine.DefaultRiskConfig()
+	risk := &fakeRisk{base: base, state: swapengine.RiskState{Config: base, Base: base}}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Risk: risk, Logger: logrus.New()}, ServerConfig{})
+
+	rec := get(t, e, "/v1/swap/risk-config", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp RiskConfigResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 1.0, resp.Config.MaxSwapAmountSOL)
+	assert.Nil(t, resp.UpdatedAt)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/v1/swap/risk-config", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-Actor", "oncall")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec = put(`{"max_swap_amount_sol": 0.25, "allowed_tokens": ["SOL", "USDC"]}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 0.25, resp.Config.MaxSwapAmountSOL)
+	assert.Equal(t, 1.0, resp.Base.MaxSwapAmountSOL)
+	assert.Equal(t, []string{"SOL", "USDC"}, resp.Config.AllowedTokens)
+	assert.Equal(t, "oncall", risk.actor.Name)
+
+	rec = put(`{"max_swap_amount_sol": 5, "max_route_hops": 9}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	var errResp struct {
+		Details []FieldError `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+	require.Len(t, errResp.Details, 2)
+	assert.Equal(t, "max_swap_amount_sol", errResp.Details[0].Field)
+	assert.Equal(t, "max_route_hops", errResp.Details[1].Field)
+	assert.Equal(t, 0.25, risk.state.Config.MaxSwapAmountSOL, "rejected update not applied")
+
+	rec = put(`{"max_slippage_bps": 20000}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{Logger: logrus.New()}, ServerConfig{})
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/swap/risk-config", "").Code)
+}
@@ -2,59 +2,188 @@ package server
 
 import (
 	"net/http"
+	"slices"
 	"time"
 
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"golang.org/x/time/rate"
 )
 
+// apiKeyIDContextKey holds the fingerprint of the API key that authenticated a request
+const apiKeyIDContextKey = "api_key_id"
+
+// apiKeyScopedContextKey is set on requests authenticated by one of the
+// further API_KEYS, which may not use the privileged routes
+const apiKeyScopedContextKey = "api_key_scoped"
+
+// durationOr returns d, or def when d is not positive
+func durationOr(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
 // RegisterRoutes configures all API routes, middleware, and error handlers
 func RegisterRoutes(e *echo.Echo, h *Handlers, cfg ServerConfig) {
 	// Set custom error handler for consistent JSON responses
 	e.HTTPErrorHandler = NotFoundJSON()
 
+	// Bind path/query/body into request structs and check their validate tags
+	e.Binder = &requestBinder{}
+	e.Validator = requestValidator{}
+
 	// Apply global middleware
 	e.Use(SetJSONContentType) // Ensure all responses are JSON
 	e.Use(SetNoCacheHeaders)  // Prevent caching of API responses
 
+	// Latency and status code per route (Prometheus, see metrics.go)
+	e.Use(requestMetrics)
+
+	// In-flight requests are tracked so Shutdown can drain them (503 once draining)
+	if h.drain == nil {
+		h.drain = newDrainer()
+	}
+	e.Use(h.drainGuard(h.drain))
+
+	// Bounded request bodies (413) and per-route deadlines (408)
+	maxBody := cfg.MaxBodyBytes
+	if maxBody <= 0 {
+		maxBody = constants.MaxRequestBodyBytes
+	}
+	e.Use(h.bodyLimit(maxBody))
+	e.Use(h.routeTimeout(durationOr(cfg.RequestTimeout, constants.RequestTimeout), map[string]time.Duration{
+		"/v1/ai/ask":             durationOr(cfg.AITimeout, constants.AIRequestTimeout),
+		"/v1/quote":              durationOr(cfg.QuoteTimeout, constants.QuoteRequestTimeout),
+		"/v1/export/swaps":       durationOr(cfg.ExportTimeout, constants.ExportTimeout),
+		"/v1/swap/execute":       0, // detached; bounded by swapExecuteTimeout
+		"/v1/admin/pools/reload": 0, // may resolve every pool on-chain
+	}))
+
 	// Optional API key authentication
 	if cfg.APIKey != "" {
 		e.Use(middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
+			Skipper: func(c echo.Context) bool {
+				switch c.Path() {
+				case "/metrics", "/healthz", "/readyz": // Prometheus and orchestrator probes carry no key
+					return true
+				}
+				return false
+			},
 			KeyLookup: "header:X-API-Key", // Look for API key in X-API-Key header
 			Validator: func(key string, c echo.Context) (bool, error) {
-				return key == cfg.APIKey, nil // Simple string comparison
+				if key != cfg.APIKey && !slices.Contains(cfg.APIKeys, key) { // Simple string comparison
+					return false, nil
+				}
+				c.Set(apiKeyIDContextKey, flags.KeyFingerprint(key)) // Attribute writes (e.g. flag history) to the key
+				c.Set(apiKeyScopedContextKey, key != cfg.APIKey)     // API_KEYS are kept off privileged routes
+				return true, nil
 			},
 		}))
 	}
 
+	// Prometheus metrics of this process (indexer metrics too when run via cmd/all)
+	e.GET("/metrics", echo.WrapHandler(metrics.Default.Handler()))
+
+	// Liveness and readiness probes for orchestrators
+	e.GET("/healthz", h.Healthz) // Process is serving
+	e.GET("/readyz", h.Readyz)   // Redis reachable and ingestion not lagging (503 otherwise)
+
+	// GraphQL over ClickHouse: swaps, candles, pairs, tokens and wallet stats
+	e.GET("/graphql", h.GraphQL)
+	e.POST("/graphql", h.GraphQL)
+
+	// Dashboards poll these every second; the micro-cache collapses identical polls
+	hot := h.microCache(h.Responses, cfg.ResponseCacheTTL)
+
 	// API v1 routes
 	v1 := e.Group("/v1")
-	v1.GET("/health", h.Health)            // Health check endpoint
-	v1.POST("/echo", h.Echo)               // Echo endpoint for testing
-	v1.GET("/swaps/recent", h.RecentSwaps) // Recent swap events
-	v1.GET("/prices/:token", h.Price)      // Token price lookup
-	v1.GET("/quote", h.Quote)              // Jupiter quote proxy (for /swap)
+	v1.GET("/health", h.Health)                                          // Health check endpoint
+	v1.POST("/echo", h.Echo)                                             // Echo endpoint for testing
+	v1.GET("/swaps/recent", h.RecentSwaps, hot)                          // Recent swap events
+	v1.GET("/swaps/:signature", h.GetSwap)                               // One indexed swap, from Redis or ClickHouse
+	v1.GET("/prices/:token", h.Price)                                    // Token price lookup
+	v1.GET("/prices/:token/history", h.PriceHistory)                     // Rolling price history (sparklines)
+	v1.GET("/prices/:token/markets", h.PriceMarkets)                     // Per-venue prices, mid and spread
+	v1.GET("/quote", h.Quote)                                            // Jupiter quote proxy (for /swap)
+	v1.POST("/swap/execute", h.SwapExecute, h.requirePrimaryKey)         // Execute a swap (SWAP_API_ENABLED; honours Idempotency-Key)
+	v1.GET("/swap/risk-config", h.RiskConfigGet)                         // Swap engine risk limits in force
+	v1.PUT("/swap/risk-config", h.RiskConfigUpdate, h.requirePrimaryKey) // Tighten risk limits at runtime (engine.risk flag)
+	v1.GET("/pools", h.PoolsList)                                        // Swap engine pools with current reserves
+	v1.POST("/tx/simulate", h.TxSimulate)                                // Simulate a client-built transaction on our RPC node
+	v1.POST("/tx/send", h.TxSend, h.requirePrimaryKey)                   // Relay a signed transaction and track its confirmation
+
+	// Wallet profiles aggregate ClickHouse; results are shared for WalletStatsCacheTTL
+	walletCache := h.microCache(h.Responses, cfg.WalletStatsCacheTTL)
+	v1.GET("/wallets/top", h.TopWallets, walletCache)             // Top traders by volume or trade count
+	v1.GET("/wallets/:address/stats", h.WalletStats, walletCache) // Favourite pairs, trade size, activity heatmap
+
+	v1.GET("/arb/opportunities", h.ArbOpportunities) // Cross-DEX price gaps from the arbitrage detector
+	v1.GET("/anomalies", h.RecentAnomalies)          // Hourly volume and trade-count spikes
+	v1.GET("/mev/stats", h.MEVStats)                 // Sandwich attacks found by ssi mev
+	v1.GET("/fees/stats", h.FeeStats)                // Average landing cost per DEX and pair
 
-	// AI endpoints with rate limiting
+	// Listings scan many stored swaps; results are shared for MarketsCacheTTL
+	marketsCache := h.microCache(h.Responses, cfg.MarketsCacheTTL)
+	v1.GET("/pairs", h.ListPairs, marketsCache) // Every indexed pair: first/last seen, DEXes, 24h activity
+	v1.GET("/dexes", h.DexStats, marketsCache)  // Per-DEX trades, volume, average size and market share
+
+	v1.GET("/export/swaps", h.ExportSwaps) // Stored swaps streamed as NDJSON or CSV
+
+	// Pairs and tokens an API key follows: webhook routing and digests
+	v1.GET("/subscriptions", h.SubscriptionsList)          // The calling key's subscriptions
+	v1.POST("/subscriptions", h.SubscriptionsAdd)          // Subscribe to a pair or token (replaces one to the same)
+	v1.DELETE("/subscriptions/:id", h.SubscriptionsDelete) // Unsubscribe
+
+	// AI endpoints, rate limited per API key (per IP without keys)
 	aigroup := v1.Group("/ai")
-	aigroup.Use(middleware.RateLimiter(middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
-		Rate:      rate.Limit(0.2), // 1 request every 5 seconds
-		Burst:     2,               // Allow burst of 2 requests
-		ExpiresIn: 2 * time.Minute, // Rate limit window
-	})))
+	if h.AIRate != nil {
+		aigroup.Use(h.aiRateLimit(h.AIRate))
+	} else {
+		aiRate, aiBurst := cfg.AIRateLimit, cfg.AIRateBurst
+		if aiRate <= 0 {
+			aiRate = constants.AIRateLimit
+		}
+		if aiBurst <= 0 {
+			aiBurst = constants.AIRateBurst
+		}
+		aigroup.Use(middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+			Store: middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+				Rate:      rate.Limit(aiRate), // Requests per second (AI_RATE_LIMIT)
+				Burst:     aiBurst,            // Burst allowance (AI_RATE_BURST)
+				ExpiresIn: 2 * time.Minute,    // Rate limit window
+			}),
+			IdentifierExtractor: func(c echo.Context) (string, error) { return aiClient(c), nil },
+		}))
+	}
 	aigroup.POST("/ask", h.AIAsk) // Natural language to SQL endpoint
 
-	// Feature flags CRUD endpoints
-	flagGroup := v1.Group("/flags")
-	flagGroup.GET("", h.FlagsList)           // List all flags
-	flagGroup.POST("", h.FlagsUpsert)        // Create new flag
-	flagGroup.GET("/:key", h.FlagsGet)       // Get specific flag
-	flagGroup.PUT("/:key", h.FlagsUpdate)    // Update existing flag
-	flagGroup.DELETE("/:key", h.FlagsDelete) // Delete flag
+	// Feature flags CRUD endpoints (API_KEY only, like admin below)
+	flagGroup := v1.Group("/flags", h.requirePrimaryKey, h.requireFlags)
+	flagGroup.GET("", h.FlagsList)                 // List all flags
+	flagGroup.POST("", h.FlagsUpsert)              // Create new flag
+	flagGroup.GET("/:key", h.FlagsGet)             // Get specific flag
+	flagGroup.PUT("/:key", h.FlagsUpdate)          // Update existing flag
+	flagGroup.DELETE("/:key", h.FlagsDelete)       // Delete flag
+	flagGroup.GET("/:key/history", h.FlagsHistory) // Audit history of a flag
+
+	// Admin endpoints
+	adminGroup := v1.Group("/admin", h.requirePrimaryKey)
+	adminGroup.POST("/config/reload", h.ConfigReload)                       // Broadcast config reload to running services
+	adminGroup.GET("/indexer/status", h.IndexerStatus)                      // Throughput, parse rates and slot lag per indexer replica
+	adminGroup.GET("/indexer/latency", h.IndexerLatency)                    // Block time to indexing latency per stream provider
+	adminGroup.GET("/indexer/programs", h.IndexerPrograms)                  // Programs added/removed at runtime
+	adminGroup.POST("/indexer/programs", h.IndexerProgramAdd)               // Start polling a program on every indexer
+	adminGroup.DELETE("/indexer/programs/:address", h.IndexerProgramRemove) // Stop polling a program on every indexer
+	adminGroup.POST("/pools/reload", h.PoolsReload)                         // Re-read the swap engine pool config
 
 	// Catch-all route for 404 responses
 	e.RouteNotFound("/*", func(c echo.Context) error {
-		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "not found", Code: http.StatusNotFound})
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "not found", Code: http.StatusNotFound, Category: string(apperr.NotFound)})
 	})
 }
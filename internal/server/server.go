@@ -2,17 +2,45 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
 	"time"
 
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 // ServerConfig holds configuration for the HTTP server
 type ServerConfig struct {
-	Addr    string // Server bind address (e.g., ":8090")
-	DevMode bool   // Enable development mode (detailed error responses)
-	APIKey  string // Optional API key for authentication
+	Addr    string   // Server bind address (e.g., ":8090")
+	DevMode bool     // Enable development mode (detailed error responses)
+	APIKey  string   // Optional API key for authentication
+	APIKeys []string // Further keys, each with its own AI rate and budget; kept off the privileged routes
+
+	AIRateLimit float64 // Requests per second per client on /v1/ai without Handlers.AIRate (default: constants.AIRateLimit)
+	AIRateBurst int     // Burst allowance on /v1/ai without Handlers.AIRate (default: constants.AIRateBurst)
+
+	ResponseCacheTTL    time.Duration // Hot read endpoints answered from Handlers.Responses this long (0: off)
+	WalletStatsCacheTTL time.Duration // /v1/wallets responses answered from Handlers.Responses this long (0: off)
+	MarketsCacheTTL     time.Duration // /v1/pairs and /v1/dexes responses answered from Handlers.Responses this long (0: off)
+
+	MaxBodyBytes   int64         // Larger request bodies get 413 (default: constants.MaxRequestBodyBytes)
+	RequestTimeout time.Duration // Per-request deadline, 408 past it (default: constants.RequestTimeout)
+	AITimeout      time.Duration // Deadline on /v1/ai/ask (default: constants.AIRequestTimeout)
+	QuoteTimeout   time.Duration // Deadline on /v1/quote (default: constants.QuoteRequestTimeout)
+	ExportTimeout  time.Duration // Deadline on /v1/export/swaps (default: constants.ExportTimeout)
+
+	// Native HTTPS: either a certificate pair or Let's Encrypt for AutocertHosts
+	TLSCertFile      string   // PEM certificate (chain) file
+	TLSKeyFile       string   // PEM private key file
+	AutocertHosts    []string // Host names certificates may be requested for
+	AutocertCacheDir string   // Where issued certificates are kept (default: certs)
+	AutocertEmail    string   // Contact address for the ACME account (optional)
+	RedirectAddr     string   // Plain HTTP listener redirecting to HTTPS and answering ACME challenges (optional)
 }
 
 // ServerDeps contains dependencies required to create a new Server
@@ -23,9 +51,11 @@ type ServerDeps struct {
 
 // Server wraps Echo HTTP server with additional lifecycle management
 type Server struct {
-	e      *echo.Echo
-	cfg    ServerConfig
-	closed chan struct{} // Channel to signal server shutdown completion
+	e        *echo.Echo
+	h        *Handlers
+	cfg      ServerConfig
+	redirect *http.Server  // HTTP to HTTPS redirect listener (nil unless RedirectAddr is set with TLS)
+	closed   chan struct{} // Channel to signal server shutdown completion
 }
 
 // NewServer creates a new HTTP server with the given dependencies
@@ -37,29 +67,76 @@ func NewServer(deps ServerDeps) (*Server, error) {
 
 	// Add standard middleware for recovery and request logging
 	e.Use(middleware.Recover())
-	e.Use(middleware.Logger())
+	e.Use(middleware.RequestID())
+	e.Use(requestLogger(deps.Handlers.Logger))
 
 	// Configure server timeouts for robustness
 	e.Server.ReadTimeout = 15 * time.Second  // Max time to read request headers
 	e.Server.WriteTimeout = 75 * time.Second // Max time to write response
-	e.Server.IdleTimeout = 60 * time.Second  // Max time to wait for next request
+	// The response of the slowest route must still fit in the write deadline
+	cfg := deps.Config
+	if longest := max(cfg.RequestTimeout, cfg.AITimeout, cfg.QuoteTimeout) + 15*time.Second; longest > e.Server.WriteTimeout {
+		e.Server.WriteTimeout = longest
+	}
+	e.Server.IdleTimeout = 60 * time.Second // Max time to wait for next request
+	// The HTTPS listener gets the same limits
+	e.TLSServer.ReadTimeout = e.Server.ReadTimeout
+	e.TLSServer.WriteTimeout = e.Server.WriteTimeout
+	e.TLSServer.IdleTimeout = e.Server.IdleTimeout
+
+	cfg.AutocertHosts = normalizeHosts(cfg.AutocertHosts)
+	srv := &Server{e: e, h: deps.Handlers, cfg: cfg, closed: make(chan struct{})}
+	var acme *autocert.Manager
+	if cfg.TLSCertFile == "" && len(cfg.AutocertHosts) > 0 {
+		acme = &e.AutoTLSManager
+		configureAutocert(acme, cfg)
+	}
+	if cfg.RedirectAddr != "" && cfg.TLSEnabled() {
+		srv.redirect = newRedirectServer(cfg.RedirectAddr, cfg.Addr, acme)
+	}
 
 	h := deps.Handlers
 	RegisterRoutes(e, h, deps.Config)
 
-	return &Server{e: e, cfg: deps.Config, closed: make(chan struct{})}, nil
+	return srv, nil
 }
 
-// Start begins serving HTTP requests on the configured address
+// Start begins serving requests on the configured address: HTTPS with the
+// certificate files, HTTPS with autocert, or plain HTTP
 func (s *Server) Start() error {
-	return s.e.Start(s.cfg.Addr)
+	if s.redirect != nil {
+		ln, err := net.Listen("tcp", s.redirect.Addr)
+		if err != nil {
+			return fmt.Errorf("redirect listener: %w", err)
+		}
+		go func() { _ = s.redirect.Serve(ln) }()
+	}
+
+	switch {
+	case s.cfg.TLSCertFile != "":
+		return s.e.StartTLS(s.cfg.Addr, s.cfg.TLSCertFile, s.cfg.TLSKeyFile)
+	case len(s.cfg.AutocertHosts) > 0:
+		return s.e.StartAutoTLS(s.cfg.Addr)
+	default:
+		return s.e.Start(s.cfg.Addr)
+	}
 }
 
-// Shutdown gracefully shuts down the server with a 10-second timeout
+// Shutdown gracefully shuts down the server with a 10-second timeout. New
+// requests get 503 while open streams are closed and in-flight requests
+// finish; the listeners are closed after that.
 func (s *Server) Shutdown(ctx context.Context) error {
 	defer close(s.closed) // Signal that shutdown is complete
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
+	if s.h != nil && s.h.drain != nil {
+		if err := s.h.drain.drain(ctx); err != nil && s.h.Logger != nil {
+			s.h.Logger.WithError(err).Warn("shutdown: in-flight requests still running")
+		}
+	}
+	if s.redirect != nil {
+		_ = s.redirect.Shutdown(ctx)
+	}
 	return s.e.Shutdown(ctx)
 }
 
@@ -88,3 +165,33 @@ func SetJSONContentType(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+// requestLogger puts the request id (X-Request-Id, from the client or
+// generated by middleware.RequestID) in the request context's log fields and
+// logs each request once handled
+func requestLogger(logger *logrus.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+			id := c.Response().Header().Get(echo.HeaderXRequestID)
+			ctx := logging.WithFields(req.Context(), logrus.Fields{logging.FieldRequestID: id})
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+			if err != nil {
+				c.Error(err) // settle the status code now; the error is handled
+			}
+			if logger != nil {
+				logging.Entry(ctx, logger).WithFields(logrus.Fields{
+					"method":     req.Method,
+					"uri":        req.RequestURI,
+					"status":     c.Response().Status,
+					"latency_ms": time.Since(start).Milliseconds(),
+					"remote_ip":  c.RealIP(),
+				}).Info("request")
+			}
+			return nil
+		}
+	}
+}
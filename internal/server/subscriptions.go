@@ -0,0 +1,115 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// subscriptionOwner is who a /v1/subscriptions request acts for: the
+// fingerprint of its API key, or one shared owner when the API runs
+// without keys
+func subscriptionOwner(c echo.Context) string {
+	if id, ok := c.Get(apiKeyIDContextKey).(string); ok && id != "" {
+		return "key:" + id
+	}
+	return "anonymous"
+}
+
+// SubscriptionsList lists the subscriptions of the calling API key
+func (h *Handlers) SubscriptionsList(c echo.Context) error {
+	if h.Subscriptions == nil {
+		return h.err(c, http.StatusBadRequest, "subscriptions are not configured", nil)
+	}
+	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	subs, err := h.Subscriptions.ListSubscriptions(ctx, subscriptionOwner(c))
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to list subscriptions", err)
+	}
+	return c.JSON(http.StatusOK, SubscriptionsResponse{Subscriptions: subs, Limit: h.subscriptionsMax()})
+}
+
+// SubscriptionsAdd subscribes the calling API key to a pair or a token,
+// replacing its earlier subscription to the same one
+func (h *Handlers) SubscriptionsAdd(c echo.Context) error {
+	if h.Subscriptions == nil {
+		return h.err(c, http.StatusBadRequest, "subscriptions are not configured", nil)
+	}
+	var req SubscriptionRequest
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+	req.Pair, req.Token = strings.ToUpper(strings.TrimSpace(req.Pair)), strings.TrimSpace(req.Token)
+	if (req.Pair == "") == (req.Token == "") {
+		return h.invalidField(c, "body", "required", "set exactly one of pair and token")
+	}
+	if req.WebhookURL != "" {
+		if err := h.Webhooks.CheckURL(req.WebhookURL); err != nil {
+			return h.invalidField(c, "webhook_url", "webhook_url", err.Error())
+		}
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	sub := &models.Subscription{
+		Pair:       req.Pair,
+		Token:      req.Token,
+		WebhookURL: req.WebhookURL,
+		Digest:     req.Digest,
+		CreatedAt:  time.Now().UTC(),
+	}
+	limit := h.subscriptionsMax()
+	ok, err := h.Subscriptions.AddSubscription(ctx, subscriptionOwner(c), sub, limit)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to add subscription", err)
+	}
+	if !ok {
+		return h.writeErr(c, ErrorResponse{
+			Error: fmt.Sprintf("subscription limit reached (%d per API key)", limit),
+			Code:  http.StatusConflict,
+			Hint:  "delete a subscription first",
+		})
+	}
+
+	h.log(c).WithFields(logrus.Fields{"subscription": sub.ID, "target": sub.Target()}).Info("subscription added")
+	return c.JSON(http.StatusOK, sub)
+}
+
+// SubscriptionsDelete removes one of the calling API key's subscriptions
+func (h *Handlers) SubscriptionsDelete(c echo.Context) error {
+	if h.Subscriptions == nil {
+		return h.err(c, http.StatusBadRequest, "subscriptions are not configured", nil)
+	}
+	var req SubscriptionIDRequest
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	ok, err := h.Subscriptions.DeleteSubscription(ctx, subscriptionOwner(c), req.ID)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to delete subscription", err)
+	}
+	if !ok {
+		return h.err(c, http.StatusNotFound, "subscription not found", nil)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handlers) subscriptionsMax() int {
+	if h.SubscriptionsMax <= 0 {
+		return constants.SubscriptionsMaxPerKey
+	}
+	return h.SubscriptionsMax
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSubscriptions keeps subscriptions by owner, then ID (the target)
+type fakeSubscriptions map[string]map[string]*models.Subscription
+
+func (f fakeSubscriptions) ListSubscriptions(_ context.Context, owner string) ([]*models.Subscription, error) {
+	out := []*models.Subscription{}
+	for _, s := range f[owner] {
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (f fakeSubscriptions) AddSubscription(_ context.Context, owner string, sub *models.Subscription, limit int) (bool, error) {
+	sub.ID = sub.Target()
+	if _, ok := f[owner][sub.ID]; !ok && len(f[owner]) >= limit {
+		return false, nil
+	}
+	if f[owner] == nil {
+		f[owner] = map[string]*models.Subscription{}
+	}
+	f[owner][sub.ID] = sub
+	return true, nil
+}
+
+func (f fakeSubscriptions) DeleteSubscription(_ context.Context, owner, id string) (bool, error) {
+	_, ok := f[owner][id]
+	delete(f[owner], id)
+	return ok, nil
+}
+
+func (f fakeSubscriptions) AllSubscriptions(context.Context) (map[string][]*models.Subscription, error) {
+	return nil, nil
+}
+
+func TestSubscriptions(t *testing.T) {
+	store := fakeSubscriptions{}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Subscriptions: store, SubscriptionsMax: 2}, ServerConfig{APIKey: "alpha", APIKeys: []string{"beta"}})
+
+	do := func(method, path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	list := func(key string) (out SubscriptionsResponse) {
+		rec := do(http.MethodGet, "/v1/subscriptions", key, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+		return out
+	}
+
+	rec := do(http.MethodPost, "/v1/subscriptions", "alpha", `{"pair":"sol/usdc","webhook_url":"https://example.com/hook","digest":"hourly"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var sub models.Subscription
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sub))
+	assert.Equal(t, "SOL/USDC", sub.Pair)
+	assert.Equal(t, "pair:SOL/USDC", sub.ID)
+	assert.False(t, sub.CreatedAt.IsZero())
+
+	// subscribing again replaces the subscription and does not count twice
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/v1/subscriptions", "alpha", `{"pair":"SOL/USDC"}`).Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/v1/subscriptions", "alpha", `{"token":"BONK"}`).Code)
+	rec = do(http.MethodPost, "/v1/subscriptions", "alpha", `{"token":"JUP"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code, "past the limit")
+
+	got := list("alpha")
+	assert.Len(t, got.Subscriptions, 2)
+	assert.Equal(t, 2, got.Limit)
+	assert.Empty(t, list("beta").Subscriptions, "each key has its own subscriptions")
+
+	for _, bad := range []string{
+		`{}`,
+		`{"pair":"SOL/USDC","token":"BONK"}`,
+		`{"pair":"not a pair"}`,
+		`{"token":"BONK","webhook_url":"ftp://example.com"}`,
+		`{"token":"BONK","webhook_url":"http://127.0.0.1:6379"}`,
+		`{"token":"BONK","webhook_url":"http://[fd00::1]/hook"}`,
+		`{"token":"BONK","digest":"weekly"}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/v1/subscriptions", "beta", bad).Code, bad)
+	}
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/v1/subscriptions/token:BONK", "beta", "").Code)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/v1/subscriptions/token:BONK", "alpha", "").Code)
+	assert.Len(t, list("alpha").Subscriptions, 1)
+
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{})
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/idempotency"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
+	"github.com/gagliardetto/solana-go"
+	"github.com/labstack/echo/v4"
+)
+
+// maxSwapRequestBytes bounds the body of POST /v1/swap/execute
+const maxSwapRequestBytes = 64 << 10
+
+// swapExecuteTimeout covers quoting, simulation, sending and confirmation
+const swapExecuteTimeout = 60 * time.Second
+
+// SwapExecutor executes swaps on-chain (implemented by *swapengine.Engine)
+type SwapExecutor interface {
+	ExecuteAISwap(ctx context.Context, intent *swapengine.SwapIntent) (*swapengine.SwapResult, error)
+}
+
+// IdempotencyStore remembers the response of each Idempotency-Key
+// (implemented by *idempotency.Store)
+type IdempotencyStore interface {
+	Begin(ctx context.Context, key, fingerprint string) (*idempotency.Record, error)
+	Complete(ctx context.Context, key, fingerprint string, status int, body []byte) error
+	Release(ctx context.Context, key string) error
+}
+
+// SwapExecute executes a swap through the swap engine. With an
+// Idempotency-Key header the response is stored, and retries with the same
+// key and body replay it (Idempotent-Replayed: true) instead of sending a
+// second transaction.
+func (h *Handlers) SwapExecute(c echo.Context) error {
+	if h.Swaps == nil {
+		return h.err(c, http.StatusBadRequest, "swap execution is not enabled", nil)
+	}
+
+	// the raw body is kept to fingerprint the request for its Idempotency-Key
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxSwapRequestBytes+1))
+	if err != nil || len(body) > maxSwapRequestBytes {
+		return h.invalidField(c, "body", "json", "invalid json")
+	}
+	var req SwapExecuteRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return h.invalidField(c, "body", "json", "invalid json")
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.invalid(c, err)
+	}
+	req.InputToken = normalizeToken(req.InputToken)
+	req.OutputToken = normalizeToken(req.OutputToken)
+
+	key := strings.TrimSpace(c.Request().Header.Get("Idempotency-Key"))
+	var fingerprint string
+	if key != "" {
+		if h.Idempotency == nil {
+			return h.err(c, http.StatusBadRequest, "idempotency keys are not configured", nil)
+		}
+		if err := idempotency.ValidateKey(key); err != nil {
+			return h.invalidField(c, "Idempotency-Key", "format", err.Error())
+		}
+		// keys are per API key, so two clients cannot collide or read each other's results
+		if id, ok := c.Get(apiKeyIDContextKey).(string); ok && id != "" {
+			key = id + ":" + key
+		}
+		sum := sha256.Sum256(body)
+		fingerprint = hex.EncodeToString(sum[:])
+
+		ctx, cancel := h.withTimeout(c.Request().Context(), 3*time.Second)
+		rec, err := h.Idempotency.Begin(ctx, key, fingerprint)
+		cancel()
+		switch {
+		case errors.Is(err, idempotency.ErrInProgress):
+			return h.err(c, http.StatusConflict, "request with this idempotency key is in progress", nil)
+		case errors.Is(err, idempotency.ErrMismatch):
+			return h.err(c, http.StatusUnprocessableEntity, "idempotency key reused with a different request", nil)
+		case err != nil:
+			// without the record a retry could execute twice, so refuse rather than run unprotected
+			return h.err(c, http.StatusServiceUnavailable, "idempotency store unavailable", map[string]any{"err": err.Error()})
+		case rec != nil:
+			c.Response().Header().Set("Idempotent-Replayed", "true")
+			return c.JSONBlob(rec.Status, rec.Body)
+		}
+	}
+
+	// A client that disconnects must not abort a swap half way; it retries with the same key instead
+	ctx, cancel := h.withTimeout(context.WithoutCancel(c.Request().Context()), swapExecuteTimeout)
+	defer cancel()
+
+	status, resp, executed := h.executeSwap(ctx, &swapengine.SwapIntent{
+		InputToken:  req.InputToken,
+		OutputToken: req.OutputToken,
+		Amount:      req.Amount,
+		SlippageBps: req.SlippageBps,
+		Reason:      req.Reason,
+		RequestedAt: time.Now(),
+	})
+	out, err := json.Marshal(resp)
+	if err != nil {
+		return h.err(c, http.StatusInternalServerError, "failed to encode response", nil)
+	}
+
+	switch {
+	case key == "":
+	case !executed:
+		// refused before anything ran (kill switch): a retry with the same key may try again
+		_ = h.Idempotency.Release(ctx, key)
+	default:
+		if err := h.Idempotency.Complete(ctx, key, fingerprint, status, out); err != nil && h.Logger != nil {
+			// the key stays in progress until it expires, so retries get 409 rather than a second swap
+			h.log(c).WithError(err).WithField("idempotency_key", key).Error("failed to store idempotent response")
+		}
+	}
+	return c.JSONBlob(status, out)
+}
+
+// executeSwap runs the intent and maps the outcome to a status and body;
+// executed is false when the engine refused the swap without trying it
+func (h *Handlers) executeSwap(ctx context.Context, intent *swapengine.SwapIntent) (status int, body any, executed bool) {
+	res, err := h.Swaps.ExecuteAISwap(ctx, intent)
+	if errors.Is(err, swapengine.ErrKillSwitch) {
+		return http.StatusServiceUnavailable, h.errResponse(http.StatusServiceUnavailable, "swap execution disabled by kill switch", nil), false
+	}
+	if res == nil {
+		if err == nil {
+			err = errors.New("no result")
+		}
+		// an intent the engine refuses before sending anything is the caller's to fix
+		status, code := http.StatusBadGateway, apperr.CodeOf(err)
+		if code == apperr.ValidationFailed || code == apperr.RiskRejected {
+			status = code.HTTPStatus()
+		}
+		resp := h.errResponse(status, "swap execution failed", map[string]any{"err": err.Error()})
+		resp.Category = string(code)
+		return status, resp, true
+	}
+
+	resp := SwapExecuteResponse{
+		ExecutionID: res.ExecutionID,
+		Signature:   res.Signature,
+		Success:     res.Success,
+		Error:       res.Error,
+		ErrorKind:   string(res.ErrorKind),
+		ExpectedOut: res.ExpectedOut,
+		ActualOut:   res.ActualOut,
+		DurationMs:  res.Duration.Milliseconds(),
+	}
+	if res.ErrorKind != "" && res.ErrorCode >= 0 {
+		resp.ErrorCode = &res.ErrorCode
+	}
+	if err != nil {
+		// a transaction may have been sent; the signature tells the client what to look up
+		if resp.Error == "" {
+			resp.Error = err.Error()
+		}
+		if errors.Is(err, apperr.RiskRejected) {
+			return http.StatusUnprocessableEntity, resp, true
+		}
+		return http.StatusBadGateway, resp, true
+	}
+	return http.StatusOK, resp, true
+}
+
+// normalizeToken upper-cases a token symbol; mint addresses are
+// case-sensitive and kept as given
+func normalizeToken(s string) string {
+	s = strings.TrimSpace(s)
+	if _, err := solana.PublicKeyFromBase58(s); err == nil {
+		return s
+	}
+	return strings.ToUpper(s)
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/idempotency"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExecutor struct {
+	calls int
+	err   error
+}
+
+func (f *fakeExecutor) ExecuteAISwap(_ context.Context, intent *swapengine.SwapIntent) (*swapengine.SwapResult, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &swapengine.SwapResult{ExecutionID: "exec-1", Signature: "sig-1", Success: true, ExpectedOut: 42}, nil
+}
+
+// memIdempotency mirrors idempotency.Store without Redis
+type memIdempotency struct {
+	mu   sync.Mutex
+	recs map[string]*idempotency.Record
+}
+
+func (m *memIdempotency) Begin(_ context.Context, key, fp string) (*idempotency.Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rec, ok := m.recs[key]
+	if !ok {
+		m.recs[key] = &idempotency.Record{Fingerprint: fp}
+		return nil, nil
+	}
+	if rec.Fingerprint != fp {
+		return nil, idempotency.ErrMismatch
+	}
+	if !rec.Completed() {
+		return nil, idempotency.ErrInProgress
+	}
+	return rec, nil
+}
+
+func (m *memIdempotency) Complete(_ context.Context, key, fp string, status int, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recs[key] = &idempotency.Record{Fingerprint: fp, Status: status, Body: body}
+	return nil
+}
+
+func (m *memIdempotency) Release(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.recs, key)
+	return nil
+}
+
+func postSwap(e *echo.Echo, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/swap/execute", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSwapExecuteIdempotency(t *testing.T) {
+	exec := &fakeExecutor{}
+	store := &memIdempotency{recs: map[string]*idempotency.Record{}}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Swaps: exec, Idempotency: store}, ServerConfig{})
+
+	body := `{"input_token":"sol","output_token":"usdc","amount":0.1}`
+	first := postSwap(e, "k-1", body)
+	require.Equal(t, http.StatusOK, first.Code, first.Body.String())
+	assert.Contains(t, first.Body.String(), `"signature":"sig-1"`)
+
+	retry := postSwap(e, "k-1", body)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, 1, exec.calls, "a retry must not execute again")
+
+	other := postSwap(e, "k-1", `{"input_token":"sol","output_token":"usdc","amount":0.2}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, other.Code)
+
+	sum := sha256.Sum256([]byte(body))
+	store.recs["k-busy"] = &idempotency.Record{Fingerprint: hex.EncodeToString(sum[:])}
+	assert.Equal(t, http.StatusConflict, postSwap(e, "k-busy", body).Code)
+
+	assert.Equal(t, http.StatusBadRequest, postSwap(e, "bad key", body).Code)
+
+	// without a key every request executes
+	postSwap(e, "", body)
+	postSwap(e, "", body)
+	assert.Equal(t, 3, exec.calls)
+}
+
+func TestSwapExecuteKillSwitchReleasesKey(t *testing.T) {
+	exec := &fakeExecutor{err: swapengine.ErrKillSwitch}
+	store := &memIdempotency{recs: map[string]*idempotency.Record{}}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Swaps: exec, Idempotency: store}, ServerConfig{})
+
+	body := `{"input_token":"SOL","output_token":"USDC","amount":1}`
+	assert.Equal(t, http.StatusServiceUnavailable, postSwap(e, "k-2", body).Code)
+	assert.Empty(t, store.recs, "refused swaps are not recorded")
+
+	exec.err = nil
+	assert.Equal(t, http.StatusOK, postSwap(e, "k-2", body).Code)
+	assert.Equal(t, 2, exec.calls)
+}
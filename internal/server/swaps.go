@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/labstack/echo/v4"
+)
+
+// SwapLookup finds a stored swap by transaction signature
+// (implemented by *cache.ClickHouseStore)
+type SwapLookup interface {
+	GetSwap(ctx context.Context, signature string) (*models.SwapEvent, error)
+}
+
+// SignatureChecker reports where a transaction stands on chain
+// (implemented by *rpc.Client)
+type SignatureChecker interface {
+	GetSignatureStatus(ctx context.Context, signature string) (*rpc.SignatureStatus, error)
+}
+
+// GetSwap returns the swap of one transaction. The recent swaps in Redis are
+// searched first, then ClickHouse. When neither holds it, the 404 carries a
+// hint saying whether the transaction landed on chain.
+func (h *Handlers) GetSwap(c echo.Context) error {
+	var req SwapRequest
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	// A cache miss or outage still leaves ClickHouse to ask
+	if recent, err := h.Cache.GetRecentSwaps(ctx, int64(h.recentSearched())); err == nil {
+		for _, swap := range recent {
+			if swap != nil && swap.Signature == req.Signature {
+				return c.JSON(http.StatusOK, SwapResponse{Source: "cache", Swap: swap})
+			}
+		}
+	}
+
+	if h.SwapStore != nil {
+		swap, err := h.SwapStore.GetSwap(ctx, req.Signature)
+		if err != nil {
+			return h.fail(c, http.StatusInternalServerError, "failed to get swap", err)
+		}
+		if swap != nil {
+			return c.JSON(http.StatusOK, SwapResponse{Source: "store", Swap: swap})
+		}
+	}
+
+	return c.JSON(http.StatusNotFound, ErrorResponse{
+		Error:    "swap not found",
+		Code:     http.StatusNotFound,
+		Category: string(apperr.NotFound),
+		Hint:     h.notIndexedHint(ctx, req.Signature),
+	})
+}
+
+// notIndexedHint explains why a signature has no indexed swap, from its
+// status on chain; "" when the chain cannot be asked
+func (h *Handlers) notIndexedHint(ctx context.Context, signature string) string {
+	if h.Chain == nil {
+		return ""
+	}
+	status, err := h.Chain.GetSignatureStatus(ctx, signature)
+	switch {
+	case err != nil:
+		if h.Logger != nil {
+			logging.Entry(ctx, h.Logger).WithError(err).Warn("failed to check signature status")
+		}
+		return ""
+	case status == nil:
+		return "no transaction with this signature was found on chain"
+	case status.Err != nil:
+		return fmt.Sprintf("the transaction failed on chain in slot %d, so it swapped nothing", status.Slot)
+	case h.SwapStore == nil:
+		return fmt.Sprintf("the transaction landed in slot %d; without ClickHouse only the last %d swaps can be looked up", status.Slot, h.recentSearched())
+	default:
+		return fmt.Sprintf("the transaction landed in slot %d but was not indexed: it is not a swap on a polled program, or the indexer has not reached it yet", status.Slot)
+	}
+}
+
+// recentSearched is the number of recent swaps searched by signature
+func (h *Handlers) recentSearched() int {
+	if h.MaxRecentSwaps > 0 {
+		return h.MaxRecentSwaps
+	}
+	return constants.MaxRecentSwaps
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/gagliardetto/solana-go"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSwapStore map[string]*models.SwapEvent
+
+func (f fakeSwapStore) GetSwap(_ context.Context, signature string) (*models.SwapEvent, error) {
+	return f[signature], nil
+}
+
+type fakeChain map[string]*rpc.SignatureStatus
+
+func (f fakeChain) GetSignatureStatus(_ context.Context, signature string) (*rpc.SignatureStatus, error) {
+	return f[signature], nil
+}
+
+func TestGetSwap(t *testing.T) {
+	sig := func(b byte) string { return solana.Signature{b}.String() }
+	recent, stored, landed, failed, unknown := sig(1), sig(2), sig(3), sig(4), sig(5)
+
+	mem := cache.NewMemoryCache(10, 0)
+	require.NoError(t, mem.AddRecentSwap(context.Background(), &models.SwapEvent{Signature: recent, Pair: "SOL/USDC", Wallet: "w1"}))
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{
+		Cache:     mem,
+		SwapStore: fakeSwapStore{stored: {Signature: stored, Pair: "BONK/SOL", PriorityFee: 5000}},
+		Chain: fakeChain{
+			landed: {Slot: 42},
+			failed: {Slot: 43, Err: map[string]any{"InstructionError": []any{2, "Custom"}}},
+		},
+	}, ServerConfig{})
+
+	lookup := func(signature string) (int, SwapResponse, ErrorResponse) {
+		rec := get(t, e, "/v1/swaps/"+signature, "")
+		var ok SwapResponse
+		var fail ErrorResponse
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &ok))
+		} else {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &fail))
+		}
+		return rec.Code, ok, fail
+	}
+
+	code, resp, _ := lookup(recent)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "cache", resp.Source)
+	assert.Equal(t, "w1", resp.Swap.Wallet)
+
+	code, resp, _ = lookup(stored)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "store", resp.Source)
+	assert.EqualValues(t, 5000, resp.Swap.PriorityFee)
+
+	code, _, fail := lookup(landed)
+	require.Equal(t, http.StatusNotFound, code)
+	assert.Contains(t, fail.Hint, "landed in slot 42 but was not indexed")
+
+	_, _, fail = lookup(failed)
+	assert.Contains(t, fail.Hint, "failed on chain in slot 43")
+
+	_, _, fail = lookup(unknown)
+	assert.Contains(t, fail.Hint, "no transaction with this signature")
+
+	code, _, _ = lookup("not-a-signature")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, http.StatusOK, get(t, e, "/v1/swaps/recent", "").Code, "static route wins over the signature")
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSEnabled reports whether the server terminates TLS itself
+func (c ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" || len(c.AutocertHosts) > 0
+}
+
+// configureAutocert makes m obtain and renew Let's Encrypt certificates for
+// the allowed hosts only, caching them on disk across restarts
+func configureAutocert(m *autocert.Manager, cfg ServerConfig) {
+	dir := cfg.AutocertCacheDir
+	if dir == "" {
+		dir = constants.AutocertCacheDir
+	}
+	m.Prompt = autocert.AcceptTOS
+	m.HostPolicy = autocert.HostWhitelist(cfg.AutocertHosts...)
+	m.Cache = autocert.DirCache(dir)
+	m.Email = cfg.AutocertEmail
+}
+
+// newRedirectServer serves plain HTTP on addr: ACME http-01 challenges when
+// m is set, and a permanent redirect to the HTTPS listener for everything else
+func newRedirectServer(addr, tlsAddr string, m *autocert.Manager) *http.Server {
+	_, port, _ := net.SplitHostPort(tlsAddr)
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+
+	var handler http.Handler = redirect
+	if m != nil {
+		handler = m.HTTPHandler(redirect)
+	}
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		IdleTimeout:       30 * time.Second,
+	}
+}
+
+// normalizeHosts lower-cases and trims autocert host names, dropping empties
+func normalizeHosts(hosts []string) []string {
+	var out []string
+	for _, h := range hosts {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			out = append(out, h)
+		}
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirectServer(t *testing.T) {
+	cases := []struct{ tlsAddr, host, want string }{
+		{":443", "api.example.com", "https://api.example.com/v1/swaps/recent?limit=5"},
+		{":443", "api.example.com:80", "https://api.example.com/v1/swaps/recent?limit=5"},
+		{":8443", "api.example.com:8080", "https://api.example.com:8443/v1/swaps/recent?limit=5"},
+	}
+	for _, tc := range cases {
+		srv := newRedirectServer(":80", tc.tlsAddr, nil)
+		req := httptest.NewRequest(http.MethodGet, "/v1/swaps/recent?limit=5", nil)
+		req.Host = tc.host
+		rec := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+		assert.Equal(t, tc.want, rec.Header().Get("Location"))
+	}
+}
+
+func TestServerStartTLS(t *testing.T) {
+	certFile, keyFile := writeSelfSigned(t)
+	srv, err := NewServer(ServerDeps{
+		Handlers: &Handlers{},
+		Config:   ServerConfig{Addr: "127.0.0.1:0", TLSCertFile: certFile, TLSKeyFile: keyFile},
+	})
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Start() }()
+	t.Cleanup(func() {
+		require.NoError(t, srv.Shutdown(context.Background()))
+		assert.ErrorIs(t, <-done, http.ErrServerClosed)
+	})
+
+	var addr net.Addr
+	require.Eventually(t, func() bool {
+		addr = srv.e.TLSListenerAddr()
+		return addr != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + addr.String() + "/healthz")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotNil(t, resp.TLS)
+}
+
+// writeSelfSigned writes a throwaway certificate and key for 127.0.0.1
+func writeSelfSigned(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
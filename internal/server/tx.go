@@ -0,0 +1,286 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/wallet"
+	"github.com/gagliardetto/solana-go"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// maxTransactionBytes is the largest serialized transaction the cluster
+// accepts (one packet)
+const maxTransactionBytes = 1232
+
+// TxSimulator simulates transactions built by API clients
+// (implemented by *wallet.Wallet)
+type TxSimulator interface {
+	SimulateTransaction(ctx context.Context, tx *solana.Transaction) (*wallet.SimulationResult, error)
+}
+
+// TxSimulate simulates a base64-encoded transaction against our RPC node and
+// returns its logs and compute units. A transaction the simulation rejects is
+// still a 200, with success false and the error classified like a failed
+// swap; 4xx is for transactions that do not decode, 5xx for RPC failures.
+func (h *Handlers) TxSimulate(c echo.Context) error {
+	if h.Simulator == nil {
+		return h.err(c, http.StatusBadRequest, "transaction simulation is not configured", nil)
+	}
+	var req TxSimulateRequest
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+	tx, err := decodeTransaction(req.Transaction)
+	if err != nil {
+		return h.invalidField(c, "transaction", "format", err.Error())
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 15*time.Second)
+	defer cancel()
+
+	res, err := h.Simulator.SimulateTransaction(ctx, tx)
+	var txErr *wallet.TxError
+	if err != nil && !errors.As(err, &txErr) {
+		return h.fail(c, http.StatusBadGateway, "simulation failed", err)
+	}
+
+	resp := TxSimulateResponse{Success: err == nil, Logs: []string{}}
+	if res != nil {
+		resp.UnitsConsumed = res.UnitsConsumed
+		if res.Logs != nil {
+			resp.Logs = res.Logs
+		}
+	}
+	if txErr != nil {
+		resp.TxFailure = txFailure(err, txErr)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// txFailure describes err, whose transaction error is txErr, classified like
+// a failed swap
+func txFailure(err error, txErr *wallet.TxError) TxFailure {
+	kind, code := swapengine.ClassifyError(err)
+	f := TxFailure{Error: err.Error(), Err: txErr.Err, ErrorKind: string(kind)}
+	if code >= 0 {
+		f.ErrorCode = &code
+	}
+	return f
+}
+
+// decodeTransaction parses a base64 wire transaction
+func decodeTransaction(s string) (*solana.Transaction, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("transaction must be base64")
+	}
+	if len(raw) > maxTransactionBytes {
+		return nil, errors.New("transaction exceeds 1232 bytes")
+	}
+	tx, err := solana.TransactionFromBytes(raw)
+	if err != nil {
+		return nil, errors.New("not a serialized transaction")
+	}
+	return tx, nil
+}
+
+// Relayed transactions are tracked until they land or this long after sending,
+// past the roughly 60-90s a blockhash stays valid
+const txConfirmTimeout = 2 * time.Minute
+
+// Webhooks of relayed transactions are tried this many times, txWebhookTimeout
+// each, one second apart and doubling, within txWebhookDeadline
+const (
+	txWebhookAttempts = 3
+	txWebhookTimeout  = 5 * time.Second
+	txWebhookDeadline = 30 * time.Second
+)
+
+var (
+	txRelayed = metrics.Default.Counter("api_tx_relayed_total",
+		"Transactions relayed by POST /v1/tx/send, by outcome: rejected (preflight), confirmed, failed (on chain) or unconfirmed.", "outcome")
+	txWebhookFailures = metrics.Default.Counter("api_tx_webhook_failures_total",
+		"Webhooks of relayed transactions that could not be delivered after every attempt.")
+)
+
+// TxRelay sends transactions signed by API clients and waits for them to
+// land (implemented by *wallet.Wallet)
+type TxRelay interface {
+	SendTx(ctx context.Context, tx *solana.Transaction, opts *wallet.SendOptions) (string, error)
+	ConfirmTransaction(ctx context.Context, signature, commitment string, timeout time.Duration) error
+}
+
+// TxSend relays a signed base64-encoded transaction through our RPC node and
+// tracks its confirmation in the background, posting a TxWebhook to
+// webhook_url once it is confirmed, failed on chain or given up on. Signed
+// transactions cannot take a priority fee on top, so the response only notes
+// the bid it carries.
+func (h *Handlers) TxSend(c echo.Context) error {
+	if h.Relay == nil {
+		return h.err(c, http.StatusBadRequest, "transaction relay is not configured", nil)
+	}
+	req := TxSendRequest{Commitment: "confirmed"}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+	if req.WebhookURL != "" {
+		if err := h.Webhooks.CheckURL(req.WebhookURL); err != nil {
+			return h.invalidField(c, "webhook_url", "webhook_url", err.Error())
+		}
+	}
+	tx, err := decodeTransaction(req.Transaction)
+	if err != nil {
+		return h.invalidField(c, "transaction", "format", err.Error())
+	}
+	// a message requiring no signatures verifies trivially but has no
+	// signature to track it by
+	if len(tx.Signatures) == 0 || tx.Message.Header.NumRequiredSignatures == 0 {
+		return h.invalidField(c, "transaction", "signature", "transaction carries no signatures")
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		return h.invalidField(c, "transaction", "signature", "transaction is not fully signed: "+err.Error())
+	}
+
+	resp := TxSendResponse{Signature: tx.Signatures[0].String(), ComputeUnitPrice: computeUnitPrice(tx)}
+	if resp.ComputeUnitPrice == 0 {
+		resp.Note = "no SetComputeUnitPrice instruction: the transaction bids no priority fee and may land slowly when the cluster is busy"
+	}
+
+	opts := wallet.DefaultSendOptions()
+	opts.SkipPreflight = req.SkipPreflight
+	ctx, cancel := h.withTimeout(c.Request().Context(), 15*time.Second)
+	defer cancel()
+
+	sig, err := h.Relay.SendTx(ctx, tx, &opts)
+	var txErr *wallet.TxError
+	switch {
+	case errors.As(err, &txErr):
+		txRelayed.With("rejected").Inc()
+		resp.TxFailure, resp.Logs = txFailure(err, txErr), txErr.Logs
+		return c.JSON(http.StatusUnprocessableEntity, resp)
+	case err != nil:
+		return h.fail(c, http.StatusBadGateway, "failed to send transaction", err)
+	}
+
+	resp.Sent, resp.Signature, resp.Commitment = true, sig, req.Commitment
+	resp.Webhook = req.WebhookURL != ""
+	h.trackTx(sig, req.Commitment, req.WebhookURL, h.log(c))
+	return c.JSON(http.StatusAccepted, resp)
+}
+
+// trackTx waits in the background for a relayed transaction to reach
+// commitment and posts the outcome to webhook, if any. The API drains
+// tracked transactions like requests on shutdown; those still pending when
+// the drain window ends get no webhook.
+func (h *Handlers) trackTx(sig, commitment, webhook string, log *logrus.Entry) {
+	if h.drain != nil && !h.drain.enter() {
+		return
+	}
+	sentAt := time.Now()
+	go func() {
+		if h.drain != nil {
+			defer h.drain.leave()
+		}
+		out := TxWebhook{Signature: sig, Status: "confirmed", Commitment: commitment, SentAt: sentAt}
+		ctx, cancel := context.WithTimeout(context.Background(), txConfirmTimeout+time.Second)
+		err := h.Relay.ConfirmTransaction(ctx, sig, commitment, txConfirmTimeout)
+		cancel()
+		out.LatencyMs = time.Since(sentAt).Milliseconds()
+		var txErr *wallet.TxError
+		switch {
+		case errors.As(err, &txErr):
+			out.Status, out.TxFailure = "failed", txFailure(err, txErr)
+		case err != nil:
+			// timed out, or the RPC node stopped answering: the outcome is unknown
+			out.Status, out.Error = "unconfirmed", err.Error()
+		}
+		txRelayed.With(out.Status).Inc()
+		log = log.WithFields(logrus.Fields{"signature": sig, "status": out.Status})
+		log.WithField("latency_ms", out.LatencyMs).Debug("relayed transaction settled")
+
+		if webhook == "" {
+			return
+		}
+		ctx, cancel = context.WithTimeout(context.Background(), txWebhookDeadline)
+		defer cancel()
+		if err := postTxWebhook(ctx, h.txWebhookClient(), webhook, &out); err != nil {
+			txWebhookFailures.With().Inc()
+			log.WithError(err).Warn("transaction webhook failed")
+		}
+	}()
+}
+
+// txWebhookClient posts TxWebhooks, refusing addresses h.Webhooks does not
+// allow once their host name is resolved
+func (h *Handlers) txWebhookClient() *http.Client {
+	h.webhookOnce.Do(func() { h.webhookClient = h.Webhooks.Client(txWebhookTimeout) })
+	return h.webhookClient
+}
+
+// postTxWebhook posts out to url, retrying failed deliveries
+func postTxWebhook(ctx context.Context, client *http.Client, url string, out *TxWebhook) error {
+	body, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		err = postWebhookOnce(ctx, client, url, body)
+		if err == nil || attempt == txWebhookAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
+
+func postWebhookOnce(ctx context.Context, client *http.Client, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s returned %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// computeUnitPrice returns the micro-lamports per compute unit a
+// SetComputeUnitPrice instruction (tag 3, then a little-endian u64) of tx
+// bids, or 0
+func computeUnitPrice(tx *solana.Transaction) uint64 {
+	for _, ix := range tx.Message.Instructions {
+		program, err := tx.ResolveProgramIDIndex(ix.ProgramIDIndex)
+		if err != nil || program.String() != constants.ComputeBudgetProgram {
+			continue
+		}
+		if len(ix.Data) == 9 && ix.Data[0] == 3 {
+			return binary.LittleEndian.Uint64(ix.Data[1:])
+		}
+	}
+	return 0
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/netguard"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/swapengine"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/wallet"
+	"github.com/gagliardetto/solana-go"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSimulator struct {
+	res *wallet.SimulationResult
+	err error
+	got *solana.Transaction
+}
+
+func (f *fakeSimulator) SimulateTransaction(_ context.Context, tx *solana.Transaction) (*wallet.SimulationResult, error) {
+	f.got = tx
+	return f.res, f.err
+}
+
+func postJSON(e *echo.Echo, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// testTransaction returns an unsigned transaction calling the memo program, base64-encoded
+func testTransaction(t *testing.T) string {
+	return buildTransaction(t, solana.NewWallet().PrivateKey, false)
+}
+
+// buildTransaction returns a transaction paid by payer calling the memo
+// program after ixs, base64-encoded and signed when sign is set
+func buildTransaction(t *testing.T, payer solana.PrivateKey, sign bool, ixs ...solana.Instruction) string {
+	t.Helper()
+	memo := solana.MustPublicKeyFromBase58("MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr")
+	ixs = append(ixs, solana.NewInstruction(memo, solana.AccountMetaSlice{solana.Meta(payer.PublicKey()).SIGNER()}, []byte("hi")))
+	tx, err := solana.NewTransaction(ixs, solana.Hash{1}, solana.TransactionPayer(payer.PublicKey()))
+	require.NoError(t, err)
+	if sign {
+		_, err = tx.Sign(func(solana.PublicKey) *solana.PrivateKey { return &payer })
+		require.NoError(t, err)
+	}
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func TestTxSimulate(t *testing.T) {
+	sim := &fakeSimulator{}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Simulator: sim}, ServerConfig{})
+	body := `{"transaction":"` + testTransaction(t) + `"}`
+
+	simulate := func(status int) (out TxSimulateResponse) {
+		rec := postJSON(e, "/v1/tx/simulate", body)
+		require.Equal(t, status, rec.Code, rec.Body.String())
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+		return out
+	}
+
+	sim.res = &wallet.SimulationResult{Success: true, Logs: []string{"Program log: Memo"}, UnitsConsumed: 4200}
+	ok := simulate(http.StatusOK)
+	assert.True(t, ok.Success)
+	assert.Equal(t, []string{"Program log: Memo"}, ok.Logs)
+	assert.Equal(t, uint64(4200), ok.UnitsConsumed)
+	require.NotNil(t, sim.got)
+	assert.Equal(t, solana.Hash{1}, sim.got.Message.RecentBlockhash)
+
+	// a rejected transaction is the simulation's answer, not a failed request
+	txErr := map[string]any{"InstructionError": []any{float64(0), map[string]any{"Custom": float64(6001)}}}
+	sim.res = &wallet.SimulationResult{Logs: []string{"Program failed"}, UnitsConsumed: 900}
+	sim.err = &wallet.TxError{Op: "simulation failed", Err: txErr, Logs: sim.res.Logs}
+	failed := simulate(http.StatusOK)
+	assert.False(t, failed.Success)
+	assert.Equal(t, txErr, failed.Err)
+	assert.Equal(t, "program_error", failed.ErrorKind)
+	require.NotNil(t, failed.ErrorCode)
+	assert.Equal(t, int64(6001), *failed.ErrorCode)
+	assert.Equal(t, uint64(900), failed.UnitsConsumed)
+
+	sim.res, sim.err = nil, apperr.New(apperr.UpstreamUnavailable, "request failed")
+	assert.Equal(t, http.StatusServiceUnavailable, postJSON(e, "/v1/tx/simulate", body).Code)
+
+	for _, bad := range []string{`{}`, `{"transaction":"not base64!"}`, `{"transaction":"aGVsbG8="}`} {
+		assert.Equal(t, http.StatusBadRequest, postJSON(e, "/v1/tx/simulate", bad).Code, bad)
+	}
+
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{})
+	assert.Equal(t, http.StatusBadRequest, postJSON(e, "/v1/tx/simulate", body).Code)
+}
+
+type fakeRelay struct {
+	sendErr    error
+	confirmErr error
+	sent       []*solana.Transaction
+}
+
+func (f *fakeRelay) SendTx(_ context.Context, tx *solana.Transaction, _ *wallet.SendOptions) (string, error) {
+	if f.sendErr != nil {
+		return "", f.sendErr
+	}
+	f.sent = append(f.sent, tx)
+	return tx.Signatures[0].String(), nil
+}
+
+func (f *fakeRelay) ConfirmTransaction(context.Context, string, string, time.Duration) error {
+	return f.confirmErr
+}
+
+func TestTxSend(t *testing.T) {
+	hooks := make(chan TxWebhook, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out TxWebhook
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&out))
+		hooks <- out
+	}))
+	defer webhook.Close()
+
+	relay := &fakeRelay{}
+	e := echo.New()
+	// the test webhook listens on loopback
+	RegisterRoutes(e, &Handlers{Relay: relay, Webhooks: netguard.Policy{AllowPrivate: true}}, ServerConfig{})
+	payer := solana.NewWallet().PrivateKey
+
+	send := func(status int, body string) (out TxSendResponse) {
+		rec := postJSON(e, "/v1/tx/send", body)
+		require.Equal(t, status, rec.Code, rec.Body.String())
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+		return out
+	}
+
+	signed := buildTransaction(t, payer, true)
+	sent := send(http.StatusAccepted, `{"transaction":"`+signed+`","webhook_url":"`+webhook.URL+`"}`)
+	assert.True(t, sent.Sent)
+	assert.True(t, sent.Webhook)
+	assert.Equal(t, "confirmed", sent.Commitment)
+	assert.Zero(t, sent.ComputeUnitPrice)
+	assert.Contains(t, sent.Note, "no priority fee")
+	require.Len(t, relay.sent, 1)
+	assert.Equal(t, relay.sent[0].Signatures[0].String(), sent.Signature)
+
+	hook := <-hooks
+	assert.Equal(t, sent.Signature, hook.Signature)
+	assert.Equal(t, "confirmed", hook.Status)
+
+	// a priority fee is read from the transaction; a failure on chain is classified
+	relay.confirmErr = &wallet.TxError{Op: "transaction failed", Err: map[string]any{"InstructionError": []any{float64(1), map[string]any{"Custom": float64(6001)}}}}
+	prioritized := buildTransaction(t, payer, true, swapengine.NewSetComputeUnitPriceIx(5000))
+	sent = send(http.StatusAccepted, `{"transaction":"`+prioritized+`","commitment":"finalized","webhook_url":"`+webhook.URL+`"}`)
+	assert.Equal(t, uint64(5000), sent.ComputeUnitPrice)
+	assert.Empty(t, sent.Note)
+	hook = <-hooks
+	assert.Equal(t, "failed", hook.Status)
+	assert.Equal(t, "finalized", hook.Commitment)
+	require.NotNil(t, hook.ErrorCode)
+	assert.Equal(t, int64(6001), *hook.ErrorCode)
+
+	// preflight rejections are answered with the reason and logs
+	relay.sendErr = &wallet.TxError{Op: "sendTransaction error", Code: -32002, Message: "Transaction simulation failed",
+		Err: "AccountNotFound", Logs: []string{"Program log: missing"}}
+	rejected := send(http.StatusUnprocessableEntity, `{"transaction":"`+signed+`"}`)
+	assert.False(t, rejected.Sent)
+	assert.Equal(t, "AccountNotFound", rejected.Err)
+	assert.Equal(t, []string{"Program log: missing"}, rejected.Logs)
+
+	// a message that requires no signatures verifies trivially
+	memo := solana.MustPublicKeyFromBase58("MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr")
+	unsignable := solana.Transaction{Message: solana.Message{
+		AccountKeys:     solana.PublicKeySlice{memo},
+		Header:          solana.MessageHeader{NumReadonlyUnsignedAccounts: 1},
+		RecentBlockhash: solana.Hash{1},
+		Instructions:    []solana.CompiledInstruction{{ProgramIDIndex: 0, Data: []byte("hi")}},
+	}}
+	raw, err := unsignable.MarshalBinary()
+	require.NoError(t, err)
+
+	for _, bad := range []string{
+		`{"transaction":"` + buildTransaction(t, payer, false) + `"}`,      // unsigned
+		`{"transaction":"` + base64.StdEncoding.EncodeToString(raw) + `"}`, // no signers
+		`{"transaction":"` + signed + `","commitment":"recent"}`,
+		`{"transaction":"` + signed + `","webhook_url":"ftp://example.com"}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, postJSON(e, "/v1/tx/send", bad).Code, bad)
+	}
+	assert.Len(t, relay.sent, 2)
+
+	// by default webhooks may not point into our own network
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{Relay: relay, Webhooks: netguard.Policy{AllowedHosts: []string{"hooks.example.com"}}}, ServerConfig{})
+	for _, bad := range []string{webhook.URL, "http://169.254.169.254/latest/meta-data", "https://example.com/hook"} {
+		rec := postJSON(e, "/v1/tx/send", `{"transaction":"`+signed+`","webhook_url":"`+bad+`"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, bad)
+	}
+	assert.Len(t, relay.sent, 2, "refused before sending")
+}
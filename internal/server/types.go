@@ -1,10 +1,19 @@
 package server
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
 // ErrorResponse represents a standardized error response format
 type ErrorResponse struct {
-	Error   string `json:"error"`             // Human-readable error message
-	Code    int    `json:"code"`              // HTTP status code
-	Details any    `json:"details,omitempty"` // Additional error details (dev mode only)
+	Error    string `json:"error"`              // Human-readable error message
+	Code     int    `json:"code"`               // HTTP status code
+	Category string `json:"category,omitempty"` // apperr code: not_found, rate_limited, upstream_unavailable, validation_failed or risk_rejected
+	Details  any    `json:"details,omitempty"`  // Additional error details (dev mode only)
+	Hint     string `json:"hint,omitempty"`     // What the caller can check next
 }
 
 // HealthResponse represents the health check response
@@ -12,37 +21,503 @@ type HealthResponse struct {
 	OK bool `json:"ok"` // Service health status
 }
 
+// ReadyResponse represents the readiness probe result
+type ReadyResponse struct {
+	Ready  bool                  `json:"ready"`  // Every check passed
+	Checks map[string]ReadyCheck `json:"checks"` // Result per dependency (redis, ingestion)
+}
+
+// ReadyCheck is the result of one readiness check
+type ReadyCheck struct {
+	OK        bool   `json:"ok"`
+	Detail    string `json:"detail,omitempty"` // e.g. "max slot lag 12"
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
 // SwapsRecentResponse represents recent swaps response (deprecated - use inline struct)
 type SwapsRecentResponse struct {
 	Items any `json:"items"` // List of swap events
 }
 
+// RecentSwapsRequest holds the parameters of GET /v1/swaps/recent
+type RecentSwapsRequest struct {
+	Pair  string `query:"pair" validate:"omitempty,pair"` // Optional pair filter (case-insensitive)
+	Limit int    `query:"limit" validate:"min=1,max=200"` // Number of swaps (default 100)
+}
+
+// SwapRequest holds the :signature path parameter of GET /v1/swaps/:signature
+type SwapRequest struct {
+	Signature string `param:"signature" validate:"signature"` // Base58 transaction signature
+}
+
+// SwapResponse is one indexed swap and where it was found
+type SwapResponse struct {
+	Source string            `json:"source"` // cache (recent swaps in Redis) or store (ClickHouse)
+	Swap   *models.SwapEvent `json:"swap"`
+}
+
+// TokenRequest holds the :token path parameter of the price endpoints
+type TokenRequest struct {
+	Token string `param:"token" validate:"required,max=64"` // Token symbol (case-insensitive)
+}
+
+// PriceHistoryRequest holds the parameters of GET /v1/prices/:token/history
+type PriceHistoryRequest struct {
+	TokenRequest
+	Window time.Duration `query:"window" validate:"min=1s,max=24h"` // Lookback (default 15m)
+}
+
+// PriceMarketsRequest holds the parameters of GET /v1/prices/:token/markets
+type PriceMarketsRequest struct {
+	TokenRequest
+	Quote string `query:"quote" validate:"max=64"` // Quote token (default: the one with the most volume)
+}
+
+// MarketEntry is one venue's price of the token
+type MarketEntry struct {
+	models.MarketPrice
+	Stale        bool     `json:"stale"`                   // Older than PRICE_STALE_AFTER; left out of mid and spread
+	DeviationBps *float64 `json:"deviation_bps,omitempty"` // (price / mid - 1) * 10000
+}
+
+// PriceMarketsResponse compares a token's price across venues
+type PriceMarketsResponse struct {
+	Token     string        `json:"token"`
+	Quote     string        `json:"quote"`
+	Mid       *float64      `json:"mid,omitempty"`        // Volume-weighted price of the fresh venues
+	SpreadBps *float64      `json:"spread_bps,omitempty"` // (highest - lowest fresh price) / mid * 10000
+	Markets   []MarketEntry `json:"markets"`              // Most volume first
+	Count     int           `json:"count"`
+}
+
 // PriceResponse represents token price information
 type PriceResponse struct {
-	Token string  `json:"token"` // Token symbol (uppercase)
-	Price float64 `json:"price"` // Current price
+	Token     string     `json:"token"`                // Token symbol (uppercase)
+	Price     float64    `json:"price"`                // Last price (0 if none is cached)
+	UpdatedAt *time.Time `json:"updated_at,omitempty"` // When the price was last written
+	Stale     bool       `json:"stale"`                // Older than PRICE_STALE_AFTER, or unknown
+}
+
+// PriceHistoryResponse represents a token's recent price points
+type PriceHistoryResponse struct {
+	Token  string              `json:"token"`  // Token symbol (uppercase)
+	Window string              `json:"window"` // Requested window (e.g. "15m")
+	Points []models.PricePoint `json:"points"` // Oldest first
+}
+
+// FlagKeyRequest holds the :key path parameter of the flag endpoints
+type FlagKeyRequest struct {
+	Key string `param:"key" validate:"flag_key"` // Flag key (must match regex pattern)
+}
+
+// FlagsListRequest holds the parameters of GET /v1/flags
+type FlagsListRequest struct {
+	Prefix string `query:"prefix" validate:"flag_prefix"`        // Namespace, e.g. "engine."
+	Cursor string `query:"cursor" validate:"omitempty,flag_key"` // next_cursor of the previous page
+	Limit  int    `query:"limit" validate:"min=1,max=500"`       // Page size (default flags.DefaultPageSize, max flags.MaxPageSize)
+}
+
+// FlagsHistoryRequest holds the parameters of GET /v1/flags/:key/history
+type FlagsHistoryRequest struct {
+	FlagKeyRequest
+	Limit int `query:"limit" validate:"min=1"` // Entries (default 50, max FLAGS_HISTORY_LIMIT)
 }
 
 // FlagUpsertRequest represents a request to create or update a feature flag
 type FlagUpsertRequest struct {
-	Key   string `json:"key"`   // Flag key (must match regex pattern)
-	Value bool   `json:"value"` // Flag value (true/false)
+	Key   string          `json:"key" validate:"flag_key"`             // Flag key (must match regex pattern)
+	Type  string          `json:"type" validate:"omitempty,flag_type"` // bool, int, float, string or json (inferred from value if empty)
+	Value json.RawMessage `json:"value"`                               // Flag value matching Type
+
+	FlagScheduleRequest
 }
 
 // FlagUpdateRequest represents a request to update an existing feature flag
 type FlagUpdateRequest struct {
-	Value bool `json:"value"` // New flag value
+	Key   string          `param:"key" json:"-" validate:"flag_key"`   // Flag key from the path
+	Type  string          `json:"type" validate:"omitempty,flag_type"` // Optional; defaults to the existing flag's type
+	Value json.RawMessage `json:"value"`                               // New flag value
+
+	FlagScheduleRequest // Omit all fields to keep the existing schedule
+}
+
+// FlagScheduleRequest holds the optional activation window of a flag
+type FlagScheduleRequest struct {
+	TTL        string     `json:"ttl,omitempty"`         // Expire after this duration (e.g. "2h"); exclusive with ExpiresAt
+	ActiveFrom *time.Time `json:"active_from,omitempty"` // Takes effect at this time (RFC3339)
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`  // Removed at this time (RFC3339)
 }
 
 // AIAskRequest represents a natural language query request
 type AIAskRequest struct {
-	Question string `json:"question"` // Natural language question about swap data
-	Model    string `json:"model"`    // Optional AI model override
+	Question string `json:"question" validate:"required"` // Natural language question about swap data
+	Model    string `json:"model"`                        // Optional AI model override
+	AllTime  bool   `json:"all_time"`                     // Query all stored swaps when the SQL has no time filter (default: the last AI_MAX_LOOKBACK)
+	SQLOnly  bool   `json:"sql_only"`                     // Return the generated SQL without running it
+	DryRun   bool   `json:"dry_run"`                      // Return the SQL with its EXPLAIN plan and estimate instead of running it
 }
 
 // AIAskResponse represents the response from an AI query
 type AIAskResponse struct {
-	SQL    string `json:"sql"`     // Generated SQL query
-	Answer string `json:"answer"`  // Natural language answer
-	TookMs int64  `json:"took_ms"` // Execution time in milliseconds
+	Intent   string      `json:"intent"`             // How the question was answered: analytics (SQL) or price (Redis)
+	SQL      string      `json:"sql"`                // Generated SQL query; empty for price answers
+	Answer   string      `json:"answer"`             // Natural language answer
+	Lookback string      `json:"lookback,omitempty"` // Set when the SQL had no time filter and only read this far back (AI_MAX_LOOKBACK)
+	Estimate *AIEstimate `json:"estimate,omitempty"` // What the SQL would read (dry_run only)
+	TookMs   int64       `json:"took_ms"`            // Execution time in milliseconds
+}
+
+// AIEstimate is ClickHouse's estimate of what generated SQL would read
+type AIEstimate struct {
+	Rows  uint64   `json:"rows"`  // Rows to read
+	Marks uint64   `json:"marks"` // Index granules to read
+	Parts uint64   `json:"parts"` // Data parts to read
+	Plan  []string `json:"plan"`  // EXPLAIN output, one step per line
+}
+
+// SwapExecuteRequest represents a swap to execute through the swap engine
+type SwapExecuteRequest struct {
+	InputToken  string  `json:"input_token" validate:"required,max=64"`  // Token symbol or mint address to sell (e.g. SOL)
+	OutputToken string  `json:"output_token" validate:"required,max=64"` // Token symbol or mint address to buy (e.g. USDC)
+	Amount      float64 `json:"amount" validate:"gt=0"`                  // Amount of InputToken in human units
+	SlippageBps *uint16 `json:"slippage_bps,omitempty"`                  // Optional; defaults to SWAPENGINE_DEFAULT_SLIPPAGE_BPS
+	Reason      string  `json:"reason,omitempty"`                        // Optional note kept with the execution
+}
+
+// SwapExecuteResponse represents the outcome of an executed swap
+type SwapExecuteResponse struct {
+	ExecutionID string  `json:"execution_id"`
+	Signature   string  `json:"signature,omitempty"` // Transaction signature, once sent
+	Success     bool    `json:"success"`
+	Error       string  `json:"error,omitempty"`
+	ErrorKind   string  `json:"error_kind,omitempty"` // slippage_exceeded, insufficient_funds, blockhash_expired, ...
+	ErrorCode   *int64  `json:"error_code,omitempty"` // Failing program's custom error code
+	ExpectedOut uint64  `json:"expected_out"`         // Quoted output in raw units
+	ActualOut   *uint64 `json:"actual_out,omitempty"` // Output read from the transaction
+	DurationMs  int64   `json:"duration_ms"`
+}
+
+// TxSimulateRequest is a transaction an API client built, to simulate
+type TxSimulateRequest struct {
+	Transaction string `json:"transaction" validate:"required"` // Base64 wire transaction; need not be signed
+}
+
+// TxSimulateResponse is the outcome of a simulated transaction
+type TxSimulateResponse struct {
+	Success bool `json:"success"`
+	TxFailure
+	Logs          []string `json:"logs"`           // Program logs
+	UnitsConsumed uint64   `json:"units_consumed"` // Compute units the transaction used
+}
+
+// TxFailure describes a transaction the cluster rejected or that failed on chain
+type TxFailure struct {
+	Error     string `json:"error,omitempty"`
+	Err       any    `json:"err,omitempty"`        // Transaction error as returned by the RPC node, e.g. {"InstructionError":[2,{"Custom":6001}]}
+	ErrorKind string `json:"error_kind,omitempty"` // slippage_exceeded, insufficient_funds, account_not_found, ...
+	ErrorCode *int64 `json:"error_code,omitempty"` // Failing program's custom error code
+}
+
+// TxSendRequest is a signed transaction to relay
+type TxSendRequest struct {
+	Transaction   string `json:"transaction" validate:"required"`                                               // Base64 signed wire transaction
+	SkipPreflight bool   `json:"skip_preflight,omitempty"`                                                      // Send without the RPC node's simulation
+	Commitment    string `json:"commitment,omitempty" validate:"omitempty,oneof=processed confirmed finalized"` // Level confirmation is tracked to (default confirmed)
+	WebhookURL    string `json:"webhook_url,omitempty" validate:"omitempty,webhook_url"`                        // Optional; receives a TxWebhook once the outcome is known
+}
+
+// TxSendResponse is the outcome of relaying a transaction. Sent is false
+// when preflight rejected it, with the reason and logs.
+type TxSendResponse struct {
+	Sent       bool   `json:"sent"`
+	Signature  string `json:"signature"`
+	Commitment string `json:"commitment,omitempty"` // Level confirmation is tracked to
+	Webhook    bool   `json:"webhook"`              // A webhook is posted once the outcome is known
+	TxFailure
+	Logs             []string `json:"logs,omitempty"`     // Preflight logs of a rejected transaction
+	ComputeUnitPrice uint64   `json:"compute_unit_price"` // SetComputeUnitPrice bid in micro-lamports per unit (0: none)
+	Note             string   `json:"note,omitempty"`     // Advice, e.g. on a missing priority fee
+}
+
+// TxWebhook is posted to TxSendRequest.WebhookURL once a relayed
+// transaction is confirmed, failed on chain or stopped being tracked
+type TxWebhook struct {
+	Signature  string `json:"signature"`
+	Status     string `json:"status"` // confirmed, failed or unconfirmed
+	Commitment string `json:"commitment"`
+	TxFailure
+	SentAt    time.Time `json:"sent_at"`
+	LatencyMs int64     `json:"latency_ms"` // From sending to the outcome
+}
+
+// ConfigReloadResponse represents the result of a config reload request
+type ConfigReloadResponse struct {
+	OK        bool  `json:"ok"`        // Request was published
+	Receivers int64 `json:"receivers"` // Number of services that received it
+}
+
+// PoolResponse is one swap engine pool with its current reserves (raw units)
+type PoolResponse struct {
+	Name        string `json:"name"`
+	SwapAccount string `json:"swap_account"`
+	TokenMintA  string `json:"token_mint_a"`
+	TokenMintB  string `json:"token_mint_b"`
+	VaultA      string `json:"vault_a"`
+	VaultB      string `json:"vault_b"`
+	FeeBps      uint16 `json:"fee_bps"`
+	Discovered  bool   `json:"discovered"`
+	ReserveA    uint64 `json:"reserve_a"`
+	ReserveB    uint64 `json:"reserve_b"`
+	Error       string `json:"error,omitempty"` // reserves could not be fetched
+}
+
+// PoolsResponse lists the swap engine's registered pools
+type PoolsResponse struct {
+	Pools []PoolResponse `json:"pools"`
+	Count int            `json:"count"`
+}
+
+// PoolsReloadResponse summarises a pool registry reload
+type PoolsReloadResponse struct {
+	OK         bool   `json:"ok"`
+	Path       string `json:"path"`
+	Source     string `json:"source"`
+	Configured int    `json:"configured"`        // Pools loaded from the config file
+	Skipped    int    `json:"skipped"`           // Invalid entries left out
+	Discovered int    `json:"discovered"`        // Pools added by the rescan after the reload
+	Total      int    `json:"total"`             // Pools now registered
+	Warning    string `json:"warning,omitempty"` // Set when the rescan failed
+
+	Errors []PoolLoadError `json:"errors,omitempty"` // Why entries were skipped
+}
+
+// RiskConfigRequest is the body of PUT /v1/swap/risk-config: runtime
+// overrides that tighten the configured swap engine risk limits. Omitted
+// fields keep the configured value.
+type RiskConfigRequest struct {
+	MaxSwapAmountSOL   *float64  `json:"max_swap_amount_sol,omitempty" validate:"gt=0"`
+	DailyLimitSOL      *float64  `json:"daily_limit_sol,omitempty" validate:"gt=0"`
+	MaxPriceImpactBps  *uint16   `json:"max_price_impact_bps,omitempty" validate:"max=10000"`
+	DefaultSlippageBps *uint16   `json:"default_slippage_bps,omitempty" validate:"max=10000"`
+	MaxSlippageBps     *uint16   `json:"max_slippage_bps,omitempty" validate:"max=10000"`
+	AllowedTokens      *[]string `json:"allowed_tokens,omitempty"` // Subset of the configured whitelist
+	MinBalanceSOL      *float64  `json:"min_balance_sol,omitempty" validate:"min=0"`
+	MaxRouteHops       *int      `json:"max_route_hops,omitempty" validate:"min=1"`
+	ExcludedDexes      []string  `json:"excluded_dexes,omitempty"` // Excluded on top of the configured DEXes
+}
+
+// RiskLimits are the swap engine risk limits
+type RiskLimits struct {
+	MaxSwapAmountSOL   float64  `json:"max_swap_amount_sol"`
+	DailyLimitSOL      float64  `json:"daily_limit_sol"`
+	MaxPriceImpactBps  uint16   `json:"max_price_impact_bps"`
+	DefaultSlippageBps uint16   `json:"default_slippage_bps"`
+	MaxSlippageBps     uint16   `json:"max_slippage_bps"`
+	AllowedTokens      []string `json:"allowed_tokens"` // Empty allows every token
+	MaxRouteHops       int      `json:"max_route_hops"` // 0 = unlimited
+	ExcludedDexes      []string `json:"excluded_dexes"`
+	RequireSimulation  bool     `json:"require_simulation"`
+	MinBalanceSOL      float64  `json:"min_balance_sol"`
+	FailureCooldown    string   `json:"failure_cooldown"` // How long a pair is refused after a failed swap
+
+	WalletLimits map[string]WalletRiskLimits `json:"wallet_limits,omitempty"` // By wallet address (SWAPENGINE_WALLET_LIMITS)
+}
+
+// WalletRiskLimits are the limits of one wallet; 0 keeps the global limit
+type WalletRiskLimits struct {
+	MaxSwapAmountSOL float64 `json:"max_swap_amount_sol"`
+	DailyLimitSOL    float64 `json:"daily_limit_sol"`
+}
+
+// RiskConfigResponse shows the risk limits in force and where they come from
+type RiskConfigResponse struct {
+	Config    RiskLimits        `json:"config"`               // Limits in force: base tightened by overrides
+	Base      RiskLimits        `json:"base"`                 // Configured limits (SWAPENGINE_*)
+	Overrides RiskConfigRequest `json:"overrides"`            // Runtime overrides (engine.risk flag)
+	UpdatedAt *time.Time        `json:"updated_at,omitempty"` // When the overrides last changed
+	FlagError string            `json:"flag_error,omitempty"` // Why the stored engine.risk flag was not applied
+}
+
+// PoolLoadError is one invalid field of a pool config entry
+type PoolLoadError struct {
+	Index   int    `json:"index"`
+	Pool    string `json:"pool"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// IndexerStatusResponse lists the latest status report of each live indexer replica
+type IndexerStatusResponse struct {
+	Instances []models.IndexerStatus `json:"instances"`
+	Count     int                    `json:"count"`
+}
+
+// IndexerLatencyResponse is the end-to-end indexing latency of each stream provider
+type IndexerLatencyResponse struct {
+	Providers []ProviderLatency `json:"providers"`
+}
+
+// ProviderLatency is the end-to-end latency of the swaps indexed from one
+// stream provider. Quantiles from different replicas cannot be merged, so each
+// is the highest any replica reports; Instances has each replica's own.
+type ProviderLatency struct {
+	Provider  string            `json:"provider"`
+	Swaps     uint64            `json:"swaps"`
+	P50Ms     float64           `json:"p50_ms"`
+	P95Ms     float64           `json:"p95_ms"`
+	P99Ms     float64           `json:"p99_ms"`
+	Instances []InstanceLatency `json:"instances"`
+}
+
+// InstanceLatency is one replica's end-to-end latency
+type InstanceLatency struct {
+	Instance string  `json:"instance"`
+	Swaps    uint64  `json:"swaps"`
+	P50Ms    float64 `json:"p50_ms"`
+	P95Ms    float64 `json:"p95_ms"`
+	P99Ms    float64 `json:"p99_ms"`
+}
+
+// ProgramRequest names a program address, in the body of
+// POST /v1/admin/indexer/programs or the path of its DELETE
+type ProgramRequest struct {
+	Address string `param:"address" json:"address" validate:"program"` // Base58 program address
+}
+
+// ProgramOverridesResponse lists the program addresses added and removed at runtime
+type ProgramOverridesResponse struct {
+	Added     []string `json:"added"`
+	Removed   []string `json:"removed"`
+	Receivers int64    `json:"receivers,omitempty"` // Indexers told to apply the change (add/remove only)
+}
+
+// TopWalletsRequest holds the parameters of GET /v1/wallets/top
+type TopWalletsRequest struct {
+	By     string        `query:"by" validate:"oneof=volume trades"` // Ranking (default volume)
+	Window time.Duration `query:"window" validate:"min=1m,max=720h"` // Lookback (default 24h)
+	Limit  int           `query:"limit" validate:"min=1,max=100"`    // Wallets (default 20)
+}
+
+// TopWalletsResponse ranks trader wallets
+type TopWalletsResponse struct {
+	By      string                 `json:"by"`
+	Window  string                 `json:"window"`
+	Wallets []models.WalletSummary `json:"wallets"`
+	Count   int                    `json:"count"`
+}
+
+// WalletStatsRequest holds the parameters of GET /v1/wallets/:address/stats
+type WalletStatsRequest struct {
+	Address string        `param:"address" validate:"wallet"`
+	Window  time.Duration `query:"window" validate:"min=1m,max=720h"` // Lookback (default 7d)
+}
+
+// WalletStatsResponse profiles one trader wallet
+type WalletStatsResponse struct {
+	Window string `json:"window"`
+	*models.WalletStats
+}
+
+// ArbOpportunitiesRequest holds the parameters of GET /v1/arb/opportunities
+type ArbOpportunitiesRequest struct {
+	Limit int    `query:"limit" validate:"min=1,max=200"` // Opportunities (default 50)
+	Pair  string `query:"pair" validate:"omitempty,pair"` // Optional pair filter, either token order
+}
+
+// ArbOpportunitiesResponse lists recent arbitrage opportunities, newest first
+type ArbOpportunitiesResponse struct {
+	Opportunities []*models.ArbOpportunity `json:"opportunities"`
+	Count         int                      `json:"count"`
+}
+
+// AnomaliesRequest holds the parameters of GET /v1/anomalies
+type AnomaliesRequest struct {
+	Limit  int    `query:"limit" validate:"min=1,max=500"`                  // Anomalies (default 50)
+	Pair   string `query:"pair" validate:"omitempty,pair"`                  // Optional pair filter (case-insensitive)
+	Metric string `query:"metric" validate:"omitempty,oneof=volume trades"` // Optional metric filter
+}
+
+// AnomaliesResponse lists recent volume anomalies, newest first
+type AnomaliesResponse struct {
+	Anomalies []*models.Anomaly `json:"anomalies"`
+	Count     int               `json:"count"`
+}
+
+// MEVStatsRequest holds the parameters of GET /v1/mev/stats
+type MEVStatsRequest struct {
+	Window time.Duration `query:"window" validate:"min=1m,max=720h"` // Lookback (default 24h)
+	Top    int           `query:"top" validate:"min=1,max=50"`       // Attackers and pools listed (default 10)
+}
+
+// MEVStatsResponse summarises sandwich attacks over a window
+type MEVStatsResponse struct {
+	Window string `json:"window"`
+	*models.MEVStats
+}
+
+// ListPairsRequest holds the parameters of GET /v1/pairs
+type ListPairsRequest struct {
+	Token  string `query:"token" validate:"max=64"`             // Either side of the pair, as stored (e.g. SOL)
+	Dex    string `query:"dex" validate:"max=64"`               // Pairs traded on this DEX
+	Limit  int    `query:"limit" validate:"min=1,max=1000"`     // Pairs (default 100)
+	Offset int    `query:"offset" validate:"min=0,max=1000000"` // Pairs skipped
+}
+
+// ListPairsResponse lists indexed pairs, busiest over the last 24 hours first
+type ListPairsResponse struct {
+	Pairs []models.PairListing `json:"pairs"`
+	Count int                  `json:"count"`
+}
+
+// DexStatsRequest holds the parameters of GET /v1/dexes
+type DexStatsRequest struct {
+	Window time.Duration `query:"window" validate:"min=1m,max=720h"` // Lookback (default 24h)
+}
+
+// DexStatsResponse compares DEXes over a window
+type DexStatsResponse struct {
+	Window string `json:"window"`
+	*models.DexStats
+}
+
+// SubscriptionRequest subscribes the API key to one pair or one token; it
+// is the body of POST /v1/subscriptions
+type SubscriptionRequest struct {
+	Pair       string `json:"pair,omitempty" validate:"omitempty,pair"`                 // e.g. SOL/USDC
+	Token      string `json:"token,omitempty" validate:"omitempty,max=64"`              // Symbol or mint, matched on either side of a swap
+	WebhookURL string `json:"webhook_url,omitempty" validate:"omitempty,webhook_url"`   // Optional; receives every matching swap
+	Digest     string `json:"digest,omitempty" validate:"omitempty,oneof=hourly daily"` // Optional summary schedule
+}
+
+// SubscriptionIDRequest holds the :id path parameter of DELETE /v1/subscriptions/:id
+type SubscriptionIDRequest struct {
+	ID string `param:"id" validate:"required,max=64"`
+}
+
+// SubscriptionsResponse lists the subscriptions of an API key
+type SubscriptionsResponse struct {
+	Subscriptions []*models.Subscription `json:"subscriptions"`
+	Limit         int                    `json:"limit"` // Subscriptions per API key at most
+}
+
+// ExportSwapsRequest holds the parameters of GET /v1/export/swaps
+type ExportSwapsRequest struct {
+	Format string    `query:"format" validate:"oneof=ndjson csv"` // Output (default ndjson)
+	From   time.Time `query:"from"`                               // RFC3339, inclusive (default 24h before to)
+	To     time.Time `query:"to"`                                 // RFC3339, exclusive (default now)
+	Pair   string    `query:"pair" validate:"omitempty,pair"`     // Optional pair filter (case-insensitive)
+	Limit  int       `query:"limit" validate:"min=1"`             // Rows (default and max ExportMaxRows)
+}
+
+// FeeStatsRequest holds the parameters of GET /v1/fees/stats
+type FeeStatsRequest struct {
+	Window time.Duration `query:"window" validate:"min=1m,max=720h"` // Lookback (default 24h)
+	Top    int           `query:"top" validate:"min=1,max=50"`       // Pairs listed (default 10)
+}
+
+// FeeStatsResponse averages the landing cost of swaps over a window
+type FeeStatsResponse struct {
+	Window string `json:"window"`
+	*models.FeeStats
 }
@@ -0,0 +1,466 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+	"github.com/gagliardetto/solana-go"
+	"github.com/labstack/echo/v4"
+)
+
+// Request structs declare where each field comes from with `param`, `query`
+// and `json` tags and what it must satisfy with a `validate` tag, e.g.
+//
+//	Limit int `query:"limit" validate:"min=1,max=200"`
+//
+// Rules are comma-separated:
+//
+//	required      non-zero (strings: not blank)
+//	omitempty     skip the remaining rules when the value is zero
+//	min=N, max=N  bounds of numbers and durations (e.g. max=24h), length of strings
+//	gt=N          numbers strictly greater than N
+//	oneof=A B C   one of the listed values
+//	<format>      a named format from the formats table (pair, flag_key, ...)
+//
+// Handlers call h.bind, which reports every invalid field at once as
+// ErrorResponse.Details: [{"field", "rule", "message"}, ...].
+
+// FieldError describes one invalid request field
+type FieldError struct {
+	Field   string `json:"field"`   // query/path parameter or JSON field name
+	Rule    string `json:"rule"`    // rule that failed: type, required, min, max, oneof, json, or a format name
+	Message string `json:"message"` // human-readable explanation
+}
+
+// ValidationError collects every invalid field of a request
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// format is a named string rule usable in validate tags
+type format struct {
+	check   func(string) bool
+	message string
+}
+
+var formats = map[string]format{
+	"pair": {
+		check:   func(s string) bool { return pairRe.MatchString(strings.ToUpper(strings.TrimSpace(s))) },
+		message: "expected BASE/QUOTE, e.g. SOL/USDC",
+	},
+	"uint64": {
+		check:   func(s string) bool { _, err := strconv.ParseUint(s, 10, 64); return err == nil },
+		message: "must be uint64",
+	},
+	"wallet": {
+		check:   func(s string) bool { _, err := solana.PublicKeyFromBase58(s); return err == nil },
+		message: "must be a base58 wallet address",
+	},
+	"program": {
+		check:   func(s string) bool { _, err := solana.PublicKeyFromBase58(s); return err == nil },
+		message: "must be a base58 program address",
+	},
+	"signature": {
+		check:   func(s string) bool { _, err := solana.SignatureFromBase58(s); return err == nil },
+		message: "must be a base58 transaction signature",
+	},
+	"webhook_url": {
+		check: func(s string) bool {
+			u, err := url.Parse(s)
+			return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+		},
+		message: "must be an http(s) URL",
+	},
+	"flag_key": {
+		check:   func(s string) bool { return flags.ValidateKey(s) == nil },
+		message: "invalid format",
+	},
+	"flag_prefix": {
+		check:   func(s string) bool { return flags.ValidatePrefix(s) == nil },
+		message: "invalid format",
+	},
+	"flag_type": {
+		check:   func(s string) bool { _, err := flags.ParseType(s); return err == nil },
+		message: "must be bool, int, float, string or json",
+	},
+}
+
+// requestBinder is the echo.Binder of the API. Unlike echo.DefaultBinder it
+// converts every param/query field before failing, reporting each bad value
+// as a FieldError rather than stopping at the first.
+type requestBinder struct {
+	echo.DefaultBinder
+}
+
+func (b *requestBinder) Bind(i any, c echo.Context) error {
+	var errs []FieldError
+
+	params := map[string][]string{}
+	for n, name := range c.ParamNames() {
+		params[name] = []string{c.ParamValues()[n]}
+	}
+	errs = append(errs, bindValues(i, "param", params)...)
+	errs = append(errs, bindValues(i, "query", c.QueryParams())...)
+
+	if req := c.Request(); req.ContentLength != 0 && req.Body != nil && req.Method != http.MethodGet {
+		if err := b.BindBody(c, i); err != nil {
+			errs = append(errs, FieldError{Field: "body", Rule: "json", Message: "invalid json"})
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// bindValues sets the fields of the struct i carrying tag from values
+func bindValues(i any, tag string, values map[string][]string) []FieldError {
+	v := reflect.ValueOf(i)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	var errs []FieldError
+	for n := 0; n < t.NumField(); n++ {
+		sf := t.Field(n)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			errs = append(errs, bindValues(v.Field(n).Addr().Interface(), tag, values)...)
+			continue
+		}
+		name := sf.Tag.Get(tag)
+		if name == "" || !sf.IsExported() {
+			continue
+		}
+		raw, ok := values[name]
+		if !ok || len(raw) == 0 {
+			continue
+		}
+		if msg := setField(v.Field(n), raw); msg != "" {
+			errs = append(errs, FieldError{Field: name, Rule: "type", Message: msg})
+		}
+	}
+	return errs
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// setField parses raw into f and returns a message when a value does not fit;
+// surrounding whitespace is dropped
+func setField(f reflect.Value, raw []string) string {
+	if f.Kind() == reflect.Pointer {
+		elem := reflect.New(f.Type().Elem())
+		if msg := setField(elem.Elem(), raw); msg != "" {
+			return msg
+		}
+		f.Set(elem)
+		return ""
+	}
+	if f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.String {
+		// repeated and comma-separated values both add items
+		f.Set(reflect.ValueOf(splitCSVQuery(raw)))
+		return ""
+	}
+
+	s := strings.TrimSpace(raw[0])
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return "must be a duration, e.g. 15m"
+		}
+		f.SetInt(int64(d))
+		return ""
+	}
+	if f.Type() == timeType {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return "must be an RFC3339 time, e.g. 2025-01-02T15:04:05Z"
+		}
+		f.Set(reflect.ValueOf(ts))
+		return ""
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return "must be an integer"
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Sprintf("must be uint%d", f.Type().Bits())
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return "must be a number"
+		}
+		f.SetFloat(x)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return "must be boolean"
+		}
+		f.SetBool(b)
+	default:
+		return "unsupported parameter type"
+	}
+	return ""
+}
+
+// requestValidator is the echo.Validator of the API; it checks `validate` tags
+type requestValidator struct{}
+
+func (requestValidator) Validate(i any) error {
+	v := reflect.ValueOf(i)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	if errs := validateStruct(v); len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func validateStruct(v reflect.Value) []FieldError {
+	t := v.Type()
+	var errs []FieldError
+	for n := 0; n < t.NumField(); n++ {
+		sf := t.Field(n)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			errs = append(errs, validateStruct(v.Field(n))...)
+			continue
+		}
+		rules := sf.Tag.Get("validate")
+		if rules == "" || !sf.IsExported() {
+			continue
+		}
+		if fe := checkField(fieldName(sf), v.Field(n), rules); fe != nil {
+			errs = append(errs, *fe)
+		}
+	}
+	return errs
+}
+
+// fieldName is how the client spelled the field: its json, query or param name
+func fieldName(sf reflect.StructField) string {
+	for _, tag := range []string{"json", "query", "param"} {
+		if name, _, _ := strings.Cut(sf.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+// checkField applies rules to f and returns the first rule it breaks
+func checkField(name string, f reflect.Value, rules string) *FieldError {
+	if f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			if strings.Contains(","+rules+",", ",required,") {
+				return &FieldError{Field: name, Rule: "required", Message: "required"}
+			}
+			return nil
+		}
+		f = f.Elem()
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		rule, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch rule {
+		case "":
+		case "omitempty":
+			if isZero(f) {
+				return nil
+			}
+		case "required":
+			if isZero(f) {
+				return &FieldError{Field: name, Rule: rule, Message: "required"}
+			}
+		case "min", "max", "gt":
+			if msg := checkBound(f, rule, arg); msg != "" {
+				return &FieldError{Field: name, Rule: rule, Message: msg}
+			}
+		case "oneof":
+			opts := strings.Fields(arg)
+			if !containsString(opts, fmt.Sprint(f.Interface())) {
+				return &FieldError{Field: name, Rule: rule, Message: "must be one of " + strings.Join(opts, ", ")}
+			}
+		default:
+			fm, ok := formats[rule]
+			if !ok {
+				panic(fmt.Sprintf("server: unknown validate rule %q on %s", rule, name))
+			}
+			if f.Kind() == reflect.String && !fm.check(f.String()) {
+				return &FieldError{Field: name, Rule: rule, Message: fm.message}
+			}
+		}
+	}
+	return nil
+}
+
+func isZero(f reflect.Value) bool {
+	if f.Kind() == reflect.String {
+		return strings.TrimSpace(f.String()) == ""
+	}
+	return f.IsZero()
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBound compares a number, duration or string length against arg
+func checkBound(f reflect.Value, rule, arg string) string {
+	var val, bound float64
+	var shown string
+	switch {
+	case f.Type() == durationType:
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			panic(fmt.Sprintf("server: invalid duration %q in %s rule", arg, rule))
+		}
+		val, bound, shown = float64(f.Int()), float64(d), arg
+	case f.Kind() == reflect.String:
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			panic(fmt.Sprintf("server: invalid length %q in %s rule", arg, rule))
+		}
+		val, bound = float64(len(strings.TrimSpace(f.String()))), float64(n)
+		shown = arg + " characters"
+	default:
+		x, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			panic(fmt.Sprintf("server: invalid number %q in %s rule", arg, rule))
+		}
+		switch f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			val = float64(f.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			val = float64(f.Uint())
+		case reflect.Float32, reflect.Float64:
+			val = f.Float()
+		default:
+			panic(fmt.Sprintf("server: %s rule on unsupported kind %s", rule, f.Kind()))
+		}
+		bound, shown = x, arg
+	}
+
+	switch {
+	case rule == "min" && val < bound:
+		return "min " + shown
+	case rule == "max" && val > bound:
+		return "max " + shown
+	case rule == "gt" && val <= bound:
+		return "must be > " + shown
+	}
+	return ""
+}
+
+// bind fills req from the path, query and body, then validates it. Values
+// that do not parse and rules they break are reported together; a field that
+// failed to parse is only reported once.
+func (h *Handlers) bind(c echo.Context, req any) error {
+	var errs []FieldError
+	if err := c.Bind(req); err != nil {
+		ve, ok := err.(*ValidationError)
+		if !ok {
+			return err
+		}
+		for _, fe := range ve.Errors {
+			if fe.Rule == "json" {
+				return ve // the rest of the body is unknown
+			}
+		}
+		errs = ve.Errors
+	}
+
+	if err := c.Validate(req); err != nil {
+		ve, ok := err.(*ValidationError)
+		if !ok {
+			return err
+		}
+	next:
+		for _, fe := range ve.Errors {
+			for _, prev := range errs {
+				if prev.Field == fe.Field {
+					continue next
+				}
+			}
+			errs = append(errs, fe)
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// invalid writes a 400 listing every invalid field. Unlike other error
+// details, field errors are shown outside dev mode: they describe the
+// caller's own input.
+func (h *Handlers) invalid(c echo.Context, err error) error {
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		ve = &ValidationError{Errors: []FieldError{{Field: "body", Rule: "json", Message: "invalid json"}}}
+	}
+	return c.JSON(http.StatusBadRequest, ErrorResponse{
+		Error:    validationMessage(ve.Errors),
+		Code:     http.StatusBadRequest,
+		Category: string(apperr.ValidationFailed),
+		Details:  ve.Errors,
+	})
+}
+
+// invalidField writes a 400 for one field checked outside the validate tags
+func (h *Handlers) invalidField(c echo.Context, field, rule, msg string) error {
+	return h.invalid(c, &ValidationError{Errors: []FieldError{{Field: field, Rule: rule, Message: msg}}})
+}
+
+// validationMessage keeps the short "invalid <field>" message when a single
+// field is at fault
+func validationMessage(errs []FieldError) string {
+	if len(errs) == 0 {
+		return "invalid request"
+	}
+	for _, fe := range errs[1:] {
+		if fe.Field != errs[0].Field {
+			return "invalid request"
+		}
+	}
+	if errs[0].Field == "body" {
+		return "invalid json"
+	}
+	return "invalid " + errs[0].Field
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/jupiter"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeFieldErrors(t *testing.T, rec *httptest.ResponseRecorder) (string, []FieldError) {
+	t.Helper()
+	var resp struct {
+		Error   string       `json:"error"`
+		Code    int          `json:"code"`
+		Details []FieldError `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), rec.Body.String())
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	return resp.Error, resp.Details
+}
+
+func TestValidationReportsEveryField(t *testing.T) {
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{
+		Cache:   cache.NewMemoryCache(10, 0),
+		Jupiter: jupiter.NewClient("http://127.0.0.1:0", ""),
+	}, ServerConfig{})
+
+	rec := get(t, e, "/v1/swaps/recent?limit=abc&pair=nope", "")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	msg, errs := decodeFieldErrors(t, rec)
+	assert.Equal(t, "invalid request", msg)
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "limit", Rule: "type", Message: "must be an integer"},
+		{Field: "pair", Rule: "pair", Message: "expected BASE/QUOTE, e.g. SOL/USDC"},
+	}, errs)
+
+	rec = get(t, e, "/v1/swaps/recent?limit=500", "")
+	msg, errs = decodeFieldErrors(t, rec)
+	assert.Equal(t, "invalid limit", msg)
+	assert.Equal(t, []FieldError{{Field: "limit", Rule: "max", Message: "max 200"}}, errs)
+
+	rec = get(t, e, "/v1/prices/SOL/history?window=48h", "")
+	_, errs = decodeFieldErrors(t, rec)
+	assert.Equal(t, []FieldError{{Field: "window", Rule: "max", Message: "max 24h"}}, errs)
+
+	rec = get(t, e, "/v1/quote?outputMint=USDC&amount=-1&slippageBps=70000&swapMode=Both&onlyDirectRoutes=maybe", "")
+	_, errs = decodeFieldErrors(t, rec)
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "inputMint", Rule: "required", Message: "required"},
+		{Field: "amount", Rule: "uint64", Message: "must be uint64"},
+		{Field: "slippageBps", Rule: "type", Message: "must be uint16"},
+		{Field: "swapMode", Rule: "oneof", Message: "must be one of ExactIn, ExactOut"},
+		{Field: "onlyDirectRoutes", Rule: "type", Message: "must be boolean"},
+	}, errs)
+}
+
+func TestValidationBody(t *testing.T) {
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Swaps: &fakeExecutor{}}, ServerConfig{})
+
+	rec := postSwap(e, "", `{"input_token":"SOL","amount":0}`)
+	msg, errs := decodeFieldErrors(t, rec)
+	assert.Equal(t, "invalid request", msg)
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "output_token", Rule: "required", Message: "required"},
+		{Field: "amount", Rule: "gt", Message: "must be > 0"},
+	}, errs)
+
+	rec = postSwap(e, "", `{"input_token":`)
+	msg, _ = decodeFieldErrors(t, rec)
+	assert.Equal(t, "invalid json", msg)
+}
+
+func TestRequestBinderDefaults(t *testing.T) {
+	e := echo.New()
+	e.Binder = &requestBinder{}
+	e.Validator = requestValidator{}
+
+	req := httptest.NewRequest(http.MethodGet, "/?dexes=Orca,Raydium&dexes=Meteora", strings.NewReader(""))
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	q := struct {
+		Window time.Duration `query:"window" validate:"min=1s"`
+		Dexes  []string      `query:"dexes"`
+		Max    *uint64       `query:"max"`
+	}{Window: time.Minute}
+	require.NoError(t, (&Handlers{}).bind(c, &q))
+	assert.Equal(t, time.Minute, q.Window, "absent params keep their defaults")
+	assert.Equal(t, []string{"Orca", "Raydium", "Meteora"}, q.Dexes)
+	assert.Nil(t, q.Max)
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/labstack/echo/v4"
+)
+
+// WalletAnalytics ranks and profiles trader wallets
+// (implemented by *cache.ClickHouseStore)
+type WalletAnalytics interface {
+	storage.WalletAnalytics
+}
+
+// walletFavoritePairs is how many pairs GET /v1/wallets/:address/stats lists
+const walletFavoritePairs = 5
+
+// TopWallets ranks trader wallets by stablecoin volume or trade count
+// Accepts by (volume or trades, default volume), window (Go duration, default
+// 24h, max 720h) and limit (default 20, max 100)
+func (h *Handlers) TopWallets(c echo.Context) error {
+	if h.Wallets == nil {
+		return h.err(c, http.StatusBadRequest, "wallet analytics is not enabled", nil)
+	}
+	req := TopWalletsRequest{By: storage.WalletOrderVolume, Window: 24 * time.Hour, Limit: 20}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	wallets, err := h.Wallets.TopWallets(ctx, storage.WalletQuery{
+		Since:   time.Now().Add(-req.Window),
+		OrderBy: req.By,
+		Limit:   req.Limit,
+	})
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to rank wallets", err)
+	}
+	return c.JSON(http.StatusOK, TopWalletsResponse{By: req.By, Window: req.Window.String(), Wallets: wallets, Count: len(wallets)})
+}
+
+// WalletStats profiles one wallet: totals, average trade size, favourite
+// pairs and an activity heatmap (window: Go duration, default 168h, max 720h)
+func (h *Handlers) WalletStats(c echo.Context) error {
+	if h.Wallets == nil {
+		return h.err(c, http.StatusBadRequest, "wallet analytics is not enabled", nil)
+	}
+	req := WalletStatsRequest{Window: 7 * 24 * time.Hour}
+	if err := h.bind(c, &req); err != nil {
+		return h.invalid(c, err)
+	}
+
+	ctx, cancel := h.withTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	stats, err := h.Wallets.WalletStats(ctx, req.Address, time.Now().Add(-req.Window), walletFavoritePairs)
+	if err != nil {
+		return h.fail(c, http.StatusInternalServerError, "failed to get wallet stats", err)
+	}
+	if stats == nil {
+		return h.err(c, http.StatusNotFound, "no swaps for wallet in window", nil)
+	}
+	return c.JSON(http.StatusOK, WalletStatsResponse{Window: req.Window.String(), WalletStats: stats})
+}
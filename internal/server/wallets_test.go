@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWallet = "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+
+type fakeWallets struct {
+	query storage.WalletQuery
+	since time.Time
+}
+
+func (f *fakeWallets) TopWallets(_ context.Context, q storage.WalletQuery) ([]models.WalletSummary, error) {
+	f.query = q
+	return []models.WalletSummary{{Wallet: testWallet, Trades: 3, VolumeUSD: 420}}, nil
+}
+
+func (f *fakeWallets) WalletStats(_ context.Context, wallet string, since time.Time, topPairs int) (*models.WalletStats, error) {
+	f.since = since
+	if wallet != testWallet {
+		return nil, nil
+	}
+	s := &models.WalletStats{AvgTradeUSD: 140, FavoritePairs: []models.PairActivity{{Pair: "SOL/USDC", Trades: 3}}}
+	s.Wallet, s.Trades = wallet, 3
+	s.Heatmap[0][13] = 3
+	return s, nil
+}
+
+func TestTopWallets(t *testing.T) {
+	wallets := &fakeWallets{}
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Wallets: wallets}, ServerConfig{})
+
+	rec := get(t, e, "/v1/wallets/top?by=trades&window=1h&limit=5", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp TopWalletsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Count)
+	assert.Equal(t, "1h0m0s", resp.Window)
+	assert.Equal(t, storage.WalletOrderTrades, wallets.query.OrderBy)
+	assert.Equal(t, 5, wallets.query.Limit)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), wallets.query.Since, 5*time.Second)
+
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/wallets/top?by=fees", "").Code)
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/wallets/top?window=2000h", "").Code)
+}
+
+func TestWalletStats(t *testing.T) {
+	e := echo.New()
+	RegisterRoutes(e, &Handlers{Wallets: &fakeWallets{}}, ServerConfig{})
+
+	rec := get(t, e, "/v1/wallets/"+testWallet+"/stats", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp WalletStatsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "168h0m0s", resp.Window)
+	assert.Equal(t, uint64(3), resp.Trades)
+	assert.Equal(t, uint64(3), resp.Heatmap[0][13])
+	assert.Equal(t, "SOL/USDC", resp.FavoritePairs[0].Pair)
+
+	assert.Equal(t, http.StatusNotFound, get(t, e, "/v1/wallets/So11111111111111111111111111111111111111112/stats", "").Code)
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/wallets/not-a-wallet/stats", "").Code)
+
+	// Without ClickHouse the endpoints report they are off
+	e = echo.New()
+	RegisterRoutes(e, &Handlers{}, ServerConfig{})
+	assert.Equal(t, http.StatusBadRequest, get(t, e, "/v1/wallets/top", "").Code)
+}
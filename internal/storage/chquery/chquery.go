@@ -0,0 +1,298 @@
+// Package chquery builds parameterized ClickHouse queries over the swaps
+// table. Filters become placeholders and arguments, never interpolated
+// values, so handlers and stores share one set of typed builders instead of
+// assembling SQL by hand.
+package chquery
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+)
+
+// USDVolume is the USD value of a swap taken from its stablecoin leg, 0
+// without one
+const USDVolume = `multiIf(token_in IN ` + stablecoins + `, amount_in, token_out IN ` + stablecoins + `, amount_out, 0)`
+
+// stablecoins are the tokens taken to be worth one dollar
+const stablecoins = `('USDC', 'USDT')`
+
+// Outcomes of swap_finality rows. Finality is recorded there rather than
+// by rewriting swaps rows, and joined back at read time.
+const (
+	FinalityFinalized = "finalized" // the swap's block was finalized
+	FinalityRetracted = "retracted" // the swap's fork was dropped
+)
+
+// Finalized is the finalized flag of a swap: set in swaps when it was
+// indexed at finalized commitment, or by a later swap_finality row
+const Finalized = `finalized OR signature IN (SELECT signature FROM swap_finality WHERE status = '` + FinalityFinalized + `')`
+
+// NotRetracted keeps swaps whose fork was dropped out of a read of swaps;
+// retractions are rare, so the set it excludes stays small
+const NotRetracted = `signature NOT IN (SELECT signature FROM swap_finality WHERE status = '` + FinalityRetracted + `')`
+
+// Query is a statement with its positional arguments
+type Query struct {
+	SQL  string
+	Args []any
+}
+
+// Where is a conjunction of conditions with placeholders; the zero value
+// matches everything
+type Where struct {
+	conds []string
+	args  []any
+}
+
+// Add appends cond, whose ? placeholders take args in order
+func (w *Where) Add(cond string, args ...any) {
+	w.conds = append(w.conds, cond)
+	w.args = append(w.args, args...)
+}
+
+// Clause returns " WHERE ..." or "" without conditions
+func (w Where) Clause() string {
+	if len(w.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conds, " AND ")
+}
+
+// Args returns the arguments of the conditions, in order
+func (w Where) Args() []any {
+	return w.args
+}
+
+// SwapWhere turns the non-empty fields of f (limit and offset aside) into
+// conditions, leaving out retracted swaps
+func SwapWhere(f storage.SwapFilter) Where {
+	var w Where
+	if !f.From.IsZero() {
+		w.Add("timestamp >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		w.Add("timestamp < ?", f.To)
+	}
+	if f.Pair != "" {
+		w.Add("pair = ?", f.Pair)
+	}
+	if f.Token != "" {
+		w.Add("(token_in = ? OR token_out = ?)", f.Token, f.Token)
+	}
+	if f.Dex != "" {
+		w.Add("dex = ?", f.Dex)
+	}
+	if f.Wallet != "" {
+		w.Add("wallet = ?", f.Wallet)
+	}
+	w.Add(NotRetracted)
+	return w
+}
+
+// RecentSwaps selects columns of the swaps matching f, newest first. A zero
+// f.Limit returns every match.
+func RecentSwaps(columns string, f storage.SwapFilter) Query {
+	w := SwapWhere(f)
+	q := Query{SQL: `SELECT ` + columns + ` FROM swaps` + w.Clause() + ` ORDER BY timestamp DESC, signature`, Args: w.Args()}
+	return q.paged(f.Limit, f.Offset)
+}
+
+// Candles aggregates one pair's swaps into OHLCV candles, oldest first:
+// start, open, high, low, close, volume, USD volume and trades
+func Candles(cq storage.CandleQuery) Query {
+	q := Query{
+		SQL: `SELECT toStartOfInterval(timestamp, toIntervalSecond(?)) AS start,
+			argMin(price, timestamp), max(price), min(price), argMax(price, timestamp),
+			sum(amount_in), sum(` + USDVolume + `), count()
+		FROM swaps
+		WHERE pair = ? AND timestamp >= ? AND timestamp < ? AND ` + NotRetracted + `
+		GROUP BY start
+		ORDER BY start`,
+		Args: []any{uint64(cq.Interval / time.Second), cq.Pair, cq.From, cq.To},
+	}
+	return q.paged(cq.Limit, 0)
+}
+
+// Digest summarises one pair's swaps, or those with one token on either
+// side, over [From, To): trades, volume, USD volume, first and last price,
+// and the signature, amount and USD value of the largest swap. Rows scan
+// into DigestRow.Dest. A token's volume is in the token and its price in
+// USD, from its swaps against a stablecoin.
+func Digest(dq storage.DigestQuery) Query {
+	amount, price := `amount_in`, `price`
+	var args []any
+	if dq.Pair == "" {
+		amount = `if(token_in = ?, amount_in, amount_out)`
+		price = `multiIf(token_in = ? AND token_out IN ` + stablecoins + ` AND amount_in > 0, amount_out / amount_in,
+				token_out = ? AND token_in IN ` + stablecoins + ` AND amount_out > 0, amount_in / amount_out, 0)`
+		args = append(args, dq.Token, dq.Token, dq.Token)
+	}
+	w := SwapWhere(storage.SwapFilter{From: dq.From, To: dq.To, Pair: dq.Pair, Token: dq.Token})
+	return Query{
+		SQL: `SELECT count(), sum(amount), sum(usd), argMinIf(p, timestamp, p > 0), argMaxIf(p, timestamp, p > 0),
+			argMax(signature, (usd, amount)), argMax(amount, (usd, amount)), max(usd)
+		FROM (
+			SELECT timestamp, signature, ` + amount + ` AS amount, ` + USDVolume + ` AS usd, ` + price + ` AS p
+			FROM swaps` + w.Clause() + `
+		)`,
+		Args: append(args, w.Args()...),
+	}
+}
+
+// DigestRow is the row of a Digest
+type DigestRow struct {
+	Trades     uint64
+	Volume     float64
+	VolumeUSD  float64
+	OpenPrice  float64
+	ClosePrice float64
+	Biggest    string // signature
+	BiggestAmt float64
+	BiggestUSD float64
+}
+
+// Dest returns the scan destinations of r in the column order of Digest
+func (r *DigestRow) Dest() []any {
+	return []any{&r.Trades, &r.Volume, &r.VolumeUSD, &r.OpenPrice, &r.ClosePrice, &r.Biggest, &r.BiggestAmt, &r.BiggestUSD}
+}
+
+// Orderings of an Aggregate
+const (
+	ByVolume = "volume_usd DESC, trades DESC, key" // stablecoin volume, then trade count
+	ByTrades = "trades DESC, volume_usd DESC, key"
+)
+
+// groupMetrics are the columns of Group after its key
+const groupMetrics = `count() AS trades, sum(amount_in), sum(` + USDVolume + `) AS volume_usd,
+			countIf(` + USDVolume + ` > 0), argMax(price, timestamp), min(timestamp), max(timestamp),
+			uniqExact(wallet)`
+
+// Aggregate groups the swaps matching Filter by Key and computes the market
+// metrics of Group for each
+type Aggregate struct {
+	Key     string             // grouping expression, e.g. "pair" or "dex"; never user input
+	Filter  storage.SwapFilter // Limit and Offset page through the groups
+	OrderBy string             // ByVolume (default) or ByTrades
+}
+
+// Query builds the aggregation; rows scan into Group.Dest
+func (a Aggregate) Query() Query {
+	order := a.OrderBy
+	if order == "" {
+		order = ByVolume
+	}
+	w := SwapWhere(a.Filter)
+	q := Query{
+		SQL: `SELECT ` + a.Key + ` AS key, ` + groupMetrics + `
+		FROM swaps` + w.Clause() + `
+		GROUP BY key
+		ORDER BY ` + order,
+		Args: w.Args(),
+	}
+	return q.paged(a.Filter.Limit, a.Filter.Offset)
+}
+
+// Total builds the metrics of every swap matching Filter as a single Group
+// with an empty key, e.g. to compute shares of the groups
+func (a Aggregate) Total() Query {
+	w := SwapWhere(a.Filter)
+	return Query{
+		SQL:  `SELECT '' AS key, ` + groupMetrics + ` FROM swaps` + w.Clause(),
+		Args: w.Args(),
+	}
+}
+
+// Group is one row of an Aggregate. Volume is in the key's input tokens, so
+// it only adds up within a pair; the USD figures count swaps with a
+// stablecoin leg.
+type Group struct {
+	Key        string
+	Trades     uint64
+	Volume     float64
+	VolumeUSD  float64
+	USDTrades  uint64 // swaps with a stablecoin leg
+	LastPrice  float64
+	FirstTrade time.Time
+	LastTrade  time.Time
+	Wallets    uint64 // distinct traders; swaps indexed before wallets were recorded count as one
+}
+
+// Dest returns the scan destinations of g in the column order of Aggregate
+func (g *Group) Dest() []any {
+	return []any{&g.Key, &g.Trades, &g.Volume, &g.VolumeUSD, &g.USDTrades, &g.LastPrice, &g.FirstTrade, &g.LastTrade, &g.Wallets}
+}
+
+// AvgUSD is the average USD size of the swaps with a stablecoin leg, 0
+// without any
+func (g *Group) AvgUSD() float64 {
+	if g.USDTrades == 0 {
+		return 0
+	}
+	return g.VolumeUSD / float64(g.USDTrades)
+}
+
+// Activity groups all stored swaps by Key: when each group was first and last
+// traded, on which DEXes, and its trades and volume since Since
+type Activity struct {
+	Key    string    // grouping expression, e.g. "pair"; never user input
+	Since  time.Time // start of the recent window
+	Token  string    // keep swaps with this token on either side
+	Dex    string    // keep groups seen on this DEX, still listing all their DEXes
+	Limit  int
+	Offset int
+}
+
+// Query builds the listing, busiest in the recent window first; rows scan
+// into ActivityRow.Dest
+func (a Activity) Query() Query {
+	var w Where
+	if a.Token != "" {
+		w.Add("(token_in = ? OR token_out = ?)", a.Token, a.Token)
+	}
+	w.Add(NotRetracted)
+	args := append([]any{a.Since, a.Since, a.Since}, w.Args()...)
+	sql := `SELECT ` + a.Key + ` AS key, any(token_in), any(token_out), min(timestamp), max(timestamp),
+			countIf(timestamp >= ?) AS trades, sumIf(amount_in, timestamp >= ?),
+			sumIf(` + USDVolume + `, timestamp >= ?) AS volume_usd, arraySort(groupUniqArray(dex)) AS dexes
+		FROM swaps` + w.Clause() + `
+		GROUP BY key`
+	if a.Dex != "" {
+		sql += `
+		HAVING has(dexes, ?)`
+		args = append(args, a.Dex)
+	}
+	q := Query{SQL: sql + `
+		ORDER BY volume_usd DESC, trades DESC, max(timestamp) DESC, key`, Args: args}
+	return q.paged(a.Limit, a.Offset)
+}
+
+// ActivityRow is one row of an Activity. TokenIn and TokenOut are those of
+// any swap in the group, i.e. the tokens of a pair.
+type ActivityRow struct {
+	Key       string
+	TokenIn   string
+	TokenOut  string
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Trades    uint64 // since Activity.Since, like the volumes
+	Volume    float64
+	VolumeUSD float64
+	Dexes     []string
+}
+
+// Dest returns the scan destinations of r in the column order of Activity
+func (r *ActivityRow) Dest() []any {
+	return []any{&r.Key, &r.TokenIn, &r.TokenOut, &r.FirstSeen, &r.LastSeen, &r.Trades, &r.Volume, &r.VolumeUSD, &r.Dexes}
+}
+
+// paged appends LIMIT and OFFSET when limit is set
+func (q Query) paged(limit, offset int) Query {
+	if limit <= 0 {
+		return q
+	}
+	q.SQL += ` LIMIT ? OFFSET ?`
+	q.Args = append(q.Args, uint64(limit), uint64(offset))
+	return q
+}
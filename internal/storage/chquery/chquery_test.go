@@ -0,0 +1,85 @@
+package chquery
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentSwaps(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := RecentSwaps("signature", storage.SwapFilter{From: from, Token: "SOL", Dex: "Orca", Limit: 10, Offset: 20})
+	assert.Equal(t, `SELECT signature FROM swaps WHERE timestamp >= ? AND (token_in = ? OR token_out = ?) AND dex = ? AND `+NotRetracted+` ORDER BY timestamp DESC, signature LIMIT ? OFFSET ?`, q.SQL)
+	assert.Equal(t, []any{from, "SOL", "SOL", "Orca", uint64(10), uint64(20)}, q.Args)
+
+	q = RecentSwaps("signature", storage.SwapFilter{Wallet: "x' OR 1=1 --"})
+	assert.Equal(t, `SELECT signature FROM swaps WHERE wallet = ? AND `+NotRetracted+` ORDER BY timestamp DESC, signature`, q.SQL, "values never reach the SQL")
+	assert.Equal(t, []any{"x' OR 1=1 --"}, q.Args)
+}
+
+func TestCandles(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := Candles(storage.CandleQuery{Pair: "SOL/USDC", Interval: time.Hour, From: from, To: from.Add(24 * time.Hour), Limit: 24})
+	assert.Contains(t, q.SQL, "toIntervalSecond(?)")
+	assert.Contains(t, q.SQL, NotRetracted)
+	assert.Equal(t, []any{uint64(3600), "SOL/USDC", from, from.Add(24 * time.Hour), uint64(24), uint64(0)}, q.Args)
+}
+
+func TestDigest(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := Digest(storage.DigestQuery{Pair: "SOL/USDC", From: from, To: from.Add(time.Hour)})
+	assert.Contains(t, q.SQL, "amount_in AS amount")
+	assert.Contains(t, q.SQL, "price AS p")
+	assert.Contains(t, q.SQL, "WHERE timestamp >= ? AND timestamp < ? AND pair = ?")
+	assert.Equal(t, []any{from, from.Add(time.Hour), "SOL/USDC"}, q.Args)
+
+	q = Digest(storage.DigestQuery{Token: "BONK", From: from, To: from.Add(time.Hour)})
+	assert.Contains(t, q.SQL, "if(token_in = ?, amount_in, amount_out) AS amount")
+	assert.Contains(t, q.SQL, "(token_in = ? OR token_out = ?)")
+	assert.Equal(t, []any{"BONK", "BONK", "BONK", from, from.Add(time.Hour), "BONK", "BONK"}, q.Args)
+	assert.Equal(t, strings.Count(q.SQL, "?"), len(q.Args))
+
+	var r DigestRow
+	assert.Len(t, r.Dest(), 8)
+}
+
+func TestAggregate(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := Aggregate{Key: "dex", Filter: storage.SwapFilter{From: from, Pair: "SOL/USDC", Limit: 5}, OrderBy: ByTrades}
+
+	q := a.Query()
+	assert.Contains(t, q.SQL, "SELECT dex AS key, count() AS trades")
+	assert.Contains(t, q.SQL, "WHERE timestamp >= ? AND pair = ?")
+	assert.Contains(t, q.SQL, "GROUP BY key\n\t\tORDER BY "+ByTrades+" LIMIT ? OFFSET ?")
+	assert.Equal(t, []any{from, "SOL/USDC", uint64(5), uint64(0)}, q.Args)
+
+	total := a.Total()
+	assert.NotContains(t, total.SQL, "GROUP BY")
+	assert.NotContains(t, total.SQL, "LIMIT")
+	assert.Equal(t, []any{from, "SOL/USDC"}, total.Args)
+
+	var g Group
+	assert.Len(t, g.Dest(), 9)
+	assert.Zero(t, g.AvgUSD())
+	g.VolumeUSD, g.USDTrades = 300, 3
+	assert.Equal(t, 100.0, g.AvgUSD())
+}
+
+func TestActivity(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := Activity{Key: "pair", Since: since, Token: "SOL", Dex: "Orca", Limit: 10}.Query()
+	assert.Contains(t, q.SQL, "WHERE (token_in = ? OR token_out = ?)")
+	assert.Contains(t, q.SQL, "HAVING has(dexes, ?)")
+	assert.Equal(t, []any{since, since, since, "SOL", "SOL", "Orca", uint64(10), uint64(0)}, q.Args)
+
+	q = Activity{Key: "pair", Since: since}.Query()
+	assert.Contains(t, q.SQL, "WHERE "+NotRetracted, "retracted swaps are always left out")
+	assert.NotContains(t, q.SQL, "HAVING")
+	assert.Equal(t, []any{since, since, since}, q.Args)
+
+	var r ActivityRow
+	assert.Len(t, r.Dest(), 9)
+}
@@ -3,12 +3,18 @@ package storage
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
 )
 
 // SwapCache defines the interface for caching swap data
 type SwapCache interface {
+	// ProcessSwap records a swap in the recent lists, updates the output
+	// token's price, publishes it and appends it to the stream, in as few
+	// round trips as the implementation allows
+	ProcessSwap(ctx context.Context, swap *models.SwapEvent) error
+
 	// AddRecentSwap adds a swap to the recent swaps list
 	AddRecentSwap(ctx context.Context, swap *models.SwapEvent) error
 
@@ -18,8 +24,16 @@ type SwapCache interface {
 	// GetRecentSwaps retrieves the most recent swaps
 	GetRecentSwaps(ctx context.Context, limit int64) ([]*models.SwapEvent, error)
 
-	// GetPrice retrieves the current price for a token
-	GetPrice(ctx context.Context, token string) (float64, error)
+	// GetRecentSwapsByPair retrieves the most recent swaps of one pair (e.g. "SOL/USDC")
+	GetRecentSwapsByPair(ctx context.Context, pair string, limit int64) ([]*models.SwapEvent, error)
+
+	// GetPrice retrieves the last price for a token, or nil if none is cached
+	// (never written, or expired)
+	GetPrice(ctx context.Context, token string) (*models.TokenPrice, error)
+
+	// GetPriceHistory retrieves the rolling price history for a token since
+	// the given time, oldest first
+	GetPriceHistory(ctx context.Context, token string, since time.Time) ([]models.PricePoint, error)
 
 	// Ping checks if the cache is reachable
 	Ping(ctx context.Context) error
@@ -32,8 +46,31 @@ type SwapCache interface {
 
 	// SubscribeSwaps subscribes to real-time swap events
 	SubscribeSwaps(ctx context.Context) (<-chan *models.SwapEvent, error)
+
+	// AppendSwap appends a swap event to the durable swap stream
+	AppendSwap(ctx context.Context, swap *models.SwapEvent) error
+
+	// ConsumeSwaps reads the swap stream as a member of a consumer group until
+	// ctx is cancelled. Events the handler accepts (nil error) are acknowledged;
+	// failed ones stay pending and are redelivered.
+	ConsumeSwaps(ctx context.Context, cfg ConsumerConfig, handler StreamHandler) error
 }
 
+// ConsumerConfig identifies a stream consumer. Consumers sharing a Group split
+// the stream between them; each group sees every event.
+type ConsumerConfig struct {
+	Group     string        // consumer group (default constants.StreamDefaultGroup)
+	Consumer  string        // unique name of this worker within the group (required)
+	StartID   string        // where a new group starts: "$" (new events, default) or "0" (full backlog)
+	BatchSize int64         // entries per read (default constants.StreamReadBatchSize)
+	Block     time.Duration // how long a read waits for new entries (default constants.StreamReadBlock)
+	MinIdle   time.Duration // pending entries idle this long are claimed and retried (default constants.StreamClaimMinIdle)
+}
+
+// StreamHandler processes one swap event from the stream; returning an error
+// leaves the event pending so it is retried
+type StreamHandler func(ctx context.Context, swap *models.SwapEvent) error
+
 // SwapStore defines the interface for persistent swap storage
 type SwapStore interface {
 	// InsertSwap inserts a swap event into the store
@@ -46,8 +83,115 @@ type SwapStore interface {
 	io.Closer
 }
 
-// SwapHandler is a function that processes swap events
-type SwapHandler func(*models.SwapEvent)
+// FailedSwapStore records failed swap attempts (implemented by *cache.ClickHouseStore)
+type FailedSwapStore interface {
+	// InsertFailedSwap inserts a failed swap attempt into the store
+	InsertFailedSwap(ctx context.Context, fs *models.FailedSwap) error
+}
+
+// FinalityStore updates stored swaps as their blocks are finalized or
+// dropped with their fork (implemented by *cache.ClickHouseStore and
+// *cache.MemoryStore)
+type FinalityStore interface {
+	// MarkFinalized sets finalized on the swaps with these signatures
+	MarkFinalized(ctx context.Context, signatures []string) error
+
+	// RetractSwaps removes the swaps with these signatures from reads
+	RetractSwaps(ctx context.Context, signatures []string) error
+}
+
+// RetractionPublisher announces retracted swaps to live consumers
+// (implemented by *cache.RedisCache)
+type RetractionPublisher interface {
+	PublishRetraction(ctx context.Context, r *models.SwapRetraction) error
+}
+
+// RecentSwapSource reads the newest stored swaps of every pair, to warm a
+// fresh cache (implemented by *cache.ClickHouseStore)
+type RecentSwapSource interface {
+	// RecentSwapsPerPair returns up to perPair of the newest swaps of each
+	// pair traded since the given time, newest first within a pair
+	RecentSwapsPerPair(ctx context.Context, since time.Time, perPair int) ([]*models.SwapEvent, error)
+}
+
+// SubscriptionStore keeps the pair and token subscriptions of each API key
+// (implemented by *cache.RedisCache)
+type SubscriptionStore interface {
+	// ListSubscriptions returns owner's subscriptions, oldest first
+	ListSubscriptions(ctx context.Context, owner string) ([]*models.Subscription, error)
+	// AddSubscription stores sub, replacing owner's subscription to the
+	// same target. It returns false, storing nothing, when owner already has
+	// limit other subscriptions.
+	AddSubscription(ctx context.Context, owner string, sub *models.Subscription, limit int) (bool, error)
+	// DeleteSubscription removes one of owner's subscriptions; false when
+	// there was none with that ID
+	DeleteSubscription(ctx context.Context, owner, id string) (bool, error)
+	// AllSubscriptions returns every owner's subscriptions, by owner
+	AllSubscriptions(ctx context.Context) (map[string][]*models.Subscription, error)
+}
+
+// SwapHandler is a function that processes swap events. Returning an error
+// tells the provider the swap was not accepted: it must not checkpoint past
+// it, so the swap is delivered again.
+type SwapHandler func(*models.SwapEvent) error
+
+// CheckpointStore persists a stream provider's cursor per source (e.g. the
+// newest handled signature per program address), so restarts resume where
+// they stopped and replicas taking over from each other share progress
+type CheckpointStore interface {
+	// GetCheckpoint returns the saved cursor for source, or "" if none
+	GetCheckpoint(ctx context.Context, source string) (string, error)
+
+	// SetCheckpoint saves the cursor for source
+	SetCheckpoint(ctx context.Context, source, cursor string) error
+}
+
+// Sinks a swap is written to, as recorded on dead letters
+const (
+	SinkStore = "store" // SwapStore (ClickHouse)
+	SinkCache = "cache" // SwapCache (Redis lists, price, Pub/Sub, stream)
+)
+
+// SwapSink is a destination the indexer writes every swap to. Name
+// identifies it on dead letters, so it must be stable across restarts.
+type SwapSink interface {
+	Name() string
+	WriteSwap(ctx context.Context, swap *models.SwapEvent) error
+}
+
+// DeadLetter is a swap that one or more sinks failed to accept
+type DeadLetter struct {
+	Swap     *models.SwapEvent `json:"swap"`
+	Sinks    []string          `json:"sinks"` // sinks still missing the swap
+	Error    string            `json:"error"`
+	FailedAt time.Time         `json:"failed_at"`
+	Attempts int               `json:"attempts"` // redrives tried so far
+}
+
+// DeadLetterQueue holds swaps whose sink writes failed until they are
+// redriven. Redrivers claim entries, so replicas never redrive the same one.
+type DeadLetterQueue interface {
+	// PushDeadLetter appends an entry to the queue
+	PushDeadLetter(ctx context.Context, dl *DeadLetter) error
+
+	// ClaimDeadLetters moves up to n of the oldest entries out of the queue
+	// into worker's claim, oldest first. Entries the worker still held, and
+	// those of workers silent for too long, go back to the queue first.
+	// Entries that cannot be decoded are dropped rather than returned.
+	ClaimDeadLetters(ctx context.Context, worker string, n int64) ([]*DeadLetter, error)
+
+	// AckDeadLetter drops the oldest entry of worker's claim, appending
+	// requeue (if non-nil) to the queue in the same step. It fails once the
+	// claim was handed back for being silent too long.
+	AckDeadLetter(ctx context.Context, worker string, requeue *DeadLetter) error
+
+	// ReleaseDeadLetters returns the rest of worker's claim to the head of
+	// the queue, in order
+	ReleaseDeadLetters(ctx context.Context, worker string) error
+
+	// DeadLetterCount returns the number of queued entries
+	DeadLetterCount(ctx context.Context) (int64, error)
+}
 
 // StreamProvider defines the interface for swap event streaming
 type StreamProvider interface {
@@ -57,3 +201,123 @@ type StreamProvider interface {
 	// Stop stops the stream provider
 	Stop() error
 }
+
+// SwapQuery selects stored swaps by time range and, optionally, pair
+type SwapQuery struct {
+	From  time.Time // inclusive
+	To    time.Time // exclusive
+	Pair  string    // e.g. "SOL/USDC"; empty matches every pair
+	Limit int       // oldest swaps kept at most (0: all)
+}
+
+// SwapHistory reads historical swaps back from persistent storage
+type SwapHistory interface {
+	// ScanSwaps calls fn for every swap matching q, oldest first, and stops
+	// at the first error fn returns
+	ScanSwaps(ctx context.Context, q SwapQuery, fn func(*models.SwapEvent) error) error
+}
+
+// SwapFilter selects stored swaps to list, newest first. Empty fields match
+// everything.
+type SwapFilter struct {
+	From   time.Time // inclusive
+	To     time.Time // exclusive
+	Pair   string
+	Token  string // either side of the swap
+	Dex    string
+	Wallet string
+	Limit  int
+	Offset int
+}
+
+// CandleQuery selects the candles of one pair, oldest first
+type CandleQuery struct {
+	Pair     string
+	Interval time.Duration // candle width, whole seconds
+	From     time.Time     // inclusive
+	To       time.Time     // exclusive
+	Limit    int
+}
+
+// DigestQuery selects the swaps of one pair, or with one token on either
+// side, to summarise in a models.Digest
+type DigestQuery struct {
+	Pair  string // exactly one of Pair and Token
+	Token string
+	From  time.Time // inclusive
+	To    time.Time // exclusive
+}
+
+// DigestSource summarises stored swaps for subscription digests
+// (implemented by *cache.ClickHouseStore)
+type DigestSource interface {
+	// Digest fills the trading figures of a models.Digest
+	Digest(ctx context.Context, q DigestQuery) (*models.Digest, error)
+}
+
+// MarketQuery pages through the pairs or tokens traded since Since, busiest first
+type MarketQuery struct {
+	Since    time.Time
+	Category string // tokens only: keep tokens of this category (e.g. "meme"); empty keeps all
+	Limit    int
+	Offset   int
+}
+
+// SwapExplorer answers ad-hoc read queries over stored swaps
+type SwapExplorer interface {
+	// ListSwaps returns the swaps matching f, newest first
+	ListSwaps(ctx context.Context, f SwapFilter) ([]*models.SwapEvent, error)
+
+	// Candles aggregates one pair's swaps into OHLCV candles; intervals
+	// without swaps are left out
+	Candles(ctx context.Context, q CandleQuery) ([]models.Candle, error)
+
+	// Pairs ranks pairs by stablecoin volume, then trade count
+	Pairs(ctx context.Context, q MarketQuery) ([]models.PairSummary, error)
+
+	// Tokens ranks tokens by stablecoin volume, then trade count, naming and
+	// classifying them from the tokens table
+	Tokens(ctx context.Context, q MarketQuery) ([]models.TokenSummary, error)
+}
+
+// PairListQuery pages through every indexed pair, busiest over the last 24
+// hours first. Empty fields match everything.
+type PairListQuery struct {
+	Token  string // either side of the pair
+	Dex    string // pairs traded on this DEX
+	Limit  int
+	Offset int
+}
+
+// Orderings of WalletQuery
+const (
+	WalletOrderVolume = "volume"
+	WalletOrderTrades = "trades"
+)
+
+// WalletQuery selects the wallets ranked by TopWallets
+type WalletQuery struct {
+	Since   time.Time // swaps at or after this time
+	OrderBy string    // WalletOrderVolume (default) or WalletOrderTrades
+	Limit   int
+}
+
+// WalletAnalytics aggregates stored swaps per trader wallet. Swaps indexed
+// before wallets were recorded are left out.
+type WalletAnalytics interface {
+	// TopWallets ranks the wallets that traded since q.Since
+	TopWallets(ctx context.Context, q WalletQuery) ([]models.WalletSummary, error)
+
+	// WalletStats profiles one wallet's swaps since the given time; nil when it has none
+	WalletStats(ctx context.Context, wallet string, since time.Time, topPairs int) (*models.WalletStats, error)
+}
+
+// MEVStore keeps the sandwich attacks found among stored swaps
+type MEVStore interface {
+	// InsertSandwiches records sandwiches; recording one again replaces it
+	InsertSandwiches(ctx context.Context, sandwiches []models.Sandwich) error
+
+	// MEVStats aggregates the sandwiches since the given time, listing the
+	// top attackers and pools
+	MEVStats(ctx context.Context, since time.Time, top int) (*models.MEVStats, error)
+}
@@ -0,0 +1,79 @@
+package stream
+
+import (
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// Parse results recorded per fetched transaction
+const (
+	parseSwap    = "swap"     // parsed into a swap event
+	parseNotSwap = "not_swap" // valid transaction without a recognisable swap
+	parseFailed  = "failed"   // fetch or parse error, or a failed transaction
+)
+
+var (
+	txTotal = metrics.Default.Counter("indexer_transactions_total",
+		"Transactions fetched by the poller, by DEX and parse result (swap, not_swap, failed).", "dex", "result")
+	pollErrorsTotal = metrics.Default.Counter("indexer_poll_errors_total",
+		"Polls of a program that ended in an error.", "dex")
+	indexedSlot = metrics.Default.Gauge("indexer_last_indexed_slot",
+		"Slot of the newest transaction the poller has handled.", "dex")
+	chainSlot = metrics.Default.Gauge("indexer_chain_slot",
+		"Latest slot reported by the RPC node at the stream commitment.")
+	slotLag = metrics.Default.Gauge("indexer_slot_lag",
+		"Chain tip minus the last indexed slot; 0 when the poller is caught up.", "dex")
+	failedSwapsTotal = metrics.Default.Counter("indexer_failed_swaps_total",
+		"Failed swap attempts recorded in ClickHouse, by DEX and error class.", "dex", "error_class")
+
+	streamLastEvent = metrics.Default.Gauge("stream_last_event_timestamp_seconds",
+		"Unix time of the provider's last swap or heartbeat.", "provider")
+	streamStalled = metrics.Default.Gauge("stream_stalled",
+		"1 while the provider has been silent past STREAM_STALL_TIMEOUT.", "provider")
+	streamRestarts = metrics.Default.Counter("stream_restarts_total",
+		"Stream provider restarts by the supervisor, by reason (stalled, exited).", "provider", "reason")
+)
+
+// dexName labels a program address with its DEX name (the address itself if unknown)
+func dexName(program string) string {
+	for name, addr := range constants.ProgramAddresses {
+		if addr == program {
+			return name
+		}
+	}
+	return program
+}
+
+// Stats returns the progress of every configured program
+func (r *RPCPoller) Stats() []models.ProgramStatus {
+	r.mu.RLock()
+	programs := r.programAddresses
+	r.mu.RUnlock()
+
+	out := make([]models.ProgramStatus, 0, len(programs))
+	for _, program := range programs {
+		dex := dexName(program)
+		st := models.ProgramStatus{
+			Program:          program,
+			Dex:              dex,
+			Active:           r.leads(program),
+			Swaps:            txTotal.With(dex, parseSwap).Value(),
+			NotSwaps:         txTotal.With(dex, parseNotSwap).Value(),
+			ParseFailures:    txTotal.With(dex, parseFailed).Value(),
+			ParseSuccessRate: 1,
+			LastIndexedSlot:  int64(indexedSlot.With(dex).Value()),
+			SlotLag:          int64(slotLag.With(dex).Value()),
+		}
+		if total := st.Swaps + st.NotSwaps + st.ParseFailures; total > 0 {
+			st.ParseSuccessRate = (st.Swaps + st.NotSwaps) / total
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// ChainSlot returns the last chain tip the poller observed
+func (r *RPCPoller) ChainSlot() int64 {
+	return int64(chainSlot.With().Value())
+}
@@ -0,0 +1,185 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// Ingestion modes of the RPC poller (STREAM_MODE)
+const (
+	// ModeSignatures pages getSignaturesForAddress per program and fetches
+	// each new transaction. Cheap, but a burst larger than the batch size
+	// between two polls is only caught up over later polls.
+	ModeSignatures = "signatures"
+	// ModeBlocks reads every block with getBlock and handles each transaction
+	// that touches a polled program. Nothing is missed, at the cost of
+	// downloading whole blocks.
+	ModeBlocks = "blocks"
+)
+
+// BlockLease names the block cursor in ModeBlocks, both as the checkpoint
+// source and as the leader-election lease: one replica reads blocks for all
+// programs
+const BlockLease = "blocks"
+
+// Mode returns the ingestion mode
+func (r *RPCPoller) Mode() string {
+	return r.mode
+}
+
+// leads reports whether this replica currently polls program
+func (r *RPCPoller) leads(program string) bool {
+	switch {
+	case r.gate == nil:
+		return true
+	case r.mode == ModeBlocks:
+		return r.gate(BlockLease)
+	}
+	return r.gate(program)
+}
+
+// pollBlocks reads the blocks after the cursor up to tip, at most
+// constants.MaxBlocksPerPoll of them, and handles the transactions of programs
+func (r *RPCPoller) pollBlocks(ctx context.Context, handler storage.SwapHandler, programs []string, tip int64) error {
+	if r.gate != nil && !r.gate(BlockLease) {
+		r.logger.Debug("not leader for the block stream, skipping poll")
+		return nil
+	}
+
+	next := r.blockCursor(ctx, tip)
+	last := min(tip, next+constants.MaxBlocksPerPoll-1)
+	if next <= last {
+		r.logger.WithFields(logrus.Fields{"from": next, "to": last}).Debug("reading blocks")
+	}
+
+	for slot := next; slot <= last; slot++ {
+		resp, err := r.client.GetBlock(ctx, slot, r.commitment)
+		var rpcErr *rpc.RPCError
+		switch {
+		case errors.As(err, &rpcErr) && (rpcErr.Code == rpc.ErrCodeSlotSkipped || rpcErr.Code == rpc.ErrCodeLongTermStorageSlotSkipped):
+			r.logger.WithField("slot", slot).Debug("skipped slot")
+		case errors.As(err, &rpcErr) && rpcErr.Code == rpc.ErrCodeBlockNotAvailable:
+			return nil // not confirmed on this node yet; read again next poll
+		case err != nil:
+			return fmt.Errorf("block %d: %w", slot, err)
+		default:
+			if err := r.handleBlock(ctx, handler, programs, slot, resp.Result); err != nil {
+				return err
+			}
+		}
+		r.blockCheckpoint(ctx, programs, slot, tip)
+	}
+	return nil
+}
+
+// handleBlock parses every successful transaction of the block that touches
+// one of programs, and records the failed ones. A rejected swap stops the
+// block, so it is read again next poll; swaps before it in the block are then
+// handled twice.
+func (r *RPCPoller) handleBlock(ctx context.Context, handler storage.SwapHandler, programs []string, slot int64, block *rpc.Block) error {
+	if block == nil {
+		return nil
+	}
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		if tx.Transaction == nil || len(tx.Transaction.Signatures) == 0 {
+			continue
+		}
+		program := touchedProgram(tx.Transaction, programs)
+		if program == "" {
+			continue
+		}
+		dex := dexName(program)
+		sig := rpc.SignatureInfo{Signature: tx.Transaction.Signatures[0], Slot: slot, BlockTime: block.BlockTime}
+
+		if tx.Meta != nil && tx.Meta.Err != nil {
+			txTotal.With(dex, parseFailed).Inc()
+			r.recordFailed(ctx, program, sig, tx)
+			continue
+		}
+		swap, err := r.swapFromTransaction(ctx, program, sig, tx)
+		switch {
+		case err != nil:
+			r.logger.WithError(err).WithField("signature", sig.Signature[:8]).Warn("failed to parse transaction")
+			txTotal.With(dex, parseFailed).Inc()
+		case swap == nil:
+			txTotal.With(dex, parseNotSwap).Inc()
+		default:
+			txTotal.With(dex, parseSwap).Inc()
+			if err := handler(swap); err != nil {
+				return fmt.Errorf("swap %s not accepted, retrying block %d next poll: %w", sig.Signature[:8], slot, err)
+			}
+		}
+	}
+	return nil
+}
+
+// touchedProgram returns the first of programs among the transaction's
+// accounts, or "". Invoked programs are always static account keys.
+func touchedProgram(tx *rpc.Transaction, programs []string) string {
+	for _, program := range programs {
+		if slices.ContainsFunc(tx.Message.AccountKeys, func(k rpc.AccountKey) bool { return k.Pubkey == program }) {
+			return program
+		}
+	}
+	return ""
+}
+
+// blockCursor returns the next slot to read: after the shared checkpoint if
+// there is one (another replica may have advanced it), else the local
+// cursor, else the chain tip
+func (r *RPCPoller) blockCursor(ctx context.Context, tip int64) int64 {
+	r.mu.RLock()
+	next := r.nextSlot
+	r.mu.RUnlock()
+
+	if r.checkpoints != nil {
+		saved, err := r.checkpoints.GetCheckpoint(ctx, BlockLease)
+		switch {
+		case err != nil:
+			r.logger.WithError(err).Warn("failed to load block checkpoint, using local cursor")
+		case saved != "":
+			if slot, err := strconv.ParseInt(saved, 10, 64); err == nil {
+				next = slot + 1
+			} else {
+				r.logger.WithField("checkpoint", saved).Warn("ignoring invalid block checkpoint")
+			}
+		}
+	}
+
+	if next == 0 {
+		r.logger.WithField("slot", tip).Info("no block checkpoint, starting at the chain tip")
+		next = tip
+	}
+	return next
+}
+
+// blockCheckpoint records slot as read for every program
+func (r *RPCPoller) blockCheckpoint(ctx context.Context, programs []string, slot, tip int64) {
+	r.mu.Lock()
+	r.nextSlot = slot + 1
+	r.mu.Unlock()
+
+	for _, program := range programs {
+		dex := dexName(program)
+		indexedSlot.With(dex).Set(float64(slot))
+		slotLag.With(dex).Set(float64(max(tip-slot, 0)))
+	}
+
+	if r.checkpoints != nil {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+		defer cancel()
+		if err := r.checkpoints.SetCheckpoint(ctx, BlockLease, strconv.FormatInt(slot, 10)); err != nil {
+			r.logger.WithError(err).Warn("failed to save block checkpoint")
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProgram = "whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc"
+	testSOL     = "So11111111111111111111111111111111111111112"
+	testUSDC    = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+)
+
+type memCheckpoints struct {
+	mu sync.Mutex
+	m  map[string]string
+}
+
+func (c *memCheckpoints) GetCheckpoint(_ context.Context, source string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m[source], nil
+}
+
+func (c *memCheckpoints) SetCheckpoint(_ context.Context, source, cursor string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[source] = cursor
+	return nil
+}
+
+// blockTx is a jsonParsed transaction of the trader selling 1 SOL for 150 USDC
+func blockTx(sig string, keys ...string) map[string]any {
+	accountKeys := []map[string]any{}
+	for _, k := range keys {
+		accountKeys = append(accountKeys, map[string]any{"pubkey": k})
+	}
+	balance := func(i int, mint, owner, amount string, ui float64) map[string]any {
+		return map[string]any{"accountIndex": i, "mint": mint, "owner": owner,
+			"uiTokenAmount": map[string]any{"amount": amount, "decimals": 6, "uiAmount": ui}}
+	}
+	return map[string]any{
+		"transaction": map[string]any{"signatures": []string{sig}, "message": map[string]any{"accountKeys": accountKeys}},
+		"meta": map[string]any{
+			"err":               nil,
+			"preTokenBalances":  []any{balance(1, testSOL, "trader", "2000000", 2), balance(2, testUSDC, "trader", "0", 0)},
+			"postTokenBalances": []any{balance(1, testSOL, "trader", "1000000", 1), balance(2, testUSDC, "trader", "150000000", 150)},
+		},
+	}
+}
+
+func newBlockServer(t *testing.T, blocks map[int64]any) *rpc.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			Params []any  `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		var resp map[string]any
+		switch req.Method {
+		case "getSlot":
+			resp = map[string]any{"result": 12}
+		case "getBlock":
+			assert.Equal(t, "finalized", req.Params[1].(map[string]any)["commitment"])
+			if b, ok := blocks[int64(req.Params[0].(float64))]; ok {
+				resp = map[string]any{"result": b}
+			} else {
+				resp = map[string]any{"error": map[string]any{"code": rpc.ErrCodeSlotSkipped, "message": "Slot was skipped"}}
+			}
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+		resp["jsonrpc"], resp["id"] = "2.0", 1
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return rpc.NewClient(rpc.ClientConfig{BaseURL: srv.URL})
+}
+
+func TestPollBlocks(t *testing.T) {
+	client := newBlockServer(t, map[int64]any{
+		10: map[string]any{"blockTime": 1700000000, "transactions": []any{
+			blockTx("sigOther111", "trader", "otherProgram"),
+			blockTx("sigSwap1111", "trader", "pool", testProgram),
+		}},
+		12: map[string]any{"blockTime": 1700000001, "transactions": []any{}},
+	})
+	checkpoints := &memCheckpoints{m: map[string]string{BlockLease: "9"}}
+	r := NewRPCPoller(RPCPollerConfig{
+		RPCClient:        client,
+		ProgramAddresses: []string{testProgram},
+		Commitment:       "finalized",
+		Mode:             ModeBlocks,
+		Checkpoints:      checkpoints,
+	})
+
+	var swaps []*models.SwapEvent
+	require.NoError(t, r.poll(context.Background(), func(s *models.SwapEvent) error {
+		swaps = append(swaps, s)
+		return nil
+	}))
+
+	require.Len(t, swaps, 1, "only the transaction touching the program is handled")
+	assert.Equal(t, "sigSwap1111", swaps[0].Signature)
+	assert.Equal(t, uint64(10), swaps[0].Slot)
+	assert.Equal(t, testSOL+"/"+testUSDC, swaps[0].Pair, "tokens are labeled by the indexer")
+	assert.Equal(t, uint64(1000000), swaps[0].AmountInRaw)
+	assert.Equal(t, "12", checkpoints.m[BlockLease], "the skipped slot 11 is passed over")
+
+	// nothing new until the tip moves
+	require.NoError(t, r.poll(context.Background(), func(*models.SwapEvent) error {
+		t.Error("block handled twice")
+		return nil
+	}))
+}
@@ -0,0 +1,110 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+// recordFailed stores a failed transaction of program as a failed swap
+// attempt, when FailedSwaps is set. tx is fetched when nil. It is best
+// effort: errors are logged and never hold back the cursor.
+func (r *RPCPoller) recordFailed(ctx context.Context, program string, sig rpc.SignatureInfo, tx *rpc.TransactionResult) {
+	if r.failedSwaps == nil {
+		return
+	}
+	if tx == nil {
+		resp, err := r.client.GetTransaction(ctx, sig.Signature, r.commitment)
+		if err != nil {
+			r.logger.WithError(err).WithField("signature", sig.Signature[:8]).Warn("failed to fetch failed transaction, recording it without a wallet")
+		} else {
+			tx = resp.Result
+		}
+	}
+
+	fs := failedSwap(program, sig, tx)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := r.failedSwaps.InsertFailedSwap(ctx, fs); err != nil {
+		r.logger.WithError(err).WithField("signature", sig.Signature[:8]).Warn("failed to record failed swap")
+		return
+	}
+	failedSwapsTotal.With(fs.Dex, fs.ErrorClass).Inc()
+	logging.Swaps.Debug(logging.Entry(ctx, r.logger).WithFields(logrus.Fields{
+		"signature": sig.Signature[:8],
+		"error":     fs.ErrorClass,
+	}), "recorded failed swap")
+}
+
+// failedSwap describes a failed transaction of program. The error comes from
+// tx when it was fetched, else from the signature listing.
+func failedSwap(program string, sig rpc.SignatureInfo, tx *rpc.TransactionResult) *models.FailedSwap {
+	txErr := sig.Err
+	var wallet string
+	if tx != nil {
+		if tx.Meta != nil && tx.Meta.Err != nil {
+			txErr = tx.Meta.Err
+		}
+		wallet = feePayer(tx.Transaction)
+	}
+
+	class, code, index := classifyTxError(txErr)
+	raw, _ := json.Marshal(txErr)
+	return &models.FailedSwap{
+		Signature:        sig.Signature,
+		Timestamp:        time.Unix(sig.BlockTime, 0),
+		Slot:             uint64(sig.Slot),
+		BlockTime:        sig.BlockTime,
+		ProgramID:        program,
+		Dex:              dexName(program),
+		Wallet:           wallet,
+		ErrorClass:       class,
+		ErrorCode:        code,
+		InstructionIndex: index,
+		Error:            string(raw),
+	}
+}
+
+// classifyTxError reduces a transaction error, as decoded from JSON, to its
+// class, the program's custom error code (-1 if none) and the failing
+// instruction (-1 if none). Errors look like "AccountInUse",
+// {"InsufficientFundsForRent":{"account_index":0}} or
+// {"InstructionError":[2,{"Custom":6001}]}.
+func classifyTxError(txErr any) (class string, code int64, index int) {
+	code, index = -1, -1
+	switch e := txErr.(type) {
+	case string:
+		return e, code, index
+	case map[string]any:
+		for k, v := range e {
+			class = k
+			args, ok := v.([]any)
+			if k != "InstructionError" || !ok || len(args) != 2 {
+				break
+			}
+			if i, ok := args[0].(float64); ok {
+				index = int(i)
+			}
+			switch inner := args[1].(type) {
+			case string:
+				class += "/" + inner
+			case map[string]any:
+				for ik, iv := range inner {
+					class += "/" + ik
+					if n, ok := iv.(float64); ok && ik == "Custom" {
+						code = int64(n)
+					}
+				}
+			}
+		}
+		if class != "" {
+			return class, code, index
+		}
+	}
+	return "Unknown", code, index
+}
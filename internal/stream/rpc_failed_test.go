@@ -0,0 +1,76 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memFailedSwaps struct {
+	rows []*models.FailedSwap
+}
+
+func (m *memFailedSwaps) InsertFailedSwap(_ context.Context, fs *models.FailedSwap) error {
+	m.rows = append(m.rows, fs)
+	return nil
+}
+
+func TestClassifyTxError(t *testing.T) {
+	tests := []struct {
+		err   string
+		class string
+		code  int64
+		index int
+	}{
+		{`"AccountInUse"`, "AccountInUse", -1, -1},
+		{`{"InsufficientFundsForRent":{"account_index":0}}`, "InsufficientFundsForRent", -1, -1},
+		{`{"InstructionError":[2,{"Custom":6001}]}`, "InstructionError/Custom", 6001, 2},
+		{`{"InstructionError":[0,"ComputationalBudgetExceeded"]}`, "InstructionError/ComputationalBudgetExceeded", -1, 0},
+		{`42`, "Unknown", -1, -1},
+	}
+	for _, tt := range tests {
+		var txErr any
+		require.NoError(t, json.Unmarshal([]byte(tt.err), &txErr))
+		class, code, index := classifyTxError(txErr)
+		assert.Equal(t, tt.class, class, tt.err)
+		assert.Equal(t, tt.code, code, tt.err)
+		assert.Equal(t, tt.index, index, tt.err)
+	}
+}
+
+func TestPollBlocksRecordsFailedSwaps(t *testing.T) {
+	failed := blockTx("sigFail1111", "trader", "pool", testProgram)
+	failed["meta"].(map[string]any)["err"] = map[string]any{"InstructionError": []any{1, map[string]any{"Custom": 6001}}}
+	client := newBlockServer(t, map[int64]any{
+		12: map[string]any{"blockTime": 1700000000, "transactions": []any{failed}},
+	})
+	store := &memFailedSwaps{}
+	r := NewRPCPoller(RPCPollerConfig{
+		RPCClient:        client,
+		ProgramAddresses: []string{testProgram},
+		Commitment:       "finalized",
+		Mode:             ModeBlocks,
+		Checkpoints:      &memCheckpoints{m: map[string]string{BlockLease: "11"}},
+		FailedSwaps:      store,
+	})
+
+	require.NoError(t, r.poll(context.Background(), func(*models.SwapEvent) error {
+		t.Error("failed transaction handled as a swap")
+		return nil
+	}))
+
+	require.Len(t, store.rows, 1)
+	fs := store.rows[0]
+	assert.Equal(t, "sigFail1111", fs.Signature)
+	assert.Equal(t, uint64(12), fs.Slot)
+	assert.Equal(t, testProgram, fs.ProgramID)
+	assert.Equal(t, "trader", fs.Wallet)
+	assert.Equal(t, "InstructionError/Custom", fs.ErrorClass)
+	assert.Equal(t, int64(6001), fs.ErrorCode)
+	assert.Equal(t, 1, fs.InstructionIndex)
+	assert.JSONEq(t, `{"InstructionError":[1,{"Custom":6001}]}`, fs.Error)
+}
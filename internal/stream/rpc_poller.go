@@ -2,28 +2,47 @@ package stream
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/logging"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/tokens"
 
+	"github.com/mr-tron/base58"
 	"github.com/sirupsen/logrus"
 )
 
 // RPCPoller implements StreamProvider for polling Solana RPC
 type RPCPoller struct {
-	client           *rpc.Client
+	client      *rpc.Client
+	logger      *logrus.Logger
+	checkpoints storage.CheckpointStore // optional shared cursor store
+	failedSwaps storage.FailedSwapStore // optional; records failed transactions
+	decimals    *tokens.Resolver        // optional; learns the decimals of every mint seen
+	gate        func(program string) bool
+	commitment  string
+	mode        string // ModeSignatures or ModeBlocks
+
+	// intervalChanged wakes Start so a new poll interval applies immediately
+	intervalChanged chan struct{}
+
+	mu               sync.RWMutex
 	programAddresses []string
 	pollInterval     time.Duration
-	logger           *logrus.Logger
-
-	mu            sync.RWMutex
-	lastSignature string
-	running       bool
+	batchSize        int
+	txFetchDelay     time.Duration
+	lastSignatures   map[string]string // program address -> newest seen signature
+	nextSlot         int64             // next block to read in ModeBlocks; 0 until known
+	running          bool
+	paused           bool
+	heartbeat        func() // called after every successful poll
 }
 
 // RPCPollerConfig holds configuration for the RPC poller
@@ -31,7 +50,28 @@ type RPCPollerConfig struct {
 	RPCClient        *rpc.Client
 	ProgramAddresses []string
 	PollInterval     time.Duration
+	BatchSize        int           // Signatures fetched per poll (default: constants.SignatureBatchSize)
+	TxFetchDelay     time.Duration // Delay between getTransaction calls (0: none)
+	Commitment       string        // confirmed or finalized (default: constants.StreamCommitment)
+	Mode             string        // ModeSignatures (default) or ModeBlocks
 	Logger           *logrus.Logger
+
+	// Checkpoints, if set, persists the newest handled signature per program
+	// so restarts and other replicas resume from it
+	Checkpoints storage.CheckpointStore
+
+	// Gate, if set, reports whether this replica may poll a program right now
+	// (e.g. leader election); programs it rejects are skipped
+	Gate func(program string) bool
+
+	// FailedSwaps, if set, records every failed transaction of a polled
+	// program as a failed swap attempt. In ModeSignatures this costs one more
+	// getTransaction per failed signature, for the wallet.
+	FailedSwaps storage.FailedSwapStore
+
+	// Decimals, if set, learns the decimals of every mint in parsed token
+	// balances, so other processes resolve them without an RPC call
+	Decimals *tokens.Resolver
 }
 
 // NewRPCPoller creates a new RPC poller
@@ -46,11 +86,115 @@ func NewRPCPoller(cfg RPCPollerConfig) *RPCPoller {
 		}
 	}
 
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = constants.SignatureBatchSize
+	}
+
+	if cfg.TxFetchDelay < 0 {
+		cfg.TxFetchDelay = 0
+	}
+
+	if cfg.Commitment == "" {
+		cfg.Commitment = constants.StreamCommitment
+	}
+
+	if cfg.Mode == "" {
+		cfg.Mode = ModeSignatures
+	}
+
 	return &RPCPoller{
 		client:           cfg.RPCClient,
+		logger:           cfg.Logger,
+		checkpoints:      cfg.Checkpoints,
+		failedSwaps:      cfg.FailedSwaps,
+		decimals:         cfg.Decimals,
+		gate:             cfg.Gate,
+		commitment:       cfg.Commitment,
+		mode:             cfg.Mode,
+		intervalChanged:  make(chan struct{}, 1),
 		programAddresses: cfg.ProgramAddresses,
 		pollInterval:     cfg.PollInterval,
-		logger:           cfg.Logger,
+		batchSize:        cfg.BatchSize,
+		txFetchDelay:     cfg.TxFetchDelay,
+		lastSignatures:   make(map[string]string),
+	}
+}
+
+// SetPollInterval changes the poll interval of a running poller
+func (r *RPCPoller) SetPollInterval(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	r.mu.Lock()
+	r.pollInterval = d
+	r.mu.Unlock()
+
+	select {
+	case r.intervalChanged <- struct{}{}:
+	default:
+	}
+}
+
+// SetProgramAddresses replaces the set of polled programs; cursors for programs
+// that stay in the list are kept
+func (r *RPCPoller) SetProgramAddresses(addrs []string) {
+	if len(addrs) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.programAddresses = append([]string(nil), addrs...)
+}
+
+// SetBatchSize changes how many signatures are fetched per poll
+func (r *RPCPoller) SetBatchSize(n int) {
+	if n <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batchSize = n
+}
+
+// SetTxFetchDelay changes the delay between getTransaction calls; 0
+// removes it
+func (r *RPCPoller) SetTxFetchDelay(d time.Duration) {
+	if d < 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txFetchDelay = d
+}
+
+// SetPaused stops or resumes polling without losing the signature cursor;
+// swaps that land while paused are picked up on resume (up to the batch size)
+func (r *RPCPoller) SetPaused(paused bool) {
+	r.mu.Lock()
+	changed := r.paused != paused
+	r.paused = paused
+	r.mu.Unlock()
+
+	if changed {
+		r.logger.WithField("paused", paused).Info("poller pause state changed")
+	}
+}
+
+// OnHeartbeat sets fn to be called after every poll that succeeds, and on
+// every tick while paused (implements Heartbeater)
+func (r *RPCPoller) OnHeartbeat(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.heartbeat = fn
+}
+
+// beat calls the heartbeat function, if any
+func (r *RPCPoller) beat() {
+	r.mu.RLock()
+	fn := r.heartbeat
+	r.mu.RUnlock()
+	if fn != nil {
+		fn()
 	}
 }
 
@@ -62,14 +206,16 @@ func (r *RPCPoller) Start(ctx context.Context, handler storage.SwapHandler) erro
 		return fmt.Errorf("poller already running")
 	}
 	r.running = true
+	interval := r.pollInterval
+	programs := r.programAddresses
 	r.mu.Unlock()
 
-	ticker := time.NewTicker(r.pollInterval)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	r.logger.WithFields(logrus.Fields{
-		"interval": r.pollInterval,
-		"programs": r.programAddresses,
+		"interval": interval,
+		"programs": programs,
 	}).Info("starting RPC polling")
 
 	for {
@@ -80,10 +226,28 @@ func (r *RPCPoller) Start(ctx context.Context, handler storage.SwapHandler) erro
 			r.mu.Unlock()
 			return ctx.Err()
 
+		case <-r.intervalChanged:
+			r.mu.RLock()
+			interval = r.pollInterval
+			r.mu.RUnlock()
+			ticker.Reset(interval)
+			r.logger.WithField("interval", interval).Info("poll interval updated")
+
 		case <-ticker.C:
+			r.mu.RLock()
+			paused := r.paused
+			r.mu.RUnlock()
+			if paused {
+				r.logger.Debug("poller paused, skipping poll")
+				r.beat() // paused on purpose, not stalled
+				continue
+			}
+
 			if err := r.poll(ctx, handler); err != nil {
 				r.logger.WithError(err).Error("poll error")
+				continue
 			}
+			r.beat()
 		}
 	}
 }
@@ -96,87 +260,198 @@ func (r *RPCPoller) Stop() error {
 	return nil
 }
 
-// poll fetches and processes new transactions
+// poll fetches and processes new transactions for every configured program
 func (r *RPCPoller) poll(ctx context.Context, handler storage.SwapHandler) error {
-	opts := map[string]interface{}{
-		"limit": constants.SignatureBatchSize,
+	r.mu.RLock()
+	programs := r.programAddresses
+	r.mu.RUnlock()
+
+	// chain tip for the slot lag metric; signature polling goes ahead without it
+	tip, err := r.client.GetSlot(ctx, r.commitment)
+	if err != nil {
+		r.logger.WithError(err).Debug("failed to get chain slot")
+	} else {
+		chainSlot.With().Set(float64(tip))
+	}
+
+	if r.mode == ModeBlocks {
+		if err != nil {
+			return fmt.Errorf("failed to get chain slot: %w", err)
+		}
+		if err := r.pollBlocks(ctx, handler, programs, tip); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			for _, program := range programs {
+				pollErrorsTotal.With(dexName(program)).Inc()
+			}
+			return err
+		}
+		return nil
+	}
+
+	var errs []error
+	for _, program := range programs {
+		if err := r.pollProgram(ctx, handler, program); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			pollErrorsTotal.With(dexName(program)).Inc()
+			errs = append(errs, fmt.Errorf("%s: %w", program, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("poll errors: %v", errs)
+	}
+	return nil
+}
+
+// pollProgram fetches and processes new transactions for a single program
+func (r *RPCPoller) pollProgram(ctx context.Context, handler storage.SwapHandler, program string) error {
+	if r.gate != nil && !r.gate(program) {
+		r.logger.WithField("program", program).Debug("not leader for program, skipping poll")
+		return nil
 	}
 
 	r.mu.RLock()
-	lastSig := r.lastSignature
+	lastSig := r.lastSignatures[program]
+	batchSize := r.batchSize
+	txFetchDelay := r.txFetchDelay
 	r.mu.RUnlock()
 
+	// the shared checkpoint wins: another replica may have advanced it
+	if r.checkpoints != nil {
+		saved, err := r.checkpoints.GetCheckpoint(ctx, program)
+		if err != nil {
+			r.logger.WithError(err).WithField("program", program).Warn("failed to load checkpoint, using local cursor")
+		} else if saved != "" {
+			lastSig = saved
+		}
+	}
+
+	opts := map[string]interface{}{
+		"limit":      batchSize,
+		"commitment": r.commitment,
+	}
+
 	if lastSig != "" {
 		opts["until"] = lastSig
 		r.logger.WithField("after", lastSig[:8]).Debug("fetching new signatures")
 	}
 
 	// Fetch signatures
-	sigResp, err := r.client.GetSignaturesForAddress(ctx, r.programAddresses[0], opts)
+	sigResp, err := r.client.GetSignaturesForAddress(ctx, program, opts)
 	if err != nil {
 		return fmt.Errorf("failed to get signatures: %w", err)
 	}
 
+	dex := dexName(program)
 	if len(sigResp.Result) == 0 {
 		r.logger.Debug("no new transactions")
+		slotLag.With(dex).Set(0) // caught up with the chain for this program
 		return nil
 	}
 
 	r.logger.WithField("count", len(sigResp.Result)).Info("found new signatures")
 
-	// Update last signature
-	r.mu.Lock()
-	r.lastSignature = sigResp.Result[0].Signature
-	r.mu.Unlock()
-
-	// Process each transaction with delay to avoid rate limits
-	for i, sig := range sigResp.Result {
-		if sig.Err != nil {
+	// Process oldest first and move the cursor past each signature only once
+	// its swap is accepted, so a rejected swap is fetched again on the next poll
+	sigs := sigResp.Result
+	for i := range sigs {
+		sig := sigs[len(sigs)-1-i]
+		failed := sig.Err != nil
+		if failed && r.failedSwaps == nil {
 			r.logger.WithField("signature", sig.Signature[:8]).Debug("skipping failed transaction")
+			txTotal.With(dex, parseFailed).Inc()
+			r.checkpoint(ctx, program, sig)
 			continue
 		}
 
 		// Add delay between requests to avoid rate limiting
-		if i > 0 {
-			r.logger.WithField("delay", constants.DelayBetweenTxFetch).Debug("waiting before next request")
+		if i > 0 && txFetchDelay > 0 {
+			r.logger.WithField("delay", txFetchDelay).Debug("waiting before next request")
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case <-time.After(constants.DelayBetweenTxFetch):
+			case <-time.After(txFetchDelay):
 			}
 		}
 
-		r.logger.WithFields(logrus.Fields{
-			"index":     fmt.Sprintf("%d/%d", i+1, len(sigResp.Result)),
+		if failed {
+			txTotal.With(dex, parseFailed).Inc()
+			r.recordFailed(ctx, program, sig, nil)
+			r.checkpoint(ctx, program, sig)
+			continue
+		}
+
+		logging.Swaps.Debug(logging.Entry(ctx, r.logger).WithFields(logrus.Fields{
+			"index":     fmt.Sprintf("%d/%d", i+1, len(sigs)),
 			"signature": sig.Signature[:8],
-		}).Debug("processing transaction")
+		}), "processing transaction")
 
-		swap, err := r.parseTransaction(ctx, sig.Signature, sig.BlockTime)
+		swap, err := r.parseTransaction(ctx, program, sig)
 		if err != nil {
 			r.logger.WithError(err).WithField("signature", sig.Signature[:8]).Warn("failed to parse transaction")
+			txTotal.With(dex, parseFailed).Inc()
+			r.checkpoint(ctx, program, sig)
 			continue
 		}
 
-		if swap != nil {
-			handler(swap)
+		if swap == nil {
+			txTotal.With(dex, parseNotSwap).Inc()
+		} else {
+			txTotal.With(dex, parseSwap).Inc()
+			if err := handler(swap); err != nil {
+				return fmt.Errorf("swap %s not accepted, retrying from it next poll: %w", sig.Signature[:8], err)
+			}
 		}
+		r.checkpoint(ctx, program, sig)
 	}
 
 	return nil
 }
 
-// parseTransaction fetches and parses a transaction into a SwapEvent
-func (r *RPCPoller) parseTransaction(ctx context.Context, signature string, blockTime int64) (*models.SwapEvent, error) {
-	txResp, err := r.client.GetTransaction(ctx, signature)
+// checkpoint records sig as the newest handled one for program. It is saved
+// even while the poller is stopping, so a drained swap is not fetched again.
+func (r *RPCPoller) checkpoint(ctx context.Context, program string, sig rpc.SignatureInfo) {
+	r.mu.Lock()
+	r.lastSignatures[program] = sig.Signature
+	r.mu.Unlock()
+
+	dex := dexName(program)
+	indexedSlot.With(dex).Set(float64(sig.Slot))
+	if tip := int64(chainSlot.With().Value()); tip >= sig.Slot {
+		slotLag.With(dex).Set(float64(tip - sig.Slot))
+	}
+
+	if r.checkpoints != nil {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+		defer cancel()
+		if err := r.checkpoints.SetCheckpoint(ctx, program, sig.Signature); err != nil {
+			r.logger.WithError(err).WithField("program", program).Warn("failed to save checkpoint")
+		}
+	}
+}
+
+// parseTransaction fetches and parses a transaction of program into a SwapEvent
+func (r *RPCPoller) parseTransaction(ctx context.Context, program string, sig rpc.SignatureInfo) (*models.SwapEvent, error) {
+	txResp, err := r.client.GetTransaction(ctx, sig.Signature, r.commitment)
 	if err != nil {
 		return nil, err
 	}
+	return r.swapFromTransaction(ctx, program, sig, txResp.Result)
+}
 
-	if txResp.Result == nil || txResp.Result.Meta == nil {
+// swapFromTransaction turns a fetched transaction of program into a SwapEvent; it
+// returns nil for a transaction that is not a swap
+func (r *RPCPoller) swapFromTransaction(ctx context.Context, program string, sig rpc.SignatureInfo, tx *rpc.TransactionResult) (*models.SwapEvent, error) {
+	signature := sig.Signature
+	if tx == nil || tx.Meta == nil {
 		return nil, fmt.Errorf("empty transaction result")
 	}
 
-	meta := txResp.Result.Meta
+	meta := tx.Meta
 
 	if meta.Err != nil {
 		return nil, fmt.Errorf("transaction failed")
@@ -188,13 +463,16 @@ func (r *RPCPoller) parseTransaction(ctx context.Context, signature string, bloc
 		return nil, nil
 	}
 
-	// Calculate balance changes
+	// Calculate balance changes, in UI units and exactly in base units
 	balanceChanges := make(map[int]float64)
+	rawChanges := make(map[int]int64)
 	for _, pre := range meta.PreTokenBalances {
 		balanceChanges[pre.AccountIndex] = -pre.UITokenAmount.UIAmount
+		rawChanges[pre.AccountIndex] = -rawAmount(pre.UITokenAmount)
 	}
 	for _, post := range meta.PostTokenBalances {
 		balanceChanges[post.AccountIndex] += post.UITokenAmount.UIAmount
+		rawChanges[post.AccountIndex] += rawAmount(post.UITokenAmount)
 	}
 
 	// Collect non-zero changes
@@ -203,12 +481,21 @@ func (r *RPCPoller) parseTransaction(ctx context.Context, signature string, bloc
 		change := balanceChanges[post.AccountIndex]
 		if change != 0 {
 			changes = append(changes, rpc.BalanceChange{
-				Mint:   post.Mint,
-				Amount: change,
+				Mint:     post.Mint,
+				Owner:    post.Owner,
+				Amount:   change,
+				Raw:      rawChanges[post.AccountIndex],
+				Decimals: uint8(post.UITokenAmount.Decimals),
 			})
 		}
 	}
 
+	if r.decimals != nil {
+		for _, ch := range changes {
+			r.decimals.Learn(ctx, ch.Mint, ch.Decimals)
+		}
+	}
+
 	if len(changes) < 2 {
 		r.logger.WithField("signature", signature[:8]).Debug("not a swap transaction (no token changes)")
 		return nil, nil
@@ -217,14 +504,18 @@ func (r *RPCPoller) parseTransaction(ctx context.Context, signature string, bloc
 	// Determine token in/out based on balance direction
 	var tokenIn, tokenOut string
 	var amountIn, amountOut float64
+	var in, out rpc.BalanceChange
 
+	// Tokens are left as mints; the indexer's tokens stage labels them
 	for _, ch := range changes {
 		if ch.Amount < 0 {
 			amountIn = -ch.Amount
-			tokenIn = r.getTokenSymbol(ch.Mint)
+			tokenIn = ch.Mint
+			in = ch
 		} else if ch.Amount > 0 {
 			amountOut = ch.Amount
-			tokenOut = r.getTokenSymbol(ch.Mint)
+			tokenOut = ch.Mint
+			out = ch
 		}
 	}
 
@@ -245,7 +536,7 @@ func (r *RPCPoller) parseTransaction(ctx context.Context, signature string, bloc
 
 	swap := &models.SwapEvent{
 		Signature: signature,
-		Timestamp: time.Unix(blockTime, 0),
+		Timestamp: time.Unix(sig.BlockTime, 0),
 		Pair:      pair,
 		TokenIn:   tokenIn,
 		TokenOut:  tokenOut,
@@ -255,27 +546,103 @@ func (r *RPCPoller) parseTransaction(ctx context.Context, signature string, bloc
 		Fee:       constants.OrcaWhirlpoolFee,
 		Pool:      constants.PoolOrcaWhirl,
 		Dex:       "Orca",
+
+		Slot:         uint64(sig.Slot),
+		BlockTime:    sig.BlockTime,
+		AmountInRaw:  uint64(-in.Raw),
+		AmountOutRaw: uint64(out.Raw),
+		DecimalsIn:   in.Decimals,
+		DecimalsOut:  out.Decimals,
+		ProgramID:    program,
+		PoolAddress:  poolAddress(changes, tx.Transaction),
+		Wallet:       feePayer(tx.Transaction),
+
+		// read at finalized commitment, the swap can no longer be rolled back
+		Finalized: r.commitment == "finalized",
 	}
+	swap.FeeLamports, swap.PriorityFee, swap.ComputeUnitPrice = landingCost(tx)
 
 	r.logger.WithFields(logrus.Fields{
-		"pair":       pair,
-		"amount_in":  fmt.Sprintf("%.4f %s", amountIn, tokenIn),
-		"amount_out": fmt.Sprintf("%.4f %s", amountOut, tokenOut),
+		"amount_in":  fmt.Sprintf("%.4f %s", amountIn, tokens.ShortLabel(tokenIn)),
+		"amount_out": fmt.Sprintf("%.4f %s", amountOut, tokens.ShortLabel(tokenOut)),
 		"price":      fmt.Sprintf("%.4f", price),
 	}).Info("parsed swap")
 
 	return swap, nil
 }
 
-// getTokenSymbol maps a token mint address to its symbol
-func (r *RPCPoller) getTokenSymbol(mint string) string {
-	if symbol, ok := constants.TokenSymbols[mint]; ok {
-		return symbol
+// rawAmount parses a token amount in base units; unparseable amounts count as 0
+func rawAmount(a rpc.TokenAmount) int64 {
+	n, err := strconv.ParseInt(a.Amount, 10, 64)
+	if err != nil {
+		return 0
 	}
+	return n
+}
 
-	// Return shortened mint if unknown
-	if len(mint) > 8 {
-		return mint[:4] + "..." + mint[len(mint)-4:]
+// poolAddress guesses the pool a swap traded against: the first token
+// account owner, other than the fee payer, that both received and sent
+// tokens. It returns "" when no owner qualifies.
+func poolAddress(changes []rpc.BalanceChange, tx *rpc.Transaction) string {
+	payer := feePayer(tx)
+
+	received := map[string]bool{}
+	sent := map[string]bool{}
+	for _, ch := range changes {
+		if ch.Owner == "" || ch.Owner == payer {
+			continue
+		}
+		if ch.Amount > 0 {
+			received[ch.Owner] = true
+		} else {
+			sent[ch.Owner] = true
+		}
+	}
+	for _, ch := range changes {
+		if received[ch.Owner] && sent[ch.Owner] {
+			return ch.Owner
+		}
+	}
+	return ""
+}
+
+// feePayer returns the first account key of the transaction, the wallet that
+// signed and paid for it, or "" when the message is missing
+func feePayer(tx *rpc.Transaction) string {
+	if tx == nil || len(tx.Message.AccountKeys) == 0 {
+		return ""
+	}
+	return tx.Message.AccountKeys[0].Pubkey
+}
+
+// landingCost returns the lamports a transaction paid, the part above the
+// base fee per signature, and the compute unit price it set, if any
+func landingCost(tx *rpc.TransactionResult) (fee, priority, cuPrice uint64) {
+	if tx.Meta != nil {
+		fee = tx.Meta.Fee
+	}
+	signatures := 1
+	if tx.Transaction != nil {
+		signatures = max(len(tx.Transaction.Signatures), 1)
+		cuPrice = computeUnitPrice(tx.Transaction.Message.Instructions)
+	}
+	if base := uint64(signatures) * constants.LamportsPerSignature; fee > base {
+		priority = fee - base
+	}
+	return fee, priority, cuPrice
+}
+
+// computeUnitPrice returns the micro-lamports per compute unit set by a
+// SetComputeUnitPrice instruction (tag 3, then a little-endian u64), or 0
+func computeUnitPrice(ixs []rpc.Instruction) uint64 {
+	for _, ix := range ixs {
+		if ix.ProgramID != constants.ComputeBudgetProgram {
+			continue
+		}
+		data, err := base58.Decode(ix.Data)
+		if err == nil && len(data) == 9 && data[0] == 3 {
+			return binary.LittleEndian.Uint64(data[1:])
+		}
 	}
-	return mint
+	return 0
 }
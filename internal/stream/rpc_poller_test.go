@@ -0,0 +1,67 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolAddress(t *testing.T) {
+	tx := &rpc.Transaction{Message: rpc.TransactionMessage{AccountKeys: []rpc.AccountKey{{Pubkey: "trader"}}}}
+	changes := []rpc.BalanceChange{
+		{Mint: "SOL", Owner: "trader", Amount: -1},
+		{Mint: "USDC", Owner: "trader", Amount: 140},
+		{Mint: "SOL", Owner: "pool", Amount: 1},
+		{Mint: "USDC", Owner: "pool", Amount: -140},
+		{Mint: "USDC", Owner: "fees", Amount: 0.1},
+	}
+	assert.Equal(t, "pool", poolAddress(changes, tx))
+	assert.Empty(t, poolAddress(changes[:2], tx), "only the fee payer moved tokens")
+	assert.Equal(t, "trader", feePayer(tx))
+	assert.Empty(t, feePayer(nil))
+}
+
+func TestRawAmount(t *testing.T) {
+	assert.Equal(t, int64(1500000000), rawAmount(rpc.TokenAmount{Amount: "1500000000", Decimals: 9}))
+	assert.Zero(t, rawAmount(rpc.TokenAmount{}))
+}
+
+func TestLandingCost(t *testing.T) {
+	setPrice := base58.Encode([]byte{3, 0x20, 0xa1, 0x07, 0, 0, 0, 0, 0}) // 500000 micro-lamports
+	setLimit := base58.Encode([]byte{2, 0x40, 0x0d, 0x03, 0})
+	tx := &rpc.TransactionResult{
+		Meta: &rpc.TransactionMeta{Fee: 105000},
+		Transaction: &rpc.Transaction{
+			Signatures: []string{"sig"},
+			Message: rpc.TransactionMessage{Instructions: []rpc.Instruction{
+				{ProgramID: constants.ComputeBudgetProgram, Data: setLimit},
+				{ProgramID: constants.ComputeBudgetProgram, Data: setPrice},
+				{ProgramID: testProgram, Data: setPrice},
+			}},
+		},
+	}
+
+	fee, priority, cuPrice := landingCost(tx)
+	assert.Equal(t, uint64(105000), fee)
+	assert.Equal(t, uint64(100000), priority)
+	assert.Equal(t, uint64(500000), cuPrice)
+
+	tx.Meta.Fee = 5000
+	tx.Transaction.Message.Instructions = nil
+	fee, priority, cuPrice = landingCost(tx)
+	assert.Equal(t, uint64(5000), fee)
+	assert.Zero(t, priority, "base fee only")
+	assert.Zero(t, cuPrice)
+}
+
+func TestSetTxFetchDelay(t *testing.T) {
+	r := NewRPCPoller(RPCPollerConfig{TxFetchDelay: constants.DelayBetweenTxFetch})
+	r.SetTxFetchDelay(-1)
+	assert.Equal(t, constants.DelayBetweenTxFetch, r.txFetchDelay, "negative delays are ignored")
+	r.SetTxFetchDelay(0)
+	assert.Zero(t, r.txFetchDelay, "TX_FETCH_DELAY=0 removes the delay")
+}
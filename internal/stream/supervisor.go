@@ -0,0 +1,212 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// Heartbeater is implemented by providers that can prove they are alive
+// between swaps (*RPCPoller after every successful poll), so a quiet market
+// is not mistaken for a dead feed
+type Heartbeater interface {
+	OnHeartbeat(fn func())
+}
+
+// Restart reasons recorded in stream_restarts_total
+const (
+	restartStalled = "stalled" // no event within StallAfter
+	restartExited  = "exited"  // Start returned while the supervisor was still running
+)
+
+// SupervisorConfig configures a Supervisor
+type SupervisorConfig struct {
+	Name        string        // provider label in metrics and health reports (e.g. "rpc")
+	StallAfter  time.Duration // restart the provider after this long without an event
+	CheckEvery  time.Duration // how often staleness is checked (default StallAfter/4)
+	StopTimeout time.Duration // how long a restarted provider may take to return (default 10s)
+	Logger      *logrus.Logger
+}
+
+// Supervisor runs a StreamProvider, tracks the time since its last swap or
+// heartbeat, and restarts it when that exceeds StallAfter or when it exits
+// on its own. It is itself a StreamProvider.
+type Supervisor struct {
+	provider storage.StreamProvider
+	cfg      SupervisorConfig
+	logger   *logrus.Logger
+
+	mu          sync.Mutex
+	lastEvent   time.Time
+	stalled     bool
+	restarts    int64
+	lastRestart time.Time
+	lastReason  string
+}
+
+// NewSupervisor wraps provider
+func NewSupervisor(provider storage.StreamProvider, cfg SupervisorConfig) *Supervisor {
+	if cfg.Name == "" {
+		cfg.Name = "default"
+	}
+	if cfg.CheckEvery <= 0 {
+		cfg.CheckEvery = max(cfg.StallAfter/4, time.Second)
+	}
+	if cfg.StopTimeout <= 0 {
+		cfg.StopTimeout = 10 * time.Second
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	return &Supervisor{provider: provider, cfg: cfg, logger: cfg.Logger}
+}
+
+// Start runs the provider until ctx is cancelled, restarting it with backoff
+func (s *Supervisor) Start(ctx context.Context, handler storage.SwapHandler) error {
+	if hb, ok := s.provider.(Heartbeater); ok {
+		hb.OnHeartbeat(s.touch)
+	}
+	wrapped := func(swap *models.SwapEvent) error {
+		s.touch()
+		return handler(swap)
+	}
+
+	backoff := time.Second
+	for {
+		s.touch() // every run gets a full StallAfter before it counts as stalled
+		started := time.Now()
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() { done <- s.provider.Start(runCtx, wrapped) }()
+
+		reason, err := s.watch(ctx, done)
+		cancel()
+		if reason == "" {
+			return err
+		}
+		if reason == restartStalled {
+			s.awaitStop(done)
+		}
+
+		s.recordRestart(reason)
+		entry := s.logger.WithFields(logrus.Fields{"provider": s.cfg.Name, "reason": reason})
+		if err != nil {
+			entry = entry.WithError(err)
+		}
+		entry.Warn("restarting stream provider")
+
+		// a provider that keeps failing right away is retried ever more slowly
+		if time.Since(started) > 4*backoff {
+			backoff = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, time.Minute)
+	}
+}
+
+// watch waits for the provider to exit, ctx to end or the feed to stall. It
+// returns the restart reason, or "" when the supervisor itself should stop.
+func (s *Supervisor) watch(ctx context.Context, done <-chan error) (string, error) {
+	ticker := time.NewTicker(s.cfg.CheckEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", <-done
+		case err := <-done:
+			if ctx.Err() != nil {
+				return "", err
+			}
+			return restartExited, err
+		case <-ticker.C:
+			if s.cfg.StallAfter > 0 && s.checkStalled() {
+				return restartStalled, nil
+			}
+		}
+	}
+}
+
+// awaitStop waits for a cancelled provider to return and releases it, so
+// the next Start does not find it still running
+func (s *Supervisor) awaitStop(done <-chan error) {
+	select {
+	case <-done:
+	case <-time.After(s.cfg.StopTimeout):
+		s.logger.WithField("provider", s.cfg.Name).Warn("stalled stream provider did not stop in time")
+	}
+	if err := s.provider.Stop(); err != nil {
+		s.logger.WithError(err).WithField("provider", s.cfg.Name).Warn("failed to stop stream provider")
+	}
+}
+
+// Stop stops the provider
+func (s *Supervisor) Stop() error {
+	return s.provider.Stop()
+}
+
+// touch records an event or heartbeat
+func (s *Supervisor) touch() {
+	now := time.Now()
+	s.mu.Lock()
+	s.lastEvent = now
+	s.stalled = false
+	s.mu.Unlock()
+
+	streamLastEvent.With(s.cfg.Name).Set(float64(now.Unix()))
+	streamStalled.With(s.cfg.Name).Set(0)
+}
+
+// checkStalled marks the provider stalled once StallAfter has passed since
+// its last event
+func (s *Supervisor) checkStalled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.lastEvent) <= s.cfg.StallAfter {
+		return false
+	}
+	s.stalled = true
+	streamStalled.With(s.cfg.Name).Set(1)
+	return true
+}
+
+func (s *Supervisor) recordRestart(reason string) {
+	s.mu.Lock()
+	s.restarts++
+	s.lastRestart = time.Now().UTC()
+	s.lastReason = reason
+	s.mu.Unlock()
+
+	streamRestarts.With(s.cfg.Name, reason).Inc()
+}
+
+// Health returns the provider's current state
+func (s *Supervisor) Health() models.StreamHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := models.StreamHealth{
+		Provider:          s.cfg.Name,
+		Stalled:           s.stalled,
+		StallAfterSeconds: s.cfg.StallAfter.Seconds(),
+		Restarts:          s.restarts,
+		LastRestartReason: s.lastReason,
+	}
+	if !s.lastEvent.IsZero() {
+		h.LastEventAt = s.lastEvent.UTC()
+		h.SecondsSinceEvent = time.Since(s.lastEvent).Seconds()
+	}
+	if !s.lastRestart.IsZero() {
+		t := s.lastRestart
+		h.LastRestartAt = &t
+	}
+	return h
+}
@@ -0,0 +1,95 @@
+package stream
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stallingProvider goes silent on its first run and delivers a swap on the next
+type stallingProvider struct {
+	starts atomic.Int32
+	stops  atomic.Int32
+}
+
+func (p *stallingProvider) Start(ctx context.Context, handler storage.SwapHandler) error {
+	if p.starts.Add(1) > 1 {
+		if err := handler(&models.SwapEvent{Signature: "sig"}); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (p *stallingProvider) Stop() error {
+	p.stops.Add(1)
+	return nil
+}
+
+func TestSupervisorRestartsStalledProvider(t *testing.T) {
+	p := &stallingProvider{}
+	sup := NewSupervisor(p, SupervisorConfig{Name: "test-stall", StallAfter: 50 * time.Millisecond, CheckEvery: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	got := make(chan string, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- sup.Start(ctx, func(swap *models.SwapEvent) error {
+			got <- swap.Signature
+			return nil
+		})
+	}()
+
+	select {
+	case sig := <-got:
+		assert.Equal(t, "sig", sig)
+	case <-time.After(5 * time.Second):
+		t.Fatal("provider was not restarted")
+	}
+	h := sup.Health()
+	assert.Equal(t, "test-stall", h.Provider)
+	assert.False(t, h.Stalled, "the swap proves the new run is alive")
+	assert.Equal(t, int64(1), h.Restarts)
+	assert.Equal(t, restartStalled, h.LastRestartReason)
+	assert.Equal(t, int32(1), p.stops.Load())
+	assert.Equal(t, 1.0, streamRestarts.With("test-stall", restartStalled).Value())
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, int32(2), p.starts.Load())
+}
+
+func TestSupervisorHeartbeatKeepsQuietProviderAlive(t *testing.T) {
+	r := NewRPCPoller(RPCPollerConfig{})
+	sup := NewSupervisor(&heartbeatProvider{RPCPoller: r}, SupervisorConfig{Name: "test-quiet", StallAfter: 50 * time.Millisecond, CheckEvery: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, sup.Start(ctx, func(*models.SwapEvent) error { return nil }), context.DeadlineExceeded)
+	assert.Zero(t, sup.Health().Restarts)
+}
+
+// heartbeatProvider beats through the poller's heartbeat without polling
+type heartbeatProvider struct {
+	*RPCPoller
+}
+
+func (p *heartbeatProvider) Start(ctx context.Context, _ storage.SwapHandler) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			p.beat()
+		}
+	}
+}
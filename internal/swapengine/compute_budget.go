@@ -0,0 +1,73 @@
+package swapengine
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/gagliardetto/solana-go"
+)
+
+var computeBudgetProgramID = solana.MustPublicKeyFromBase58(constants.ComputeBudgetProgram)
+
+// MaxComputeUnitLimit is the most compute a transaction may request; swaps
+// are simulated under it so the simulation measures what they really need
+const MaxComputeUnitLimit = 1_400_000
+
+// ComputeBudgetConfig sizes each swap's compute budget from its simulation
+type ComputeBudgetConfig struct {
+	Enabled bool // simulate every swap and set its compute unit limit (and price)
+
+	// Margin added to the simulated units, e.g. 0.2 requests 20% more
+	Margin float64
+
+	// PriorityFeeLamports is the priority fee to pay per swap; the compute
+	// unit price is derived from it and the limit (0 = no priority fee)
+	PriorityFeeLamports uint64
+}
+
+// DefaultComputeBudgetConfig budgets simulated units plus 20%, without a
+// priority fee
+func DefaultComputeBudgetConfig() ComputeBudgetConfig {
+	return ComputeBudgetConfig{Enabled: true, Margin: 0.2}
+}
+
+// Plan returns the compute unit limit and price (micro-lamports per unit)
+// for a swap whose simulation consumed simulatedUnits
+func (c ComputeBudgetConfig) Plan(simulatedUnits uint64) (limit uint32, microLamports uint64) {
+	units := math.Ceil(float64(simulatedUnits) * (1 + max(c.Margin, 0)))
+	limit = uint32(min(max(units, 1), MaxComputeUnitLimit))
+	if c.PriorityFeeLamports > 0 {
+		microLamports = (c.PriorityFeeLamports*1_000_000 + uint64(limit) - 1) / uint64(limit)
+	}
+	return limit, microLamports
+}
+
+// NewSetComputeUnitLimitIx builds a ComputeBudget SetComputeUnitLimit
+// instruction (tag 2, then a little-endian u32)
+func NewSetComputeUnitLimitIx(units uint32) solana.Instruction {
+	data := make([]byte, 1+4)
+	data[0] = 2
+	binary.LittleEndian.PutUint32(data[1:], units)
+	return solana.NewInstruction(computeBudgetProgramID, solana.AccountMetaSlice{}, data)
+}
+
+// NewSetComputeUnitPriceIx builds a ComputeBudget SetComputeUnitPrice
+// instruction (tag 3, then a little-endian u64 of micro-lamports per unit)
+func NewSetComputeUnitPriceIx(microLamports uint64) solana.Instruction {
+	data := make([]byte, 1+8)
+	data[0] = 3
+	binary.LittleEndian.PutUint64(data[1:], microLamports)
+	return solana.NewInstruction(computeBudgetProgramID, solana.AccountMetaSlice{}, data)
+}
+
+// withComputeBudget prepends compute budget instructions to ixs; a zero price
+// sets no priority fee
+func withComputeBudget(ixs []solana.Instruction, limit uint32, microLamports uint64) []solana.Instruction {
+	out := make([]solana.Instruction, 0, len(ixs)+2)
+	out = append(out, NewSetComputeUnitLimitIx(limit))
+	if microLamports > 0 {
+		out = append(out, NewSetComputeUnitPriceIx(microLamports))
+	}
+	return append(out, ixs...)
+}
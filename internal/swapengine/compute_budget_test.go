@@ -0,0 +1,35 @@
+package swapengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeBudgetPlan(t *testing.T) {
+	cfg := ComputeBudgetConfig{Enabled: true, Margin: 0.2, PriorityFeeLamports: 10_000}
+
+	limit, price := cfg.Plan(100_000)
+	assert.Equal(t, uint32(120_000), limit)
+	assert.Equal(t, uint64(83_334), price, "10000 lamports over 120k units, rounded up")
+
+	limit, _ = cfg.Plan(1_300_000)
+	assert.Equal(t, uint32(MaxComputeUnitLimit), limit, "capped at the maximum")
+
+	limit, price = DefaultComputeBudgetConfig().Plan(0)
+	assert.Equal(t, uint32(1), limit)
+	assert.Zero(t, price, "no priority fee by default")
+
+	ixs := withComputeBudget(nil, 120_000, 83_334)
+	require.Len(t, ixs, 2)
+	data, err := ixs[0].Data()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2, 0xc0, 0xd4, 0x01, 0x00}, data)
+	data, err = ixs[1].Data()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{3, 0x86, 0x45, 0x01, 0, 0, 0, 0, 0}, data)
+	assert.Equal(t, computeBudgetProgramID, ixs[1].ProgramID())
+
+	assert.Len(t, withComputeBudget(nil, 120_000, 0), 1, "no price instruction without a fee")
+}
@@ -1,21 +1,40 @@
 package swapengine
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/tokens"
 	"github.com/gagliardetto/solana-go"
 )
 
 type DecisionEngine struct {
-	risk RiskConfig
+	mu       sync.RWMutex
+	risk     RiskConfig
+	decimals *tokens.Resolver // optional; TokenDecimals without it
 }
 
 func NewDecisionEngine(risk RiskConfig) *DecisionEngine {
 	return &DecisionEngine{risk: risk}
 }
 
+// WithDecimals resolves token decimals through r, so intents may name any
+// SPL mint
+func (de *DecisionEngine) WithDecimals(r *tokens.Resolver) *DecisionEngine {
+	de.decimals = r
+	return de
+}
+
+// SetRiskConfig replaces the defaults used to enrich intents
+func (de *DecisionEngine) SetRiskConfig(risk RiskConfig) {
+	de.mu.Lock()
+	defer de.mu.Unlock()
+	de.risk = risk
+}
+
 func (de *DecisionEngine) ValidateIntent(intent *SwapIntent) error {
 	if intent == nil {
 		return fmt.Errorf("intent is nil")
@@ -29,16 +48,19 @@ func (de *DecisionEngine) ValidateIntent(intent *SwapIntent) error {
 	if intent.Amount <= 0 {
 		return fmt.Errorf("amount must be > 0")
 	}
-	if _, ok := TokenMints[intent.InputToken]; !ok {
+	if _, ok := MintFor(intent.InputToken); !ok {
 		return fmt.Errorf("unknown input token: %s", intent.InputToken)
 	}
-	if _, ok := TokenMints[intent.OutputToken]; !ok {
+	if _, ok := MintFor(intent.OutputToken); !ok {
 		return fmt.Errorf("unknown output token: %s", intent.OutputToken)
 	}
 	return nil
 }
 
 func (de *DecisionEngine) EnrichIntent(intent *SwapIntent) {
+	de.mu.RLock()
+	defer de.mu.RUnlock()
+
 	if intent.RequestedAt.IsZero() {
 		intent.RequestedAt = time.Now()
 	}
@@ -52,16 +74,21 @@ func (de *DecisionEngine) EnrichIntent(intent *SwapIntent) {
 	}
 }
 
-func (de *DecisionEngine) ParseIntent(intent *SwapIntent) (*SwapParams, error) {
+func (de *DecisionEngine) ParseIntent(ctx context.Context, intent *SwapIntent) (*SwapParams, error) {
 	if err := de.ValidateIntent(intent); err != nil {
 		return nil, err
 	}
 	de.EnrichIntent(intent)
 
-	inMint := solana.MustPublicKeyFromBase58(TokenMints[intent.InputToken])
-	outMint := solana.MustPublicKeyFromBase58(TokenMints[intent.OutputToken])
+	inAddr, _ := MintFor(intent.InputToken)
+	outAddr, _ := MintFor(intent.OutputToken)
+	inMint := solana.MustPublicKeyFromBase58(inAddr)
+	outMint := solana.MustPublicKeyFromBase58(outAddr)
 
-	inDecimals := TokenDecimals[intent.InputToken]
+	inDecimals, err := de.tokenDecimals(ctx, intent.InputToken, inAddr)
+	if err != nil {
+		return nil, err
+	}
 	amountIn := toRawAmount(intent.Amount, inDecimals)
 
 	params := &SwapParams{
@@ -79,6 +106,17 @@ func (de *DecisionEngine) ParseIntent(intent *SwapIntent) (*SwapParams, error) {
 	return params, nil
 }
 
+// tokenDecimals returns the decimals of token (with mint address mint)
+func (de *DecisionEngine) tokenDecimals(ctx context.Context, token, mint string) (uint8, error) {
+	if de.decimals != nil {
+		return de.decimals.Decimals(ctx, mint)
+	}
+	if d, ok := TokenDecimals[token]; ok {
+		return d, nil
+	}
+	return 0, fmt.Errorf("unknown decimals for token %s", token)
+}
+
 func toRawAmount(amount float64, decimals uint8) uint64 {
 	if amount <= 0 {
 		return 0
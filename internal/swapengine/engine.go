@@ -2,15 +2,25 @@ package swapengine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/orca"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/tokens"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/wallet"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/sirupsen/logrus"
 )
 
 // Engine is the main orchestrator for swap operations
@@ -23,8 +33,31 @@ type Engine struct {
 	decisionEngine *DecisionEngine
 	executor       *Executor
 	riskManager    *RiskManager
+	logger         *logrus.Logger
+
+	flags     *flags.Watcher // nil without Redis
+	flagStore *flags.Store   // nil without Redis
+	stopFlags context.CancelFunc
+
+	riskMu        sync.Mutex // serialises risk config updates
+	baseRisk      RiskConfig // configured limits, before overrides
+	riskOverrides RiskOverrides
+	riskUpdatedAt time.Time
+	riskFlagErr   string
+
+	poolMu         sync.Mutex // serialises pool reloads
+	poolConfigPath string
+	poolSource     string
+	poolStrict     bool
+	poolSummary    *orca.PoolLoadSummary // result of the last (re)load
+	discoverer     *orca.Discoverer      // nil unless pool discovery runs
+	stopDiscovery  context.CancelFunc    // nil unless pool discovery runs
+	stopBlockhash  context.CancelFunc    // nil unless blockhashes are prefetched
 }
 
+// ErrKillSwitch is returned while the engine.kill_switch flag is on
+var ErrKillSwitch = errors.New("swap execution disabled by kill switch")
+
 // EngineConfig holds configuration for the swap engine
 type EngineConfig struct {
 	// RPC settings
@@ -35,36 +68,67 @@ type EngineConfig struct {
 
 	// Wallet
 	WalletPrivateKey string
+	BlockhashRefresh time.Duration // prefetch a blockhash this often (0 = one per transaction)
 
 	// Pool configuration
 	PoolConfigPath string
+	PoolSource     string // orca.PoolSourceFile or orca.PoolSourceChain
+	PoolStrict     bool   // fail on any invalid pool entry instead of skipping it
+
+	// Pool discovery scans the swap programs in the background and registers
+	// pools for whitelisted pairs missing from the config file
+	DiscoveryEnabled bool
+	Discovery        orca.DiscoveryConfig
 
 	// Storage
-	RedisAddr      string
+	Redis          cache.RedisConfig // Redis disabled when Addr is empty
 	ClickHouseAddr string
 	ClickHouseDB   string
 
 	// Risk management
 	RiskConfig RiskConfig
+
+	// Compute unit limit and priority fee, sized from each swap's simulation
+	ComputeBudget ComputeBudgetConfig
+
+	Logger *logrus.Logger // also used by Redis, ClickHouse and discovery unless they set their own
 }
 
 // DefaultEngineConfig returns sensible defaults
 func DefaultEngineConfig() EngineConfig {
 	return EngineConfig{
-		RPCURL:         "https://api.mainnet-beta.solana.com",
-		RPCTimeout:     30 * time.Second,
-		MaxRetries:     3,
-		RetryBackoff:   1 * time.Second,
-		PoolConfigPath: "internal/config/pools.json",
-		RedisAddr:      "",
+		RPCURL:           "https://api.mainnet-beta.solana.com",
+		RPCTimeout:       30 * time.Second,
+		MaxRetries:       3,
+		RetryBackoff:     1 * time.Second,
+		BlockhashRefresh: 5 * time.Second,
+		PoolConfigPath:   "internal/config/pools.json",
+		PoolSource:       orca.PoolSourceFile,
+		Discovery: orca.DiscoveryConfig{
+			ProgramIDs: []solana.PublicKey{solana.MustPublicKeyFromBase58(orca.LegacyProgramID)},
+			Mints:      defaultDiscoveryMints(),
+			MinReserve: 1_000_000,
+			Interval:   15 * time.Minute,
+		},
 		ClickHouseAddr: "",
 		ClickHouseDB:   "",
 		RiskConfig:     DefaultRiskConfig(),
+		ComputeBudget:  DefaultComputeBudgetConfig(),
 	}
 }
 
 // NewEngine creates a new swap engine with all dependencies
 func NewEngine(cfg EngineConfig) (*Engine, error) {
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	if cfg.Redis.Logger == nil {
+		cfg.Redis.Logger = cfg.Logger
+	}
+	if cfg.Discovery.Logger == nil {
+		cfg.Discovery.Logger = cfg.Logger
+	}
+
 	// 1. Initialize wallet
 	walletCfg := wallet.WalletConfig{
 		RPCURL:              cfg.RPCURL,
@@ -75,12 +139,19 @@ func NewEngine(cfg EngineConfig) (*Engine, error) {
 		DefaultCommitment:   "confirmed",
 		SkipPreflight:       false,
 		PreflightCommitment: "processed",
+		BlockhashRefresh:    cfg.BlockhashRefresh,
 	}
 
 	w, err := wallet.NewWallet(walletCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create wallet: %w", err)
 	}
+	var stopBlockhash context.CancelFunc
+	if cfg.BlockhashRefresh > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		go w.RefreshBlockhash(ctx)
+		stopBlockhash = cancel
+	}
 
 	// 2. Initialize Orca client
 	rpcCfg := rpc.ClientConfig{
@@ -96,27 +167,46 @@ func NewEngine(cfg EngineConfig) (*Engine, error) {
 	}
 
 	// 3. Load pool registry
-	poolRegistry, err := orca.NewPoolRegistry(cfg.PoolConfigPath)
+	poolRegistry, poolSummary, err := loadPoolRegistry(cfg.PoolConfigPath, cfg.PoolSource, cfg.PoolStrict, orcaClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load pool registry: %w", err)
 	}
 
 	// 4. Initialize Redis cache
 	var redisCache *cache.RedisCache
-	if cfg.RedisAddr != "" {
-		rc, err := cache.NewRedisCache(context.Background(), cache.RedisConfig{Addr: cfg.RedisAddr})
+	if cfg.Redis.Addr != "" {
+		rc, err := cache.NewRedisCache(context.Background(), cfg.Redis)
 		if err != nil {
 			return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 		}
 		redisCache = rc
 	}
 
+	// 4b. Watch feature flags (kill switch, risk overrides) when Redis is available
+	var (
+		watcher   *flags.Watcher
+		flagStore *flags.Store
+	)
+	stopFlags := func() {}
+	if redisCache != nil {
+		if store, err := flags.NewStore(redisCache.Client()); err == nil {
+			flagStore = store
+			ctx, cancel := context.WithCancel(context.Background())
+			if w, err := store.Watch(ctx, 0); err == nil {
+				watcher, stopFlags = w, cancel
+			} else {
+				cancel()
+			}
+		}
+	}
+
 	// 5. Initialize ClickHouse
 	var clickhouseStore *cache.ClickHouseStore
 	if cfg.ClickHouseAddr != "" && cfg.ClickHouseDB != "" {
 		ch, err := cache.NewClickHouseStore(context.Background(), cache.ClickHouseConfig{
 			Addr:     cfg.ClickHouseAddr,
 			Database: cfg.ClickHouseDB,
+			Logger:   cfg.Logger,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to connect to ClickHouse: %w", err)
@@ -124,11 +214,24 @@ func NewEngine(cfg EngineConfig) (*Engine, error) {
 		clickhouseStore = ch
 	}
 
-	// 6. Create decision engine
-	decisionEngine := NewDecisionEngine(cfg.RiskConfig)
+	// 6. Create decision engine; decimals of any mint are read from chain once
+	// and shared with other processes through Redis
+	var decimalsCache tokens.DecimalsCache
+	if redisCache != nil {
+		decimalsCache = redisCache
+	}
+	decimals := tokens.NewResolver(rpc.NewClient(rpcCfg), decimalsCache, nil)
+	decisionEngine := NewDecisionEngine(cfg.RiskConfig).WithDecimals(decimals)
 
-	// 7. Create risk manager
+	// 7. Create risk manager; swaps without a SOL leg are valued from the
+	// prices the indexer keeps in Redis
 	riskManager := NewRiskManager(cfg.RiskConfig)
+	if redisCache != nil {
+		riskManager.WithPrices(redisCache, decimals, 0)
+	}
+	if watcher != nil {
+		riskManager.WithCooldownBypass(func() bool { return watcher.Bool(flags.KeyEngineCooldownBypass, false) })
+	}
 
 	// 8. Create executor
 	executor := NewExecutor(
@@ -138,9 +241,22 @@ func NewEngine(cfg EngineConfig) (*Engine, error) {
 		redisCache,
 		clickhouseStore,
 		riskManager,
-	).WithTokenAccountResolver(NewDefaultTokenAccountResolver(w))
+	).WithTokenAccountResolver(NewDefaultTokenAccountResolver(w)).
+		WithComputeBudget(cfg.ComputeBudget)
+
+	// 9. Start pool discovery
+	var (
+		discoverer    *orca.Discoverer
+		stopDiscovery context.CancelFunc
+	)
+	if cfg.DiscoveryEnabled {
+		ctx, cancel := context.WithCancel(context.Background())
+		discoverer = orca.NewDiscoverer(orcaClient, poolRegistry, cfg.Discovery)
+		go discoverer.Run(ctx)
+		stopDiscovery = cancel
+	}
 
-	return &Engine{
+	e := &Engine{
 		wallet:         w,
 		orcaClient:     orcaClient,
 		poolRegistry:   poolRegistry,
@@ -149,23 +265,98 @@ func NewEngine(cfg EngineConfig) (*Engine, error) {
 		decisionEngine: decisionEngine,
 		executor:       executor,
 		riskManager:    riskManager,
-	}, nil
+		logger:         cfg.Logger,
+		flags:          watcher,
+		flagStore:      flagStore,
+		stopFlags:      stopFlags,
+		baseRisk:       cfg.RiskConfig,
+		poolConfigPath: cfg.PoolConfigPath,
+		poolSource:     cfg.PoolSource,
+		poolStrict:     cfg.PoolStrict,
+		poolSummary:    poolSummary,
+		discoverer:     discoverer,
+		stopDiscovery:  stopDiscovery,
+		stopBlockhash:  stopBlockhash,
+	}
+
+	// 10. Apply runtime risk overrides (engine.risk flag) now and on every change
+	if watcher != nil {
+		e.applyRiskFlag()
+		watcher.OnChange(func(ch flags.Change) {
+			if ch.Key == flags.KeyEngineRisk {
+				e.applyRiskFlag()
+			}
+		})
+	}
+	return e, nil
+}
+
+// poolResolveTimeout bounds fetching every swap account at startup
+const poolResolveTimeout = time.Minute
+
+// loadPoolRegistry reads pools from the config file, resolving and validating
+// them against chain state when the source is chain. Invalid entries are
+// skipped (and listed in the summary) unless strict is set.
+func loadPoolRegistry(path, source string, strict bool, client *orca.Client) (*orca.PoolRegistry, *orca.PoolLoadSummary, error) {
+	switch source {
+	case "", orca.PoolSourceFile, orca.PoolSourceChain:
+	default:
+		return nil, nil, fmt.Errorf("unknown pool source %q (want %s or %s)", source, orca.PoolSourceFile, orca.PoolSourceChain)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), poolResolveTimeout)
+	defer cancel()
+	return orca.LoadPoolRegistry(ctx, path, orca.PoolLoadOptions{Source: source, Strict: strict, Fetcher: client})
 }
 
-// NewEngineFromEnv creates an engine using environment variables
-func NewEngineFromEnv() (*Engine, error) {
+// PoolLoadSummary reports which pool entries the last (re)load kept and skipped
+func (e *Engine) PoolLoadSummary() *orca.PoolLoadSummary {
+	e.poolMu.Lock()
+	defer e.poolMu.Unlock()
+	return e.poolSummary
+}
+
+// NewEngineFromEnv creates an engine using environment variables; a nil
+// logger gets a default one
+func NewEngineFromEnv(logger *logrus.Logger) (*Engine, error) {
 	cfg := DefaultEngineConfig()
+	cfg.Logger = logger
 
 	if v := os.Getenv("SOLANA_RPC_URL"); v != "" {
 		cfg.RPCURL = v
 	}
 	cfg.WalletPrivateKey = os.Getenv("WALLET_PRIVATE_KEY")
+	if v := os.Getenv("WALLET_BLOCKHASH_REFRESH"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.BlockhashRefresh = d
+		}
+	}
 
 	if v := os.Getenv("SWAPENGINE_POOL_CONFIG_PATH"); v != "" {
 		cfg.PoolConfigPath = v
 	}
+	if v := os.Getenv("SWAPENGINE_POOL_SOURCE"); v != "" {
+		cfg.PoolSource = strings.ToLower(strings.TrimSpace(v))
+	}
+	if v := os.Getenv("SWAPENGINE_POOL_STRICT"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.PoolStrict = b
+		}
+	}
 	if v := os.Getenv("REDIS_ADDR"); v != "" {
-		cfg.RedisAddr = v
+		cfg.Redis.Addr = v
+	}
+	cfg.Redis.Username = os.Getenv("REDIS_USERNAME")
+	cfg.Redis.Password = os.Getenv("REDIS_PASSWORD")
+	if v := os.Getenv("REDIS_DB"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Redis.DB = n
+		}
+	}
+	if v := os.Getenv("REDIS_TLS"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.Redis.TLS = b
+		}
 	}
 	if v := os.Getenv("CLICKHOUSE_ADDR"); v != "" {
 		cfg.ClickHouseAddr = v
@@ -174,50 +365,286 @@ func NewEngineFromEnv() (*Engine, error) {
 		cfg.ClickHouseDB = v
 	}
 
-	if v := os.Getenv("SWAPENGINE_REQUIRE_SIMULATION"); v != "" {
+	if err := applyComputeBudgetEnv(&cfg.ComputeBudget); err != nil {
+		return nil, err
+	}
+	if err := applyRiskEnv(&cfg.RiskConfig); err != nil {
+		return nil, err
+	}
+	if err := applyDiscoveryEnv(&cfg); err != nil {
+		return nil, err
+	}
+
+	return NewEngine(cfg)
+}
+
+// defaultDiscoveryMints whitelists the tokens in TokenMints
+func defaultDiscoveryMints() []solana.PublicKey {
+	mints := make([]solana.PublicKey, 0, len(TokenMints))
+	for _, addr := range TokenMints {
+		mints = append(mints, solana.MustPublicKeyFromBase58(addr))
+	}
+	return mints
+}
+
+// applyDiscoveryEnv reads SWAPENGINE_DISCOVERY_* settings. Mints may be given
+// as symbols known to TokenMints or as addresses.
+func applyDiscoveryEnv(cfg *EngineConfig) error {
+	if v := os.Getenv("SWAPENGINE_DISCOVERY_ENABLED"); v != "" {
 		if b, err := strconv.ParseBool(v); err == nil {
-			cfg.RiskConfig.RequireSimulation = b
+			cfg.DiscoveryEnabled = b
+		}
+	}
+	if v := os.Getenv("SWAPENGINE_DISCOVERY_PROGRAMS"); v != "" {
+		programs, err := parseKeyList(v, nil)
+		if err != nil {
+			return fmt.Errorf("SWAPENGINE_DISCOVERY_PROGRAMS: %w", err)
+		}
+		cfg.Discovery.ProgramIDs = programs
+	}
+	if v := os.Getenv("SWAPENGINE_DISCOVERY_MINTS"); v != "" {
+		mints, err := parseKeyList(v, TokenMints)
+		if err != nil {
+			return fmt.Errorf("SWAPENGINE_DISCOVERY_MINTS: %w", err)
 		}
+		cfg.Discovery.Mints = mints
 	}
+	if v := os.Getenv("SWAPENGINE_DISCOVERY_MIN_RESERVE"); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+			cfg.Discovery.MinReserve = n
+		}
+	}
+	if v := os.Getenv("SWAPENGINE_DISCOVERY_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Discovery.Interval = d
+		}
+	}
+	return nil
+}
 
-	return NewEngine(cfg)
+// parseKeyList parses comma-separated public keys, resolving symbols through aliases
+func parseKeyList(v string, aliases map[string]string) ([]solana.PublicKey, error) {
+	var keys []solana.PublicKey
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if addr, ok := aliases[strings.ToUpper(item)]; ok {
+			item = addr
+		}
+		pk, err := solana.PublicKeyFromBase58(item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", item, err)
+		}
+		keys = append(keys, pk)
+	}
+	return keys, nil
+}
+
+// applyComputeBudgetEnv reads the SWAPENGINE_* compute budget settings; a
+// value that does not parse is an error
+func applyComputeBudgetEnv(cb *ComputeBudgetConfig) error {
+	if v := os.Getenv("SWAPENGINE_COMPUTE_BUDGET"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("SWAPENGINE_COMPUTE_BUDGET must be true or false (got %q)", v)
+		}
+		cb.Enabled = b
+	}
+	if v := os.Getenv("SWAPENGINE_CU_MARGIN"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			return fmt.Errorf("SWAPENGINE_CU_MARGIN must be a non-negative fraction (got %q)", v)
+		}
+		cb.Margin = f
+	}
+	if v := os.Getenv("SWAPENGINE_PRIORITY_FEE_LAMPORTS"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("SWAPENGINE_PRIORITY_FEE_LAMPORTS must be a whole number of lamports (got %q)", v)
+		}
+		cb.PriorityFeeLamports = n
+	}
+	return nil
+}
+
+// applyRiskEnv overrides risk limits from SWAPENGINE_* env vars (also
+// populated by config.yaml); a value that does not parse is an error, so the
+// engine never trades on limits other than those configured
+func applyRiskEnv(rc *RiskConfig) error {
+	if v := os.Getenv("SWAPENGINE_REQUIRE_SIMULATION"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("SWAPENGINE_REQUIRE_SIMULATION must be true or false (got %q)", v)
+		}
+		rc.RequireSimulation = b
+	}
+	for _, f := range []struct {
+		key string
+		dst *float64
+	}{
+		{"SWAPENGINE_MAX_SWAP_AMOUNT_SOL", &rc.MaxSwapAmountSOL},
+		{"SWAPENGINE_DAILY_LIMIT_SOL", &rc.DailyLimitSOL},
+		{"SWAPENGINE_MIN_BALANCE_SOL", &rc.MinBalanceSOL},
+	} {
+		if v := os.Getenv(f.key); v != "" {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil || n < 0 {
+				return fmt.Errorf("%s must be a non-negative number of SOL (got %q)", f.key, v)
+			}
+			*f.dst = n
+		}
+	}
+	for _, f := range []struct {
+		key string
+		dst *uint16
+	}{
+		{"SWAPENGINE_MAX_PRICE_IMPACT_BPS", &rc.MaxPriceImpactBps},
+		{"SWAPENGINE_DEFAULT_SLIPPAGE_BPS", &rc.DefaultSlippageBps},
+		{"SWAPENGINE_MAX_SLIPPAGE_BPS", &rc.MaxSlippageBps},
+	} {
+		if v := os.Getenv(f.key); v != "" {
+			n, err := strconv.ParseUint(v, 10, 16)
+			if err != nil {
+				return fmt.Errorf("%s must be a whole number of basis points (got %q)", f.key, v)
+			}
+			*f.dst = uint16(n)
+		}
+	}
+	if v := os.Getenv("SWAPENGINE_ALLOWED_TOKENS"); v != "" {
+		var tokens []string
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.ToUpper(strings.TrimSpace(t)); t != "" {
+				tokens = append(tokens, t)
+			}
+		}
+		rc.AllowedTokens = tokens
+	}
+	if v := os.Getenv("SWAPENGINE_MAX_ROUTE_HOPS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("SWAPENGINE_MAX_ROUTE_HOPS must be a non-negative integer (got %q)", v)
+		}
+		rc.MaxRouteHops = n
+	}
+	if v := os.Getenv("SWAPENGINE_EXCLUDED_DEXES"); v != "" {
+		var dexes []string
+		for _, d := range strings.Split(v, ",") {
+			if d = strings.TrimSpace(d); d != "" {
+				dexes = append(dexes, d)
+			}
+		}
+		rc.ExcludedDexes = dexes
+	}
+	if v := os.Getenv("SWAPENGINE_FAILURE_COOLDOWN"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return fmt.Errorf("SWAPENGINE_FAILURE_COOLDOWN must be a non-negative duration (got %q)", v)
+		}
+		rc.FailureCooldown = d
+	}
+	if v := os.Getenv("SWAPENGINE_WALLET_LIMITS"); v != "" {
+		limits, err := parseWalletLimits(v)
+		if err != nil {
+			return fmt.Errorf("SWAPENGINE_WALLET_LIMITS: %w", err)
+		}
+		rc.WalletLimits = limits
+	}
+	return nil
+}
+
+// parseWalletLimits reads SWAPENGINE_WALLET_LIMITS: comma-separated
+// ADDRESS:MAX_SWAP_SOL:DAILY_LIMIT_SOL entries, where an empty or 0 limit
+// keeps the global one
+func parseWalletLimits(v string) (map[string]WalletLimits, error) {
+	limits := make(map[string]WalletLimits)
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%q: want ADDRESS:MAX_SWAP_SOL:DAILY_LIMIT_SOL", entry)
+		}
+		addr, err := solana.PublicKeyFromBase58(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, fmt.Errorf("%q: invalid address: %w", entry, err)
+		}
+		var l WalletLimits
+		for i, dst := range []*float64{&l.MaxSwapAmountSOL, &l.DailyLimitSOL} {
+			s := strings.TrimSpace(parts[i+1])
+			if s == "" {
+				continue
+			}
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil || f < 0 {
+				return nil, fmt.Errorf("%q: limit %q must be a non-negative number of SOL", entry, s)
+			}
+			*dst = f
+		}
+		limits[addr.String()] = l
+	}
+	return limits, nil
 }
 
 // ExecuteAISwap processes an AI-generated swap intent end-to-end
 func (e *Engine) ExecuteAISwap(ctx context.Context, intent *SwapIntent) (*SwapResult, error) {
+	if e.KillSwitchOn() {
+		return nil, ErrKillSwitch
+	}
+
 	// 1. Validate intent
 	if err := e.decisionEngine.ValidateIntent(intent); err != nil {
-		return nil, fmt.Errorf("invalid intent: %w", err)
+		return nil, apperr.Errorf(apperr.ValidationFailed, "invalid intent: %w", err)
 	}
 
 	// 2. Enrich with defaults
 	e.decisionEngine.EnrichIntent(intent)
 
 	// 3. Parse into executable parameters
-	params, err := e.decisionEngine.ParseIntent(intent)
+	params, err := e.decisionEngine.ParseIntent(ctx, intent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse intent: %w", err)
 	}
 
 	// 4. Execute the swap
 	result, err := e.executor.ExecuteSwap(ctx, params)
+	entry := e.logger.WithFields(logrus.Fields{
+		"pair":      intent.InputToken + "/" + intent.OutputToken,
+		"amount_in": intent.Amount,
+	})
+	if result != nil {
+		entry = entry.WithFields(logrus.Fields{"execution_id": result.ExecutionID, "signature": result.Signature})
+	}
 	if err != nil {
+		if result != nil && result.ErrorKind != "" {
+			entry = entry.WithField("error_kind", result.ErrorKind)
+		}
+		entry.WithError(err).Warn("swap execution failed")
 		return result, fmt.Errorf("execution failed: %w", err)
 	}
+	entry.WithField("duration", result.Duration).Info("swap executed")
 
 	return result, nil
 }
 
+// KillSwitchOn reports whether the engine.kill_switch flag is set
+func (e *Engine) KillSwitchOn() bool {
+	return e.flags != nil && e.flags.Bool(flags.KeyEngineKillSwitch, false)
+}
+
 // GetQuote returns a quote for a swap intent without executing
 func (e *Engine) GetQuote(ctx context.Context, intent *SwapIntent) (*QuoteResult, error) {
 	// Validate and parse
 	if err := e.decisionEngine.ValidateIntent(intent); err != nil {
-		return nil, fmt.Errorf("invalid intent: %w", err)
+		return nil, apperr.Errorf(apperr.ValidationFailed, "invalid intent: %w", err)
 	}
 
 	e.decisionEngine.EnrichIntent(intent)
 
-	params, err := e.decisionEngine.ParseIntent(intent)
+	params, err := e.decisionEngine.ParseIntent(ctx, intent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse intent: %w", err)
 	}
@@ -229,7 +656,7 @@ func (e *Engine) GetQuote(ctx context.Context, intent *SwapIntent) (*QuoteResult
 // CheckRisk validates a swap intent against risk rules without executing
 func (e *Engine) CheckRisk(ctx context.Context, intent *SwapIntent) (*RiskCheckResult, error) {
 	// Parse intent
-	params, err := e.decisionEngine.ParseIntent(intent)
+	params, err := e.decisionEngine.ParseIntent(ctx, intent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse intent: %w", err)
 	}
@@ -247,9 +674,55 @@ func (e *Engine) CheckRisk(ctx context.Context, intent *SwapIntent) (*RiskCheckR
 	}
 
 	// Check risk
+	params.Wallet = e.wallet.PublicKey()
 	return e.riskManager.CheckSwap(ctx, params, quote, balance)
 }
 
+// UpdateRiskConfig replaces the configured risk limits of a running engine;
+// runtime overrides keep tightening them
+func (e *Engine) UpdateRiskConfig(rc RiskConfig) {
+	e.riskMu.Lock()
+	defer e.riskMu.Unlock()
+	e.baseRisk = rc
+	e.applyRiskLocked()
+}
+
+// ReloadRiskConfig rebuilds the risk limits from DefaultRiskConfig and the
+// SWAPENGINE_* settings in the environment (call config.LoadFile first to
+// pick up config file edits), so a setting removed since the last load falls
+// back to its default, and applies them under the runtime overrides. It
+// returns the limits now in force; when a setting does not parse, the limits
+// in force are left unchanged.
+func (e *Engine) ReloadRiskConfig() (RiskConfig, error) {
+	rc := DefaultRiskConfig()
+	if err := applyRiskEnv(&rc); err != nil {
+		return e.riskManager.Config(), err
+	}
+	e.UpdateRiskConfig(rc)
+	return e.riskManager.Config(), nil
+}
+
+// ReloadHook applies the SWAPENGINE_* risk settings to engine on every
+// config reload (SIGHUP or POST /v1/admin/config/reload)
+func ReloadHook(engine *Engine, logger *logrus.Logger) config.ReloadHook {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return func(_, _ *config.Config) {
+		rc, err := engine.ReloadRiskConfig()
+		if err != nil {
+			logger.WithError(err).Warn("swap engine risk limits not reloaded")
+			return
+		}
+		logger.WithFields(logrus.Fields{
+			"max_swap_amount_sol":  rc.MaxSwapAmountSOL,
+			"daily_limit_sol":      rc.DailyLimitSOL,
+			"max_price_impact_bps": rc.MaxPriceImpactBps,
+			"max_slippage_bps":     rc.MaxSlippageBps,
+		}).Info("swap engine risk limits reloaded")
+	}
+}
+
 // GetWalletInfo returns wallet status
 func (e *Engine) GetWalletInfo(ctx context.Context) (*WalletInfo, error) {
 	balance, err := e.wallet.GetBalanceSOL(ctx)
@@ -277,16 +750,20 @@ func (e *Engine) GetPoolInfo() *PoolInfo {
 	}
 }
 
-// GetRiskStatus returns current risk limits and usage
+// GetRiskStatus returns current risk limits and usage of the engine's wallet
 func (e *Engine) GetRiskStatus() *RiskStatus {
-	dailyUsage := e.riskManager.dailyTracker.GetDailyUsage()
+	wallet := e.wallet.PublicKey().String()
+	dailyUsage := e.riskManager.DailyUsage(wallet)
+	cfg := e.riskManager.Config().ForWallet(wallet)
 
 	return &RiskStatus{
-		MaxSwapAmountSOL:  e.riskManager.config.MaxSwapAmountSOL,
-		DailyLimitSOL:     e.riskManager.config.DailyLimitSOL,
+		Wallet:            wallet,
+		Cooldowns:         e.riskManager.Cooldowns(wallet),
+		MaxSwapAmountSOL:  cfg.MaxSwapAmountSOL,
+		DailyLimitSOL:     cfg.DailyLimitSOL,
 		DailyUsedSOL:      dailyUsage,
-		DailyRemainingSOL: e.riskManager.config.DailyLimitSOL - dailyUsage,
-		AllowedTokens:     e.riskManager.config.AllowedTokens,
+		DailyRemainingSOL: cfg.DailyLimitSOL - dailyUsage,
+		AllowedTokens:     cfg.AllowedTokens,
 	}
 }
 
@@ -294,6 +771,16 @@ func (e *Engine) GetRiskStatus() *RiskStatus {
 func (e *Engine) Close() error {
 	var errs []error
 
+	if e.stopFlags != nil {
+		e.stopFlags()
+	}
+	if e.stopDiscovery != nil {
+		e.stopDiscovery()
+	}
+	if e.stopBlockhash != nil {
+		e.stopBlockhash()
+	}
+
 	if err := e.wallet.Close(); err != nil {
 		errs = append(errs, fmt.Errorf("wallet close: %w", err))
 	}
@@ -333,9 +820,11 @@ type PoolInfo struct {
 }
 
 type RiskStatus struct {
+	Wallet            string
 	MaxSwapAmountSOL  float64
 	DailyLimitSOL     float64
 	DailyUsedSOL      float64
 	DailyRemainingSOL float64
 	AllowedTokens     []string
+	Cooldowns         map[string]time.Time // pairs the wallet may not trade after a failed execution, until when
 }
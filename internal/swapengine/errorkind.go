@@ -0,0 +1,149 @@
+package swapengine
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/wallet"
+)
+
+// ErrorKind classifies why a swap failed at simulation, sending or
+// confirmation, so callers can react per class (retry, top up, requote)
+type ErrorKind string
+
+const (
+	ErrorKindSlippage          ErrorKind = "slippage_exceeded"  // output fell below MinAmountOut; requote
+	ErrorKindInsufficientFunds ErrorKind = "insufficient_funds" // not enough SOL for fees or rent, or tokens to swap
+	ErrorKindBlockhashExpired  ErrorKind = "blockhash_expired"  // not processed before its blockhash expired; safe to retry
+	ErrorKindAccountNotFound   ErrorKind = "account_not_found"  // fee payer, token account or program missing
+	ErrorKindProgram           ErrorKind = "program_error"      // any other instruction error; see ErrorCode
+	ErrorKindTimeout           ErrorKind = "confirmation_timeout"
+	ErrorKindUnknown           ErrorKind = "unknown"
+)
+
+// Transient reports whether the failure says nothing about the pair, so a
+// retry is expected to work and no post-failure cooldown applies
+func (k ErrorKind) Transient() bool {
+	return k == ErrorKindBlockhashExpired
+}
+
+// slippageLogs mark a slippage failure in program logs: SPL token-swap
+// (legacy Orca) logs "exceeds desired slippage limit", Jupiter
+// "SlippageToleranceExceeded" and Whirlpool "AmountOutBelowMinimum"
+var slippageLogs = []string{"slippage", "AmountOutBelowMinimum"}
+
+// insufficientFundsLogs mark a failed transfer in program logs: SPL token
+// and the system program
+var insufficientFundsLogs = []string{
+	"Error: insufficient funds",
+	"insufficient lamports",
+}
+
+// ClassifyError reduces an execution error to its kind and the failing
+// program's custom error code (-1 if none). Structured
+// errors from the wallet are classified by their transaction error and
+// logs, anything else by its message.
+func ClassifyError(err error) (kind ErrorKind, code int64) {
+	if err == nil {
+		return "", -1
+	}
+	if errors.Is(err, wallet.ErrBlockhashExpired) {
+		return ErrorKindBlockhashExpired, -1 // predicted, never sent
+	}
+	var txErr *wallet.TxError
+	if errors.As(err, &txErr) {
+		if kind, code = classifyTxError(txErr.Err, txErr.Logs); kind != ErrorKindUnknown {
+			return kind, code
+		}
+	}
+	return classifyMessage(err.Error()), -1
+}
+
+// classifyTxError classifies a transaction error as decoded from JSON:
+// "BlockhashNotFound", {"InsufficientFundsForRent":{"account_index":0}} or
+// {"InstructionError":[2,{"Custom":6001}]}
+func classifyTxError(txErr any, logs []string) (ErrorKind, int64) {
+	var class string
+	var args any
+	switch e := txErr.(type) {
+	case string:
+		class = e
+	case map[string]any:
+		for k, v := range e {
+			class, args = k, v
+		}
+	}
+
+	switch class {
+	case "":
+		return ErrorKindUnknown, -1
+	case "BlockhashNotFound":
+		return ErrorKindBlockhashExpired, -1
+	case "InsufficientFundsForFee", "InsufficientFundsForRent":
+		return ErrorKindInsufficientFunds, -1
+	case "AccountNotFound", "ProgramAccountNotFound", "InvalidAccountForFee":
+		return ErrorKindAccountNotFound, -1
+	}
+	if class != "InstructionError" {
+		return ErrorKindUnknown, -1 // e.g. AccountInUse, AlreadyProcessed
+	}
+
+	if logsContain(logs, slippageLogs) {
+		return ErrorKindSlippage, instructionErrorCode(args)
+	}
+	if logsContain(logs, insufficientFundsLogs) {
+		return ErrorKindInsufficientFunds, instructionErrorCode(args)
+	}
+	if pair, ok := args.([]any); ok && len(pair) == 2 && pair[1] == "InsufficientFunds" {
+		return ErrorKindInsufficientFunds, -1
+	}
+	return ErrorKindProgram, instructionErrorCode(args)
+}
+
+// instructionErrorCode returns the custom code of an InstructionError's
+// arguments ([2, {"Custom": 6001}]), or -1
+func instructionErrorCode(args any) int64 {
+	pair, ok := args.([]any)
+	if !ok || len(pair) != 2 {
+		return -1
+	}
+	inner, ok := pair[1].(map[string]any)
+	if !ok {
+		return -1
+	}
+	if n, ok := inner["Custom"].(float64); ok {
+		return int64(n)
+	}
+	return -1
+}
+
+// classifyMessage classifies errors that carry no transaction error, e.g.
+// preflight failures some RPCs only describe in their message
+func classifyMessage(msg string) ErrorKind {
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "blockhash not found"), strings.Contains(lower, "block height exceeded"):
+		return ErrorKindBlockhashExpired
+	case strings.Contains(lower, "confirmation timeout"):
+		return ErrorKindTimeout
+	case strings.Contains(lower, "insufficient funds"), strings.Contains(lower, "insufficient lamports"):
+		return ErrorKindInsufficientFunds
+	case strings.Contains(lower, "found no record of a prior credit"), strings.Contains(lower, "account not found"):
+		return ErrorKindAccountNotFound
+	case strings.Contains(lower, "slippage"):
+		return ErrorKindSlippage
+	}
+	return ErrorKindUnknown
+}
+
+func logsContain(logs, needles []string) bool {
+	for _, l := range logs {
+		lower := strings.ToLower(l)
+		for _, n := range needles {
+			if strings.Contains(lower, strings.ToLower(n)) {
+				return true
+			}
+		}
+	}
+	return false
+}
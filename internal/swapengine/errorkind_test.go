@@ -0,0 +1,53 @@
+package swapengine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	txErr := func(op, raw string, logs ...string) error {
+		var v any
+		require.NoError(t, json.Unmarshal([]byte(raw), &v))
+		return fmt.Errorf("failed to check signature: %w", &wallet.TxError{Op: op, Err: v, Logs: logs})
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		kind ErrorKind
+		code int64
+	}{
+		{"token-swap slippage", txErr("simulation failed", `{"InstructionError":[2,{"Custom":16}]}`,
+			"Program log: Error: Swap instruction exceeds desired slippage limit"), ErrorKindSlippage, 16},
+		{"jupiter slippage", txErr("transaction failed", `{"InstructionError":[3,{"Custom":6001}]}`,
+			"Program log: AnchorError occurred. Error Code: SlippageToleranceExceeded."), ErrorKindSlippage, 6001},
+		{"spl token balance", txErr("simulation failed", `{"InstructionError":[1,{"Custom":1}]}`,
+			"Program log: Error: insufficient funds"), ErrorKindInsufficientFunds, 1},
+		{"rent", txErr("transaction failed", `{"InsufficientFundsForRent":{"account_index":0}}`), ErrorKindInsufficientFunds, -1},
+		{"blockhash", txErr("sendTransaction error", `"BlockhashNotFound"`), ErrorKindBlockhashExpired, -1},
+		{"account", txErr("simulation failed", `"AccountNotFound"`), ErrorKindAccountNotFound, -1},
+		{"program", txErr("transaction failed", `{"InstructionError":[2,{"Custom":6018}]}`), ErrorKindProgram, 6018},
+		{"builtin instruction error", txErr("transaction failed", `{"InstructionError":[0,"InvalidAccountData"]}`), ErrorKindProgram, -1},
+		{"preflight message only", &wallet.TxError{Op: "sendTransaction error", Code: -32002,
+			Message: "Transaction simulation failed: Blockhash not found"}, ErrorKindBlockhashExpired, -1},
+		{"timeout", errors.New("transaction confirmation timeout after 1m0s"), ErrorKindTimeout, -1},
+		{"other", errors.New("connection refused"), ErrorKindUnknown, -1},
+	}
+	for _, tt := range tests {
+		kind, code := ClassifyError(tt.err)
+		assert.Equal(t, tt.kind, kind, tt.name)
+		assert.Equal(t, tt.code, code, tt.name)
+	}
+
+	kind, _ := ClassifyError(nil)
+	assert.Empty(t, kind)
+	assert.True(t, ErrorKindBlockhashExpired.Transient())
+	assert.False(t, ErrorKindSlippage.Transient())
+}
@@ -2,9 +2,11 @@ package swapengine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/cache"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/orca"
@@ -12,6 +14,10 @@ import (
 	"github.com/gagliardetto/solana-go"
 )
 
+// ErrInsufficientTokenBalance is returned when the wallet holds less of a
+// non-SOL input token than the swap spends
+var ErrInsufficientTokenBalance = apperr.New(apperr.RiskRejected, "insufficient token balance")
+
 type TokenAccountResolver interface {
 	Resolve(ctx context.Context, owner solana.PublicKey, mint solana.PublicKey) (*ResolvedTokenAccount, error)
 }
@@ -32,6 +38,7 @@ type Executor struct {
 
 	tokenAccounts  TokenAccountResolver
 	confirmTimeout time.Duration
+	computeBudget  ComputeBudgetConfig
 }
 
 func NewExecutor(
@@ -51,9 +58,16 @@ func NewExecutor(
 		risk:           risk,
 		tokenAccounts:  errTokenAccountResolver{},
 		confirmTimeout: 60 * time.Second,
+		computeBudget:  DefaultComputeBudgetConfig(),
 	}
 }
 
+// WithComputeBudget replaces how swaps are budgeted compute
+func (e *Executor) WithComputeBudget(c ComputeBudgetConfig) *Executor {
+	e.computeBudget = c
+	return e
+}
+
 func (e *Executor) WithTokenAccountResolver(r TokenAccountResolver) *Executor {
 	if r != nil {
 		e.tokenAccounts = r
@@ -115,6 +129,8 @@ func (e *Executor) GetQuote(ctx context.Context, params *SwapParams) (*QuoteResu
 		ReserveOut:    reserveOut,
 		ExecutionRate: float64(amountOut) / float64(params.AmountIn),
 		QuotedAt:      time.Now(),
+		Dexes:         []string{"Orca"},
+		Hops:          1,
 	}, nil
 }
 
@@ -131,12 +147,13 @@ func (e *Executor) ExecuteSwap(ctx context.Context, params *SwapParams) (*SwapRe
 		return &SwapResult{Success: false, Error: err.Error(), Quote: quote}, err
 	}
 
+	params.Wallet = e.wallet.PublicKey()
 	riskCheck, err := e.risk.CheckSwap(ctx, params, quote, bal)
 	if err != nil {
 		return &SwapResult{Success: false, Error: err.Error(), Quote: quote}, err
 	}
 	if !riskCheck.Allowed {
-		err := fmt.Errorf("risk check rejected: %s", riskCheck.Reason)
+		err := apperr.Errorf(apperr.RiskRejected, "risk check rejected: %s", riskCheck.Reason)
 		return &SwapResult{Success: false, Error: err.Error(), Quote: quote}, err
 	}
 
@@ -172,6 +189,18 @@ func (e *Executor) ExecuteSwap(ctx context.Context, params *SwapParams) (*SwapRe
 		return &SwapResult{Success: false, Error: err.Error(), Quote: quote}, err
 	}
 
+	// The SOL balance was checked by the risk manager; other inputs are spent
+	// from their token account, which must hold AmountIn before anything is sent
+	if params.InputMint.String() != TokenMints["SOL"] {
+		if err := e.verifyTokenBalance(ctx, params, inRes); err != nil {
+			res := &SwapResult{Success: false, Error: err.Error(), Quote: quote, ErrorCode: -1}
+			if errors.Is(err, ErrInsufficientTokenBalance) {
+				res.ErrorKind = ErrorKindInsufficientFunds
+			}
+			return res, err
+		}
+	}
+
 	// Build pre/post instruction list
 	var preIxs []solana.Instruction
 	var postIxs []solana.Instruction
@@ -216,29 +245,70 @@ func (e *Executor) ExecuteSwap(ctx context.Context, params *SwapParams) (*SwapRe
 	ixs = append(ixs, ix)
 	ixs = append(ixs, postIxs...)
 
+	// Simulate under the maximum compute limit to measure what the swap uses,
+	// then request that plus the margin
+	budget := e.computeBudget
+	compute := &ComputeUsage{}
+	var simulationMS int64
+	if budget.Enabled || e.risk.Config().RequireSimulation {
+		simIxs := ixs
+		if budget.Enabled {
+			simIxs = withComputeBudget(ixs, MaxComputeUnitLimit, 0)
+		}
+		simTx, err := e.wallet.BuildTransaction(ctx, simIxs)
+		if err != nil {
+			return &SwapResult{Success: false, Error: err.Error(), Quote: quote}, err
+		}
+		simStart := time.Now()
+		sim, err := e.wallet.SimulateTransaction(ctx, simTx)
+		if err != nil {
+			kind, code := ClassifyError(err)
+			e.risk.RecordFailure(params, kind)
+			return &SwapResult{Success: false, Error: err.Error(), ErrorKind: kind, ErrorCode: code, Quote: quote}, err
+		}
+		simulationMS = time.Since(simStart).Milliseconds()
+		compute.SimulatedUnits = sim.UnitsConsumed
+		if budget.Enabled && sim.UnitsConsumed > 0 {
+			compute.Limit, compute.PriceMicroLamports = budget.Plan(sim.UnitsConsumed)
+			ixs = withComputeBudget(ixs, compute.Limit, compute.PriceMicroLamports)
+		}
+	}
+
+	execID := fmt.Sprintf("exec_%d", time.Now().UnixNano())
+	fail := func(sig string, err error) (*SwapResult, error) {
+		kind, code := ClassifyError(err)
+		e.risk.RecordFailure(params, kind)
+		e.recordExecution(ctx, execID, sig, params, quote, compute, err, start)
+		return &SwapResult{
+			ExecutionID: execID,
+			Signature:   sig,
+			Success:     false,
+			Error:       err.Error(),
+			ErrorKind:   kind,
+			ErrorCode:   code,
+			Quote:       quote,
+			Compute:     compute,
+		}, err
+	}
+
 	tx, err := e.wallet.BuildTransaction(ctx, ixs)
 	if err != nil {
 		return &SwapResult{Success: false, Error: err.Error(), Quote: quote}, err
 	}
 
-	if e.risk.config.RequireSimulation {
-		if _, err := e.wallet.SimulateTransaction(ctx, tx); err != nil {
-			return &SwapResult{Success: false, Error: err.Error(), Quote: quote}, err
-		}
-	}
-
 	if err := e.wallet.SignTx(tx); err != nil {
 		return &SwapResult{Success: false, Error: err.Error(), Quote: quote}, err
 	}
 
 	sig, err := e.wallet.SendTx(ctx, tx, nil)
 	if err != nil {
-		return &SwapResult{Success: false, Error: err.Error(), Quote: quote}, err
+		return fail("", err)
 	}
 
 	if err := e.wallet.ConfirmTransaction(ctx, sig, "confirmed", e.confirmTimeout); err != nil {
-		return &SwapResult{Signature: sig, Success: false, Error: err.Error(), Quote: quote}, err
+		return fail(sig, err)
 	}
+	e.recordExecution(ctx, execID, sig, params, quote, compute, nil, start)
 
 	// publish to redis/clickhouse (best-effort)
 	ev := &models.SwapEvent{
@@ -262,13 +332,72 @@ func (e *Executor) ExecuteSwap(ctx context.Context, params *SwapParams) (*SwapRe
 		_ = e.clickhouse.InsertSwap(ctx, ev)
 	}
 
-	e.risk.RecordSwap(params, quote)
+	e.risk.RecordSwap(params, riskCheck.SwapValueSOL)
 
 	return &SwapResult{
-		ExecutionID: fmt.Sprintf("exec_%d", time.Now().UnixNano()),
-		Signature:   sig,
-		Success:     true,
-		Duration:    time.Since(start),
-		Quote:       quote,
+		ExecutionID:  execID,
+		Signature:    sig,
+		Success:      true,
+		Duration:     time.Since(start),
+		SimulationMS: simulationMS,
+		Quote:        quote,
+		Compute:      compute,
 	}, nil
 }
+
+// verifyTokenBalance checks that the input token account holds AmountIn; an
+// account the swap would have to create holds nothing
+func (e *Executor) verifyTokenBalance(ctx context.Context, params *SwapParams, in *ResolvedTokenAccount) error {
+	var have uint64
+	if !in.Created {
+		bal, err := e.wallet.GetTokenAccountBalance(ctx, in.Account)
+		if err != nil {
+			return fmt.Errorf("failed to read input token balance: %w", err)
+		}
+		have = bal
+	}
+	if have < params.AmountIn {
+		return fmt.Errorf("%w: %s account %s holds %d, swap needs %d (raw units)",
+			ErrInsufficientTokenBalance, e.risk.getTokenSymbol(params.InputMint), in.Account, have, params.AmountIn)
+	}
+	return nil
+}
+
+// recordExecution stores the attempt in swap_executions (best-effort). For a
+// transaction that landed, the units it consumed and its fee are read back
+// from chain; execErr is nil on success.
+func (e *Executor) recordExecution(ctx context.Context, execID, sig string, params *SwapParams, quote *QuoteResult, compute *ComputeUsage, execErr error, start time.Time) {
+	if sig != "" {
+		if units, fee, err := e.wallet.TransactionCost(ctx, sig); err == nil {
+			compute.ActualUnits, compute.FeeLamports = units, fee
+		}
+	}
+	if e.clickhouse == nil {
+		return
+	}
+	ex := &models.SwapExecution{
+		ExecutionID:      execID,
+		Signature:        sig,
+		Timestamp:        start,
+		Wallet:           params.Wallet.String(),
+		Pool:             quote.PoolName,
+		InputMint:        params.InputMint.String(),
+		OutputMint:       params.OutputMint.String(),
+		AmountIn:         params.AmountIn,
+		ExpectedOut:      quote.AmountOut,
+		Success:          execErr == nil,
+		ErrorCode:        -1,
+		SimulatedUnits:   compute.SimulatedUnits,
+		ComputeUnitLimit: compute.Limit,
+		ComputeUnitPrice: compute.PriceMicroLamports,
+		ActualUnits:      compute.ActualUnits,
+		FeeLamports:      compute.FeeLamports,
+		DurationMS:       time.Since(start).Milliseconds(),
+	}
+	if execErr != nil {
+		ex.Error = execErr.Error()
+		kind, code := ClassifyError(execErr)
+		ex.ErrorKind, ex.ErrorCode = string(kind), code
+	}
+	_ = e.clickhouse.InsertExecution(ctx, ex)
+}
@@ -0,0 +1,114 @@
+package swapengine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/jupiter"
+	"github.com/gagliardetto/solana-go"
+)
+
+// QuoteFromJupiter converts a Jupiter quote into the engine's QuoteResult so
+// Jupiter routes go through the same risk checks as native Orca swaps.
+// priceImpactPct is taken as a fraction (0.01 = 1%), like QuoteResult.PriceImpact;
+// for ExactIn quotes otherAmountThreshold is the slippage-adjusted minimum output.
+func QuoteFromJupiter(q *jupiter.QuoteResponse) (*QuoteResult, error) {
+	if q == nil {
+		return nil, fmt.Errorf("jupiter quote is nil")
+	}
+	in, err := strconv.ParseUint(q.InAmount, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inAmount %q: %w", q.InAmount, err)
+	}
+	out, err := strconv.ParseUint(q.OutAmount, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid outAmount %q: %w", q.OutAmount, err)
+	}
+	minOut := out
+	if q.SwapMode != "ExactOut" && q.OtherAmountThreshold != "" {
+		if minOut, err = strconv.ParseUint(q.OtherAmountThreshold, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid otherAmountThreshold %q: %w", q.OtherAmountThreshold, err)
+		}
+	}
+	var impact float64
+	if q.PriceImpactPct != "" {
+		if impact, err = strconv.ParseFloat(q.PriceImpactPct, 64); err != nil {
+			return nil, fmt.Errorf("invalid priceImpactPct %q: %w", q.PriceImpactPct, err)
+		}
+	}
+
+	dexes, hops := routeSummary(q.RoutePlan)
+	res := &QuoteResult{
+		PoolName:     strings.Join(dexes, ">"),
+		AmountIn:     in,
+		AmountOut:    out,
+		MinAmountOut: minOut,
+		PriceImpact:  impact,
+		Dexes:        dexes,
+		Hops:         hops,
+		QuotedAt:     time.Now(),
+	}
+	if in > 0 {
+		res.ExecutionRate = float64(out) / float64(in)
+	}
+	return res, nil
+}
+
+// routeSummary lists the distinct DEX labels of a route plan in order, and
+// counts hops as the distinct input mints: split legs of one hop share theirs
+func routeSummary(plan []jupiter.RoutePlanStep) (dexes []string, hops int) {
+	seenDex := map[string]bool{}
+	seenMint := map[string]bool{}
+	for _, step := range plan {
+		label := step.SwapInfo.Label
+		if label == "" {
+			label = step.SwapInfo.AmmKey
+		}
+		if !seenDex[label] {
+			seenDex[label] = true
+			dexes = append(dexes, label)
+		}
+		if !seenMint[step.SwapInfo.InputMint] {
+			seenMint[step.SwapInfo.InputMint] = true
+			hops++
+		}
+	}
+	return dexes, hops
+}
+
+// CheckJupiterRoute runs a Jupiter quote through the risk manager as a swap
+// by the engine's wallet, including the route hop limit and excluded DEXes
+func (e *Engine) CheckJupiterRoute(ctx context.Context, q *jupiter.QuoteResponse) (*RiskCheckResult, *QuoteResult, error) {
+	quote, err := QuoteFromJupiter(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	inMint, err := solana.PublicKeyFromBase58(q.InputMint)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid inputMint: %w", err)
+	}
+	outMint, err := solana.PublicKeyFromBase58(q.OutputMint)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid outputMint: %w", err)
+	}
+	params := &SwapParams{
+		InputMint:    inMint,
+		OutputMint:   outMint,
+		AmountIn:     quote.AmountIn,
+		MinAmountOut: quote.MinAmountOut,
+		PoolName:     quote.PoolName,
+		SlippageBps:  q.SlippageBps,
+		Wallet:       e.wallet.PublicKey(),
+		ParsedAt:     time.Now(),
+	}
+
+	balance, err := e.wallet.GetBalanceSOL(ctx)
+	if err != nil {
+		return nil, quote, fmt.Errorf("failed to get balance: %w", err)
+	}
+	res, err := e.riskManager.CheckSwap(ctx, params, quote, balance)
+	return res, quote, err
+}
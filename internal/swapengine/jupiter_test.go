@@ -0,0 +1,48 @@
+package swapengine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/jupiter"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteFromJupiterRoute(t *testing.T) {
+	sol, usdc, bonk := TokenMints["SOL"], TokenMints["USDC"], "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+	q := &jupiter.QuoteResponse{
+		InputMint: sol, OutputMint: usdc,
+		InAmount: "1000000000", OutAmount: "150000000", OtherAmountThreshold: "148500000",
+		SwapMode: "ExactIn", SlippageBps: 100, PriceImpactPct: "0.002",
+		RoutePlan: []jupiter.RoutePlanStep{
+			{SwapInfo: jupiter.SwapInfo{Label: "Orca", InputMint: sol, OutputMint: bonk}},
+			{SwapInfo: jupiter.SwapInfo{Label: "Raydium", InputMint: sol, OutputMint: bonk}}, // split leg
+			{SwapInfo: jupiter.SwapInfo{Label: "Phoenix", InputMint: bonk, OutputMint: usdc}},
+		},
+	}
+
+	quote, err := QuoteFromJupiter(q)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(148500000), quote.MinAmountOut)
+	assert.InDelta(t, 0.002, quote.PriceImpact, 1e-9)
+	assert.Equal(t, []string{"Orca", "Raydium", "Phoenix"}, quote.Dexes)
+	assert.Equal(t, 2, quote.Hops)
+
+	params := &SwapParams{InputMint: solana.MustPublicKeyFromBase58(sol), OutputMint: solana.MustPublicKeyFromBase58(usdc), AmountIn: quote.AmountIn, SlippageBps: 100}
+	cfg := DefaultRiskConfig()
+
+	res, err := NewRiskManager(cfg).CheckSwap(context.Background(), params, quote, 5)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, res.Reason)
+
+	cfg.MaxRouteHops = 1
+	res, _ = NewRiskManager(cfg).CheckSwap(context.Background(), params, quote, 5)
+	assert.True(t, res.RouteTooLong)
+
+	cfg.MaxRouteHops, cfg.ExcludedDexes = 0, []string{"phoenix"}
+	res, _ = NewRiskManager(cfg).CheckSwap(context.Background(), params, quote, 5)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, "Phoenix", res.ExcludedDex)
+}
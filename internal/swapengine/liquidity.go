@@ -0,0 +1,234 @@
+package swapengine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/orca"
+	"github.com/gagliardetto/solana-go"
+)
+
+// PositionInfo is the engine wallet's LP stake in one pool
+type PositionInfo struct {
+	PoolName   string
+	TokenMintA string
+	TokenMintB string
+	LPBalance  uint64  // LP tokens held
+	LPSupply   uint64  // LP tokens outstanding
+	Share      float64 // fraction of the pool owned
+	AmountA    uint64  // token A redeemable at current reserves (raw)
+	AmountB    uint64  // token B redeemable at current reserves (raw)
+}
+
+// LiquidityResult describes a confirmed deposit or withdrawal
+type LiquidityResult struct {
+	Signature  string
+	PoolName   string
+	PoolTokens uint64 // LP tokens minted or burned
+	AmountA    uint64 // max token A deposited / min token A withdrawn (raw)
+	AmountB    uint64 // max token B deposited / min token B withdrawn (raw)
+}
+
+// GetPositions lists the pools in which the engine wallet holds LP tokens
+func (e *Engine) GetPositions(ctx context.Context) ([]PositionInfo, error) {
+	owner := e.wallet.PublicKey()
+	var out []PositionInfo
+	for _, pool := range e.poolRegistry.GetAllPools() {
+		pos, err := orca.FetchPosition(ctx, e.orcaClient, &pool, owner)
+		if err != nil {
+			return nil, fmt.Errorf("pool %s: %w", pool.Name, err)
+		}
+		if pos.LPBalance == 0 {
+			continue
+		}
+		out = append(out, PositionInfo{
+			PoolName:   pool.Name,
+			TokenMintA: pool.TokenMintA.String(),
+			TokenMintB: pool.TokenMintB.String(),
+			LPBalance:  pos.LPBalance,
+			LPSupply:   pos.LPSupply,
+			Share:      pos.Share,
+			AmountA:    pos.AmountA,
+			AmountB:    pos.AmountB,
+		})
+	}
+	return out, nil
+}
+
+// ProvideLiquidity deposits into poolName using at most maxA/maxB raw tokens.
+// Reserves may move before the transaction lands, so the LP tokens requested
+// are reduced by slippageBps (0 uses the default slippage).
+func (e *Engine) ProvideLiquidity(ctx context.Context, poolName string, maxA, maxB uint64, slippageBps uint16) (*LiquidityResult, error) {
+	if e.KillSwitchOn() {
+		return nil, ErrKillSwitch
+	}
+	return e.executor.Deposit(ctx, poolName, maxA, maxB, e.slippage(slippageBps))
+}
+
+// WithdrawLiquidity burns poolTokens LP tokens of poolName (0 withdraws the
+// whole position), accepting up to slippageBps less than the current value
+func (e *Engine) WithdrawLiquidity(ctx context.Context, poolName string, poolTokens uint64, slippageBps uint16) (*LiquidityResult, error) {
+	if e.KillSwitchOn() {
+		return nil, ErrKillSwitch
+	}
+	return e.executor.Withdraw(ctx, poolName, poolTokens, e.slippage(slippageBps))
+}
+
+// slippage applies the default and the cap from the risk config
+func (e *Engine) slippage(bps uint16) uint16 {
+	rc := e.riskManager.Config()
+	if bps == 0 {
+		bps = rc.DefaultSlippageBps
+	}
+	if rc.MaxSlippageBps > 0 && bps > rc.MaxSlippageBps {
+		bps = rc.MaxSlippageBps
+	}
+	return bps
+}
+
+// Deposit builds, sends and confirms a DepositAllTokenTypes transaction
+func (e *Executor) Deposit(ctx context.Context, poolName string, maxA, maxB uint64, slippageBps uint16) (*LiquidityResult, error) {
+	pool, err := e.poolRegistry.FindPoolByName(poolName)
+	if err != nil {
+		return nil, err
+	}
+	state, err := orca.RefreshPoolState(ctx, e.orcaClient, pool)
+	if err != nil {
+		return nil, err
+	}
+	supply, err := e.orcaClient.FetchTokenSupply(ctx, pool.PoolMint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch LP supply: %w", err)
+	}
+
+	poolTokens := orca.ApplySlippage(orca.PoolTokensForDeposit(maxA, maxB, state.ReserveA, state.ReserveB, supply), slippageBps)
+	if poolTokens == 0 {
+		return nil, fmt.Errorf("deposit too small for pool %s", pool.Name)
+	}
+
+	owner := e.wallet.PublicKey()
+	accA, accB, accLP, err := e.resolveLiquidityAccounts(ctx, owner, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	preIxs := append(append(append([]solana.Instruction{}, accA.PreIxs...), accB.PreIxs...), accLP.PreIxs...)
+	var postIxs []solana.Instruction
+	// Wrap the SOL side; leftovers are unwrapped if the wSOL account is new
+	for _, side := range []struct {
+		mint solana.PublicKey
+		acc  *ResolvedTokenAccount
+		max  uint64
+	}{{pool.TokenMintA, accA, maxA}, {pool.TokenMintB, accB, maxB}} {
+		if side.mint.String() != TokenMints["SOL"] {
+			continue
+		}
+		preIxs = append(preIxs, NewSystemTransferIx(owner, side.acc.Account, side.max), NewTokenSyncNativeIx(side.acc.Account))
+		if side.acc.Created {
+			postIxs = append(postIxs, NewTokenCloseAccountIx(side.acc.Account, owner, owner))
+		}
+	}
+
+	ix, err := orca.BuildDepositInstruction(pool, poolTokens, maxA, maxB, owner, accA.Account, accB.Account, accLP.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := e.sendInstructions(ctx, append(append(preIxs, ix), postIxs...))
+	res := &LiquidityResult{Signature: sig, PoolName: pool.Name, PoolTokens: poolTokens, AmountA: maxA, AmountB: maxB}
+	return res, err
+}
+
+// Withdraw builds, sends and confirms a WithdrawAllTokenTypes transaction
+func (e *Executor) Withdraw(ctx context.Context, poolName string, poolTokens uint64, slippageBps uint16) (*LiquidityResult, error) {
+	pool, err := e.poolRegistry.FindPoolByName(poolName)
+	if err != nil {
+		return nil, err
+	}
+	owner := e.wallet.PublicKey()
+	pos, err := orca.FetchPosition(ctx, e.orcaClient, pool, owner)
+	if err != nil {
+		return nil, err
+	}
+	if pos.LPBalance == 0 {
+		return nil, fmt.Errorf("no liquidity position in pool %s", pool.Name)
+	}
+	if poolTokens == 0 {
+		poolTokens = pos.LPBalance
+	}
+	if poolTokens > pos.LPBalance {
+		return nil, fmt.Errorf("withdraw of %d LP tokens exceeds position of %d", poolTokens, pos.LPBalance)
+	}
+
+	state, err := orca.RefreshPoolState(ctx, e.orcaClient, pool)
+	if err != nil {
+		return nil, err
+	}
+	outA, outB := orca.WithdrawAmounts(poolTokens, state.ReserveA, state.ReserveB, pos.LPSupply)
+	minA, minB := orca.ApplySlippage(outA, slippageBps), orca.ApplySlippage(outB, slippageBps)
+
+	accA, accB, accLP, err := e.resolveLiquidityAccounts(ctx, owner, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	preIxs := append(append(append([]solana.Instruction{}, accA.PreIxs...), accB.PreIxs...), accLP.PreIxs...)
+	var postIxs []solana.Instruction
+	// Unwrap withdrawn SOL when the wSOL account is created for this transaction
+	for _, side := range []struct {
+		mint solana.PublicKey
+		acc  *ResolvedTokenAccount
+	}{{pool.TokenMintA, accA}, {pool.TokenMintB, accB}} {
+		if side.mint.String() == TokenMints["SOL"] && side.acc.Created {
+			postIxs = append(postIxs, NewTokenCloseAccountIx(side.acc.Account, owner, owner))
+		}
+	}
+
+	ix, err := orca.BuildWithdrawInstruction(pool, poolTokens, minA, minB, owner, accLP.Account, accA.Account, accB.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := e.sendInstructions(ctx, append(append(preIxs, ix), postIxs...))
+	res := &LiquidityResult{Signature: sig, PoolName: pool.Name, PoolTokens: poolTokens, AmountA: minA, AmountB: minB}
+	return res, err
+}
+
+// resolveLiquidityAccounts resolves the owner's token A, token B and LP token accounts
+func (e *Executor) resolveLiquidityAccounts(ctx context.Context, owner solana.PublicKey, pool *orca.LegacyPool) (a, b, lp *ResolvedTokenAccount, err error) {
+	if a, err = e.tokenAccounts.Resolve(ctx, owner, pool.TokenMintA); err != nil {
+		return nil, nil, nil, err
+	}
+	if b, err = e.tokenAccounts.Resolve(ctx, owner, pool.TokenMintB); err != nil {
+		return nil, nil, nil, err
+	}
+	if lp, err = e.tokenAccounts.Resolve(ctx, owner, pool.PoolMint); err != nil {
+		return nil, nil, nil, err
+	}
+	return a, b, lp, nil
+}
+
+// sendInstructions builds, optionally simulates, signs, sends and confirms a
+// transaction. The signature is returned even when confirmation fails.
+func (e *Executor) sendInstructions(ctx context.Context, ixs []solana.Instruction) (string, error) {
+	tx, err := e.wallet.BuildTransaction(ctx, ixs)
+	if err != nil {
+		return "", err
+	}
+	if e.risk.Config().RequireSimulation {
+		if _, err := e.wallet.SimulateTransaction(ctx, tx); err != nil {
+			return "", err
+		}
+	}
+	if err := e.wallet.SignTx(tx); err != nil {
+		return "", err
+	}
+	sig, err := e.wallet.SendTx(ctx, tx, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := e.wallet.ConfirmTransaction(ctx, sig, "confirmed", e.confirmTimeout); err != nil {
+		return sig, err
+	}
+	return sig, nil
+}
@@ -0,0 +1,129 @@
+package swapengine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/orca"
+)
+
+// poolStateConcurrency bounds parallel reserve lookups in ListPools
+const poolStateConcurrency = 8
+
+// PoolStatus is a registered pool with its current reserves
+type PoolStatus struct {
+	Name        string
+	SwapAccount string
+	TokenMintA  string
+	TokenMintB  string
+	VaultA      string
+	VaultB      string
+	FeeBps      uint16
+	Discovered  bool
+	ReserveA    uint64
+	ReserveB    uint64
+	Error       string // set when the reserves could not be fetched
+}
+
+// PoolReloadResult summarises a pool registry reload
+type PoolReloadResult struct {
+	Path       string
+	Source     string
+	Configured int // pools loaded from the config file
+	Skipped    int // invalid entries left out
+	Discovered int // pools added by the rescan that follows the reload
+	Total      int
+	Errors     []orca.PoolFieldError // why entries were skipped
+}
+
+// ReloadPools re-reads the pool config (SWAPENGINE_POOL_CONFIG_PATH,
+// SWAPENGINE_POOL_SOURCE and SWAPENGINE_POOL_STRICT from the refreshed
+// environment, falling back to the values in use) and swaps it in without a
+// restart. Invalid entries are skipped (see Errors) unless strict mode is on.
+// Discovered pools are kept, and with discovery enabled the programs are
+// rescanned. A config that fails to load leaves the running registry untouched.
+func (e *Engine) ReloadPools(ctx context.Context) (*PoolReloadResult, error) {
+	e.poolMu.Lock()
+	defer e.poolMu.Unlock()
+
+	path, source, strict := e.poolConfigPath, e.poolSource, e.poolStrict
+	if v := os.Getenv("SWAPENGINE_POOL_CONFIG_PATH"); v != "" {
+		path = v
+	}
+	if v := os.Getenv("SWAPENGINE_POOL_SOURCE"); v != "" {
+		source = strings.ToLower(strings.TrimSpace(v))
+	}
+	if v := os.Getenv("SWAPENGINE_POOL_STRICT"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			strict = b
+		}
+	}
+
+	next, summary, err := loadPoolRegistry(path, source, strict, e.orcaClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pool registry: %w", err)
+	}
+	e.poolRegistry.ReplaceConfigured(next.GetAllPools())
+	e.poolConfigPath, e.poolSource, e.poolStrict, e.poolSummary = path, source, strict, summary
+
+	res := &PoolReloadResult{
+		Path:       path,
+		Source:     summary.Source,
+		Configured: summary.Loaded,
+		Skipped:    summary.Skipped,
+		Errors:     summary.Errors,
+	}
+	if e.discoverer != nil {
+		added, err := e.discoverer.Discover(ctx)
+		res.Discovered = added
+		if err != nil {
+			res.Total = e.poolRegistry.PoolCount()
+			return res, fmt.Errorf("pools reloaded, rediscovery failed: %w", err)
+		}
+	}
+	res.Total = e.poolRegistry.PoolCount()
+	return res, nil
+}
+
+// ListPools returns every registered pool with its current vault balances.
+// A pool whose balances cannot be fetched is still listed, with Error set.
+func (e *Engine) ListPools(ctx context.Context) []PoolStatus {
+	pools := e.poolRegistry.GetAllPools()
+	out := make([]PoolStatus, len(pools))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, poolStateConcurrency)
+	for i := range pools {
+		pool := &pools[i]
+		out[i] = PoolStatus{
+			Name:        pool.Name,
+			SwapAccount: pool.SwapAccount.String(),
+			TokenMintA:  pool.TokenMintA.String(),
+			TokenMintB:  pool.TokenMintB.String(),
+			VaultA:      pool.VaultA.String(),
+			VaultB:      pool.VaultB.String(),
+			FeeBps:      orca.CalculateFeeBps(pool.FeeNumerator, pool.FeeDenominator),
+			Discovered:  pool.Discovered,
+		}
+
+		wg.Add(1)
+		go func(st *PoolStatus) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			state, err := orca.RefreshPoolState(ctx, e.orcaClient, pool)
+			if err != nil {
+				st.Error = err.Error()
+				return
+			}
+			st.ReserveA, st.ReserveB = state.ReserveA, state.ReserveB
+		}(&out[i])
+	}
+	wg.Wait()
+	return out
+}
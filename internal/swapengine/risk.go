@@ -3,9 +3,11 @@ package swapengine
 import (
 	"context"
 	"fmt"
-	"math"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/tokens"
 	"github.com/gagliardetto/solana-go"
 )
 
@@ -27,9 +29,40 @@ type RiskConfig struct {
 	// Token whitelist (empty = allow all)
 	AllowedTokens []string
 
+	// Route constraints for aggregator quotes
+	MaxRouteHops  int      // Max sequential swaps in a route (0 = unlimited)
+	ExcludedDexes []string // DEX labels a route must not touch (case-insensitive)
+
 	// Safety features
-	RequireSimulation bool    // Always simulate before sending
-	MinBalanceSOL     float64 // Min wallet balance to keep
+	RequireSimulation bool          // Always simulate before sending
+	MinBalanceSOL     float64       // Min wallet balance to keep
+	FailureCooldown   time.Duration // Block a pair this long after a failed execution (0 = off)
+
+	// Per-wallet limits by address; other wallets get the limits above.
+	// Daily usage is tracked per wallet either way.
+	WalletLimits map[string]WalletLimits
+}
+
+// WalletLimits scope the per-swap and daily limits to one wallet (0 = the
+// global limit), e.g. a small budget for a test wallet next to the treasury
+type WalletLimits struct {
+	MaxSwapAmountSOL float64
+	DailyLimitSOL    float64
+}
+
+// ForWallet returns the limits that apply to the wallet at address
+func (c RiskConfig) ForWallet(address string) RiskConfig {
+	l, ok := c.WalletLimits[address]
+	if !ok {
+		return c
+	}
+	if l.MaxSwapAmountSOL > 0 {
+		c.MaxSwapAmountSOL = l.MaxSwapAmountSOL
+	}
+	if l.DailyLimitSOL > 0 {
+		c.DailyLimitSOL = l.DailyLimitSOL
+	}
+	return c
 }
 
 // DefaultRiskConfig returns conservative risk settings
@@ -41,8 +74,10 @@ func DefaultRiskConfig() RiskConfig {
 		DefaultSlippageBps: 100,  // 1% default slippage
 		MaxSlippageBps:     1000, // 10% max slippage
 		AllowedTokens:      []string{"SOL", "USDC", "USDT"},
+		MaxRouteHops:       3,
 		RequireSimulation:  true,
 		MinBalanceSOL:      0.05, // Keep 0.05 SOL for fees
+		FailureCooldown:    5 * time.Minute,
 	}
 }
 
@@ -59,16 +94,66 @@ func (rm *RiskManager) getTokenSymbol(mint solana.PublicKey) string {
 
 // RiskManager enforces risk limits
 type RiskManager struct {
-	config       RiskConfig
-	dailyTracker *DailyLimitTracker
+	mu            sync.RWMutex
+	config        RiskConfig
+	dailyTrackers map[string]*DailyLimitTracker // by wallet address
+
+	prices     PriceFeed        // optional; see WithPrices
+	decimals   *tokens.Resolver // optional; TokenDecimals without it
+	staleAfter time.Duration
+
+	cooldowns      map[cooldownKey]time.Time // end of each wallet's post-failure pair cooldowns
+	bypassCooldown func() bool               // optional; see WithCooldownBypass
 }
 
 // NewRiskManager creates a risk manager with the given config
 func NewRiskManager(config RiskConfig) *RiskManager {
 	return &RiskManager{
-		config:       config,
-		dailyTracker: NewDailyLimitTracker(),
+		config:        config,
+		dailyTrackers: make(map[string]*DailyLimitTracker),
+		cooldowns:     make(map[cooldownKey]time.Time),
+	}
+}
+
+// tracker returns the daily usage tracker of the wallet at address
+func (rm *RiskManager) tracker(address string) *DailyLimitTracker {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	t, ok := rm.dailyTrackers[address]
+	if !ok {
+		t = NewDailyLimitTracker()
+		rm.dailyTrackers[address] = t
+	}
+	return t
+}
+
+// DailyUsage returns the SOL value swapped by the wallet at address in the
+// last 24 hours
+func (rm *RiskManager) DailyUsage(address string) float64 {
+	return rm.tracker(address).GetDailyUsage()
+}
+
+// walletAddress is the address risk state is scoped to; swaps without a
+// wallet share one budget
+func walletAddress(params *SwapParams) string {
+	if params.Wallet.IsZero() {
+		return ""
 	}
+	return params.Wallet.String()
+}
+
+// Config returns a snapshot of the active risk settings
+func (rm *RiskManager) Config() RiskConfig {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.config
+}
+
+// SetConfig replaces the risk settings; daily usage tracking is preserved
+func (rm *RiskManager) SetConfig(config RiskConfig) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.config = config
 }
 
 // CheckSwap validates a swap against all risk rules
@@ -78,44 +163,65 @@ func (rm *RiskManager) CheckSwap(
 	quote *QuoteResult,
 	walletBalanceSOL float64,
 ) (*RiskCheckResult, error) {
+	wallet := walletAddress(params)
+	cfg := rm.Config().ForWallet(wallet)
 
 	result := &RiskCheckResult{
 		Allowed:           true,
-		MaxSwapAmountSOL:  rm.config.MaxSwapAmountSOL,
-		DailyLimitSOL:     rm.config.DailyLimitSOL,
-		MaxPriceImpactBps: rm.config.MaxPriceImpactBps,
-		WhitelistedTokens: rm.config.AllowedTokens,
+		MaxSwapAmountSOL:  cfg.MaxSwapAmountSOL,
+		DailyLimitSOL:     cfg.DailyLimitSOL,
+		MaxPriceImpactBps: cfg.MaxPriceImpactBps,
+		WhitelistedTokens: cfg.AllowedTokens,
+		MaxRouteHops:      cfg.MaxRouteHops,
+	}
+
+	// 0. Check the pair is not cooling down after a failed execution by this wallet
+	if until, ok := rm.cooldownUntil(params); ok {
+		result.Allowed = false
+		result.CooldownUntil = until
+		result.Reason = fmt.Sprintf("pair %s/%s is cooling down after a failed swap until %s",
+			rm.getTokenSymbol(params.InputMint), rm.getTokenSymbol(params.OutputMint), until.UTC().Format(time.RFC3339))
+		return result, nil
 	}
 
 	// 1. Check per-transaction limit
-	swapValueSOL := rm.estimateSwapValueSOL(params, quote)
-	if swapValueSOL > rm.config.MaxSwapAmountSOL {
+	valuation := rm.estimateSwapValueSOL(ctx, params, quote)
+	swapValueSOL := valuation.SOL
+	result.SwapValueSOL, result.ValuationSource = swapValueSOL, valuation.Source
+	if valuation.Source == ValuationUnpriced {
+		result.Allowed = false
+		result.ExceedsMaxSwapAmount = true
+		result.Reason = fmt.Sprintf("cannot value %s/%s swap in SOL: no indexed price, so it cannot be held to the %.4f SOL per-transaction limit",
+			rm.getTokenSymbol(params.InputMint), rm.getTokenSymbol(params.OutputMint), cfg.MaxSwapAmountSOL)
+		return result, nil
+	}
+	if swapValueSOL > cfg.MaxSwapAmountSOL {
 		result.Allowed = false
 		result.ExceedsMaxSwapAmount = true
 		result.Reason = fmt.Sprintf("swap value %.4f SOL exceeds max %.4f SOL per transaction",
-			swapValueSOL, rm.config.MaxSwapAmountSOL)
+			swapValueSOL, cfg.MaxSwapAmountSOL)
 		return result, nil
 	}
 
 	// 2. Check daily limit
-	dailyUsed := rm.dailyTracker.GetDailyUsage()
+	dailyUsed := rm.DailyUsage(wallet)
 	result.DailyUsedSOL = dailyUsed
-	result.DailyRemainingSOL = rm.config.DailyLimitSOL - dailyUsed
+	result.DailyRemainingSOL = cfg.DailyLimitSOL - dailyUsed
 
-	if dailyUsed+swapValueSOL > rm.config.DailyLimitSOL {
+	if dailyUsed+swapValueSOL > cfg.DailyLimitSOL {
 		result.Allowed = false
 		result.ExceedsDailyLimit = true
 		result.Reason = fmt.Sprintf("daily limit exceeded: used %.4f + %.4f > %.4f SOL",
-			dailyUsed, swapValueSOL, rm.config.DailyLimitSOL)
+			dailyUsed, swapValueSOL, cfg.DailyLimitSOL)
 		return result, nil
 	}
 
 	// 3. Check token whitelist
-	if len(rm.config.AllowedTokens) > 0 {
+	if len(cfg.AllowedTokens) > 0 {
 		inputSymbol := rm.getTokenSymbol(params.InputMint)
 		outputSymbol := rm.getTokenSymbol(params.OutputMint)
 
-		if !rm.isTokenAllowed(inputSymbol) || !rm.isTokenAllowed(outputSymbol) {
+		if !isTokenAllowed(cfg, inputSymbol) || !isTokenAllowed(cfg, outputSymbol) {
 			result.Allowed = false
 			result.TokenNotWhitelisted = true
 			result.Reason = fmt.Sprintf("token not whitelisted: %s or %s",
@@ -125,67 +231,142 @@ func (rm *RiskManager) CheckSwap(
 	}
 
 	// 4. Check price impact
-	if quote.PriceImpact*10000 > float64(rm.config.MaxPriceImpactBps) {
+	if quote.PriceImpact*10000 > float64(cfg.MaxPriceImpactBps) {
 		result.Allowed = false
 		result.PriceImpactTooHigh = true
 		result.ActualPriceImpact = quote.PriceImpact
 		result.Reason = fmt.Sprintf("price impact %.2f%% exceeds max %.2f%%",
-			quote.PriceImpact*100, float64(rm.config.MaxPriceImpactBps)/100)
+			quote.PriceImpact*100, float64(cfg.MaxPriceImpactBps)/100)
 		return result, nil
 	}
 
-	// 5. Check minimum balance (ensure enough for fees)
-	if walletBalanceSOL-swapValueSOL < rm.config.MinBalanceSOL {
+	// 5. Check route length and venues
+	if cfg.MaxRouteHops > 0 && quote.Hops > cfg.MaxRouteHops {
+		result.Allowed = false
+		result.RouteTooLong = true
+		result.Reason = fmt.Sprintf("route has %d hops, max %d", quote.Hops, cfg.MaxRouteHops)
+		return result, nil
+	}
+	if dex := excludedDex(cfg, quote.Dexes); dex != "" {
+		result.Allowed = false
+		result.ExcludedDex = dex
+		result.Reason = fmt.Sprintf("route uses excluded DEX %s", dex)
+		return result, nil
+	}
+
+	// 6. Check minimum balance (ensure enough for fees); only SOL inputs
+	// spend the wallet's SOL
+	remainingSOL := walletBalanceSOL
+	if params.InputMint.String() == TokenMints["SOL"] {
+		remainingSOL -= swapValueSOL
+	}
+	if remainingSOL < cfg.MinBalanceSOL {
 		result.Allowed = false
 		result.Reason = fmt.Sprintf("insufficient balance: would leave %.4f SOL, need %.4f SOL minimum",
-			walletBalanceSOL-swapValueSOL, rm.config.MinBalanceSOL)
+			remainingSOL, cfg.MinBalanceSOL)
 		return result, nil
 	}
 
-	// 6. Validate slippage
-	if params.SlippageBps > rm.config.MaxSlippageBps {
+	// 7. Validate slippage
+	if params.SlippageBps > cfg.MaxSlippageBps {
 		result.Allowed = false
 		result.Reason = fmt.Sprintf("slippage %d bps exceeds max %d bps",
-			params.SlippageBps, rm.config.MaxSlippageBps)
+			params.SlippageBps, cfg.MaxSlippageBps)
 		return result, nil
 	}
 
 	return result, nil
 }
 
-// RecordSwap records a successful swap for daily limit tracking
-func (rm *RiskManager) RecordSwap(params *SwapParams, quote *QuoteResult) {
-	swapValueSOL := rm.estimateSwapValueSOL(params, quote)
-	rm.dailyTracker.RecordSwap(swapValueSOL)
+// RecordSwap records a successful swap, valued at RiskCheckResult.SwapValueSOL,
+// against its wallet's daily limit
+func (rm *RiskManager) RecordSwap(params *SwapParams, swapValueSOL float64) {
+	rm.tracker(walletAddress(params)).RecordSwap(swapValueSOL)
 }
 
-// estimateSwapValueSOL converts swap amount to SOL equivalent
-func (rm *RiskManager) estimateSwapValueSOL(params *SwapParams, quote *QuoteResult) float64 {
-	// If input is SOL, use that directly
-	if params.InputMint.String() == TokenMints["SOL"] {
-		decimals := TokenDecimals["SOL"]
-		denom := math.Pow10(int(decimals))
-		return float64(params.AmountIn) / denom
+// WithCooldownBypass lets swaps through pairs that are cooling down while
+// bypass returns true, e.g. while an operator flag is set
+func (rm *RiskManager) WithCooldownBypass(bypass func() bool) *RiskManager {
+	rm.bypassCooldown = bypass
+	return rm
+}
+
+// RecordFailure starts the FailureCooldown of the swap's pair for its
+// wallet after an execution failed or reverted; transient failures (an
+// expired blockhash) start none
+func (rm *RiskManager) RecordFailure(params *SwapParams, kind ErrorKind) {
+	cooldown := rm.Config().FailureCooldown
+	if cooldown <= 0 || kind.Transient() {
+		return
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.cooldowns[newCooldownKey(params)] = time.Now().Add(cooldown)
+}
+
+// Cooldowns returns the end of every active pair cooldown of the wallet at
+// address, keyed by pair ("SOL/USDC", in either token order)
+func (rm *RiskManager) Cooldowns(address string) map[string]time.Time {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	now := time.Now()
+	out := make(map[string]time.Time)
+	for key, until := range rm.cooldowns {
+		if !until.After(now) {
+			delete(rm.cooldowns, key)
+			continue
+		}
+		if key.wallet != address {
+			continue
+		}
+		a, b, _ := strings.Cut(key.pair, "|")
+		out[rm.getTokenSymbol(solana.MustPublicKeyFromBase58(a))+"/"+rm.getTokenSymbol(solana.MustPublicKeyFromBase58(b))] = until
 	}
+	return out
+}
 
-	// If output is SOL, use that
-	if params.OutputMint.String() == TokenMints["SOL"] {
-		decimals := TokenDecimals["SOL"]
-		denom := math.Pow10(int(decimals))
-		return float64(quote.AmountOut) / denom
+// cooldownUntil reports when the swap's wallet may trade its pair again, if
+// it is cooling down and the cooldown is not bypassed
+func (rm *RiskManager) cooldownUntil(params *SwapParams) (time.Time, bool) {
+	rm.mu.RLock()
+	until, ok := rm.cooldowns[newCooldownKey(params)]
+	rm.mu.RUnlock()
+	if !ok || !until.After(time.Now()) {
+		return time.Time{}, false
 	}
+	if rm.bypassCooldown != nil && rm.bypassCooldown() {
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// cooldownKey scopes a pair cooldown to the wallet whose swap failed, like
+// the daily limits
+type cooldownKey struct {
+	wallet string
+	pair   string
+}
 
-	// MVP fallback: treat non-SOL swaps as small constant SOL value
-	return 0.01
+func newCooldownKey(params *SwapParams) cooldownKey {
+	return cooldownKey{wallet: walletAddress(params), pair: pairKey(params.InputMint, params.OutputMint)}
+}
+
+// pairKey names a pair the same way in either swap direction
+func pairKey(a, b solana.PublicKey) string {
+	x, y := a.String(), b.String()
+	if x > y {
+		x, y = y, x
+	}
+	return x + "|" + y
 }
 
 // isTokenAllowed checks if a token is in the whitelist
-func (rm *RiskManager) isTokenAllowed(symbol string) bool {
-	if len(rm.config.AllowedTokens) == 0 {
+func isTokenAllowed(cfg RiskConfig, symbol string) bool {
+	if len(cfg.AllowedTokens) == 0 {
 		return true // No whitelist = allow all
 	}
 
-	for _, allowed := range rm.config.AllowedTokens {
+	for _, allowed := range cfg.AllowedTokens {
 		if allowed == symbol {
 			return true
 		}
@@ -193,8 +374,21 @@ func (rm *RiskManager) isTokenAllowed(symbol string) bool {
 	return false
 }
 
+// excludedDex returns the first route DEX on the exclusion list, or ""
+func excludedDex(cfg RiskConfig, dexes []string) string {
+	for _, d := range dexes {
+		for _, ex := range cfg.ExcludedDexes {
+			if strings.EqualFold(d, ex) {
+				return d
+			}
+		}
+	}
+	return ""
+}
+
 // DailyLimitTracker tracks rolling 24-hour usage
 type DailyLimitTracker struct {
+	mu    sync.Mutex
 	swaps []swapRecord
 }
 
@@ -212,6 +406,8 @@ func NewDailyLimitTracker() *DailyLimitTracker {
 
 // RecordSwap adds a swap to the tracker
 func (t *DailyLimitTracker) RecordSwap(amountSOL float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.swaps = append(t.swaps, swapRecord{
 		timestamp: time.Now(),
 		amountSOL: amountSOL,
@@ -223,6 +419,8 @@ func (t *DailyLimitTracker) RecordSwap(amountSOL float64) {
 
 // GetDailyUsage calculates total usage in the last 24 hours
 func (t *DailyLimitTracker) GetDailyUsage() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.cleanup()
 
 	total := 0.0
@@ -248,11 +446,15 @@ func (t *DailyLimitTracker) cleanup() {
 
 // GetSwapHistory returns recent swaps
 func (t *DailyLimitTracker) GetSwapHistory() []swapRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.cleanup()
 	return t.swaps
 }
 
 // Reset clears all tracked swaps (for testing)
 func (t *DailyLimitTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.swaps = make([]swapRecord, 0)
 }
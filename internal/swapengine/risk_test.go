@@ -0,0 +1,174 @@
+package swapengine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiskManagerScopesPerWallet(t *testing.T) {
+	treasury, test := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+	cfg := DefaultRiskConfig()
+	var err error
+	cfg.WalletLimits, err = parseWalletLimits(test.String() + ":0.2:0.3")
+	require.NoError(t, err)
+	for _, bad := range []string{"bogus:1:1", treasury.String() + ":x:1", treasury.String() + ":1", treasury.String() + ":1:-1"} {
+		_, err := parseWalletLimits(test.String() + ":0.2:0.3," + bad)
+		assert.Error(t, err, bad)
+	}
+	rm := NewRiskManager(cfg)
+
+	swap := func(wallet solana.PublicKey, sol float64) *SwapParams {
+		return &SwapParams{
+			InputMint:   solana.MustPublicKeyFromBase58(TokenMints["SOL"]),
+			OutputMint:  solana.MustPublicKeyFromBase58(TokenMints["USDC"]),
+			AmountIn:    uint64(sol * 1e9),
+			SlippageBps: 100,
+			Wallet:      wallet,
+		}
+	}
+	check := func(p *SwapParams) *RiskCheckResult {
+		res, err := rm.CheckSwap(context.Background(), p, &QuoteResult{AmountIn: p.AmountIn}, 100)
+		require.NoError(t, err)
+		return res
+	}
+
+	res := check(swap(test, 0.5))
+	assert.True(t, res.ExceedsMaxSwapAmount, "test wallet has its own per-swap cap")
+	assert.True(t, check(swap(treasury, 0.5)).Allowed)
+
+	rm.RecordSwap(swap(test, 0.2), 0.2)
+	res = check(swap(test, 0.2))
+	assert.True(t, res.ExceedsDailyLimit, "0.2 + 0.2 over the test wallet's 0.3")
+	assert.InDelta(t, 0.2, rm.DailyUsage(test.String()), 1e-9)
+
+	res = check(swap(treasury, 1))
+	assert.True(t, res.Allowed, "treasury budget is independent")
+	assert.Zero(t, res.DailyUsedSOL)
+}
+
+type fakeFeed map[string][]models.MarketPrice
+
+func (f fakeFeed) GetMarkets(_ context.Context, token string) ([]models.MarketPrice, error) {
+	return f[token], nil
+}
+
+func TestRiskManagerValuesFromPrices(t *testing.T) {
+	now, old := time.Now(), time.Now().Add(-time.Hour)
+	feed := fakeFeed{
+		"SOL":  {{Quote: "USDC", Price: 150, UpdatedAt: now}, {Quote: "USDT", Price: 149, UpdatedAt: now}, {Quote: "USDC", Price: 10, UpdatedAt: old}},
+		"USDT": {{Quote: "USDC", Price: 1, UpdatedAt: now}},
+	}
+	cfg := DefaultRiskConfig()
+	cfg.MaxSwapAmountSOL, cfg.DailyLimitSOL = 1, 10
+	usdc, usdt := solana.MustPublicKeyFromBase58(TokenMints["USDC"]), solana.MustPublicKeyFromBase58(TokenMints["USDT"])
+	params := &SwapParams{InputMint: usdc, OutputMint: usdt, AmountIn: 300_000_000, SlippageBps: 100} // 300 USDC
+	quote := &QuoteResult{AmountIn: params.AmountIn, AmountOut: 299_000_000}
+
+	check := func(rm *RiskManager) *RiskCheckResult {
+		res, err := rm.CheckSwap(context.Background(), params, quote, 100)
+		require.NoError(t, err)
+		return res
+	}
+
+	res := check(NewRiskManager(cfg).WithPrices(feed, nil, 0))
+	assert.Equal(t, ValuationMarket, res.ValuationSource)
+	assert.InDelta(t, 300/149.5, res.SwapValueSOL, 1e-9, "median of the fresh SOL/USD venues")
+	assert.True(t, res.ExceedsMaxSwapAmount, "300 USDC is worth 2 SOL, over the 1 SOL cap")
+
+	feed["SOL"] = feed["SOL"][2:] // only a stale SOL price left
+	params.AmountIn, quote.AmountOut = 100_000_000, 99_000_000
+	res = check(NewRiskManager(cfg).WithPrices(feed, nil, 0))
+	assert.Equal(t, ValuationStaleMarket, res.ValuationSource)
+	assert.InDelta(t, 100/10.0*stalePriceMarkup, res.SwapValueSOL, 1e-9)
+
+	res = check(NewRiskManager(cfg))
+	assert.Equal(t, ValuationUnpriced, res.ValuationSource)
+	assert.False(t, res.Allowed, "unpriced swaps are refused")
+	assert.True(t, res.ExceedsMaxSwapAmount)
+
+	// however large: 1M USDC for USDT without a price feed
+	params.AmountIn, quote.AmountOut = 1_000_000_000_000, 999_000_000_000
+	res = check(NewRiskManager(cfg))
+	assert.False(t, res.Allowed)
+	assert.Contains(t, res.Reason, "cannot value USDC/USDT swap")
+
+	// and when the feed has no price for either leg
+	res = check(NewRiskManager(cfg).WithPrices(fakeFeed{}, nil, 0))
+	assert.False(t, res.Allowed)
+	assert.Equal(t, ValuationUnpriced, res.ValuationSource)
+}
+
+func TestRiskManagerMinBalance(t *testing.T) {
+	feed := fakeFeed{"SOL": {{Quote: "USDC", Price: 150, UpdatedAt: time.Now()}}}
+	cfg := DefaultRiskConfig()
+	cfg.MaxSwapAmountSOL, cfg.DailyLimitSOL, cfg.MinBalanceSOL = 100, 100, 0.05
+	rm := NewRiskManager(cfg).WithPrices(feed, nil, 0)
+	sol, usdc, usdt := solana.MustPublicKeyFromBase58(TokenMints["SOL"]),
+		solana.MustPublicKeyFromBase58(TokenMints["USDC"]), solana.MustPublicKeyFromBase58(TokenMints["USDT"])
+
+	check := func(params *SwapParams, balanceSOL float64) *RiskCheckResult {
+		res, err := rm.CheckSwap(context.Background(), params, &QuoteResult{AmountIn: params.AmountIn, AmountOut: params.AmountIn}, balanceSOL)
+		require.NoError(t, err)
+		return res
+	}
+
+	// 7500 USDC is worth 50 SOL, but spends none of the wallet's 0.1 SOL
+	res := check(&SwapParams{InputMint: usdc, OutputMint: usdt, AmountIn: 7_500_000_000, SlippageBps: 100}, 0.1)
+	assert.InDelta(t, 50, res.SwapValueSOL, 1e-9)
+	assert.True(t, res.Allowed, res.Reason)
+
+	res = check(&SwapParams{InputMint: usdc, OutputMint: usdt, AmountIn: 7_500_000_000, SlippageBps: 100}, 0.01)
+	assert.False(t, res.Allowed, "fees still need the minimum balance")
+	assert.Contains(t, res.Reason, "insufficient balance")
+
+	res = check(&SwapParams{InputMint: sol, OutputMint: usdc, AmountIn: 80_000_000, SlippageBps: 100}, 0.1)
+	assert.False(t, res.Allowed, "0.08 SOL in leaves 0.02 SOL")
+	assert.Contains(t, res.Reason, "insufficient balance")
+}
+
+func TestRiskManagerFailureCooldown(t *testing.T) {
+	cfg := DefaultRiskConfig()
+	cfg.FailureCooldown = time.Minute
+	bypass := false
+	rm := NewRiskManager(cfg).WithCooldownBypass(func() bool { return bypass })
+
+	sol, usdc := solana.MustPublicKeyFromBase58(TokenMints["SOL"]), solana.MustPublicKeyFromBase58(TokenMints["USDC"])
+	alice, bob := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+	sell := &SwapParams{Wallet: alice, InputMint: sol, OutputMint: usdc, AmountIn: 1e8, SlippageBps: 100}
+	buy := &SwapParams{Wallet: alice, InputMint: usdc, OutputMint: sol, AmountIn: 1e6, SlippageBps: 100}
+	other := &SwapParams{Wallet: bob, InputMint: sol, OutputMint: usdc, AmountIn: 1e8, SlippageBps: 100}
+	check := func(p *SwapParams) *RiskCheckResult {
+		res, err := rm.CheckSwap(context.Background(), p, &QuoteResult{AmountIn: p.AmountIn, AmountOut: 1e6}, 100)
+		require.NoError(t, err)
+		return res
+	}
+
+	require.True(t, check(sell).Allowed)
+	rm.RecordFailure(sell, ErrorKindBlockhashExpired)
+	assert.Empty(t, rm.Cooldowns(alice.String()), "transient failures start no cooldown")
+	rm.RecordFailure(sell, ErrorKindSlippage)
+
+	res := check(buy)
+	assert.False(t, res.Allowed, "either direction of the pair")
+	assert.WithinDuration(t, time.Now().Add(time.Minute), res.CooldownUntil, time.Second)
+	assert.Contains(t, res.Reason, "USDC/SOL is cooling down")
+	assert.Len(t, rm.Cooldowns(alice.String()), 1)
+	assert.True(t, check(other).Allowed, "other wallets keep trading the pair")
+	assert.Empty(t, rm.Cooldowns(bob.String()))
+
+	bypass = true
+	assert.True(t, check(sell).Allowed, "operator bypass")
+
+	bypass = false
+	rm.mu.Lock()
+	rm.cooldowns[newCooldownKey(sell)] = time.Now().Add(-time.Second)
+	rm.mu.Unlock()
+	assert.True(t, check(sell).Allowed, "cooldown over")
+	assert.Empty(t, rm.Cooldowns(alice.String()))
+}
@@ -0,0 +1,262 @@
+package swapengine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/apperr"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
+)
+
+// ErrRiskOverride is matched by every *RiskOverrideError
+var ErrRiskOverride = errors.New("invalid risk override")
+
+// RiskOverrideError rejects one field of a RiskOverrides
+type RiskOverrideError struct {
+	Field   string // JSON name of the field
+	Message string
+}
+
+func (e *RiskOverrideError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrRiskOverride, e.Field, e.Message)
+}
+
+// Is reports whether target is ErrRiskOverride or apperr.ValidationFailed
+func (e *RiskOverrideError) Is(target error) bool {
+	return target == ErrRiskOverride || target == apperr.ValidationFailed
+}
+
+// RiskOverrides tighten the configured risk limits (SWAPENGINE_*) at runtime.
+// They are stored as the engine.risk flag, so every replica applies the same
+// limits and each change is recorded in the flag history. Unset fields keep
+// the configured value.
+type RiskOverrides struct {
+	MaxSwapAmountSOL   *float64  `json:"max_swap_amount_sol,omitempty"`
+	DailyLimitSOL      *float64  `json:"daily_limit_sol,omitempty"`
+	MaxPriceImpactBps  *uint16   `json:"max_price_impact_bps,omitempty"`
+	DefaultSlippageBps *uint16   `json:"default_slippage_bps,omitempty"`
+	MaxSlippageBps     *uint16   `json:"max_slippage_bps,omitempty"`
+	AllowedTokens      *[]string `json:"allowed_tokens,omitempty"` // must be configured tokens when a whitelist is configured
+	MinBalanceSOL      *float64  `json:"min_balance_sol,omitempty"`
+	MaxRouteHops       *int      `json:"max_route_hops,omitempty"`
+	ExcludedDexes      []string  `json:"excluded_dexes,omitempty"` // excluded on top of SWAPENGINE_EXCLUDED_DEXES
+}
+
+// normalize upper-cases token symbols and drops blank entries
+func (o *RiskOverrides) normalize() {
+	if o.AllowedTokens != nil {
+		tokens := []string{}
+		for _, t := range *o.AllowedTokens {
+			if t = strings.ToUpper(strings.TrimSpace(t)); t != "" && !slices.Contains(tokens, t) {
+				tokens = append(tokens, t)
+			}
+		}
+		o.AllowedTokens = &tokens
+	}
+	var dexes []string
+	for _, d := range o.ExcludedDexes {
+		if d = strings.TrimSpace(d); d != "" {
+			dexes = append(dexes, d)
+		}
+	}
+	o.ExcludedDexes = dexes
+}
+
+// Validate rejects overrides that are out of range or would loosen base,
+// with one *RiskOverrideError per field. Loosening a limit takes a config
+// change and a reload.
+func (o RiskOverrides) Validate(base RiskConfig) error {
+	var errs []error
+	reject := func(field, format string, args ...any) {
+		errs = append(errs, &RiskOverrideError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	if v := o.MaxSwapAmountSOL; v != nil && (*v <= 0 || *v > base.MaxSwapAmountSOL) {
+		reject("max_swap_amount_sol", "must be above 0 and at most the configured %g", base.MaxSwapAmountSOL)
+	}
+	if v := o.DailyLimitSOL; v != nil && (*v <= 0 || *v > base.DailyLimitSOL) {
+		reject("daily_limit_sol", "must be above 0 and at most the configured %g", base.DailyLimitSOL)
+	}
+	if v := o.MaxPriceImpactBps; v != nil && *v > base.MaxPriceImpactBps {
+		reject("max_price_impact_bps", "must be at most the configured %d", base.MaxPriceImpactBps)
+	}
+	if v := o.MaxSlippageBps; v != nil && *v > base.MaxSlippageBps {
+		reject("max_slippage_bps", "must be at most the configured %d", base.MaxSlippageBps)
+	}
+	if v := o.DefaultSlippageBps; v != nil {
+		if maxBps := o.Apply(base).MaxSlippageBps; *v > maxBps {
+			reject("default_slippage_bps", "must be at most max_slippage_bps %d", maxBps)
+		}
+	}
+	if v := o.MinBalanceSOL; v != nil && *v < base.MinBalanceSOL {
+		reject("min_balance_sol", "must be at least the configured %g", base.MinBalanceSOL)
+	}
+	if v := o.MaxRouteHops; v != nil && (*v < 1 || (base.MaxRouteHops > 0 && *v > base.MaxRouteHops)) {
+		reject("max_route_hops", "must be between 1 and the configured %d", base.MaxRouteHops)
+	}
+	if v := o.AllowedTokens; v != nil {
+		if len(*v) == 0 {
+			reject("allowed_tokens", "must not be empty")
+		}
+		for _, t := range *v {
+			if !isTokenAllowed(base, t) {
+				reject("allowed_tokens", "%s is not in the configured whitelist %v", t, base.AllowedTokens)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Apply returns base tightened by the overrides. Limits only ever get
+// stricter: an override looser than base (e.g. after base was tightened by a
+// config reload) leaves the base value in force.
+func (o RiskOverrides) Apply(base RiskConfig) RiskConfig {
+	rc := base
+	rc.AllowedTokens = slices.Clone(base.AllowedTokens)
+	rc.ExcludedDexes = slices.Clone(base.ExcludedDexes)
+	rc.WalletLimits = maps.Clone(base.WalletLimits)
+
+	if v := o.MaxSwapAmountSOL; v != nil && *v > 0 {
+		rc.MaxSwapAmountSOL = min(rc.MaxSwapAmountSOL, *v)
+	}
+	if v := o.DailyLimitSOL; v != nil && *v > 0 {
+		rc.DailyLimitSOL = min(rc.DailyLimitSOL, *v)
+	}
+	for addr, l := range rc.WalletLimits {
+		if v := o.MaxSwapAmountSOL; v != nil && *v > 0 && l.MaxSwapAmountSOL > *v {
+			l.MaxSwapAmountSOL = *v
+		}
+		if v := o.DailyLimitSOL; v != nil && *v > 0 && l.DailyLimitSOL > *v {
+			l.DailyLimitSOL = *v
+		}
+		rc.WalletLimits[addr] = l
+	}
+	if v := o.MaxPriceImpactBps; v != nil {
+		rc.MaxPriceImpactBps = min(rc.MaxPriceImpactBps, *v)
+	}
+	if v := o.MaxSlippageBps; v != nil {
+		rc.MaxSlippageBps = min(rc.MaxSlippageBps, *v)
+	}
+	if v := o.DefaultSlippageBps; v != nil {
+		rc.DefaultSlippageBps = *v
+	}
+	rc.DefaultSlippageBps = min(rc.DefaultSlippageBps, rc.MaxSlippageBps)
+	if v := o.MinBalanceSOL; v != nil {
+		rc.MinBalanceSOL = max(rc.MinBalanceSOL, *v)
+	}
+	if v := o.MaxRouteHops; v != nil && *v >= 1 {
+		if rc.MaxRouteHops == 0 || *v < rc.MaxRouteHops {
+			rc.MaxRouteHops = *v
+		}
+	}
+	if v := o.AllowedTokens; v != nil && len(*v) > 0 {
+		var tokens []string
+		for _, t := range *v {
+			if isTokenAllowed(base, t) {
+				tokens = append(tokens, t)
+			}
+		}
+		if len(tokens) > 0 { // an empty whitelist would allow every token
+			rc.AllowedTokens = tokens
+		}
+	}
+	for _, d := range o.ExcludedDexes {
+		if excludedDex(rc, []string{d}) == "" {
+			rc.ExcludedDexes = append(rc.ExcludedDexes, d)
+		}
+	}
+	return rc
+}
+
+// RiskState describes the risk limits in force and where they come from
+type RiskState struct {
+	Config    RiskConfig    // limits in force: Base tightened by Overrides
+	Base      RiskConfig    // configured limits (SWAPENGINE_*)
+	Overrides RiskOverrides // runtime overrides (engine.risk flag)
+	UpdatedAt time.Time     // when the overrides last changed; zero if never
+	FlagError string        // why the stored engine.risk flag could not be applied
+}
+
+// RiskState returns the risk limits in force with their configured base and overrides
+func (e *Engine) RiskState() RiskState {
+	e.riskMu.Lock()
+	defer e.riskMu.Unlock()
+	return RiskState{
+		Config:    e.riskManager.Config(),
+		Base:      e.baseRisk,
+		Overrides: e.riskOverrides,
+		UpdatedAt: e.riskUpdatedAt,
+		FlagError: e.riskFlagErr,
+	}
+}
+
+// SetRiskOverrides validates o, stores it as the engine.risk flag (attributed
+// to the flags.Actor in ctx) and applies it. Without Redis it only applies to
+// this process until restart.
+func (e *Engine) SetRiskOverrides(ctx context.Context, o RiskOverrides) (RiskState, error) {
+	o.normalize()
+	e.riskMu.Lock()
+	base := e.baseRisk
+	e.riskMu.Unlock()
+	if err := o.Validate(base); err != nil {
+		return RiskState{}, err
+	}
+
+	updatedAt := time.Now().UTC()
+	if e.flagStore != nil {
+		raw, err := json.Marshal(o)
+		if err != nil {
+			return RiskState{}, fmt.Errorf("failed to encode risk overrides: %w", err)
+		}
+		f, err := e.flagStore.Set(ctx, flags.KeyEngineRisk, flags.TypeJSON, raw)
+		if err != nil {
+			return RiskState{}, fmt.Errorf("failed to store risk overrides: %w", err)
+		}
+		updatedAt = f.UpdatedAt
+	}
+
+	e.riskMu.Lock()
+	e.riskOverrides, e.riskUpdatedAt, e.riskFlagErr = o, updatedAt, ""
+	e.applyRiskLocked()
+	e.riskMu.Unlock()
+	return e.RiskState(), nil
+}
+
+// applyRiskFlag applies the engine.risk flag as last seen by the watcher; a
+// missing flag clears the overrides, one that does not decode keeps the
+// limits in force
+func (e *Engine) applyRiskFlag() {
+	var o RiskOverrides
+	var updatedAt time.Time
+	f, ok := e.flags.Get(flags.KeyEngineRisk)
+	if ok {
+		if err := f.Decode(&o); err != nil {
+			e.logger.WithError(err).Warn("ignoring invalid " + flags.KeyEngineRisk + " flag, keeping current risk limits")
+			e.riskMu.Lock()
+			e.riskFlagErr = fmt.Sprintf("%s: %v", flags.KeyEngineRisk, err)
+			e.riskMu.Unlock()
+			return
+		}
+		o.normalize()
+		updatedAt = f.UpdatedAt
+	}
+
+	e.riskMu.Lock()
+	defer e.riskMu.Unlock()
+	e.riskOverrides, e.riskUpdatedAt, e.riskFlagErr = o, updatedAt, ""
+	e.applyRiskLocked()
+}
+
+// applyRiskLocked puts baseRisk tightened by riskOverrides in force on both
+// the risk manager and the decision engine; riskMu must be held, so
+// concurrent updates cannot interleave
+func (e *Engine) applyRiskLocked() {
+	rc := e.riskOverrides.Apply(e.baseRisk)
+	e.riskManager.SetConfig(rc)
+	e.decisionEngine.SetRiskConfig(rc)
+}
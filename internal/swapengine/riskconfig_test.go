@@ -0,0 +1,167 @@
+package swapengine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiskOverridesApply(t *testing.T) {
+	base := DefaultRiskConfig()
+	base.ExcludedDexes = []string{"Phoenix"}
+	amount, hops, slippage, minBal := 0.5, 2, uint16(50), 0.2
+	tokens := []string{"SOL", "USDC"}
+	o := RiskOverrides{
+		MaxSwapAmountSOL: &amount,
+		MaxRouteHops:     &hops,
+		MaxSlippageBps:   &slippage,
+		MinBalanceSOL:    &minBal,
+		AllowedTokens:    &tokens,
+		ExcludedDexes:    []string{"phoenix", "Lifinity"},
+	}
+	require.NoError(t, o.Validate(base))
+
+	rc := o.Apply(base)
+	assert.Equal(t, 0.5, rc.MaxSwapAmountSOL)
+	assert.Equal(t, base.DailyLimitSOL, rc.DailyLimitSOL)
+	assert.Equal(t, 2, rc.MaxRouteHops)
+	assert.Equal(t, uint16(50), rc.MaxSlippageBps)
+	assert.Equal(t, uint16(50), rc.DefaultSlippageBps, "default clamped to the tighter max")
+	assert.Equal(t, 0.2, rc.MinBalanceSOL)
+	assert.Equal(t, []string{"SOL", "USDC"}, rc.AllowedTokens)
+	assert.Equal(t, []string{"Phoenix", "Lifinity"}, rc.ExcludedDexes)
+	assert.Equal(t, []string{"Phoenix"}, base.ExcludedDexes, "base untouched")
+
+	// A reload that tightens base below the overrides keeps the base values
+	base.MaxSwapAmountSOL, base.AllowedTokens = 0.25, []string{"USDT"}
+	rc = o.Apply(base)
+	assert.Equal(t, 0.25, rc.MaxSwapAmountSOL)
+	assert.Equal(t, []string{"USDT"}, rc.AllowedTokens)
+}
+
+func TestRiskOverridesValidate(t *testing.T) {
+	base := DefaultRiskConfig()
+	amount, hops, impact, slippage := 2.0, 0, uint16(600), uint16(1000)
+	tokens := []string{"SOL", "BONK"}
+	o := RiskOverrides{
+		MaxSwapAmountSOL:   &amount,
+		MaxRouteHops:       &hops,
+		MaxPriceImpactBps:  &impact,
+		DefaultSlippageBps: &slippage,
+		AllowedTokens:      &tokens,
+	}
+	err := o.Validate(base)
+	require.ErrorIs(t, err, ErrRiskOverride)
+
+	var fields []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var re *RiskOverrideError
+		require.True(t, errors.As(e, &re))
+		fields = append(fields, re.Field)
+	}
+	assert.Equal(t, []string{"max_swap_amount_sol", "max_price_impact_bps", "max_route_hops", "allowed_tokens"}, fields)
+
+	tighter := uint16(200)
+	o = RiskOverrides{MaxSlippageBps: &tighter, DefaultSlippageBps: &slippage}
+	assert.ErrorContains(t, o.Validate(base), "default_slippage_bps must be at most max_slippage_bps 200")
+}
+
+func TestReloadHookTightensLimits(t *testing.T) {
+	rc := DefaultRiskConfig()
+	rc.MaxSwapAmountSOL, rc.DailyLimitSOL = 1, 10
+	e := &Engine{baseRisk: rc, riskManager: NewRiskManager(rc), decisionEngine: NewDecisionEngine(rc)}
+	hook := ReloadHook(e, nil)
+
+	params := &SwapParams{
+		InputMint:   solana.MustPublicKeyFromBase58(TokenMints["SOL"]),
+		OutputMint:  solana.MustPublicKeyFromBase58(TokenMints["USDC"]),
+		AmountIn:    500_000_000, // 0.5 SOL
+		SlippageBps: 100,
+	}
+	check := func() *RiskCheckResult {
+		res, err := e.riskManager.CheckSwap(context.Background(), params, &QuoteResult{AmountIn: params.AmountIn}, 100)
+		require.NoError(t, err)
+		return res
+	}
+	require.True(t, check().Allowed)
+
+	t.Setenv("SWAPENGINE_MAX_SWAP_AMOUNT_SOL", "0.25")
+	hook(&config.Config{}, &config.Config{})
+
+	res := check()
+	assert.False(t, res.Allowed, "the reloaded per-swap cap applies to the next check")
+	assert.True(t, res.ExceedsMaxSwapAmount)
+	assert.Equal(t, 0.25, e.riskManager.Config().MaxSwapAmountSOL)
+	assert.Equal(t, 10.0, e.riskManager.Config().DailyLimitSOL, "settings left unset keep their values")
+}
+
+func TestApplyRiskEnvRejectsBadValues(t *testing.T) {
+	for key, v := range map[string]string{
+		"SWAPENGINE_DAILY_LIMIT_SOL":      "5SOL",
+		"SWAPENGINE_MAX_SWAP_AMOUNT_SOL":  "-1",
+		"SWAPENGINE_MAX_PRICE_IMPACT_BPS": "100000",
+		"SWAPENGINE_MAX_ROUTE_HOPS":       "two",
+		"SWAPENGINE_FAILURE_COOLDOWN":     "-5m",
+		"SWAPENGINE_REQUIRE_SIMULATION":   "ture",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, v)
+			rc := DefaultRiskConfig()
+			err := applyRiskEnv(&rc)
+			require.Error(t, err)
+			assert.ErrorContains(t, err, key)
+			assert.ErrorContains(t, err, v)
+		})
+	}
+}
+
+func TestApplyComputeBudgetEnvRejectsBadValues(t *testing.T) {
+	for key, v := range map[string]string{
+		"SWAPENGINE_COMPUTE_BUDGET":        "yes please",
+		"SWAPENGINE_CU_MARGIN":             "-0.1",
+		"SWAPENGINE_PRIORITY_FEE_LAMPORTS": "1e5",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, v)
+			cb := DefaultEngineConfig().ComputeBudget
+			err := applyComputeBudgetEnv(&cb)
+			require.Error(t, err)
+			assert.ErrorContains(t, err, key)
+		})
+	}
+}
+
+func TestReloadRiskConfigKeepsLimitsOnBadValues(t *testing.T) {
+	rc := DefaultRiskConfig()
+	rc.DailyLimitSOL = 10
+	e := &Engine{baseRisk: rc, riskManager: NewRiskManager(rc), decisionEngine: NewDecisionEngine(rc)}
+
+	t.Setenv("SWAPENGINE_MAX_SWAP_AMOUNT_SOL", "0.25")
+	t.Setenv("SWAPENGINE_DAILY_LIMIT_SOL", "5SOL")
+	got, err := e.ReloadRiskConfig()
+	require.Error(t, err)
+	assert.Equal(t, 10.0, got.DailyLimitSOL)
+	assert.Equal(t, rc.MaxSwapAmountSOL, e.riskManager.Config().MaxSwapAmountSOL, "nothing applied")
+}
+
+func TestReloadRiskConfigRestoresDefaults(t *testing.T) {
+	rc := DefaultRiskConfig()
+	e := &Engine{baseRisk: rc, riskManager: NewRiskManager(rc), decisionEngine: NewDecisionEngine(rc)}
+
+	t.Setenv("SWAPENGINE_DAILY_LIMIT_SOL", "5")
+	got, err := e.ReloadRiskConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, got.DailyLimitSOL)
+
+	// the setting was removed from the config file
+	require.NoError(t, os.Unsetenv("SWAPENGINE_DAILY_LIMIT_SOL"))
+	got, err = e.ReloadRiskConfig()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRiskConfig().DailyLimitSOL, got.DailyLimitSOL)
+}
@@ -9,8 +9,8 @@ import (
 // SwapIntent represents the AI agent's trading intention
 type SwapIntent struct {
 	// Core swap parameters
-	InputToken  string  // Token symbol (e.g., "SOL", "USDC")
-	OutputToken string  // Token symbol
+	InputToken  string  // Token symbol (e.g., "SOL", "USDC") or mint address
+	OutputToken string  // Token symbol or mint address
 	Amount      float64 // Amount in human-readable units (e.g., 1.5 SOL)
 
 	// Optional parameters (AI can specify or use defaults)
@@ -36,6 +36,9 @@ type SwapParams struct {
 	// Pool selection
 	PoolName string
 
+	// Wallet that signs the swap; risk limits and daily usage are scoped to it
+	Wallet solana.PublicKey
+
 	// Risk parameters
 	SlippageBps       uint16
 	MaxPriceImpactBps uint16
@@ -97,6 +100,10 @@ type QuoteResult struct {
 	ReserveOut    uint64
 	ExecutionRate float64 // Output per input
 	QuotedAt      time.Time
+
+	// Route (Jupiter quotes may span several DEXes and hops)
+	Dexes []string // venues used, e.g. ["Orca"]
+	Hops  int      // sequential swaps; split legs of one hop count once
 }
 
 // SwapResult is the final result returned to the caller
@@ -105,6 +112,8 @@ type SwapResult struct {
 	Signature   string
 	Success     bool
 	Error       string
+	ErrorKind   ErrorKind // why simulation, sending or confirmation failed; "" otherwise
+	ErrorCode   int64     // with ErrorKind: the failing program's custom error code, -1 if none
 
 	// Quote vs actual
 	ExpectedOut uint64
@@ -118,6 +127,16 @@ type SwapResult struct {
 	// Details
 	Quote     *QuoteResult
 	Execution *SwapExecution
+	Compute   *ComputeUsage // nil when the swap failed before simulation
+}
+
+// ComputeUsage is the compute a swap was simulated at, budgeted and used
+type ComputeUsage struct {
+	SimulatedUnits     uint64 // consumed by the simulation
+	Limit              uint32 // SetComputeUnitLimit requested; 0 = runtime default
+	PriceMicroLamports uint64 // SetComputeUnitPrice bid; 0 = no priority fee
+	ActualUnits        uint64 // consumed on chain; 0 if the transaction did not land
+	FeeLamports        uint64 // total fee paid on chain
 }
 
 // RiskCheckResult contains risk validation outcome
@@ -128,6 +147,8 @@ type RiskCheckResult struct {
 	// Per-transaction limits
 	ExceedsMaxSwapAmount bool
 	MaxSwapAmountSOL     float64
+	SwapValueSOL         float64 // value the limits were checked against
+	ValuationSource      string  // how it was valued: ValuationSOL, ValuationMarket, ...
 
 	// Daily limits
 	ExceedsDailyLimit bool
@@ -143,9 +164,18 @@ type RiskCheckResult struct {
 	PriceImpactTooHigh bool
 	MaxPriceImpactBps  uint16
 	ActualPriceImpact  float64
+
+	// Post-failure cooldown
+	CooldownUntil time.Time // set when the pair is cooling down after a failed execution
+
+	// Route constraints
+	RouteTooLong bool
+	MaxRouteHops int
+	ExcludedDex  string // first excluded DEX found in the route
 }
 
-// TokenDecimals maps token symbols to their decimal places
+// TokenDecimals maps token symbols to their decimal places; used when the
+// decision engine has no tokens.Resolver
 var TokenDecimals = map[string]uint8{
 	"SOL":  9,
 	"USDC": 6,
@@ -161,3 +191,15 @@ var TokenMints = map[string]string{
 	"USDT": "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB",
 	// Add more as needed
 }
+
+// MintFor returns the mint address of token: a symbol in TokenMints, or a
+// mint address as given
+func MintFor(token string) (string, bool) {
+	if mint, ok := TokenMints[token]; ok {
+		return mint, true
+	}
+	if _, err := solana.PublicKeyFromBase58(token); err == nil {
+		return token, true
+	}
+	return "", false
+}
@@ -0,0 +1,180 @@
+package swapengine
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/tokens"
+	"github.com/gagliardetto/solana-go"
+)
+
+// PriceFeed returns the indexed per-venue prices of a token, keyed by the
+// label the indexer gives its swaps (implemented by *cache.RedisCache)
+type PriceFeed interface {
+	GetMarkets(ctx context.Context, token string) ([]models.MarketPrice, error)
+}
+
+// usdQuotes are the stablecoins a token is valued in when it has no SOL market
+var usdQuotes = []string{"USDC", "USDT"}
+
+// stalePriceMarkup inflates values derived from prices older than the stale
+// threshold, so a lagging feed errs towards the limits
+const stalePriceMarkup = 1.5
+
+// Valuation sources reported in RiskCheckResult.ValuationSource
+const (
+	ValuationSOL         = "sol"          // one leg is SOL
+	ValuationMarket      = "market"       // indexed prices
+	ValuationStaleMarket = "stale_market" // indexed prices older than the stale threshold, marked up
+	ValuationUnpriced    = "unpriced"     // no price: the swap is refused
+)
+
+// Valuation is the SOL value a risk check assigns to a swap
+type Valuation struct {
+	SOL    float64
+	Source string
+}
+
+// WithPrices values swaps without a SOL leg from feed instead of refusing
+// them. Decimals of mints outside TokenDecimals come from
+// decimals (optional); prices older than staleAfter (default
+// constants.PriceStaleAfter) are marked up.
+func (rm *RiskManager) WithPrices(feed PriceFeed, decimals *tokens.Resolver, staleAfter time.Duration) *RiskManager {
+	if staleAfter <= 0 {
+		staleAfter = constants.PriceStaleAfter
+	}
+	rm.prices, rm.decimals, rm.staleAfter = feed, decimals, staleAfter
+	return rm
+}
+
+// estimateSwapValueSOL values a swap in SOL: exactly when either leg is SOL,
+// otherwise from indexed prices of whichever leg values higher. Without a
+// price it is ValuationUnpriced and worth 0; CheckSwap refuses it, since its
+// size cannot be held to the limits.
+func (rm *RiskManager) estimateSwapValueSOL(ctx context.Context, params *SwapParams, quote *QuoteResult) Valuation {
+	// If input is SOL, use that directly
+	if params.InputMint.String() == TokenMints["SOL"] {
+		return Valuation{SOL: float64(params.AmountIn) / math.Pow10(int(TokenDecimals["SOL"])), Source: ValuationSOL}
+	}
+
+	// If output is SOL, use that
+	if params.OutputMint.String() == TokenMints["SOL"] {
+		return Valuation{SOL: float64(quote.AmountOut) / math.Pow10(int(TokenDecimals["SOL"])), Source: ValuationSOL}
+	}
+
+	best := Valuation{Source: ValuationUnpriced}
+	for _, leg := range []struct {
+		mint   solana.PublicKey
+		amount uint64
+	}{
+		{params.InputMint, params.AmountIn},
+		{params.OutputMint, quote.AmountOut},
+	} {
+		v, ok := rm.legValueSOL(ctx, leg.mint, leg.amount)
+		if ok && (best.Source == ValuationUnpriced || v.SOL > best.SOL) {
+			best = v
+		}
+	}
+	return best
+}
+
+// legValueSOL values amount (raw units) of mint in SOL
+func (rm *RiskManager) legValueSOL(ctx context.Context, mint solana.PublicKey, amount uint64) (Valuation, bool) {
+	if rm.prices == nil || amount == 0 {
+		return Valuation{}, false
+	}
+	decimals, ok := rm.mintDecimals(ctx, mint)
+	if !ok {
+		return Valuation{}, false
+	}
+	human := float64(amount) / math.Pow10(int(decimals))
+	label := marketLabel(mint)
+
+	var sol float64
+	var stale bool
+	if p, st, ok := rm.marketPrice(ctx, label, []string{"SOL"}); ok {
+		sol, stale = human*p, st
+	} else {
+		usd, st := human, false
+		if !slices.Contains(usdQuotes, label) {
+			p, pst, ok := rm.marketPrice(ctx, label, usdQuotes)
+			if !ok {
+				return Valuation{}, false
+			}
+			usd, st = human*p, pst
+		}
+		solUSD, sst, ok := rm.marketPrice(ctx, "SOL", usdQuotes)
+		if !ok || solUSD <= 0 {
+			return Valuation{}, false
+		}
+		sol, stale = usd/solUSD, st || sst
+	}
+	if stale {
+		return Valuation{SOL: sol * stalePriceMarkup, Source: ValuationStaleMarket}, true
+	}
+	return Valuation{SOL: sol, Source: ValuationMarket}, true
+}
+
+// marketPrice is the median price of token across venues quoting it in one
+// of quotes. Venues updated within staleAfter are preferred; it reports
+// whether only older ones were left.
+func (rm *RiskManager) marketPrice(ctx context.Context, token string, quotes []string) (price float64, stale, ok bool) {
+	markets, err := rm.prices.GetMarkets(ctx, token)
+	if err != nil {
+		return 0, false, false
+	}
+	cutoff := time.Now().Add(-rm.staleAfter)
+	var fresh, old []float64
+	for _, m := range markets {
+		if m.Price <= 0 || !slices.ContainsFunc(quotes, func(q string) bool { return strings.EqualFold(q, m.Quote) }) {
+			continue
+		}
+		if m.UpdatedAt.Before(cutoff) {
+			old = append(old, m.Price)
+		} else {
+			fresh = append(fresh, m.Price)
+		}
+	}
+	if len(fresh) > 0 {
+		return median(fresh), false, true
+	}
+	if len(old) > 0 {
+		return median(old), true, true
+	}
+	return 0, false, false
+}
+
+// mintDecimals returns the decimals of mint from TokenDecimals or the resolver
+func (rm *RiskManager) mintDecimals(ctx context.Context, mint solana.PublicKey) (uint8, bool) {
+	if d, ok := TokenDecimals[rm.getTokenSymbol(mint)]; ok {
+		return d, true
+	}
+	if rm.decimals == nil {
+		return 0, false
+	}
+	d, err := rm.decimals.Decimals(ctx, mint.String())
+	return d, err == nil
+}
+
+// marketLabel is the token label the indexer records markets of mint under
+// before a registry lookup names it
+func marketLabel(mint solana.PublicKey) string {
+	if symbol, ok := constants.TokenSymbols[mint.String()]; ok {
+		return symbol
+	}
+	return tokens.ShortLabel(mint.String())
+}
+
+func median(xs []float64) float64 {
+	slices.Sort(xs)
+	n := len(xs)
+	if n%2 == 1 {
+		return xs[n/2]
+	}
+	return (xs[n/2-1] + xs[n/2]) / 2
+}
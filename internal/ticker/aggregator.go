@@ -0,0 +1,150 @@
+// Package ticker condenses the live swap feed into one small message per
+// pair every few seconds: the last price and the volume and trade count of
+// the last minute. Price widgets subscribe to swaps:ticker:<pair> instead of
+// processing every swap.
+package ticker
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Publisher receives each round of tickers; *cache.RedisCache implements it
+type Publisher interface {
+	PublishTickers(ctx context.Context, tickers []*models.Ticker) error
+}
+
+// Config tunes an Aggregator
+type Config struct {
+	Interval  time.Duration // between publishes (default constants.TickerInterval)
+	Window    time.Duration // volume and trade-count window (default constants.TickerWindow)
+	Publisher Publisher
+	Logger    *logrus.Logger
+}
+
+// trade is one swap in a pair's window
+type trade struct {
+	at     time.Time
+	volume float64
+}
+
+// pairState is the last price and recent trades of one pair, oldest first
+type pairState struct {
+	price  float64
+	trades []trade
+}
+
+// Aggregator keeps the recent trades of every pair in memory
+type Aggregator struct {
+	cfg Config
+	now func() time.Time
+
+	mu    sync.Mutex
+	pairs map[string]*pairState
+}
+
+// NewAggregator creates an aggregator, filling in defaults
+func NewAggregator(cfg Config) *Aggregator {
+	if cfg.Interval <= 0 {
+		cfg.Interval = constants.TickerInterval
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = constants.TickerWindow
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	return &Aggregator{cfg: cfg, now: time.Now, pairs: make(map[string]*pairState)}
+}
+
+// Observe records a swap. Both directions of a pair count towards the same
+// ticker. Swaps are timed on arrival, so a backlog replayed at once lands in
+// the current window.
+func (a *Aggregator) Observe(swap *models.SwapEvent) {
+	pair, price, volume, ok := canonical(swap)
+	if !ok {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := a.pairs[pair]
+	if s == nil {
+		s = &pairState{}
+		a.pairs[pair] = s
+	}
+	s.price = price
+	s.trades = append(s.trades, trade{at: a.now(), volume: volume})
+}
+
+// Run publishes tickers every Interval until ctx is cancelled
+func (a *Aggregator) Run(ctx context.Context) {
+	t := time.NewTicker(a.cfg.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			a.Publish(ctx)
+		}
+	}
+}
+
+// Publish sends one ticker per pair that traded within the window. A pair
+// whose window empties gets a last ticker with zero volume and is then
+// forgotten until it trades again.
+func (a *Aggregator) Publish(ctx context.Context) {
+	tickers := a.snapshot()
+	if len(tickers) == 0 || a.cfg.Publisher == nil {
+		return
+	}
+	if err := a.cfg.Publisher.PublishTickers(ctx, tickers); err != nil && ctx.Err() == nil {
+		a.cfg.Logger.WithError(err).Warn("failed to publish tickers")
+		return
+	}
+	tickersPublished.With().Add(float64(len(tickers)))
+}
+
+// snapshot drops trades older than the window and builds the tickers, sorted by pair
+func (a *Aggregator) snapshot() []*models.Ticker {
+	now := a.now()
+	cutoff := now.Add(-a.cfg.Window)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]*models.Ticker, 0, len(a.pairs))
+	for pair, s := range a.pairs {
+		i := sort.Search(len(s.trades), func(i int) bool { return s.trades[i].at.After(cutoff) })
+		s.trades = append(s.trades[:0], s.trades[i:]...)
+
+		t := &models.Ticker{Pair: pair, Price: s.price, Trades: len(s.trades), Timestamp: now.UTC()}
+		for _, tr := range s.trades {
+			t.Volume += tr.volume
+		}
+		if len(s.trades) == 0 {
+			delete(a.pairs, pair)
+		}
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Pair < out[j].Pair })
+	return out
+}
+
+// canonical orders a swap's tokens alphabetically and returns the pair, the
+// price in quote per base and the base amount traded
+func canonical(swap *models.SwapEvent) (pair string, price, volume float64, ok bool) {
+	in, out := swap.TokenIn, swap.TokenOut
+	if in == "" || out == "" || in == out || swap.AmountIn <= 0 || swap.AmountOut <= 0 {
+		return "", 0, 0, false
+	}
+	if in < out {
+		return in + "/" + out, swap.AmountOut / swap.AmountIn, swap.AmountIn, true
+	}
+	return out + "/" + in, swap.AmountIn / swap.AmountOut, swap.AmountOut, true
+}
@@ -0,0 +1,60 @@
+package ticker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePublisher struct {
+	rounds [][]*models.Ticker
+}
+
+func (f *fakePublisher) PublishTickers(_ context.Context, tickers []*models.Ticker) error {
+	f.rounds = append(f.rounds, tickers)
+	return nil
+}
+
+func TestAggregator(t *testing.T) {
+	pub := &fakePublisher{}
+	a := NewAggregator(Config{Publisher: pub})
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	a.Observe(&models.SwapEvent{TokenIn: "SOL", TokenOut: "USDC", AmountIn: 2, AmountOut: 300})
+	now = now.Add(30 * time.Second)
+	// the other direction counts towards the same pair, in SOL
+	a.Observe(&models.SwapEvent{TokenIn: "USDC", TokenOut: "SOL", AmountIn: 152, AmountOut: 1})
+	a.Observe(&models.SwapEvent{TokenIn: "BONK", TokenOut: "SOL", AmountIn: 1e6, AmountOut: 0.1})
+	a.Observe(&models.SwapEvent{TokenIn: "SOL", TokenOut: "SOL", AmountIn: 1, AmountOut: 1})
+
+	a.Publish(context.Background())
+	require.Len(t, pub.rounds, 1)
+	round := pub.rounds[0]
+	require.Len(t, round, 2)
+	assert.Equal(t, "BONK/SOL", round[0].Pair)
+	assert.Equal(t, "SOL/USDC", round[1].Pair)
+	assert.InDelta(t, 152, round[1].Price, 1e-9)
+	assert.InDelta(t, 3, round[1].Volume, 1e-9)
+	assert.Equal(t, 2, round[1].Trades)
+
+	// the first SOL/USDC swap leaves the window
+	now = now.Add(45 * time.Second)
+	a.Publish(context.Background())
+	sol := pub.rounds[1][1]
+	assert.InDelta(t, 1, sol.Volume, 1e-9)
+	assert.Equal(t, 1, sol.Trades)
+
+	// idle pairs get one empty ticker, then stop
+	now = now.Add(time.Minute)
+	a.Publish(context.Background())
+	require.Len(t, pub.rounds[2], 2)
+	assert.Zero(t, pub.rounds[2][1].Trades)
+	assert.InDelta(t, 152, pub.rounds[2][1].Price, 1e-9)
+	a.Publish(context.Background())
+	assert.Len(t, pub.rounds, 3)
+}
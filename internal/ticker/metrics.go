@@ -0,0 +1,6 @@
+package ticker
+
+import "github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
+
+var tickersPublished = metrics.Default.Counter("tickers_published_total",
+	"Per-pair ticker messages published on swaps:ticker:<pair>.")
@@ -0,0 +1,51 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+)
+
+// TokenCatalog keeps the token metadata that ClickHouse queries join swaps
+// against (implemented by *cache.ClickHouseStore)
+type TokenCatalog interface {
+	UpsertTokens(ctx context.Context, metas []*models.TokenMetadata) error
+}
+
+// StaticTokens returns the metadata of constants.TokenSymbols
+func StaticTokens() []*models.TokenMetadata {
+	out := make([]*models.TokenMetadata, 0, len(constants.TokenSymbols))
+	for mint, symbol := range constants.TokenSymbols {
+		detail := constants.TokenDetails[symbol]
+		if detail.Category == "" && strings.HasSuffix(symbol, " LP") {
+			detail.Category = constants.TokenCategoryLP
+		}
+		out = append(out, &models.TokenMetadata{
+			Mint:     mint,
+			Symbol:   symbol,
+			Name:     detail.Name,
+			Category: detail.Category,
+		})
+	}
+	return out
+}
+
+// SyncCatalog writes the static tokens, and those registered in store if it
+// is set, to catalog
+func SyncCatalog(ctx context.Context, catalog TokenCatalog, store MetadataStore) error {
+	metas := StaticTokens()
+	if store != nil {
+		registered, err := store.ListTokenMetadata(ctx)
+		if err != nil {
+			return err
+		}
+		metas = append(metas, registered...)
+	}
+	if err := catalog.UpsertTokens(ctx, metas); err != nil {
+		return fmt.Errorf("failed to sync token catalog: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package tokens
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Metadata is the descriptive part of a Metaplex token metadata account
+type Metadata struct {
+	Name   string
+	Symbol string
+	URI    string
+}
+
+// Metaplex metadata account layout: key (1), update authority (32) and mint
+// (32), then name, symbol and uri as borsh strings (u32 length + bytes)
+// padded with NULs
+const (
+	metadataKeyV1        = 4
+	metadataStringsStart = 1 + 32 + 32
+)
+
+// MetadataAddress returns the Metaplex metadata account of mint
+func MetadataAddress(mint string) (string, error) {
+	key, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return "", fmt.Errorf("invalid mint %q: %w", mint, err)
+	}
+	addr, _, err := solana.FindTokenMetadataAddress(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive metadata address of %s: %w", mint, err)
+	}
+	return addr.String(), nil
+}
+
+// ParseMetadata decodes the name, symbol and uri of a metadata account
+func ParseMetadata(data []byte) (*Metadata, error) {
+	if len(data) < metadataStringsStart || data[0] != metadataKeyV1 {
+		return nil, errors.New("not a metadata account")
+	}
+	rest := data[metadataStringsStart:]
+	var fields [3]string
+	for i := range fields {
+		if len(rest) < 4 {
+			return nil, errors.New("truncated metadata account")
+		}
+		n := int(binary.LittleEndian.Uint32(rest))
+		rest = rest[4:]
+		if n > len(rest) {
+			return nil, errors.New("truncated metadata account")
+		}
+		fields[i] = strings.TrimSpace(strings.TrimRight(string(rest[:n]), "\x00"))
+		rest = rest[n:]
+	}
+	return &Metadata{Name: fields[0], Symbol: fields[1], URI: fields[2]}, nil
+}
+
+// ShortLabel is the label swaps of a mint carry until it is registered,
+// e.g. "7vfC...voxs"
+func ShortLabel(mint string) string {
+	if len(mint) > 8 {
+		return mint[:4] + "..." + mint[len(mint)-4:]
+	}
+	return mint
+}
+
+// cleanSymbol makes an on-chain symbol usable as a swap label: pairs are
+// written "IN/OUT", so slashes and control characters are dropped
+func cleanSymbol(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '/' || r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(s)
+}
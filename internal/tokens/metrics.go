@@ -0,0 +1,14 @@
+package tokens
+
+import "github.com/aman-zulfiqar/solana-swap-indexer/internal/metrics"
+
+// Results recorded in token_lookups_total
+const (
+	lookupRegistered = "registered"  // labeled with its Metaplex symbol
+	lookupNoMetadata = "no_metadata" // keeps the short label
+	lookupFailed     = "failed"      // retried on next sight
+	lookupDropped    = "dropped"     // lookup queue full
+)
+
+var tokenLookups = metrics.Default.Counter("token_lookups_total",
+	"Metadata lookups of mints outside the static token list, by result.", "result")
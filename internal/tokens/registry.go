@@ -0,0 +1,315 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+// AccountReader reads raw account data from chain (implemented by *rpc.Client)
+type AccountReader interface {
+	GetAccountData(ctx context.Context, address string) ([]byte, error)
+}
+
+// MetadataStore shares registered mints between processes, and lets one of
+// them relabel a mint's stored swaps (implemented by *cache.RedisCache)
+type MetadataStore interface {
+	GetTokenMetadata(ctx context.Context, mint string) (*models.TokenMetadata, error)
+	ListTokenMetadata(ctx context.Context) ([]*models.TokenMetadata, error)
+	SaveTokenMetadata(ctx context.Context, meta *models.TokenMetadata) error
+
+	ClaimRelabel(ctx context.Context, mint string) (bool, error)
+	FinishRelabel(ctx context.Context, mint string, done bool) error
+}
+
+// SwapRelabeler rewrites the token label of stored swaps
+// (implemented by *cache.ClickHouseStore)
+type SwapRelabeler interface {
+	RelabelToken(ctx context.Context, from, to string, before time.Time) error
+}
+
+// RegistryConfig configures a Registry
+type RegistryConfig struct {
+	Reader    AccountReader // required
+	Store     MetadataStore // optional; without it every process looks mints up itself
+	Relabeler SwapRelabeler // optional; without it stored swaps keep the short label
+	Catalog   TokenCatalog  // optional; registered mints are added to it
+
+	// BackfillDelay is how long after registering a mint its stored swaps are
+	// relabeled, so swaps labeled before still reach the store first
+	// (default constants.TokenBackfillDelay)
+	BackfillDelay time.Duration
+	QueueSize     int // default constants.TokenRegistryQueueSize
+	Logger        *logrus.Logger
+}
+
+// Registry labels mints outside constants.TokenSymbols with their Metaplex
+// symbol. Unknown mints are looked up in the background by Run; until then,
+// and for good if they have no metadata, they keep ShortLabel. It is safe
+// for concurrent use.
+type Registry struct {
+	cfg    RegistryConfig
+	logger *logrus.Logger
+	queue  chan string
+
+	mu      sync.RWMutex
+	known   map[string]*models.TokenMetadata // mint -> metadata, Symbol "" without any
+	labels  map[string]string                // label -> mint, static symbols included
+	pending map[string]bool                  // mints queued or being looked up
+}
+
+// NewRegistry creates a registry that knows only the static symbols; call
+// Load to add the mints registered earlier
+func NewRegistry(cfg RegistryConfig) *Registry {
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+	if cfg.BackfillDelay <= 0 {
+		cfg.BackfillDelay = constants.TokenBackfillDelay
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = constants.TokenRegistryQueueSize
+	}
+	labels := make(map[string]string, len(constants.TokenSymbols))
+	for mint, symbol := range constants.TokenSymbols {
+		labels[symbol] = mint
+	}
+	return &Registry{
+		cfg:     cfg,
+		logger:  cfg.Logger,
+		queue:   make(chan string, cfg.QueueSize),
+		known:   make(map[string]*models.TokenMetadata),
+		labels:  labels,
+		pending: make(map[string]bool),
+	}
+}
+
+// Load reads the mints registered so far from the store
+func (r *Registry) Load(ctx context.Context) error {
+	if r.cfg.Store == nil {
+		return nil
+	}
+	metas, err := r.cfg.Store.ListTokenMetadata(ctx)
+	if err != nil {
+		return err
+	}
+	for _, meta := range metas {
+		r.remember(meta)
+	}
+	r.logger.WithField("tokens", len(metas)).Debug("loaded registered tokens")
+	return nil
+}
+
+// Symbol returns the label of mint: its static symbol, else its registered
+// one. ok is false when the mint is unknown or has no metadata.
+func (r *Registry) Symbol(mint string) (string, bool) {
+	if symbol, ok := constants.TokenSymbols[mint]; ok {
+		return symbol, true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if meta, ok := r.known[mint]; ok && meta.Symbol != "" {
+		return meta.Symbol, true
+	}
+	return "", false
+}
+
+// Label returns the label of mint, queueing a metadata lookup the first time
+// an unknown mint is seen
+func (r *Registry) Label(mint string) string {
+	if symbol, ok := r.Symbol(mint); ok {
+		return symbol
+	}
+	r.observe(mint)
+	return ShortLabel(mint)
+}
+
+// observe queues mint for a lookup unless it was already looked up or queued.
+// When the queue is full the mint is dropped and queued again on next sight.
+func (r *Registry) observe(mint string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.known[mint]; ok || r.pending[mint] {
+		return
+	}
+	select {
+	case r.queue <- mint:
+		r.pending[mint] = true
+	default:
+		tokenLookups.With(lookupDropped).Inc()
+	}
+}
+
+// Run looks up queued mints until ctx is cancelled
+func (r *Registry) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case mint := <-r.queue:
+			r.register(ctx, mint)
+		}
+	}
+}
+
+// register looks mint up in the store, then on chain, and schedules the
+// backfill of its stored swaps. A failed lookup is retried on next sight.
+func (r *Registry) register(ctx context.Context, mint string) {
+	defer func() {
+		r.mu.Lock()
+		delete(r.pending, mint)
+		r.mu.Unlock()
+	}()
+	log := r.logger.WithField("mint", mint)
+
+	meta, err := r.stored(ctx, mint)
+	if err != nil {
+		log.WithError(err).Warn("failed to read registered token")
+	}
+	if meta == nil {
+		if meta, err = r.lookup(ctx, mint); err != nil {
+			log.WithError(err).Warn("failed to look up token metadata")
+			tokenLookups.With(lookupFailed).Inc()
+			return
+		}
+		if r.cfg.Store != nil {
+			sctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			if err := r.cfg.Store.SaveTokenMetadata(sctx, meta); err != nil {
+				log.WithError(err).Warn("failed to save token metadata")
+			}
+			cancel()
+			// another process may have registered the mint meanwhile; its label wins
+			if saved, err := r.stored(ctx, mint); err == nil && saved != nil {
+				meta = saved
+			}
+		}
+	}
+
+	r.remember(meta)
+	if meta.Symbol == "" {
+		tokenLookups.With(lookupNoMetadata).Inc()
+		log.Debug("token has no metadata, keeping the short label")
+		return
+	}
+	tokenLookups.With(lookupRegistered).Inc()
+	log.WithFields(logrus.Fields{"symbol": meta.Symbol, "name": meta.Name}).Info("registered token")
+	r.catalog(ctx, meta)
+	r.scheduleBackfill(ctx, mint, meta.Symbol)
+}
+
+// catalog adds a registered mint to the token catalog
+func (r *Registry) catalog(ctx context.Context, meta *models.TokenMetadata) {
+	if r.cfg.Catalog == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := r.cfg.Catalog.UpsertTokens(ctx, []*models.TokenMetadata{meta}); err != nil {
+		r.logger.WithError(err).WithField("mint", meta.Mint).Warn("failed to add token to the catalog")
+	}
+}
+
+// stored returns the mint as another process registered it, or nil
+func (r *Registry) stored(ctx context.Context, mint string) (*models.TokenMetadata, error) {
+	if r.cfg.Store == nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	return r.cfg.Store.GetTokenMetadata(ctx, mint)
+}
+
+// lookup reads the mint's metadata account and picks a label no other mint
+// uses
+func (r *Registry) lookup(ctx context.Context, mint string) (*models.TokenMetadata, error) {
+	addr, err := MetadataAddress(mint)
+	if err != nil {
+		return nil, err
+	}
+	meta := &models.TokenMetadata{Mint: mint, RegisteredAt: time.Now().UTC()}
+
+	data, err := r.cfg.Reader.GetAccountData(ctx, addr)
+	if errors.Is(err, rpc.ErrAccountNotFound) {
+		return meta, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	md, err := ParseMetadata(data)
+	if err != nil {
+		return nil, fmt.Errorf("metadata account %s: %w", addr, err)
+	}
+	meta.Name, meta.URI = md.Name, md.URI
+	meta.Symbol = r.uniqueLabel(mint, cleanSymbol(md.Symbol))
+	return meta, nil
+}
+
+// uniqueLabel returns symbol, or symbol suffixed with the start of mint when
+// another mint (e.g. an impostor "USDC") already carries it
+func (r *Registry) uniqueLabel(mint, symbol string) string {
+	if symbol == "" {
+		return ""
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if owner, ok := r.labels[symbol]; ok && owner != mint {
+		return symbol + "-" + mint[:4]
+	}
+	return symbol
+}
+
+func (r *Registry) remember(meta *models.TokenMetadata) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.known[meta.Mint] = meta
+	if meta.Symbol != "" {
+		r.labels[meta.Symbol] = meta.Mint
+	}
+}
+
+// scheduleBackfill relabels the swaps stored under the mint's short label
+// once BackfillDelay has passed. Every process that learns the mint gets
+// here, so with a Store only the one that claims the mint there relabels.
+func (r *Registry) scheduleBackfill(ctx context.Context, mint, symbol string) {
+	if r.cfg.Relabeler == nil {
+		return
+	}
+	before := time.Now()
+	time.AfterFunc(r.cfg.BackfillDelay, func() {
+		if ctx.Err() != nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(ctx, constants.TokenRelabelTimeout)
+		defer cancel()
+		log := r.logger.WithFields(logrus.Fields{"mint": mint, "symbol": symbol})
+		if r.cfg.Store != nil {
+			claimed, err := r.cfg.Store.ClaimRelabel(ctx, mint)
+			if err != nil {
+				log.WithError(err).Warn("failed to claim the relabel of stored swaps")
+				return
+			}
+			if !claimed {
+				log.Debug("stored swaps relabeled by another process")
+				return
+			}
+		}
+		err := r.cfg.Relabeler.RelabelToken(ctx, ShortLabel(mint), symbol, before)
+		if r.cfg.Store != nil {
+			if ferr := r.cfg.Store.FinishRelabel(context.WithoutCancel(ctx), mint, err == nil); ferr != nil {
+				log.WithError(ferr).Warn("failed to release the relabel claim")
+			}
+		}
+		if err != nil {
+			log.WithError(err).Warn("failed to relabel stored swaps")
+			return
+		}
+		log.Info("relabeled stored swaps")
+	})
+}
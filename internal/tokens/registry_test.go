@@ -0,0 +1,201 @@
+package tokens
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// metadataAccount encodes a Metaplex metadata account with padded strings
+func metadataAccount(name, symbol, uri string) []byte {
+	data := make([]byte, metadataStringsStart)
+	data[0] = metadataKeyV1
+	for _, f := range []struct {
+		s   string
+		pad int
+	}{{name, 32}, {symbol, 10}, {uri, 200}} {
+		b := make([]byte, f.pad)
+		copy(b, f.s)
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(b)))
+		data = append(data, b...)
+	}
+	return data
+}
+
+type fakeAccounts map[string][]byte
+
+func (f fakeAccounts) GetAccountData(_ context.Context, address string) ([]byte, error) {
+	data, ok := f[address]
+	if !ok {
+		return nil, rpc.ErrAccountNotFound
+	}
+	return data, nil
+}
+
+type memMetadata map[string]*models.TokenMetadata
+
+func (m memMetadata) GetTokenMetadata(_ context.Context, mint string) (*models.TokenMetadata, error) {
+	return m[mint], nil
+}
+
+func (m memMetadata) ListTokenMetadata(_ context.Context) ([]*models.TokenMetadata, error) {
+	out := make([]*models.TokenMetadata, 0, len(m))
+	for _, meta := range m {
+		out = append(out, meta)
+	}
+	return out, nil
+}
+
+func (m memMetadata) SaveTokenMetadata(_ context.Context, meta *models.TokenMetadata) error {
+	if _, ok := m[meta.Mint]; !ok {
+		m[meta.Mint] = meta
+	}
+	return nil
+}
+
+func (m memMetadata) ClaimRelabel(context.Context, string) (bool, error) { return true, nil }
+
+func (m memMetadata) FinishRelabel(context.Context, string, bool) error { return nil }
+
+// memClaims is a memMetadata whose relabel claims are shared like in Redis
+type memClaims struct {
+	memMetadata
+	mu     sync.Mutex
+	claims map[string]string // mint -> running or done
+}
+
+func (m *memClaims) ClaimRelabel(_ context.Context, mint string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.claims[mint]; ok {
+		return false, nil
+	}
+	m.claims[mint] = "running"
+	return true, nil
+}
+
+func (m *memClaims) FinishRelabel(_ context.Context, mint string, done bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if done {
+		m.claims[mint] = "done"
+	} else {
+		delete(m.claims, mint)
+	}
+	return nil
+}
+
+type relabel struct{ from, to string }
+
+type fakeRelabeler struct {
+	mu    sync.Mutex
+	calls []relabel
+}
+
+func (f *fakeRelabeler) RelabelToken(_ context.Context, from, to string, _ time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, relabel{from, to})
+	return nil
+}
+
+func (f *fakeRelabeler) relabels() []relabel {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]relabel(nil), f.calls...)
+}
+
+func mustMetadataAddress(t *testing.T, mint string) string {
+	t.Helper()
+	addr, err := MetadataAddress(mint)
+	require.NoError(t, err)
+	return addr
+}
+
+func TestParseMetadata(t *testing.T) {
+	md, err := ParseMetadata(metadataAccount("Popcat", "POPCAT", "https://example.com/popcat.json"))
+	require.NoError(t, err)
+	assert.Equal(t, &Metadata{Name: "Popcat", Symbol: "POPCAT", URI: "https://example.com/popcat.json"}, md)
+
+	_, err = ParseMetadata([]byte{metadataKeyV1, 1, 2})
+	assert.Error(t, err)
+	_, err = ParseMetadata(metadataAccount("a", "b", "c")[:80])
+	assert.Error(t, err, "truncated")
+}
+
+func TestRegistryRegistersUnknownMint(t *testing.T) {
+	const newMint = "3HdPGbsJipHQf2Df43JXUiEN5Z2XgEGLv5ZsE7eLprWQ"
+	ctx := context.Background()
+	accounts := fakeAccounts{mustMetadataAddress(t, newMint): metadataAccount("Meow", "ME/OW", "")}
+	store := memMetadata{}
+	relabeler := &fakeRelabeler{}
+	r := NewRegistry(RegistryConfig{Reader: accounts, Store: store, Relabeler: relabeler, BackfillDelay: time.Millisecond})
+
+	assert.Equal(t, "SOL", r.Label("So11111111111111111111111111111111111111112"))
+	assert.Equal(t, "3HdP...prWQ", r.Label(newMint), "short label until looked up")
+	r.Label(newMint)
+	require.Len(t, r.queue, 1, "queued once")
+
+	r.register(ctx, <-r.queue)
+	assert.Equal(t, "MEOW", r.Label(newMint))
+	require.NotNil(t, store[newMint])
+	assert.Equal(t, "Meow", store[newMint].Name)
+
+	assert.Eventually(t, func() bool { return len(relabeler.relabels()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, relabel{"3HdP...prWQ", "MEOW"}, relabeler.relabels()[0])
+
+	// another process loads the registration instead of looking it up
+	other := NewRegistry(RegistryConfig{Reader: fakeAccounts{}, Store: store})
+	require.NoError(t, other.Load(ctx))
+	symbol, ok := other.Symbol(newMint)
+	assert.True(t, ok)
+	assert.Equal(t, "MEOW", symbol)
+}
+
+func TestRegistryRelabelsOnce(t *testing.T) {
+	const newMint = "3HdPGbsJipHQf2Df43JXUiEN5Z2XgEGLv5ZsE7eLprWQ"
+	ctx := context.Background()
+	accounts := fakeAccounts{mustMetadataAddress(t, newMint): metadataAccount("Meow", "MEOW", "")}
+	store := &memClaims{memMetadata: memMetadata{}, claims: map[string]string{}}
+	relabeler := &fakeRelabeler{}
+
+	// every replica learns the mint; only the one that claims it relabels
+	for range 3 {
+		r := NewRegistry(RegistryConfig{Reader: accounts, Store: store, Relabeler: relabeler, BackfillDelay: time.Millisecond})
+		r.Label(newMint)
+		r.register(ctx, <-r.queue)
+	}
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return store.claims[newMint] == "done"
+	}, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, []relabel{{"3HdP...prWQ", "MEOW"}}, relabeler.relabels())
+}
+
+func TestRegistryLabels(t *testing.T) {
+	const (
+		impostor = "8MNT2vuabf79CfB4fX6jH4BWnqfFrvXVDpDb3WrNriku"
+		bare     = "CGzeZ12z4TDLxdtDBhJrgRWYhAx6rWSq9oPmWszbUVVC"
+	)
+	ctx := context.Background()
+	accounts := fakeAccounts{mustMetadataAddress(t, impostor): metadataAccount("USD Coin", "USDC", "")}
+	r := NewRegistry(RegistryConfig{Reader: accounts})
+
+	r.Label(impostor)
+	r.register(ctx, <-r.queue)
+	assert.Equal(t, "USDC-8MNT", r.Label(impostor), "symbols of other mints are not reused")
+
+	r.Label(bare)
+	r.register(ctx, <-r.queue)
+	assert.Equal(t, "CGze...UVVC", r.Label(bare), "no metadata keeps the short label")
+	assert.Empty(t, r.queue, "and is not looked up again")
+}
@@ -0,0 +1,109 @@
+// Package tokens resolves SPL token metadata by mint address. Decimals are
+// read from the mint account on first sight and cached in memory and in
+// Redis, so every process (decision engine, parsers, valuation) agrees on
+// them without a hard-coded table.
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/constants"
+	"github.com/sirupsen/logrus"
+)
+
+// MintFetcher reads a mint's decimals from chain (implemented by *rpc.Client)
+type MintFetcher interface {
+	GetMintDecimals(ctx context.Context, mint string) (uint8, error)
+}
+
+// DecimalsCache shares resolved decimals between processes
+// (implemented by *cache.RedisCache)
+type DecimalsCache interface {
+	GetTokenDecimals(ctx context.Context, mint string) (decimals uint8, ok bool, err error)
+	SetTokenDecimals(ctx context.Context, mint string, decimals uint8) error
+}
+
+// Resolver returns the decimals of any SPL mint: from memory, then Redis,
+// then the mint account. It is safe for concurrent use.
+type Resolver struct {
+	fetcher MintFetcher   // optional; without it unknown mints fail
+	cache   DecimalsCache // optional
+	logger  *logrus.Logger
+
+	mu       sync.RWMutex
+	decimals map[string]uint8
+}
+
+// NewResolver creates a resolver seeded with constants.TokenDecimals
+func NewResolver(fetcher MintFetcher, cache DecimalsCache, logger *logrus.Logger) *Resolver {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	known := make(map[string]uint8, len(constants.TokenDecimals))
+	for mint, d := range constants.TokenDecimals {
+		known[mint] = d
+	}
+	return &Resolver{fetcher: fetcher, cache: cache, logger: logger, decimals: known}
+}
+
+// Decimals returns the decimals of mint, fetching and caching them on first
+// sight
+func (r *Resolver) Decimals(ctx context.Context, mint string) (uint8, error) {
+	if d, ok := r.cached(mint); ok {
+		return d, nil
+	}
+
+	if r.cache != nil {
+		d, ok, err := r.cache.GetTokenDecimals(ctx, mint)
+		if err != nil {
+			r.logger.WithError(err).WithField("mint", mint).Warn("failed to read cached token decimals")
+		} else if ok {
+			r.remember(mint, d)
+			return d, nil
+		}
+	}
+
+	if r.fetcher == nil {
+		return 0, fmt.Errorf("unknown decimals for mint %s", mint)
+	}
+	d, err := r.fetcher.GetMintDecimals(ctx, mint)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve decimals of %s: %w", mint, err)
+	}
+	r.Learn(ctx, mint, d)
+	r.logger.WithFields(logrus.Fields{"mint": mint, "decimals": d}).Debug("resolved token decimals")
+	return d, nil
+}
+
+// Learn records decimals seen elsewhere, e.g. in a transaction's token
+// balances, so they never need a lookup. Only new mints reach Redis.
+func (r *Resolver) Learn(ctx context.Context, mint string, decimals uint8) {
+	if d, ok := r.cached(mint); ok && d == decimals {
+		return
+	}
+	r.remember(mint, decimals)
+
+	if r.cache != nil {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+		defer cancel()
+		if err := r.cache.SetTokenDecimals(ctx, mint, decimals); err != nil {
+			r.logger.WithError(err).WithField("mint", mint).Warn("failed to cache token decimals")
+		}
+	}
+}
+
+func (r *Resolver) cached(mint string) (uint8, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.decimals[mint]
+	return d, ok
+}
+
+func (r *Resolver) remember(mint string, decimals uint8) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decimals[mint] = decimals
+}
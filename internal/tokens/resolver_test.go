@@ -0,0 +1,81 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMint = "7GCihgDB8fe6KNjn2MYtkzZcRjQy3t9GHdC8uHYmW2hr"
+
+type fakeFetcher struct {
+	decimals map[string]uint8
+	calls    int
+}
+
+func (f *fakeFetcher) GetMintDecimals(_ context.Context, mint string) (uint8, error) {
+	f.calls++
+	d, ok := f.decimals[mint]
+	if !ok {
+		return 0, errors.New("account not found")
+	}
+	return d, nil
+}
+
+type memCache map[string]uint8
+
+func (m memCache) GetTokenDecimals(_ context.Context, mint string) (uint8, bool, error) {
+	d, ok := m[mint]
+	return d, ok, nil
+}
+
+func (m memCache) SetTokenDecimals(_ context.Context, mint string, decimals uint8) error {
+	m[mint] = decimals
+	return nil
+}
+
+func TestResolverFetchesOnce(t *testing.T) {
+	ctx := context.Background()
+	fetcher := &fakeFetcher{decimals: map[string]uint8{testMint: 9}}
+	cache := memCache{}
+	r := NewResolver(fetcher, cache, nil)
+
+	d, err := r.Decimals(ctx, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	require.NoError(t, err)
+	assert.Equal(t, uint8(6), d, "well-known mints are seeded")
+	assert.Zero(t, fetcher.calls)
+
+	for range 2 {
+		d, err = r.Decimals(ctx, testMint)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(9), d)
+	}
+	assert.Equal(t, 1, fetcher.calls)
+	assert.Equal(t, uint8(9), cache[testMint], "shared through the cache")
+
+	// another process finds it in the cache
+	d, err = NewResolver(fetcher, cache, nil).Decimals(ctx, testMint)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(9), d)
+	assert.Equal(t, 1, fetcher.calls)
+
+	_, err = r.Decimals(ctx, "unknownMint")
+	assert.Error(t, err)
+}
+
+func TestResolverLearn(t *testing.T) {
+	cache := memCache{}
+	r := NewResolver(nil, cache, nil)
+	r.Learn(context.Background(), testMint, 9)
+
+	d, err := r.Decimals(context.Background(), testMint)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(9), d)
+	assert.Equal(t, uint8(9), cache[testMint])
+
+	_, err = r.Decimals(context.Background(), "unknownMint")
+	assert.Error(t, err, "nothing to fetch with")
+}
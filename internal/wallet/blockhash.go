@@ -0,0 +1,121 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	projectrpc "github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
+	"github.com/gagliardetto/solana-go"
+)
+
+// ErrBlockhashExpired is returned by SendTx for a transaction whose blockhash
+// is predicted to have expired, instead of sending it to be dropped
+var ErrBlockhashExpired = errors.New("blockhash expired")
+
+// blockhashValidity is how many blocks a blockhash is accepted for
+// (MAX_PROCESSING_AGE), and blockTime the target time per block; 150 blocks
+// of 400ms is the ~60s a transaction has to land
+const (
+	blockhashValidity = 150
+	blockTime         = 400 * time.Millisecond
+)
+
+// Blockhash is a recent blockhash and when it was fetched
+type Blockhash struct {
+	Hash                 solana.Hash
+	LastValidBlockHeight uint64 // last block height a transaction using it can land at
+	FetchedAt            time.Time
+}
+
+// ExpiresAt predicts when the blockhash stops being accepted. Blocks are
+// often slower than the target, so it errs early.
+func (b Blockhash) ExpiresAt() time.Time {
+	return b.FetchedAt.Add(blockhashValidity * blockTime)
+}
+
+// fetchBlockhash calls getLatestBlockhash and caches the result
+func (w *Wallet) fetchBlockhash(ctx context.Context, commitment string) (Blockhash, error) {
+	var resp struct {
+		Result struct {
+			Value struct {
+				Blockhash            string `json:"blockhash"`
+				LastValidBlockHeight uint64 `json:"lastValidBlockHeight"`
+			} `json:"value"`
+		} `json:"result"`
+		Error *projectrpc.RPCError `json:"error"`
+	}
+
+	params := []any{
+		map[string]any{"commitment": commitment},
+	}
+
+	if err := w.rpc.Call(ctx, "getLatestBlockhash", params, &resp); err != nil {
+		return Blockhash{}, fmt.Errorf("getLatestBlockhash failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return Blockhash{}, fmt.Errorf("getLatestBlockhash error: %s", resp.Error.Message)
+	}
+
+	hash, err := solana.HashFromBase58(resp.Result.Value.Blockhash)
+	if err != nil {
+		return Blockhash{}, fmt.Errorf("invalid blockhash format: %w", err)
+	}
+
+	bh := Blockhash{Hash: hash, LastValidBlockHeight: resp.Result.Value.LastValidBlockHeight, FetchedAt: time.Now()}
+	w.bhMu.Lock()
+	defer w.bhMu.Unlock()
+	w.blockhash = bh
+	w.issued[hash] = bh
+	for h, old := range w.issued {
+		if time.Since(old.ExpiresAt()) > time.Minute {
+			delete(w.issued, h)
+		}
+	}
+	return bh, nil
+}
+
+// RecentBlockhash returns the cached blockhash while it is younger than
+// twice BlockhashRefresh, and fetches one otherwise (always, when
+// BlockhashRefresh is 0)
+func (w *Wallet) RecentBlockhash(ctx context.Context) (Blockhash, error) {
+	if maxAge := 2 * w.cfg.BlockhashRefresh; maxAge > 0 {
+		w.bhMu.Lock()
+		bh := w.blockhash
+		w.bhMu.Unlock()
+		if !bh.FetchedAt.IsZero() && time.Since(bh.FetchedAt) < maxAge {
+			return bh, nil
+		}
+	}
+	return w.fetchBlockhash(ctx, "processed")
+}
+
+// RefreshBlockhash fetches a blockhash every BlockhashRefresh until ctx is
+// done, so BuildTransaction takes it from the cache. A failed refresh is
+// retried on the next tick; the cache falls back to fetching once it is stale.
+func (w *Wallet) RefreshBlockhash(ctx context.Context) {
+	if w.cfg.BlockhashRefresh <= 0 {
+		return
+	}
+	ticker := time.NewTicker(w.cfg.BlockhashRefresh)
+	defer ticker.Stop()
+	for {
+		_, _ = w.fetchBlockhash(ctx, "processed")
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// BlockhashExpiresAt predicts when a blockhash this wallet fetched expires;
+// ok is false for a blockhash it did not fetch
+func (w *Wallet) BlockhashExpiresAt(hash solana.Hash) (expiresAt time.Time, ok bool) {
+	w.bhMu.Lock()
+	defer w.bhMu.Unlock()
+	bh, ok := w.issued[hash]
+	return bh.ExpiresAt(), ok
+}
@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockhashCache(t *testing.T) {
+	hash := solana.HashFromBytes(make([]byte, 32))
+	var fetches, sends atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "getLatestBlockhash":
+			fetches.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{"value": map[string]any{
+				"blockhash": hash.String(), "lastValidBlockHeight": 1150}}})
+		case "sendTransaction":
+			sends.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{"result": "sig"})
+		}
+	}))
+	defer srv.Close()
+
+	w, err := NewWallet(WalletConfig{
+		RPCURL:           srv.URL,
+		PrivateKey:       solana.NewWallet().PrivateKey.String(),
+		MaxRetries:       1,
+		BlockhashRefresh: time.Minute,
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	ixs := []solana.Instruction{solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{}, []byte("hi"))}
+
+	tx, err := w.BuildTransaction(ctx, ixs)
+	require.NoError(t, err)
+	_, err = w.BuildTransaction(ctx, ixs)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load(), "second build reuses the cached blockhash")
+
+	expiresAt, ok := w.BlockhashExpiresAt(hash)
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(60*time.Second), expiresAt, time.Second)
+
+	w.bhMu.Lock()
+	bh := w.issued[hash]
+	bh.FetchedAt = time.Now().Add(-2 * time.Minute)
+	w.issued[hash], w.blockhash = bh, bh
+	w.bhMu.Unlock()
+
+	_, err = w.SendTx(ctx, tx, nil)
+	assert.ErrorIs(t, err, ErrBlockhashExpired)
+	assert.Zero(t, sends.Load(), "not sent")
+
+	_, err = w.RecentBlockhash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load(), "stale cache fetches again")
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	projectrpc "github.com/aman-zulfiqar/solana-swap-indexer/internal/rpc"
@@ -25,6 +27,10 @@ type WalletConfig struct {
 	DefaultCommitment   string // e.g. "confirmed"
 	SkipPreflight       bool
 	PreflightCommitment string // e.g. "processed"
+
+	// BlockhashRefresh is how often RefreshBlockhash prefetches a blockhash
+	// for BuildTransaction (0 = fetch one per transaction)
+	BlockhashRefresh time.Duration
 }
 
 type Wallet struct {
@@ -32,12 +38,43 @@ type Wallet struct {
 	rpc  *projectrpc.Client
 	priv solana.PrivateKey
 	pub  solana.PublicKey
+
+	bhMu      sync.Mutex
+	blockhash Blockhash                 // latest fetched
+	issued    map[solana.Hash]Blockhash // every blockhash fetched until a minute past its expiry
 }
 
 func NewWallet(cfg WalletConfig) (*Wallet, error) {
 	if cfg.RPCURL == "" {
 		return nil, fmt.Errorf("wallet: RPCURL is required")
 	}
+	if strings.TrimSpace(cfg.PrivateKey) == "" {
+		return nil, fmt.Errorf("wallet: PrivateKey is required")
+	}
+
+	priv, err := ParsePrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	w := newWallet(cfg)
+	w.priv, w.pub = priv, priv.PublicKey()
+	return w, nil
+}
+
+// NewReadOnlyWallet creates a wallet without a key, for transactions signed
+// elsewhere: it simulates and sends them but cannot sign. cfg.PrivateKey is
+// ignored.
+func NewReadOnlyWallet(cfg WalletConfig) (*Wallet, error) {
+	if cfg.RPCURL == "" {
+		return nil, fmt.Errorf("wallet: RPCURL is required")
+	}
+	cfg.PrivateKey = ""
+	return newWallet(cfg), nil
+}
+
+// newWallet applies the config defaults and creates a wallet without a key
+func newWallet(cfg WalletConfig) *Wallet {
 	if cfg.Timeout == 0 {
 		cfg.Timeout = 30 * time.Second
 	}
@@ -53,30 +90,17 @@ func NewWallet(cfg WalletConfig) (*Wallet, error) {
 	if cfg.PreflightCommitment == "" {
 		cfg.PreflightCommitment = "processed"
 	}
-	if strings.TrimSpace(cfg.PrivateKey) == "" {
-		return nil, fmt.Errorf("wallet: PrivateKey is required")
-	}
-
-	priv, err := parsePrivateKey(cfg.PrivateKey)
-	if err != nil {
-		return nil, err
-	}
-
-	rpcClient := projectrpc.NewClient(projectrpc.ClientConfig{
-		BaseURL:      cfg.RPCURL,
-		Timeout:      cfg.Timeout,
-		MaxRetries:   cfg.MaxRetries,
-		RetryBackoff: cfg.RetryBackoff,
-	})
-
-	pub := priv.PublicKey()
 
 	return &Wallet{
-		cfg:  cfg,
-		rpc:  rpcClient,
-		priv: priv,
-		pub:  pub,
-	}, nil
+		cfg: cfg,
+		rpc: projectrpc.NewClient(projectrpc.ClientConfig{
+			BaseURL:      cfg.RPCURL,
+			Timeout:      cfg.Timeout,
+			MaxRetries:   cfg.MaxRetries,
+			RetryBackoff: cfg.RetryBackoff,
+		}),
+		issued: make(map[solana.Hash]Blockhash),
+	}
 }
 
 func NewWalletFromEnv() (*Wallet, error) {
@@ -85,6 +109,9 @@ func NewWalletFromEnv() (*Wallet, error) {
 		PrivateKey:        os.Getenv("WALLET_PRIVATE_KEY"),
 		DefaultCommitment: os.Getenv("WALLET_COMMITMENT"),
 	}
+	if d, err := time.ParseDuration(os.Getenv("WALLET_BLOCKHASH_REFRESH")); err == nil && d >= 0 {
+		cfg.BlockhashRefresh = d
+	}
 	return NewWallet(cfg)
 }
 
@@ -115,6 +142,37 @@ func (w *Wallet) GetBalanceSOL(ctx context.Context) (float64, error) {
 	return float64(resp.Result.Value) / 1e9, nil
 }
 
+// GetTokenAccountBalance returns the raw balance of one of the wallet's
+// token accounts
+func (w *Wallet) GetTokenAccountBalance(ctx context.Context, account solana.PublicKey) (uint64, error) {
+	var resp struct {
+		Result struct {
+			Value struct {
+				Amount string `json:"amount"` // raw units
+			} `json:"value"`
+		} `json:"result"`
+		Error *projectrpc.RPCError `json:"error"`
+	}
+
+	params := []any{
+		account.String(),
+		map[string]any{"commitment": w.cfg.DefaultCommitment},
+	}
+
+	if err := w.rpc.Call(ctx, "getTokenAccountBalance", params, &resp); err != nil {
+		return 0, fmt.Errorf("getTokenAccountBalance RPC failed: %w", err)
+	}
+	if resp.Error != nil {
+		return 0, fmt.Errorf("getTokenAccountBalance error: %s", resp.Error.Message)
+	}
+
+	amount, err := strconv.ParseUint(resp.Result.Value.Amount, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid token amount %q: %w", resp.Result.Value.Amount, err)
+	}
+	return amount, nil
+}
+
 // AccountExists checks if an account exists on-chain (getAccountInfo != nil).
 func (w *Wallet) AccountExists(ctx context.Context, pubkey solana.PublicKey) (bool, error) {
 	var resp struct {
@@ -141,7 +199,8 @@ func (w *Wallet) AccountExists(ctx context.Context, pubkey solana.PublicKey) (bo
 	return resp.Result.Value != nil, nil
 }
 
-func parsePrivateKey(s string) (solana.PrivateKey, error) {
+// ParsePrivateKey accepts a base58 secret key or a JSON byte array (solana-keygen format)
+func ParsePrivateKey(s string) (solana.PrivateKey, error) {
 	s = strings.TrimSpace(s)
 	if strings.HasPrefix(s, "[") {
 		var ints []int
@@ -3,6 +3,7 @@ package wallet
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -50,6 +51,12 @@ func (w *Wallet) SendTx(ctx context.Context, tx *solana.Transaction, opts *SendO
 		opts = &defaultOpts
 	}
 
+	// A transaction past its blockhash would only be dropped by the cluster
+	hash := tx.Message.RecentBlockhash
+	if expiresAt, ok := w.BlockhashExpiresAt(hash); ok && !time.Now().Before(expiresAt) {
+		return "", fmt.Errorf("%w: %s expired at %s", ErrBlockhashExpired, hash, expiresAt.Format(time.RFC3339))
+	}
+
 	// Serialize transaction
 	txBytes, err := tx.MarshalBinary()
 	if err != nil {
@@ -84,8 +91,15 @@ func (w *Wallet) SendTx(ctx context.Context, tx *solana.Transaction, opts *SendO
 	}
 
 	if resp.Error != nil {
-		return "", fmt.Errorf("sendTransaction error: code=%d, message=%s",
-			resp.Error.Code, resp.Error.Message)
+		txErr := &TxError{Op: "sendTransaction error", Code: resp.Error.Code, Message: resp.Error.Message}
+		var preflight struct {
+			Err  any      `json:"err"`
+			Logs []string `json:"logs"`
+		}
+		if len(resp.Error.Data) > 0 && json.Unmarshal(resp.Error.Data, &preflight) == nil {
+			txErr.Err, txErr.Logs = preflight.Err, preflight.Logs
+		}
+		return "", txErr
 	}
 
 	return resp.Result, nil
@@ -97,36 +111,11 @@ func (w *Wallet) GetLatestBlockhash(ctx context.Context, commitment ...string) (
 	if len(commitment) > 0 {
 		commitmentLevel = commitment[0]
 	}
-
-	var resp struct {
-		Result struct {
-			Value struct {
-				Blockhash            string `json:"blockhash"`
-				LastValidBlockHeight uint64 `json:"lastValidBlockHeight"`
-			} `json:"value"`
-		} `json:"result"`
-		Error *projectrpc.RPCError `json:"error"`
-	}
-
-	params := []any{
-		map[string]any{"commitment": commitmentLevel},
-	}
-
-	if err := w.rpc.Call(ctx, "getLatestBlockhash", params, &resp); err != nil {
-		return solana.Hash{}, fmt.Errorf("getLatestBlockhash failed: %w", err)
-	}
-
-	if resp.Error != nil {
-		return solana.Hash{}, fmt.Errorf("getLatestBlockhash error: %s", resp.Error.Message)
-	}
-
-	// Decode blockhash
-	hash, err := solana.HashFromBase58(resp.Result.Value.Blockhash)
+	bh, err := w.fetchBlockhash(ctx, commitmentLevel)
 	if err != nil {
-		return solana.Hash{}, fmt.Errorf("invalid blockhash format: %w", err)
+		return solana.Hash{}, err
 	}
-
-	return hash, nil
+	return bh.Hash, nil
 }
 
 // SimulateTransaction simulates a transaction before sending
@@ -181,13 +170,30 @@ func (w *Wallet) SimulateTransaction(ctx context.Context, tx *solana.Transaction
 	if resp.Result.Value.Err != nil {
 		result.Success = false
 		result.Error = fmt.Sprintf("%v", resp.Result.Value.Err)
-		return result, fmt.Errorf("simulation failed: %v", resp.Result.Value.Err)
+		return result, &TxError{Op: "simulation failed", Err: resp.Result.Value.Err, Logs: resp.Result.Value.Logs}
 	}
 
 	result.Success = true
 	return result, nil
 }
 
+// TxError is a transaction the cluster rejected (simulation or
+// sendTransaction preflight) or that failed on chain
+type TxError struct {
+	Op      string   // what failed: "simulation failed", "sendTransaction error" or "transaction failed"
+	Code    int      // JSON-RPC error code (sendTransaction only)
+	Message string   // JSON-RPC error message (sendTransaction only)
+	Err     any      // transaction error as decoded from JSON, e.g. {"InstructionError":[2,{"Custom":6001}]}
+	Logs    []string // program logs, when the RPC returned them
+}
+
+func (e *TxError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s: code=%d, message=%s", e.Op, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
 // SimulationResult contains simulation output
 type SimulationResult struct {
 	Success       bool
@@ -269,7 +275,7 @@ func (w *Wallet) checkSignatureStatus(ctx context.Context, signature string, com
 
 	// Check for transaction error
 	if status.Err != nil {
-		return false, fmt.Errorf("transaction failed: %v", status.Err)
+		return false, &TxError{Op: "transaction failed", Err: status.Err}
 	}
 
 	// Check if commitment level is met
@@ -291,8 +297,8 @@ func (w *Wallet) BuildTransaction(
 	instructions []solana.Instruction,
 ) (*solana.Transaction, error) {
 
-	// Get recent blockhash
-	recentBlockhash, err := w.GetLatestBlockhash(ctx, "processed")
+	// Get recent blockhash (cached while RefreshBlockhash runs)
+	recentBlockhash, err := w.RecentBlockhash(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get blockhash: %w", err)
 	}
@@ -300,7 +306,7 @@ func (w *Wallet) BuildTransaction(
 	// Create transaction
 	tx, err := solana.NewTransaction(
 		instructions,
-		recentBlockhash,
+		recentBlockhash.Hash,
 		solana.TransactionPayer(w.pub),
 	)
 	if err != nil {
@@ -336,3 +342,16 @@ func (w *Wallet) SignAndSend(
 
 	return sig, nil
 }
+
+// TransactionCost returns the compute units a landed transaction consumed and
+// the fee it paid, in lamports
+func (w *Wallet) TransactionCost(ctx context.Context, signature string) (units, feeLamports uint64, err error) {
+	resp, err := w.rpc.GetTransaction(ctx, signature, w.cfg.DefaultCommitment)
+	if err != nil {
+		return 0, 0, fmt.Errorf("getTransaction failed: %w", err)
+	}
+	if resp.Result == nil || resp.Result.Meta == nil {
+		return 0, 0, fmt.Errorf("transaction %s not found", signature)
+	}
+	return resp.Result.Meta.ComputeUnitsConsumed, resp.Result.Meta.Fee, nil
+}
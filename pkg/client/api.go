@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Health calls GET /v1/health
+func (c *Client) Health(ctx context.Context) error {
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/health", retry: true}, nil)
+	return err
+}
+
+// RecentSwaps returns the newest swaps, newest first
+func (c *Client) RecentSwaps(ctx context.Context, opts RecentSwapsOptions) ([]Swap, error) {
+	q := url.Values{}
+	if opts.Pair != "" {
+		q.Set("pair", opts.Pair)
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var out struct {
+		Items []Swap `json:"items"`
+	}
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/swaps/recent", query: q, retry: true}, &out); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+// Price returns a token's last price; unknown tokens come back with price 0
+// and Stale set
+func (c *Client) Price(ctx context.Context, token string) (*Price, error) {
+	var out Price
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/prices/" + url.PathEscape(token), retry: true}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PriceHistory returns a token's price points over window (1s-24h; 0 uses
+// the server default of 15m)
+func (c *Client) PriceHistory(ctx context.Context, token string, window time.Duration) (*PriceHistory, error) {
+	q := url.Values{}
+	if window > 0 {
+		q.Set("window", window.String())
+	}
+	var out PriceHistory
+	path := "/v1/prices/" + url.PathEscape(token) + "/history"
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: path, query: q, retry: true}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Quote returns a Jupiter quote through the API
+func (c *Client) Quote(ctx context.Context, req QuoteRequest) (*Quote, error) {
+	q := url.Values{}
+	q.Set("inputMint", req.InputMint)
+	q.Set("outputMint", req.OutputMint)
+	q.Set("amount", strconv.FormatUint(req.Amount, 10))
+	if req.SlippageBps != nil {
+		q.Set("slippageBps", strconv.Itoa(int(*req.SlippageBps)))
+	}
+	if req.SwapMode != "" {
+		q.Set("swapMode", req.SwapMode)
+	}
+	if len(req.Dexes) > 0 {
+		q.Set("dexes", strings.Join(req.Dexes, ","))
+	}
+	if len(req.ExcludeDexes) > 0 {
+		q.Set("excludeDexes", strings.Join(req.ExcludeDexes, ","))
+	}
+	setBool(q, "restrictIntermediateTokens", req.RestrictIntermediateTokens)
+	setBool(q, "onlyDirectRoutes", req.OnlyDirectRoutes)
+	setBool(q, "asLegacyTransaction", req.AsLegacyTransaction)
+	if req.PlatformFeeBps != nil {
+		q.Set("platformFeeBps", strconv.Itoa(int(*req.PlatformFeeBps)))
+	}
+	if req.MaxAccounts != nil {
+		q.Set("maxAccounts", strconv.FormatUint(*req.MaxAccounts, 10))
+	}
+	if req.InstructionVersion != "" {
+		q.Set("instructionVersion", req.InstructionVersion)
+	}
+	setBool(q, "dynamicSlippage", req.DynamicSlippage)
+
+	var out Quote
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/quote", query: q, retry: true}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func setBool(q url.Values, key string, v *bool) {
+	if v != nil {
+		q.Set(key, strconv.FormatBool(*v))
+	}
+}
+
+// AskAI asks a question about the swap data. The endpoint is rate limited
+// per client (AI_RATE_LIMIT); 429s are retried like other transient errors.
+func (c *Client) AskAI(ctx context.Context, req AskAIRequest) (*AskAIResponse, error) {
+	var out AskAIResponse
+	// read-only, so safe to repeat
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/v1/ai/ask", body: req, retry: true}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExecuteSwap executes a swap through the swap engine (the server needs
+// SWAP_API_ENABLED). It is only retried when req.IdempotencyKey is set; a
+// 409 for a key still in progress is then retried too, until the first
+// call's response can be replayed.
+//
+// A swap that failed after reaching the engine comes back as a 502 *Error
+// together with the response, whose Signature (if any) names the
+// transaction to look up before trying again.
+func (c *Client) ExecuteSwap(ctx context.Context, req ExecuteSwapRequest) (*ExecuteSwapResponse, error) {
+	r := request{method: http.MethodPost, path: "/v1/swap/execute", body: req}
+	if req.IdempotencyKey != "" {
+		r.header = http.Header{"Idempotency-Key": {req.IdempotencyKey}}
+		r.retry, r.retryConflict = true, true
+	}
+	var out ExecuteSwapResponse
+	header, err := c.do(ctx, r, &out)
+	if err != nil {
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway ||
+			json.Unmarshal(apiErr.Body, &out) != nil || out.ExecutionID == "" {
+			return nil, err
+		}
+	}
+	out.Replayed = header.Get("Idempotent-Replayed") == "true"
+	return &out, err
+}
@@ -0,0 +1,299 @@
+// Package client is a Go client for the swap indexer's REST API. It covers
+// recent swaps, prices, Jupiter quotes, the AI endpoint and swap execution
+// with typed requests and responses, sends the API key, and retries
+// transient failures (network errors, 429 and 5xx) with exponential backoff.
+//
+// The API has no WebSocket route; live swaps and prices are streamed by the
+// gRPC service (GRPC_ADDR, proto/swapindexer/v1/api.proto).
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults used when a Config field is zero
+const (
+	DefaultTimeout      = 30 * time.Second
+	DefaultMaxRetries   = 2
+	DefaultRetryBackoff = 250 * time.Millisecond
+	DefaultMaxBackoff   = 5 * time.Second
+)
+
+// Config configures a Client
+type Config struct {
+	BaseURL      string        // e.g. http://localhost:8080 (required)
+	APIKey       string        // sent as X-API-Key when set
+	HTTPClient   *http.Client  // default: a client with Timeout
+	Timeout      time.Duration // per attempt, when HTTPClient is nil
+	MaxRetries   int           // attempts after the first; negative disables retries
+	RetryBackoff time.Duration // delay before the first retry, doubled each time
+	MaxBackoff   time.Duration // cap on a single delay, Retry-After included
+	UserAgent    string
+}
+
+// Client calls the swap indexer API. It is safe for concurrent use.
+type Client struct {
+	baseURL      string
+	apiKey       string
+	http         *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+	maxBackoff   time.Duration
+	userAgent    string
+}
+
+// New creates a client, filling zero fields with defaults
+func New(cfg Config) (*Client, error) {
+	base := strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+	u, err := url.Parse(base)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("client: BaseURL must be an http(s) URL, got %q", cfg.BaseURL)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: cfg.Timeout}
+	}
+	switch {
+	case cfg.MaxRetries == 0:
+		cfg.MaxRetries = DefaultMaxRetries
+	case cfg.MaxRetries < 0:
+		cfg.MaxRetries = 0
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = "solana-swap-indexer-go-client"
+	}
+	return &Client{
+		baseURL:      base,
+		apiKey:       strings.TrimSpace(cfg.APIKey),
+		http:         cfg.HTTPClient,
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff,
+		maxBackoff:   cfg.MaxBackoff,
+		userAgent:    cfg.UserAgent,
+	}, nil
+}
+
+// Error is a non-2xx response. Message comes from the API's error body;
+// Fields lists the invalid parameters of a 400, and Details holds the extra
+// context servers running with DEV=true add to other errors.
+type Error struct {
+	StatusCode int
+	Message    string
+	Details    map[string]any
+	Fields     []FieldError
+	RetryAfter time.Duration // from the Retry-After header, 0 when absent
+	Body       []byte        // raw response body
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("swap indexer api: http %d", e.StatusCode)
+	}
+	return fmt.Sprintf("swap indexer api: http %d: %s", e.StatusCode, e.Message)
+}
+
+// IsStatus reports whether err is an *Error with the given status code
+func IsStatus(err error, status int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// request is one API call
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	header http.Header
+
+	// retry repeats the call after transient failures; only set for calls
+	// that are safe to send twice
+	retry bool
+	// retryConflict also retries 409, the answer to a request whose
+	// Idempotency-Key is still being processed
+	retryConflict bool
+}
+
+// do runs req, retrying as allowed, and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, req request, out any) (http.Header, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("client: encoding request: %w", err)
+		}
+	}
+
+	backoff := c.retryBackoff
+	for attempt := 1; ; attempt++ {
+		header, err := c.attempt(ctx, req, body, out)
+		if err == nil {
+			return header, nil
+		}
+		if !req.retry || attempt > c.maxRetries || !c.retryable(ctx, req, err) {
+			return header, attemptsError(attempt, err)
+		}
+
+		wait := jitter(backoff)
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			wait = apiErr.RetryAfter
+		}
+		wait = min(wait, c.maxBackoff)
+		// Give up now rather than sleep past the caller's deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return header, attemptsError(attempt, err)
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return header, attemptsError(attempt, err)
+		case <-t.C:
+		}
+		backoff *= 2
+	}
+}
+
+// attempt performs a single HTTP request
+func (c *Client) attempt(ctx context.Context, req request, body []byte, out any) (http.Header, error) {
+	u := c.baseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, r)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.header {
+		httpReq.Header[k] = v
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	res, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return res.Header, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.Header, decodeError(res, data)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return res.Header, fmt.Errorf("client: decoding %s %s response: %w", req.method, req.path, err)
+		}
+	}
+	return res.Header, nil
+}
+
+// decodeError builds an *Error from a non-2xx response
+func decodeError(res *http.Response, data []byte) error {
+	apiErr := &Error{
+		StatusCode: res.StatusCode,
+		RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"), time.Now()),
+		Body:       data,
+	}
+	var body struct {
+		Error   string          `json:"error"`
+		Message string          `json:"message"` // echo's own errors (e.g. missing API key)
+		Details json.RawMessage `json:"details"` // []FieldError on 400s, an object otherwise
+	}
+	if json.Unmarshal(data, &body) == nil {
+		apiErr.Message = body.Error
+		if apiErr.Message == "" {
+			apiErr.Message = body.Message
+		}
+		if json.Unmarshal(body.Details, &apiErr.Fields) != nil {
+			_ = json.Unmarshal(body.Details, &apiErr.Details)
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+	return apiErr
+}
+
+// retryable reports whether a failed attempt is worth repeating: transport
+// errors, 429 and 5xx, but never once the caller's context is done
+func (c *Client) retryable(ctx context.Context, req request, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500 ||
+			(req.retryConflict && apiErr.StatusCode == http.StatusConflict)
+	}
+	return true
+}
+
+// attemptsError notes how many attempts were made before err
+func attemptsError(attempts int, err error) error {
+	if attempts == 1 {
+		return err
+	}
+	return fmt.Errorf("swap indexer api: failed after %d attempts: %w", attempts, err)
+}
+
+// jitter returns a random delay in [d/2, d]
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
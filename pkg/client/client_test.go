@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, h http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	c, err := New(Config{BaseURL: srv.URL + "/", APIKey: "secret", RetryBackoff: time.Millisecond})
+	require.NoError(t, err)
+	return c
+}
+
+func TestRecentSwaps(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/swaps/recent", r.URL.Path)
+		assert.Equal(t, "SOL/USDC", r.URL.Query().Get("pair"))
+		assert.Equal(t, "5", r.URL.Query().Get("limit"))
+		assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+		_, _ = w.Write([]byte(`{"items":[{"signature":"5h3k","pair":"SOL/USDC","token_in":"SOL","amount_in":2,"price":151.2,"slot":9}]}`))
+	})
+
+	swaps, err := c.RecentSwaps(context.Background(), RecentSwapsOptions{Pair: "SOL/USDC", Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, swaps, 1)
+	assert.Equal(t, Swap{Signature: "5h3k", Pair: "SOL/USDC", TokenIn: "SOL", AmountIn: 2, Price: 151.2, Slot: 9}, swaps[0])
+}
+
+func TestRetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`{"token":"SOL","price":150,"stale":false}`))
+		}
+	})
+
+	p, err := c.Price(context.Background(), "SOL")
+	require.NoError(t, err)
+	assert.Equal(t, 150.0, p.Price)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestValidationError(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid window","code":400,"details":[{"field":"window","rule":"max","message":"must be at most 24h"}]}`))
+	})
+
+	_, err := c.PriceHistory(context.Background(), "SOL", 48*time.Hour)
+	require.Error(t, err)
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "invalid window", apiErr.Message)
+	assert.Equal(t, []FieldError{{Field: "window", Rule: "max", Message: "must be at most 24h"}}, apiErr.Fields)
+	assert.Equal(t, int32(1), calls.Load(), "client errors are not retried")
+}
+
+func TestExecuteSwapRetriesOnlyWithIdempotencyKey(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Idempotency-Key") != "" {
+			w.Header().Set("Idempotent-Replayed", "true")
+		}
+		_, _ = w.Write([]byte(`{"execution_id":"ex1","signature":"sig","success":true,"expected_out":42}`))
+	})
+	ctx := context.Background()
+
+	_, err := c.ExecuteSwap(ctx, ExecuteSwapRequest{InputToken: "SOL", OutputToken: "USDC", Amount: 1})
+	assert.True(t, IsStatus(err, http.StatusServiceUnavailable))
+	assert.Equal(t, int32(1), calls.Load())
+
+	calls.Store(0)
+	res, err := c.ExecuteSwap(ctx, ExecuteSwapRequest{InputToken: "SOL", OutputToken: "USDC", Amount: 1, IdempotencyKey: "k1"})
+	require.NoError(t, err)
+	assert.Equal(t, "sig", res.Signature)
+	assert.True(t, res.Replayed)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestExecuteSwapFailureKeepsResponse(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(`{"execution_id":"ex1","signature":"sig","success":false,"error":"slippage exceeded"}`))
+	})
+
+	res, err := c.ExecuteSwap(context.Background(), ExecuteSwapRequest{InputToken: "SOL", OutputToken: "USDC", Amount: 1})
+	assert.True(t, IsStatus(err, http.StatusBadGateway))
+	require.NotNil(t, res)
+	assert.Equal(t, "sig", res.Signature)
+	assert.Equal(t, "slippage exceeded", res.Error)
+}
+
+func TestNewRejectsInvalidBaseURL(t *testing.T) {
+	_, err := New(Config{BaseURL: "localhost:8080"})
+	assert.Error(t, err)
+}
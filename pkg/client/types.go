@@ -0,0 +1,169 @@
+package client
+
+import "time"
+
+// Types mirror the JSON of the REST API (ROUTES.md)
+
+// Swap is one indexed swap
+type Swap struct {
+	Signature string    `json:"signature"`
+	Timestamp time.Time `json:"timestamp"`
+	Pair      string    `json:"pair"`
+	TokenIn   string    `json:"token_in"`
+	TokenOut  string    `json:"token_out"`
+	AmountIn  float64   `json:"amount_in"`  // UI units of TokenIn
+	AmountOut float64   `json:"amount_out"` // UI units of TokenOut
+	Price     float64   `json:"price"`
+	Fee       float64   `json:"fee"`
+	Pool      string    `json:"pool"`
+	Dex       string    `json:"dex"`
+
+	// On-chain position and exact amounts; zero on swaps indexed before they
+	// were recorded
+	Slot         uint64 `json:"slot"`
+	BlockTime    int64  `json:"block_time"`     // unix seconds
+	AmountInRaw  uint64 `json:"amount_in_raw"`  // base units of TokenIn
+	AmountOutRaw uint64 `json:"amount_out_raw"` // base units of TokenOut
+	DecimalsIn   uint8  `json:"decimals_in"`
+	DecimalsOut  uint8  `json:"decimals_out"`
+	ProgramID    string `json:"program_id"`
+	PoolAddress  string `json:"pool_address"`
+	Wallet       string `json:"wallet"`
+
+	// Landing cost of the transaction; zero on swaps indexed before it was
+	// recorded
+	FeeLamports      uint64 `json:"fee_lamports"`       // base and priority fee
+	PriorityFee      uint64 `json:"priority_fee"`       // lamports above the base fee
+	ComputeUnitPrice uint64 `json:"compute_unit_price"` // micro-lamports per compute unit
+
+	// When the indexer processed the swap; IndexedAt minus BlockTime is its
+	// end-to-end latency. Zero on swaps indexed before it was recorded.
+	IndexedAt time.Time `json:"indexed_at"`
+
+	// Set once the swap's block is finalized; recent swaps may still be
+	// rolled back with their fork
+	Finalized bool `json:"finalized"`
+}
+
+// RecentSwapsOptions filters RecentSwaps
+type RecentSwapsOptions struct {
+	Pair  string // e.g. "SOL/USDC"; empty for every pair
+	Limit int    // 1-200; 0 uses the server default (100)
+}
+
+// Price is a token's last price
+type Price struct {
+	Token     string     `json:"token"`
+	Price     float64    `json:"price"`                // 0 if none is cached
+	UpdatedAt *time.Time `json:"updated_at,omitempty"` // when the price was last written
+	Stale     bool       `json:"stale"`                // older than PRICE_STALE_AFTER, or unknown
+}
+
+// PricePoint is one observation in a token's price history
+type PricePoint struct {
+	Price float64   `json:"price"`
+	At    time.Time `json:"at"`
+}
+
+// PriceHistory is a token's recent price points, oldest first
+type PriceHistory struct {
+	Token  string       `json:"token"`
+	Window string       `json:"window"`
+	Points []PricePoint `json:"points"`
+}
+
+// QuoteRequest holds the parameters of GET /v1/quote, passed through to
+// Jupiter. Mints and Amount are required; nil and empty fields are omitted.
+type QuoteRequest struct {
+	InputMint  string
+	OutputMint string
+	Amount     uint64 // raw amount, before decimals
+
+	SlippageBps                *uint16
+	SwapMode                   string // ExactIn or ExactOut
+	Dexes                      []string
+	ExcludeDexes               []string
+	RestrictIntermediateTokens *bool
+	OnlyDirectRoutes           *bool
+	AsLegacyTransaction        *bool
+	PlatformFeeBps             *uint16
+	MaxAccounts                *uint64
+	InstructionVersion         string // V1 or V2
+	DynamicSlippage            *bool
+}
+
+// Quote is a Jupiter quote as returned by the API
+type Quote struct {
+	InputMint            string          `json:"inputMint"`
+	OutputMint           string          `json:"outputMint"`
+	InAmount             string          `json:"inAmount"`
+	OutAmount            string          `json:"outAmount"`
+	OtherAmountThreshold string          `json:"otherAmountThreshold"`
+	SwapMode             string          `json:"swapMode"`
+	SlippageBps          uint16          `json:"slippageBps"`
+	PriceImpactPct       string          `json:"priceImpactPct"`
+	RoutePlan            []QuoteRouteHop `json:"routePlan"`
+	ContextSlot          uint64          `json:"contextSlot,omitempty"`
+	Cached               bool            `json:"cached"` // served from the server's quote cache
+}
+
+// QuoteRouteHop is one step of a quote's route
+type QuoteRouteHop struct {
+	SwapInfo struct {
+		AmmKey     string `json:"ammKey"`
+		Label      string `json:"label,omitempty"`
+		InputMint  string `json:"inputMint"`
+		OutputMint string `json:"outputMint"`
+		InAmount   string `json:"inAmount"`
+		OutAmount  string `json:"outAmount"`
+	} `json:"swapInfo"`
+	Percent *uint8 `json:"percent,omitempty"`
+	Bps     uint16 `json:"bps"`
+}
+
+// AskAIRequest is a natural-language question about the swap data
+type AskAIRequest struct {
+	Question string `json:"question"`
+	Model    string `json:"model,omitempty"` // optional model override
+}
+
+// AskAIResponse is the AI's answer and the SQL it ran
+type AskAIResponse struct {
+	SQL    string `json:"sql"`
+	Answer string `json:"answer"`
+	TookMs int64  `json:"took_ms"`
+}
+
+// ExecuteSwapRequest is a swap to execute through the swap engine
+type ExecuteSwapRequest struct {
+	InputToken  string  `json:"input_token"`  // symbol to sell, e.g. SOL
+	OutputToken string  `json:"output_token"` // symbol to buy, e.g. USDC
+	Amount      float64 `json:"amount"`       // of InputToken, in UI units
+	SlippageBps *uint16 `json:"slippage_bps,omitempty"`
+	Reason      string  `json:"reason,omitempty"`
+
+	// IdempotencyKey, when set, makes retries safe: the server replays the
+	// first response instead of sending a second transaction, and the client
+	// retries transient failures. Without it the call is never retried.
+	IdempotencyKey string `json:"-"`
+}
+
+// ExecuteSwapResponse is the outcome of an executed swap
+type ExecuteSwapResponse struct {
+	ExecutionID string  `json:"execution_id"`
+	Signature   string  `json:"signature,omitempty"`
+	Success     bool    `json:"success"`
+	Error       string  `json:"error,omitempty"`
+	ExpectedOut uint64  `json:"expected_out"`         // raw units
+	ActualOut   *uint64 `json:"actual_out,omitempty"` // raw units, read from the transaction
+	DurationMs  int64   `json:"duration_ms"`
+
+	Replayed bool `json:"-"` // answered from the idempotency record of an earlier call
+}
+
+// FieldError describes one invalid request parameter of a 400
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
-	"os"
 	"testing"
 	"time"
 
@@ -14,7 +14,9 @@ import (
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/config"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/flags"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/models"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/redistest"
 	"github.com/aman-zulfiqar/solana-swap-indexer/internal/server"
+	"github.com/aman-zulfiqar/solana-swap-indexer/internal/storage"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -26,50 +28,48 @@ const (
 	testAPIKey  = "test-api-key-integration"
 )
 
-func setupIntegrationTest(t *testing.T) (*server.Server, *redis.Client, func()) {
-	// Check if Redis is available
-	redisAddr := os.Getenv("REDIS_ADDR")
-	if redisAddr == "" {
-		redisAddr = "localhost:6379"
-	}
-
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: redisAddr,
-		DB:   2, // Use different DB for integration tests
-	})
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := redisClient.Ping(ctx).Err(); err != nil {
-		t.Skipf("Redis not available for integration tests: %v", err)
-	}
-
-	// Clear test DB
-	_ = redisClient.FlushDB(ctx).Err()
+// integrationEnv is the API under test and the cache behind it
+type integrationEnv struct {
+	Cache storage.SwapCache
+	Redis *redis.Client // nil without Redis: the cache is a cache.MemoryCache and flags are off
+}
 
-	// Create test configuration
-	cfg := &config.Config{
-		APIAddr: testAPIAddr,
-		APIKey:  testAPIKey,
-		DevMode: true,
+// requireRedis skips tests of features only Redis provides
+func (env *integrationEnv) requireRedis(t *testing.T) {
+	if env.Redis == nil {
+		t.Skip("Redis not available for integration tests")
 	}
+}
 
-	// Initialize cache and flags store
+func setupIntegrationTest(t *testing.T) (*integrationEnv, func()) {
+	env := &integrationEnv{}
 	logger := logrus.New()
-	swapCache := cache.NewRedisCacheFromClient(redisClient, logger)
-	flagStore, err := flags.NewStore(redisClient)
-	require.NoError(t, err)
 
-	// Create server dependencies
+	// Create server dependencies, on Redis when it is reachable
 	handlers := &server.Handlers{
-		Cache:        swapCache,
-		Flags:        flagStore,
 		AI:           nil,
 		AIBaseConfig: ai.AgentConfig{},
 		DevMode:      true,
 		Logger:       logger,
 	}
+	if redisClient, err := redistest.Connect(t, redistest.DBIntegration); err == nil {
+		flagStore, err := flags.NewStore(redisClient)
+		require.NoError(t, err)
+		env.Redis = redisClient
+		env.Cache = cache.NewRedisCacheFromClient(redisClient, logger)
+		handlers.Flags = flagStore
+	} else {
+		t.Logf("Redis not available, serving from memory: %v", err)
+		env.Cache = cache.NewMemoryCache(0, 0)
+	}
+	handlers.Cache = env.Cache
+
+	// Create test configuration
+	cfg := &config.Config{
+		APIAddr: testAPIAddr,
+		APIKey:  testAPIKey,
+		DevMode: true,
+	}
 
 	serverConfig := server.ServerConfig{
 		Addr:    cfg.APIAddr,
@@ -95,21 +95,19 @@ func setupIntegrationTest(t *testing.T) (*server.Server, *redis.Client, func())
 	// Wait for server to be ready
 	time.Sleep(100 * time.Millisecond)
 
-	// Cleanup function
+	// Cleanup function; the Redis database is emptied by redistest
 	cleanup := func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
 		_ = srv.Shutdown(ctx)
-		_ = redisClient.FlushDB(ctx).Err()
-		_ = redisClient.Close()
 	}
 
-	return srv, redisClient, cleanup
+	return env, cleanup
 }
 
 func makeRequest(t *testing.T, method, url string, body interface{}, expectedStatus int) *http.Response {
-	var reqBody *bytes.Buffer
+	var reqBody io.Reader // a nil *bytes.Buffer would not be a nil io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
 		require.NoError(t, err)
@@ -132,7 +130,7 @@ func makeRequest(t *testing.T, method, url string, body interface{}, expectedSta
 }
 
 func TestIntegration_Health(t *testing.T) {
-	_, _, cleanup := setupIntegrationTest(t)
+	_, cleanup := setupIntegrationTest(t)
 	defer cleanup()
 
 	resp := makeRequest(t, http.MethodGet, "http://localhost:8091/v1/health", nil, http.StatusOK)
@@ -146,7 +144,7 @@ func TestIntegration_Health(t *testing.T) {
 }
 
 func TestIntegration_Echo(t *testing.T) {
-	_, _, cleanup := setupIntegrationTest(t)
+	_, cleanup := setupIntegrationTest(t)
 	defer cleanup()
 
 	payload := map[string]interface{}{"message": "hello", "count": 42}
@@ -158,12 +156,13 @@ func TestIntegration_Echo(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, payload["message"], response["message"])
-	assert.Equal(t, payload["count"], response["count"])
+	assert.Equal(t, float64(42), response["count"]) // JSON numbers decode as float64
 }
 
 func TestIntegration_FlagsCRUD(t *testing.T) {
-	_, _, cleanup := setupIntegrationTest(t)
+	env, cleanup := setupIntegrationTest(t)
 	defer cleanup()
+	env.requireRedis(t)
 
 	// Create flag
 	upsertPayload := map[string]interface{}{"key": "test.flag", "value": true}
@@ -221,8 +220,9 @@ func TestIntegration_FlagsCRUD(t *testing.T) {
 }
 
 func TestIntegration_FlagsValidation(t *testing.T) {
-	_, _, cleanup := setupIntegrationTest(t)
+	env, cleanup := setupIntegrationTest(t)
 	defer cleanup()
+	env.requireRedis(t)
 
 	// Test invalid key (empty key will fail regex validation)
 	invalidPayload := map[string]interface{}{"key": "", "value": true}
@@ -245,18 +245,20 @@ func TestIntegration_FlagsValidation(t *testing.T) {
 }
 
 func TestIntegration_SwapsAndPrices(t *testing.T) {
-	_, redisClient, cleanup := setupIntegrationTest(t)
+	env, cleanup := setupIntegrationTest(t)
 	defer cleanup()
 
 	ctx := context.Background()
-
-	// Add some test data to Redis
-	swapData := `{"signature":"test_sig","pair":"SOL/USDC","amount_in":1.0,"amount_out":100.0,"price":100.0,"token_in":"SOL","token_out":"USDC"}`
-	err := redisClient.LPush(ctx, "swaps:recent", swapData).Err()
-	require.NoError(t, err)
-
-	err = redisClient.Set(ctx, "price:SOL", "150.5", 0).Err()
-	require.NoError(t, err)
+	redisClient, swapCache := env.Redis, env.Cache
+
+	// Add some test data; in Redis as an older indexer wrote it
+	if redisClient != nil {
+		swapData := `{"signature":"test_sig","pair":"SOL/USDC","amount_in":1.0,"amount_out":100.0,"price":100.0,"token_in":"SOL","token_out":"USDC"}`
+		require.NoError(t, redisClient.LPush(ctx, "swaps:recent", swapData).Err())
+		require.NoError(t, redisClient.Set(ctx, "price:SOL", "150.5", 0).Err())
+	} else {
+		require.NoError(t, swapCache.AddRecentSwap(ctx, &models.SwapEvent{Signature: "test_sig", Pair: "SOL/USDC", AmountIn: 1, AmountOut: 100, Price: 100, TokenIn: "SOL", TokenOut: "USDC"}))
+	}
 
 	// Test recent swaps
 	resp := makeRequest(t, http.MethodGet, "http://localhost:8091/v1/swaps/recent?limit=5", nil, http.StatusOK)
@@ -265,20 +267,73 @@ func TestIntegration_SwapsAndPrices(t *testing.T) {
 	var swapsResponse struct {
 		Items []*models.SwapEvent `json:"items"`
 	}
-	err = json.NewDecoder(resp.Body).Decode(&swapsResponse)
+	err := json.NewDecoder(resp.Body).Decode(&swapsResponse)
 	require.NoError(t, err)
 	assert.Len(t, swapsResponse.Items, 1)
 	assert.Equal(t, "test_sig", swapsResponse.Items[0].Signature)
 
+	// Test per-pair list written by the cache
+	require.NoError(t, swapCache.AddRecentSwap(ctx, &models.SwapEvent{Signature: "pair_sig_1", Pair: "BONK/SOL"}))
+	resp = makeRequest(t, http.MethodGet, "http://localhost:8091/v1/swaps/recent?pair=bonk/sol", nil, http.StatusOK)
+	defer resp.Body.Close()
+
+	var pairResponse struct {
+		Items []*models.SwapEvent `json:"items"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&pairResponse)
+	require.NoError(t, err)
+	require.Len(t, pairResponse.Items, 1)
+	assert.Equal(t, "pair_sig_1", pairResponse.Items[0].Signature)
+
+	// Test the pipelined write path used by the indexer
+	require.NoError(t, swapCache.ProcessSwap(ctx, &models.SwapEvent{Signature: "proc_sig_1", Pair: "JUP/USDC", TokenOut: "JUP", Price: 0.9}))
+	if redisClient != nil {
+		assert.Equal(t, int64(1), redisClient.LLen(ctx, "swaps:recent:JUP/USDC").Val())
+		assert.Equal(t, int64(1), redisClient.XLen(ctx, "swaps:stream").Val())
+	}
+	jup, err := swapCache.GetPrice(ctx, "JUP")
+	require.NoError(t, err)
+	require.NotNil(t, jup)
+	assert.Equal(t, 0.9, jup.Price)
+
 	// Test price
-	resp = makeRequest(t, http.MethodGet, "http://localhost:8091/v1/prices/SOL", nil, http.StatusOK)
+	if redisClient != nil {
+		resp = makeRequest(t, http.MethodGet, "http://localhost:8091/v1/prices/SOL", nil, http.StatusOK)
+		defer resp.Body.Close()
+
+		var priceResponse server.PriceResponse
+		err = json.NewDecoder(resp.Body).Decode(&priceResponse)
+		require.NoError(t, err)
+		assert.Equal(t, "SOL", priceResponse.Token)
+		assert.Equal(t, 150.5, priceResponse.Price)
+		assert.True(t, priceResponse.Stale) // bare legacy value has no timestamp
+		assert.Nil(t, priceResponse.UpdatedAt)
+	}
+
+	// Test fresh price written by the indexer
+	require.NoError(t, swapCache.UpdatePrice(ctx, "USDC", 1.0))
+	resp = makeRequest(t, http.MethodGet, "http://localhost:8091/v1/prices/usdc", nil, http.StatusOK)
 	defer resp.Body.Close()
 
-	var priceResponse server.PriceResponse
-	err = json.NewDecoder(resp.Body).Decode(&priceResponse)
+	var freshPriceResponse server.PriceResponse
+	err = json.NewDecoder(resp.Body).Decode(&freshPriceResponse)
 	require.NoError(t, err)
-	assert.Equal(t, "SOL", priceResponse.Token)
-	assert.Equal(t, 150.5, priceResponse.Price)
+	assert.Equal(t, 1.0, freshPriceResponse.Price)
+	assert.False(t, freshPriceResponse.Stale)
+	require.NotNil(t, freshPriceResponse.UpdatedAt)
+	if redisClient != nil {
+		assert.Positive(t, redisClient.TTL(ctx, "price:USDC").Val())
+	}
+
+	// Test price history recorded alongside the price
+	resp = makeRequest(t, http.MethodGet, "http://localhost:8091/v1/prices/USDC/history?window=5m", nil, http.StatusOK)
+	defer resp.Body.Close()
+
+	var historyResponse server.PriceHistoryResponse
+	err = json.NewDecoder(resp.Body).Decode(&historyResponse)
+	require.NoError(t, err)
+	require.Len(t, historyResponse.Points, 1)
+	assert.Equal(t, 1.0, historyResponse.Points[0].Price)
 
 	// Test unknown token price (should return 0)
 	resp = makeRequest(t, http.MethodGet, "http://localhost:8091/v1/prices/UNKNOWN", nil, http.StatusOK)
@@ -289,10 +344,11 @@ func TestIntegration_SwapsAndPrices(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "UNKNOWN", unknownPriceResponse.Token)
 	assert.Equal(t, 0.0, unknownPriceResponse.Price)
+	assert.True(t, unknownPriceResponse.Stale)
 }
 
 func TestIntegration_SwapsValidation(t *testing.T) {
-	_, _, cleanup := setupIntegrationTest(t)
+	_, cleanup := setupIntegrationTest(t)
 	defer cleanup()
 
 	// Test invalid limit
@@ -306,10 +362,10 @@ func TestIntegration_SwapsValidation(t *testing.T) {
 }
 
 func TestIntegration_Authentication(t *testing.T) {
-	_, _, cleanup := setupIntegrationTest(t)
+	_, cleanup := setupIntegrationTest(t)
 	defer cleanup()
 
-	// Test without API key
+	// Test without API key: echo's key auth answers a missing key with 400
 	req, err := http.NewRequest(http.MethodGet, "http://localhost:8091/v1/health", nil)
 	require.NoError(t, err)
 
@@ -318,7 +374,7 @@ func TestIntegration_Authentication(t *testing.T) {
 	require.NoError(t, err)
 	defer resp.Body.Close()
 
-	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
 	// Test with invalid API key
 	req, err = http.NewRequest(http.MethodGet, "http://localhost:8091/v1/health", nil)
@@ -333,7 +389,7 @@ func TestIntegration_Authentication(t *testing.T) {
 }
 
 func TestIntegration_ErrorHandling(t *testing.T) {
-	_, _, cleanup := setupIntegrationTest(t)
+	_, cleanup := setupIntegrationTest(t)
 	defer cleanup()
 
 	// Test 404 for non-existent endpoint
@@ -368,11 +424,11 @@ func TestIntegration_ErrorHandling(t *testing.T) {
 
 	err = json.NewDecoder(resp.Body).Decode(&errorResponse)
 	require.NoError(t, err)
-	assert.Contains(t, errorResponse.Error, "invalid JSON")
+	assert.Contains(t, errorResponse.Error, "invalid json")
 }
 
 func TestIntegration_ConcurrentRequests(t *testing.T) {
-	_, _, cleanup := setupIntegrationTest(t)
+	_, cleanup := setupIntegrationTest(t)
 	defer cleanup()
 
 	const numRequests = 50
@@ -398,7 +454,7 @@ func TestIntegration_ConcurrentRequests(t *testing.T) {
 }
 
 func TestIntegration_RateLimiting(t *testing.T) {
-	_, _, cleanup := setupIntegrationTest(t)
+	_, cleanup := setupIntegrationTest(t)
 	defer cleanup()
 
 	// Note: This is a basic test. In a real scenario, you'd want to test